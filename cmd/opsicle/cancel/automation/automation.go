@@ -0,0 +1,115 @@
+package automation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"opsicle/internal/automations"
+	"opsicle/internal/cli"
+	"opsicle/internal/config"
+	"opsicle/internal/types"
+	"opsicle/internal/validate"
+	"opsicle/pkg/controller"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var flags cli.Flags = cli.Flags{
+	{
+		Name:         "reason",
+		DefaultValue: "",
+		Usage:        "reason for cancelling the automation, this is included in its status",
+		Type:         cli.FlagTypeString,
+	},
+}.Append(config.GetControllerUrlFlags())
+
+var Command = cli.NewCommand(cli.CommandOpts{
+	Flags:   flags,
+	Use:     "automation <automation-id>",
+	Aliases: []string{"a"},
+	Short:   "Cancels an automation run",
+	Long:    "Cancels an automation run; runs which are executing are stopped by their worker which sends SIGTERM to the current phase and SIGKILL if it does not exit within the worker's grace period",
+	Run: func(cmd *cobra.Command, opts *cli.Command, args []string) error {
+		if len(args) == 0 {
+			return fmt.Errorf("failed to receive an automation id")
+		}
+		automationId := strings.TrimSpace(args[0])
+		if err := validate.Uuid(automationId); err != nil {
+			return fmt.Errorf("failed to validate automation id '%s': %w", automationId, err)
+		}
+
+		controllerUrl := viper.GetString("controller-url")
+		methodId := "opsicle/cancel/automation"
+
+	enforceAuth:
+		sessionToken, err := cli.RequireAuth(controllerUrl, methodId)
+		if err != nil {
+			rootCmd := cmd.Root()
+			rootCmd.SetArgs([]string{"login"})
+			_, execErr := rootCmd.ExecuteC()
+			if execErr != nil {
+				return execErr
+			}
+			goto enforceAuth
+		}
+
+		client, err := controller.NewClient(controller.NewClientOpts{
+			ControllerUrl: controllerUrl,
+			BearerAuth: &controller.NewClientBearerAuthOpts{
+				Token: sessionToken,
+			},
+			Id: methodId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create controller client: %w", err)
+		}
+
+		cancelOutput, err := client.CancelAutomationV1(controller.CancelAutomationV1Input{
+			AutomationId: automationId,
+			Reason:       viper.GetString("reason"),
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, types.ErrorInsufficientPermissions):
+				cli.PrintBoxedErrorMessage("You are not authorized to cancel this automation")
+				return fmt.Errorf("not authorized to cancel automation")
+			case errors.Is(err, types.ErrorNotFound):
+				cli.PrintBoxedErrorMessage("The automation could not be found")
+				return fmt.Errorf("automation not found")
+			case errors.Is(err, types.ErrorAutomationCompleted):
+				cli.PrintBoxedErrorMessage("The automation has already completed")
+				return fmt.Errorf("automation already completed")
+			default:
+				return fmt.Errorf("failed to cancel automation: %w", err)
+			}
+		}
+		if cancelOutput == nil {
+			return fmt.Errorf("controller returned no data")
+		}
+
+		outputFormat := strings.ToLower(viper.GetString("output"))
+		switch outputFormat {
+		case "json":
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(cancelOutput.Data); err != nil {
+				return fmt.Errorf("failed to encode json output: %w", err)
+			}
+		default:
+			if automations.RunStatusCode(cancelOutput.Data.Status) == automations.RunStatusCancelled {
+				cli.PrintBoxedSuccessMessage(fmt.Sprintf("Automation %s was cancelled", automationId))
+			} else {
+				cli.PrintBoxedSuccessMessage(fmt.Sprintf(
+					"Cancellation of automation %s was requested, it will be stopped by its worker\nUse `opsicle get automation %s` to check its status",
+					automationId,
+					automationId,
+				))
+			}
+		}
+		return nil
+	},
+})
@@ -0,0 +1,19 @@
+package cancel
+
+import (
+	"opsicle/cmd/opsicle/cancel/automation"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	Command.AddCommand(automation.Command.Get())
+}
+
+var Command = &cobra.Command{
+	Use:   "cancel",
+	Short: "Cancels resources in Opsicle",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cmd.Help()
+	},
+}
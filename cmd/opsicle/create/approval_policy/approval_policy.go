@@ -0,0 +1,141 @@
+package approval_policy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"opsicle/internal/approvals"
+	"opsicle/internal/cli"
+	"opsicle/internal/common"
+	"opsicle/internal/config"
+	"opsicle/internal/types"
+	"opsicle/pkg/controller"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+var flags cli.Flags = cli.Flags{
+	{
+		Name:         "file",
+		Short:        'f',
+		DefaultValue: "",
+		Usage:        "path to an ApprovalPolicy resource",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "org",
+		DefaultValue: "",
+		Usage:        "codeword or ID of the organisation to create the approval policy in",
+		Type:         cli.FlagTypeString,
+	},
+}.Append(config.GetControllerUrlFlags())
+
+var Command = cli.NewCommand(cli.CommandOpts{
+	Flags:   flags,
+	Use:     "approval-policy",
+	Aliases: []string{"approvalpolicy", "ap"},
+	Short:   "Creates or updates an approval policy which templates in the organisation can reference",
+	Long:    "Creates an approval policy in an organisation or updates it if a policy with the same name exists; templates submitted to the organisation can reference it using `approvalPolicy.policyRef` and receive a copy of it when they are submitted",
+	Run: func(cmd *cobra.Command, opts *cli.Command, args []string) error {
+		policyPath := viper.GetString("file")
+		if policyPath == "" {
+			cli.PrintBoxedErrorMessage("Specify the path to an approval policy using --file")
+			return fmt.Errorf("approval policy file path undefined")
+		}
+		orgInput := strings.TrimSpace(viper.GetString("org"))
+		if orgInput == "" {
+			cli.PrintBoxedErrorMessage("Specify the organisation to create the approval policy in using --org")
+			return fmt.Errorf("org undefined")
+		}
+		absolutePolicyPath, err := common.ToAbsolutePath(policyPath)
+		if err != nil {
+			return fmt.Errorf("failed to get absolute path of file: %w", err)
+		}
+		policyData, err := os.ReadFile(absolutePolicyPath)
+		if err != nil {
+			return fmt.Errorf("failed to load approval policy from path[%s]: %w", absolutePolicyPath, err)
+		}
+		var policy approvals.Policy
+		if err := yaml.Unmarshal(policyData, &policy); err != nil {
+			return fmt.Errorf("failed to parse approval policy: %w", err)
+		}
+		if err := policy.Validate(); err != nil {
+			cli.PrintBoxedErrorMessage(fmt.Sprintf("The approval policy is invalid: %s", err))
+			return fmt.Errorf("invalid approval policy")
+		}
+
+		controllerUrl := viper.GetString("controller-url")
+		methodId := "opsicle/create/approval-policy"
+
+	enforceAuth:
+		sessionToken, err := cli.RequireAuth(controllerUrl, methodId)
+		if err != nil {
+			rootCmd := cmd.Root()
+			rootCmd.SetArgs([]string{"login"})
+			_, execErr := rootCmd.ExecuteC()
+			if execErr != nil {
+				return execErr
+			}
+			goto enforceAuth
+		}
+
+		client, err := controller.NewClient(controller.NewClientOpts{
+			ControllerUrl: controllerUrl,
+			BearerAuth: &controller.NewClientBearerAuthOpts{
+				Token: sessionToken,
+			},
+			Id: methodId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create controller client: %w", err)
+		}
+
+		getOrgOutput, err := client.GetOrgV1(controller.GetOrgV1Input{Ref: orgInput})
+		if err != nil {
+			return fmt.Errorf("failed to retrieve org: %w", err)
+		}
+		setOutput, err := client.SetOrgApprovalPolicyV1(controller.SetOrgApprovalPolicyV1Input{
+			OrgId: getOrgOutput.Data.Id,
+			Data:  policyData,
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, types.ErrorInsufficientPermissions):
+				cli.PrintBoxedErrorMessage("You are not authorized to manage approval policies in this organisation")
+				return fmt.Errorf("not authorized to create approval policy")
+			case errors.Is(err, types.ErrorInvalidInput):
+				cli.PrintBoxedErrorMessage(fmt.Sprintf("The approval policy could not be created: %s", err))
+				return fmt.Errorf("invalid approval policy")
+			default:
+				return fmt.Errorf("failed to create approval policy: %w", err)
+			}
+		}
+		if setOutput == nil {
+			return fmt.Errorf("controller returned no data")
+		}
+		approvalPolicy := setOutput.Data
+
+		switch strings.ToLower(viper.GetString("output")) {
+		case "json":
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(approvalPolicy); err != nil {
+				return fmt.Errorf("failed to encode json output: %w", err)
+			}
+		default:
+			cli.PrintBoxedSuccessMessage(fmt.Sprintf(
+				"Approval policy %s was saved with id %s in organisation %s\nReference it from templates using `approvalPolicy.policyRef: %s`",
+				approvalPolicy.Name,
+				approvalPolicy.Id,
+				getOrgOutput.Data.Name,
+				approvalPolicy.Name,
+			))
+		}
+		return nil
+	},
+})
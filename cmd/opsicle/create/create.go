@@ -1,21 +1,27 @@
 package create
 
 import (
+	"opsicle/cmd/opsicle/create/approval_policy"
 	"opsicle/cmd/opsicle/create/approval_request"
 	"opsicle/cmd/opsicle/create/mfa"
 	"opsicle/cmd/opsicle/create/org"
+	"opsicle/cmd/opsicle/create/schedule"
 	"opsicle/cmd/opsicle/create/session"
 	"opsicle/cmd/opsicle/create/template"
+	"opsicle/cmd/opsicle/create/trigger"
 	"opsicle/cmd/opsicle/create/user"
 
 	"github.com/spf13/cobra"
 )
 
 func init() {
+	Command.AddCommand(approval_policy.Command.Get())
 	Command.AddCommand(approval_request.Command)
 	Command.AddCommand(mfa.Command)
 	Command.AddCommand(org.Command)
+	Command.AddCommand(schedule.Command.Get())
 	Command.AddCommand(template.Command)
+	Command.AddCommand(trigger.Command.Get())
 	Command.AddCommand(session.Command)
 	Command.AddCommand(user.Command)
 }
@@ -0,0 +1,190 @@
+package schedule
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"opsicle/internal/automations"
+	"opsicle/internal/cli"
+	"opsicle/internal/config"
+	"opsicle/internal/types"
+	"opsicle/pkg/controller"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var flags cli.Flags = cli.Flags{
+	{
+		Name:         "cron",
+		DefaultValue: "",
+		Usage:        "cron expression (eg. '0 2 * * *' or '@daily') defining when automations are triggered",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "timezone",
+		DefaultValue: automations.DefaultScheduleTimezone,
+		Usage:        "timezone the cron expression is evaluated in (eg. 'Asia/Singapore')",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "template-id",
+		Short:        't',
+		DefaultValue: "",
+		Usage:        "ID (or name) of the template to trigger automations from",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "template-version",
+		DefaultValue: 0,
+		Usage:        "version of the template to trigger automations from, the latest version at the time of each run is used when not specified",
+		Type:         cli.FlagTypeInteger,
+	},
+	{
+		Name:         "org",
+		DefaultValue: "",
+		Usage:        "codeword or ID of the organisation to create the schedule in",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "var",
+		DefaultValue: []string{},
+		Usage:        "value of a template variable in the format '<variable-id>=<value>', specify this multiple times for more variables",
+		Type:         cli.FlagTypeStringSlice,
+	},
+}.Append(config.GetControllerUrlFlags())
+
+var Command = cli.NewCommand(cli.CommandOpts{
+	Flags:   flags,
+	Use:     "schedule <name>",
+	Aliases: []string{"sched"},
+	Short:   "Creates a schedule which triggers automations from a template",
+	Long:    "Creates a schedule which triggers automations from a template as you whenever its cron expression fires; runs go through the same path as `opsicle run automation` and are subject to the template's approval policy",
+	Run: func(cmd *cobra.Command, opts *cli.Command, args []string) error {
+		if len(args) == 0 {
+			return fmt.Errorf("failed to receive a schedule name")
+		}
+		scheduleName := strings.TrimSpace(args[0])
+		cronExpression := strings.TrimSpace(viper.GetString("cron"))
+		if _, err := automations.ParseSchedule(cronExpression, viper.GetString("timezone")); err != nil {
+			return fmt.Errorf("failed to validate schedule: %w", err)
+		}
+		variableMap := map[string]any{}
+		for _, variable := range viper.GetStringSlice("var") {
+			variableId, value, ok := strings.Cut(variable, "=")
+			if !ok || strings.TrimSpace(variableId) == "" {
+				return fmt.Errorf("failed to parse variable '%s', expected '<variable-id>=<value>'", variable)
+			}
+			variableMap[strings.TrimSpace(variableId)] = value
+		}
+
+		controllerUrl := viper.GetString("controller-url")
+		methodId := "opsicle/create/schedule"
+
+	enforceAuth:
+		sessionToken, err := cli.RequireAuth(controllerUrl, methodId)
+		if err != nil {
+			rootCmd := cmd.Root()
+			rootCmd.SetArgs([]string{"login"})
+			_, execErr := rootCmd.ExecuteC()
+			if execErr != nil {
+				return execErr
+			}
+			goto enforceAuth
+		}
+
+		client, err := controller.NewClient(controller.NewClientOpts{
+			ControllerUrl: controllerUrl,
+			BearerAuth: &controller.NewClientBearerAuthOpts{
+				Token: sessionToken,
+			},
+			Id: methodId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create controller client: %w", err)
+		}
+
+		var orgId *string
+		orgInput := strings.TrimSpace(viper.GetString("org"))
+		inputTemplateReference := viper.GetString("template-id")
+		var templateInstance *cli.Template
+		if orgInput != "" {
+			getOrgOutput, err := client.GetOrgV1(controller.GetOrgV1Input{Ref: orgInput})
+			if err != nil {
+				return fmt.Errorf("failed to retrieve org: %w", err)
+			}
+			orgId = &getOrgOutput.Data.Id
+			templateInstance, err = cli.HandleOrgTemplateSelection(cli.HandleOrgTemplateSelectionOpts{
+				Client:    client,
+				OrgId:     *orgId,
+				UserInput: inputTemplateReference,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to select a template: %w", err)
+			}
+		} else {
+			templateInstance, err = cli.HandleTemplateSelection(cli.HandleTemplateSelectionOpts{
+				Client:    client,
+				UserInput: inputTemplateReference,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to select a template: %w", err)
+			}
+		}
+
+		createInput := controller.CreateAutomationScheduleV1Input{
+			Name:           scheduleName,
+			CronExpression: cronExpression,
+			Timezone:       viper.GetString("timezone"),
+			OrgId:          orgId,
+			TemplateId:     templateInstance.Id,
+			VariableMap:    variableMap,
+		}
+		if templateVersion := viper.GetInt64("template-version"); templateVersion > 0 {
+			createInput.TemplateVersion = &templateVersion
+		}
+		createOutput, err := client.CreateAutomationScheduleV1(createInput)
+		if err != nil {
+			switch {
+			case errors.Is(err, types.ErrorInsufficientPermissions):
+				cli.PrintBoxedErrorMessage("You are not authorized to schedule automations from this template")
+				return fmt.Errorf("not authorized to create schedule")
+			case errors.Is(err, types.ErrorInvalidInput):
+				cli.PrintBoxedErrorMessage(fmt.Sprintf("The schedule could not be created: %s", err))
+				return fmt.Errorf("invalid schedule")
+			default:
+				return fmt.Errorf("failed to create schedule: %w", err)
+			}
+		}
+		if createOutput == nil {
+			return fmt.Errorf("controller returned no data")
+		}
+		schedule := createOutput.Data
+
+		outputFormat := strings.ToLower(viper.GetString("output"))
+		switch outputFormat {
+		case "json":
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(schedule); err != nil {
+				return fmt.Errorf("failed to encode json output: %w", err)
+			}
+		default:
+			nextRunAt := "-"
+			if schedule.NextRunAt != nil {
+				nextRunAt = schedule.NextRunAt.Local().Format(cli.TimestampHuman)
+			}
+			cli.PrintBoxedSuccessMessage(fmt.Sprintf(
+				"Schedule %s was created with id %s\nTemplate[%s] will next be triggered at %s\nUse `opsicle list schedules` to view your schedules",
+				schedule.Name,
+				schedule.Id,
+				schedule.TemplateName,
+				nextRunAt,
+			))
+		}
+		return nil
+	},
+})
@@ -0,0 +1,234 @@
+package trigger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"opsicle/internal/automations"
+	"opsicle/internal/cli"
+	"opsicle/internal/config"
+	"opsicle/internal/types"
+	"opsicle/pkg/controller"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var flags cli.Flags = cli.Flags{
+	{
+		Name:         "template-id",
+		Short:        't',
+		DefaultValue: "",
+		Usage:        "ID (or name) of the template to trigger automations from",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "template-version",
+		DefaultValue: 0,
+		Usage:        "version of the template to trigger automations from, the latest version at the time of each webhook is used when not specified",
+		Type:         cli.FlagTypeInteger,
+	},
+	{
+		Name:         "org",
+		DefaultValue: "",
+		Usage:        "codeword or ID of the organisation to create the trigger in",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "auth",
+		DefaultValue: "hmac",
+		Usage:        "how webhooks are authenticated, one of ['hmac', 'org_token']",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "org-token-id",
+		DefaultValue: "",
+		Usage:        "ID of the org token which authenticates webhooks when --auth is 'org_token'",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "map",
+		DefaultValue: []string{},
+		Usage:        "mapping of the webhook payload to a template variable in the format '<variable-id>=<expression>' where the expression is a JSONPath (eg. '$.alerts[0].labels.instance') or a Go template (eg. '{{ .body.status }}'), specify this multiple times for more variables",
+		Type:         cli.FlagTypeStringSlice,
+	},
+	{
+		Name:         "dedup-key",
+		DefaultValue: "",
+		Usage:        "expression evaluated against the webhook payload (eg. '$.groupKey'), webhooks with the same key within the dedup window only trigger one automation",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "dedup-window",
+		DefaultValue: 5 * time.Minute,
+		Usage:        "duration within which webhooks with the same dedup key are dropped",
+		Type:         cli.FlagTypeDuration,
+	},
+	{
+		Name:         "rate-limit",
+		DefaultValue: 0,
+		Usage:        "maximum number of automations triggered within the rate limit window, 0 for no limit",
+		Type:         cli.FlagTypeInteger,
+	},
+	{
+		Name:         "rate-limit-window",
+		DefaultValue: time.Minute,
+		Usage:        "duration of the window which the rate limit applies to",
+		Type:         cli.FlagTypeDuration,
+	},
+}.Append(config.GetControllerUrlFlags())
+
+var Command = cli.NewCommand(cli.CommandOpts{
+	Flags:   flags,
+	Use:     "trigger <name>",
+	Aliases: []string{"webhook"},
+	Short:   "Creates a trigger which runs automations from a template when a webhook is received",
+	Long:    "Creates a trigger which runs automations from a template as you when an authenticated webhook (eg. from Alertmanager or Grafana) is received; variables are mapped from the JSON payload of the webhook and runs go through the same path as `opsicle run automation`",
+	Run: func(cmd *cobra.Command, opts *cli.Command, args []string) error {
+		if len(args) == 0 {
+			return fmt.Errorf("failed to receive a trigger name")
+		}
+		triggerName := strings.TrimSpace(args[0])
+		variableMapping := automations.PayloadMapping{}
+		for _, mapping := range viper.GetStringSlice("map") {
+			variableId, expression, ok := strings.Cut(mapping, "=")
+			if !ok || strings.TrimSpace(variableId) == "" {
+				return fmt.Errorf("failed to parse mapping '%s', expected '<variable-id>=<expression>'", mapping)
+			}
+			variableMapping[strings.TrimSpace(variableId)] = expression
+		}
+		if err := variableMapping.Validate(); err != nil {
+			return fmt.Errorf("failed to validate mappings: %w", err)
+		}
+		var dedupKey *string
+		if dedupKeyInput := strings.TrimSpace(viper.GetString("dedup-key")); dedupKeyInput != "" {
+			if err := automations.ValidatePayloadExpression(dedupKeyInput); err != nil {
+				return fmt.Errorf("failed to validate dedup key: %w", err)
+			}
+			dedupKey = &dedupKeyInput
+		}
+
+		controllerUrl := viper.GetString("controller-url")
+		methodId := "opsicle/create/trigger"
+
+	enforceAuth:
+		sessionToken, err := cli.RequireAuth(controllerUrl, methodId)
+		if err != nil {
+			rootCmd := cmd.Root()
+			rootCmd.SetArgs([]string{"login"})
+			_, execErr := rootCmd.ExecuteC()
+			if execErr != nil {
+				return execErr
+			}
+			goto enforceAuth
+		}
+
+		client, err := controller.NewClient(controller.NewClientOpts{
+			ControllerUrl: controllerUrl,
+			BearerAuth: &controller.NewClientBearerAuthOpts{
+				Token: sessionToken,
+			},
+			Id: methodId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create controller client: %w", err)
+		}
+
+		var orgId *string
+		orgInput := strings.TrimSpace(viper.GetString("org"))
+		inputTemplateReference := viper.GetString("template-id")
+		var templateInstance *cli.Template
+		if orgInput != "" {
+			getOrgOutput, err := client.GetOrgV1(controller.GetOrgV1Input{Ref: orgInput})
+			if err != nil {
+				return fmt.Errorf("failed to retrieve org: %w", err)
+			}
+			orgId = &getOrgOutput.Data.Id
+			templateInstance, err = cli.HandleOrgTemplateSelection(cli.HandleOrgTemplateSelectionOpts{
+				Client:    client,
+				OrgId:     *orgId,
+				UserInput: inputTemplateReference,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to select a template: %w", err)
+			}
+		} else {
+			templateInstance, err = cli.HandleTemplateSelection(cli.HandleTemplateSelectionOpts{
+				Client:    client,
+				UserInput: inputTemplateReference,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to select a template: %w", err)
+			}
+		}
+
+		createInput := controller.CreateTemplateTriggerV1Input{
+			Name:            triggerName,
+			OrgId:           orgId,
+			TemplateId:      templateInstance.Id,
+			AuthType:        viper.GetString("auth"),
+			VariableMapping: variableMapping,
+			DedupKey:        dedupKey,
+			DedupWindow:     viper.GetDuration("dedup-window"),
+			RateLimit:       viper.GetInt("rate-limit"),
+			RateLimitWindow: viper.GetDuration("rate-limit-window"),
+		}
+		if templateVersion := viper.GetInt64("template-version"); templateVersion > 0 {
+			createInput.TemplateVersion = &templateVersion
+		}
+		if orgTokenId := strings.TrimSpace(viper.GetString("org-token-id")); orgTokenId != "" {
+			createInput.OrgTokenId = &orgTokenId
+		}
+		createOutput, err := client.CreateTemplateTriggerV1(createInput)
+		if err != nil {
+			switch {
+			case errors.Is(err, types.ErrorInsufficientPermissions):
+				cli.PrintBoxedErrorMessage("You are not authorized to create triggers for this template")
+				return fmt.Errorf("not authorized to create trigger")
+			case errors.Is(err, types.ErrorInvalidInput):
+				cli.PrintBoxedErrorMessage(fmt.Sprintf("The trigger could not be created: %s", err))
+				return fmt.Errorf("invalid trigger")
+			default:
+				return fmt.Errorf("failed to create trigger: %w", err)
+			}
+		}
+		if createOutput == nil {
+			return fmt.Errorf("controller returned no data")
+		}
+		trigger := createOutput.Data
+
+		outputFormat := strings.ToLower(viper.GetString("output"))
+		switch outputFormat {
+		case "json":
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(trigger); err != nil {
+				return fmt.Errorf("failed to encode json output: %w", err)
+			}
+		default:
+			webhookUrl := strings.TrimSuffix(controllerUrl, "/") + trigger.WebhookPath
+			authInstructions := ""
+			if trigger.HmacSecret != "" {
+				authInstructions = fmt.Sprintf(
+					"Send the current unix timestamp as `X-Opsicle-Timestamp: <timestamp>`, sign `<timestamp>.<body>` with HMAC-SHA256 using the secret below and send the signature as `X-Opsicle-Signature: sha256=<hex-signature>`\nThis secret will not be shown again:\n\n%s",
+					trigger.HmacSecret,
+				)
+			} else if trigger.OrgTokenId != nil {
+				authInstructions = fmt.Sprintf("Authenticate webhooks with the API key of org token %s as `Authorization: Bearer <token-id>:<api-key>` or as basic auth", *trigger.OrgTokenId)
+			}
+			cli.PrintBoxedSuccessMessage(fmt.Sprintf(
+				"Trigger %s was created with id %s\nWebhooks sent to %s will trigger template[%s]\n%s",
+				trigger.Name,
+				trigger.Id,
+				webhookUrl,
+				trigger.TemplateName,
+				authInstructions,
+			))
+		}
+		return nil
+	},
+})
@@ -0,0 +1,122 @@
+package approval_policy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"opsicle/internal/approvals"
+	"opsicle/internal/cli"
+	"opsicle/internal/common"
+	"opsicle/internal/config"
+	"opsicle/internal/types"
+	"opsicle/pkg/controller"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+var flags cli.Flags = cli.Flags{
+	{
+		Name:         "org",
+		DefaultValue: "",
+		Usage:        "codeword or ID of the organisation the approval policy is in",
+		Type:         cli.FlagTypeString,
+	},
+}.Append(config.GetControllerUrlFlags())
+
+var Command = cli.NewCommand(cli.CommandOpts{
+	Flags:   flags,
+	Use:     "approval-policy <name>",
+	Aliases: []string{"approvalpolicy", "ap"},
+	Short:   "Displays an approval policy of an organisation as an ApprovalPolicy resource",
+	Run: func(cmd *cobra.Command, opts *cli.Command, args []string) error {
+		if len(args) == 0 {
+			return fmt.Errorf("failed to receive an approval policy name")
+		}
+		policyName := strings.TrimSpace(args[0])
+		orgInput := strings.TrimSpace(viper.GetString("org"))
+		if orgInput == "" {
+			cli.PrintBoxedErrorMessage("Specify the organisation the approval policy is in using --org")
+			return fmt.Errorf("org undefined")
+		}
+
+		controllerUrl := viper.GetString("controller-url")
+		methodId := "opsicle/get/approval-policy"
+
+	enforceAuth:
+		sessionToken, err := cli.RequireAuth(controllerUrl, methodId)
+		if err != nil {
+			rootCmd := cmd.Root()
+			rootCmd.SetArgs([]string{"login"})
+			_, execErr := rootCmd.ExecuteC()
+			if execErr != nil {
+				return execErr
+			}
+			goto enforceAuth
+		}
+
+		client, err := controller.NewClient(controller.NewClientOpts{
+			ControllerUrl: controllerUrl,
+			BearerAuth: &controller.NewClientBearerAuthOpts{
+				Token: sessionToken,
+			},
+			Id: methodId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create controller client: %w", err)
+		}
+
+		getOrgOutput, err := client.GetOrgV1(controller.GetOrgV1Input{Ref: orgInput})
+		if err != nil {
+			return fmt.Errorf("failed to retrieve org: %w", err)
+		}
+		policyOutput, err := client.GetOrgApprovalPolicyV1(controller.GetOrgApprovalPolicyV1Input{
+			OrgId: getOrgOutput.Data.Id,
+			Name:  policyName,
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, types.ErrorInsufficientPermissions):
+				cli.PrintBoxedErrorMessage("You are not authorized to view approval policies in this organisation")
+				return fmt.Errorf("not authorized to view approval policy")
+			case errors.Is(err, types.ErrorNotFound):
+				cli.PrintBoxedErrorMessage(fmt.Sprintf("The approval policy '%s' could not be found", policyName))
+				return fmt.Errorf("approval policy not found")
+			default:
+				return fmt.Errorf("failed to retrieve approval policy: %w", err)
+			}
+		}
+		if policyOutput == nil {
+			return fmt.Errorf("controller returned no data")
+		}
+		approvalPolicy := policyOutput.Data
+
+		switch strings.ToLower(viper.GetString("output")) {
+		case "json":
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(approvalPolicy); err != nil {
+				return fmt.Errorf("failed to encode json output: %w", err)
+			}
+		default:
+			resource := approvals.Policy{
+				Resource: common.Resource{
+					ApiVersion: "v1",
+					Type:       approvals.PolicyResourceType,
+					Metadata:   common.Metadata{Name: approvalPolicy.Name},
+				},
+				Spec: approvalPolicy.Spec,
+			}
+			o, err := yaml.Marshal(resource)
+			if err != nil {
+				return fmt.Errorf("failed to encode yaml output: %w", err)
+			}
+			fmt.Print(string(o))
+		}
+		return nil
+	},
+})
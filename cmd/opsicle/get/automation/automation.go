@@ -0,0 +1,168 @@
+package automation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"opsicle/internal/cli"
+	"opsicle/internal/config"
+	"opsicle/internal/types"
+	"opsicle/internal/validate"
+	"opsicle/pkg/controller"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var flags cli.Flags = cli.Flags{}.Append(config.GetControllerUrlFlags())
+
+var Command = cli.NewCommand(cli.CommandOpts{
+	Flags:   flags,
+	Use:     "automation <automation-id>",
+	Aliases: []string{"a"},
+	Short:   "Displays the status of an automation run",
+	Run: func(cmd *cobra.Command, opts *cli.Command, args []string) error {
+		if len(args) == 0 {
+			return fmt.Errorf("failed to receive an automation id")
+		}
+		automationId := strings.TrimSpace(args[0])
+		if err := validate.Uuid(automationId); err != nil {
+			return fmt.Errorf("failed to validate automation id '%s': %w", automationId, err)
+		}
+
+		controllerUrl := viper.GetString("controller-url")
+		methodId := "opsicle/get/automation"
+
+	enforceAuth:
+		sessionToken, err := cli.RequireAuth(controllerUrl, methodId)
+		if err != nil {
+			rootCmd := cmd.Root()
+			rootCmd.SetArgs([]string{"login"})
+			_, execErr := rootCmd.ExecuteC()
+			if execErr != nil {
+				return execErr
+			}
+			goto enforceAuth
+		}
+
+		client, err := controller.NewClient(controller.NewClientOpts{
+			ControllerUrl: controllerUrl,
+			BearerAuth: &controller.NewClientBearerAuthOpts{
+				Token: sessionToken,
+			},
+			Id: methodId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create controller client: %w", err)
+		}
+
+		automationOutput, err := client.GetAutomationV1(controller.GetAutomationV1Input{
+			AutomationId: automationId,
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, types.ErrorInsufficientPermissions):
+				cli.PrintBoxedErrorMessage("You are not authorized to view this automation")
+				return fmt.Errorf("not authorized to view automation")
+			case errors.Is(err, types.ErrorNotFound):
+				cli.PrintBoxedErrorMessage("The automation could not be found")
+				return fmt.Errorf("automation not found")
+			default:
+				return fmt.Errorf("failed to retrieve automation: %w", err)
+			}
+		}
+		if automationOutput == nil {
+			return fmt.Errorf("controller returned no data")
+		}
+		automation := automationOutput.Data
+
+		outputFormat := strings.ToLower(viper.GetString("output"))
+		switch outputFormat {
+		case "json":
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(automation); err != nil {
+				return fmt.Errorf("failed to encode json output: %w", err)
+			}
+		default:
+			orgId := "-"
+			if automation.OrgId != nil {
+				orgId = *automation.OrgId
+			}
+			fmt.Println("")
+			fmt.Printf("Automation ID: %s\n", automation.Id)
+			fmt.Printf("Template: %s (%s, v%v)\n", fallbackString(automation.TemplateName, "-"), automation.TemplateId, automation.TemplateVersion)
+			fmt.Printf("Org ID: %s\n", orgId)
+			fmt.Printf("Status: %s\n", automation.Status)
+			if automation.ParentAutomationId != nil {
+				fmt.Printf("Re-run Of: %s\n", *automation.ParentAutomationId)
+			}
+			fmt.Printf("Triggered By: %s\n", fallbackString(automation.TriggeredByEmail, automation.TriggeredById))
+			fmt.Printf("Triggered At: %s\n", automation.TriggeredAt.Local().Format(cli.TimestampHuman))
+			fmt.Printf("Comment: %s\n", fallbackString(automation.TriggererComment, "-"))
+			fmt.Printf("Started At: %s\n", formatTimestamp(automation.StartedAt))
+			fmt.Printf("Completed At: %s\n", formatTimestamp(automation.CompletedAt))
+			if automation.RunStatus == nil {
+				return nil
+			}
+			if automation.RunStatus.ExitCode != nil {
+				fmt.Printf("Exit Code: %v\n", *automation.RunStatus.ExitCode)
+			}
+			fmt.Printf("Message: %s\n", fallbackString(automation.RunStatus.Message, "-"))
+			fmt.Printf("Worker ID: %s\n", fallbackString(automation.RunStatus.WorkerId, "-"))
+			if len(automation.RunStatus.Phases) == 0 {
+				return nil
+			}
+			fmt.Println("")
+			fmt.Println("Phases:")
+			table := cli.NewTable(cli.NewTableOpts{
+				Headers: []string{"Phase", "Status", "Attempts", "Exit Code", "Duration", "Image Digest", "Message"},
+				Rows: func(t *cli.Table) error {
+					for idx, phase := range automation.RunStatus.Phases {
+						exitCode := "-"
+						if phase.ExitCode != nil {
+							exitCode = fmt.Sprintf("%v", *phase.ExitCode)
+						}
+						duration := "-"
+						if phase.StartedAt != nil && phase.CompletedAt != nil {
+							duration = phase.CompletedAt.Sub(*phase.StartedAt).Round(time.Millisecond).String()
+						}
+						if err := t.NewRow(
+							phase.Name,
+							string(phase.Status),
+							fmt.Sprintf("%v", phase.Attempts),
+							exitCode,
+							duration,
+							fallbackString(phase.ImageDigest, "-"),
+							fallbackString(phase.Message, "-"),
+						); err != nil {
+							return fmt.Errorf("failed to insert phase row[%d]: %w", idx, err)
+						}
+					}
+					return nil
+				},
+			}).Render()
+			fmt.Println(table.GetString())
+		}
+
+		return nil
+	},
+})
+
+func fallbackString(value string, fallback string) string {
+	if strings.TrimSpace(value) == "" {
+		return fallback
+	}
+	return value
+}
+
+func formatTimestamp(timestamp *time.Time) string {
+	if timestamp == nil {
+		return "-"
+	}
+	return timestamp.Local().Format(cli.TimestampHuman)
+}
@@ -0,0 +1,131 @@
+package automation_artifacts
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"opsicle/internal/cli"
+	"opsicle/internal/config"
+	"opsicle/internal/types"
+	"opsicle/internal/validate"
+	"opsicle/pkg/controller"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var flags cli.Flags = cli.Flags{
+	{
+		Name:         "out-file",
+		Short:        'f',
+		DefaultValue: "",
+		Usage:        "path to save the artifacts tarball to, defaults to <automation-id>.tar.gz",
+		Type:         cli.FlagTypeString,
+	},
+}.Append(config.GetControllerUrlFlags())
+
+var Command = cli.NewCommand(cli.CommandOpts{
+	Flags:   flags,
+	Use:     "automation-artifacts <automation-id>",
+	Aliases: []string{"artifacts", "aa"},
+	Short:   "Downloads the artifacts produced by an automation run",
+	Run: func(cmd *cobra.Command, opts *cli.Command, args []string) error {
+		if len(args) == 0 {
+			return fmt.Errorf("failed to receive an automation id")
+		}
+		automationId := strings.TrimSpace(args[0])
+		if err := validate.Uuid(automationId); err != nil {
+			return fmt.Errorf("failed to validate automation id '%s': %w", automationId, err)
+		}
+		outFile := viper.GetString("out-file")
+		if outFile == "" {
+			outFile = fmt.Sprintf("%s.tar.gz", automationId)
+		}
+
+		controllerUrl := viper.GetString("controller-url")
+		methodId := "opsicle/get/automation-artifacts"
+
+	enforceAuth:
+		sessionToken, err := cli.RequireAuth(controllerUrl, methodId)
+		if err != nil {
+			rootCmd := cmd.Root()
+			rootCmd.SetArgs([]string{"login"})
+			_, execErr := rootCmd.ExecuteC()
+			if execErr != nil {
+				return execErr
+			}
+			goto enforceAuth
+		}
+
+		client, err := controller.NewClient(controller.NewClientOpts{
+			ControllerUrl: controllerUrl,
+			BearerAuth: &controller.NewClientBearerAuthOpts{
+				Token: sessionToken,
+			},
+			Id: methodId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create controller client: %w", err)
+		}
+
+		artifactsOutput, err := client.GetAutomationArtifactsV1(controller.GetAutomationArtifactsV1Input{
+			AutomationId: automationId,
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, types.ErrorInsufficientPermissions):
+				cli.PrintBoxedErrorMessage("You are not authorized to view this automation")
+				return fmt.Errorf("not authorized to view automation")
+			case errors.Is(err, types.ErrorNotFound):
+				cli.PrintBoxedErrorMessage("The automation could not be found or has no artifacts")
+				return fmt.Errorf("artifacts not found")
+			default:
+				return fmt.Errorf("failed to retrieve artifacts: %w", err)
+			}
+		}
+		if artifactsOutput == nil {
+			return fmt.Errorf("controller returned no data")
+		}
+		artifacts := artifactsOutput.Data
+		checksum := sha256.Sum256(artifacts.Content)
+		if hex.EncodeToString(checksum[:]) != artifacts.Checksum {
+			return fmt.Errorf("checksum of downloaded artifacts does not match checksum[%s]", artifacts.Checksum)
+		}
+		if err := os.WriteFile(outFile, artifacts.Content, 0o644); err != nil {
+			return fmt.Errorf("failed to write artifacts to path[%s]: %w", outFile, err)
+		}
+		absoluteOutFile, err := filepath.Abs(outFile)
+		if err != nil {
+			absoluteOutFile = outFile
+		}
+
+		outputFormat := strings.ToLower(viper.GetString("output"))
+		switch outputFormat {
+		case "json":
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(map[string]any{
+				"path":      absoluteOutFile,
+				"size":      artifacts.Size,
+				"checksum":  artifacts.Checksum,
+				"createdAt": artifacts.CreatedAt,
+			}); err != nil {
+				return fmt.Errorf("failed to encode json output: %w", err)
+			}
+		default:
+			cli.PrintBoxedSuccessMessage(fmt.Sprintf(
+				"Saved %v bytes of artifacts to %s\nsha256: %s",
+				artifacts.Size,
+				absoluteOutFile,
+				artifacts.Checksum,
+			))
+		}
+		return nil
+	},
+})
@@ -2,7 +2,10 @@ package get
 
 import (
 	"opsicle/cmd/opsicle/get/approval"
+	"opsicle/cmd/opsicle/get/approval_policy"
 	"opsicle/cmd/opsicle/get/approval_request"
+	"opsicle/cmd/opsicle/get/automation"
+	"opsicle/cmd/opsicle/get/automation_artifacts"
 	"opsicle/cmd/opsicle/get/org"
 	"opsicle/cmd/opsicle/get/users"
 
@@ -11,7 +14,10 @@ import (
 
 func init() {
 	Command.AddCommand(approval.Command)
+	Command.AddCommand(approval_policy.Command.Get())
 	Command.AddCommand(approval_request.Command)
+	Command.AddCommand(automation.Command.Get())
+	Command.AddCommand(automation_artifacts.Command.Get())
 	Command.AddCommand(org.Command.Get())
 	Command.AddCommand(users.Command)
 }
@@ -0,0 +1,117 @@
+package approval_policies
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"opsicle/internal/cli"
+	"opsicle/internal/config"
+	"opsicle/pkg/controller"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/term"
+)
+
+var flags cli.Flags = cli.Flags{
+	{
+		Name:         "org",
+		DefaultValue: "",
+		Usage:        "codeword or ID of the organisation to list approval policies from",
+		Type:         cli.FlagTypeString,
+	},
+}.Append(config.GetControllerUrlFlags())
+
+var Command = cli.NewCommand(cli.CommandOpts{
+	Flags:   flags,
+	Use:     "approval-policies",
+	Aliases: []string{"approval-policy", "approvalpolicies", "aps"},
+	Short:   "Lists approval policies which templates in an organisation can reference",
+	Run: func(cmd *cobra.Command, opts *cli.Command, args []string) error {
+		orgInput := strings.TrimSpace(viper.GetString("org"))
+		if orgInput == "" {
+			cli.PrintBoxedErrorMessage("Specify the organisation to list approval policies from using --org")
+			return fmt.Errorf("org undefined")
+		}
+
+		controllerUrl := viper.GetString("controller-url")
+		methodId := "opsicle/list/approval-policies"
+
+	enforceAuth:
+		sessionToken, err := cli.RequireAuth(controllerUrl, methodId)
+		if err != nil {
+			rootCmd := cmd.Root()
+			rootCmd.SetArgs([]string{"login"})
+			_, execErr := rootCmd.ExecuteC()
+			if execErr != nil {
+				return execErr
+			}
+			goto enforceAuth
+		}
+
+		client, err := controller.NewClient(controller.NewClientOpts{
+			ControllerUrl: controllerUrl,
+			BearerAuth: &controller.NewClientBearerAuthOpts{
+				Token: sessionToken,
+			},
+			Id: methodId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create controller client: %w", err)
+		}
+
+		getOrgOutput, err := client.GetOrgV1(controller.GetOrgV1Input{Ref: orgInput})
+		if err != nil {
+			return fmt.Errorf("failed to retrieve org: %w", err)
+		}
+		policies, err := client.ListOrgApprovalPoliciesV1(controller.ListOrgApprovalPoliciesV1Input{
+			OrgId: getOrgOutput.Data.Id,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list approval policies: %w", err)
+		}
+
+		if len(policies.Data) == 0 {
+			cli.PrintBoxedInfoMessage(
+				"There aren't any approval policies, create one using `opsicle create approval-policy` and check back here",
+			)
+			return nil
+		}
+
+		switch viper.GetString("output") {
+		case "json":
+			o, _ := json.MarshalIndent(policies.Data, "", "  ")
+			fmt.Println(string(o))
+		default:
+			var displayOut bytes.Buffer
+			table := tablewriter.NewWriter(&displayOut)
+			table.Configure(func(cfg *tablewriter.Config) {
+				width, _, _ := term.GetSize(int(os.Stdout.Fd()))
+				cfg.MaxWidth = width
+			})
+			table.Header("id", "name", "stages", "self approval", "last updated at", "last updated by")
+			for _, policy := range policies.Data {
+				stages := fmt.Sprintf("%v", len(policy.Spec.GetRequestStages()))
+				if len(policy.Spec.Stages) == 0 {
+					stages = "-"
+				}
+				selfApproval := "allowed"
+				if policy.Spec.DisallowSelfApproval {
+					selfApproval = "disallowed"
+				}
+				lastUpdatedBy := "-"
+				if policy.LastUpdatedBy != nil {
+					lastUpdatedBy = policy.LastUpdatedBy.Email
+				}
+				table.Append([]string{policy.Id, policy.Name, stages, selfApproval, policy.LastUpdatedAt.Local().Format(cli.TimestampHuman), lastUpdatedBy})
+			}
+			table.Render()
+			fmt.Println(displayOut.String())
+		}
+		return nil
+	},
+})
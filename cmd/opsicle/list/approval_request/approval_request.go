@@ -3,10 +3,13 @@ package approval_request
 import (
 	"encoding/json"
 	"fmt"
+	"opsicle/internal/approvals"
 	"opsicle/internal/cli"
 	"opsicle/internal/common"
 	approverApi "opsicle/pkg/approver"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -21,6 +24,42 @@ var flags cli.Flags = cli.Flags{
 		Usage:        "defines the url where the approver service is accessible at",
 		Type:         cli.FlagTypeString,
 	},
+	{
+		Name:         "status",
+		DefaultValue: "",
+		Usage:        "lists only approval requests in this status (one of pending, approved, rejected or expired)",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "requester",
+		DefaultValue: "",
+		Usage:        "lists only approval requests whose requester has this ID or name",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "platform",
+		DefaultValue: "",
+		Usage:        "lists only approval requests sent to this platform (one of slack or telegram)",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "since",
+		DefaultValue: "",
+		Usage:        "lists only approval requests created at or after this local time; Format it as {YYYY}-{MM}-{DD}T{HH}:{mm}:{ss}",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "until",
+		DefaultValue: "",
+		Usage:        "lists only approval requests created before this local time; Format it as {YYYY}-{MM}-{DD}T{HH}:{mm}:{ss}",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "limit",
+		DefaultValue: 20,
+		Usage:        "Defines how many approval requests to return",
+		Type:         cli.FlagTypeInteger,
+	},
 }
 
 func init() {
@@ -30,7 +69,7 @@ func init() {
 var Command = &cobra.Command{
 	Use:     "approval-request",
 	Aliases: []string{"approval-requests", "approvalrequests", "appovreq", "appreq", "reqs", "req", "ar"},
-	Short:   "Retrieves ApprovalRequest keys from the approver service",
+	Short:   "Retrieves ApprovalRequests from the approver service, latest first",
 	PreRun: func(cmd *cobra.Command, args []string) {
 		flags.BindViper(cmd)
 	},
@@ -53,12 +92,31 @@ var Command = &cobra.Command{
 			return fmt.Errorf("failed to create client for approver service: %w", err)
 		}
 
-		approvalRequestUuids, err := client.ListApprovalRequests()
+		filter := approvals.RequestFilter{
+			Status:    approvals.Status(strings.TrimSpace(viper.GetString("status"))),
+			Requester: strings.TrimSpace(viper.GetString("requester")),
+			Platform:  approvals.Platform(strings.TrimSpace(viper.GetString("platform"))),
+			Limit:     viper.GetInt("limit"),
+		}
+		for flag, timestamp := range map[string]**time.Time{"since": &filter.CreatedAfter, "until": &filter.CreatedBefore} {
+			if input := strings.TrimSpace(viper.GetString(flag)); input != "" {
+				parsedTimestamp, err := time.ParseInLocation(cli.TimestampSystem, input, time.Local)
+				if err != nil {
+					return fmt.Errorf("--%s date format invalid", flag)
+				}
+				*timestamp = &parsedTimestamp
+			}
+		}
+		if err := filter.Validate(); err != nil {
+			return fmt.Errorf("invalid filters: %w", err)
+		}
+
+		approvalRequests, err := client.ListApprovalRequests(filter)
 		if err != nil {
 			return fmt.Errorf("failed to retrieve approval requests: %w", err)
 		}
 
-		o, _ := json.MarshalIndent(approvalRequestUuids, "", "  ")
+		o, _ := json.MarshalIndent(approvalRequests, "", "  ")
 		fmt.Println(string(o))
 		return nil
 	},
@@ -0,0 +1,247 @@
+package automations
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"opsicle/internal/cli"
+	"opsicle/internal/config"
+	"opsicle/pkg/controller"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+var flags cli.Flags = cli.Flags{
+	{
+		Name:         "org",
+		DefaultValue: "",
+		Usage:        "codeword or ID of the organisation to list automations from, automations you triggered are listed when not specified",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "template",
+		DefaultValue: "",
+		Usage:        "name or ID of the template to list automations of",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "status",
+		DefaultValue: "",
+		Usage:        "lists only automations in this status (eg. executing, completed-failed)",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "triggered-by",
+		DefaultValue: "",
+		Usage:        "email or ID of the user who triggered the automations, only applicable with --org",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "since",
+		DefaultValue: "",
+		Usage:        "lists only automations triggered at or after this local time; Format it as {YYYY}-{MM}-{DD}T{HH}:{mm}:{ss}",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "until",
+		DefaultValue: "",
+		Usage:        "lists only automations triggered before this local time; Format it as {YYYY}-{MM}-{DD}T{HH}:{mm}:{ss}",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "comment",
+		DefaultValue: "",
+		Usage:        "lists only automations whose comment contains this text",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "limit",
+		DefaultValue: 20,
+		Usage:        "Defines how many automations to return",
+		Type:         cli.FlagTypeInteger,
+	},
+	{
+		Name:         "cursor",
+		DefaultValue: "",
+		Usage:        "cursor returned by a previous listing to continue from",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "wide",
+		Short:        'w',
+		DefaultValue: false,
+		Usage:        "Displays more information",
+		Type:         cli.FlagTypeBool,
+	},
+}.Append(config.GetControllerUrlFlags())
+
+var Command = cli.NewCommand(cli.CommandOpts{
+	Flags:   flags,
+	Use:     "automations",
+	Aliases: []string{"automation", "runs"},
+	Short:   "Lists automation runs, latest first",
+	Run: func(cmd *cobra.Command, opts *cli.Command, args []string) error {
+		controllerUrl := viper.GetString("controller-url")
+		methodId := "opsicle/list/automations"
+
+	enforceAuth:
+		sessionToken, err := cli.RequireAuth(controllerUrl, methodId)
+		if err != nil {
+			rootCmd := cmd.Root()
+			rootCmd.SetArgs([]string{"login"})
+			_, execErr := rootCmd.ExecuteC()
+			if execErr != nil {
+				return execErr
+			}
+			goto enforceAuth
+		}
+
+		client, err := controller.NewClient(controller.NewClientOpts{
+			ControllerUrl: controllerUrl,
+			BearerAuth: &controller.NewClientBearerAuthOpts{
+				Token: sessionToken,
+			},
+			Id: methodId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create controller client: %w", err)
+		}
+
+		listInput := controller.ListAutomationsV1Input{
+			Status:      strings.TrimSpace(viper.GetString("status")),
+			TriggeredBy: strings.TrimSpace(viper.GetString("triggered-by")),
+			Comment:     strings.TrimSpace(viper.GetString("comment")),
+			Cursor:      strings.TrimSpace(viper.GetString("cursor")),
+			Limit:       viper.GetInt("limit"),
+		}
+		for flag, timestamp := range map[string]**time.Time{"since": &listInput.Since, "until": &listInput.Until} {
+			if input := strings.TrimSpace(viper.GetString(flag)); input != "" {
+				parsedTimestamp, err := time.ParseInLocation(cli.TimestampSystem, input, time.Local)
+				if err != nil {
+					return fmt.Errorf("--%s date format invalid", flag)
+				}
+				*timestamp = &parsedTimestamp
+			}
+		}
+		templateInput := strings.TrimSpace(viper.GetString("template"))
+		if orgInput := strings.TrimSpace(viper.GetString("org")); orgInput != "" {
+			getOrgOutput, err := client.GetOrgV1(controller.GetOrgV1Input{Ref: orgInput})
+			if err != nil {
+				return fmt.Errorf("failed to retrieve org: %w", err)
+			}
+			listInput.OrgId = &getOrgOutput.Data.Id
+			if templateInput != "" {
+				templateInstance, err := cli.HandleOrgTemplateSelection(cli.HandleOrgTemplateSelectionOpts{
+					Client:    client,
+					OrgId:     *listInput.OrgId,
+					UserInput: templateInput,
+				})
+				if err != nil {
+					return fmt.Errorf("failed to select a template: %w", err)
+				}
+				listInput.TemplateId = templateInstance.Id
+			}
+		} else if templateInput != "" {
+			templateInstance, err := cli.HandleTemplateSelection(cli.HandleTemplateSelectionOpts{
+				Client:    client,
+				UserInput: templateInput,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to select a template: %w", err)
+			}
+			listInput.TemplateId = templateInstance.Id
+		}
+
+		automationsOutput, err := client.ListAutomationsV1(listInput)
+		if err != nil {
+			return fmt.Errorf("failed to list automations: %w", err)
+		}
+		automations := automationsOutput.Data
+
+		switch viper.GetString("output") {
+		case "json":
+			o, _ := json.MarshalIndent(automations, "", "  ")
+			fmt.Println(string(o))
+			return nil
+		case "yaml":
+			// round-trip through json so that keys match the json output
+			var data any
+			jsonData, _ := json.Marshal(automations)
+			if err := json.Unmarshal(jsonData, &data); err != nil {
+				return fmt.Errorf("failed to encode yaml output: %w", err)
+			}
+			o, err := yaml.Marshal(data)
+			if err != nil {
+				return fmt.Errorf("failed to encode yaml output: %w", err)
+			}
+			fmt.Print(string(o))
+			return nil
+		}
+
+		if len(automations.Automations) == 0 {
+			cli.PrintBoxedInfoMessage(
+				"There aren't any automations matching your filters",
+			)
+			return nil
+		}
+		headers := []string{"id", "template", "status", "triggered by", "triggered at"}
+		if viper.GetBool("wide") {
+			headers = append(headers, "version", "started at", "completed at", "re-run of", "comment")
+		}
+		table := cli.NewTable(cli.NewTableOpts{
+			Headers: headers,
+			Rows: func(t *cli.Table) error {
+				for idx, automation := range automations.Automations {
+					row := []any{
+						automation.Id,
+						fallbackString(automation.TemplateName, automation.TemplateId),
+						automation.Status,
+						fallbackString(automation.TriggeredByEmail, automation.TriggeredById),
+						automation.TriggeredAt.Local().Format(cli.TimestampHuman),
+					}
+					if viper.GetBool("wide") {
+						parentAutomationId := "-"
+						if automation.ParentAutomationId != nil {
+							parentAutomationId = *automation.ParentAutomationId
+						}
+						row = append(
+							row,
+							fmt.Sprintf("%v", automation.TemplateVersion),
+							formatTimestamp(automation.StartedAt),
+							formatTimestamp(automation.CompletedAt),
+							parentAutomationId,
+							fallbackString(automation.TriggererComment, "-"),
+						)
+					}
+					if err := t.NewRow(row...); err != nil {
+						return fmt.Errorf("failed to insert automation row[%d]: %w", idx, err)
+					}
+				}
+				return nil
+			},
+		}).Render()
+		fmt.Println(table.GetString())
+		if automations.NextCursor != nil {
+			fmt.Printf("💡 There are more automations, continue with --cursor %s\n", *automations.NextCursor)
+		}
+		return nil
+	},
+})
+
+func fallbackString(value string, fallback string) string {
+	if strings.TrimSpace(value) == "" {
+		return fallback
+	}
+	return value
+}
+
+func formatTimestamp(timestamp *time.Time) string {
+	if timestamp == nil {
+		return "-"
+	}
+	return timestamp.Local().Format(cli.TimestampHuman)
+}
@@ -1,19 +1,27 @@
 package list
 
 import (
+	"opsicle/cmd/opsicle/list/approval_policies"
 	"opsicle/cmd/opsicle/list/approval_request"
 	"opsicle/cmd/opsicle/list/audit_logs"
+	"opsicle/cmd/opsicle/list/automations"
 	"opsicle/cmd/opsicle/list/orgs"
+	"opsicle/cmd/opsicle/list/schedules"
 	"opsicle/cmd/opsicle/list/templates"
+	"opsicle/cmd/opsicle/list/triggers"
 
 	"github.com/spf13/cobra"
 )
 
 func init() {
+	Command.AddCommand(approval_policies.Command.Get())
 	Command.AddCommand(approval_request.Command)
 	Command.AddCommand(audit_logs.Command)
+	Command.AddCommand(automations.Command.Get())
 	Command.AddCommand(orgs.Command)
+	Command.AddCommand(schedules.Command.Get())
 	Command.AddCommand(templates.Command)
+	Command.AddCommand(triggers.Command.Get())
 }
 
 var Command = &cobra.Command{
@@ -0,0 +1,140 @@
+package schedules
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"opsicle/internal/cli"
+	"opsicle/internal/config"
+	"opsicle/pkg/controller"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/term"
+)
+
+var flags cli.Flags = cli.Flags{
+	{
+		Name:         "org",
+		DefaultValue: "",
+		Usage:        "codeword or ID of the organisation to list schedules from, your own schedules are listed when not specified",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "wide",
+		Short:        'w',
+		DefaultValue: false,
+		Usage:        "Displays more information",
+		Type:         cli.FlagTypeBool,
+	},
+}.Append(config.GetControllerUrlFlags())
+
+var Command = cli.NewCommand(cli.CommandOpts{
+	Flags:   flags,
+	Use:     "schedules",
+	Aliases: []string{"schedule", "sched"},
+	Short:   "Lists schedules which trigger automations",
+	Run: func(cmd *cobra.Command, opts *cli.Command, args []string) error {
+		controllerUrl := viper.GetString("controller-url")
+		methodId := "opsicle/list/schedules"
+
+	enforceAuth:
+		sessionToken, err := cli.RequireAuth(controllerUrl, methodId)
+		if err != nil {
+			rootCmd := cmd.Root()
+			rootCmd.SetArgs([]string{"login"})
+			_, execErr := rootCmd.ExecuteC()
+			if execErr != nil {
+				return execErr
+			}
+			goto enforceAuth
+		}
+
+		client, err := controller.NewClient(controller.NewClientOpts{
+			ControllerUrl: controllerUrl,
+			BearerAuth: &controller.NewClientBearerAuthOpts{
+				Token: sessionToken,
+			},
+			Id: methodId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create controller client: %w", err)
+		}
+
+		listInput := controller.ListAutomationSchedulesV1Input{}
+		if orgInput := strings.TrimSpace(viper.GetString("org")); orgInput != "" {
+			getOrgOutput, err := client.GetOrgV1(controller.GetOrgV1Input{Ref: orgInput})
+			if err != nil {
+				return fmt.Errorf("failed to retrieve org: %w", err)
+			}
+			listInput.OrgId = &getOrgOutput.Data.Id
+		}
+		schedules, err := client.ListAutomationSchedulesV1(listInput)
+		if err != nil {
+			return fmt.Errorf("failed to list schedules: %w", err)
+		}
+
+		if len(schedules.Data) == 0 {
+			cli.PrintBoxedInfoMessage(
+				"There aren't any schedules, create one using `opsicle create schedule` and check back here",
+			)
+			return nil
+		}
+
+		switch viper.GetString("output") {
+		case "json":
+			o, _ := json.MarshalIndent(schedules.Data, "", "  ")
+			fmt.Println(string(o))
+		default:
+			var displayOut bytes.Buffer
+			table := tablewriter.NewWriter(&displayOut)
+			table.Configure(func(cfg *tablewriter.Config) {
+				width, _, _ := term.GetSize(int(os.Stdout.Fd()))
+				cfg.MaxWidth = width
+			})
+			if viper.GetBool("wide") {
+				table.Header("id", "name", "template", "version", "cron", "timezone", "status", "next run at", "last run at", "last automation", "created by")
+			} else {
+				table.Header("id", "name", "template", "cron", "timezone", "status", "next run at")
+			}
+			for _, schedule := range schedules.Data {
+				status := "active"
+				if schedule.IsPaused {
+					status = "paused"
+				}
+				nextRunAt := "-"
+				if schedule.NextRunAt != nil && !schedule.IsPaused {
+					nextRunAt = schedule.NextRunAt.Local().Format(cli.TimestampHuman)
+				}
+				if !viper.GetBool("wide") {
+					table.Append([]string{schedule.Id, schedule.Name, schedule.TemplateName, schedule.CronExpression, schedule.Timezone, status, nextRunAt})
+					continue
+				}
+				templateVersion := "latest"
+				if schedule.TemplateVersion != nil {
+					templateVersion = fmt.Sprintf("%v", *schedule.TemplateVersion)
+				}
+				lastRunAt := "-"
+				if schedule.LastRunAt != nil {
+					lastRunAt = schedule.LastRunAt.Local().Format(cli.TimestampHuman)
+				}
+				lastAutomationId := "-"
+				if schedule.LastAutomationId != nil {
+					lastAutomationId = *schedule.LastAutomationId
+				}
+				createdBy := "-"
+				if schedule.CreatedBy != nil {
+					createdBy = schedule.CreatedBy.Email
+				}
+				table.Append([]string{schedule.Id, schedule.Name, schedule.TemplateName, templateVersion, schedule.CronExpression, schedule.Timezone, status, nextRunAt, lastRunAt, lastAutomationId, createdBy})
+			}
+			table.Render()
+			fmt.Println(displayOut.String())
+		}
+		return nil
+	},
+})
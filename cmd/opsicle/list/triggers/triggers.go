@@ -0,0 +1,140 @@
+package triggers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"opsicle/internal/cli"
+	"opsicle/internal/config"
+	"opsicle/pkg/controller"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/term"
+)
+
+var flags cli.Flags = cli.Flags{
+	{
+		Name:         "org",
+		DefaultValue: "",
+		Usage:        "codeword or ID of the organisation to list triggers from, your own triggers are listed when not specified",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "wide",
+		Short:        'w',
+		DefaultValue: false,
+		Usage:        "Displays more information",
+		Type:         cli.FlagTypeBool,
+	},
+}.Append(config.GetControllerUrlFlags())
+
+var Command = cli.NewCommand(cli.CommandOpts{
+	Flags:   flags,
+	Use:     "triggers",
+	Aliases: []string{"trigger", "webhooks"},
+	Short:   "Lists triggers which run automations when webhooks are received",
+	Run: func(cmd *cobra.Command, opts *cli.Command, args []string) error {
+		controllerUrl := viper.GetString("controller-url")
+		methodId := "opsicle/list/triggers"
+
+	enforceAuth:
+		sessionToken, err := cli.RequireAuth(controllerUrl, methodId)
+		if err != nil {
+			rootCmd := cmd.Root()
+			rootCmd.SetArgs([]string{"login"})
+			_, execErr := rootCmd.ExecuteC()
+			if execErr != nil {
+				return execErr
+			}
+			goto enforceAuth
+		}
+
+		client, err := controller.NewClient(controller.NewClientOpts{
+			ControllerUrl: controllerUrl,
+			BearerAuth: &controller.NewClientBearerAuthOpts{
+				Token: sessionToken,
+			},
+			Id: methodId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create controller client: %w", err)
+		}
+
+		listInput := controller.ListTemplateTriggersV1Input{}
+		if orgInput := strings.TrimSpace(viper.GetString("org")); orgInput != "" {
+			getOrgOutput, err := client.GetOrgV1(controller.GetOrgV1Input{Ref: orgInput})
+			if err != nil {
+				return fmt.Errorf("failed to retrieve org: %w", err)
+			}
+			listInput.OrgId = &getOrgOutput.Data.Id
+		}
+		triggers, err := client.ListTemplateTriggersV1(listInput)
+		if err != nil {
+			return fmt.Errorf("failed to list triggers: %w", err)
+		}
+
+		if len(triggers.Data) == 0 {
+			cli.PrintBoxedInfoMessage(
+				"There aren't any triggers, create one using `opsicle create trigger` and check back here",
+			)
+			return nil
+		}
+
+		switch viper.GetString("output") {
+		case "json":
+			o, _ := json.MarshalIndent(triggers.Data, "", "  ")
+			fmt.Println(string(o))
+		default:
+			var displayOut bytes.Buffer
+			table := tablewriter.NewWriter(&displayOut)
+			table.Configure(func(cfg *tablewriter.Config) {
+				width, _, _ := term.GetSize(int(os.Stdout.Fd()))
+				cfg.MaxWidth = width
+			})
+			if viper.GetBool("wide") {
+				table.Header("id", "name", "template", "version", "auth", "dedup key", "rate limit", "webhook path", "last triggered at", "last automation", "created by")
+			} else {
+				table.Header("id", "name", "template", "auth", "last triggered at")
+			}
+			for _, trigger := range triggers.Data {
+				lastTriggeredAt := "-"
+				if trigger.LastTriggeredAt != nil {
+					lastTriggeredAt = trigger.LastTriggeredAt.Local().Format(cli.TimestampHuman)
+				}
+				if !viper.GetBool("wide") {
+					table.Append([]string{trigger.Id, trigger.Name, trigger.TemplateName, trigger.AuthType, lastTriggeredAt})
+					continue
+				}
+				templateVersion := "latest"
+				if trigger.TemplateVersion != nil {
+					templateVersion = fmt.Sprintf("%v", *trigger.TemplateVersion)
+				}
+				dedupKey := "-"
+				if trigger.DedupKey != nil {
+					dedupKey = fmt.Sprintf("%s (%s)", *trigger.DedupKey, trigger.DedupWindow)
+				}
+				rateLimit := "-"
+				if trigger.RateLimit > 0 {
+					rateLimit = fmt.Sprintf("%v per %s", trigger.RateLimit, trigger.RateLimitWindow)
+				}
+				lastAutomationId := "-"
+				if trigger.LastAutomationId != nil {
+					lastAutomationId = *trigger.LastAutomationId
+				}
+				createdBy := "-"
+				if trigger.CreatedBy != nil {
+					createdBy = trigger.CreatedBy.Email
+				}
+				table.Append([]string{trigger.Id, trigger.Name, trigger.TemplateName, templateVersion, trigger.AuthType, dedupKey, rateLimit, trigger.WebhookPath, lastTriggeredAt, lastAutomationId, createdBy})
+			}
+			table.Render()
+			fmt.Println(displayOut.String())
+		}
+		return nil
+	},
+})
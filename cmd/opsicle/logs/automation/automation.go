@@ -0,0 +1,203 @@
+package automation
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"opsicle/internal/automations"
+	"opsicle/internal/cli"
+	"opsicle/internal/config"
+	"opsicle/internal/types"
+	"opsicle/internal/validate"
+	"opsicle/pkg/controller"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	streamAll    = "all"
+	streamStdout = "stdout"
+	streamStderr = "stderr"
+
+	// idlePollsBeforeExit is the number of polls without new logs after
+	// the automation has completed before following stops, this allows
+	// logs still in flight to be displayed
+	idlePollsBeforeExit = 3
+
+	// logsWaitDuration is how long the controller holds a request for
+	// logs open when --follow is specified and no new logs are available
+	logsWaitDuration = 5 * time.Second
+)
+
+var flags cli.Flags = cli.Flags{
+	{
+		Name:         "follow",
+		Short:        'f',
+		DefaultValue: false,
+		Usage:        "when specified, logs are streamed until the automation completes",
+		Type:         cli.FlagTypeBool,
+	},
+	{
+		Name:         "poll-interval",
+		DefaultValue: time.Second,
+		Usage:        "duration between checks for remaining logs after the automation completes when --follow is specified",
+		Type:         cli.FlagTypeDuration,
+	},
+	{
+		Name:         "stream",
+		DefaultValue: streamAll,
+		Usage:        "one of 'all', 'stdout' or 'stderr'; stdout logs are written to stdout and stderr logs are written to stderr",
+		Type:         cli.FlagTypeString,
+	},
+}.Append(config.GetControllerUrlFlags())
+
+var Command = cli.NewCommand(cli.CommandOpts{
+	Flags:   flags,
+	Use:     "automation <automation-id>",
+	Aliases: []string{"a"},
+	Short:   "Displays logs of an automation run",
+	Run: func(cmd *cobra.Command, opts *cli.Command, args []string) error {
+		if len(args) == 0 {
+			return fmt.Errorf("failed to receive an automation id")
+		}
+		automationId := strings.TrimSpace(args[0])
+		if err := validate.Uuid(automationId); err != nil {
+			return fmt.Errorf("failed to validate automation id '%s': %w", automationId, err)
+		}
+		stream := strings.ToLower(viper.GetString("stream"))
+		switch stream {
+		case streamAll, streamStdout, streamStderr:
+		default:
+			return fmt.Errorf("failed to validate stream '%s': expected one of 'all', 'stdout' or 'stderr'", stream)
+		}
+		isFollowing := viper.GetBool("follow")
+		pollInterval := viper.GetDuration("poll-interval")
+
+		controllerUrl := viper.GetString("controller-url")
+		methodId := "opsicle/logs/automation"
+
+	enforceAuth:
+		sessionToken, err := cli.RequireAuth(controllerUrl, methodId)
+		if err != nil {
+			rootCmd := cmd.Root()
+			rootCmd.SetArgs([]string{"login"})
+			_, execErr := rootCmd.ExecuteC()
+			if execErr != nil {
+				return execErr
+			}
+			goto enforceAuth
+		}
+
+		client, err := controller.NewClient(controller.NewClientOpts{
+			ControllerUrl: controllerUrl,
+			BearerAuth: &controller.NewClientBearerAuthOpts{
+				Token: sessionToken,
+			},
+			Id:             methodId,
+			RequestTimeout: logsWaitDuration + controller.DefaultClientTimeout,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create controller client: %w", err)
+		}
+
+		waitDuration := time.Duration(0)
+		if isFollowing {
+			waitDuration = logsWaitDuration
+		}
+		printer := logsPrinter{
+			Stream: stream,
+			Stdout: os.Stdout,
+			Stderr: os.Stderr,
+		}
+		offset := 0
+		idlePolls := 0
+		for {
+			logsOutput, err := client.GetAutomationLogsV1(controller.GetAutomationLogsV1Input{
+				AutomationId: automationId,
+				Offset:       offset,
+				Wait:         waitDuration,
+			})
+			if err != nil {
+				switch {
+				case errors.Is(err, types.ErrorInsufficientPermissions):
+					cli.PrintBoxedErrorMessage("You are not authorized to view logs of this automation")
+					return fmt.Errorf("not authorized to view automation logs")
+				case errors.Is(err, types.ErrorNotFound):
+					cli.PrintBoxedErrorMessage("The automation could not be found")
+					return fmt.Errorf("automation not found")
+				default:
+					return fmt.Errorf("failed to retrieve automation logs: %w", err)
+				}
+			}
+			hasNewLogs := logsOutput.Data.Logs != ""
+			printer.Write(logsOutput.Data.Logs)
+			offset = logsOutput.Data.Offset
+			if !isFollowing {
+				break
+			}
+			if logsOutput.Data.IsComplete && !hasNewLogs {
+				idlePolls++
+				if idlePolls >= idlePollsBeforeExit {
+					break
+				}
+				<-time.After(pollInterval)
+			} else {
+				idlePolls = 0
+			}
+		}
+		printer.Flush()
+		return nil
+	},
+})
+
+// logsPrinter writes automation logs line-by-line to the stdout/stderr
+// writers based on the `[stderr]` prefix of each line, partial lines
+// are held until they are completed or until Flush is called
+type logsPrinter struct {
+	Stream string
+	Stdout io.Writer
+	Stderr io.Writer
+
+	partialLine string
+}
+
+func (p *logsPrinter) Write(logs string) {
+	logs = p.partialLine + logs
+	p.partialLine = ""
+	lines := strings.SplitAfter(logs, "\n")
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		if !strings.HasSuffix(line, "\n") {
+			p.partialLine = line
+			continue
+		}
+		p.writeLine(line)
+	}
+}
+
+func (p *logsPrinter) Flush() {
+	if p.partialLine != "" {
+		p.writeLine(p.partialLine + "\n")
+		p.partialLine = ""
+	}
+}
+
+func (p *logsPrinter) writeLine(line string) {
+	isStderr, line := automations.IsStderrLine(line)
+	if isStderr {
+		if p.Stream != streamStdout {
+			fmt.Fprint(p.Stderr, line)
+		}
+		return
+	}
+	if p.Stream != streamStderr {
+		fmt.Fprint(p.Stdout, line)
+	}
+}
@@ -0,0 +1,19 @@
+package logs
+
+import (
+	"opsicle/cmd/opsicle/logs/automation"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	Command.AddCommand(automation.Command.Get())
+}
+
+var Command = &cobra.Command{
+	Use:   "logs",
+	Short: "Retrieves logs of resources in Opsicle",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cmd.Help()
+	},
+}
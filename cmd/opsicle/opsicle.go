@@ -3,6 +3,7 @@ package opsicle
 import (
 	"fmt"
 	"opsicle/cmd/opsicle/can"
+	"opsicle/cmd/opsicle/cancel"
 	"opsicle/cmd/opsicle/create"
 	"opsicle/cmd/opsicle/explain"
 	"opsicle/cmd/opsicle/get"
@@ -11,10 +12,13 @@ import (
 	"opsicle/cmd/opsicle/leave"
 	"opsicle/cmd/opsicle/list"
 	"opsicle/cmd/opsicle/login"
+	"opsicle/cmd/opsicle/logs"
 	"opsicle/cmd/opsicle/logout"
+	"opsicle/cmd/opsicle/pause"
 	"opsicle/cmd/opsicle/register"
 	"opsicle/cmd/opsicle/remove"
 	"opsicle/cmd/opsicle/reset"
+	"opsicle/cmd/opsicle/resume"
 	"opsicle/cmd/opsicle/run"
 	"opsicle/cmd/opsicle/start"
 	"opsicle/cmd/opsicle/submit"
@@ -39,6 +43,7 @@ import (
 var availableOutputs = []string{
 	"text",
 	"json",
+	"yaml",
 }
 
 var availableLogLevels = []string{
@@ -96,6 +101,7 @@ func init() {
 	Command.SetVersionTemplate(cli.Logo + "\n" + `{{with .DisplayName}}{{printf "%s " .}}{{end}}{{printf "version %s" .Version}}`)
 
 	Command.AddCommand(can.Command)
+	Command.AddCommand(cancel.Command)
 	Command.AddCommand(create.Command)
 	Command.AddCommand(explain.Command)
 	Command.AddCommand(get.Command)
@@ -104,10 +110,13 @@ func init() {
 	Command.AddCommand(leave.Command)
 	Command.AddCommand(list.Command)
 	Command.AddCommand(login.Command)
+	Command.AddCommand(logs.Command)
 	Command.AddCommand(logout.Command)
+	Command.AddCommand(pause.Command)
 	Command.AddCommand(register.Command)
 	Command.AddCommand(remove.Command)
 	Command.AddCommand(reset.Command)
+	Command.AddCommand(resume.Command)
 	Command.AddCommand(run.Command)
 	Command.AddCommand(start.Command)
 	Command.AddCommand(submit.Command)
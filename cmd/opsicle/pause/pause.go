@@ -0,0 +1,19 @@
+package pause
+
+import (
+	"opsicle/cmd/opsicle/pause/schedule"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	Command.AddCommand(schedule.Command.Get())
+}
+
+var Command = &cobra.Command{
+	Use:   "pause",
+	Short: "Pauses resources in Opsicle",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cmd.Help()
+	},
+}
@@ -0,0 +1,96 @@
+package schedule
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"opsicle/internal/cli"
+	"opsicle/internal/config"
+	"opsicle/internal/types"
+	"opsicle/internal/validate"
+	"opsicle/pkg/controller"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var flags cli.Flags = cli.Flags{}.Append(config.GetControllerUrlFlags())
+
+var Command = cli.NewCommand(cli.CommandOpts{
+	Flags:   flags,
+	Use:     "schedule <schedule-id>",
+	Aliases: []string{"sched"},
+	Short:   "Pauses a schedule",
+	Long:    "Pauses a schedule so that it stops triggering automations until it is resumed",
+	Run: func(cmd *cobra.Command, opts *cli.Command, args []string) error {
+		if len(args) == 0 {
+			return fmt.Errorf("failed to receive a schedule id")
+		}
+		scheduleId := strings.TrimSpace(args[0])
+		if err := validate.Uuid(scheduleId); err != nil {
+			return fmt.Errorf("failed to validate schedule id '%s': %w", scheduleId, err)
+		}
+
+		controllerUrl := viper.GetString("controller-url")
+		methodId := "opsicle/pause/schedule"
+
+	enforceAuth:
+		sessionToken, err := cli.RequireAuth(controllerUrl, methodId)
+		if err != nil {
+			rootCmd := cmd.Root()
+			rootCmd.SetArgs([]string{"login"})
+			_, execErr := rootCmd.ExecuteC()
+			if execErr != nil {
+				return execErr
+			}
+			goto enforceAuth
+		}
+
+		client, err := controller.NewClient(controller.NewClientOpts{
+			ControllerUrl: controllerUrl,
+			BearerAuth: &controller.NewClientBearerAuthOpts{
+				Token: sessionToken,
+			},
+			Id: methodId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create controller client: %w", err)
+		}
+
+		scheduleOutput, err := client.SetAutomationSchedulePausedV1(controller.SetAutomationSchedulePausedV1Input{
+			ScheduleId: scheduleId,
+			IsPaused:   true,
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, types.ErrorInsufficientPermissions):
+				cli.PrintBoxedErrorMessage("You are not authorized to update this schedule")
+				return fmt.Errorf("not authorized to update schedule")
+			case errors.Is(err, types.ErrorNotFound):
+				cli.PrintBoxedErrorMessage("The schedule could not be found")
+				return fmt.Errorf("schedule not found")
+			default:
+				return fmt.Errorf("failed to update schedule: %w", err)
+			}
+		}
+		if scheduleOutput == nil {
+			return fmt.Errorf("controller returned no data")
+		}
+
+		outputFormat := strings.ToLower(viper.GetString("output"))
+		switch outputFormat {
+		case "json":
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(scheduleOutput.Data); err != nil {
+				return fmt.Errorf("failed to encode json output: %w", err)
+			}
+		default:
+			cli.PrintBoxedSuccessMessage(fmt.Sprintf("Schedule %s was paused", scheduleOutput.Data.Name))
+		}
+		return nil
+	},
+})
@@ -2,14 +2,18 @@ package remove
 
 import (
 	"opsicle/cmd/opsicle/remove/org"
+	"opsicle/cmd/opsicle/remove/schedule"
 	"opsicle/cmd/opsicle/remove/template"
+	"opsicle/cmd/opsicle/remove/trigger"
 
 	"github.com/spf13/cobra"
 )
 
 func init() {
 	Command.AddCommand(org.Command)
+	Command.AddCommand(schedule.Command.Get())
 	Command.AddCommand(template.Command)
+	Command.AddCommand(trigger.Command.Get())
 }
 
 var Command = &cobra.Command{
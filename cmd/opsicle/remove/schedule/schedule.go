@@ -0,0 +1,77 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"opsicle/internal/cli"
+	"opsicle/internal/config"
+	"opsicle/internal/types"
+	"opsicle/internal/validate"
+	"opsicle/pkg/controller"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var flags cli.Flags = cli.Flags{}.Append(config.GetControllerUrlFlags())
+
+var Command = cli.NewCommand(cli.CommandOpts{
+	Flags:   flags,
+	Use:     "schedule <schedule-id>",
+	Aliases: []string{"sched"},
+	Short:   "Removes a schedule, automations it already triggered are not affected",
+	Run: func(cmd *cobra.Command, opts *cli.Command, args []string) error {
+		if len(args) == 0 {
+			return fmt.Errorf("failed to receive a schedule id")
+		}
+		scheduleId := strings.TrimSpace(args[0])
+		if err := validate.Uuid(scheduleId); err != nil {
+			return fmt.Errorf("failed to validate schedule id '%s': %w", scheduleId, err)
+		}
+
+		controllerUrl := viper.GetString("controller-url")
+		methodId := "opsicle/remove/schedule"
+
+	enforceAuth:
+		sessionToken, err := cli.RequireAuth(controllerUrl, methodId)
+		if err != nil {
+			rootCmd := cmd.Root()
+			rootCmd.SetArgs([]string{"login"})
+			_, execErr := rootCmd.ExecuteC()
+			if execErr != nil {
+				return execErr
+			}
+			goto enforceAuth
+		}
+
+		client, err := controller.NewClient(controller.NewClientOpts{
+			ControllerUrl: controllerUrl,
+			BearerAuth: &controller.NewClientBearerAuthOpts{
+				Token: sessionToken,
+			},
+			Id: methodId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create controller client: %w", err)
+		}
+
+		if _, err := client.DeleteAutomationScheduleV1(controller.DeleteAutomationScheduleV1Input{
+			ScheduleId: scheduleId,
+		}); err != nil {
+			switch {
+			case errors.Is(err, types.ErrorInsufficientPermissions):
+				cli.PrintBoxedErrorMessage("You are not authorized to remove this schedule")
+				return fmt.Errorf("not authorized to remove schedule")
+			case errors.Is(err, types.ErrorNotFound):
+				cli.PrintBoxedErrorMessage("The schedule could not be found")
+				return fmt.Errorf("schedule not found")
+			default:
+				return fmt.Errorf("failed to remove schedule: %w", err)
+			}
+		}
+		cli.PrintBoxedSuccessMessage(fmt.Sprintf("Schedule %s was removed", scheduleId))
+		return nil
+	},
+})
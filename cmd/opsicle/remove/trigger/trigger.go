@@ -0,0 +1,77 @@
+package trigger
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"opsicle/internal/cli"
+	"opsicle/internal/config"
+	"opsicle/internal/types"
+	"opsicle/internal/validate"
+	"opsicle/pkg/controller"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var flags cli.Flags = cli.Flags{}.Append(config.GetControllerUrlFlags())
+
+var Command = cli.NewCommand(cli.CommandOpts{
+	Flags:   flags,
+	Use:     "trigger <trigger-id>",
+	Aliases: []string{"webhook"},
+	Short:   "Removes a trigger, webhooks sent to it are rejected afterwards",
+	Run: func(cmd *cobra.Command, opts *cli.Command, args []string) error {
+		if len(args) == 0 {
+			return fmt.Errorf("failed to receive a trigger id")
+		}
+		triggerId := strings.TrimSpace(args[0])
+		if err := validate.Uuid(triggerId); err != nil {
+			return fmt.Errorf("failed to validate trigger id '%s': %w", triggerId, err)
+		}
+
+		controllerUrl := viper.GetString("controller-url")
+		methodId := "opsicle/remove/trigger"
+
+	enforceAuth:
+		sessionToken, err := cli.RequireAuth(controllerUrl, methodId)
+		if err != nil {
+			rootCmd := cmd.Root()
+			rootCmd.SetArgs([]string{"login"})
+			_, execErr := rootCmd.ExecuteC()
+			if execErr != nil {
+				return execErr
+			}
+			goto enforceAuth
+		}
+
+		client, err := controller.NewClient(controller.NewClientOpts{
+			ControllerUrl: controllerUrl,
+			BearerAuth: &controller.NewClientBearerAuthOpts{
+				Token: sessionToken,
+			},
+			Id: methodId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create controller client: %w", err)
+		}
+
+		if _, err := client.DeleteTemplateTriggerV1(controller.DeleteTemplateTriggerV1Input{
+			TriggerId: triggerId,
+		}); err != nil {
+			switch {
+			case errors.Is(err, types.ErrorInsufficientPermissions):
+				cli.PrintBoxedErrorMessage("You are not authorized to remove this trigger")
+				return fmt.Errorf("not authorized to remove trigger")
+			case errors.Is(err, types.ErrorNotFound):
+				cli.PrintBoxedErrorMessage("The trigger could not be found")
+				return fmt.Errorf("trigger not found")
+			default:
+				return fmt.Errorf("failed to remove trigger: %w", err)
+			}
+		}
+		cli.PrintBoxedSuccessMessage(fmt.Sprintf("Trigger %s was removed", triggerId))
+		return nil
+	},
+})
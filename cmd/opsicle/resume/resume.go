@@ -0,0 +1,19 @@
+package resume
+
+import (
+	"opsicle/cmd/opsicle/resume/schedule"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	Command.AddCommand(schedule.Command.Get())
+}
+
+var Command = &cobra.Command{
+	Use:   "resume",
+	Short: "Resumes resources in Opsicle",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cmd.Help()
+	},
+}
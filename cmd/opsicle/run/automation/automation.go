@@ -41,6 +41,18 @@ var flags cli.Flags = cli.Flags{
 		Usage:        "Specifies the organization ID where the automation should be created",
 		Type:         cli.FlagTypeString,
 	},
+	{
+		Name:         "from",
+		DefaultValue: "",
+		Usage:        "ID of a completed automation to run again with the same template version and variables",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "from-phase",
+		DefaultValue: "",
+		Usage:        "Name of the phase to resume from when --from is specified, earlier phases are not run again",
+		Type:         cli.FlagTypeString,
+	},
 }.Append(config.GetControllerUrlFlags())
 
 func init() {
@@ -77,6 +89,14 @@ var Command = &cobra.Command{
 			return fmt.Errorf("failed to create controller client: %w", err)
 		}
 
+		parentAutomationId := strings.TrimSpace(viper.GetString("from"))
+		fromPhase := strings.TrimSpace(viper.GetString("from-phase"))
+		if parentAutomationId != "" {
+			return handleRerun(client, parentAutomationId, fromPhase)
+		} else if fromPhase != "" {
+			return fmt.Errorf("--from-phase can only be used with --from")
+		}
+
 		orgInput := strings.TrimSpace(viper.GetString("org"))
 		getOrgOutput, err := client.GetOrgV1(controller.GetOrgV1Input{Ref: orgInput})
 		if err != nil {
@@ -132,6 +152,9 @@ var Command = &cobra.Command{
 			formFields := cli.FormFields{}
 			variableMap := automationOutput.Data.VariableMap
 			for id, variable := range variableMap {
+				if variable.ValueFrom != nil {
+					continue
+				}
 				var defaultValue *string
 				if variable.Default != nil {
 					val := fmt.Sprintf("%v", variable.Default)
@@ -147,6 +170,9 @@ var Command = &cobra.Command{
 					fieldType = cli.FormFieldString
 				case "number":
 					fieldType = cli.FormFieldInteger
+				case "secret":
+					fieldType = cli.FormFieldSecret
+					defaultValue = nil
 				}
 				input := cli.FormField{
 					Id:          id,
@@ -188,7 +214,14 @@ var Command = &cobra.Command{
 				return cli.ErrorUserCancelled
 			}
 			inputVariableMap = variableInputForm.GetValueMap()
-			o, _ := json.MarshalIndent(inputVariableMap, "", "  ")
+			loggedVariableMap := map[string]any{}
+			for id, value := range inputVariableMap {
+				loggedVariableMap[id] = value
+				if variableMap[id].IsSecret() {
+					loggedVariableMap[id] = automations.RedactedValue
+				}
+			}
+			o, _ := json.MarshalIndent(loggedVariableMap, "", "  ")
 			logrus.Debugf("submitting variable map as follows:\n%s", string(o))
 		}
 
@@ -208,14 +241,13 @@ var Command = &cobra.Command{
 			return fmt.Errorf("failed to run automation")
 		}
 
-		// 4. display "successfully triggered message" to user along with instructions
-		//    on how to view the status of the automation
-
 		cli.PrintBoxedSuccessMessage(
 			fmt.Sprintf(
-				"Successfully triggered automation[%s] as automationRun[%s]",
+				"Successfully triggered automation[%s] as automationRun[%s]%s\n\nView its status using:\n  opsicle get automation %s",
 				automationOutput.Data.AutomationId,
 				automationRunOutput.Data.AutomationRunId,
+				getApprovalMessage(automationRunOutput.Data.Status),
+				automationRunOutput.Data.AutomationRunId,
 			),
 		)
 
@@ -223,6 +255,62 @@ var Command = &cobra.Command{
 	},
 }
 
+// handleRerun runs the completed automation identified by
+// `parentAutomationId` again with its variables, resuming from the
+// phase `fromPhase` when it is defined
+func handleRerun(client *controller.Client, parentAutomationId, fromPhase string) error {
+	rerunOutput, err := client.RerunAutomationV1(controller.RerunAutomationV1Input{
+		AutomationId: parentAutomationId,
+		FromPhase:    fromPhase,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create re-run of automation[%s]: %w", parentAutomationId, err)
+	}
+	pendingAutomation := rerunOutput.Data
+	o, _ := json.MarshalIndent(pendingAutomation, "", "  ")
+	logrus.Debugf("received pending automation data:\n%s\n", string(o))
+
+	automationRunOutput, err := client.RunAutomationV1(controller.RunAutomationV1Input{
+		AutomationId: pendingAutomation.AutomationId,
+		VariableMap:  map[string]any{},
+	})
+	if err != nil {
+		cli.PrintBoxedErrorMessage(
+			fmt.Sprintf(
+				"failed to re-run automation[%s] as automation[%s]: %s",
+				parentAutomationId,
+				pendingAutomation.AutomationId,
+				err,
+			),
+		)
+		return fmt.Errorf("failed to run automation")
+	}
+	resumeMessage := ""
+	if fromPhase != "" {
+		resumeMessage = fmt.Sprintf(" from phase[%s]", fromPhase)
+	}
+	cli.PrintBoxedSuccessMessage(
+		fmt.Sprintf(
+			"Successfully re-ran automation[%s]%s as automationRun[%s]%s\n\nView its status using:\n  opsicle get automation %s",
+			parentAutomationId,
+			resumeMessage,
+			automationRunOutput.Data.AutomationRunId,
+			getApprovalMessage(automationRunOutput.Data.Status),
+			automationRunOutput.Data.AutomationRunId,
+		),
+	)
+	return nil
+}
+
+// getApprovalMessage returns a note to append to the success message
+// when the triggered automation is waiting for approval
+func getApprovalMessage(status string) string {
+	if automations.RunStatusCode(status) != automations.RunStatusPendingApproval {
+		return ""
+	}
+	return ", it will start once its approval request is approved"
+}
+
 func handleLocalExecution(cmd *cobra.Command, resourcePath string) error {
 	automationInstance, err := automations.LoadAutomationFromFile(resourcePath)
 	if err != nil {
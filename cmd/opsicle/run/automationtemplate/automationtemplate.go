@@ -16,7 +16,6 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
-	"gopkg.in/yaml.v3"
 )
 
 var flags cli.Flags = cli.Flags{
@@ -93,23 +92,9 @@ var Command = &cobra.Command{
 				if externalPoliciesPath == "" {
 					return fmt.Errorf("failed to receive a path where approval policies can be found but policyRef was defined")
 				}
-				matchedResources, err := common.FindResourceInFilesystem(externalPoliciesPath, approvalPolicyName, "ApprovalPolicy")
+				approvalPolicyResource, err := approvals.LoadPolicyFromDirectory(externalPoliciesPath, approvalPolicyName)
 				if err != nil {
-					return fmt.Errorf("failed while finding resource of type[ApprovalPolicy] with name[%s]: %s", approvalPolicyName, err)
-				}
-				if len(matchedResources) <= 0 {
-					return fmt.Errorf("failed to get any resources of type[ApprovalPolicy] with name[%s]: %s", approvalPolicyName, err)
-				} else if len(matchedResources) > 1 {
-					weirdFiles := []string{}
-					for _, matchedResource := range matchedResources {
-						weirdFiles = append(weirdFiles, matchedResource.Path)
-					}
-					return fmt.Errorf("more than one resource had type[ApprovalPolicy] with name[%s]: ['%s']", approvalPolicyName, strings.Join(weirdFiles, "', '"))
-				}
-				logrus.Tracef("following is the data from the matched resource:\n%s", string(matchedResources[0].Data))
-				var approvalPolicyResource approvals.Policy
-				if err := yaml.Unmarshal(matchedResources[0].Data, &approvalPolicyResource); err != nil {
-					return fmt.Errorf("failed to parse file[%s]: %s", matchedResources[0].Path, err)
+					return fmt.Errorf("failed to load approval policy[%s]: %w", approvalPolicyName, err)
 				}
 				automationTemplateInstance.Spec.ApprovalPolicy.Spec = &approvalPolicyResource.Spec
 			}
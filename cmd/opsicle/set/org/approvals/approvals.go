@@ -1,12 +1,16 @@
 package approvals
 
 import (
+	"errors"
 	"fmt"
 	"opsicle/internal/cli"
 	"opsicle/internal/config"
+	"opsicle/internal/types"
+	"opsicle/pkg/controller"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 var flags = cli.Flags{
@@ -34,9 +38,69 @@ var Command = cli.NewCommand(cli.CommandOpts{
 		if len(args) == 0 {
 			return fmt.Errorf("failed to receive a valid <status>, <status> should be either 'on' or 'off'")
 		}
+		var isApprovalsEnabled bool
+		switch strings.ToLower(args[0]) {
+		case "on":
+			isApprovalsEnabled = true
+		case "off":
+			isApprovalsEnabled = false
+		default:
+			return fmt.Errorf("failed to receive a valid <status>, <status> should be either 'on' or 'off'")
+		}
+
+		controllerUrl := viper.GetString("controller-url")
+		methodId := "opsicle/set/org/approvals"
 
-		// TODO: implement a method that results in the column `is_approvals_enabled` of `org_config` table being set
+	enforceAuth:
+		sessionToken, err := cli.RequireAuth(controllerUrl, methodId)
+		if err != nil {
+			rootCmd := cmd.Root()
+			rootCmd.SetArgs([]string{"login"})
+			_, execErr := rootCmd.ExecuteC()
+			if execErr != nil {
+				return execErr
+			}
+			goto enforceAuth
+		}
+
+		client, err := controller.NewClient(controller.NewClientOpts{
+			ControllerUrl: controllerUrl,
+			BearerAuth: &controller.NewClientBearerAuthOpts{
+				Token: sessionToken,
+			},
+			Id: methodId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create controller client: %w", err)
+		}
 
+		selectedOrg, err := cli.HandleOrgSelection(cli.HandleOrgSelectionOpts{
+			Client:    client,
+			UserInput: viper.GetString("org"),
+		})
+		if err != nil {
+			return fmt.Errorf("org selection failed: %w", err)
+		}
+
+		output, err := client.SetOrgApprovalsConfigV1(controller.SetOrgApprovalsConfigV1Input{
+			OrgId:              selectedOrg.Id,
+			IsApprovalsEnabled: isApprovalsEnabled,
+		})
+		if err != nil {
+			if errors.Is(err, types.ErrorInsufficientPermissions) {
+				cli.PrintBoxedErrorMessage("You are not authorized to update the config of this organisation")
+				return fmt.Errorf("not authorized to update org config")
+			}
+			return fmt.Errorf("failed to set approvals of org[%s]: %w", selectedOrg.Code, err)
+		}
+
+		status := "off"
+		if output.Data.IsApprovalsEnabled {
+			status = "on"
+		}
+		cli.PrintBoxedSuccessMessage(
+			fmt.Sprintf("Approvals of org[%s] are now %s", selectedOrg.Code, status),
+		)
 		return nil
 	},
 })
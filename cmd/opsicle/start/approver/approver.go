@@ -1,6 +1,7 @@
 package approver
 
 import (
+	"context"
 	"fmt"
 	"opsicle/internal/approver"
 	"opsicle/internal/cache"
@@ -58,6 +59,12 @@ var flags cli.Flags = cli.Flags{
 		Usage:        "specifies remote ip addresses that are allowed to communicate with the server",
 		Type:         cli.FlagTypeStringSlice,
 	},
+	{
+		Name:         "mongo-enabled",
+		DefaultValue: false,
+		Usage:        "when this flag is specified, approval requests and approvals are durably stored in mongodb in addition to the cache",
+		Type:         cli.FlagTypeBool,
+	},
 	{
 		Name:         "redis-enabled",
 		DefaultValue: true,
@@ -82,6 +89,12 @@ var flags cli.Flags = cli.Flags{
 		Usage:        "the slack bot token to be used when slack is enabled",
 		Type:         cli.FlagTypeString,
 	},
+	{
+		Name:         "sweeper-interval",
+		DefaultValue: approver.DefaultSweeperInterval,
+		Usage:        "specifies the interval between sweeps which expire, remind and escalate pending approval requests",
+		Type:         cli.FlagTypeDuration,
+	},
 	{
 		Name:         "telegram-enabled",
 		DefaultValue: false,
@@ -96,6 +109,7 @@ var flags cli.Flags = cli.Flags{
 	},
 }.
 	Append(config.GetListenAddrFlags(13370)).
+	Append(config.GetMongoFlags()).
 	Append(config.GetRedisFlags())
 
 var Command = cli.NewCommand(cli.CommandOpts{
@@ -133,6 +147,31 @@ var Command = cli.NewCommand(cli.CommandOpts{
 			logrus.Infof("redis client initialised")
 		}
 
+		isMongoEnabled := viper.GetBool("mongo-enabled")
+		logrus.Debugf("mongo-enabled status: %v", isMongoEnabled)
+		if isMongoEnabled {
+			mongoInstance := persistence.NewMongo(
+				persistence.MongoConnectionOpts{
+					AppName:  appName,
+					Hosts:    viper.GetStringSlice(config.MongoHosts),
+					IsDirect: true,
+				},
+				persistence.MongoAuthOpts{
+					Password: viper.GetString(config.MongoPassword),
+					Username: viper.GetString(config.MongoUsername),
+				},
+				&serviceLogs,
+			)
+			if err := mongoInstance.Init(); err != nil {
+				return fmt.Errorf("failed to connect to mongo: %w", err)
+			}
+			opts.AddShutdownProcess("mongo", mongoInstance.Shutdown)
+			if err := approver.InitMongoStore(mongoInstance.GetClient()); err != nil {
+				return fmt.Errorf("failed to initialise approval request store: %w", err)
+			}
+			logrus.Infof("mongo store initialised")
+		}
+
 		isSlackEnabled := viper.GetBool("slack-enabled")
 		logrus.Debugf("slack-enabled status: %v", isSlackEnabled)
 		if isSlackEnabled {
@@ -175,6 +214,12 @@ var Command = cli.NewCommand(cli.CommandOpts{
 		logrus.Debugf("starting notifiers...")
 		go approver.Notifiers.StartListening()
 
+		logrus.Debugf("starting approval request sweeper...")
+		go approver.StartSweeper(context.Background(), approver.StartSweeperOpts{
+			Interval:    viper.GetDuration("sweeper-interval"),
+			ServiceLogs: serviceLogs,
+		})
+
 		listenAddress := viper.GetString("listen-addr")
 		logrus.Debugf("starting http server on addr[%s]...", listenAddress)
 		httpServerDone := make(chan common.Done)
@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"opsicle/internal/audit"
@@ -10,6 +11,7 @@ import (
 	"opsicle/internal/controller"
 	"opsicle/internal/email"
 	"opsicle/internal/persistence"
+	"opsicle/pkg/approver"
 	"strconv"
 
 	"github.com/sirupsen/logrus"
@@ -183,6 +185,7 @@ var Command = cli.NewCommand(cli.CommandOpts{
 			mongoInstance.GetStatus().GetError,
 		}
 
+		secretsMasterKey := viper.GetString("secrets-master-key")
 		sessionSigningToken := viper.GetString("session-signing-token")
 		apiKeys := viper.GetStringSlice("api-keys")
 		listenAddress := viper.GetString("listen-addr")
@@ -191,18 +194,47 @@ var Command = cli.NewCommand(cli.CommandOpts{
 			publicUrl = fmt.Sprintf("http://%s", listenAddress)
 		}
 
+		controllerContext, stopController := context.WithCancel(context.Background())
+		opts.AddShutdownProcess("controller", func() error {
+			stopController()
+			return nil
+		})
+
 		controllerOpts := controller.HttpApplicationOpts{
 			ApiKeys:             apiKeys,
 			CacheConnection:     redisInstance,
+			Context:             controllerContext,
 			DatabaseConnection:  mysqlInstance,
 			ReadinessChecks:     healthcheckProbes,
 			LivenessChecks:      healthcheckProbes,
 			PublicServerUrl:     publicUrl,
 			QueueConnection:     natsInstance,
+			SecretsMasterKey:    secretsMasterKey,
 			ServiceLogs:         serviceLogs,
 			SessionSigningToken: sessionSigningToken,
 		}
 
+		if approverUrl := viper.GetString("approver-url"); approverUrl != "" {
+			controllerOpts.ApproverConfig = &controller.ApproverConfig{Url: approverUrl}
+			approverUsername := viper.GetString("approver-basic-auth-username")
+			approverPassword := viper.GetString("approver-basic-auth-password")
+			if approverUsername != "" || approverPassword != "" {
+				if approverUsername == "" || approverPassword == "" {
+					return fmt.Errorf("failed to receive both '--approver-basic-auth-username' and '--approver-basic-auth-password'")
+				}
+				controllerOpts.ApproverConfig.BasicAuth = &approver.NewClientBasicAuthOpts{
+					Username: approverUsername,
+					Password: approverPassword,
+				}
+			}
+			if approverToken := viper.GetString("approver-bearer-auth-token"); approverToken != "" {
+				controllerOpts.ApproverConfig.BearerAuth = &approver.NewClientBearerAuthOpts{
+					Token: approverToken,
+				}
+			}
+			logrus.Infof("using approver service at url[%s]", approverUrl)
+		}
+
 		logrus.Infof("initialising email...")
 		smtpHost := viper.GetString("smtp-hostname")
 		smtpPort := viper.GetInt("smtp-port")
@@ -12,12 +12,42 @@ var flags cli.Flags = cli.Flags{
 		Usage:        "specifies an API key for accessing protected endpoints",
 		Type:         cli.FlagTypeStringSlice,
 	},
+	{
+		Name:         "approver-url",
+		DefaultValue: "",
+		Usage:        "specifies the url of the approver service, automations whose templates define an approval policy cannot be run when this is not set",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "approver-basic-auth-username",
+		DefaultValue: "",
+		Usage:        "the username used when authenticating with the approver service with basic auth; requires '--approver-basic-auth-password' to be set as well",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "approver-basic-auth-password",
+		DefaultValue: "",
+		Usage:        "the password used when authenticating with the approver service with basic auth; requires '--approver-basic-auth-username' to be set as well",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "approver-bearer-auth-token",
+		DefaultValue: "",
+		Usage:        "the token used when authenticating with the approver service with bearer auth",
+		Type:         cli.FlagTypeString,
+	},
 	{
 		Name:         "public-server-url",
 		DefaultValue: "",
 		Usage:        "specifies a url where the controller server can be accessed via - required for emails to work properly",
 		Type:         cli.FlagTypeString,
 	},
+	{
+		Name:         "secrets-master-key",
+		DefaultValue: "",
+		Usage:        "specifies the key used to derive encryption keys for secrets stored in the database, this is required and should be a random string of at least 32 characters",
+		Type:         cli.FlagTypeString,
+	},
 	{
 		Name:         "sender-email",
 		DefaultValue: "noreply@notification.opsicle.io",
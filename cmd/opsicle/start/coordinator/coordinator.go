@@ -159,6 +159,7 @@ var Command = cli.NewCommand(cli.CommandOpts{
 		handler, err := coordinator.GetHttpApplication(coordinator.HttpApplicationOpts{
 			Cache:            redisInstance,
 			ControllerApiKey: controllerApiKey,
+			ControllerUrl:    controllerUrl,
 			Queue:            natsInstance,
 
 			LivenessChecks:  healthcheckProbes,
@@ -170,11 +171,24 @@ var Command = cli.NewCommand(cli.CommandOpts{
 			return fmt.Errorf("failed to initialise web application: %w", err)
 		}
 
-		httpServer, err := common.NewHttpServer(common.NewHttpServerOpts{
-			Addr:        viper.GetString("listen-addr"),
-			Handler:     handler,
-			ServiceLogs: opts.GetServiceLogs(),
-		})
+		httpServerOpts := common.NewHttpServerOpts{
+			Addr:         viper.GetString("listen-addr"),
+			Handler:      handler,
+			ServiceLogs:  opts.GetServiceLogs(),
+			WriteTimeout: coordinator.DefaultServerWriteTimeout,
+		}
+		tlsCertPath := viper.GetString("tls-cert-path")
+		tlsKeyPath := viper.GetString("tls-key-path")
+		if tlsCertPath != "" || tlsKeyPath != "" {
+			httpServerOpts.Tls = &common.NewHttpServerTlsOpts{
+				CertPath:     tlsCertPath,
+				KeyPath:      tlsKeyPath,
+				ClientCaPath: viper.GetString("tls-client-ca-path"),
+			}
+		} else {
+			logrus.Warnf("tls is not configured, workers will only be able to authenticate using bearer tokens")
+		}
+		httpServer, err := common.NewHttpServer(httpServerOpts)
 		if err != nil {
 			return fmt.Errorf("failed to create http server: %w", err)
 		}
@@ -18,6 +18,24 @@ var flags = cli.Flags{
 		Usage:        "defines the url which the controller is available at",
 		Type:         cli.FlagTypeString,
 	},
+	{
+		Name:         "tls-cert-path",
+		DefaultValue: "",
+		Usage:        "defines the path to the server certificate, when specified with --tls-key-path, https is served",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "tls-key-path",
+		DefaultValue: "",
+		Usage:        "defines the path to the server private key, when specified with --tls-cert-path, https is served",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "tls-client-ca-path",
+		DefaultValue: "",
+		Usage:        "defines the path to a bundle of org certificate authorities which worker certificates are verified against",
+		Type:         cli.FlagTypeString,
+	},
 }.
 	Append(config.GetListenAddrFlags(13372)).
 	Append(config.GetMongoFlags()).
@@ -5,6 +5,7 @@ import (
 	"opsicle/internal/cli"
 	"opsicle/internal/common"
 	"opsicle/internal/config"
+	"opsicle/internal/tls"
 	"opsicle/internal/worker"
 	"os"
 	"os/signal"
@@ -17,6 +18,11 @@ import (
 	"github.com/spf13/viper"
 )
 
+// registryPasswordEnv is the environment variable which the registry
+// password is read from, the password is not accepted as a flag so
+// that it is not exposed in the process list
+const registryPasswordEnv = "REGISTRY_PASSWORD"
+
 var flags cli.Flags = cli.Flags{
 	{
 		Name:         "filesystem-path",
@@ -33,6 +39,116 @@ var flags cli.Flags = cli.Flags{
 		Usage:        fmt.Sprintf("runtime to use, one of ['%s']", strings.Join(common.Runtimes, "', '")),
 		Type:         cli.FlagTypeString,
 	},
+	{
+		Name:         "kubeconfig",
+		DefaultValue: "",
+		Usage:        "path to the kubeconfig used by the kubernetes runtime, defaults to the in-cluster configuration",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "kubernetes-namespace",
+		DefaultValue: worker.DefaultKubernetesNamespace,
+		Usage:        "namespace where the kubernetes runtime creates jobs",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "kubernetes-image-pull-secrets",
+		DefaultValue: []string{},
+		Usage:        "names of secrets used by the kubernetes runtime to pull images of phases",
+		Type:         cli.FlagTypeStringSlice,
+	},
+	{
+		Name:         "kubernetes-workspace-size",
+		DefaultValue: worker.DefaultKubernetesWorkspaceSize,
+		Usage:        "storage requested for the workspace of each automation when using the kubernetes runtime",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "kubernetes-workspace-storage-class",
+		DefaultValue: "",
+		Usage:        "storage class of the workspace of each automation when using the kubernetes runtime, defaults to the cluster's default",
+		Type:         cli.FlagTypeString,
+	},
+
+	{
+		Name:         "process-user",
+		DefaultValue: "",
+		Usage:        "name or id of an unprivileged user which the process runtime runs phases as, the worker must be run as root to use this",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "registry-config",
+		DefaultValue: "",
+		Usage:        "path to a docker config.json containing credentials used to pull images of phases",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "registry-server",
+		DefaultValue: "",
+		Usage:        "host of a registry to authenticate with when pulling images of phases",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "registry-username",
+		DefaultValue: "",
+		Usage:        "username used to authenticate with the registry defined by --registry-server",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "registry-password-file",
+		DefaultValue: "",
+		Usage:        "path to a file containing the password used to authenticate with the registry defined by --registry-server, the password can also be provided using the " + registryPasswordEnv + " environment variable",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "require-image-digests",
+		DefaultValue: false,
+		Usage:        "rejects phases whose image is not pinned to a digest",
+		Type:         cli.FlagTypeBool,
+	},
+	{
+		Name:         "allow-privileged",
+		DefaultValue: false,
+		Usage:        "allows phases to run privileged containers, this is required by the process runtime",
+		Type:         cli.FlagTypeBool,
+	},
+	{
+		Name:         "forbid-host-mounts",
+		DefaultValue: false,
+		Usage:        "rejects automations which mount paths on the worker's host, which all automations run by the process runtime do",
+		Type:         cli.FlagTypeBool,
+	},
+	{
+		Name:         "forbid-host-network",
+		DefaultValue: false,
+		Usage:        "rejects phases which use the network of the worker's host, which all phases run by the process runtime do",
+		Type:         cli.FlagTypeBool,
+	},
+
+	{
+		Name:         "cert-path",
+		DefaultValue: "",
+		Usage:        "path to the org token certificate used to authenticate with the coordinator",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "key-path",
+		DefaultValue: "",
+		Usage:        "path to the private key of the org token certificate",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "ca-path",
+		DefaultValue: "",
+		Usage:        "path to the certificate authority used to verify the coordinator's certificate",
+		Type:         cli.FlagTypeString,
+	},
+	{
+		Name:         "worker-id",
+		DefaultValue: "",
+		Usage:        "id used to identify this worker to the coordinator, defaults to the hostname",
+		Type:         cli.FlagTypeString,
+	},
 
 	{
 		Name:         "poll-interval",
@@ -41,6 +157,24 @@ var flags cli.Flags = cli.Flags{
 		Usage:        "interval between polls",
 		Type:         cli.FlagTypeDuration,
 	},
+	{
+		Name:         "drain-timeout",
+		DefaultValue: worker.DefaultDrainTimeout,
+		Usage:        "duration to wait for automations in progress to complete when exiting before they are cancelled, a second exit signal cancels them immediately",
+		Type:         cli.FlagTypeDuration,
+	},
+	{
+		Name:         "stop-grace-period",
+		DefaultValue: worker.DefaultStopGracePeriod,
+		Usage:        "duration phases of a cancelled automation are given to exit after SIGTERM before they are sent SIGKILL",
+		Type:         cli.FlagTypeDuration,
+	},
+	{
+		Name:         "workspace-retention",
+		DefaultValue: time.Duration(0),
+		Usage:        "duration workspaces of completed automations are kept for re-runs resuming from a phase to reuse, workspaces are removed immediately when this is zero",
+		Type:         cli.FlagTypeDuration,
+	},
 }.Append(config.GetCoordinatorUrlFlags())
 
 func init() {
@@ -71,6 +205,79 @@ var Command = &cobra.Command{
 		if source == "" {
 			return fmt.Errorf("failed to identify a worker mode, specify only the coordinator url or the filesystem path")
 		}
+		workerOpts := worker.NewWorkerOpts{
+			DrainTimeout: viper.GetDuration("drain-timeout"),
+			Id:           viper.GetString("worker-id"),
+			Mode:         mode,
+			PollInterval: pollInterval,
+			Policy: worker.Policy{
+				AllowPrivileged:     viper.GetBool("allow-privileged"),
+				ForbidHostMounts:    viper.GetBool("forbid-host-mounts"),
+				ForbidHostNetwork:   viper.GetBool("forbid-host-network"),
+				RequireImageDigests: viper.GetBool("require-image-digests"),
+			},
+			Runtime:            runtime,
+			Source:             source,
+			StopGracePeriod:    viper.GetDuration("stop-grace-period"),
+			WorkspaceRetention: viper.GetDuration("workspace-retention"),
+		}
+		if workerOpts.Id == "" {
+			workerOpts.Id, _ = os.Hostname()
+		}
+		if registryConfigPath := viper.GetString("registry-config"); registryConfigPath != "" {
+			registryCredentials, err := worker.LoadRegistryCredentialsFromDockerConfig(registryConfigPath)
+			if err != nil {
+				return fmt.Errorf("failed to load registry credentials: %w", err)
+			}
+			workerOpts.RegistryCredentials = append(workerOpts.RegistryCredentials, registryCredentials...)
+		}
+		if registryServer := viper.GetString("registry-server"); registryServer != "" {
+			registryPassword := os.Getenv(registryPasswordEnv)
+			if registryPasswordPath := viper.GetString("registry-password-file"); registryPasswordPath != "" {
+				registryPasswordData, err := os.ReadFile(registryPasswordPath)
+				if err != nil {
+					return fmt.Errorf("failed to read registry password from %s: %w", registryPasswordPath, err)
+				}
+				registryPassword = strings.TrimRight(string(registryPasswordData), "\r\n")
+			}
+			workerOpts.RegistryCredentials = append(workerOpts.RegistryCredentials, worker.RegistryCredential{
+				Server:   registryServer,
+				Username: viper.GetString("registry-username"),
+				Password: registryPassword,
+			})
+		}
+		if runtime == common.RuntimeKubernetes {
+			workerOpts.Kubernetes = &worker.KubernetesConfig{
+				ImagePullSecrets:      viper.GetStringSlice("kubernetes-image-pull-secrets"),
+				Kubeconfig:            viper.GetString("kubeconfig"),
+				Namespace:             viper.GetString("kubernetes-namespace"),
+				WorkspaceSize:         viper.GetString("kubernetes-workspace-size"),
+				WorkspaceStorageClass: viper.GetString("kubernetes-workspace-storage-class"),
+			}
+		}
+		if runtime == common.RuntimeProcess {
+			workerOpts.Process = &worker.ProcessConfig{
+				User: viper.GetString("process-user"),
+			}
+		}
+		if mode == worker.ModeCoordinator {
+			certPath := viper.GetString("cert-path")
+			keyPath := viper.GetString("key-path")
+			if certPath != "" && keyPath != "" {
+				certificate, err := tls.LoadCertificate(certPath, keyPath)
+				if err != nil {
+					return fmt.Errorf("failed to load certificate from path[%s] and key from path[%s]: %w", certPath, keyPath, err)
+				}
+				workerOpts.Cert = certificate.TLSCertificate
+			}
+			if caPath := viper.GetString("ca-path"); caPath != "" {
+				ca, err := os.ReadFile(caPath)
+				if err != nil {
+					return fmt.Errorf("failed to read certificate authority from path[%s]: %w", caPath, err)
+				}
+				workerOpts.Ca = ca
+			}
+		}
 		serviceLogs := make(chan common.ServiceLog, 64)
 		go func() {
 			for {
@@ -108,19 +315,17 @@ var Command = &cobra.Command{
 		sigs := make(chan os.Signal, 1)
 		signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 		go func() {
-			sig := <-sigs
-			logrus.Infof("received signal: %s", sig)
-			doneChannel <- common.Done{}
+			// the first signal drains the worker and the next cancels
+			// automations in progress
+			for sig := range sigs {
+				logrus.Infof("received signal: %s", sig)
+				doneChannel <- common.Done{}
+			}
 		}()
-		workerInstance := worker.NewWorker(worker.NewWorkerOpts{
-			AutomationLogs: &automationLogs,
-			DoneChannel:    doneChannel,
-			ServiceLogs:    &serviceLogs,
-			Mode:           mode,
-			PollInterval:   pollInterval,
-			Runtime:        runtime,
-			Source:         source,
-		})
+		workerOpts.AutomationLogs = &automationLogs
+		workerOpts.DoneChannel = doneChannel
+		workerOpts.ServiceLogs = &serviceLogs
+		workerInstance := worker.NewWorker(workerOpts)
 		if err := workerInstance.Start(); err != nil {
 			logrus.Errorf("failed to start worker instance: %s", err)
 			os.Exit(1)
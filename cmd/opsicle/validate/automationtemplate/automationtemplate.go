@@ -3,19 +3,35 @@ package automationtemplate
 import (
 	"fmt"
 	"opsicle/internal/automations"
+	"opsicle/internal/cli"
 	"os"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"gopkg.in/yaml.v3"
 )
 
+var flags cli.Flags = cli.Flags{
+	{
+		Name:         "graph",
+		Short:        'g',
+		DefaultValue: false,
+		Usage:        "when this flag is specified, displays the order in which phases run instead of the template",
+		Type:         cli.FlagTypeBool,
+	},
+}
+
 func init() {
+	flags.AddToCommand(Command)
 }
 
 var Command = &cobra.Command{
 	Use:     "automationtemplate <path-to-template-file>",
 	Aliases: []string{"template"},
 	Short:   "Validates an AutomationTemplate resource",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		flags.BindViper(cmd)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		resourceIsSpecified := false
 		resourcePath := ""
@@ -37,6 +53,15 @@ var Command = &cobra.Command{
 		if err != nil {
 			return fmt.Errorf("failed to load automation template from path[%s]: %s", resourcePath, err)
 		}
+		if err := automationTemplate.Validate(); err != nil {
+			return fmt.Errorf("automation template at path[%s] is invalid: %w", resourcePath, err)
+		}
+		if viper.GetBool("graph") {
+			template := automationTemplate.Spec.Template
+			fmt.Printf("phases run after the phases they are listed under, up to %v at a time:\n\n", template.GetMaxParallelPhases())
+			fmt.Print(template.RenderPhaseGraph())
+			return nil
+		}
 		o, _ := yaml.Marshal(automationTemplate)
 		fmt.Println(string(o))
 		return nil
@@ -8,7 +8,9 @@ const (
 const (
 	StatusApproved     Status = "approved"
 	StatusError        Status = "error"
+	StatusExpired      Status = "expired"
 	StatusNew          Status = "new"
+	StatusPending      Status = "pending"
 	StatusMfaError     Status = "errorMfa"
 	StatusMfaInvalid   Status = "invalidMfa"
 	StatusMfaTriggered Status = "triggeredMfa"
@@ -20,6 +22,10 @@ const (
 	CallbackWebhook CallbackType = "webhook"
 )
 
+const (
+	PolicyResourceType = "ApprovalPolicy"
+)
+
 type Platform string
 type Status string
 type CallbackType string
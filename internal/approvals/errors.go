@@ -0,0 +1,12 @@
+package approvals
+
+import "errors"
+
+var (
+	ErrorPolicyInvalid      = errors.New("policy_invalid")
+	ErrorPolicyNotFound     = errors.New("policy_not_found")
+	ErrorRequestDecided     = errors.New("request_decided")
+	ErrorRequestExpired     = errors.New("request_expired")
+	ErrorResponseDuplicated = errors.New("response_duplicated")
+	ErrorSelfApproval       = errors.New("self_approval")
+)
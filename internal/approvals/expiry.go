@@ -0,0 +1,152 @@
+package approvals
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultRequestTtl is how long a request awaits responses when it does
+// not define `.TtlSeconds`
+const DefaultRequestTtl = 24 * time.Hour
+
+// ReminderSpec configures reminders which are sent to the targets of a
+// request which is still pending
+type ReminderSpec struct {
+	// IntervalSeconds is the duration in seconds between reminders, no
+	// reminders are sent when this is not positive
+	IntervalSeconds int `json:"intervalSeconds" yaml:"intervalSeconds"`
+
+	// Limit is the maximum number of reminders to send, reminders are
+	// sent until the request expires when this is not positive
+	Limit int `json:"limit" yaml:"limit"`
+}
+
+// EscalationSpec configures the targets a request is escalated to when
+// nobody has responded to it in time
+type EscalationSpec struct {
+	// AfterSeconds is the duration in seconds without responses to the
+	// current stage after which the request is escalated
+	AfterSeconds int `json:"afterSeconds" yaml:"afterSeconds"`
+
+	// Slack specifies the targets in Slack to escalate the request to
+	Slack []SlackRequestSpec `json:"slack" yaml:"slack"`
+
+	// Telegram specifies the targets in Telegram to escalate the
+	// request to
+	Telegram []TelegramRequestSpec `json:"telegram" yaml:"telegram"`
+}
+
+// GetTtl returns the duration a request awaits responses for
+func (rs *RequestSpec) GetTtl() time.Duration {
+	if rs.TtlSeconds <= 0 {
+		return DefaultRequestTtl
+	}
+	return time.Duration(rs.TtlSeconds) * time.Second
+}
+
+// GetExpiresAt returns the time at which the request expires
+func (rs *RequestSpec) GetExpiresAt() time.Time {
+	return rs.CreatedAt.Add(rs.GetTtl())
+}
+
+// IsExpired returns true if the request is still pending at `now` after
+// it should have expired
+func (rs *RequestSpec) IsExpired(now time.Time) bool {
+	if rs.Approval != nil || rs.CreatedAt.IsZero() {
+		return false
+	}
+	return !now.Before(rs.GetExpiresAt())
+}
+
+// getWaitingSince returns the time since which the current stage of the
+// request has been awaiting responses
+func (rs *RequestSpec) getWaitingSince() time.Time {
+	if rs.StageStartedAt != nil && rs.StageStartedAt.After(rs.CreatedAt) {
+		return *rs.StageStartedAt
+	}
+	return rs.CreatedAt
+}
+
+// hasCurrentStageResponses returns true if anyone has responded to the
+// current stage of the request
+func (rs *RequestSpec) hasCurrentStageResponses() bool {
+	if rs.Responses == nil {
+		return false
+	}
+	for _, response := range rs.Responses.Slack {
+		if response.Stage == rs.CurrentStage {
+			return true
+		}
+	}
+	for _, response := range rs.Responses.Telegram {
+		if response.Stage == rs.CurrentStage {
+			return true
+		}
+	}
+	return false
+}
+
+// IsReminderDue returns true if a reminder should be sent to the targets
+// of the request at `now`
+func (rs *RequestSpec) IsReminderDue(now time.Time) bool {
+	if rs.Reminders == nil || rs.Reminders.IntervalSeconds <= 0 {
+		return false
+	}
+	if rs.Approval != nil || rs.CreatedAt.IsZero() || rs.IsExpired(now) {
+		return false
+	}
+	if rs.Reminders.Limit > 0 && rs.RemindersSent >= rs.Reminders.Limit {
+		return false
+	}
+	lastNotifiedAt := rs.getWaitingSince()
+	if rs.LastRemindedAt != nil && rs.LastRemindedAt.After(lastNotifiedAt) {
+		lastNotifiedAt = *rs.LastRemindedAt
+	}
+	interval := time.Duration(rs.Reminders.IntervalSeconds) * time.Second
+	return !now.Before(lastNotifiedAt.Add(interval))
+}
+
+// IsEscalationDue returns true if the request should be escalated at
+// `now` because nobody has responded to its current stage in time, a
+// request is only escalated once
+func (rs *RequestSpec) IsEscalationDue(now time.Time) bool {
+	if rs.Escalation == nil || rs.Escalation.AfterSeconds <= 0 || rs.EscalatedAt != nil {
+		return false
+	}
+	if rs.Approval != nil || rs.CreatedAt.IsZero() || rs.IsExpired(now) {
+		return false
+	}
+	if rs.hasCurrentStageResponses() {
+		return false
+	}
+	after := time.Duration(rs.Escalation.AfterSeconds) * time.Second
+	return !now.Before(rs.getWaitingSince().Add(after))
+}
+
+// SetReminded records that a reminder was sent at `now`
+func (rs *RequestSpec) SetReminded(now time.Time) {
+	rs.LastRemindedAt = &now
+	rs.RemindersSent++
+}
+
+// Escalate adds the escalation targets to the targets of the request so
+// that they are authorised to respond to it and returns a copy of the
+// request which only targets them so that they can be sent the request
+func (rs *RequestSpec) Escalate(now time.Time) (*RequestSpec, error) {
+	if rs.Escalation == nil {
+		return nil, fmt.Errorf("request[%s] does not define an escalation", rs.GetUuid())
+	}
+	rs.EscalatedAt = &now
+	escalation := *rs
+	escalation.Slack = append([]SlackRequestSpec{}, rs.Escalation.Slack...)
+	escalation.Telegram = append([]TelegramRequestSpec{}, rs.Escalation.Telegram...)
+	return &escalation, nil
+}
+
+// AddEscalatedTargets adds the targets of `escalation`, which should be
+// the request returned by .Escalate after it was sent, to the targets of
+// the request
+func (rs *RequestSpec) AddEscalatedTargets(escalation *RequestSpec) {
+	rs.Slack = append(rs.Slack, escalation.Slack...)
+	rs.Telegram = append(rs.Telegram, escalation.Telegram...)
+}
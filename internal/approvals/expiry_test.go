@@ -0,0 +1,85 @@
+package approvals
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRequestExpiry(t *testing.T) {
+	createdAt := time.Now().Add(-2 * time.Hour)
+	req := RequestSpec{CreatedAt: createdAt, TtlSeconds: 3600}
+	if !req.IsExpired(time.Now()) {
+		t.Fatalf("expected request created at %s to have expired", createdAt)
+	}
+	if _, err := req.AddSlackResponse(SlackResponseSpec{UserId: "U1", Status: StatusApproved}); !errors.Is(err, ErrorRequestExpired) {
+		t.Fatalf("expected expired request error, got %v", err)
+	}
+	req.TtlSeconds = 0
+	if req.IsExpired(time.Now()) || req.GetExpiresAt() != createdAt.Add(DefaultRequestTtl) {
+		t.Fatalf("expected request without a ttl to expire after %s", DefaultRequestTtl)
+	}
+	req.TtlSeconds = 3600
+	req.Approval = &ApprovalSpec{Status: StatusApproved}
+	if req.IsExpired(time.Now()) {
+		t.Fatalf("expected decided request to not expire")
+	}
+}
+
+func TestRequestReminders(t *testing.T) {
+	createdAt := time.Now()
+	req := RequestSpec{CreatedAt: createdAt, Reminders: &ReminderSpec{IntervalSeconds: 60, Limit: 2}}
+	if req.IsReminderDue(createdAt.Add(30 * time.Second)) {
+		t.Fatalf("expected no reminder before the interval")
+	}
+	now := createdAt.Add(time.Minute)
+	if !req.IsReminderDue(now) {
+		t.Fatalf("expected a reminder after the interval")
+	}
+	req.SetReminded(now)
+	if req.IsReminderDue(now.Add(30 * time.Second)) {
+		t.Fatalf("expected no reminder before the interval since the last reminder")
+	}
+	req.SetReminded(now.Add(time.Minute))
+	if req.IsReminderDue(now.Add(5 * time.Minute)) {
+		t.Fatalf("expected no reminders after %v were sent", req.Reminders.Limit)
+	}
+}
+
+func TestRequestEscalation(t *testing.T) {
+	createdAt := time.Now()
+	req := RequestSpec{
+		CreatedAt: createdAt,
+		Slack:     []SlackRequestSpec{{ChannelNames: []string{"primary"}}},
+		Escalation: &EscalationSpec{
+			AfterSeconds: 300,
+			Telegram:     []TelegramRequestSpec{{ChatIds: []int64{1}}},
+		},
+	}
+	if req.IsEscalationDue(createdAt.Add(time.Minute)) {
+		t.Fatalf("expected no escalation before the deadline")
+	}
+	now := createdAt.Add(5 * time.Minute)
+	if !req.IsEscalationDue(now) {
+		t.Fatalf("expected an escalation after the deadline")
+	}
+	escalation, err := req.Escalate(now)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(escalation.Slack) != 0 || len(escalation.Telegram) != 1 {
+		t.Fatalf("expected escalation to only target the escalation targets, got %v and %v", escalation.Slack, escalation.Telegram)
+	}
+	req.AddEscalatedTargets(escalation)
+	if len(req.Slack) != 1 || len(req.Telegram) != 1 {
+		t.Fatalf("expected request to target the original and escalation targets, got %v and %v", req.Slack, req.Telegram)
+	}
+	if req.IsEscalationDue(now.Add(time.Hour)) {
+		t.Fatalf("expected request to only be escalated once")
+	}
+	req.EscalatedAt = nil
+	req.Responses = &ResponsesSpec{Slack: []SlackResponseSpec{{UserId: "U1", Status: StatusApproved}}}
+	if req.IsEscalationDue(now) {
+		t.Fatalf("expected no escalation when the current stage has responses")
+	}
+}
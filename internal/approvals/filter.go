@@ -0,0 +1,106 @@
+package approvals
+
+import (
+	"fmt"
+	"time"
+)
+
+// RequestFilter selects approval requests when listing them, fields
+// which are not defined do not filter requests
+type RequestFilter struct {
+	// Status selects requests with the status, undecided requests have
+	// the status StatusPending
+	Status Status `json:"status" yaml:"status"`
+
+	// Requester selects requests whose requester ID or name is this
+	Requester string `json:"requester" yaml:"requester"`
+
+	// CreatedAfter selects requests created at or after this time
+	CreatedAfter *time.Time `json:"createdAfter" yaml:"createdAfter"`
+
+	// CreatedBefore selects requests created before this time
+	CreatedBefore *time.Time `json:"createdBefore" yaml:"createdBefore"`
+
+	// Platform selects requests which were sent to the platform
+	Platform Platform `json:"platform" yaml:"platform"`
+
+	// Limit is the maximum number of requests to select, requests are
+	// selected from the newest to the oldest
+	Limit int `json:"limit" yaml:"limit"`
+}
+
+// Validate returns an error if the filter cannot be used to select
+// requests
+func (f RequestFilter) Validate() error {
+	switch f.Status {
+	case "", StatusPending, StatusApproved, StatusRejected, StatusExpired:
+	default:
+		return fmt.Errorf("status: expected one of '%s', '%s', '%s' or '%s' but got '%s'", StatusPending, StatusApproved, StatusRejected, StatusExpired, f.Status)
+	}
+	switch f.Platform {
+	case "", PlatformSlack, PlatformTelegram:
+	default:
+		return fmt.Errorf("platform: expected one of '%s' or '%s' but got '%s'", PlatformSlack, PlatformTelegram, f.Platform)
+	}
+	if f.Limit < 0 {
+		return fmt.Errorf("limit: must not be negative")
+	}
+	return nil
+}
+
+// Matches returns true if the request is selected by the filter
+func (f RequestFilter) Matches(rs RequestSpec) bool {
+	if f.Status != "" && rs.GetStatus() != f.Status {
+		return false
+	}
+	if f.Requester != "" && rs.RequesterId != f.Requester && rs.RequesterName != f.Requester {
+		return false
+	}
+	if f.CreatedAfter != nil && rs.CreatedAt.Before(*f.CreatedAfter) {
+		return false
+	}
+	if f.CreatedBefore != nil && !rs.CreatedAt.Before(*f.CreatedBefore) {
+		return false
+	}
+	if f.Platform != "" {
+		isSentToPlatform := false
+		for _, platform := range rs.GetPlatforms() {
+			if platform == f.Platform {
+				isSentToPlatform = true
+				break
+			}
+		}
+		if !isSentToPlatform {
+			return false
+		}
+	}
+	return true
+}
+
+// GetStatus returns the status of the request's approval or
+// StatusPending if it has not been decided
+func (rs *RequestSpec) GetStatus() Status {
+	if rs.Approval == nil {
+		return StatusPending
+	}
+	return rs.Approval.Status
+}
+
+// GetPlatforms returns the platforms which the request is sent to across
+// all of its stages
+func (rs *RequestSpec) GetPlatforms() []Platform {
+	isSlack := len(rs.Slack) > 0
+	isTelegram := len(rs.Telegram) > 0
+	for _, stage := range rs.Stages {
+		isSlack = isSlack || len(stage.Slack) > 0
+		isTelegram = isTelegram || len(stage.Telegram) > 0
+	}
+	platforms := []Platform{}
+	if isSlack {
+		platforms = append(platforms, PlatformSlack)
+	}
+	if isTelegram {
+		platforms = append(platforms, PlatformTelegram)
+	}
+	return platforms
+}
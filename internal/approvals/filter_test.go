@@ -0,0 +1,50 @@
+package approvals
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRequestFilterMatches(t *testing.T) {
+	createdAt := time.Now()
+	req := RequestSpec{
+		CreatedAt:     createdAt,
+		RequesterId:   "u-1",
+		RequesterName: "alex@example.com",
+		Stages: []RequestStageSpec{
+			{Name: "lead", Slack: []SlackRequestSpec{{ChannelNames: []string{"leads"}}}},
+			{Name: "sre", Telegram: []TelegramRequestSpec{{ChatIds: []int64{1}}}},
+		},
+	}
+	if !(RequestFilter{Status: StatusPending, Requester: "alex@example.com", Platform: PlatformTelegram}).Matches(req) {
+		t.Fatalf("expected pending request sent to telegram in a later stage to match")
+	}
+	if (RequestFilter{Status: StatusApproved}).Matches(req) {
+		t.Fatalf("expected pending request to not match approved status")
+	}
+	if (RequestFilter{Requester: "u-2"}).Matches(req) {
+		t.Fatalf("expected request to not match another requester")
+	}
+	after := createdAt.Add(time.Second)
+	if (RequestFilter{CreatedAfter: &after}).Matches(req) {
+		t.Fatalf("expected request to not match when created before createdAfter")
+	}
+	if (RequestFilter{CreatedBefore: &createdAt}).Matches(req) {
+		t.Fatalf("expected createdBefore to be exclusive")
+	}
+	req.Approval = &ApprovalSpec{Status: StatusExpired}
+	if !(RequestFilter{Status: StatusExpired, CreatedAfter: &createdAt}).Matches(req) {
+		t.Fatalf("expected expired request to match expired status")
+	}
+}
+
+func TestRequestFilterValidate(t *testing.T) {
+	if err := (RequestFilter{Status: StatusExpired, Platform: PlatformSlack}).Validate(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	for _, filter := range []RequestFilter{{Status: StatusMfaTriggered}, {Platform: "email"}, {Limit: -1}} {
+		if err := filter.Validate(); err == nil {
+			t.Fatalf("expected filter %+v to be invalid", filter)
+		}
+	}
+}
@@ -1,6 +1,13 @@
 package approvals
 
-import "opsicle/internal/common"
+import (
+	"errors"
+	"fmt"
+	"opsicle/internal/common"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
 
 type Policy struct {
 	common.Resource `json:"resource" yaml:",inline"`
@@ -27,8 +34,181 @@ type PolicySpec struct {
 	// Telegram specifies target chats and authorised responders on the
 	// Telegram communication platform
 	Telegram *TelegramRequestSpec `json:"telegram" yaml:"telegram"`
+
+	// MinApprovals is the number of distinct responders who have to
+	// approve a request before it is approved, defaults to 1; this is
+	// ignored when `.Stages` is defined
+	MinApprovals int `json:"minApprovals" yaml:"minApprovals"`
+
+	// Stages when defined replaces `.Slack` and `.Telegram` with an
+	// ordered list of approval stages, each stage is sent to its targets
+	// only after the previous stage has been approved
+	Stages []StageSpec `json:"stages" yaml:"stages"`
+
+	// DisallowSelfApproval when true prevents the requester from
+	// approving their own request, requesters are matched to responders
+	// by their linked Slack and Telegram users so automations subject to
+	// the policy cannot be run by users without any
+	DisallowSelfApproval bool `json:"disallowSelfApproval" yaml:"disallowSelfApproval"`
+
+	// TtlSeconds is the duration in seconds until requests expire,
+	// defaults to DefaultRequestTtl
+	TtlSeconds int `json:"ttlSeconds" yaml:"ttlSeconds"`
+
+	// Reminders when defined results in reminders being sent to the
+	// targets of requests until they are decided or expire
+	Reminders *ReminderSpec `json:"reminders" yaml:"reminders"`
+
+	// Escalation when defined escalates requests to additional targets
+	// when nobody responds to them in time
+	Escalation *PolicyEscalationSpec `json:"escalation" yaml:"escalation"`
+}
+
+// PolicyEscalationSpec defines the targets requests are escalated to
+// such as a secondary on-call rotation, see EscalationSpec
+type PolicyEscalationSpec struct {
+	// AfterSeconds is the duration in seconds without responses to the
+	// current stage after which a request is escalated
+	AfterSeconds int `json:"afterSeconds" yaml:"afterSeconds"`
+
+	// Slack specifies target channels and authorised responders to
+	// escalate to on the Slack communication platform
+	Slack *SlackRequestSpec `json:"slack" yaml:"slack"`
+
+	// Telegram specifies target chats and authorised responders to
+	// escalate to on the Telegram communication platform
+	Telegram *TelegramRequestSpec `json:"telegram" yaml:"telegram"`
 }
 
-func LoadPolicyFromDirectory() {
+// StageSpec defines a stage of an approval policy such as approval by
+// a team lead followed by approval by the on-call engineer
+type StageSpec struct {
+	// Name is a human-readable name for the stage
+	Name string `json:"name" yaml:"name"`
+
+	// MinApprovals is the number of distinct responders who have to
+	// approve the stage before the next stage starts, defaults to 1
+	MinApprovals int `json:"minApprovals" yaml:"minApprovals"`
+
+	// Slack specifies target channels and authorised responders of the
+	// stage on the Slack communication platform
+	Slack *SlackRequestSpec `json:"slack" yaml:"slack"`
+
+	// Telegram specifies target chats and authorised responders of the
+	// stage on the Telegram communication platform
+	Telegram *TelegramRequestSpec `json:"telegram" yaml:"telegram"`
+}
+
+// GetRequestStages returns the stages of the policy in the form used by
+// approval requests
+func (p PolicySpec) GetRequestStages() []RequestStageSpec {
+	stages := []RequestStageSpec{}
+	for _, stage := range p.Stages {
+		requestStage := RequestStageSpec{
+			Name:         stage.Name,
+			MinApprovals: stage.MinApprovals,
+		}
+		if stage.Slack != nil {
+			requestStage.Slack = []SlackRequestSpec{*stage.Slack}
+		}
+		if stage.Telegram != nil {
+			requestStage.Telegram = []TelegramRequestSpec{*stage.Telegram}
+		}
+		stages = append(stages, requestStage)
+	}
+	return stages
+}
+
+// GetRequestEscalation returns the escalation of the policy in the form
+// used by approval requests
+func (p PolicySpec) GetRequestEscalation() *EscalationSpec {
+	if p.Escalation == nil {
+		return nil
+	}
+	escalation := EscalationSpec{AfterSeconds: p.Escalation.AfterSeconds}
+	if p.Escalation.Slack != nil {
+		escalation.Slack = []SlackRequestSpec{*p.Escalation.Slack}
+	}
+	if p.Escalation.Telegram != nil {
+		escalation.Telegram = []TelegramRequestSpec{*p.Escalation.Telegram}
+	}
+	return &escalation
+}
+
+// Validate returns an error if the policy cannot be stored or used to
+// create approval requests
+func (p Policy) Validate() error {
+	errs := []error{}
+	if p.Type != PolicyResourceType {
+		errs = append(errs, fmt.Errorf("type: expected '%s' but got '%s'", PolicyResourceType, p.Type))
+	}
+	if p.Metadata.Name == "" {
+		errs = append(errs, fmt.Errorf("metadata.name: a name is required"))
+	}
+	if err := p.Spec.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("spec: %w", err))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", ErrorPolicyInvalid, errors.Join(errs...))
+	}
+	return nil
+}
+
+// Validate returns an error if the policy has no one to send requests
+// to or if its approval thresholds or timings are invalid
+func (p PolicySpec) Validate() error {
+	errs := []error{}
+	if p.MinApprovals < 0 {
+		errs = append(errs, fmt.Errorf("minApprovals: must not be negative"))
+	}
+	if len(p.Stages) == 0 && p.Slack == nil && p.Telegram == nil {
+		errs = append(errs, fmt.Errorf("one of slack, telegram or stages is required"))
+	}
+	for i, stage := range p.Stages {
+		if stage.MinApprovals < 0 {
+			errs = append(errs, fmt.Errorf("stages[%v]: minApprovals: must not be negative", i))
+		}
+		if stage.Slack == nil && stage.Telegram == nil {
+			errs = append(errs, fmt.Errorf("stages[%v]: one of slack or telegram is required", i))
+		}
+	}
+	if p.TtlSeconds < 0 {
+		errs = append(errs, fmt.Errorf("ttlSeconds: must not be negative"))
+	}
+	if p.Reminders != nil && p.Reminders.IntervalSeconds <= 0 {
+		errs = append(errs, fmt.Errorf("reminders.intervalSeconds: must be positive"))
+	}
+	if p.Escalation != nil {
+		if p.Escalation.AfterSeconds <= 0 {
+			errs = append(errs, fmt.Errorf("escalation.afterSeconds: must be positive"))
+		}
+		if p.Escalation.Slack == nil && p.Escalation.Telegram == nil {
+			errs = append(errs, fmt.Errorf("escalation: one of slack or telegram is required"))
+		}
+	}
+	return errors.Join(errs...)
+}
 
+// LoadPolicyFromDirectory returns the ApprovalPolicy resource named
+// `name` from the YAML files in `directoryPath`, an error is returned
+// if no or more than one resource matches
+func LoadPolicyFromDirectory(directoryPath, name string) (*Policy, error) {
+	matchedResources, err := common.FindResourceInFilesystem(directoryPath, name, PolicyResourceType)
+	if err != nil {
+		return nil, fmt.Errorf("failed while finding resource of type[%s] with name[%s]: %w", PolicyResourceType, name, err)
+	}
+	if len(matchedResources) == 0 {
+		return nil, fmt.Errorf("failed to find resource of type[%s] with name[%s] in path[%s]: %w", PolicyResourceType, name, directoryPath, ErrorPolicyNotFound)
+	} else if len(matchedResources) > 1 {
+		paths := []string{}
+		for _, matchedResource := range matchedResources {
+			paths = append(paths, matchedResource.Path)
+		}
+		return nil, fmt.Errorf("more than one resource had type[%s] with name[%s]: ['%s']", PolicyResourceType, name, strings.Join(paths, "', '"))
+	}
+	var policy Policy
+	if err := yaml.Unmarshal(matchedResources[0].Data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse file[%s]: %w", matchedResources[0].Path, err)
+	}
+	return &policy, nil
 }
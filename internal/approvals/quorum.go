@@ -0,0 +1,298 @@
+package approvals
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// RequestStageSpec is a stage of an approval request, see StageSpec
+type RequestStageSpec struct {
+	// Name is a human-readable name for the stage
+	Name string `json:"name" yaml:"name"`
+
+	// MinApprovals is the number of distinct responders who have to
+	// approve the stage, defaults to 1
+	MinApprovals int `json:"minApprovals" yaml:"minApprovals"`
+
+	// Slack specifies the targets of the stage in Slack
+	Slack []SlackRequestSpec `json:"slack" yaml:"slack"`
+
+	// Telegram specifies the targets of the stage in Telegram
+	Telegram []TelegramRequestSpec `json:"telegram" yaml:"telegram"`
+}
+
+func (s RequestStageSpec) GetMinApprovals() int {
+	if s.MinApprovals < 1 {
+		return 1
+	}
+	return s.MinApprovals
+}
+
+// RequesterIdentitiesSpec holds the users of a requester on the
+// platforms requests are sent to
+type RequesterIdentitiesSpec struct {
+	// SlackUserIds are the IDs of the requester's Slack users
+	SlackUserIds []string `json:"slackUserIds" yaml:"slackUserIds"`
+
+	// TelegramUserIds are the IDs of the requester's Telegram users
+	TelegramUserIds []int64 `json:"telegramUserIds" yaml:"telegramUserIds"`
+}
+
+// ApproverIdentitySpec holds the users of a user of the requesting
+// system on the platforms requests are sent to
+type ApproverIdentitySpec struct {
+	// UserId is the ID of the user in the requesting system
+	UserId string `json:"userId" yaml:"userId"`
+
+	// SlackUserIds are the IDs of the user's Slack users
+	SlackUserIds []string `json:"slackUserIds" yaml:"slackUserIds"`
+
+	// TelegramUserIds are the IDs of the user's Telegram users
+	TelegramUserIds []int64 `json:"telegramUserIds" yaml:"telegramUserIds"`
+}
+
+// ResponsesSpec holds the responses to a request from all platforms
+type ResponsesSpec struct {
+	Slack    []SlackResponseSpec    `json:"slack" yaml:"slack"`
+	Telegram []TelegramResponseSpec `json:"telegram" yaml:"telegram"`
+}
+
+// ResponseOutcome describes the effect of a response on its request
+type ResponseOutcome struct {
+	// Status is StatusApproved or StatusRejected when the response
+	// decided the request and StatusPending otherwise
+	Status Status
+
+	// Stage is the stage which was responded to
+	Stage RequestStageSpec
+
+	// StageIndex is the index of the stage which was responded to
+	StageIndex int
+
+	// StageCount is the number of stages of the request
+	StageCount int
+
+	// Approvals is the number of approvals the stage has received
+	Approvals int
+
+	// IsStageCompleted is true when the response approved the stage and
+	// the request moved on to the next stage
+	IsStageCompleted bool
+}
+
+// platformUser is the platform of responders which were resolved to
+// their user in the requesting system
+const platformUser Platform = "user"
+
+// responder identifies a responder across platforms
+type responder struct {
+	platform Platform
+	userId   string
+}
+
+// resolveResponder returns the user in `.ApproverIdentities` which the
+// responder is linked to so that a user responding from more than one
+// platform counts as one responder, the responder is returned as is
+// when it is not linked to a user
+func (rs *RequestSpec) resolveResponder(r responder) responder {
+	for _, identity := range rs.ApproverIdentities {
+		isLinked := false
+		switch r.platform {
+		case PlatformSlack:
+			isLinked = slices.Contains(identity.SlackUserIds, r.userId)
+		case PlatformTelegram:
+			for _, telegramUserId := range identity.TelegramUserIds {
+				if fmt.Sprintf("%v", telegramUserId) == r.userId {
+					isLinked = true
+					break
+				}
+			}
+		}
+		if isLinked {
+			return responder{platformUser, identity.UserId}
+		}
+	}
+	return r
+}
+
+// GetStages returns the stages of the request, requests without stages
+// have a single stage made up of their targets
+func (rs *RequestSpec) GetStages() []RequestStageSpec {
+	if len(rs.Stages) > 0 {
+		return rs.Stages
+	}
+	return []RequestStageSpec{
+		{
+			MinApprovals: rs.MinApprovals,
+			Slack:        rs.Slack,
+			Telegram:     rs.Telegram,
+		},
+	}
+}
+
+// ActivateStage makes the targets of the stage at index `stageIndex`
+// the targets of the request so that they are sent the request and are
+// authorised to respond to it
+func (rs *RequestSpec) ActivateStage(stageIndex int) error {
+	if len(rs.Stages) == 0 {
+		return nil
+	}
+	if stageIndex < 0 || stageIndex >= len(rs.Stages) {
+		return fmt.Errorf("failed to find stage[%v] of request[%s]", stageIndex, rs.GetUuid())
+	}
+	stage := rs.Stages[stageIndex]
+	now := time.Now()
+	rs.CurrentStage = stageIndex
+	rs.StageStartedAt = &now
+	rs.Slack = append([]SlackRequestSpec{}, stage.Slack...)
+	rs.Telegram = append([]TelegramRequestSpec{}, stage.Telegram...)
+	return nil
+}
+
+// AddSlackResponse records the response of a Slack user to the current
+// stage of the request
+func (rs *RequestSpec) AddSlackResponse(response SlackResponseSpec) (*ResponseOutcome, error) {
+	if err := rs.assertCanRespond(response.Status, responder{PlatformSlack, response.UserId}); err != nil {
+		return nil, err
+	}
+	if rs.Responses == nil {
+		rs.Responses = &ResponsesSpec{}
+	}
+	response.Stage = rs.CurrentStage
+	if response.Status == StatusApproved && rs.hasApproved(responder{PlatformSlack, response.UserId}) {
+		return nil, fmt.Errorf("user[%s] has already approved request[%s]: %w", response.UserId, rs.GetUuid(), ErrorResponseDuplicated)
+	}
+	rs.Responses.Slack = append(rs.Responses.Slack, response)
+	return rs.evaluate(response.Status)
+}
+
+// AddTelegramResponse records the response of a Telegram user to the
+// current stage of the request
+func (rs *RequestSpec) AddTelegramResponse(response TelegramResponseSpec) (*ResponseOutcome, error) {
+	userId := fmt.Sprintf("%v", response.UserId)
+	if err := rs.assertCanRespond(response.Status, responder{PlatformTelegram, userId}); err != nil {
+		return nil, err
+	}
+	if rs.Responses == nil {
+		rs.Responses = &ResponsesSpec{}
+	}
+	response.Stage = rs.CurrentStage
+	if response.Status == StatusApproved && rs.hasApproved(responder{PlatformTelegram, userId}) {
+		return nil, fmt.Errorf("user[%s] has already approved request[%s]: %w", userId, rs.GetUuid(), ErrorResponseDuplicated)
+	}
+	rs.Responses.Telegram = append(rs.Responses.Telegram, response)
+	return rs.evaluate(response.Status)
+}
+
+// assertCanRespond returns an error if the request has been decided or
+// has expired or if the response is an approval by the requester which the request does
+// not allow
+func (rs *RequestSpec) assertCanRespond(status Status, r responder) error {
+	if rs.Approval != nil {
+		return fmt.Errorf("request[%s] is already %s: %w", rs.GetUuid(), rs.Approval.Status, ErrorRequestDecided)
+	}
+	if rs.IsExpired(time.Now()) {
+		return fmt.Errorf("request[%s] expired at %s: %w", rs.GetUuid(), rs.GetExpiresAt().Format(time.RFC3339), ErrorRequestExpired)
+	}
+	if status == StatusApproved && rs.DisallowSelfApproval && rs.isRequester(r) {
+		return fmt.Errorf("user[%s] requested request[%s]: %w", r.userId, rs.GetUuid(), ErrorSelfApproval)
+	}
+	return nil
+}
+
+// isRequester returns true if the responder is one of the users of the
+// requester in `.RequesterIdentities` or is linked to the requester in
+// `.ApproverIdentities`; users are matched by their IDs on the platform
+// since names can be changed by the users themselves
+func (rs *RequestSpec) isRequester(r responder) bool {
+	if resolved := rs.resolveResponder(r); resolved.platform == platformUser && resolved.userId == rs.RequesterId {
+		return true
+	}
+	if rs.RequesterIdentities == nil {
+		return false
+	}
+	switch r.platform {
+	case PlatformSlack:
+		return slices.Contains(rs.RequesterIdentities.SlackUserIds, r.userId)
+	case PlatformTelegram:
+		for _, telegramUserId := range rs.RequesterIdentities.TelegramUserIds {
+			if fmt.Sprintf("%v", telegramUserId) == r.userId {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasApproved returns true if the responder has approved any stage of
+// the request, a responder can only count towards one approval
+func (rs *RequestSpec) hasApproved(r responder) bool {
+	_, approvers := rs.getApprovers()
+	_, ok := approvers[rs.resolveResponder(r)]
+	return ok
+}
+
+// getApprovers returns the distinct responders who approved the current
+// stage and those who approved any stage, responders are resolved to
+// their linked users before they are counted
+func (rs *RequestSpec) getApprovers() (currentStage, all map[responder]struct{}) {
+	currentStage = map[responder]struct{}{}
+	all = map[responder]struct{}{}
+	if rs.Responses == nil {
+		return currentStage, all
+	}
+	add := func(r responder, stage int, status Status) {
+		if status != StatusApproved {
+			return
+		}
+		r = rs.resolveResponder(r)
+		all[r] = struct{}{}
+		if stage == rs.CurrentStage {
+			currentStage[r] = struct{}{}
+		}
+	}
+	for _, response := range rs.Responses.Slack {
+		add(responder{PlatformSlack, response.UserId}, response.Stage, response.Status)
+	}
+	for _, response := range rs.Responses.Telegram {
+		add(responder{PlatformTelegram, fmt.Sprintf("%v", response.UserId)}, response.Stage, response.Status)
+	}
+	return currentStage, all
+}
+
+// evaluate returns the outcome of the latest response which had the
+// status `status`; a rejection rejects the request while approvals
+// complete the current stage once it has enough of them and approve
+// the request when the last stage is completed
+func (rs *RequestSpec) evaluate(status Status) (*ResponseOutcome, error) {
+	stages := rs.GetStages()
+	if rs.CurrentStage >= len(stages) {
+		return nil, fmt.Errorf("failed to find stage[%v] of request[%s]", rs.CurrentStage, rs.GetUuid())
+	}
+	stage := stages[rs.CurrentStage]
+	currentStageApprovers, _ := rs.getApprovers()
+	outcome := ResponseOutcome{
+		Status:     StatusPending,
+		Stage:      stage,
+		StageIndex: rs.CurrentStage,
+		StageCount: len(stages),
+		Approvals:  len(currentStageApprovers),
+	}
+	if status == StatusRejected {
+		outcome.Status = StatusRejected
+		return &outcome, nil
+	}
+	if outcome.Approvals < stage.GetMinApprovals() {
+		return &outcome, nil
+	}
+	if rs.CurrentStage == len(stages)-1 {
+		outcome.Status = StatusApproved
+		return &outcome, nil
+	}
+	if err := rs.ActivateStage(rs.CurrentStage + 1); err != nil {
+		return nil, err
+	}
+	outcome.IsStageCompleted = true
+	return &outcome, nil
+}
@@ -0,0 +1,132 @@
+package approvals
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRequestRequiresMinApprovalsAcrossPlatforms(t *testing.T) {
+	req := RequestSpec{MinApprovals: 2, RequesterId: "requester"}
+	outcome, err := req.AddSlackResponse(SlackResponseSpec{UserId: "U1", Status: StatusApproved})
+	if err != nil {
+		t.Fatalf("expected first approval to be recorded, got %v", err)
+	}
+	if outcome.Status != StatusPending || outcome.Approvals != 1 {
+		t.Fatalf("expected request to be pending with 1 approval, got %v with %v", outcome.Status, outcome.Approvals)
+	}
+	if _, err := req.AddSlackResponse(SlackResponseSpec{UserId: "U1", Status: StatusApproved}); !errors.Is(err, ErrorResponseDuplicated) {
+		t.Fatalf("expected duplicated response error, got %v", err)
+	}
+	outcome, err = req.AddTelegramResponse(TelegramResponseSpec{UserId: 42, Status: StatusApproved})
+	if err != nil {
+		t.Fatalf("expected second approval to be recorded, got %v", err)
+	}
+	if outcome.Status != StatusApproved || outcome.Approvals != 2 {
+		t.Fatalf("expected request to be approved with 2 approvals, got %v with %v", outcome.Status, outcome.Approvals)
+	}
+	if len(req.Responses.Slack) != 1 || len(req.Responses.Telegram) != 1 {
+		t.Fatalf("expected one response per platform, got %v", req.Responses)
+	}
+}
+
+func TestRequestStagesAreApprovedInOrder(t *testing.T) {
+	req := RequestSpec{
+		Stages: []RequestStageSpec{
+			{Name: "lead", Slack: []SlackRequestSpec{{ChannelNames: []string{"leads"}}}},
+			{Name: "sre", MinApprovals: 2, Telegram: []TelegramRequestSpec{{ChatIds: []int64{1}}}},
+		},
+	}
+	if err := req.ActivateStage(0); err != nil {
+		t.Fatalf("expected first stage to be activated, got %v", err)
+	}
+	if len(req.Slack) != 1 || len(req.Telegram) != 0 {
+		t.Fatalf("expected targets of the first stage only, got %v and %v", req.Slack, req.Telegram)
+	}
+	outcome, err := req.AddSlackResponse(SlackResponseSpec{UserId: "U1", Status: StatusApproved})
+	if err != nil {
+		t.Fatalf("expected approval to be recorded, got %v", err)
+	}
+	if !outcome.IsStageCompleted || outcome.Status != StatusPending || req.CurrentStage != 1 {
+		t.Fatalf("expected first stage to be completed, got %+v", outcome)
+	}
+	if len(req.Slack) != 0 || len(req.Telegram) != 1 {
+		t.Fatalf("expected targets of the second stage only, got %v and %v", req.Slack, req.Telegram)
+	}
+	if outcome, _ := req.AddTelegramResponse(TelegramResponseSpec{UserId: 1, Status: StatusApproved}); outcome.Status != StatusPending || outcome.Approvals != 1 {
+		t.Fatalf("expected second stage to be pending with 1 approval, got %+v", outcome)
+	}
+	outcome, err = req.AddTelegramResponse(TelegramResponseSpec{UserId: 2, Status: StatusRejected})
+	if err != nil {
+		t.Fatalf("expected rejection to be recorded, got %v", err)
+	}
+	if outcome.Status != StatusRejected {
+		t.Fatalf("expected request to be rejected, got %v", outcome.Status)
+	}
+}
+
+func TestRequestDisallowsSelfApproval(t *testing.T) {
+	req := RequestSpec{
+		DisallowSelfApproval: true,
+		RequesterId:          "0f8fad5b-d9cb-469f-a165-70867728950e",
+		RequesterName:        "alex@example.com",
+		RequesterIdentities: &RequesterIdentitiesSpec{
+			SlackUserIds:    []string{"U024BE7LH"},
+			TelegramUserIds: []int64{123456789},
+		},
+	}
+	if _, err := req.AddSlackResponse(SlackResponseSpec{UserId: "U024BE7LH", UserName: "alex", Status: StatusApproved}); !errors.Is(err, ErrorSelfApproval) {
+		t.Fatalf("expected self approval error from slack, got %v", err)
+	}
+	if _, err := req.AddTelegramResponse(TelegramResponseSpec{UserId: 123456789, Username: "alex_ops", Status: StatusApproved}); !errors.Is(err, ErrorSelfApproval) {
+		t.Fatalf("expected self approval error from telegram, got %v", err)
+	}
+	if _, err := req.AddSlackResponse(SlackResponseSpec{UserId: "U0G9QF9C6", UserName: "alex@example.com", Status: StatusApproved}); err != nil {
+		t.Fatalf("expected another user with a name like the requester's to be able to approve, got %v", err)
+	}
+	req.Responses = nil
+	outcome, err := req.AddSlackResponse(SlackResponseSpec{UserId: "U024BE7LH", UserName: "alex", Status: StatusRejected})
+	if err != nil || outcome.Status != StatusRejected {
+		t.Fatalf("expected requester to be able to reject, got %v", err)
+	}
+	req.Approval = &ApprovalSpec{Status: StatusRejected}
+	if _, err := req.AddSlackResponse(SlackResponseSpec{UserId: "U2", Status: StatusApproved}); !errors.Is(err, ErrorRequestDecided) {
+		t.Fatalf("expected decided request error, got %v", err)
+	}
+}
+
+func TestRequestCountsLinkedUsersAsOneApprover(t *testing.T) {
+	req := RequestSpec{
+		MinApprovals: 2,
+		RequesterId:  "requester",
+		ApproverIdentities: []ApproverIdentitySpec{
+			{UserId: "approver", SlackUserIds: []string{"U1"}, TelegramUserIds: []int64{42}},
+			{UserId: "other-approver", TelegramUserIds: []int64{43}},
+		},
+	}
+	if _, err := req.AddSlackResponse(SlackResponseSpec{UserId: "U1", Status: StatusApproved}); err != nil {
+		t.Fatalf("expected first approval to be recorded, got %v", err)
+	}
+	if _, err := req.AddTelegramResponse(TelegramResponseSpec{UserId: 42, Status: StatusApproved}); !errors.Is(err, ErrorResponseDuplicated) {
+		t.Fatalf("expected approval of the same user from another platform to be duplicated, got %v", err)
+	}
+	outcome, err := req.AddTelegramResponse(TelegramResponseSpec{UserId: 43, Status: StatusApproved})
+	if err != nil {
+		t.Fatalf("expected second approval to be recorded, got %v", err)
+	}
+	if outcome.Status != StatusApproved || outcome.Approvals != 2 {
+		t.Fatalf("expected request to be approved with 2 approvals, got %v with %v", outcome.Status, outcome.Approvals)
+	}
+}
+
+func TestRequestDisallowsSelfApprovalOfLinkedUsers(t *testing.T) {
+	req := RequestSpec{
+		DisallowSelfApproval: true,
+		RequesterId:          "requester",
+		ApproverIdentities: []ApproverIdentitySpec{
+			{UserId: "requester", SlackUserIds: []string{"U1"}},
+		},
+	}
+	if _, err := req.AddSlackResponse(SlackResponseSpec{UserId: "U1", Status: StatusApproved}); !errors.Is(err, ErrorSelfApproval) {
+		t.Fatalf("expected self approval error, got %v", err)
+	}
+}
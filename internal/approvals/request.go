@@ -3,6 +3,7 @@ package approvals
 import (
 	"opsicle/internal/common"
 	"os"
+	"time"
 
 	"github.com/google/uuid"
 	"gopkg.in/yaml.v3"
@@ -22,14 +23,44 @@ type RequestSpec struct {
 	// happens
 	Approval *ApprovalSpec `json:"approval" yaml:"approval"`
 
+	// ApproverIdentities are the users of the requesting system who can
+	// respond to the request with their users on the platforms the
+	// request is sent to, responses from the users of one user count as
+	// a single responder towards `.MinApprovals`
+	ApproverIdentities []ApproverIdentitySpec `json:"approverIdentities" yaml:"approverIdentities"`
+
 	// Callback is a field that when specified, results in the approver
 	// service processing a callback to the specified endpoint
 	Callback *CallbackSpec `json:"callback" yaml:"callback"`
 
+	// CreatedAt is populated by the approver service when the request is
+	// created and is the time from which `.TtlSeconds` is counted
+	CreatedAt time.Time `json:"createdAt" yaml:"createdAt"`
+
+	// Escalation when defined escalates the request to additional
+	// targets when nobody responds to it in time
+	Escalation *EscalationSpec `json:"escalation" yaml:"escalation"`
+
+	// EscalatedAt is populated by the approver service when the request
+	// is escalated
+	EscalatedAt *time.Time `json:"escalatedAt" yaml:"escalatedAt"`
+
 	// Id is the ID of a request which will be the same for all
 	// requests of a given type
 	Id string `json:"id" yaml:"id"`
 
+	// CurrentStage is the index of the stage in `.Stages` which is
+	// awaiting approval
+	CurrentStage int `json:"currentStage" yaml:"currentStage"`
+
+	// DisallowSelfApproval when true prevents the requester from
+	// approving their own request
+	DisallowSelfApproval bool `json:"disallowSelfApproval" yaml:"disallowSelfApproval"`
+
+	// LastRemindedAt is populated by the approver service when a
+	// reminder is sent
+	LastRemindedAt *time.Time `json:"lastRemindedAt" yaml:"lastRemindedAt"`
+
 	// Links are optional additional links to view the request in a browser or
 	// other application,
 	Links []RequestLinkAttachment `json:"links" yaml:"links"`
@@ -37,15 +68,43 @@ type RequestSpec struct {
 	// Message is an additional message describing the request
 	Message string `json:"message" yaml:"message"`
 
+	// MinApprovals is the number of distinct responders who have to
+	// approve the request when it has no `.Stages`, defaults to 1
+	MinApprovals int `json:"minApprovals" yaml:"minApprovals"`
+
+	// Reminders when defined results in reminders being sent to the
+	// targets of the request until it is decided or expires
+	Reminders *ReminderSpec `json:"reminders" yaml:"reminders"`
+
+	// RemindersSent is the number of reminders sent for the request
+	RemindersSent int `json:"remindersSent" yaml:"remindersSent"`
+
+	// Responses aggregates the responses from all platforms received
+	// before the request is decided
+	Responses *ResponsesSpec `json:"responses" yaml:"responses"`
+
 	// RequesterName indicates the requester's system ID
 	RequesterId string `json:"requesterId" yaml:"requesterId"`
 
 	// RequesterName indicates the requester's name
 	RequesterName string `json:"requesterName" yaml:"requesterName"`
 
+	// RequesterIdentities are the users of the requester on the
+	// platforms the request is sent to, these are used to recognise the
+	// requester when `.DisallowSelfApproval` is true
+	RequesterIdentities *RequesterIdentitiesSpec `json:"requesterIdentities" yaml:"requesterIdentities"`
+
 	// Slack specifies the targets in Slack to send this request to
 	Slack []SlackRequestSpec `json:"slack" yaml:"slack"`
 
+	// StageStartedAt is populated by the approver service when the
+	// current stage of a request with `.Stages` is sent to its targets
+	StageStartedAt *time.Time `json:"stageStartedAt" yaml:"stageStartedAt"`
+
+	// Stages when defined are sent to their targets one after another,
+	// `.Slack` and `.Telegram` hold the targets of the current stage
+	Stages []RequestStageSpec `json:"stages" yaml:"stages"`
+
 	// Telegram specifies the targets in Telegram to send this request to
 	Telegram []TelegramRequestSpec `json:"telegram" yaml:"telegram"`
 
@@ -53,7 +112,8 @@ type RequestSpec struct {
 	// line of the approval request message
 	Title *string `json:"title" yaml:"title"`
 
-	// TtlSeconds indicates the duration in seconds until the request expires
+	// TtlSeconds indicates the duration in seconds until the request
+	// expires, defaults to DefaultRequestTtl
 	TtlSeconds int `json:"ttlSeconds" yaml:"ttlSeconds"`
 
 	// Uuid is populated by the approver service if it's not already
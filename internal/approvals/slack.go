@@ -22,6 +22,7 @@ type SlackRequestSpec struct {
 type SlackResponseSpec struct {
 	ChannelId  string    `json:"channelId" yaml:"channelId"`
 	ReceivedAt time.Time `json:"receivedAt" yaml:"receivedAt"`
+	Stage      int       `json:"stage" yaml:"stage"`
 	Status     Status    `json:"status" yaml:"status"`
 	UserId     string    `json:"userId" yaml:"userId"`
 	UserName   string    `json:"userName" yaml:"userName"`
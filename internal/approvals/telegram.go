@@ -19,5 +19,6 @@ type TelegramResponseSpec struct {
 	Username   string    `json:"username" yaml:"username"`
 	UserId     int64     `json:"userId" yaml:"userId"`
 	ReceivedAt time.Time `json:"receivedAt" yaml:"receivedAt"`
+	Stage      int       `json:"stage" yaml:"stage"`
 	Status     Status    `json:"status" yaml:"status"`
 }
@@ -5,56 +5,81 @@ import (
 	"fmt"
 	"opsicle/internal/approvals"
 	"opsicle/internal/cache"
+	"sort"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	approvalRequestLockPrefix   = "lock:approvreq"
+	approvalRequestLockTtl      = 30 * time.Second
+	approvalRequestLockTimeout  = 10 * time.Second
+	approvalRequestLockInterval = 100 * time.Millisecond
+
+	// approvalRequestCacheGracePeriod is how long an approval request
+	// stays cached after it expires, the cached copy is the only one
+	// which holds the credentials of its callback so the sweeper must
+	// expire the request within this period for its callback to succeed
+	approvalRequestCacheGracePeriod = 24 * time.Hour
 )
 
 type ApprovalRequest struct {
 	Spec approvals.RequestSpec `json:"spec" yaml:"spec"`
+
+	// isFromStore indicates that the request was loaded from the store
+	// and carries redacted secrets, such requests are never cached
+	// again so that the redacted values do not replace the real ones
+	isFromStore bool
 }
 
 func (req *ApprovalRequest) Create() error {
-	cacheInstance := cache.Get()
-	cacheKey := CreateApprovalRequestCacheKey(req.Spec.GetUuid())
-	cacheData, err := json.Marshal(req)
-	if err != nil {
-		return fmt.Errorf("failed to marshal approvalRequest[%s]: %s", req.Spec.GetUuid(), err)
+	if !req.isFromStore {
+		cacheInstance := cache.Get()
+		cacheKey := CreateApprovalRequestCacheKey(req.Spec.GetUuid())
+		cacheData, err := json.Marshal(req)
+		if err != nil {
+			return fmt.Errorf("failed to marshal approvalRequest[%s]: %s", req.Spec.GetUuid(), err)
+		}
+		// the cache entry outlives the request so that the sweeper can
+		// expire it and notify everyone involved
+		expiryDuration := max(time.Until(req.Spec.GetExpiresAt()), 0) + approvalRequestCacheGracePeriod
+		if err := cacheInstance.Set(cacheKey, string(cacheData), expiryDuration); err != nil {
+			return fmt.Errorf("failed to set cache for approvalRequest[%s]: %s", req.Spec.GetUuid(), err)
+		}
 	}
-	expiryDuration := time.Duration(req.Spec.TtlSeconds)*time.Second + time.Hour
-	if err := cacheInstance.Set(cacheKey, string(cacheData), expiryDuration); err != nil {
-		return fmt.Errorf("failed to set cache for approvalRequest[%s]: %s", req.Spec.GetUuid(), err)
+	if store != nil {
+		if err := store.SaveApprovalRequest(req.Spec); err != nil {
+			return fmt.Errorf("failed to store approvalRequest[%s]: %s", req.Spec.GetUuid(), err)
+		}
 	}
 	return nil
 }
 
+// hasRedactedCallback returns true if the request was loaded from the
+// store and the credentials of its callback are therefore redacted
+func (req *ApprovalRequest) hasRedactedCallback() bool {
+	if !req.isFromStore || req.Spec.Callback == nil || req.Spec.Callback.Webhook == nil {
+		return false
+	}
+	return req.Spec.Callback.Webhook.Auth != nil
+}
+
 func (req *ApprovalRequest) Exists() bool {
 	cacheInstance := cache.Get()
 	cacheKey := CreateApprovalRequestCacheKey(req.Spec.GetUuid())
 	if _, err := cacheInstance.Get(cacheKey); err != nil {
+		if store != nil {
+			_, err := store.GetApprovalRequest(req.Spec.GetUuid())
+			return err == nil
+		}
 		return false
 	}
 	return true
 }
 
-func (req *ApprovalRequest) GetRedacted() ApprovalRequest {
-	approvalRequest := *req
-	redactedText := "<REDACTED>"
-	for i, target := range approvalRequest.Spec.Slack {
-		for j, authorizedResponder := range target.AuthorizedResponders {
-			if authorizedResponder.MfaSeed != nil {
-				approvalRequest.Spec.Slack[i].AuthorizedResponders[j].MfaSeed = &redactedText
-			}
-		}
-	}
-	for i, target := range approvalRequest.Spec.Telegram {
-		for j, authorizedResponder := range target.AuthorizedResponders {
-			if authorizedResponder.MfaSeed != nil {
-				approvalRequest.Spec.Telegram[i].AuthorizedResponders[j].MfaSeed = &redactedText
-			}
-		}
-	}
-	return approvalRequest
-}
-
 func (req *ApprovalRequest) Update() error {
 	if isExists := req.Exists(); !isExists {
 		return fmt.Errorf("failed to find an existing approvalRequest[%s]", req.Spec.GetUuid())
@@ -70,10 +95,161 @@ func (req *ApprovalRequest) Load() error {
 	cacheKey := CreateApprovalRequestCacheKey(req.Spec.GetUuid())
 	value, err := cacheInstance.Get(cacheKey)
 	if err != nil {
-		return fmt.Errorf("failed to get approvalRequest[%s]: %s", cacheKey, err)
+		if store == nil {
+			return fmt.Errorf("failed to get approvalRequest[%s]: %s", cacheKey, err)
+		}
+		// requests which are no longer cached are loaded from the store
+		// with their mfa seeds redacted
+		spec, storeErr := store.GetApprovalRequest(req.Spec.GetUuid())
+		if storeErr != nil {
+			return fmt.Errorf("failed to get approvalRequest[%s]: %s (from store: %w)", cacheKey, err, storeErr)
+		}
+		req.Spec = *spec
+		req.isFromStore = true
+		return nil
 	}
 	if err := json.Unmarshal([]byte(value), req); err != nil {
 		return fmt.Errorf("failed to unmarshal approvalRequest[%s]: %s (full object: %s)", cacheKey, err, value)
 	}
+	req.isFromStore = false
 	return nil
 }
+
+// lockApprovalRequest acquires the lock which serialises updates to the
+// approval request and reloads it so that changes recorded while waiting
+// for the lock, such as the responses of other responders, are not
+// overwritten; the returned function releases the lock and is safe to
+// call more than once
+func lockApprovalRequest(req *ApprovalRequest) (func(), error) {
+	cacheInstance := cache.Get()
+	lockKey := strings.Join([]string{approvalRequestLockPrefix, req.Spec.GetUuid()}, ":")
+	owner := uuid.NewString()
+	deadline := time.Now().Add(approvalRequestLockTimeout)
+	for {
+		isLocked, err := cache.AcquireLock(cacheInstance, lockKey, owner, approvalRequestLockTtl)
+		if err != nil {
+			return nil, err
+		} else if isLocked {
+			break
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for lock of approvalRequest[%s]", req.Spec.GetUuid())
+		}
+		<-time.After(approvalRequestLockInterval)
+	}
+	var releaseOnce sync.Once
+	unlock := func() {
+		releaseOnce.Do(func() {
+			// a lock which fails to be released expires after its ttl
+			_ = cache.ReleaseLock(cacheInstance, lockKey, owner)
+		})
+	}
+	if err := req.Load(); err != nil {
+		unlock()
+		return nil, err
+	}
+	return unlock, nil
+}
+
+// getRedactedRequestSpec returns a copy of `spec` with the MFA seeds of
+// all its targets and the credentials of its callback redacted, `spec`
+// is not modified
+func getRedactedRequestSpec(spec approvals.RequestSpec) (approvals.RequestSpec, error) {
+	var redacted approvals.RequestSpec
+	specData, err := json.Marshal(spec)
+	if err != nil {
+		return redacted, fmt.Errorf("failed to marshal approvalRequest[%s]: %w", spec.GetUuid(), err)
+	}
+	if err := json.Unmarshal(specData, &redacted); err != nil {
+		return redacted, fmt.Errorf("failed to unmarshal approvalRequest[%s]: %w", spec.GetUuid(), err)
+	}
+	redactedText := "<REDACTED>"
+	redact := func(slackTargets []approvals.SlackRequestSpec, telegramTargets []approvals.TelegramRequestSpec) {
+		for _, target := range slackTargets {
+			for i := range target.AuthorizedResponders {
+				if target.AuthorizedResponders[i].MfaSeed != nil {
+					target.AuthorizedResponders[i].MfaSeed = &redactedText
+				}
+			}
+		}
+		for _, target := range telegramTargets {
+			for i := range target.AuthorizedResponders {
+				if target.AuthorizedResponders[i].MfaSeed != nil {
+					target.AuthorizedResponders[i].MfaSeed = &redactedText
+				}
+			}
+		}
+	}
+	redact(redacted.Slack, redacted.Telegram)
+	for _, stage := range redacted.Stages {
+		redact(stage.Slack, stage.Telegram)
+	}
+	if redacted.Escalation != nil {
+		redact(redacted.Escalation.Slack, redacted.Escalation.Telegram)
+	}
+	if redacted.Callback != nil && redacted.Callback.Webhook != nil && redacted.Callback.Webhook.Auth != nil {
+		auth := redacted.Callback.Webhook.Auth
+		if auth.Basic != nil {
+			auth.Basic.Password = redactedText
+		}
+		if auth.Bearer != nil {
+			auth.Bearer.Value = redactedText
+		}
+		if auth.Header != nil {
+			auth.Header.Value = redactedText
+		}
+	}
+	return redacted, nil
+}
+
+// listApprovalRequests returns the approval requests matching `filter`
+// from the newest to the oldest with their mfa seeds and callback
+// credentials redacted; requests
+// are listed from the store when one is configured and from the cache
+// otherwise
+func listApprovalRequests(filter approvals.RequestFilter) ([]approvals.RequestSpec, error) {
+	if store != nil {
+		requests, err := store.ListApprovalRequests(filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list approval requests from store: %w", err)
+		}
+		for i := range requests {
+			if requests[i], err = getRedactedRequestSpec(requests[i]); err != nil {
+				return nil, err
+			}
+		}
+		return requests, nil
+	}
+	keys, err := cache.Get().Scan(CreateApprovalRequestCacheKey("*"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list approval request keys: %w", err)
+	}
+	requests := []approvals.RequestSpec{}
+	for _, key := range keys {
+		requestUuid := StripCacheKeyPrefix(key)
+		req := ApprovalRequest{Spec: approvals.RequestSpec{Uuid: &requestUuid}}
+		if err := req.Load(); err != nil {
+			// the request may have expired from the cache in between
+			continue
+		}
+		if !filter.Matches(req.Spec) {
+			continue
+		}
+		redacted, err := getRedactedRequestSpec(req.Spec)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, redacted)
+	}
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].CreatedAt.After(requests[j].CreatedAt)
+	})
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultListApprovalRequestsLimit
+	}
+	if len(requests) > limit {
+		requests = requests[:limit]
+	}
+	return requests, nil
+}
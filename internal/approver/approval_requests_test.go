@@ -0,0 +1,64 @@
+package approver
+
+import (
+	"opsicle/internal/approvals"
+	"testing"
+)
+
+func TestGetRedactedRequestSpec(t *testing.T) {
+	mfaSeed := "JBSWY3DPEHPK3PXP"
+	spec := approvals.RequestSpec{
+		Callback: &approvals.CallbackSpec{
+			Type: approvals.CallbackWebhook,
+			Webhook: &approvals.WebhookCallbackSpec{
+				Auth: &approvals.WebhookCallbackAuthSpec{
+					Header: &approvals.WebhookCallbackHeaderAuthSpec{Key: "x-approval-token", Value: "token"},
+				},
+			},
+		},
+		Slack: []approvals.SlackRequestSpec{
+			{AuthorizedResponders: []approvals.AuthorizedResponder{{MfaSeed: &mfaSeed}}},
+		},
+	}
+	redacted, err := getRedactedRequestSpec(spec)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if redacted.Callback.Webhook.Auth.Header.Value == "token" {
+		t.Fatalf("expected callback header value to be redacted")
+	}
+	if redacted.Callback.Webhook.Auth.Header.Key != "x-approval-token" {
+		t.Fatalf("expected callback header key to be kept")
+	}
+	if *redacted.Slack[0].AuthorizedResponders[0].MfaSeed == mfaSeed {
+		t.Fatalf("expected mfa seed to be redacted")
+	}
+	if spec.Callback.Webhook.Auth.Header.Value != "token" || *spec.Slack[0].AuthorizedResponders[0].MfaSeed != mfaSeed {
+		t.Fatalf("expected original spec to be left as is")
+	}
+}
+
+func TestApprovalRequestHasRedactedCallback(t *testing.T) {
+	spec := approvals.RequestSpec{
+		Callback: &approvals.CallbackSpec{
+			Type: approvals.CallbackWebhook,
+			Webhook: &approvals.WebhookCallbackSpec{
+				Auth: &approvals.WebhookCallbackAuthSpec{
+					Header: &approvals.WebhookCallbackHeaderAuthSpec{Key: "x-approval-token", Value: "token"},
+				},
+			},
+		},
+	}
+	cachedRequest := ApprovalRequest{Spec: spec}
+	if cachedRequest.hasRedactedCallback() {
+		t.Fatalf("expected the callback of a cached request to not be redacted")
+	}
+	storedRequest := ApprovalRequest{Spec: spec, isFromStore: true}
+	if !storedRequest.hasRedactedCallback() {
+		t.Fatalf("expected the callback of a stored request to be redacted")
+	}
+	storedRequest.Spec.Callback.Webhook.Auth = nil
+	if storedRequest.hasRedactedCallback() {
+		t.Fatalf("expected a callback without credentials to not be redacted")
+	}
+}
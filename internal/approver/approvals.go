@@ -23,6 +23,11 @@ func (a *Approval) Create() error {
 	if err := cacheInstance.Set(cacheKey, string(cacheData), expiryDuration); err != nil {
 		return fmt.Errorf("failed to set cache with key[%s] for approval[%s]: %s", cacheKey, a.Spec.Id, err)
 	}
+	if store != nil {
+		if err := store.SaveApproval(a.Spec); err != nil {
+			return fmt.Errorf("failed to store approval[%s]: %s", a.Spec.Id, err)
+		}
+	}
 	return nil
 }
 
@@ -30,6 +35,10 @@ func (a *Approval) Exists() bool {
 	cacheInstance := cache.Get()
 	cacheKey := CreateApprovalCacheKey(a.Spec.Id)
 	if _, err := cacheInstance.Get(cacheKey); err != nil {
+		if store != nil {
+			_, err := store.GetApproval(a.Spec.Id)
+			return err == nil
+		}
 		return false
 	}
 	return true
@@ -50,7 +59,15 @@ func (a *Approval) Load() error {
 	cacheKey := CreateApprovalCacheKey(a.Spec.Id)
 	value, err := cacheInstance.Get(cacheKey)
 	if err != nil {
-		return fmt.Errorf("failed to get approval[%s] with key[%s]: %s", a.Spec.Id, cacheKey, err)
+		if store == nil {
+			return fmt.Errorf("failed to get approval[%s] with key[%s]: %s", a.Spec.Id, cacheKey, err)
+		}
+		spec, storeErr := store.GetApproval(a.Spec.Id)
+		if storeErr != nil {
+			return fmt.Errorf("failed to get approval[%s] with key[%s]: %s (from store: %w)", a.Spec.Id, cacheKey, err, storeErr)
+		}
+		a.Spec = *spec
+		return nil
 	}
 	if err := json.Unmarshal([]byte(value), a); err != nil {
 		return fmt.Errorf("failed to unmarshal approval[%s]: %s", a.Spec.Id, err)
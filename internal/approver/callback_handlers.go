@@ -29,9 +29,11 @@ func getWebhookCallbackRequest(opts approvals.WebhookCallbackSpec) (*http.Reques
 		method = http.MethodPost
 	}
 	req := http.Request{
+		Header: http.Header{},
 		Method: method,
 		URL:    targetUrl,
 	}
+	req.Header.Set("Content-Type", "application/json")
 	if opts.Auth != nil {
 		if opts.Auth.Basic != nil {
 			username := opts.Auth.Basic.Username
@@ -104,8 +106,10 @@ func handleWebhookCallback(opts handleCallbackOpts) error {
 	if err != nil {
 		return fmt.Errorf("failed to create webhook callback request: %w", err)
 	}
-	approvalData, _ := json.Marshal(opts.Req.Spec.Approval)
-	webhookRequest.Body = io.NopCloser(bytes.NewBuffer(approvalData))
+	approvalData, err := json.Marshal(opts.Req.Spec.Approval)
+	if err != nil {
+		return fmt.Errorf("failed to marshal approval: %w", err)
+	}
 
 	// setup for the retry loop
 
@@ -129,7 +133,13 @@ func handleWebhookCallback(opts handleCallbackOpts) error {
 
 	startedAt := time.Now()
 	for !isRequestSuccessful {
+		// the body is consumed by each attempt
+		webhookRequest.Body = io.NopCloser(bytes.NewBuffer(approvalData))
+		webhookRequest.ContentLength = int64(len(approvalData))
 		webhookResponse, err := webhookClient.Do(webhookRequest)
+		if err == nil {
+			webhookResponse.Body.Close()
+		}
 		if err != nil {
 			opts.ServiceLogs <- common.ServiceLogf(common.LogLevelWarn, "attempt[%v/%v] failed to execute webhook callback request: %s", currentRetryAttempt, retryCount, err)
 		} else if webhookResponse.StatusCode != http.StatusOK {
@@ -5,8 +5,12 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"opsicle/internal/cache"
+	"net/url"
+	"opsicle/internal/approvals"
 	"opsicle/internal/common"
+	"opsicle/internal/types"
+	"strconv"
+	"time"
 
 	"opsicle/pkg/approver"
 
@@ -62,6 +66,11 @@ func getCreateApprovalRequestHandler() http.HandlerFunc {
 
 		log(common.LogLevelDebug, "storing approval request...")
 		req.Spec.Init()
+		req.Spec.CreatedAt = time.Now()
+		if err := req.Spec.ActivateStage(0); err != nil {
+			common.SendHttpFailResponse(w, r, http.StatusBadRequest, "failed to activate the first stage of the approval request", err)
+			return
+		}
 		if err := req.Create(); err != nil {
 			common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to create approval request", err)
 			return
@@ -85,7 +94,7 @@ func getCreateApprovalRequestHandler() http.HandlerFunc {
 
 // getGetApprovalHandler godoc
 // @Summary      Retreives an approval given it's ID
-// @Description  This endpoint retrieves an approval given it's ID
+// @Description  This endpoint retrieves an approval given it's ID, approvals which are no longer cached are retrieved from the store
 // @Tags         approver-service
 // @Accept       json
 // @Produce      json
@@ -96,22 +105,18 @@ func getCreateApprovalRequestHandler() http.HandlerFunc {
 // @Failure      500 {object} commonHttpResponse "Internal server error"
 // @Router       /api/v1/approval/{approvalUuid} [get]
 func getGetApprovalHandler() http.HandlerFunc {
-	cacheInstance := cache.Get()
 	return func(w http.ResponseWriter, r *http.Request) {
 		log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
 		approvalUuid := mux.Vars(r)["approvalUuid"]
 		log(common.LogLevelDebug, fmt.Sprintf("received request for status of approval[%s]", approvalUuid))
 
-		cacheKey := CreateApprovalCacheKey(approvalUuid)
-		log(common.LogLevelDebug, fmt.Sprintf("retrieving cache item with key[%s]...", cacheKey))
-		approvalData, err := cacheInstance.Get(cacheKey)
-		if err != nil {
-			common.SendHttpFailResponse(w, r, http.StatusInternalServerError, fmt.Sprintf("failed to retrieve cache item with key[%s]", cacheKey), err)
+		approval := Approval{Spec: approvals.ApprovalSpec{Id: approvalUuid}}
+		if !approval.Exists() {
+			common.SendHttpFailResponse(w, r, http.StatusNotFound, fmt.Sprintf("failed to find approval[%s]", approvalUuid), types.ErrorNotFound)
 			return
 		}
-		var approval Approval
-		if err := json.Unmarshal([]byte(approvalData), &approval); err != nil {
-			common.SendHttpFailResponse(w, r, http.StatusInternalServerError, fmt.Sprintf("failed to unmarshal approval[%s]", approvalUuid), err)
+		if err := approval.Load(); err != nil {
+			common.SendHttpFailResponse(w, r, http.StatusInternalServerError, fmt.Sprintf("failed to retrieve approval[%s]", approvalUuid), err)
 			return
 		}
 		common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", approval.Spec)
@@ -119,8 +124,8 @@ func getGetApprovalHandler() http.HandlerFunc {
 }
 
 // getGetApprovalRequestHandler godoc
-// @Summary      Retreives all approval requests
-// @Description  This endpoint retrieves all approval requests
+// @Summary      Retreives an approval request given it's UUID
+// @Description  This endpoint retrieves an approval request given it's UUID, approval requests which are no longer cached are retrieved from the store
 // @Tags         approver-service
 // @Accept       json
 // @Produce      json
@@ -132,58 +137,97 @@ func getGetApprovalHandler() http.HandlerFunc {
 // @Router       /api/v1/approval-request/{requestUuid} [get]
 func getGetApprovalRequestHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		cacheInstance := cache.Get()
 		log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
 		requestUuid := mux.Vars(r)["requestUuid"]
 		log(common.LogLevelDebug, fmt.Sprintf("received request for status of approvalRequest[%s]", requestUuid))
 
-		cacheKey := CreateApprovalRequestCacheKey(requestUuid)
-		log(common.LogLevelDebug, fmt.Sprintf("retrieving cache item with key[%s]...", cacheKey))
-		approvalRequestData, err := cacheInstance.Get(cacheKey)
-		if err != nil {
-			common.SendHttpFailResponse(w, r, http.StatusInternalServerError, fmt.Sprintf("failed to retrieve cache item with key[%s]", cacheKey), err)
+		approvalRequest := ApprovalRequest{Spec: approvals.RequestSpec{Uuid: &requestUuid}}
+		if !approvalRequest.Exists() {
+			common.SendHttpFailResponse(w, r, http.StatusNotFound, fmt.Sprintf("failed to find approvalRequest[%s]", requestUuid), types.ErrorNotFound)
+			return
+		}
+		if err := approvalRequest.Load(); err != nil {
+			common.SendHttpFailResponse(w, r, http.StatusInternalServerError, fmt.Sprintf("failed to retrieve approvalRequest[%s]", requestUuid), err)
 			return
 		}
-		var approvalRequest ApprovalRequest
-		if err := json.Unmarshal([]byte(approvalRequestData), &approvalRequest); err != nil {
-			common.SendHttpFailResponse(w, r, http.StatusInternalServerError, fmt.Sprintf("failed to unmarshal approvalRequest[%s]", requestUuid), err)
+		redacted, err := getRedactedRequestSpec(approvalRequest.Spec)
+		if err != nil {
+			common.SendHttpFailResponse(w, r, http.StatusInternalServerError, fmt.Sprintf("failed to redact approvalRequest[%s]", requestUuid), err)
 			return
 		}
-		common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", approvalRequest.GetRedacted())
+		common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", ApprovalRequest{Spec: redacted})
 	}
 }
 
 // getListApprovalRequestsHandler godoc
-// @Summary      Retreives all approval requests
-// @Description  This endpoint retrieves all approval requests
+// @Summary      Retreives approval requests
+// @Description  This endpoint retrieves approval requests from the newest to the oldest, approval requests are retrieved from the store when one is configured
 // @Tags         approver-service
 // @Accept       json
 // @Produce      json
 // @Security     BasicAuth
+// @Param        status query string false "Status of the approval requests (pending, approved, rejected or expired)"
+// @Param        requester query string false "ID or name of the requester"
+// @Param        createdAfter query string false "RFC3339 timestamp which approval requests were created at or after"
+// @Param        createdBefore query string false "RFC3339 timestamp which approval requests were created before"
+// @Param        platform query string false "Platform the approval requests were sent to (slack or telegram)"
+// @Param        limit query int false "Maximum number of approval requests"
 // @Success      200 {object} commonHttpResponse "Success"
-// @Failure      404 {object} commonHttpResponse "Not found"
+// @Failure      400 {object} commonHttpResponse "Bad request"
 // @Failure      500 {object} commonHttpResponse "Internal server error"
 // @Router       /api/v1/approval-request [get]
 func getListApprovalRequestsHandler() http.HandlerFunc {
-	cacheInstance := cache.Get()
 	return func(w http.ResponseWriter, r *http.Request) {
 		log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
 
-		log(common.LogLevelDebug, "retrieving all cache keys...")
-		keys, err := cacheInstance.Scan(CreateApprovalRequestCacheKey("*"))
+		log(common.LogLevelDebug, "parsing filters...")
+		filter, err := parseApprovalRequestFilter(r.URL.Query())
 		if err != nil {
-			common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to retrieve approval requests", err)
+			common.SendHttpFailResponse(w, r, http.StatusBadRequest, "failed to parse filters", err)
 			return
 		}
 
-		if len(keys) == 0 {
-			common.SendHttpSuccessResponse(w, r, http.StatusNotFound, "no approval requests found")
+		log(common.LogLevelDebug, "retrieving approval requests...")
+		requests, err := listApprovalRequests(*filter)
+		if err != nil {
+			common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to retrieve approval requests", err)
 			return
 		}
-		for i := 0; i < len(keys); i++ {
-			keys[i] = StripCacheKeyPrefix(keys[i])
-		}
+		common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", requests)
+	}
+}
 
-		common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", keys)
+// parseApprovalRequestFilter returns the filter defined by the query
+// parameters of a request to list approval requests
+func parseApprovalRequestFilter(query url.Values) (*approvals.RequestFilter, error) {
+	filter := approvals.RequestFilter{
+		Status:    approvals.Status(query.Get("status")),
+		Requester: query.Get("requester"),
+		Platform:  approvals.Platform(query.Get("platform")),
+	}
+	if createdAfter := query.Get("createdAfter"); createdAfter != "" {
+		timestamp, err := time.Parse(time.RFC3339, createdAfter)
+		if err != nil {
+			return nil, fmt.Errorf("createdAfter: %w", err)
+		}
+		filter.CreatedAfter = &timestamp
+	}
+	if createdBefore := query.Get("createdBefore"); createdBefore != "" {
+		timestamp, err := time.Parse(time.RFC3339, createdBefore)
+		if err != nil {
+			return nil, fmt.Errorf("createdBefore: %w", err)
+		}
+		filter.CreatedBefore = &timestamp
+	}
+	if limit := query.Get("limit"); limit != "" {
+		limitValue, err := strconv.Atoi(limit)
+		if err != nil {
+			return nil, fmt.Errorf("limit: %w", err)
+		}
+		filter.Limit = limitValue
+	}
+	if err := filter.Validate(); err != nil {
+		return nil, err
 	}
+	return &filter, nil
 }
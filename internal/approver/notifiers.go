@@ -1,6 +1,7 @@
 package approver
 
 import (
+	"errors"
 	"fmt"
 	"time"
 )
@@ -26,6 +27,36 @@ func (n notifiers) SendApprovalRequest(req *ApprovalRequest) (requestUuid string
 	return requestUuid, notifications, err
 }
 
+// SendReminder replies to the messages of the approval request with a
+// reminder that it is awaiting a response
+func (n notifiers) SendReminder(req *ApprovalRequest) error {
+	errs := []error{}
+	for _, notifierInstance := range n {
+		if err := notifierInstance.SendReminder(req); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to send all reminders: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// UpdateExpired updates the messages of the approval request to indicate
+// that it has expired
+func (n notifiers) UpdateExpired(req *ApprovalRequest) error {
+	errs := []error{}
+	for _, notifierInstance := range n {
+		if err := notifierInstance.UpdateExpired(req); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to update all messages: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
 func (n notifiers) StartListening() {
 	for _, notifierInstance := range n {
 		notifierInstance.StartListening()
@@ -40,6 +71,8 @@ func (n notifiers) Stop() {
 
 type notifier interface {
 	SendApprovalRequest(req *ApprovalRequest) (requestUuid string, notifications notificationMessages, err error)
+	SendReminder(req *ApprovalRequest) error
+	UpdateExpired(req *ApprovalRequest) error
 	StartListening()
 	Stop()
 }
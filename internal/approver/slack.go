@@ -1,6 +1,7 @@
 package approver
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"opsicle/internal/approvals"
@@ -103,6 +104,56 @@ func (s *slackNotifier) SendApprovalRequest(req *ApprovalRequest) (string, notif
 	return requestUuid, notifications, nil
 }
 
+// SendReminder replies in the thread of every message of the approval
+// request with a reminder
+func (s *slackNotifier) SendReminder(req *ApprovalRequest) error {
+	errs := []error{}
+	for _, target := range req.Spec.Slack {
+		for _, notification := range target.Notifications {
+			if !notification.IsSuccess {
+				continue
+			}
+			if _, _, err := s.Client.PostMessage(
+				notification.TargetId,
+				slack.MsgOptionText(getSlackReminderMessage(req), false),
+				slack.MsgOptionTS(notification.MessageId),
+			); err != nil {
+				errs = append(errs, fmt.Errorf("failed to send reminder to channel[%s]: %w", notification.TargetId, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// UpdateExpired replaces every message of the approval request with one
+// that indicates the request has expired and replies in its thread
+func (s *slackNotifier) UpdateExpired(req *ApprovalRequest) error {
+	errs := []error{}
+	for _, target := range req.Spec.Slack {
+		for _, notification := range target.Notifications {
+			if !notification.IsSuccess {
+				continue
+			}
+			if _, _, _, err := s.Client.UpdateMessage(
+				notification.TargetId,
+				notification.MessageId,
+				slack.MsgOptionBlocks(getSlackExpiredBlocks(req).BlockSet...),
+			); err != nil {
+				errs = append(errs, fmt.Errorf("failed to update message[%s] in channel[%s]: %w", notification.MessageId, notification.TargetId, err))
+				continue
+			}
+			if _, _, err := s.Client.PostMessage(
+				notification.TargetId,
+				slack.MsgOptionText(getSlackExpiryMessage(req), false),
+				slack.MsgOptionTS(notification.MessageId),
+			); err != nil {
+				s.ServiceLogs <- common.ServiceLogf(common.LogLevelWarn, "failed to reply to message[%s] in channel[%s]: %s", notification.MessageId, notification.TargetId, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
 func (s *slackNotifier) StartListening() {
 	defaultHandler := getDefaultSlackHandler(
 		s.Client,
@@ -9,7 +9,6 @@ import (
 	"opsicle/internal/common"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/socketmode"
 )
@@ -461,6 +460,14 @@ type processSlackApprovalOpts struct {
 }
 
 func processSlackApproval(opts processSlackApprovalOpts) error {
+	unlock, err := lockApprovalRequest(opts.Req)
+	if err != nil {
+		if err := respondSlackSystemError(opts.App, opts.ChannelId, opts.ApprovalRequestMessageTs); err != nil {
+			opts.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "failed to respond: %s", err)
+		}
+		return fmt.Errorf("failed to lock approvalRequest[%s]: %w", opts.Req.Spec.GetUuid(), err)
+	}
+	defer unlock()
 	slackResponse := approvals.SlackResponseSpec{
 		ChannelId:  opts.ChannelId,
 		ReceivedAt: time.Now(),
@@ -468,25 +475,28 @@ func processSlackApproval(opts processSlackApprovalOpts) error {
 		UserId:     opts.SenderId,
 		UserName:   opts.SenderName,
 	}
-	if opts.Req.Spec.Approval == nil {
-		opts.Req.Spec.Approval = &approvals.ApprovalSpec{
-			ApproverId:      opts.SenderId,
-			ApproverName:    opts.SenderName,
-			Id:              uuid.New().String(),
-			RequestId:       opts.Req.Spec.Id,
-			RequestUuid:     opts.Req.Spec.GetUuid(),
-			RequesterId:     opts.Req.Spec.RequesterId,
-			RequesterName:   opts.Req.Spec.RequesterName,
-			Status:          opts.Status,
-			StatusUpdatedAt: time.Now(),
-			Slack:           []approvals.SlackResponseSpec{},
-			Type:            approvals.PlatformSlack,
+	outcome, err := opts.Req.Spec.AddSlackResponse(slackResponse)
+	if err != nil {
+		msg := getSlackResponseNotAcceptedMessage(opts.SenderId, err)
+		if _, _, err := opts.App.PostMessage(
+			opts.ChannelId,
+			slack.MsgOptionText(msg, false),
+			slack.MsgOptionTS(opts.ApprovalRequestMessageTs),
+		); err != nil {
+			opts.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "failed to respond: %s", err)
 		}
+		return fmt.Errorf("failed to add response to approvalRequest[%s]: %w", opts.Req.Spec.GetUuid(), err)
 	}
-	opts.Req.Spec.Approval.Slack = append(
-		opts.Req.Spec.Approval.Slack,
-		slackResponse,
-	)
+	if outcome.Status == approvals.StatusPending {
+		return processSlackPendingApproval(opts, *outcome, slackResponse)
+	}
+	opts.Req.Spec.Approval = newApprovalSpec(newApprovalSpecOpts{
+		Platform:      approvals.PlatformSlack,
+		Req:           opts.Req,
+		ResponderId:   opts.SenderId,
+		ResponderName: opts.SenderName,
+		Status:        outcome.Status,
+	})
 	approval := Approval{Spec: *opts.Req.Spec.Approval}
 	if err := approval.Create(); err != nil {
 		if err := respondSlackSystemError(opts.App, opts.ChannelId, opts.ApprovalRequestMessageTs); err != nil {
@@ -560,6 +570,67 @@ func processSlackApproval(opts processSlackApprovalOpts) error {
 		opts.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "failed to send error response to user: %s", err)
 		return fmt.Errorf("failed to update message[%v]: %s", opts.ApprovalRequestMessageTs, err)
 	}
+	// the request is decided so the callback, which may be retried for a
+	// while, does not need to hold up other responses
+	unlock()
+	if err := handleCallback(handleCallbackOpts{
+		Req:         opts.Req,
+		ServiceLogs: opts.ServiceLogs,
+	}); err != nil {
+		opts.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "failed to process webhook for request[%s:%s]: %s", opts.Req.Spec.Id, opts.Req.Spec.GetUuid(), err)
+	}
+	return nil
+}
+
+// processSlackPendingApproval records an approval which did not decide the
+// request and notifies the next stage of the request if the approval
+// completed the current stage
+func processSlackPendingApproval(opts processSlackApprovalOpts, outcome approvals.ResponseOutcome, slackResponse approvals.SlackResponseSpec) error {
+	if err := opts.Req.Update(); err != nil {
+		if err := respondSlackSystemError(opts.App, opts.ChannelId, opts.ApprovalRequestMessageTs); err != nil {
+			opts.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "failed to respond: %s", err)
+		}
+		return fmt.Errorf("failed to update approvalRequest[%s]: %s", opts.Req.Spec.GetUuid(), err)
+	}
+	threadMessage := getSlackPendingApprovalMessage(opts.SenderId, slackResponse.ReceivedAt, outcome)
+	channelId, messageTs, err := opts.App.PostMessage(
+		opts.ChannelId,
+		slack.MsgOptionText(threadMessage, false),
+		slack.MsgOptionTS(opts.ApprovalRequestMessageTs),
+	)
+	if err != nil {
+		opts.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "failed to respond: %s", err)
+	}
+	if outcome.IsStageCompleted {
+		if _, _, _, err := opts.App.UpdateMessage(
+			opts.ChannelId,
+			opts.ApprovalRequestMessageTs,
+			slack.MsgOptionBlocks(getSlackStageApprovedBlocks(opts.Req, outcome).BlockSet...),
+		); err != nil {
+			opts.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "failed to update message[%v]: %s", opts.ApprovalRequestMessageTs, err)
+		}
+	}
+	opts.Req.Spec.Actions = append(
+		opts.Req.Spec.Actions,
+		approvals.Action{
+			HappenedAt:  time.Now(),
+			MessageId:   messageTs,
+			Platform:    string(approvals.PlatformSlack),
+			RequestUuid: opts.Req.Spec.GetUuid(),
+			TargetId:    channelId,
+			Status:      opts.Status,
+			UserId:      opts.SenderId,
+			UserName:    opts.SenderName,
+		},
+	)
+	if err := opts.Req.Update(); err != nil {
+		opts.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "failed to update approvalRequest[%s]: %s", opts.Req.Spec.GetUuid(), err)
+	}
+	if outcome.IsStageCompleted {
+		if err := sendApprovalRequestStage(opts.Req, opts.ServiceLogs); err != nil {
+			return fmt.Errorf("failed to send next stage of approvalRequest[%s]: %w", opts.Req.Spec.GetUuid(), err)
+		}
+	}
 	return nil
 }
 
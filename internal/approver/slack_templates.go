@@ -1,7 +1,9 @@
 package approver
 
 import (
+	"errors"
 	"fmt"
+	"opsicle/internal/approvals"
 	"time"
 
 	"github.com/slack-go/slack"
@@ -108,3 +110,89 @@ func getSlackUnauthorizedMessage(userId, action string) string {
 		action,
 	)
 }
+
+func getSlackPendingApprovalMessage(userId string, respondedAt time.Time, outcome approvals.ResponseOutcome) string {
+	msg := fmt.Sprintf(
+		"☑️ Approved by <@%s> (`%s`) at %s UTC, %v of %v approvals received",
+		userId,
+		userId,
+		respondedAt.UTC().Format("2006-01-02 15:04:05"),
+		outcome.Approvals,
+		outcome.Stage.GetMinApprovals(),
+	)
+	if outcome.StageCount > 1 {
+		msg += fmt.Sprintf(" for stage `%s` (%v of %v)", outcome.Stage.Name, outcome.StageIndex+1, outcome.StageCount)
+	}
+	if outcome.IsStageCompleted {
+		msg += "\n⏭️ Stage approved, the request has been sent to the next stage"
+	}
+	return msg
+}
+
+func getSlackResponseNotAcceptedMessage(userId string, err error) string {
+	reason := "the request could not accept the response"
+	switch {
+	case errors.Is(err, approvals.ErrorRequestDecided):
+		reason = "the request has already been decided"
+	case errors.Is(err, approvals.ErrorRequestExpired):
+		reason = "the request has expired"
+	case errors.Is(err, approvals.ErrorResponseDuplicated):
+		reason = "they have already approved the request"
+	case errors.Is(err, approvals.ErrorSelfApproval):
+		reason = "requesters cannot approve their own request"
+	}
+	return fmt.Sprintf(
+		"⚠️ The response from <@%s> (`%s`) was not accepted because %s",
+		userId,
+		userId,
+		reason,
+	)
+}
+
+func getSlackStageApprovedBlocks(req *ApprovalRequest, outcome approvals.ResponseOutcome) slack.Blocks {
+	return slack.Blocks{
+		BlockSet: []slack.Block{
+			slack.NewHeaderBlock(
+				slack.NewTextBlockObject("plain_text", "⏭️ Approval Request Stage Approved", false, false),
+			),
+			slack.NewContextBlock("", slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("Request Message\n```\n%s\n```", req.Spec.Message), false, false)),
+			slack.NewContextBlock("", slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("Requester: `%s`", req.Spec.RequesterName), false, false)),
+			slack.NewContextBlock("", slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("Requester ID: `%s`", req.Spec.RequesterId), false, false)),
+			slack.NewContextBlock("", slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("Request ID: `%s`", req.Spec.Id), false, false)),
+			slack.NewContextBlock("", slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("Request UUID: `%s`", req.Spec.GetUuid()), false, false)),
+			slack.NewContextBlock("", slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("Stage: `%s` (%v of %v)", outcome.Stage.Name, outcome.StageIndex+1, outcome.StageCount), false, false)),
+			slack.NewContextBlock("", slack.NewTextBlockObject("mrkdwn", "Status: `PENDING NEXT STAGE`", false, false)),
+		},
+	}
+}
+
+func getSlackExpiredBlocks(req *ApprovalRequest) slack.Blocks {
+	return slack.Blocks{
+		BlockSet: []slack.Block{
+			slack.NewHeaderBlock(
+				slack.NewTextBlockObject("plain_text", "⌛️ Expired Approval Request", false, false),
+			),
+			slack.NewContextBlock("", slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("Request Message\n```\n%s\n```", req.Spec.Message), false, false)),
+			slack.NewContextBlock("", slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("Requester: `%s`", req.Spec.RequesterName), false, false)),
+			slack.NewContextBlock("", slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("Requester ID: `%s`", req.Spec.RequesterId), false, false)),
+			slack.NewContextBlock("", slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("Request ID: `%s`", req.Spec.Id), false, false)),
+			slack.NewContextBlock("", slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("Request UUID: `%s`", req.Spec.GetUuid()), false, false)),
+			slack.NewContextBlock("", slack.NewTextBlockObject("mrkdwn", "Status: ⌛️", false, false)),
+			slack.NewContextBlock("", slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("Expired at: `%s`", req.Spec.GetExpiresAt().Format(time.RFC1123)), false, false)),
+		},
+	}
+}
+
+func getSlackExpiryMessage(req *ApprovalRequest) string {
+	return fmt.Sprintf(
+		"⌛️ This request expired at %s UTC without being decided",
+		req.Spec.GetExpiresAt().UTC().Format("2006-01-02 15:04:05"),
+	)
+}
+
+func getSlackReminderMessage(req *ApprovalRequest) string {
+	return fmt.Sprintf(
+		"🔔 Reminder: this request is still awaiting a response and expires at %s UTC",
+		req.Spec.GetExpiresAt().UTC().Format("2006-01-02 15:04:05"),
+	)
+}
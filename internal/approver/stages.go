@@ -0,0 +1,59 @@
+package approver
+
+import (
+	"fmt"
+	"opsicle/internal/approvals"
+	"opsicle/internal/common"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// sendApprovalRequestStage sends the approval request to the targets of
+// its current stage, this is used when a stage has been approved and the
+// request has moved on to its next stage
+func sendApprovalRequestStage(req *ApprovalRequest, serviceLogs chan<- common.ServiceLog) error {
+	serviceLogs <- common.ServiceLogf(common.LogLevelDebug, "sending stage[%v] of approvalRequest[%s:%s]...", req.Spec.CurrentStage, req.Spec.Id, req.Spec.GetUuid())
+	requestUuid, notifications, err := Notifiers.SendApprovalRequest(req)
+	if err != nil {
+		return fmt.Errorf("failed to send stage[%v] of approvalRequest[%s:%s]: %w", req.Spec.CurrentStage, req.Spec.Id, requestUuid, err)
+	}
+	serviceLogs <- common.ServiceLogf(common.LogLevelInfo, "sent %v notifications for stage[%v] of approvalRequest[%s:%s]", len(notifications), req.Spec.CurrentStage, req.Spec.Id, requestUuid)
+	if err := req.Update(); err != nil {
+		return fmt.Errorf("failed to update approvalRequest[%s:%s]: %w", req.Spec.Id, requestUuid, err)
+	}
+	return nil
+}
+
+type newApprovalSpecOpts struct {
+	Platform      approvals.Platform
+	Req           *ApprovalRequest
+	ResponderId   string
+	ResponderName string
+	Status        approvals.Status
+}
+
+// newApprovalSpec returns the approval for a request which has been
+// decided by the response of the responder, responses from all platforms
+// are included in the approval
+func newApprovalSpec(opts newApprovalSpecOpts) *approvals.ApprovalSpec {
+	approval := &approvals.ApprovalSpec{
+		ApproverId:      opts.ResponderId,
+		ApproverName:    opts.ResponderName,
+		Id:              uuid.New().String(),
+		RequestId:       opts.Req.Spec.Id,
+		RequestUuid:     opts.Req.Spec.GetUuid(),
+		RequesterId:     opts.Req.Spec.RequesterId,
+		RequesterName:   opts.Req.Spec.RequesterName,
+		Status:          opts.Status,
+		StatusUpdatedAt: time.Now(),
+		Slack:           []approvals.SlackResponseSpec{},
+		Telegram:        []approvals.TelegramResponseSpec{},
+		Type:            opts.Platform,
+	}
+	if opts.Req.Spec.Responses != nil {
+		approval.Slack = append(approval.Slack, opts.Req.Spec.Responses.Slack...)
+		approval.Telegram = append(approval.Telegram, opts.Req.Spec.Responses.Telegram...)
+	}
+	return approval
+}
@@ -0,0 +1,292 @@
+package approver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"opsicle/internal/approvals"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	storeDatabase                    = "approver"
+	storeApprovalRequestsCollection  = "approval_requests"
+	storeApprovalsCollection         = "approvals"
+	storeTimeout                     = 3 * time.Second
+	defaultListApprovalRequestsLimit = 100
+)
+
+var ErrorStoreNotFound = errors.New("store_not_found")
+
+// Store durably persists approval requests and approvals so that they
+// remain available after they are evicted from the cache, the cache
+// remains the hot path for approval requests which are in progress
+type Store interface {
+	GetApproval(approvalId string) (*approvals.ApprovalSpec, error)
+	GetApprovalRequest(requestUuid string) (*approvals.RequestSpec, error)
+	ListApprovalRequests(filter approvals.RequestFilter) ([]approvals.RequestSpec, error)
+	ListPendingApprovalRequestUuids() ([]string, error)
+	SaveApproval(approval approvals.ApprovalSpec) error
+	SaveApprovalRequest(req approvals.RequestSpec) error
+}
+
+var store Store
+
+// InitMongoStore initialises the durable store of approval requests and
+// approvals using the MongoDB client `c`
+func InitMongoStore(c *mongo.Client) error {
+	if c == nil {
+		return fmt.Errorf("client is null")
+	}
+	pingCtx, pingCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer pingCancel()
+	if err := c.Ping(pingCtx, nil); err != nil {
+		return fmt.Errorf("server is unpingable")
+	}
+	mongoStoreInstance := &mongoStore{Db: c.Database(storeDatabase)}
+	if err := mongoStoreInstance.createIndexes(); err != nil {
+		return fmt.Errorf("failed to create indexes: %w", err)
+	}
+	store = mongoStoreInstance
+	return nil
+}
+
+// approvalRequestDocument is how approval requests are stored in
+// MongoDB, the fields which requests are filtered by are stored
+// alongside the request
+type approvalRequestDocument struct {
+	RequestUuid   string               `bson:"_id"`
+	RequestId     string               `bson:"requestId"`
+	Status        approvals.Status     `bson:"status"`
+	RequesterId   string               `bson:"requesterId"`
+	RequesterName string               `bson:"requesterName"`
+	Platforms     []approvals.Platform `bson:"platforms"`
+	CreatedAt     time.Time            `bson:"createdAt"`
+	UpdatedAt     time.Time            `bson:"updatedAt"`
+
+	// Spec is the request as JSON with its MFA seeds redacted
+	Spec string `bson:"spec"`
+}
+
+// approvalDocument is how approvals are stored in MongoDB
+type approvalDocument struct {
+	Id          string           `bson:"_id"`
+	RequestUuid string           `bson:"requestUuid"`
+	Status      approvals.Status `bson:"status"`
+	UpdatedAt   time.Time        `bson:"updatedAt"`
+
+	// Spec is the approval as JSON
+	Spec string `bson:"spec"`
+}
+
+// getApprovalRequestsMongoFilter returns the MongoDB query which selects
+// the approval requests that `filter` matches
+func getApprovalRequestsMongoFilter(filter approvals.RequestFilter) bson.M {
+	query := bson.M{}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+	if filter.Requester != "" {
+		query["$or"] = bson.A{
+			bson.M{"requesterId": filter.Requester},
+			bson.M{"requesterName": filter.Requester},
+		}
+	}
+	if filter.CreatedAfter != nil || filter.CreatedBefore != nil {
+		createdAt := bson.M{}
+		if filter.CreatedAfter != nil {
+			createdAt["$gte"] = *filter.CreatedAfter
+		}
+		if filter.CreatedBefore != nil {
+			createdAt["$lt"] = *filter.CreatedBefore
+		}
+		query["createdAt"] = createdAt
+	}
+	if filter.Platform != "" {
+		query["platforms"] = filter.Platform
+	}
+	return query
+}
+
+type mongoStore struct {
+	Db *mongo.Database
+}
+
+func (s *mongoStore) createIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	if _, err := s.Db.Collection(storeApprovalRequestsCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "createdAt", Value: -1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "createdAt", Value: -1}}},
+		{Keys: bson.D{{Key: "requesterId", Value: 1}, {Key: "createdAt", Value: -1}}},
+		{Keys: bson.D{{Key: "requesterName", Value: 1}, {Key: "createdAt", Value: -1}}},
+	}); err != nil {
+		return fmt.Errorf("failed to create indexes on collection[%s]: %w", storeApprovalRequestsCollection, err)
+	}
+	if _, err := s.Db.Collection(storeApprovalsCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "requestUuid", Value: 1}},
+	}); err != nil {
+		return fmt.Errorf("failed to create indexes on collection[%s]: %w", storeApprovalsCollection, err)
+	}
+	return nil
+}
+
+func (s *mongoStore) GetApproval(approvalId string) (*approvals.ApprovalSpec, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	var document approvalDocument
+	if err := s.Db.Collection(storeApprovalsCollection).FindOne(ctx, bson.M{"_id": approvalId}).Decode(&document); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, fmt.Errorf("failed to find approval[%s]: %w", approvalId, ErrorStoreNotFound)
+		}
+		return nil, fmt.Errorf("failed to get approval[%s]: %w", approvalId, err)
+	}
+	var approval approvals.ApprovalSpec
+	if err := json.Unmarshal([]byte(document.Spec), &approval); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal approval[%s]: %w", approvalId, err)
+	}
+	return &approval, nil
+}
+
+func (s *mongoStore) GetApprovalRequest(requestUuid string) (*approvals.RequestSpec, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	var document approvalRequestDocument
+	if err := s.Db.Collection(storeApprovalRequestsCollection).FindOne(ctx, bson.M{"_id": requestUuid}).Decode(&document); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, fmt.Errorf("failed to find approvalRequest[%s]: %w", requestUuid, ErrorStoreNotFound)
+		}
+		return nil, fmt.Errorf("failed to get approvalRequest[%s]: %w", requestUuid, err)
+	}
+	var req approvals.RequestSpec
+	if err := json.Unmarshal([]byte(document.Spec), &req); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal approvalRequest[%s]: %w", requestUuid, err)
+	}
+	return &req, nil
+}
+
+func (s *mongoStore) ListApprovalRequests(filter approvals.RequestFilter) ([]approvals.RequestSpec, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultListApprovalRequestsLimit
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	cursor, err := s.Db.Collection(storeApprovalRequestsCollection).Find(
+		ctx,
+		getApprovalRequestsMongoFilter(filter),
+		options.Find().
+			SetLimit(int64(limit)).
+			SetSort(bson.D{{Key: "createdAt", Value: -1}}),
+	)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, fmt.Errorf("timeout[%v] on find", storeTimeout)
+		}
+		return nil, fmt.Errorf("find failed: %w", err)
+	}
+	defer cursor.Close(ctx)
+	var documents []approvalRequestDocument
+	if err := cursor.All(ctx, &documents); err != nil {
+		return nil, fmt.Errorf("decode failed: %w", err)
+	}
+	requests := []approvals.RequestSpec{}
+	for _, document := range documents {
+		var req approvals.RequestSpec
+		if err := json.Unmarshal([]byte(document.Spec), &req); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal approvalRequest[%s]: %w", document.RequestUuid, err)
+		}
+		requests = append(requests, req)
+	}
+	return requests, nil
+}
+
+func (s *mongoStore) ListPendingApprovalRequestUuids() ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	cursor, err := s.Db.Collection(storeApprovalRequestsCollection).Find(
+		ctx,
+		bson.M{"status": approvals.StatusPending},
+		options.Find().SetProjection(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, fmt.Errorf("timeout[%v] on find", storeTimeout)
+		}
+		return nil, fmt.Errorf("find failed: %w", err)
+	}
+	defer cursor.Close(ctx)
+	var documents []struct {
+		RequestUuid string `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &documents); err != nil {
+		return nil, fmt.Errorf("decode failed: %w", err)
+	}
+	requestUuids := []string{}
+	for _, document := range documents {
+		requestUuids = append(requestUuids, document.RequestUuid)
+	}
+	return requestUuids, nil
+}
+
+func (s *mongoStore) SaveApproval(approval approvals.ApprovalSpec) error {
+	approvalData, err := json.Marshal(approval)
+	if err != nil {
+		return fmt.Errorf("failed to marshal approval[%s]: %w", approval.Id, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	if _, err := s.Db.Collection(storeApprovalsCollection).ReplaceOne(
+		ctx,
+		bson.M{"_id": approval.Id},
+		approvalDocument{
+			Id:          approval.Id,
+			RequestUuid: approval.RequestUuid,
+			Status:      approval.Status,
+			UpdatedAt:   time.Now(),
+			Spec:        string(approvalData),
+		},
+		options.Replace().SetUpsert(true),
+	); err != nil {
+		return fmt.Errorf("failed to save approval[%s]: %w", approval.Id, err)
+	}
+	return nil
+}
+
+func (s *mongoStore) SaveApprovalRequest(req approvals.RequestSpec) error {
+	requestUuid := req.GetUuid()
+	redactedRequest, err := getRedactedRequestSpec(req)
+	if err != nil {
+		return fmt.Errorf("failed to redact approvalRequest[%s]: %w", requestUuid, err)
+	}
+	requestData, err := json.Marshal(redactedRequest)
+	if err != nil {
+		return fmt.Errorf("failed to marshal approvalRequest[%s]: %w", requestUuid, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	if _, err := s.Db.Collection(storeApprovalRequestsCollection).ReplaceOne(
+		ctx,
+		bson.M{"_id": requestUuid},
+		approvalRequestDocument{
+			RequestUuid:   requestUuid,
+			RequestId:     req.Id,
+			Status:        req.GetStatus(),
+			RequesterId:   req.RequesterId,
+			RequesterName: req.RequesterName,
+			Platforms:     req.GetPlatforms(),
+			CreatedAt:     req.CreatedAt,
+			UpdatedAt:     time.Now(),
+			Spec:          string(requestData),
+		},
+		options.Replace().SetUpsert(true),
+	); err != nil {
+		return fmt.Errorf("failed to save approvalRequest[%s]: %w", requestUuid, err)
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package approver
+
+import (
+	"context"
+	"fmt"
+	"opsicle/internal/approvals"
+	"opsicle/internal/cache"
+	"opsicle/internal/common"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultSweeperInterval = 30 * time.Second
+	sweeperLockKey         = "lock:approval-request-sweeper"
+)
+
+type StartSweeperOpts struct {
+	// Interval is the duration between sweeps, defaults to
+	// DefaultSweeperInterval
+	Interval time.Duration
+
+	ServiceLogs chan<- common.ServiceLog
+}
+
+// StartSweeper periodically expires, reminds and escalates pending
+// approval requests until `ctx` is done; all approver replicas run this
+// but only the one holding the sweeper lock in the cache sweeps
+func StartSweeper(ctx context.Context, opts StartSweeperOpts) {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = DefaultSweeperInterval
+	}
+	lockTtl := 3 * interval
+	cacheInstance := cache.Get()
+	sweeperId := uuid.NewString()
+	isLeader := false
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		isLocked, err := cache.AcquireLock(cacheInstance, sweeperLockKey, sweeperId, lockTtl)
+		if err != nil {
+			opts.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "approval request sweeper[%s] failed to acquire lock: %s", sweeperId, err)
+		}
+		if isLocked != isLeader {
+			isLeader = isLocked
+			if isLeader {
+				opts.ServiceLogs <- common.ServiceLogf(common.LogLevelInfo, "approval request sweeper[%s] is now sweeping approval requests", sweeperId)
+			} else {
+				opts.ServiceLogs <- common.ServiceLogf(common.LogLevelInfo, "approval request sweeper[%s] is no longer sweeping approval requests", sweeperId)
+			}
+		}
+		if isLeader {
+			sweepApprovalRequests(time.Now(), opts.ServiceLogs)
+		}
+		select {
+		case <-ctx.Done():
+			if isLeader {
+				if err := cache.ReleaseLock(cacheInstance, sweeperLockKey, sweeperId); err != nil {
+					opts.ServiceLogs <- common.ServiceLogf(common.LogLevelWarn, "approval request sweeper[%s] failed to release lock: %s", sweeperId, err)
+				}
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sweepApprovalRequests expires pending approval requests which are past
+// their expiry at `now` and sends the reminders and escalations of the
+// others which are due
+func sweepApprovalRequests(now time.Time, serviceLogs chan<- common.ServiceLog) {
+	requestUuids, err := listPendingApprovalRequestUuids()
+	if err != nil {
+		serviceLogs <- common.ServiceLogf(common.LogLevelError, "failed to list approval requests: %s", err)
+		return
+	}
+	for _, requestUuid := range requestUuids {
+		if err := sweepApprovalRequest(requestUuid, now, serviceLogs); err != nil {
+			serviceLogs <- common.ServiceLogf(common.LogLevelError, "failed to sweep approvalRequest[%s]: %s", requestUuid, err)
+		}
+	}
+}
+
+// listPendingApprovalRequestUuids returns the uuids of the approval
+// requests which may still be pending, these are listed from the store
+// when one is configured so that requests which are no longer cached are
+// still swept and from the cache otherwise
+func listPendingApprovalRequestUuids() ([]string, error) {
+	if store != nil {
+		return store.ListPendingApprovalRequestUuids()
+	}
+	keys, err := cache.Get().Scan(CreateApprovalRequestCacheKey("*"))
+	if err != nil {
+		return nil, err
+	}
+	requestUuids := []string{}
+	for _, key := range keys {
+		requestUuids = append(requestUuids, StripCacheKeyPrefix(key))
+	}
+	return requestUuids, nil
+}
+
+// sweepApprovalRequest expires the approval request identified by
+// `requestUuid` or sends its reminder or escalation if one is due, the
+// request is locked so that responses received meanwhile are not lost
+func sweepApprovalRequest(requestUuid string, now time.Time, serviceLogs chan<- common.ServiceLog) error {
+	req := &ApprovalRequest{Spec: approvals.RequestSpec{Uuid: &requestUuid}}
+	unlock, err := lockApprovalRequest(req)
+	if err != nil {
+		return fmt.Errorf("failed to lock approvalRequest[%s]: %w", requestUuid, err)
+	}
+	defer unlock()
+	if req.Spec.Approval != nil {
+		return nil
+	}
+	switch {
+	case req.Spec.IsExpired(now):
+		if err := expireApprovalRequest(req, now, serviceLogs); err != nil {
+			return err
+		}
+		// requests which are no longer cached only have redacted
+		// callback credentials which the callback would be refused with
+		if req.hasRedactedCallback() {
+			return fmt.Errorf("failed to process callback: approvalRequest[%s] is no longer cached and the credentials of its callback are only kept in the cache", req.Spec.GetUuid())
+		}
+		// the callback may be retried for a while so the request is
+		// unlocked first
+		unlock()
+		if err := handleCallback(handleCallbackOpts{
+			Req:         req,
+			ServiceLogs: serviceLogs,
+		}); err != nil {
+			return fmt.Errorf("failed to process callback: %w", err)
+		}
+	case req.Spec.IsEscalationDue(now):
+		return escalateApprovalRequest(req, now, serviceLogs)
+	case req.Spec.IsReminderDue(now):
+		return remindApprovalRequest(req, now, serviceLogs)
+	}
+	return nil
+}
+
+// expireApprovalRequest decides the approval request as expired and
+// updates its messages to say so
+func expireApprovalRequest(req *ApprovalRequest, now time.Time, serviceLogs chan<- common.ServiceLog) error {
+	serviceLogs <- common.ServiceLogf(common.LogLevelInfo, "approvalRequest[%s:%s] expired at %s", req.Spec.Id, req.Spec.GetUuid(), req.Spec.GetExpiresAt().Format(time.RFC3339))
+	req.Spec.Approval = newApprovalSpec(newApprovalSpecOpts{
+		Req:    req,
+		Status: approvals.StatusExpired,
+	})
+	req.Spec.Approval.StatusUpdatedAt = now
+	approval := Approval{Spec: *req.Spec.Approval}
+	if err := approval.Create(); err != nil {
+		return fmt.Errorf("failed to create approval[%s]: %w", approval.Spec.Id, err)
+	}
+	req.Spec.Actions = append(
+		req.Spec.Actions,
+		approvals.Action{
+			HappenedAt:  now,
+			RequestUuid: req.Spec.GetUuid(),
+			Status:      approvals.StatusExpired,
+		},
+	)
+	if err := req.Update(); err != nil {
+		return fmt.Errorf("failed to update approvalRequest[%s]: %w", req.Spec.GetUuid(), err)
+	}
+	if err := Notifiers.UpdateExpired(req); err != nil {
+		serviceLogs <- common.ServiceLogf(common.LogLevelWarn, "failed to update messages of approvalRequest[%s]: %s", req.Spec.GetUuid(), err)
+	}
+	return nil
+}
+
+// escalateApprovalRequest sends the approval request to its escalation
+// targets and authorises them to respond to it
+func escalateApprovalRequest(req *ApprovalRequest, now time.Time, serviceLogs chan<- common.ServiceLog) error {
+	serviceLogs <- common.ServiceLogf(common.LogLevelInfo, "escalating approvalRequest[%s:%s] after no responses to stage[%v]", req.Spec.Id, req.Spec.GetUuid(), req.Spec.CurrentStage)
+	escalationSpec, err := req.Spec.Escalate(now)
+	if err != nil {
+		return err
+	}
+	// the escalation is recorded before it is sent so that a failure
+	// does not result in the escalation targets being spammed
+	if err := req.Update(); err != nil {
+		return fmt.Errorf("failed to update approvalRequest[%s]: %w", req.Spec.GetUuid(), err)
+	}
+	escalation := &ApprovalRequest{Spec: *escalationSpec}
+	_, notifications, err := Notifiers.SendApprovalRequest(escalation)
+	if err != nil {
+		return fmt.Errorf("failed to send escalation: %w", err)
+	}
+	serviceLogs <- common.ServiceLogf(common.LogLevelInfo, "sent %v escalation notifications for approvalRequest[%s:%s]", len(notifications), req.Spec.Id, req.Spec.GetUuid())
+	req.Spec.AddEscalatedTargets(&escalation.Spec)
+	if err := req.Update(); err != nil {
+		return fmt.Errorf("failed to update approvalRequest[%s]: %w", req.Spec.GetUuid(), err)
+	}
+	return nil
+}
+
+// remindApprovalRequest sends a reminder to the targets of the approval
+// request
+func remindApprovalRequest(req *ApprovalRequest, now time.Time, serviceLogs chan<- common.ServiceLog) error {
+	serviceLogs <- common.ServiceLogf(common.LogLevelDebug, "sending reminder[%v] for approvalRequest[%s:%s]", req.Spec.RemindersSent+1, req.Spec.Id, req.Spec.GetUuid())
+	req.Spec.SetReminded(now)
+	if err := req.Update(); err != nil {
+		return fmt.Errorf("failed to update approvalRequest[%s]: %w", req.Spec.GetUuid(), err)
+	}
+	if err := Notifiers.SendReminder(req); err != nil {
+		return fmt.Errorf("failed to send reminder: %w", err)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package approver
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"opsicle/internal/approvals"
 	"opsicle/internal/common"
@@ -77,6 +78,54 @@ func (t *telegramNotifier) SendApprovalRequest(req *ApprovalRequest) (string, no
 	return requestUuid, notifications, nil
 }
 
+// SendReminder replies to every message of the approval request with a
+// reminder
+func (t *telegramNotifier) SendReminder(req *ApprovalRequest) error {
+	errs := []error{}
+	for _, target := range req.Spec.Telegram {
+		for _, notification := range target.Notifications {
+			if !notification.IsSuccess {
+				continue
+			}
+			chatId, messageId, err := parseTelegramNotification(notification)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if err := t.Client.ReplyMessage(chatId, messageId, getTelegramReminderMessage(*req)); err != nil {
+				errs = append(errs, fmt.Errorf("failed to send reminder to chat[%v]: %w", chatId, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// UpdateExpired replaces every message of the approval request with one
+// that indicates the request has expired and replies to it
+func (t *telegramNotifier) UpdateExpired(req *ApprovalRequest) error {
+	errs := []error{}
+	for _, target := range req.Spec.Telegram {
+		for _, notification := range target.Notifications {
+			if !notification.IsSuccess {
+				continue
+			}
+			chatId, messageId, err := parseTelegramNotification(notification)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if err := t.Client.UpdateMessage(chatId, messageId, getTelegramExpiredMessage(*req), nil); err != nil {
+				errs = append(errs, fmt.Errorf("failed to update message[%v] in chat[%v]: %w", messageId, chatId, err))
+				continue
+			}
+			if err := t.Client.ReplyMessage(chatId, messageId, getTelegramExpiryMessage(*req)); err != nil {
+				t.ServiceLogs <- common.ServiceLogf(common.LogLevelWarn, "failed to reply to message[%v] in chat[%v]: %s", messageId, chatId, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
 func (t *telegramNotifier) StartListening() {
 	t.Done = make(chan common.Done)
 	go func() {
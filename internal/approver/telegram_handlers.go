@@ -15,7 +15,6 @@ import (
 
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
-	"github.com/google/uuid"
 )
 
 func getDefaultHandler(
@@ -577,6 +576,15 @@ func handleTelegramMfaResponse(opts handleTelegramMfaResponseOpts) {
 }
 
 func handleTelegramRejection(opts handleTelegramResponseOpts) {
+	unlock, err := lockApprovalRequest(opts.Req)
+	if err != nil {
+		opts.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "failed to lock approvalRequest[%s]: %s", opts.Req.Spec.GetUuid(), err)
+		if err := opts.Bot.ReplyMessage(opts.ChatId, opts.MessageId, "🙇🏼 Apologies, something went wrong internally"); err != nil {
+			opts.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "failed to send error response to user: %s", err)
+		}
+		return
+	}
+	defer unlock()
 	telegramResponse := approvals.TelegramResponseSpec{
 		ChatId:     opts.ChatId,
 		ReceivedAt: time.Now(),
@@ -588,25 +596,20 @@ func handleTelegramRejection(opts handleTelegramResponseOpts) {
 	approverId := strconv.FormatInt(opts.SenderId, 10)
 	approverName := opts.SenderUsername
 
-	if opts.Req.Spec.Approval == nil {
-		opts.Req.Spec.Approval = &approvals.ApprovalSpec{
-			ApproverId:      approverId,
-			ApproverName:    approverName,
-			Id:              uuid.New().String(),
-			RequestId:       opts.Req.Spec.Id,
-			RequestUuid:     opts.Req.Spec.GetUuid(),
-			RequesterId:     opts.Req.Spec.RequesterId,
-			RequesterName:   opts.Req.Spec.RequesterName,
-			Status:          approvals.StatusRejected,
-			StatusUpdatedAt: time.Now(),
-			Telegram:        []approvals.TelegramResponseSpec{},
-			Type:            approvals.PlatformTelegram,
-		}
-	}
-	opts.Req.Spec.Approval.Telegram = append(
-		opts.Req.Spec.Approval.Telegram,
-		telegramResponse,
-	)
+	if _, err := opts.Req.Spec.AddTelegramResponse(telegramResponse); err != nil {
+		opts.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "failed to add response to approvalRequest[%s]: %s", opts.Req.Spec.GetUuid(), err)
+		if err := opts.Bot.ReplyMessage(opts.ChatId, opts.MessageId, getTelegramResponseNotAcceptedMessage(err)); err != nil {
+			opts.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "failed to send error response to user: %s", err)
+		}
+		return
+	}
+	opts.Req.Spec.Approval = newApprovalSpec(newApprovalSpecOpts{
+		Platform:      approvals.PlatformTelegram,
+		Req:           opts.Req,
+		ResponderId:   approverId,
+		ResponderName: approverName,
+		Status:        approvals.StatusRejected,
+	})
 	approval := Approval{Spec: *opts.Req.Spec.Approval}
 	if err := approval.Create(); err != nil {
 		opts.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "failed to create approval[%s]: %s", approval.Spec.Id, err)
@@ -649,6 +652,7 @@ func handleTelegramRejection(opts handleTelegramResponseOpts) {
 	if err := opts.Bot.ReplyMessage(opts.ChatId, opts.MessageId, getTelegramRejectMessage(*opts.Req, opts.SenderId, approverName)); err != nil {
 		opts.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "failed to send approval message to chat[%v]: %s", opts.ChatId, err)
 	}
+	unlock()
 	if err := handleCallback(handleCallbackOpts{
 		Req:         opts.Req,
 		ServiceLogs: opts.ServiceLogs,
@@ -669,6 +673,14 @@ type processTelegramApprovalOpts struct {
 
 // processTelegramApproval processes an approval via Telegram
 func processTelegramApproval(opts processTelegramApprovalOpts) error {
+	unlock, err := lockApprovalRequest(opts.Req)
+	if err != nil {
+		if err := opts.Bot.ReplyMessage(opts.ChatId, opts.ApprovalMessageId, "🙇🏼 Apologies, something went wrong internally"); err != nil {
+			opts.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "failed to send error response to user: %s", err)
+		}
+		return fmt.Errorf("failed to lock approvalRequest[%s]: %w", opts.Req.Spec.GetUuid(), err)
+	}
+	defer unlock()
 	telegramResponse := approvals.TelegramResponseSpec{
 		ChatId:     opts.ChatId,
 		UserId:     opts.SenderId,
@@ -680,25 +692,23 @@ func processTelegramApproval(opts processTelegramApprovalOpts) error {
 	approverId := strconv.FormatInt(opts.SenderId, 10)
 	approverName := opts.SenderUsername
 
-	if opts.Req.Spec.Approval == nil {
-		opts.Req.Spec.Approval = &approvals.ApprovalSpec{
-			ApproverId:      approverId,
-			ApproverName:    approverName,
-			Id:              uuid.New().String(),
-			RequestId:       opts.Req.Spec.Id,
-			RequestUuid:     opts.Req.Spec.GetUuid(),
-			RequesterId:     opts.Req.Spec.RequesterId,
-			RequesterName:   opts.Req.Spec.RequesterName,
-			Status:          approvals.StatusApproved,
-			StatusUpdatedAt: time.Now(),
-			Telegram:        []approvals.TelegramResponseSpec{},
-			Type:            approvals.PlatformTelegram,
-		}
-	}
-	opts.Req.Spec.Approval.Telegram = append(
-		opts.Req.Spec.Approval.Telegram,
-		telegramResponse,
-	)
+	outcome, err := opts.Req.Spec.AddTelegramResponse(telegramResponse)
+	if err != nil {
+		if err := opts.Bot.ReplyMessage(opts.ChatId, opts.ApprovalMessageId, getTelegramResponseNotAcceptedMessage(err)); err != nil {
+			opts.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "failed to send error response to user: %s", err)
+		}
+		return fmt.Errorf("failed to add response to approvalRequest[%s]: %w", opts.Req.Spec.GetUuid(), err)
+	}
+	if outcome.Status == approvals.StatusPending {
+		return processTelegramPendingApproval(opts, *outcome)
+	}
+	opts.Req.Spec.Approval = newApprovalSpec(newApprovalSpecOpts{
+		Platform:      approvals.PlatformTelegram,
+		Req:           opts.Req,
+		ResponderId:   approverId,
+		ResponderName: approverName,
+		Status:        outcome.Status,
+	})
 	approval := Approval{Spec: *opts.Req.Spec.Approval}
 	if err := approval.Create(); err != nil {
 		opts.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "failed to create approval[%s]: %s", approval.Spec.Id, err)
@@ -741,6 +751,7 @@ func processTelegramApproval(opts processTelegramApprovalOpts) error {
 	if err := opts.Bot.ReplyMessage(opts.ChatId, opts.ApprovalMessageId, getTelegramApproveMessage(*opts.Req, opts.SenderId, approverName)); err != nil {
 		opts.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "failed to send approval message to chat[%v]: %s", opts.ChatId, err)
 	}
+	unlock()
 	if err := handleCallback(handleCallbackOpts{
 		Req:         opts.Req,
 		ServiceLogs: opts.ServiceLogs,
@@ -749,3 +760,57 @@ func processTelegramApproval(opts processTelegramApprovalOpts) error {
 	}
 	return nil
 }
+
+// processTelegramPendingApproval records an approval via Telegram which
+// did not decide the approval request and sends the request to the next
+// stage if the approval completed the current stage
+func processTelegramPendingApproval(opts processTelegramApprovalOpts, outcome approvals.ResponseOutcome) error {
+	opts.Req.Spec.Actions = append(
+		opts.Req.Spec.Actions,
+		approvals.Action{
+			HappenedAt:  time.Now(),
+			MessageId:   strconv.FormatInt(int64(opts.ApprovalMessageId), 10),
+			Platform:    string(approvals.PlatformTelegram),
+			RequestUuid: opts.Req.Spec.GetUuid(),
+			TargetId:    strconv.FormatInt(opts.ChatId, 10),
+			Status:      approvals.StatusApproved,
+			UserId:      strconv.FormatInt(opts.SenderId, 10),
+			UserName:    opts.SenderUsername,
+		},
+	)
+	if err := opts.Req.Update(); err != nil {
+		opts.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "failed to update request[%s]: %s", opts.Req.Spec.GetUuid(), err)
+		if err := opts.Bot.ReplyMessage(opts.ChatId, opts.ApprovalMessageId, "🙇🏼 Apologies, something went wrong internally"); err != nil {
+			opts.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "failed to send error response to user: %s", err)
+		}
+		return fmt.Errorf("failed to update approvalRequest[%s]: %s", opts.Req.Spec.GetUuid(), err)
+	}
+
+	// the keyboard is kept so that other responders in the chat can still
+	// approve the current stage, this also restores the keyboard after it
+	// was removed for a pending mfa
+	var keyboard models.ReplyMarkup
+	if !outcome.IsStageCompleted {
+		keyboard = getTelegramApprovalKeyboard(
+			createTelegramApprovalCallbackData(ActionApprove, opts.Req.Spec.GetUuid()),
+			createTelegramApprovalCallbackData(ActionReject, opts.Req.Spec.GetUuid()),
+		)
+	}
+	if err := opts.Bot.UpdateMessage(
+		opts.ChatId,
+		opts.ApprovalMessageId,
+		getTelegramPendingApprovalMessage(*opts.Req, outcome),
+		keyboard,
+	); err != nil {
+		opts.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "failed to update message[%v]: %s", opts.ApprovalMessageId, err)
+	}
+	if err := opts.Bot.ReplyMessage(opts.ChatId, opts.ApprovalMessageId, getTelegramPendingApproveMessage(*opts.Req, outcome, opts.SenderId, opts.SenderUsername)); err != nil {
+		opts.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "failed to send approval message to chat[%v]: %s", opts.ChatId, err)
+	}
+	if outcome.IsStageCompleted {
+		if err := sendApprovalRequestStage(opts.Req, opts.ServiceLogs); err != nil {
+			return fmt.Errorf("failed to send next stage of approvalRequest[%s]: %w", opts.Req.Spec.GetUuid(), err)
+		}
+	}
+	return nil
+}
@@ -1,9 +1,12 @@
 package approver
 
 import (
+	"errors"
 	"fmt"
+	"opsicle/internal/approvals"
 	"opsicle/internal/integrations/telegram"
 
+	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 )
 
@@ -191,3 +194,122 @@ func getTelegramSystemErrorMessage() string {
 func getTelegramUnauthorizedMessage() string {
 	return "⚠️ You are not authorised to perform this action"
 }
+
+// getTelegramStageProgress returns a description of the progress of the
+// stage which was responded to for use in other templates
+func getTelegramStageProgress(outcome approvals.ResponseOutcome) string {
+	progress := telegram.FormatInputf(
+		"%s of %s approvals",
+		fmt.Sprintf("%v", outcome.Approvals),
+		fmt.Sprintf("%v", outcome.Stage.GetMinApprovals()),
+	)
+	if outcome.StageCount > 1 {
+		progress += telegram.FormatInputf(
+			" for stage `%s` \\(%s of %s\\)",
+			outcome.Stage.Name,
+			fmt.Sprintf("%v", outcome.StageIndex+1),
+			fmt.Sprintf("%v", outcome.StageCount),
+		)
+	}
+	return progress
+}
+
+// getTelegramPendingApprovalMessage returns the message template for
+// replacing the approval request message after an approval which did
+// not decide the approval request
+func getTelegramPendingApprovalMessage(req ApprovalRequest, outcome approvals.ResponseOutcome) string {
+	status := "PENDING"
+	if outcome.IsStageCompleted {
+		status = "PENDING NEXT STAGE"
+	}
+	return telegram.FormatInputf(
+		"*☑️ Approval Request \\- Partially Approved*\n"+
+			"*Request ID*: `%s`\n\n"+
+			"*Message*: ```\n%s\n```"+
+			"*Requester ID*: `%s`\n"+
+			"*Requester Name*: `%s`\n"+
+			"*Request UUID*: `%s`\n"+
+			"\nStatus: *%s*\n",
+		req.Spec.Id,
+		req.Spec.Message,
+		req.Spec.RequesterId,
+		req.Spec.RequesterName,
+		req.Spec.GetUuid(),
+		status,
+	) + "Progress: " + getTelegramStageProgress(outcome)
+}
+
+// getTelegramPendingApproveMessage returns the message to be sent to the
+// chat when an approval has been recorded but more are required
+func getTelegramPendingApproveMessage(req ApprovalRequest, outcome approvals.ResponseOutcome, senderId int64, senderName string) string {
+	userReference := fmt.Sprintf("user with ID `%v`", senderId)
+	if senderName != "" {
+		userReference = fmt.Sprintf("@%s", bot.EscapeMarkdown(senderName))
+	}
+	msg := "☑️ Approval by " + userReference + " recorded, " + getTelegramStageProgress(outcome)
+	if outcome.IsStageCompleted {
+		msg += "\n⏭️ Stage approved, the request has been sent to the next stage"
+	}
+	return msg + telegram.FormatInputf(
+		"\n\n*Request UUID*: `%s`",
+		req.Spec.GetUuid(),
+	)
+}
+
+// getTelegramResponseNotAcceptedMessage returns the message to be sent to
+// a responder whose response could not be added to the approval request
+func getTelegramResponseNotAcceptedMessage(err error) string {
+	switch {
+	case errors.Is(err, approvals.ErrorRequestDecided):
+		return "⚠️ This request has already been decided"
+	case errors.Is(err, approvals.ErrorRequestExpired):
+		return "⚠️ This request has expired"
+	case errors.Is(err, approvals.ErrorResponseDuplicated):
+		return "⚠️ You have already approved this request"
+	case errors.Is(err, approvals.ErrorSelfApproval):
+		return "⚠️ You cannot approve a request which you made"
+	}
+	return getTelegramSystemErrorMessage()
+}
+
+// getTelegramExpiredMessage returns the message template for replacing
+// the approval request message after the approval request has expired
+func getTelegramExpiredMessage(req ApprovalRequest) string {
+	return telegram.FormatInputf(
+		"*⌛️ Approval Request \\- Expired*\n"+
+			"*Request ID*: `%s`\n\n"+
+			"*Message*: ```\n%s\n```"+
+			"*Requester ID*: `%s`\n"+
+			"*Requester Name*: `%s`\n"+
+			"*Request UUID*: `%s`\n"+
+			"*Expired At*: `%s`\n"+
+			"\nStatus: *EXPIRED*",
+		req.Spec.Id,
+		req.Spec.Message,
+		req.Spec.RequesterId,
+		req.Spec.RequesterName,
+		req.Spec.GetUuid(),
+		req.Spec.GetExpiresAt().Format("2006-01-02T15:04:05-0700"),
+	)
+}
+
+// getTelegramExpiryMessage returns the message to be sent to the chat
+// when the approval request has expired
+func getTelegramExpiryMessage(req ApprovalRequest) string {
+	return telegram.FormatInputf(
+		"⌛️ Request expired without being decided\n\n"+
+			"*Request UUID*: `%s`",
+		req.Spec.GetUuid(),
+	)
+}
+
+// getTelegramReminderMessage returns the message to be sent to the chat
+// as a reminder that the approval request is awaiting a response
+func getTelegramReminderMessage(req ApprovalRequest) string {
+	return telegram.FormatInputf(
+		"🔔 Reminder: this request is still awaiting a response and expires at `%s`\n\n"+
+			"*Request UUID*: `%s`",
+		req.Spec.GetExpiresAt().Format("2006-01-02T15:04:05-0700"),
+		req.Spec.GetUuid(),
+	)
+}
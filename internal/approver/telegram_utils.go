@@ -102,3 +102,17 @@ func getTelegramTargetMatchingSender(opts getTelegramTargetMatchingSenderOpts) (
 
 	return isChatMatched && isSenderMatched, &matchedSender
 }
+
+// parseTelegramNotification returns the chat ID and message ID of a
+// message sent to Telegram
+func parseTelegramNotification(notification approvals.Notification) (chatId int64, messageId int, err error) {
+	chatId, err = strconv.ParseInt(notification.TargetId, 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to parse chat id[%s]: %w", notification.TargetId, err)
+	}
+	messageId, err = strconv.Atoi(notification.MessageId)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to parse message id[%s]: %w", notification.MessageId, err)
+	}
+	return chatId, messageId, nil
+}
@@ -35,6 +35,7 @@ const (
 type EntityType string
 
 const (
+	ApproverEntity    EntityType = "approver"
 	UserEntity        EntityType = "user"
 	OrgEntity         EntityType = "org"
 	ControllerEntity  EntityType = "controller"
@@ -45,18 +46,22 @@ const (
 type ResourceType string
 
 const (
+	ApprovalPolicyResource            ResourceType = "approval_policy"
 	AutomationTemplateResource        ResourceType = "autotmpl"
 	AutomationResource                ResourceType = "automation"
+	AutomationScheduleResource        ResourceType = "automation_schedule"
 	CacheResource                     ResourceType = "cache"
 	ConfigResource                    ResourceType = "config"
 	DbResource                        ResourceType = "db"
 	OrgResource                       ResourceType = "org"
 	OrgConfigResource                 ResourceType = "org_config"
+	OrgSecretResource                 ResourceType = "org_secret"
 	OrgMemberTypesResource            ResourceType = "org_member_types"
 	OrgUserResource                   ResourceType = "org_user"
 	OrgUserInvitationResource         ResourceType = "org_user_invitation"
 	QueueResource                     ResourceType = "queue"
 	SessionResource                   ResourceType = "session"
+	TemplateTriggerResource           ResourceType = "template_trigger"
 	TemplateUserInvitationResource    ResourceType = "template_user_invitation"
 	UserResource                      ResourceType = "user"
 	UserConfigResource                ResourceType = "user_config"
@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	encryptionKeyLen = 32
+	encryptionScheme = "aes256gcm"
+)
+
+// DeriveEncryptionKey derives a 256-bit key from the provided `secret`
+// which is scoped to the provided `purpose` so that the same secret can
+// be used to derive keys for different purposes
+func DeriveEncryptionKey(secret []byte, purpose string) ([]byte, error) {
+	key := make([]byte, encryptionKeyLen)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, nil, []byte(purpose)), key); err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	return key, nil
+}
+
+// Encrypt encrypts the `plaintext` using AES-256-GCM with the provided
+// `key` and returns an encoded string in the format
+// `$aes256gcm$<nonce>$<ciphertext>`
+func Encrypt(key, plaintext []byte) (string, error) {
+	gcm, err := getGcm(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	ciphertext := gcm.Seal(nil, nonce, plaintext, nil)
+	return fmt.Sprintf("$%s$%s$%s",
+		encryptionScheme,
+		base64.RawStdEncoding.EncodeToString(nonce),
+		base64.RawStdEncoding.EncodeToString(ciphertext),
+	), nil
+}
+
+// Decrypt reverses Encrypt
+func Decrypt(key []byte, encoded string) ([]byte, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 4 || parts[1] != encryptionScheme {
+		return nil, ErrorEncryptedValueInvalid
+	}
+	nonce, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to decode nonce: %w", ErrorEncryptedValueInvalid, err)
+	}
+	ciphertext, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to decode ciphertext: %w", ErrorEncryptedValueInvalid, err)
+	}
+	gcm, err := getGcm(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("%w: invalid nonce size", ErrorEncryptedValueInvalid)
+	}
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrorDecryptionFailed, err)
+	}
+	return plaintext, nil
+}
+
+// IsEncrypted returns true if the provided value looks like an output
+// of Encrypt
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, "$"+encryptionScheme+"$")
+}
+
+func getGcm(key []byte) (cipher.AEAD, error) {
+	if len(key) != encryptionKeyLen {
+		return nil, ErrorEncryptionKeyInvalid
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gcm: %w", err)
+	}
+	return gcm, nil
+}
@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	key, err := DeriveEncryptionKey([]byte("master-key"), "test")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	encrypted, err := Encrypt(key, []byte("hunter2"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !IsEncrypted(encrypted) {
+		t.Fatalf("expected %s to be recognised as encrypted", encrypted)
+	}
+	decrypted, err := Decrypt(key, encrypted)
+	if err != nil || string(decrypted) != "hunter2" {
+		t.Fatalf("expected hunter2, got %s (%v)", decrypted, err)
+	}
+
+	otherKey, _ := DeriveEncryptionKey([]byte("master-key"), "other")
+	if _, err := Decrypt(otherKey, encrypted); !errors.Is(err, ErrorDecryptionFailed) {
+		t.Fatalf("expected decryption with a different key to fail, got %v", err)
+	}
+}
@@ -3,6 +3,10 @@ package auth
 import "errors"
 
 var (
+	ErrorDecryptionFailed      = errors.New("decryption_failed")
+	ErrorEncryptedValueInvalid = errors.New("encrypted_value_invalid")
+	ErrorEncryptionKeyInvalid  = errors.New("encryption_key_invalid")
+
 	ErrorEmailAliasesNotAllowed          = errors.New("email_aliases_not_allowed")
 	ErrorEmailDomainInvalid              = errors.New("email_domain_invalid")
 	ErrorEmailDomainTldNotAllowlisted    = errors.New("email_domain_tld_not_allowlisted")
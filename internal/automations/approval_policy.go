@@ -1,14 +1,42 @@
 package automations
 
-import "opsicle/internal/approvals"
+import (
+	"fmt"
+	"opsicle/internal/approvals"
+)
 
 // ApprovalPolicySpec defines the approval mechanism in play for
 // the automation
 type ApprovalPolicySpec struct {
 	// PolicyRef when defined should be a string that references an
-	// existing policy which can be retrieved by the controller
+	// existing policy which can be retrieved by the controller, the
+	// referenced policy is copied into `.Spec` when the template is
+	// submitted to an org
 	PolicyRef *string `json:"policyRef" yaml:"policyRef"`
 
 	// Spec contains an inline approval policy
 	Spec *approvals.PolicySpec `json:"spec" yaml:"spec"`
 }
+
+// IsResolved returns true if the policy can be used without looking up
+// the policy referenced by `.PolicyRef`
+func (a ApprovalPolicySpec) IsResolved() bool {
+	return a.Spec != nil
+}
+
+// Validate returns an error if the approval policy neither references
+// a policy nor defines one inline or if the inline policy is invalid
+func (a ApprovalPolicySpec) Validate() error {
+	if a.PolicyRef == nil && a.Spec == nil {
+		return fmt.Errorf("%w: one of policyRef or spec is required", ErrorApprovalPolicyInvalid)
+	}
+	if a.PolicyRef != nil && *a.PolicyRef == "" {
+		return fmt.Errorf("%w: policyRef cannot be empty", ErrorApprovalPolicyInvalid)
+	}
+	if a.Spec != nil {
+		if err := a.Spec.Validate(); err != nil {
+			return fmt.Errorf("%w: spec: %w", ErrorApprovalPolicyInvalid, err)
+		}
+	}
+	return nil
+}
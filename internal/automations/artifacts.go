@@ -0,0 +1,55 @@
+package automations
+
+import (
+	"path"
+	"strings"
+)
+
+const (
+	// WorkspacePath is where the per-run workspace volume is mounted in
+	// every phase's container
+	WorkspacePath = "/workspace"
+
+	// WorkspaceEnvName is the name of the environment variable which
+	// contains the WorkspacePath
+	WorkspaceEnvName = "OPSICLE_WORKSPACE"
+
+	// MaxArtifactsSize is the maximum size in bytes of the compressed
+	// artifacts of an automation run
+	MaxArtifactsSize = 32 * 1024 * 1024
+
+	// MaxArtifactsRequestSize is the maximum size in bytes of a request
+	// body which carries base64-encoded artifacts
+	MaxArtifactsRequestSize = MaxArtifactsSize * 2
+)
+
+// GetOutputPath returns the absolute path in the phase's container of
+// the provided `output`
+func GetOutputPath(output string) string {
+	if path.IsAbs(output) {
+		return path.Clean(output)
+	}
+	return path.Join(WorkspacePath, output)
+}
+
+// GetArtifactPath returns the path in the artifacts tarball where the
+// provided `output` of the phase identified by `phaseName` is stored
+func GetArtifactPath(phaseName, output string) string {
+	outputPath := GetOutputPath(output)
+	if relativePath, isInWorkspace := strings.CutPrefix(outputPath, WorkspacePath+"/"); isInWorkspace {
+		outputPath = relativePath
+	}
+	return path.Join(phaseName, strings.TrimPrefix(outputPath, "/"))
+}
+
+func isValidOutput(output string) bool {
+	if strings.TrimSpace(output) == "" {
+		return false
+	}
+	for _, segment := range strings.Split(output, "/") {
+		if segment == ".." {
+			return false
+		}
+	}
+	return GetOutputPath(output) != "/"
+}
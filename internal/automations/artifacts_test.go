@@ -0,0 +1,22 @@
+package automations
+
+import "testing"
+
+func TestGetArtifactPath(t *testing.T) {
+	cases := map[string]string{
+		"report.txt":            "build/report.txt",
+		"dist/":                 "build/dist",
+		"/workspace/out/a.json": "build/out/a.json",
+		"/tmp/result.txt":       "build/tmp/result.txt",
+	}
+	for output, expected := range cases {
+		if observed := GetArtifactPath("build", output); observed != expected {
+			t.Errorf("expected output[%s] to be stored at '%s', got '%s'", output, expected, observed)
+		}
+	}
+	for _, output := range []string{"", "/", "../etc/passwd", "/workspace/../etc"} {
+		if isValidOutput(output) {
+			t.Errorf("expected output[%s] to be invalid", output)
+		}
+	}
+}
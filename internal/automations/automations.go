@@ -1,6 +1,26 @@
 package automations
 
-import "time"
+import (
+	"strings"
+	"time"
+)
+
+const (
+	ImagePullPolicyAlways       = "Always"
+	ImagePullPolicyIfNotPresent = "IfNotPresent"
+	ImagePullPolicyNever        = "Never"
+)
+
+var ImagePullPolicies = []string{
+	ImagePullPolicyAlways,
+	ImagePullPolicyIfNotPresent,
+	ImagePullPolicyNever,
+}
+
+// VolumeMountClaimPrefix is the prefix of a VolumeMount's host which
+// identifies it as the name of a PersistentVolumeClaim instead of a path
+// on the host
+const VolumeMountClaimPrefix = "pvc:"
 
 type AutomationSpec struct {
 	// VolumeMounts defines any volume mounts in play when containers are
@@ -10,14 +30,30 @@ type AutomationSpec struct {
 	// Phases defines the various steps of the automation
 	Phases []Phase `json:"phases" yaml:"phases"`
 
+	// MaxParallelPhases is the maximum number of phases which can run
+	// at the same time, DefaultMaxParallelPhases is used when not set
+	MaxParallelPhases int `json:"maxParallelPhases,omitempty" yaml:"maxParallelPhases,omitempty"`
+
 	// Variables is used during processing but not during definition
 	Variables VariablesSpec `json:"variables" yaml:"-"`
 
+	// Concurrency is used during processing but not during definition,
+	// this is the template's concurrency spec with its key rendered
+	Concurrency *ConcurrencySpec `json:"concurrency,omitempty" yaml:"-"`
+
+	// Resume is used during processing but not during definition, this
+	// is defined when the run resumes a previous run from one of its
+	// phases
+	Resume *ResumeSpec `json:"resume,omitempty" yaml:"-"`
+
 	// Status is used during processing but not during definition
 	Status AutomationStatus `json:"status" yaml:"-"`
 }
 
 type VolumeMount struct {
+	// Host is the path on the host to mount, when using the kubernetes
+	// runtime this can be the name of a PersistentVolumeClaim prefixed
+	// with VolumeMountClaimPrefix
 	Host      string `json:"host" yaml:"host"`
 	Container string `json:"container" yaml:"container"`
 }
@@ -28,6 +64,98 @@ type Phase struct {
 	Commands []string  `json:"command" yaml:"commands"`
 	Timeout  int       `json:"timeout" yaml:"timeout"`
 	Logs     PhaseLogs `json:"logs,omitempty" yaml:"logs"`
+
+	// ImagePullPolicy determines when the image is pulled, one of
+	// ImagePullPolicies; defaults to ImagePullPolicyAlways
+	ImagePullPolicy string `json:"imagePullPolicy,omitempty" yaml:"imagePullPolicy,omitempty"`
+
+	// Retries is the number of times the phase is re-run after a
+	// failure before the phase is considered failed
+	Retries int `json:"retries,omitempty" yaml:"retries,omitempty"`
+
+	// RetryBackoffSeconds is the number of seconds to wait between
+	// retries of the phase
+	RetryBackoffSeconds int `json:"retryBackoffSeconds,omitempty" yaml:"retryBackoffSeconds,omitempty"`
+
+	// ContinueOnError when true allows subsequent phases to run and the
+	// automation to succeed even if this phase fails
+	ContinueOnError bool `json:"continueOnError,omitempty" yaml:"continueOnError,omitempty"`
+
+	// AllowedExitCodes are exit codes other than 0 which indicate that
+	// the phase was successful
+	AllowedExitCodes []int `json:"allowedExitCodes,omitempty" yaml:"allowedExitCodes,omitempty"`
+
+	// DependsOn are names of phases which must complete before this
+	// phase starts; see AutomationSpec.GetPhaseDependencies for how
+	// phases are ordered when this is not defined
+	DependsOn []string `json:"dependsOn,omitempty" yaml:"dependsOn,omitempty"`
+
+	// When is a condition which must evaluate to true for the phase to
+	// run, phases whose condition evaluates to false are skipped; see
+	// Condition for the supported syntax
+	When string `json:"when,omitempty" yaml:"when,omitempty"`
+
+	// Outputs are paths of files or directories which are collected into
+	// the automation's artifacts after the phase completes; relative
+	// paths are resolved against the workspace at WorkspacePath
+	Outputs []string `json:"outputs,omitempty" yaml:"outputs,omitempty"`
+
+	// Resources limits the compute resources available to the phase
+	Resources *PhaseResources `json:"resources,omitempty" yaml:"resources,omitempty"`
+
+	// Network is the network mode of the phase, one of PhaseNetworks;
+	// the runtime's default is used when not defined
+	Network string `json:"network,omitempty" yaml:"network,omitempty"`
+
+	// User is the user that commands are run as in the form of
+	// `user[:group]`, the image's default user is used when not defined
+	User string `json:"user,omitempty" yaml:"user,omitempty"`
+
+	// ReadOnlyRootFs when true mounts the root filesystem of the phase
+	// as read-only, the workspace and volume mounts remain writable
+	ReadOnlyRootFs bool `json:"readOnlyRootFs,omitempty" yaml:"readOnlyRootFs,omitempty"`
+
+	// CapDrop are the Linux capabilities which are dropped, eg. "ALL"
+	CapDrop []string `json:"capDrop,omitempty" yaml:"capDrop,omitempty"`
+
+	// Privileged when true runs the phase with all capabilities and
+	// access to the host's devices, this is rejected unless the
+	// worker's policy allows it
+	Privileged bool `json:"privileged,omitempty" yaml:"privileged,omitempty"`
+}
+
+// GetImagePullPolicy returns the image pull policy of the phase with
+// the default applied
+func (p Phase) GetImagePullPolicy() string {
+	if p.ImagePullPolicy == "" {
+		return ImagePullPolicyAlways
+	}
+	return p.ImagePullPolicy
+}
+
+// IsImagePinned returns true if the image of the phase is referenced
+// by its digest
+func (p Phase) IsImagePinned() bool {
+	return strings.Contains(p.Image, "@sha256:")
+}
+
+// IsExitCodeAllowed returns true if the provided exit code indicates
+// that the phase was successful
+func (p Phase) IsExitCodeAllowed(exitCode int) bool {
+	if exitCode == 0 {
+		return true
+	}
+	for _, allowedExitCode := range p.AllowedExitCodes {
+		if allowedExitCode == exitCode {
+			return true
+		}
+	}
+	return false
+}
+
+// GetRetryBackoff returns the duration to wait between retries
+func (p Phase) GetRetryBackoff() time.Duration {
+	return time.Duration(p.RetryBackoffSeconds) * time.Second
 }
 
 type PhaseLogs []PhaseLog
@@ -40,5 +168,14 @@ type PhaseLog struct {
 
 type AutomationStatus struct {
 	Id       string    `json:"id"`
+	OrgId    *string   `json:"orgId,omitempty"`
 	QueuedAt time.Time `json:"queuedAt"`
 }
+
+// GetVolumeMountClaim returns the name of the PersistentVolumeClaim
+// referenced by the volume mount when its host is prefixed with
+// VolumeMountClaimPrefix; this is only used by the kubernetes runtime
+func GetVolumeMountClaim(vm VolumeMount) (string, bool) {
+	claimName, isClaim := strings.CutPrefix(vm.Host, VolumeMountClaimPrefix)
+	return claimName, isClaim && claimName != ""
+}
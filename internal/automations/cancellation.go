@@ -0,0 +1,30 @@
+package automations
+
+import "time"
+
+// Cancellation is published by the controller when a user cancels an
+// automation run or by the coordinator when a run is superseded by a
+// newer run sharing its concurrency key, workers processing the run stop
+// its phases when they receive it
+type Cancellation struct {
+	AutomationId string  `json:"automationId"`
+	OrgId        *string `json:"orgId,omitempty"`
+
+	// CancelledBy is the ID of the user who cancelled the run, this is
+	// empty when the run was cancelled by the coordinator
+	CancelledBy string `json:"cancelledBy"`
+
+	// Reason is an optional human-readable reason for the cancellation
+	Reason string `json:"reason,omitempty"`
+
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// GetMessage returns the message used in the status of the cancelled
+// automation run
+func (c Cancellation) GetMessage() string {
+	if c.Reason == "" {
+		return "automation was cancelled"
+	}
+	return "automation was cancelled: " + c.Reason
+}
@@ -0,0 +1,107 @@
+package automations
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"text/template"
+)
+
+// ConcurrencyPolicy defines what happens to an automation run when the
+// maximum number of runs sharing its concurrency key are already running
+type ConcurrencyPolicy string
+
+const (
+	// ConcurrencyPolicyQueue holds the run in the queue until a run
+	// sharing its concurrency key completes
+	ConcurrencyPolicyQueue ConcurrencyPolicy = "queue"
+
+	// ConcurrencyPolicyReject rejects the run
+	ConcurrencyPolicyReject ConcurrencyPolicy = "reject"
+
+	// ConcurrencyPolicyCancelPrevious cancels the runs sharing the
+	// concurrency key and holds the run in the queue until they stop
+	ConcurrencyPolicyCancelPrevious ConcurrencyPolicy = "cancel-previous"
+
+	DefaultConcurrencyMaxParallel = 1
+	DefaultConcurrencyPolicy      = ConcurrencyPolicyQueue
+)
+
+var ConcurrencyPolicies = []ConcurrencyPolicy{
+	ConcurrencyPolicyQueue,
+	ConcurrencyPolicyReject,
+	ConcurrencyPolicyCancelPrevious,
+}
+
+// ConcurrencySpec limits the number of automation runs sharing the same
+// key which can run at the same time, this is enforced by the
+// coordinator across all workers of an org
+type ConcurrencySpec struct {
+	// Key identifies runs which should not run at the same time, this
+	// can reference variables using Go templates (eg.
+	// `db-{{ .vars.cluster }}`)
+	Key string `json:"key" yaml:"key"`
+
+	// MaxParallel is the maximum number of runs sharing the key which
+	// can run at the same time, DefaultConcurrencyMaxParallel is used
+	// when not set
+	MaxParallel int `json:"maxParallel,omitempty" yaml:"maxParallel,omitempty"`
+
+	// Policy defines what happens to runs when `MaxParallel` runs are
+	// already running, DefaultConcurrencyPolicy is used when not set
+	Policy ConcurrencyPolicy `json:"policy,omitempty" yaml:"policy,omitempty"`
+}
+
+// GetMaxParallel returns the maximum number of runs sharing the key
+// which can run at the same time
+func (c ConcurrencySpec) GetMaxParallel() int {
+	if c.MaxParallel <= 0 {
+		return DefaultConcurrencyMaxParallel
+	}
+	return c.MaxParallel
+}
+
+// GetPolicy returns the policy applied to runs when the maximum number
+// of runs sharing the key are already running
+func (c ConcurrencySpec) GetPolicy() ConcurrencyPolicy {
+	if c.Policy == "" {
+		return DefaultConcurrencyPolicy
+	}
+	return c.Policy
+}
+
+// Render returns a copy of the spec with the Go template expressions in
+// `.Key` evaluated; variables are accessible using `{{ .vars.<id> }}`
+func (c ConcurrencySpec) Render(vars map[string]any) (ConcurrencySpec, error) {
+	rendered := c
+	key, err := renderString("concurrency.key", c.Key, map[string]any{"vars": vars})
+	if err != nil {
+		return c, err
+	}
+	if key == "" {
+		return c, fmt.Errorf("%w: key rendered to an empty string", ErrorConcurrencyInvalid)
+	}
+	rendered.Key = key
+	return rendered, nil
+}
+
+// Validate returns an error describing all problems found with the
+// concurrency spec
+func (c ConcurrencySpec) Validate() error {
+	errs := []error{}
+	if c.Key == "" {
+		errs = append(errs, fmt.Errorf("%w: key is required", ErrorConcurrencyInvalid))
+	} else if _, err := template.New("concurrency.key").Parse(c.Key); err != nil {
+		errs = append(errs, fmt.Errorf("%w: key is not a valid template: %w", ErrorConcurrencyInvalid, err))
+	}
+	if c.MaxParallel < 0 {
+		errs = append(errs, fmt.Errorf("%w: maxParallel cannot be negative", ErrorConcurrencyInvalid))
+	}
+	if c.Policy != "" && !slices.Contains(ConcurrencyPolicies, c.Policy) {
+		errs = append(errs, fmt.Errorf("%w: policy '%s' must be one of %v", ErrorConcurrencyInvalid, c.Policy, ConcurrencyPolicies))
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package automations
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestConcurrencySpec(t *testing.T) {
+	spec := ConcurrencySpec{Key: "db-{{ .vars.cluster }}"}
+	if err := spec.Validate(); err != nil {
+		t.Fatalf("expected valid concurrency spec, got %v", err)
+	}
+	if spec.GetMaxParallel() != DefaultConcurrencyMaxParallel || spec.GetPolicy() != DefaultConcurrencyPolicy {
+		t.Fatalf("expected defaults to be applied, got %v and %s", spec.GetMaxParallel(), spec.GetPolicy())
+	}
+	rendered, err := spec.Render(map[string]any{"cluster": "primary"})
+	if err != nil {
+		t.Fatalf("expected key to render, got %v", err)
+	}
+	if rendered.Key != "db-primary" || spec.Key != "db-{{ .vars.cluster }}" {
+		t.Fatalf("expected rendered key 'db-primary' without changing the spec, got '%s'", rendered.Key)
+	}
+	if _, err := spec.Render(map[string]any{}); err == nil {
+		t.Fatalf("expected error for missing variable")
+	}
+
+	invalidSpec := ConcurrencySpec{Key: "db-{{ .vars.cluster", MaxParallel: -1, Policy: "wait"}
+	if err := invalidSpec.Validate(); !errors.Is(err, ErrorConcurrencyInvalid) {
+		t.Fatalf("expected invalid concurrency error, got %v", err)
+	}
+}
@@ -0,0 +1,517 @@
+package automations
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Condition is a parsed `when` expression of a phase. Expressions
+// support `&&`, `||`, `!`, parentheses, the comparison operators `==`,
+// `!=`, `<`, `<=`, `>` and `>=`, and string, number, boolean and `null`
+// literals. Values are referenced by dot-separated paths into the data
+// the condition is evaluated against, eg. `vars.environment` or
+// `phases.precheck.exitCode`; segments which are not valid identifiers
+// can be referenced using `vars["some-id"]`
+type Condition struct {
+	expression string
+	root       conditionNode
+}
+
+// ParseCondition parses the provided expression
+func ParseCondition(expression string) (*Condition, error) {
+	tokens, err := tokenizeCondition(expression)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrorConditionInvalid, err)
+	}
+	parser := conditionParser{tokens: tokens}
+	root, err := parser.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrorConditionInvalid, err)
+	}
+	if !parser.isDone() {
+		return nil, fmt.Errorf("%w: unexpected '%s' at position %v", ErrorConditionInvalid, parser.peek().value, parser.peek().position)
+	}
+	return &Condition{expression: expression, root: root}, nil
+}
+
+// Evaluate returns the result of the condition against the provided
+// data; an error is returned if the condition does not evaluate to a
+// boolean or if it references a path that does not exist
+func (c *Condition) Evaluate(data map[string]any) (bool, error) {
+	value, err := c.root.evaluate(data)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", ErrorConditionFailed, err)
+	}
+	result, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("%w: expression evaluated to %v instead of a boolean", ErrorConditionFailed, value)
+	}
+	return result, nil
+}
+
+// GetReferences returns the paths referenced by the condition
+func (c *Condition) GetReferences() [][]string {
+	references := [][]string{}
+	c.root.collectReferences(&references)
+	return references
+}
+
+func (c *Condition) String() string {
+	return c.expression
+}
+
+const (
+	ConditionRootPhases = "phases"
+	ConditionRootVars   = "vars"
+
+	ConditionPhaseFieldAttempts = "attempts"
+	ConditionPhaseFieldExitCode = "exitCode"
+	ConditionPhaseFieldStatus   = "status"
+)
+
+type conditionTokenType int
+
+const (
+	conditionTokenIdentifier conditionTokenType = iota
+	conditionTokenNumber
+	conditionTokenString
+	conditionTokenOperator
+)
+
+type conditionToken struct {
+	kind     conditionTokenType
+	value    string
+	position int
+}
+
+func tokenizeCondition(expression string) ([]conditionToken, error) {
+	tokens := []conditionToken{}
+	runes := []rune(expression)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"' || r == '\'':
+			start := i
+			var value strings.Builder
+			i++
+			for i < len(runes) && runes[i] != r {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				value.WriteRune(runes[i])
+				i++
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated string at position %v", start)
+			}
+			i++
+			tokens = append(tokens, conditionToken{kind: conditionTokenString, value: value.String(), position: start})
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, conditionToken{kind: conditionTokenNumber, value: string(runes[start:i]), position: start})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '-') {
+				i++
+			}
+			tokens = append(tokens, conditionToken{kind: conditionTokenIdentifier, value: string(runes[start:i]), position: start})
+		default:
+			start := i
+			operator := ""
+			if i+1 < len(runes) {
+				switch twoRunes := string(runes[i : i+2]); twoRunes {
+				case "&&", "||", "==", "!=", "<=", ">=":
+					operator = twoRunes
+				}
+			}
+			if operator == "" {
+				switch r {
+				case '!', '<', '>', '(', ')', '[', ']', '.':
+					operator = string(r)
+				default:
+					return nil, fmt.Errorf("unexpected character '%c' at position %v", r, i)
+				}
+			}
+			i += len(operator)
+			tokens = append(tokens, conditionToken{kind: conditionTokenOperator, value: operator, position: start})
+		}
+	}
+	return tokens, nil
+}
+
+type conditionParser struct {
+	tokens   []conditionToken
+	position int
+}
+
+func (p *conditionParser) isDone() bool {
+	return p.position >= len(p.tokens)
+}
+
+func (p *conditionParser) peek() conditionToken {
+	if p.isDone() {
+		return conditionToken{position: -1}
+	}
+	return p.tokens[p.position]
+}
+
+func (p *conditionParser) isOperator(operators ...string) bool {
+	token := p.peek()
+	if p.isDone() || token.kind != conditionTokenOperator {
+		return false
+	}
+	for _, operator := range operators {
+		if token.value == operator {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *conditionParser) expectOperator(operator string) error {
+	if !p.isOperator(operator) {
+		if p.isDone() {
+			return fmt.Errorf("expected '%s' but reached the end of the expression", operator)
+		}
+		return fmt.Errorf("expected '%s' at position %v", operator, p.peek().position)
+	}
+	p.position++
+	return nil
+}
+
+func (p *conditionParser) parseOr() (conditionNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("||") {
+		p.position++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = conditionLogicalNode{operator: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseAnd() (conditionNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("&&") {
+		p.position++
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = conditionLogicalNode{operator: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseNot() (conditionNode, error) {
+	if p.isOperator("!") {
+		p.position++
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return conditionNotNode{operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *conditionParser) parseComparison() (conditionNode, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if p.isOperator("==", "!=", "<", "<=", ">", ">=") {
+		operator := p.peek().value
+		p.position++
+		right, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		return conditionComparisonNode{operator: operator, left: left, right: right}, nil
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parsePrimary() (conditionNode, error) {
+	if p.isDone() {
+		return nil, fmt.Errorf("unexpected end of the expression")
+	}
+	token := p.peek()
+	switch token.kind {
+	case conditionTokenString:
+		p.position++
+		return conditionLiteralNode{value: token.value}, nil
+	case conditionTokenNumber:
+		p.position++
+		value, err := strconv.ParseFloat(token.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number '%s' at position %v", token.value, token.position)
+		}
+		return conditionLiteralNode{value: value}, nil
+	case conditionTokenIdentifier:
+		p.position++
+		switch token.value {
+		case "true":
+			return conditionLiteralNode{value: true}, nil
+		case "false":
+			return conditionLiteralNode{value: false}, nil
+		case "null":
+			return conditionLiteralNode{value: nil}, nil
+		}
+		path := []string{token.value}
+		for p.isOperator(".", "[") {
+			if p.isOperator(".") {
+				p.position++
+				segment := p.peek()
+				if p.isDone() || segment.kind != conditionTokenIdentifier {
+					return nil, fmt.Errorf("expected an identifier after '.' at position %v", token.position)
+				}
+				p.position++
+				path = append(path, segment.value)
+				continue
+			}
+			p.position++
+			segment := p.peek()
+			if p.isDone() || segment.kind != conditionTokenString {
+				return nil, fmt.Errorf("expected a string after '[' at position %v", token.position)
+			}
+			p.position++
+			if err := p.expectOperator("]"); err != nil {
+				return nil, err
+			}
+			path = append(path, segment.value)
+		}
+		return conditionPathNode{path: path}, nil
+	case conditionTokenOperator:
+		if token.value == "(" {
+			p.position++
+			node, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectOperator(")"); err != nil {
+				return nil, err
+			}
+			return node, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected '%s' at position %v", token.value, token.position)
+}
+
+type conditionNode interface {
+	evaluate(data map[string]any) (any, error)
+	collectReferences(references *[][]string)
+}
+
+type conditionLiteralNode struct {
+	value any
+}
+
+func (n conditionLiteralNode) evaluate(map[string]any) (any, error) {
+	return n.value, nil
+}
+
+func (n conditionLiteralNode) collectReferences(*[][]string) {}
+
+type conditionPathNode struct {
+	path []string
+}
+
+func (n conditionPathNode) evaluate(data map[string]any) (any, error) {
+	var current any = data
+	for i, segment := range n.path {
+		currentMap, ok := current.(map[string]any)
+		if !ok {
+			if current == nil {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("'%s' is not an object", strings.Join(n.path[:i], "."))
+		}
+		value, exists := currentMap[segment]
+		if !exists {
+			return nil, fmt.Errorf("'%s' is not defined", strings.Join(n.path[:i+1], "."))
+		}
+		current = value
+	}
+	return normaliseConditionValue(current), nil
+}
+
+func (n conditionPathNode) collectReferences(references *[][]string) {
+	*references = append(*references, n.path)
+}
+
+type conditionNotNode struct {
+	operand conditionNode
+}
+
+func (n conditionNotNode) evaluate(data map[string]any) (any, error) {
+	value, err := n.operand.evaluate(data)
+	if err != nil {
+		return nil, err
+	}
+	boolValue, ok := value.(bool)
+	if !ok {
+		return nil, fmt.Errorf("'!' requires a boolean but got %v", value)
+	}
+	return !boolValue, nil
+}
+
+func (n conditionNotNode) collectReferences(references *[][]string) {
+	n.operand.collectReferences(references)
+}
+
+type conditionLogicalNode struct {
+	operator string
+	left     conditionNode
+	right    conditionNode
+}
+
+func (n conditionLogicalNode) evaluate(data map[string]any) (any, error) {
+	left, err := n.left.evaluate(data)
+	if err != nil {
+		return nil, err
+	}
+	leftBool, ok := left.(bool)
+	if !ok {
+		return nil, fmt.Errorf("'%s' requires booleans but got %v", n.operator, left)
+	}
+	if (n.operator == "&&" && !leftBool) || (n.operator == "||" && leftBool) {
+		return leftBool, nil
+	}
+	right, err := n.right.evaluate(data)
+	if err != nil {
+		return nil, err
+	}
+	rightBool, ok := right.(bool)
+	if !ok {
+		return nil, fmt.Errorf("'%s' requires booleans but got %v", n.operator, right)
+	}
+	return rightBool, nil
+}
+
+func (n conditionLogicalNode) collectReferences(references *[][]string) {
+	n.left.collectReferences(references)
+	n.right.collectReferences(references)
+}
+
+type conditionComparisonNode struct {
+	operator string
+	left     conditionNode
+	right    conditionNode
+}
+
+func (n conditionComparisonNode) evaluate(data map[string]any) (any, error) {
+	left, err := n.left.evaluate(data)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.evaluate(data)
+	if err != nil {
+		return nil, err
+	}
+	for _, value := range []any{left, right} {
+		if _, isObject := value.(map[string]any); isObject {
+			return nil, fmt.Errorf("'%s' cannot compare objects", n.operator)
+		}
+	}
+	switch n.operator {
+	case "==":
+		return left == right, nil
+	case "!=":
+		return left != right, nil
+	}
+	if leftNumber, ok := left.(float64); ok {
+		if rightNumber, ok := right.(float64); ok {
+			switch n.operator {
+			case "<":
+				return leftNumber < rightNumber, nil
+			case "<=":
+				return leftNumber <= rightNumber, nil
+			case ">":
+				return leftNumber > rightNumber, nil
+			default:
+				return leftNumber >= rightNumber, nil
+			}
+		}
+	}
+	if leftString, ok := left.(string); ok {
+		if rightString, ok := right.(string); ok {
+			switch n.operator {
+			case "<":
+				return leftString < rightString, nil
+			case "<=":
+				return leftString <= rightString, nil
+			case ">":
+				return leftString > rightString, nil
+			default:
+				return leftString >= rightString, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("'%s' cannot compare %v and %v", n.operator, left, right)
+}
+
+func (n conditionComparisonNode) collectReferences(references *[][]string) {
+	n.left.collectReferences(references)
+	n.right.collectReferences(references)
+}
+
+// GetConditionData returns the data that `when` conditions are
+// evaluated against, `vars` is the mapping of variable ID to value and
+// `results` is the mapping of phase name to the result of the phase
+func GetConditionData(vars map[string]any, results map[string]PhaseResult) map[string]any {
+	phases := make(map[string]any, len(results))
+	for name, result := range results {
+		phases[name] = map[string]any{
+			ConditionPhaseFieldAttempts: result.Attempts,
+			ConditionPhaseFieldExitCode: result.ExitCode,
+			ConditionPhaseFieldStatus:   result.Status,
+		}
+	}
+	if vars == nil {
+		vars = map[string]any{}
+	}
+	return map[string]any{
+		ConditionRootPhases: phases,
+		ConditionRootVars:   vars,
+	}
+}
+
+// normaliseConditionValue converts numbers to float64 so that values
+// of different numeric types can be compared
+func normaliseConditionValue(value any) any {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case *int:
+		if v == nil {
+			return nil
+		}
+		return float64(*v)
+	case PhaseStatusCode:
+		return string(v)
+	}
+	return value
+}
@@ -0,0 +1,62 @@
+package automations
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestConditionEvaluate(t *testing.T) {
+	exitCode := 0
+	data := GetConditionData(
+		map[string]any{"environment": "prod", "dry-run": false, "replicas": int64(3)},
+		map[string]PhaseResult{"precheck": {Name: "precheck", Status: PhaseStatusSucceeded, ExitCode: &exitCode}},
+	)
+	cases := map[string]bool{
+		`vars.environment == "prod" && phases.precheck.exitCode == 0`:            true,
+		`vars["dry-run"] || vars.replicas >= 5`:                                  false,
+		`!(vars.environment != 'prod') && phases.precheck.status == "succeeded"`: true,
+		`vars.replicas > 2.5 && vars.replicas < 4`:                               true,
+	}
+	for expression, expected := range cases {
+		condition, err := ParseCondition(expression)
+		if err != nil {
+			t.Fatalf("failed to parse '%s': %s", expression, err)
+		}
+		observed, err := condition.Evaluate(data)
+		if err != nil {
+			t.Fatalf("failed to evaluate '%s': %s", expression, err)
+		}
+		if observed != expected {
+			t.Errorf("expected '%s' to be %v", expression, expected)
+		}
+	}
+
+	for _, expression := range []string{`vars.environment ==`, `(vars.a`, `vars.a = 1`, `"unterminated`} {
+		if _, err := ParseCondition(expression); !errors.Is(err, ErrorConditionInvalid) {
+			t.Errorf("expected '%s' to be invalid, got %v", expression, err)
+		}
+	}
+	for _, expression := range []string{`vars.missing == 1`, `vars.environment`, `vars.environment < 1`} {
+		condition, _ := ParseCondition(expression)
+		if _, err := condition.Evaluate(data); !errors.Is(err, ErrorConditionFailed) {
+			t.Errorf("expected '%s' to fail evaluation, got %v", expression, err)
+		}
+	}
+}
+
+func TestPhaseConditionsValidate(t *testing.T) {
+	spec := AutomationSpec{Phases: []Phase{
+		{Name: "precheck", Image: "alpine"},
+		{Name: "apply", Image: "alpine", When: `phases.precheck.exitCode == 0 && phases.cleanup.status == "succeeded"`},
+		{Name: "cleanup", Image: "alpine", When: `phases.precheck.output == 1`},
+	}}
+	err := spec.Validate()
+	if !errors.Is(err, ErrorConditionInvalid) {
+		t.Fatalf("expected invalid condition error, got %v", err)
+	}
+	spec.Phases[1].When = `phases.precheck.exitCode == 0`
+	spec.Phases[2].When = `phases.apply.status == "skipped"`
+	if err := spec.Validate(); err != nil {
+		t.Fatalf("expected valid spec, got %v", err)
+	}
+}
@@ -0,0 +1,29 @@
+package automations
+
+import "errors"
+
+var (
+	ErrorApprovalPolicyInvalid = errors.New("approval_policy_invalid")
+
+	ErrorConcurrencyInvalid = errors.New("concurrency_invalid")
+
+	ErrorConditionFailed  = errors.New("condition_failed")
+	ErrorConditionInvalid = errors.New("condition_invalid")
+
+	ErrorPhaseDependencyCycle   = errors.New("phase_dependency_cycle")
+	ErrorPhaseDependencyUnknown = errors.New("phase_dependency_unknown")
+	ErrorPhaseImageRequired     = errors.New("phase_image_required")
+	ErrorPhaseInvalid           = errors.New("phase_invalid")
+	ErrorPhaseNameDuplicated    = errors.New("phase_name_duplicated")
+	ErrorPhaseNameRequired      = errors.New("phase_name_required")
+
+	ErrorPayloadExpressionInvalid = errors.New("payload_expression_invalid")
+
+	ErrorResumePhaseInvalid = errors.New("resume_phase_invalid")
+
+	ErrorScheduleInvalid = errors.New("schedule_invalid")
+
+	ErrorVariableIdDuplicated = errors.New("variable_id_duplicated")
+	ErrorVariableIdRequired   = errors.New("variable_id_required")
+	ErrorVariableInvalid      = errors.New("variable_invalid")
+)
@@ -0,0 +1,181 @@
+package automations
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DefaultMaxParallelPhases is the number of phases which can run at the
+// same time when the automation does not define `maxParallelPhases`
+const DefaultMaxParallelPhases = 4
+
+// GetMaxParallelPhases returns the maximum number of phases which can
+// run at the same time
+func (s AutomationSpec) GetMaxParallelPhases() int {
+	if s.MaxParallelPhases <= 0 {
+		return DefaultMaxParallelPhases
+	}
+	return s.MaxParallelPhases
+}
+
+// HasPhaseDependencies returns true if any phase declares `dependsOn`
+func (s AutomationSpec) HasPhaseDependencies() bool {
+	for _, phase := range s.Phases {
+		if len(phase.DependsOn) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// GetPhaseDependencies returns the names of the phases that each phase
+// depends on. When no phase declares `dependsOn`, every phase depends on
+// the phase defined before it so that phases run in the order they are
+// defined; otherwise phases without `dependsOn` can start immediately
+func (s AutomationSpec) GetPhaseDependencies() map[string][]string {
+	dependencies := make(map[string][]string, len(s.Phases))
+	isOrderedByDefinition := !s.HasPhaseDependencies()
+	for i, phase := range s.Phases {
+		if isOrderedByDefinition {
+			if i > 0 {
+				dependencies[phase.Name] = []string{s.Phases[i-1].Name}
+			} else {
+				dependencies[phase.Name] = []string{}
+			}
+			continue
+		}
+		dependencies[phase.Name] = append([]string{}, phase.DependsOn...)
+	}
+	return dependencies
+}
+
+// GetPhaseDependents returns the names of the phases that depend on
+// each phase in the order that they are defined
+func (s AutomationSpec) GetPhaseDependents() map[string][]string {
+	dependents := make(map[string][]string, len(s.Phases))
+	dependencies := s.GetPhaseDependencies()
+	for _, phase := range s.Phases {
+		for _, dependency := range dependencies[phase.Name] {
+			dependents[dependency] = append(dependents[dependency], phase.Name)
+		}
+	}
+	return dependents
+}
+
+// GetPhaseAncestors returns the names of the phases which complete
+// before the phase identified by `phaseName` starts
+func (s AutomationSpec) GetPhaseAncestors(phaseName string) map[string]struct{} {
+	dependencies := s.GetPhaseDependencies()
+	ancestors := map[string]struct{}{}
+	pending := append([]string{}, dependencies[phaseName]...)
+	for len(pending) > 0 {
+		ancestor := pending[0]
+		pending = pending[1:]
+		if _, exists := ancestors[ancestor]; exists {
+			continue
+		}
+		ancestors[ancestor] = struct{}{}
+		pending = append(pending, dependencies[ancestor]...)
+	}
+	return ancestors
+}
+
+// validatePhaseDependencies checks that dependencies refer to defined
+// phases and that they do not form a cycle
+func (s AutomationSpec) validatePhaseDependencies(phaseNames map[string]struct{}) error {
+	errs := []error{}
+	for i, phase := range s.Phases {
+		for _, dependency := range phase.DependsOn {
+			if dependency == phase.Name {
+				errs = append(errs, fmt.Errorf("phases[%v]: %w: %s depends on itself", i, ErrorPhaseDependencyCycle, phase.Name))
+			} else if _, exists := phaseNames[dependency]; !exists {
+				errs = append(errs, fmt.Errorf("phases[%v]: %w: %s", i, ErrorPhaseDependencyUnknown, dependency))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	if cycle := s.findPhaseCycle(); cycle != nil {
+		return fmt.Errorf("%w: %s", ErrorPhaseDependencyCycle, strings.Join(cycle, " -> "))
+	}
+	return nil
+}
+
+// findPhaseCycle returns the names of the phases forming a dependency
+// cycle with the first phase repeated at the end, nil is returned if
+// there are no cycles
+func (s AutomationSpec) findPhaseCycle() []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	dependencies := s.GetPhaseDependencies()
+	states := map[string]int{}
+	path := []string{}
+	var visit func(phaseName string) []string
+	visit = func(phaseName string) []string {
+		switch states[phaseName] {
+		case visited:
+			return nil
+		case visiting:
+			for i, pathPhaseName := range path {
+				if pathPhaseName == phaseName {
+					return append(append([]string{}, path[i:]...), phaseName)
+				}
+			}
+		}
+		states[phaseName] = visiting
+		path = append(path, phaseName)
+		for _, dependency := range dependencies[phaseName] {
+			if cycle := visit(dependency); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		states[phaseName] = visited
+		return nil
+	}
+	for _, phase := range s.Phases {
+		if cycle := visit(phase.Name); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// RenderPhaseGraph returns an ASCII tree of the phases where each phase
+// is listed under the phases it depends on; phases with more than one
+// dependency are listed in full only under the first one and are marked
+// with `(*)` elsewhere
+func (s AutomationSpec) RenderPhaseGraph() string {
+	dependencies := s.GetPhaseDependencies()
+	dependents := s.GetPhaseDependents()
+	isRendered := map[string]bool{}
+	var output strings.Builder
+	var render func(phaseName, prefix, connector, childPrefix string)
+	render = func(phaseName, prefix, connector, childPrefix string) {
+		if isRendered[phaseName] {
+			output.WriteString(fmt.Sprintf("%s%s%s (*)\n", prefix, connector, phaseName))
+			return
+		}
+		isRendered[phaseName] = true
+		output.WriteString(fmt.Sprintf("%s%s%s\n", prefix, connector, phaseName))
+		children := dependents[phaseName]
+		for i, child := range children {
+			if i == len(children)-1 {
+				render(child, prefix+childPrefix, "`-- ", "    ")
+			} else {
+				render(child, prefix+childPrefix, "|-- ", "|   ")
+			}
+		}
+	}
+	for _, phase := range s.Phases {
+		if len(dependencies[phase.Name]) == 0 {
+			render(phase.Name, "", "", "")
+		}
+	}
+	return output.String()
+}
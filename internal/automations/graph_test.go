@@ -0,0 +1,36 @@
+package automations
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPhaseDependencies(t *testing.T) {
+	sequential := AutomationSpec{Phases: []Phase{{Name: "a"}, {Name: "b"}}}
+	if dependencies := sequential.GetPhaseDependencies(); len(dependencies["a"]) != 0 || dependencies["b"][0] != "a" {
+		t.Fatalf("expected phases without dependsOn to run in order, got %v", dependencies)
+	}
+
+	fanOut := AutomationSpec{Phases: []Phase{
+		{Name: "prepare", Image: "alpine"},
+		{Name: "check-1", Image: "alpine", DependsOn: []string{"prepare"}},
+		{Name: "check-2", Image: "alpine", DependsOn: []string{"prepare"}},
+		{Name: "report", Image: "alpine", DependsOn: []string{"check-1", "check-2"}},
+	}}
+	if err := fanOut.Validate(); err != nil {
+		t.Fatalf("expected valid spec, got %v", err)
+	}
+	expectedGraph := "prepare\n|-- check-1\n|   `-- report\n`-- check-2\n    `-- report (*)\n"
+	if graph := fanOut.RenderPhaseGraph(); graph != expectedGraph {
+		t.Fatalf("expected graph:\n%s\ngot:\n%s", expectedGraph, graph)
+	}
+
+	fanOut.Phases[0].DependsOn = []string{"report"}
+	if err := fanOut.Validate(); !errors.Is(err, ErrorPhaseDependencyCycle) {
+		t.Fatalf("expected cycle error, got %v", err)
+	}
+	fanOut.Phases[0].DependsOn = []string{"missing"}
+	if err := fanOut.Validate(); !errors.Is(err, ErrorPhaseDependencyUnknown) {
+		t.Fatalf("expected unknown dependency error, got %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package automations
+
+import (
+	"strings"
+	"time"
+)
+
+// StderrPrefix is prepended to every line of an automation's logs that
+// originated from stderr
+const StderrPrefix = "[stderr] "
+
+// LogChunk is a batch of logs from an automation run as published to
+// the logs queue
+type LogChunk struct {
+	AutomationId string    `json:"automationId"`
+	OrgId        *string   `json:"orgId,omitempty"`
+	WorkerId     string    `json:"workerId,omitempty"`
+	Data         string    `json:"data"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+// PrefixStderr prepends the StderrPrefix to every line in `text`
+func PrefixStderr(text string) string {
+	return prefixLines(text, StderrPrefix)
+}
+
+// PrefixPhase prepends the name of the phase in brackets to every line
+// in `text` so that logs of phases which run in parallel can be told
+// apart
+func PrefixPhase(text string, phaseName string) string {
+	return prefixLines(text, "["+phaseName+"] ")
+}
+
+func prefixLines(text string, prefix string) string {
+	lines := strings.SplitAfter(text, "\n")
+	var output strings.Builder
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		output.WriteString(prefix)
+		output.WriteString(line)
+	}
+	return output.String()
+}
+
+// IsStderrLine returns true if the provided log line originated from
+// stderr and the line with the StderrPrefix removed
+func IsStderrLine(line string) (bool, string) {
+	if strings.HasPrefix(line, StderrPrefix) {
+		return true, strings.TrimPrefix(line, StderrPrefix)
+	}
+	return false, line
+}
@@ -0,0 +1,24 @@
+package automations
+
+import "testing"
+
+func TestPrefixStderr(t *testing.T) {
+	prefixed := PrefixStderr("first\nsecond\n")
+	if prefixed != "[stderr] first\n[stderr] second\n" {
+		t.Fatalf("expected every line to be prefixed, got %q", prefixed)
+	}
+	isStderr, line := IsStderrLine("[stderr] first\n")
+	if !isStderr || line != "first\n" {
+		t.Fatalf("expected stderr line 'first', got %v/%q", isStderr, line)
+	}
+	if isStderr, _ := IsStderrLine("stdout line\n"); isStderr {
+		t.Fatalf("expected stdout line to not be detected as stderr")
+	}
+}
+
+func TestPrefixPhase(t *testing.T) {
+	prefixed := PrefixPhase("first\nsecond", "build")
+	if prefixed != "[build] first\n[build] second" {
+		t.Fatalf("expected every line to be prefixed, got %q", prefixed)
+	}
+}
@@ -0,0 +1,157 @@
+package automations
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// PayloadMapping maps variable IDs to expressions which are evaluated
+// against the payload of an inbound webhook to produce the values of the
+// variables; expressions starting with `$` are JSONPath expressions (eg.
+// `$.alerts[0].labels.instance`), expressions containing `{{` are Go
+// templates where the payload is accessible as `{{ .body }}` and all
+// other expressions are used as-is
+type PayloadMapping map[string]string
+
+// Resolve returns the values of the mapped variables, variables whose
+// JSONPath expressions do not match anything in the payload are omitted
+// so that their defaults apply
+func (pm PayloadMapping) Resolve(payload any) (map[string]any, error) {
+	output := map[string]any{}
+	for variableId, expression := range pm {
+		value, err := EvaluatePayloadExpression(expression, payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate mapping of var[%s]: %w", variableId, err)
+		}
+		if value != nil {
+			output[variableId] = value
+		}
+	}
+	return output, nil
+}
+
+// Validate returns an error if any of the expressions cannot be parsed
+func (pm PayloadMapping) Validate() error {
+	for variableId, expression := range pm {
+		if err := ValidatePayloadExpression(expression); err != nil {
+			return fmt.Errorf("invalid mapping of var[%s]: %w", variableId, err)
+		}
+	}
+	return nil
+}
+
+// ValidatePayloadExpression returns an error if the expression cannot be
+// parsed, see PayloadMapping for the supported expressions
+func ValidatePayloadExpression(expression string) error {
+	expression = strings.TrimSpace(expression)
+	if strings.HasPrefix(expression, "$") {
+		_, err := parseJsonPath(expression)
+		return err
+	}
+	if strings.Contains(expression, "{{") {
+		if _, err := template.New("mapping").Parse(expression); err != nil {
+			return fmt.Errorf("%w: %w", ErrorPayloadExpressionInvalid, err)
+		}
+	}
+	return nil
+}
+
+// EvaluatePayloadExpression evaluates the expression against the
+// payload, see PayloadMapping for the supported expressions
+func EvaluatePayloadExpression(expression string, payload any) (any, error) {
+	expression = strings.TrimSpace(expression)
+	if strings.HasPrefix(expression, "$") {
+		path, err := parseJsonPath(expression)
+		if err != nil {
+			return nil, err
+		}
+		return path.get(payload), nil
+	}
+	if strings.Contains(expression, "{{") {
+		tmpl, err := template.New("mapping").Option("missingkey=error").Parse(expression)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrorPayloadExpressionInvalid, err)
+		}
+		var output strings.Builder
+		if err := tmpl.Execute(&output, map[string]any{"body": payload}); err != nil {
+			return nil, fmt.Errorf("failed to render expression: %w", err)
+		}
+		return output.String(), nil
+	}
+	return expression, nil
+}
+
+// jsonPath is a parsed JSONPath expression, each segment is either a
+// string (object key) or an int (array index)
+type jsonPath []any
+
+// parseJsonPath parses the subset of JSONPath consisting of the root
+// `$`, dot-notation keys (`.key`), bracket-notation keys (`['key']`)
+// and array indices (`[0]`)
+func parseJsonPath(expression string) (jsonPath, error) {
+	if !strings.HasPrefix(expression, "$") {
+		return nil, fmt.Errorf("%w: jsonpath[%s] must start with '$'", ErrorPayloadExpressionInvalid, expression)
+	}
+	path := jsonPath{}
+	remaining := expression[1:]
+	for len(remaining) > 0 {
+		switch remaining[0] {
+		case '.':
+			remaining = remaining[1:]
+			end := strings.IndexAny(remaining, ".[")
+			if end == -1 {
+				end = len(remaining)
+			}
+			key := remaining[:end]
+			if key == "" {
+				return nil, fmt.Errorf("%w: jsonpath[%s] has an empty key", ErrorPayloadExpressionInvalid, expression)
+			}
+			path = append(path, key)
+			remaining = remaining[end:]
+		case '[':
+			end := strings.Index(remaining, "]")
+			if end == -1 {
+				return nil, fmt.Errorf("%w: jsonpath[%s] has an unclosed '['", ErrorPayloadExpressionInvalid, expression)
+			}
+			segment := remaining[1:end]
+			remaining = remaining[end+1:]
+			if len(segment) >= 2 && (segment[0] == '\'' || segment[0] == '"') && segment[len(segment)-1] == segment[0] {
+				path = append(path, segment[1:len(segment)-1])
+				continue
+			}
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("%w: jsonpath[%s] has an invalid index[%s]", ErrorPayloadExpressionInvalid, expression, segment)
+			}
+			path = append(path, index)
+		default:
+			return nil, fmt.Errorf("%w: jsonpath[%s] has an unexpected character '%c'", ErrorPayloadExpressionInvalid, expression, remaining[0])
+		}
+	}
+	return path, nil
+}
+
+// get returns the value at the path in `data` or nil if it does not
+// exist
+func (p jsonPath) get(data any) any {
+	current := data
+	for _, segment := range p {
+		switch key := segment.(type) {
+		case string:
+			object, ok := current.(map[string]any)
+			if !ok {
+				return nil
+			}
+			current = object[key]
+		case int:
+			array, ok := current.([]any)
+			if !ok || key >= len(array) {
+				return nil
+			}
+			current = array[key]
+		}
+	}
+	return current
+}
@@ -0,0 +1,50 @@
+package automations
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestPayloadMappingResolve(t *testing.T) {
+	var payload any
+	if err := json.Unmarshal([]byte(`{
+		"status": "firing",
+		"groupKey": "{}:{alertname=\"CertExpiry\"}",
+		"alerts": [{"labels": {"instance": "db-1", "alert.name": "CertExpiry"}, "value": 3}]
+	}`), &payload); err != nil {
+		t.Fatalf("failed to parse payload: %s", err)
+	}
+	values, err := PayloadMapping{
+		"instance": "$.alerts[0].labels.instance",
+		"alert":    "$.alerts[0].labels['alert.name']",
+		"value":    "$.alerts[0].value",
+		"summary":  "{{ .body.status }}: {{ (index .body.alerts 0).labels.instance }}",
+		"source":   "alertmanager",
+		"missing":  "$.alerts[3].labels.instance",
+	}.Resolve(payload)
+	if err != nil {
+		t.Fatalf("expected mapping to resolve, got %s", err)
+	}
+	expected := map[string]any{
+		"instance": "db-1",
+		"alert":    "CertExpiry",
+		"value":    float64(3),
+		"summary":  "firing: db-1",
+		"source":   "alertmanager",
+	}
+	if len(values) != len(expected) {
+		t.Fatalf("expected %v values, got %v", len(expected), values)
+	}
+	for id, value := range expected {
+		if values[id] != value {
+			t.Fatalf("expected var[%s] to be %v, got %v", id, value, values[id])
+		}
+	}
+
+	for _, expression := range []string{"$.", "$[0", "$.a[x]", "$a", "{{ .body"} {
+		if err := ValidatePayloadExpression(expression); !errors.Is(err, ErrorPayloadExpressionInvalid) {
+			t.Fatalf("expected expression[%s] to be invalid, got %v", expression, err)
+		}
+	}
+}
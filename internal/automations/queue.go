@@ -0,0 +1,84 @@
+package automations
+
+import "opsicle/internal/queue"
+
+const (
+	// QueueStream is the stream which automation runs are pushed into
+	QueueStream = "automations"
+
+	// QueueSubjectRuns is the subject prefix which automation runs are
+	// pushed to, org-scoped runs are pushed to `runs.<orgId>`
+	QueueSubjectRuns = "runs"
+)
+
+// GetRunsQueue returns the queue which automation runs belonging to the
+// org identified by `orgId` should be pushed to/popped from; when `orgId`
+// is nil, the un-scoped runs queue is returned
+func GetRunsQueue(orgId *string) queue.QueueOpts {
+	subject := QueueSubjectRuns
+	if orgId != nil && *orgId != "" {
+		subject = QueueSubjectRuns + "." + *orgId
+	}
+	return queue.QueueOpts{
+		Stream:  QueueStream,
+		Subject: subject,
+	}
+}
+
+const (
+	// LogsQueueStream is the stream which logs of automation runs are
+	// published to, each run publishes to `automation_logs.<automationId>`
+	LogsQueueStream = "automation_logs"
+)
+
+// GetLogsQueue returns the queue which logs of the automation run
+// identified by `automationId` are published to
+func GetLogsQueue(automationId string) queue.QueueOpts {
+	return queue.QueueOpts{
+		Stream:  LogsQueueStream,
+		Subject: automationId,
+	}
+}
+
+// GetAllLogsQueue returns the queue which captures logs of all
+// automation runs
+func GetAllLogsQueue() queue.QueueOpts {
+	return queue.QueueOpts{
+		Stream:  LogsQueueStream,
+		Subject: "*",
+	}
+}
+
+// GetLogsStreamOpts returns the stream options that should be used when
+// pushing to or subscribing from the logs queues so that the stream
+// captures logs of all runs using a single wildcard subject
+func GetLogsStreamOpts() *queue.StreamOpts {
+	return &queue.StreamOpts{
+		Subjects: []string{"*"},
+	}
+}
+
+const (
+	// CancellationsQueueStream is the stream which cancellations of
+	// automation runs are published to, each run's cancellation is
+	// published to `automation_cancellations.<automationId>`
+	CancellationsQueueStream = "automation_cancellations"
+)
+
+// GetCancellationsQueue returns the queue which the cancellation of the
+// automation run identified by `automationId` is published to
+func GetCancellationsQueue(automationId string) queue.QueueOpts {
+	return queue.QueueOpts{
+		Stream:  CancellationsQueueStream,
+		Subject: automationId,
+	}
+}
+
+// GetCancellationsStreamOpts returns the stream options that should be
+// used when pushing cancellations so that the stream captures
+// cancellations of all runs using a single wildcard subject
+func GetCancellationsStreamOpts() *queue.StreamOpts {
+	return &queue.StreamOpts{
+		Subjects: []string{"*"},
+	}
+}
@@ -0,0 +1,38 @@
+package automations
+
+import (
+	"sort"
+	"strings"
+)
+
+// RedactSecrets replaces all occurrences of the provided `secrets` in
+// `text` with RedactedValue; multi-line secrets are additionally
+// redacted line-by-line so that they are caught in line-based output
+func RedactSecrets(text string, secrets []string) string {
+	if len(secrets) == 0 || text == "" {
+		return text
+	}
+	redactables := []string{}
+	for _, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+		redactables = append(redactables, secret)
+		if strings.Contains(secret, "\n") {
+			for _, line := range strings.Split(secret, "\n") {
+				if strings.TrimSpace(line) != "" {
+					redactables = append(redactables, line)
+				}
+			}
+		}
+	}
+	// longest first so that secrets which contain other secrets are
+	// fully redacted
+	sort.Slice(redactables, func(i, j int) bool {
+		return len(redactables[i]) > len(redactables[j])
+	})
+	for _, redactable := range redactables {
+		text = strings.ReplaceAll(text, redactable, RedactedValue)
+	}
+	return text
+}
@@ -0,0 +1,46 @@
+package automations
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Render returns a copy of the phase with Go template expressions in
+// `.Image` and `.Commands` evaluated; variables are accessible using
+// `{{ .vars.<id> }}`
+func (p Phase) Render(vars map[string]any) (Phase, error) {
+	data := map[string]any{
+		"vars": vars,
+	}
+	rendered := p
+	image, err := renderString(fmt.Sprintf("%s.image", p.Name), p.Image, data)
+	if err != nil {
+		return p, err
+	}
+	rendered.Image = image
+	rendered.Commands = make([]string, len(p.Commands))
+	for i, command := range p.Commands {
+		renderedCommand, err := renderString(fmt.Sprintf("%s.commands[%v]", p.Name, i), command, data)
+		if err != nil {
+			return p, err
+		}
+		rendered.Commands[i] = renderedCommand
+	}
+	return rendered, nil
+}
+
+func renderString(name, text string, data map[string]any) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template of %s: %w", name, err)
+	}
+	var output strings.Builder
+	if err := tmpl.Execute(&output, data); err != nil {
+		return "", fmt.Errorf("failed to render template of %s: %w", name, err)
+	}
+	return output.String(), nil
+}
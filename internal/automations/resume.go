@@ -0,0 +1,50 @@
+package automations
+
+import (
+	"fmt"
+	"slices"
+)
+
+// ResumeSpec is defined on automation runs which are re-runs of a
+// previous run starting from one of its phases, the phases which
+// complete before `.FromPhase` are not run again
+type ResumeSpec struct {
+	// ParentAutomationId is the ID of the run being re-run, workers
+	// which still have its workspace reuse it
+	ParentAutomationId string `json:"parentAutomationId"`
+
+	// FromPhase is the name of the phase to resume from
+	FromPhase string `json:"fromPhase"`
+
+	// PhaseResults are the results of the parent run's phases which
+	// complete before `.FromPhase`, these are reported as the results
+	// of the phases instead of running them
+	PhaseResults []PhaseResult `json:"phaseResults"`
+}
+
+// GetResumedPhaseResults returns the results in `runStatus` of the
+// phases which are not run again when resuming from the phase
+// identified by `fromPhase`; an error is returned if the phase does not
+// exist or if any of those phases neither succeeded nor were skipped
+func (s AutomationSpec) GetResumedPhaseResults(fromPhase string, runStatus RunStatus) ([]PhaseResult, error) {
+	if !slices.ContainsFunc(s.Phases, func(phase Phase) bool { return phase.Name == fromPhase }) {
+		return nil, fmt.Errorf("%w: phase[%s] does not exist", ErrorResumePhaseInvalid, fromPhase)
+	}
+	ancestors := s.GetPhaseAncestors(fromPhase)
+	results := []PhaseResult{}
+	for _, phase := range s.Phases {
+		if _, isAncestor := ancestors[phase.Name]; !isAncestor {
+			continue
+		}
+		resultIndex := slices.IndexFunc(runStatus.Phases, func(result PhaseResult) bool { return result.Name == phase.Name })
+		if resultIndex < 0 {
+			return nil, fmt.Errorf("%w: phase[%s] did not run", ErrorResumePhaseInvalid, phase.Name)
+		}
+		result := runStatus.Phases[resultIndex]
+		if result.Status != PhaseStatusSucceeded && result.Status != PhaseStatusSkipped {
+			return nil, fmt.Errorf("%w: phase[%s] is in status[%s]", ErrorResumePhaseInvalid, phase.Name, result.Status)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,35 @@
+package automations
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGetResumedPhaseResults(t *testing.T) {
+	spec := AutomationSpec{Phases: []Phase{
+		{Name: "prepare", Image: "alpine"},
+		{Name: "check", Image: "alpine", DependsOn: []string{"prepare"}},
+		{Name: "lint", Image: "alpine", DependsOn: []string{"prepare"}},
+		{Name: "deploy", Image: "alpine", DependsOn: []string{"check"}},
+	}}
+	runStatus := RunStatus{Phases: []PhaseResult{
+		{Name: "prepare", Status: PhaseStatusSucceeded},
+		{Name: "check", Status: PhaseStatusSkipped},
+		{Name: "lint", Status: PhaseStatusFailed},
+		{Name: "deploy", Status: PhaseStatusFailed},
+	}}
+	results, err := spec.GetResumedPhaseResults("deploy", runStatus)
+	if err != nil {
+		t.Fatalf("expected results, got %v", err)
+	}
+	if len(results) != 2 || results[0].Name != "prepare" || results[1].Name != "check" {
+		t.Fatalf("expected results of prepare and check only, got %v", results)
+	}
+	if _, err := spec.GetResumedPhaseResults("missing", runStatus); !errors.Is(err, ErrorResumePhaseInvalid) {
+		t.Fatalf("expected invalid resume phase error for unknown phase, got %v", err)
+	}
+	runStatus.Phases[0].Status = PhaseStatusFailed
+	if _, err := spec.GetResumedPhaseResults("deploy", runStatus); !errors.Is(err, ErrorResumePhaseInvalid) {
+		t.Fatalf("expected invalid resume phase error for failed ancestor, got %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package automations
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// DefaultScheduleTimezone is the timezone cron expressions of schedules
+// are evaluated in when none is specified
+const DefaultScheduleTimezone = "UTC"
+
+// Schedule is a parsed cron expression evaluated in a timezone
+type Schedule struct {
+	Expression string
+	Timezone   string
+
+	location *time.Location
+	schedule cron.Schedule
+}
+
+// ParseSchedule parses a standard 5-field cron expression (descriptors
+// such as `@daily` are also accepted) which is evaluated in `timezone`,
+// the timezone is an IANA name such as `Asia/Singapore`
+func ParseSchedule(expression, timezone string) (*Schedule, error) {
+	expression = strings.TrimSpace(expression)
+	if expression == "" {
+		return nil, fmt.Errorf("%w: cron expression must not be empty", ErrorScheduleInvalid)
+	}
+	if strings.HasPrefix(expression, "TZ=") || strings.HasPrefix(expression, "CRON_TZ=") {
+		return nil, fmt.Errorf("%w: specify the timezone separately instead of in the cron expression", ErrorScheduleInvalid)
+	}
+	if timezone == "" {
+		timezone = DefaultScheduleTimezone
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid timezone[%s]: %w", ErrorScheduleInvalid, timezone, err)
+	}
+	schedule, err := cron.ParseStandard(expression)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid cron expression[%s]: %w", ErrorScheduleInvalid, expression, err)
+	}
+	return &Schedule{
+		Expression: expression,
+		Timezone:   timezone,
+		location:   location,
+		schedule:   schedule,
+	}, nil
+}
+
+// GetNext returns the first time after `after` that the schedule fires,
+// the returned time is in UTC
+func (s Schedule) GetNext(after time.Time) time.Time {
+	return s.schedule.Next(after.In(s.location)).UTC()
+}
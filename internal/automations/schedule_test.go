@@ -0,0 +1,38 @@
+package automations
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	schedule, err := ParseSchedule("0 2 * * *", "Asia/Singapore")
+	if err != nil {
+		t.Fatalf("expected schedule to parse, got %s", err)
+	}
+	after := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	expected := time.Date(2026, 1, 1, 18, 0, 0, 0, time.UTC)
+	if next := schedule.GetNext(after); !next.Equal(expected) {
+		t.Fatalf("expected next run at %s, got %s", expected, next)
+	}
+
+	schedule, err = ParseSchedule("@daily", "")
+	if err != nil {
+		t.Fatalf("expected descriptor to parse, got %s", err)
+	}
+	if schedule.Timezone != DefaultScheduleTimezone {
+		t.Fatalf("expected default timezone, got %s", schedule.Timezone)
+	}
+
+	for _, input := range [][2]string{
+		{"", "UTC"},
+		{"* * *", "UTC"},
+		{"0 2 * * *", "Mars/Olympus_Mons"},
+		{"CRON_TZ=UTC 0 2 * * *", "UTC"},
+	} {
+		if _, err := ParseSchedule(input[0], input[1]); !errors.Is(err, ErrorScheduleInvalid) {
+			t.Fatalf("expected expression[%s] in timezone[%s] to be invalid, got %v", input[0], input[1], err)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package automations
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/docker/go-units"
+)
+
+const (
+	PhaseNetworkBridge = "bridge"
+	PhaseNetworkHost   = "host"
+	PhaseNetworkNone   = "none"
+)
+
+var PhaseNetworks = []string{
+	PhaseNetworkBridge,
+	PhaseNetworkHost,
+	PhaseNetworkNone,
+}
+
+// PhaseResources limits the compute resources available to a phase,
+// limits which are not defined are not applied
+type PhaseResources struct {
+	// Cpu is the number of CPUs which the phase can use, eg. "0.5"
+	Cpu string `json:"cpu,omitempty" yaml:"cpu,omitempty"`
+
+	// Memory is the maximum amount of memory which the phase can use,
+	// eg. "512m" or "1Gi"
+	Memory string `json:"memory,omitempty" yaml:"memory,omitempty"`
+
+	// Pids is the maximum number of processes which can run in the
+	// phase at the same time
+	Pids int64 `json:"pids,omitempty" yaml:"pids,omitempty"`
+}
+
+// MinCpu is the smallest cpu limit of a phase, smaller limits cannot be
+// represented by the kubernetes runtime and would round down to 0 which
+// runtimes treat as unlimited
+const MinCpu = 0.001
+
+// GetNanoCpus returns the cpu limit in units of 10^-9 CPUs, 0 is
+// returned when the limit is not defined
+func (r PhaseResources) GetNanoCpus() (int64, error) {
+	if r.Cpu == "" {
+		return 0, nil
+	}
+	cpus, err := strconv.ParseFloat(r.Cpu, 64)
+	if err != nil || math.IsNaN(cpus) || math.IsInf(cpus, 0) || cpus <= 0 {
+		return 0, fmt.Errorf("%w: cpu '%s' must be a positive number", ErrorPhaseInvalid, r.Cpu)
+	}
+	if cpus < MinCpu {
+		return 0, fmt.Errorf("%w: cpu '%s' must be at least %v", ErrorPhaseInvalid, r.Cpu, MinCpu)
+	}
+	return int64(cpus * 1e9), nil
+}
+
+// GetMemoryBytes returns the memory limit in bytes, 0 is returned
+// when the limit is not defined
+func (r PhaseResources) GetMemoryBytes() (int64, error) {
+	if r.Memory == "" {
+		return 0, nil
+	}
+	memory, err := units.RAMInBytes(r.Memory)
+	if err != nil || memory <= 0 {
+		return 0, fmt.Errorf("%w: memory '%s' must be a positive size such as '512m'", ErrorPhaseInvalid, r.Memory)
+	}
+	return memory, nil
+}
+
+// Validate returns an error describing all problems found with the
+// resources
+func (r PhaseResources) Validate() error {
+	if _, err := r.GetNanoCpus(); err != nil {
+		return err
+	}
+	if _, err := r.GetMemoryBytes(); err != nil {
+		return err
+	}
+	if r.Pids < 0 {
+		return fmt.Errorf("%w: pids cannot be negative", ErrorPhaseInvalid)
+	}
+	return nil
+}
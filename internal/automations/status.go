@@ -0,0 +1,176 @@
+package automations
+
+import "time"
+
+// RunStatusCode is the status of an automation run, these values
+// mirror the `automations.last_known_status` column in the controller's
+// database
+type RunStatusCode string
+
+const (
+	RunStatusCreated          RunStatusCode = "created"
+	RunStatusAccepted         RunStatusCode = "accepted"
+	RunStatusRejected         RunStatusCode = "rejected"
+	RunStatusPendingApproval  RunStatusCode = "pending-approval"
+	RunStatusPendingExecution RunStatusCode = "pending-execution"
+	RunStatusExecuting        RunStatusCode = "executing"
+	RunStatusCompletedSuccess RunStatusCode = "completed-success"
+	RunStatusCompletedFailed  RunStatusCode = "completed-failed"
+	RunStatusCancelled        RunStatusCode = "cancelled"
+)
+
+// RunStatusCodes are all statuses an automation run can be in
+var RunStatusCodes = []RunStatusCode{
+	RunStatusCreated,
+	RunStatusAccepted,
+	RunStatusRejected,
+	RunStatusPendingApproval,
+	RunStatusPendingExecution,
+	RunStatusExecuting,
+	RunStatusCompletedSuccess,
+	RunStatusCompletedFailed,
+	RunStatusCancelled,
+}
+
+// IsFinal returns true if the status is one that an automation run
+// does not transition out of
+func (s RunStatusCode) IsFinal() bool {
+	switch s {
+	case RunStatusRejected, RunStatusCompletedSuccess, RunStatusCompletedFailed, RunStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// PhaseStatusCode is the status of a single phase of an automation run
+type PhaseStatusCode string
+
+const (
+	PhaseStatusPending   PhaseStatusCode = "pending"
+	PhaseStatusRunning   PhaseStatusCode = "running"
+	PhaseStatusSucceeded PhaseStatusCode = "succeeded"
+	PhaseStatusFailed    PhaseStatusCode = "failed"
+	PhaseStatusSkipped   PhaseStatusCode = "skipped"
+	PhaseStatusCancelled PhaseStatusCode = "cancelled"
+)
+
+// PhaseResult describes the outcome of a single phase
+type PhaseResult struct {
+	Name        string          `json:"name"`
+	Status      PhaseStatusCode `json:"status"`
+	Attempts    int             `json:"attempts,omitempty"`
+	ExitCode    *int            `json:"exitCode,omitempty"`
+	Message     string          `json:"message,omitempty"`
+	StartedAt   *time.Time      `json:"startedAt,omitempty"`
+	CompletedAt *time.Time      `json:"completedAt,omitempty"`
+
+	// ImageDigest is the digest of the image which the phase ran with
+	// as resolved by the worker
+	ImageDigest string `json:"imageDigest,omitempty"`
+}
+
+// RunStatusUpdate is emitted by a worker whenever the status of an
+// automation run or one of its phases changes
+type RunStatusUpdate struct {
+	// AutomationId is the ID of the automation run being updated
+	AutomationId string `json:"automationId"`
+
+	// Status when defined updates the status of the automation run
+	Status RunStatusCode `json:"status,omitempty"`
+
+	// Phase when defined updates the result of the phase with the
+	// same name
+	Phase *PhaseResult `json:"phase,omitempty"`
+
+	// ExitCode is the exit code of the automation run, this should
+	// only be defined when the run has completed
+	ExitCode *int `json:"exitCode,omitempty"`
+
+	// Message is a human-readable reason for the update
+	Message string `json:"message,omitempty"`
+
+	// WorkerId is the ID of the worker which emitted the update
+	WorkerId string `json:"workerId,omitempty"`
+
+	// Timestamp is when the update was emitted
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// RunStatus is the aggregated status of an automation run built up
+// from RunStatusUpdates
+type RunStatus struct {
+	Status      RunStatusCode `json:"status"`
+	Phases      []PhaseResult `json:"phases"`
+	ExitCode    *int          `json:"exitCode,omitempty"`
+	Message     string        `json:"message,omitempty"`
+	WorkerId    string        `json:"workerId,omitempty"`
+	StartedAt   *time.Time    `json:"startedAt,omitempty"`
+	CompletedAt *time.Time    `json:"completedAt,omitempty"`
+}
+
+// Apply merges the provided update into the run status; updates that
+// arrive after the run has reached a final status are ignored except
+// for phase results
+func (rs *RunStatus) Apply(update RunStatusUpdate) {
+	timestamp := update.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	if update.WorkerId != "" {
+		rs.WorkerId = update.WorkerId
+	}
+	if update.Phase != nil {
+		isExistingPhase := false
+		for i := range rs.Phases {
+			if rs.Phases[i].Name == update.Phase.Name {
+				rs.Phases[i].merge(*update.Phase)
+				isExistingPhase = true
+				break
+			}
+		}
+		if !isExistingPhase {
+			rs.Phases = append(rs.Phases, *update.Phase)
+		}
+	}
+	if update.Status == "" || rs.Status.IsFinal() {
+		return
+	}
+	rs.Status = update.Status
+	if update.Message != "" {
+		rs.Message = update.Message
+	}
+	if update.Status == RunStatusExecuting && rs.StartedAt == nil {
+		rs.StartedAt = &timestamp
+	}
+	if update.Status.IsFinal() {
+		rs.CompletedAt = &timestamp
+		if update.ExitCode != nil {
+			exitCode := *update.ExitCode
+			rs.ExitCode = &exitCode
+		}
+	}
+}
+
+func (pr *PhaseResult) merge(update PhaseResult) {
+	if update.Status != "" {
+		pr.Status = update.Status
+	}
+	if update.Attempts != 0 {
+		pr.Attempts = update.Attempts
+	}
+	if update.ExitCode != nil {
+		pr.ExitCode = update.ExitCode
+	}
+	if update.Message != "" {
+		pr.Message = update.Message
+	}
+	if update.StartedAt != nil {
+		pr.StartedAt = update.StartedAt
+	}
+	if update.CompletedAt != nil {
+		pr.CompletedAt = update.CompletedAt
+	}
+	if update.ImageDigest != "" {
+		pr.ImageDigest = update.ImageDigest
+	}
+}
@@ -0,0 +1,37 @@
+package automations
+
+import "testing"
+
+func TestRunStatusApply(t *testing.T) {
+	rs := RunStatus{Status: RunStatusPendingExecution}
+	rs.Apply(RunStatusUpdate{Status: RunStatusExecuting, WorkerId: "worker-1"})
+	if rs.Status != RunStatusExecuting || rs.StartedAt == nil {
+		t.Fatalf("expected executing status with a start time, got %s", rs.Status)
+	}
+	rs.Apply(RunStatusUpdate{Phase: &PhaseResult{Name: "build", Status: PhaseStatusRunning}})
+	exitCode := 2
+	rs.Apply(RunStatusUpdate{Phase: &PhaseResult{Name: "build", Status: PhaseStatusFailed, ExitCode: &exitCode}})
+	if len(rs.Phases) != 1 || rs.Phases[0].Status != PhaseStatusFailed {
+		t.Fatalf("expected 1 failed phase, got %+v", rs.Phases)
+	}
+	rs.Apply(RunStatusUpdate{Status: RunStatusCompletedFailed, ExitCode: &exitCode})
+	if rs.CompletedAt == nil || rs.ExitCode == nil || *rs.ExitCode != 2 {
+		t.Fatalf("expected completion time and exit code 2")
+	}
+	rs.Apply(RunStatusUpdate{Status: RunStatusExecuting})
+	if rs.Status != RunStatusCompletedFailed {
+		t.Fatalf("expected final status to be retained, got %s", rs.Status)
+	}
+}
+
+func TestRunStatusApplyCancelled(t *testing.T) {
+	rs := RunStatus{Status: RunStatusPendingExecution}
+	rs.Apply(RunStatusUpdate{Status: RunStatusCancelled, Message: "automation was cancelled"})
+	if rs.Status != RunStatusCancelled || rs.CompletedAt == nil {
+		t.Fatalf("expected cancelled status with a completion time, got %s", rs.Status)
+	}
+	rs.Apply(RunStatusUpdate{Status: RunStatusExecuting, Message: "automation started"})
+	if rs.Status != RunStatusCancelled || rs.Message != "automation was cancelled" {
+		t.Fatalf("expected a worker starting the run to not override the cancellation, got %s", rs.Status)
+	}
+}
@@ -10,6 +10,10 @@ type TemplateSpec struct {
 	// ApprovalPolicy defines the approval mechanism
 	ApprovalPolicy *ApprovalPolicySpec `json:"approvalPolicy" yaml:"approvalPolicy"`
 
+	// Concurrency limits the number of runs of the template which can
+	// run at the same time
+	Concurrency *ConcurrencySpec `json:"concurrency,omitempty" yaml:"concurrency,omitempty"`
+
 	// Metadata defines other metadata not included in the parent
 	// resource
 	Metadata MetadataSpec `json:"metadata" yaml:"metadata"`
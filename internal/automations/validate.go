@@ -0,0 +1,229 @@
+package automations
+
+import (
+	"errors"
+	"fmt"
+	"opsicle/internal/common"
+	"slices"
+	"strings"
+)
+
+// Validate returns an error describing all problems found with the
+// automation spec
+func (s AutomationSpec) Validate() error {
+	errs := []error{}
+	phaseNames := map[string]struct{}{}
+	for i, phase := range s.Phases {
+		if phase.Name == "" {
+			errs = append(errs, fmt.Errorf("phases[%v]: %w", i, ErrorPhaseNameRequired))
+		} else if _, exists := phaseNames[phase.Name]; exists {
+			errs = append(errs, fmt.Errorf("phases[%v]: %w: %s", i, ErrorPhaseNameDuplicated, phase.Name))
+		}
+		phaseNames[phase.Name] = struct{}{}
+		if err := phase.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("phases[%v]: %w", i, err))
+		}
+	}
+	if s.MaxParallelPhases < 0 {
+		errs = append(errs, fmt.Errorf("%w: maxParallelPhases cannot be negative", ErrorPhaseInvalid))
+	}
+	if err := s.validatePhaseDependencies(phaseNames); err != nil {
+		errs = append(errs, err)
+	} else if err := s.validatePhaseConditions(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+// ValidateRuntime returns an error describing all problems found with
+// the automation spec when its phases are run using `runtime`; phases
+// need an image unless they run as processes on the worker's host and
+// outputs cannot be collected from phases run in kubernetes
+func (s AutomationSpec) ValidateRuntime(runtime string) error {
+	errs := []error{}
+	for i, phase := range s.Phases {
+		if phase.Image == "" && runtime != common.RuntimeProcess {
+			errs = append(errs, fmt.Errorf("phases[%v]: %w", i, ErrorPhaseImageRequired))
+		}
+		if len(phase.Outputs) > 0 && runtime == common.RuntimeKubernetes {
+			errs = append(errs, fmt.Errorf("phases[%v]: %w: outputs are not supported by the kubernetes runtime", i, ErrorPhaseInvalid))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Validate returns an error describing all problems found with the
+// phase
+func (p Phase) Validate() error {
+	errs := []error{}
+	if p.ImagePullPolicy != "" && !slices.Contains(ImagePullPolicies, p.ImagePullPolicy) {
+		errs = append(errs, fmt.Errorf("%w: imagePullPolicy '%s' must be one of %v", ErrorPhaseInvalid, p.ImagePullPolicy, ImagePullPolicies))
+	}
+	if p.Retries < 0 {
+		errs = append(errs, fmt.Errorf("%w: retries cannot be negative", ErrorPhaseInvalid))
+	}
+	if p.RetryBackoffSeconds < 0 {
+		errs = append(errs, fmt.Errorf("%w: retryBackoffSeconds cannot be negative", ErrorPhaseInvalid))
+	}
+	if p.Timeout < 0 {
+		errs = append(errs, fmt.Errorf("%w: timeout cannot be negative", ErrorPhaseInvalid))
+	}
+	for _, exitCode := range p.AllowedExitCodes {
+		if exitCode < 0 || exitCode > 255 {
+			errs = append(errs, fmt.Errorf("%w: allowed exit code %v is not within 0-255", ErrorPhaseInvalid, exitCode))
+		}
+	}
+	if p.When != "" {
+		if _, err := ParseCondition(p.When); err != nil {
+			errs = append(errs, fmt.Errorf("when: %w", err))
+		}
+	}
+	for _, output := range p.Outputs {
+		if !isValidOutput(output) {
+			errs = append(errs, fmt.Errorf("%w: output '%s' must be a non-root path without '..'", ErrorPhaseInvalid, output))
+		}
+	}
+	if p.Resources != nil {
+		if err := p.Resources.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("resources: %w", err))
+		}
+	}
+	if p.Network != "" && !slices.Contains(PhaseNetworks, p.Network) {
+		errs = append(errs, fmt.Errorf("%w: network '%s' must be one of %v", ErrorPhaseInvalid, p.Network, PhaseNetworks))
+	}
+	for _, capability := range p.CapDrop {
+		if strings.TrimSpace(capability) == "" {
+			errs = append(errs, fmt.Errorf("%w: capDrop cannot contain empty capabilities", ErrorPhaseInvalid))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+// validatePhaseConditions checks that the `when` conditions of phases
+// only reference variables and phases which complete before them
+func (s AutomationSpec) validatePhaseConditions() error {
+	errs := []error{}
+	for i, phase := range s.Phases {
+		if phase.When == "" {
+			continue
+		}
+		condition, err := ParseCondition(phase.When)
+		if err != nil {
+			continue
+		}
+		ancestors := s.GetPhaseAncestors(phase.Name)
+		for _, reference := range condition.GetReferences() {
+			referencePath := strings.Join(reference, ".")
+			switch reference[0] {
+			case ConditionRootVars:
+			case ConditionRootPhases:
+				if len(reference) < 2 {
+					continue
+				}
+				if _, isAncestor := ancestors[reference[1]]; !isAncestor {
+					errs = append(errs, fmt.Errorf("phases[%v]: when: %w: '%s' references a phase that does not complete before this phase", i, ErrorConditionInvalid, referencePath))
+				}
+				if len(reference) > 2 {
+					switch reference[2] {
+					case ConditionPhaseFieldAttempts, ConditionPhaseFieldExitCode, ConditionPhaseFieldStatus:
+					default:
+						errs = append(errs, fmt.Errorf("phases[%v]: when: %w: '%s' references an unknown phase field", i, ErrorConditionInvalid, referencePath))
+					}
+				}
+			default:
+				errs = append(errs, fmt.Errorf("phases[%v]: when: %w: '%s' must start with '%s' or '%s'", i, ErrorConditionInvalid, referencePath, ConditionRootVars, ConditionRootPhases))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+// Validate returns an error describing all problems found with the
+// variables
+func (vs VariablesSpec) Validate() error {
+	errs := []error{}
+	variableIds := map[string]struct{}{}
+	for i, variable := range vs {
+		if variable.Id == "" {
+			errs = append(errs, fmt.Errorf("variables[%v]: %w", i, ErrorVariableIdRequired))
+		} else if _, exists := variableIds[variable.Id]; exists {
+			errs = append(errs, fmt.Errorf("variables[%v]: %w: %s", i, ErrorVariableIdDuplicated, variable.Id))
+		}
+		variableIds[variable.Id] = struct{}{}
+		switch variable.Type {
+		case "", VariableTypeBool, VariableTypeFloat, VariableTypeNumber, VariableTypeSecret, VariableTypeString:
+		default:
+			errs = append(errs, fmt.Errorf("variables[%v]: %w: unknown type '%s'", i, ErrorVariableInvalid, variable.Type))
+		}
+		if variable.ValueFrom != nil {
+			if variable.ValueFrom.SecretRef == "" {
+				errs = append(errs, fmt.Errorf("variables[%v]: %w: valueFrom requires a secretRef", i, ErrorVariableInvalid))
+			}
+			if variable.Type != "" && variable.Type != VariableTypeSecret {
+				errs = append(errs, fmt.Errorf("variables[%v]: %w: variables with a secretRef must be of type '%s'", i, ErrorVariableInvalid, VariableTypeSecret))
+			}
+			if variable.Default != nil {
+				errs = append(errs, fmt.Errorf("variables[%v]: %w: variables with a secretRef cannot have a default", i, ErrorVariableInvalid))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+// Validate returns an error describing all problems found with the
+// template
+func (t *Template) Validate() error {
+	errs := []error{}
+	if err := t.Spec.Template.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("template: %w", err))
+	}
+	if err := t.Spec.Variables.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("variables: %w", err))
+	}
+	if t.Spec.Concurrency != nil {
+		if err := t.Spec.Concurrency.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("concurrency: %w", err))
+		}
+	}
+	if t.Spec.ApprovalPolicy != nil {
+		if err := t.Spec.ApprovalPolicy.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("approvalPolicy: %w", err))
+		}
+	}
+	variableIds := map[string]struct{}{}
+	for _, variable := range t.Spec.Variables {
+		if !variable.IsSecret() {
+			variableIds[variable.Id] = struct{}{}
+		}
+	}
+	for i, phase := range t.Spec.Template.Phases {
+		condition, err := ParseCondition(phase.When)
+		if phase.When == "" || err != nil {
+			continue
+		}
+		for _, reference := range condition.GetReferences() {
+			if reference[0] != ConditionRootVars || len(reference) < 2 {
+				continue
+			}
+			if _, exists := variableIds[reference[1]]; !exists {
+				errs = append(errs, fmt.Errorf("template: phases[%v]: when: %w: '%s' references an undefined or secret variable", i, ErrorConditionInvalid, strings.Join(reference, ".")))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return nil
+}
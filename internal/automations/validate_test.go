@@ -0,0 +1,110 @@
+package automations
+
+import (
+	"errors"
+	"testing"
+
+	"opsicle/internal/approvals"
+	"opsicle/internal/common"
+)
+
+func TestAutomationSpecValidate(t *testing.T) {
+	spec := AutomationSpec{
+		Phases: []Phase{
+			{Name: "build", Image: "alpine", Retries: 2, AllowedExitCodes: []int{1}},
+			{Name: "build", Image: "alpine"},
+			{Name: "deploy", Retries: -1},
+		},
+	}
+	err := spec.Validate()
+	if !errors.Is(err, ErrorPhaseNameDuplicated) {
+		t.Fatalf("expected duplicated phase name error, got %v", err)
+	}
+	if errors.Is(err, ErrorPhaseImageRequired) || !errors.Is(err, ErrorPhaseInvalid) {
+		t.Fatalf("expected only an invalid phase error, got %v", err)
+	}
+	if err := spec.ValidateRuntime(common.RuntimeDocker); !errors.Is(err, ErrorPhaseImageRequired) {
+		t.Fatalf("expected image required error, got %v", err)
+	}
+	if err := spec.ValidateRuntime(common.RuntimeProcess); err != nil {
+		t.Fatalf("expected phases without an image to be allowed as processes, got %v", err)
+	}
+	spec.Phases[0].Outputs = []string{"dist"}
+	if err := spec.ValidateRuntime(common.RuntimeKubernetes); !errors.Is(err, ErrorPhaseInvalid) {
+		t.Fatalf("expected outputs to be rejected by the kubernetes runtime, got %v", err)
+	}
+	if err := spec.ValidateRuntime(common.RuntimeProcess); err != nil {
+		t.Fatalf("expected outputs to be allowed as processes, got %v", err)
+	}
+	if !spec.Phases[0].IsExitCodeAllowed(1) || spec.Phases[0].IsExitCodeAllowed(2) {
+		t.Fatalf("expected only exit codes 0 and 1 to be allowed")
+	}
+}
+
+func TestVariablesSpecValidate(t *testing.T) {
+	variables := VariablesSpec{
+		{Id: "token", ValueFrom: &VariableValueFrom{SecretRef: "api-token"}},
+		{Id: "token", Type: "unknown"},
+		{Id: "password", Type: VariableTypeString, Default: "x", ValueFrom: &VariableValueFrom{}},
+	}
+	err := variables.Validate()
+	if !errors.Is(err, ErrorVariableIdDuplicated) || !errors.Is(err, ErrorVariableInvalid) {
+		t.Fatalf("expected duplicated id and invalid variable errors, got %v", err)
+	}
+	if err := variables[:1].Validate(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !variables[0].IsSecret() {
+		t.Fatalf("expected variables with a secretRef to be secret")
+	}
+	if vars, err := variables[:1].Resolve(map[string]any{}); err != nil || len(vars) != 0 {
+		t.Fatalf("expected unresolved secret refs to be skipped, got %v (%v)", vars, err)
+	}
+	if input := variables[:1].GetUserInput(map[string]any{"token": "forged"}); len(input) != 0 {
+		t.Fatalf("expected user input for secret refs to be dropped, got %v", input)
+	}
+}
+
+func TestPhaseValidateResources(t *testing.T) {
+	phase := Phase{Name: "limited", Image: "alpine", Resources: &PhaseResources{Cpu: "0.5", Memory: "512m", Pids: 64}, Network: PhaseNetworkNone}
+	if err := phase.Validate(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if nanoCpus, _ := phase.Resources.GetNanoCpus(); nanoCpus != 5e8 {
+		t.Fatalf("expected 5e8 nano cpus, got %v", nanoCpus)
+	}
+	if memory, _ := phase.Resources.GetMemoryBytes(); memory != 512*1024*1024 {
+		t.Fatalf("expected 512MiB, got %v", memory)
+	}
+	for _, cpu := range []string{"0.0000000001", "0.0009", "NaN", "Inf"} {
+		phase.Resources = &PhaseResources{Cpu: cpu}
+		if err := phase.Validate(); !errors.Is(err, ErrorPhaseInvalid) {
+			t.Fatalf("expected cpu '%s' to be invalid, got %v", cpu, err)
+		}
+	}
+	phase.Resources = &PhaseResources{Cpu: "0.001"}
+	if nanoCpus, err := phase.Resources.GetNanoCpus(); err != nil || nanoCpus != 1e6 {
+		t.Fatalf("expected 1e6 nano cpus, got %v (%v)", nanoCpus, err)
+	}
+	phase.Resources = &PhaseResources{Cpu: "lots", Memory: "-1"}
+	phase.Network = "overlay"
+	if err := phase.Validate(); !errors.Is(err, ErrorPhaseInvalid) {
+		t.Fatalf("expected invalid phase error, got %v", err)
+	}
+}
+
+func TestApprovalPolicySpecValidate(t *testing.T) {
+	emptyRef := ""
+	if err := (ApprovalPolicySpec{PolicyRef: &emptyRef}).Validate(); !errors.Is(err, ErrorApprovalPolicyInvalid) {
+		t.Fatalf("expected invalid approval policy error, got %v", err)
+	}
+	policyRef := "production"
+	policy := ApprovalPolicySpec{PolicyRef: &policyRef}
+	if err := policy.Validate(); err != nil || policy.IsResolved() {
+		t.Fatalf("expected a valid unresolved policy reference, got %v", err)
+	}
+	policy.Spec = &approvals.PolicySpec{Stages: []approvals.StageSpec{{Name: "lead"}}}
+	if err := policy.Validate(); !errors.Is(err, ErrorApprovalPolicyInvalid) {
+		t.Fatalf("expected a stage without targets to be invalid, got %v", err)
+	}
+}
@@ -1,5 +1,31 @@
 package automations
 
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	VariableTypeBool   = "bool"
+	VariableTypeFloat  = "float"
+	VariableTypeNumber = "number"
+	VariableTypeSecret = "secret"
+	VariableTypeString = "string"
+
+	// RedactedValue replaces the values of secret variables wherever
+	// they would otherwise be displayed or stored in plaintext
+	RedactedValue = "********"
+
+	// VariableEnvPrefix is prepended to the upper-cased ID of a variable
+	// to form the name of the environment variable exposed to phases
+	VariableEnvPrefix = "OPSICLE_VAR_"
+)
+
 type VariablesSpec []VariableSpec
 
 type VariableSpec struct {
@@ -10,6 +36,276 @@ type VariableSpec struct {
 	Type        string `json:"type" yaml:"type"`
 	IsRequired  bool   `json:"isRequired" yaml:"isRequired"`
 
+	// ValueFrom when defined indicates that the value of the variable is
+	// not input by the user but retrieved from elsewhere when the
+	// automation is handed over to a worker
+	ValueFrom *VariableValueFrom `json:"valueFrom,omitempty" yaml:"valueFrom,omitempty"`
+
 	// Value is not in the spec but used during processing
 	Value any `json:"value" yaml:"-"`
 }
+
+type VariableValueFrom struct {
+	// SecretRef is the name of an org secret which the variable's value
+	// should be set to
+	SecretRef string `json:"secretRef" yaml:"secretRef"`
+}
+
+// GetSecretRef returns the name of the org secret which the variable's
+// value comes from or an empty string if it doesn't come from one
+func (v VariableSpec) GetSecretRef() string {
+	if v.ValueFrom == nil {
+		return ""
+	}
+	return v.ValueFrom.SecretRef
+}
+
+// GetEnvName returns the name of the environment variable which the
+// variable's value is exposed as
+func (v VariableSpec) GetEnvName() string {
+	var envName strings.Builder
+	envName.WriteString(VariableEnvPrefix)
+	for _, character := range strings.ToUpper(v.Id) {
+		if unicode.IsLetter(character) || unicode.IsDigit(character) {
+			envName.WriteRune(character)
+			continue
+		}
+		envName.WriteRune('_')
+	}
+	return envName.String()
+}
+
+// IsSecret returns true if the variable's value should never be
+// displayed or stored in plaintext
+func (v VariableSpec) IsSecret() bool {
+	return v.Type == VariableTypeSecret || v.GetSecretRef() != ""
+}
+
+// GetRedacted returns a copy of the variable with its default value and
+// value redacted if it is a secret
+func (v VariableSpec) GetRedacted() VariableSpec {
+	if !v.IsSecret() {
+		return v
+	}
+	if v.Default != nil {
+		v.Default = RedactedValue
+	}
+	if v.Value != nil {
+		v.Value = RedactedValue
+	}
+	return v
+}
+
+// ParseValue verifies that the provided value matches the type of the
+// variable and returns it in its normalised form; numbers are returned
+// as int64 and floats as float64
+func (v VariableSpec) ParseValue(value any) (any, error) {
+	variableType := v.Type
+	if v.GetSecretRef() != "" {
+		variableType = VariableTypeSecret
+	}
+	switch variableType {
+	case VariableTypeBool:
+		if _, ok := value.(bool); !ok {
+			return nil, fmt.Errorf("var[%s] should be but is not a boolean", v.Id)
+		}
+	case VariableTypeString:
+		if _, ok := value.(string); !ok {
+			return nil, fmt.Errorf("var[%s] should be but is not a string", v.Id)
+		}
+	case VariableTypeSecret:
+		if _, ok := value.(string); !ok {
+			return nil, fmt.Errorf("var[%s] should be but is not a secret string", v.Id)
+		}
+	case VariableTypeNumber:
+		switch number := value.(type) {
+		case int:
+			return int64(number), nil
+		case int64:
+			return number, nil
+		case float64:
+			if number != math.Trunc(number) {
+				return nil, fmt.Errorf("var[%s] should be but is not a whole number", v.Id)
+			}
+			return int64(number), nil
+		}
+		return nil, fmt.Errorf("var[%s] should be but is not a number", v.Id)
+	case VariableTypeFloat:
+		switch number := value.(type) {
+		case int:
+			return float64(number), nil
+		case int64:
+			return float64(number), nil
+		case float64:
+			return number, nil
+		}
+		return nil, fmt.Errorf("var[%s] should be but is not a floating point", v.Id)
+	}
+	return value, nil
+}
+
+// Resolve returns a map of variable ID to value where values are taken
+// from the provided `input` and fall back to the variable's default; an
+// error is returned if a required variable has no value or if a value
+// does not match its variable's type. Variables with a `.ValueFrom` are
+// only included if they are present in the `input`
+func (vs VariablesSpec) Resolve(input map[string]any) (map[string]any, error) {
+	output := map[string]any{}
+	errs := []error{}
+	for _, variable := range vs {
+		value, exists := input[variable.Id]
+		if variable.ValueFrom != nil && (!exists || value == nil) {
+			continue
+		}
+		if !exists || value == nil {
+			value = variable.Default
+		}
+		if value == nil {
+			if variable.IsRequired {
+				errs = append(errs, fmt.Errorf("var[%s] is required", variable.Id))
+			}
+			continue
+		}
+		parsedValue, err := variable.ParseValue(value)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		output[variable.Id] = parsedValue
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return output, nil
+}
+
+// GetSecretRefs returns the names of org secrets referenced by the
+// variables
+func (vs VariablesSpec) GetSecretRefs() []string {
+	secretRefs := []string{}
+	for _, variable := range vs {
+		if secretRef := variable.GetSecretRef(); secretRef != "" && !slices.Contains(secretRefs, secretRef) {
+			secretRefs = append(secretRefs, secretRef)
+		}
+	}
+	return secretRefs
+}
+
+// GetInputSecretIds returns the IDs of secret variables whose values
+// are input with the run instead of being referenced from org secrets
+func (vs VariablesSpec) GetInputSecretIds() []string {
+	variableIds := []string{}
+	for _, variable := range vs {
+		if variable.IsSecret() && variable.GetSecretRef() == "" {
+			variableIds = append(variableIds, variable.Id)
+		}
+	}
+	return variableIds
+}
+
+// GetSecretEnvNames returns the names of the environment variables
+// which hold the values of secret variables
+func (vs VariablesSpec) GetSecretEnvNames() []string {
+	envNames := []string{}
+	for _, variable := range vs {
+		if variable.IsSecret() {
+			envNames = append(envNames, variable.GetEnvName())
+		}
+	}
+	return envNames
+}
+
+// GetUserInput returns a copy of the provided `input` without values
+// for variables that are not meant to be input by users
+func (vs VariablesSpec) GetUserInput(input map[string]any) map[string]any {
+	output := map[string]any{}
+	for id, value := range input {
+		output[id] = value
+	}
+	for _, variable := range vs {
+		if variable.ValueFrom != nil {
+			delete(output, variable.Id)
+		}
+	}
+	return output
+}
+
+// GetValueMap returns a map of variable ID to the variable's `.Value`
+func (vs VariablesSpec) GetValueMap() map[string]any {
+	output := map[string]any{}
+	for _, variable := range vs {
+		output[variable.Id] = variable.Value
+	}
+	return output
+}
+
+// GetRedacted returns a copy of the provided `values` with the values
+// of secret variables redacted
+func (vs VariablesSpec) GetRedacted(values map[string]any) map[string]any {
+	output := map[string]any{}
+	for id, value := range values {
+		output[id] = value
+	}
+	for _, variable := range vs {
+		if _, exists := output[variable.Id]; exists && variable.IsSecret() {
+			output[variable.Id] = RedactedValue
+		}
+	}
+	return output
+}
+
+// GetSecrets returns the values of secret variables from the provided
+// `values` so that they can be redacted from output
+func (vs VariablesSpec) GetSecrets(values map[string]any) []string {
+	secrets := []string{}
+	for _, variable := range vs {
+		if !variable.IsSecret() {
+			continue
+		}
+		if secret, ok := values[variable.Id].(string); ok && secret != "" {
+			secrets = append(secrets, secret)
+		}
+	}
+	return secrets
+}
+
+// GetTemplateVars returns the provided `values` without the values of
+// secret variables; secrets are only exposed to phases through their
+// environment so that they never appear in rendered commands
+func (vs VariablesSpec) GetTemplateVars(values map[string]any) map[string]any {
+	output := map[string]any{}
+	for id, value := range values {
+		output[id] = value
+	}
+	for _, variable := range vs {
+		if variable.IsSecret() {
+			delete(output, variable.Id)
+		}
+	}
+	return output
+}
+
+// GetEnv returns the variables as a list of `KEY=value` strings suitable
+// for use as a container's environment; variables without a value are
+// omitted
+func (vs VariablesSpec) GetEnv(values map[string]any) []string {
+	env := []string{}
+	for _, variable := range vs {
+		value, exists := values[variable.Id]
+		if !exists || value == nil {
+			continue
+		}
+		env = append(env, fmt.Sprintf("%s=%s", variable.GetEnvName(), formatVariableValue(value)))
+	}
+	return env
+}
+
+func formatVariableValue(value any) string {
+	switch typedValue := value.(type) {
+	case float64:
+		return strconv.FormatFloat(typedValue, 'f', -1, 64)
+	case string:
+		return typedValue
+	}
+	return fmt.Sprintf("%v", value)
+}
@@ -0,0 +1,92 @@
+package automations
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestVariablesSpecResolve(t *testing.T) {
+	variables := VariablesSpec{
+		{Id: "env-name", Type: VariableTypeString, IsRequired: true},
+		{Id: "replicas", Type: VariableTypeNumber, Default: 2},
+		{Id: "ratio", Type: VariableTypeFloat},
+		{Id: "dry_run", Type: VariableTypeBool},
+	}
+	vars, err := variables.Resolve(map[string]any{
+		"env-name": "staging",
+		"ratio":    0.5,
+		"dry_run":  true,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if vars["replicas"] != int64(2) {
+		t.Fatalf("expected default replicas to be int64(2), got %#v", vars["replicas"])
+	}
+	env := variables.GetEnv(vars)
+	expectedEnv := []string{
+		"OPSICLE_VAR_ENV_NAME=staging",
+		"OPSICLE_VAR_REPLICAS=2",
+		"OPSICLE_VAR_RATIO=0.5",
+		"OPSICLE_VAR_DRY_RUN=true",
+	}
+	if !slices.Equal(env, expectedEnv) {
+		t.Fatalf("expected env %v, got %v", expectedEnv, env)
+	}
+
+	if _, err := variables.Resolve(map[string]any{"replicas": 1.5}); err == nil {
+		t.Fatalf("expected errors for missing required variable and fractional number")
+	}
+}
+
+func TestPhaseRender(t *testing.T) {
+	phase := Phase{
+		Name:     "deploy",
+		Image:    "alpine:{{ .vars.version }}",
+		Commands: []string{"echo {{ .vars.target }}", "echo done"},
+	}
+	rendered, err := phase.Render(map[string]any{"version": "3.20", "target": "prod"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if rendered.Image != "alpine:3.20" || rendered.Commands[0] != "echo prod" {
+		t.Fatalf("unexpected rendered phase: %+v", rendered)
+	}
+	if phase.Commands[0] != "echo {{ .vars.target }}" {
+		t.Fatalf("expected original phase to be left unchanged")
+	}
+	if _, err := phase.Render(map[string]any{"version": "3.20"}); err == nil {
+		t.Fatalf("expected an error when a variable is missing")
+	}
+}
+
+func TestSecretVariables(t *testing.T) {
+	variables := VariablesSpec{
+		{Id: "db_password", Type: VariableTypeSecret, Default: "hunter2"},
+		{Id: "db_user", Type: VariableTypeString},
+	}
+	vars, err := variables.Resolve(map[string]any{"db_user": "admin"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if redacted := variables.GetRedacted(vars); redacted["db_password"] != RedactedValue || redacted["db_user"] != "admin" {
+		t.Fatalf("expected only the secret to be redacted, got %v", redacted)
+	}
+	if _, exists := variables.GetTemplateVars(vars)["db_password"]; exists {
+		t.Fatalf("expected secrets to be excluded from template vars")
+	}
+	if variables[0].GetRedacted().Default != RedactedValue {
+		t.Fatalf("expected the default value of a secret to be redacted")
+	}
+	logLine := RedactSecrets("connecting as admin:hunter2", variables.GetSecrets(vars))
+	if logLine != "connecting as admin:"+RedactedValue {
+		t.Fatalf("unexpected redacted log line: %s", logLine)
+	}
+	variables = append(variables, VariableSpec{Id: "api_token", ValueFrom: &VariableValueFrom{SecretRef: "api-token"}})
+	if inputSecretIds := variables.GetInputSecretIds(); len(inputSecretIds) != 1 || inputSecretIds[0] != "db_password" {
+		t.Fatalf("expected only db_password to be an input secret, got %v", inputSecretIds)
+	}
+	if envNames := variables.GetSecretEnvNames(); len(envNames) != 2 || envNames[0] != variables[0].GetEnvName() || envNames[1] != variables[2].GetEnvName() {
+		t.Fatalf("expected the env names of db_password and api_token, got %v", envNames)
+	}
+}
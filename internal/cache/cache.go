@@ -8,7 +8,11 @@ var instance Cache
 
 type Cache interface {
 	Set(key string, value string, ttl time.Duration) (err error)
+	SetIfNotExists(key string, value string, ttl time.Duration) (isSet bool, err error)
+	CompareAndSet(key string, expected string, value string, ttl time.Duration) (isSet bool, err error)
+	CompareAndDel(key string, expected string) (isDeleted bool, err error)
 	Get(key string) (value string, err error)
+	Increment(key string, ttl time.Duration) (count int64, err error)
 	Scan(prefix string) (keys []string, err error)
 	Del(key string) (err error)
 	Ping() (err error)
@@ -0,0 +1,36 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+// AcquireLock attempts to acquire the lock identified by `key` on behalf
+// of `owner` for `ttl`; calling this while already holding the lock
+// extends it, locks which are not extended expire after `ttl` so that a
+// crashed owner does not hold the lock forever
+func AcquireLock(c Cache, key, owner string, ttl time.Duration) (bool, error) {
+	isAcquired, err := c.SetIfNotExists(key, owner, ttl)
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lock[%s]: %w", key, err)
+	} else if isAcquired {
+		return true, nil
+	}
+	// the lock is only extended if it is still held by `owner`, this is
+	// done atomically so that a lock which expired and was acquired by
+	// someone else in between is not overwritten
+	isExtended, err := c.CompareAndSet(key, owner, owner, ttl)
+	if err != nil {
+		return false, fmt.Errorf("failed to extend lock[%s]: %w", key, err)
+	}
+	return isExtended, nil
+}
+
+// ReleaseLock releases the lock identified by `key` if it is held by
+// `owner`
+func ReleaseLock(c Cache, key, owner string) error {
+	if _, err := c.CompareAndDel(key, owner); err != nil {
+		return fmt.Errorf("failed to release lock[%s]: %w", key, err)
+	}
+	return nil
+}
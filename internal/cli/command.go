@@ -93,7 +93,7 @@ type Command struct {
 // Shutdown() method is called
 func (cd *Command) AddShutdownProcess(id string, process func() error) {
 	if _, ok := cd.shutdownProcesses[id]; ok {
-		*cd.serviceLogs <- common.ServiceLogf(common.LogLevelWarn, "process[%s] was overwritten")
+		*cd.serviceLogs <- common.ServiceLogf(common.LogLevelWarn, "process[%s] was overwritten", id)
 	}
 	cd.shutdownProcesses[id] = process
 }
@@ -32,7 +32,7 @@ func HandleTemplateUserSelection(opts HandleTemplateUserSelectionOpts) (user *Se
 	} else {
 		logs = opts.ServiceLog
 	}
-	logs <- common.ServiceLogf(common.LogLevelDebug, fmt.Sprintf("retrieving available users from template[%s]", opts.TemplateId))
+	logs <- common.ServiceLogf(common.LogLevelDebug, "retrieving available users from template[%s]", opts.TemplateId)
 
 	templateUsers, err := opts.Client.ListTemplateUsersV1(controller.ListTemplateUsersV1Input{
 		TemplateId: opts.TemplateId,
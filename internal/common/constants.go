@@ -27,11 +27,13 @@ var LogLevels = []LogLevel{
 const (
 	RuntimeDocker     = "docker"
 	RuntimeKubernetes = "kubernetes"
+	RuntimeProcess    = "process"
 )
 
 var Runtimes = []string{
 	RuntimeDocker,
 	RuntimeKubernetes,
+	RuntimeProcess,
 }
 
 const (
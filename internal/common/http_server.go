@@ -2,9 +2,12 @@ package common
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"net/http"
+	"os"
 	"strings"
 	"time"
 )
@@ -12,6 +15,11 @@ import (
 type HttpServer struct {
 	Server      http.Server
 	ServiceLogs chan<- ServiceLog
+
+	// TlsCertPath and TlsKeyPath when both are defined causes the
+	// server to serve HTTPS instead of HTTP
+	TlsCertPath string
+	TlsKeyPath  string
 }
 
 func (s *HttpServer) Shutdown() error {
@@ -25,6 +33,15 @@ func (s *HttpServer) Shutdown() error {
 }
 
 func (s *HttpServer) Start() error {
+	if s.TlsCertPath != "" && s.TlsKeyPath != "" {
+		s.ServiceLogs <- ServiceLogf(LogLevelInfo, "starting https server on %s...", s.Server.Addr)
+		if err := s.Server.ListenAndServeTLS(s.TlsCertPath, s.TlsKeyPath); err != nil {
+			if !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("failed to start https server: %w", err)
+			}
+		}
+		return nil
+	}
 	s.ServiceLogs <- ServiceLogf(LogLevelInfo, "starting http server on %s...", s.Server.Addr)
 	if err := s.Server.ListenAndServe(); err != nil {
 		if !errors.Is(err, http.ErrServerClosed) {
@@ -57,6 +74,28 @@ type NewHttpServerOpts struct {
 
 	// ServiceLogs is where logs are sent to
 	ServiceLogs chan<- ServiceLog
+
+	// Tls when defined, sets the server up to serve HTTPS and optionally
+	// verify client certificates
+	Tls *NewHttpServerTlsOpts
+
+	// WriteTimeout when defined overrides the default write timeout, this
+	// should be increased for servers which hold on to requests (eg. long
+	// polling)
+	WriteTimeout time.Duration
+}
+
+type NewHttpServerTlsOpts struct {
+	// CertPath is the path to the server's PEM-encoded certificate
+	CertPath string
+
+	// KeyPath is the path to the server's PEM-encoded private key
+	KeyPath string
+
+	// ClientCaPath when defined is the path to a PEM-encoded bundle of
+	// certificate authorities which client certificates are verified
+	// against; client certificates are optional but verified if provided
+	ClientCaPath string
 }
 
 type NewHttpServerBasicAuthOpts struct {
@@ -117,15 +156,44 @@ func NewHttpServer(opts NewHttpServerOpts) (*HttpServer, error) {
 
 	handler = logger(metrics(handler))
 
-	return &HttpServer{
+	writeTimeout := DefaultDurationConnectionTimeout
+	if opts.WriteTimeout != 0 {
+		writeTimeout = opts.WriteTimeout
+	}
+
+	httpServer := &HttpServer{
 		Server: http.Server{
 			Addr:              opts.Addr,
 			Handler:           handler,
 			IdleTimeout:       DefaultDurationConnectionTimeout,
 			ReadTimeout:       DefaultDurationConnectionTimeout,
 			ReadHeaderTimeout: DefaultDurationConnectionTimeout,
-			WriteTimeout:      DefaultDurationConnectionTimeout,
+			WriteTimeout:      writeTimeout,
 		},
 		ServiceLogs: opts.ServiceLogs,
-	}, nil
+	}
+
+	if opts.Tls != nil {
+		if opts.Tls.CertPath == "" || opts.Tls.KeyPath == "" {
+			return nil, fmt.Errorf("failed to receive both a certificate and key path for tls")
+		}
+		httpServer.TlsCertPath = opts.Tls.CertPath
+		httpServer.TlsKeyPath = opts.Tls.KeyPath
+		tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+		if opts.Tls.ClientCaPath != "" {
+			clientCaPem, err := os.ReadFile(opts.Tls.ClientCaPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read client ca at path[%s]: %w", opts.Tls.ClientCaPath, err)
+			}
+			clientCas := x509.NewCertPool()
+			if !clientCas.AppendCertsFromPEM(clientCaPem) {
+				return nil, fmt.Errorf("failed to parse any certificates from client ca at path[%s]", opts.Tls.ClientCaPath)
+			}
+			tlsConfig.ClientCAs = clientCas
+			tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		}
+		httpServer.Server.TLSConfig = tlsConfig
+	}
+
+	return httpServer, nil
 }
@@ -0,0 +1,354 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"opsicle/internal/approvals"
+	"opsicle/internal/audit"
+	"opsicle/internal/automations"
+	"opsicle/internal/common"
+	"opsicle/internal/controller/models"
+	"opsicle/internal/types"
+	"opsicle/internal/validate"
+	"time"
+
+	"github.com/gorilla/mux"
+	"gopkg.in/yaml.v3"
+)
+
+func registerOrgApprovalPolicyRoutes(opts RouteRegistrationOpts) {
+	requiresAuth := getRouteAuther(opts.ServiceLogs)
+
+	v1 := opts.Router.PathPrefix("/v1/org").Subrouter()
+
+	v1.Handle("/{orgId}/approval-policy", requiresAuth(http.HandlerFunc(handleSetOrgApprovalPolicyV1))).Methods(http.MethodPost)
+	v1.Handle("/{orgId}/approval-policies", requiresAuth(http.HandlerFunc(handleListOrgApprovalPoliciesV1))).Methods(http.MethodGet)
+	v1.Handle("/{orgId}/approval-policy/{policyName}", requiresAuth(http.HandlerFunc(handleGetOrgApprovalPolicyV1))).Methods(http.MethodGet)
+	v1.Handle("/{orgId}/approval-policy/{policyName}", requiresAuth(http.HandlerFunc(handleDeleteOrgApprovalPolicyV1))).Methods(http.MethodDelete)
+}
+
+// canUserAccessOrgApprovalPolicies returns true if the user identified
+// by `userId` is allowed to perform `action` on approval policies of the
+// org identified by `orgId`
+func canUserAccessOrgApprovalPolicies(orgId, userId string, action models.Action) (bool, error) {
+	org := models.Org{Id: &orgId}
+	orgUser, err := org.GetUserV1(models.GetOrgUserV1Opts{Db: dbInstance, UserId: userId})
+	if err != nil {
+		if errors.Is(err, models.ErrorNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to load org user[%s] in org[%s]: %w", userId, orgId, err)
+	}
+	_, _, isAllowed, err := orgUser.CanV1(models.DatabaseConnection{Db: dbInstance}, models.ResourceApprovalPolicies, action)
+	if err != nil {
+		return false, fmt.Errorf("failed to check permissions of user[%s] in org[%s]: %w", userId, orgId, err)
+	}
+	return isAllowed, nil
+}
+
+// resolveTemplateApprovalPolicy copies the approval policy referenced by
+// the template into the template so that later changes to the policy do
+// not affect templates which have already been submitted; templates
+// outside of orgs cannot reference policies and can only be submitted
+// if they were already resolved
+func resolveTemplateApprovalPolicy(template *automations.Template, orgId *string) error {
+	approvalPolicy := template.Spec.ApprovalPolicy
+	if approvalPolicy == nil || approvalPolicy.PolicyRef == nil {
+		return nil
+	}
+	policyName := *approvalPolicy.PolicyRef
+	if orgId == nil {
+		if approvalPolicy.IsResolved() {
+			return nil
+		}
+		return fmt.Errorf("approval policy[%s] cannot be referenced by templates outside of an org: %w", policyName, approvals.ErrorPolicyNotFound)
+	}
+	policy, err := getOrgApprovalPolicy(*orgId, policyName)
+	if err != nil {
+		return err
+	}
+	approvalPolicy.Spec = &policy.Spec
+	return nil
+}
+
+// getOrgApprovalPolicy returns the approval policy named `policyName` in
+// the org identified by `orgId`
+func getOrgApprovalPolicy(orgId, policyName string) (*models.ApprovalPolicy, error) {
+	org := models.Org{Id: &orgId}
+	policy, err := org.GetApprovalPolicyV1(models.GetOrgApprovalPolicyV1Input{
+		DatabaseConnection: models.DatabaseConnection{Db: dbInstance},
+		Name:               policyName,
+	})
+	if err != nil {
+		if errors.Is(err, models.ErrorNotFound) {
+			return nil, fmt.Errorf("approval policy[%s] does not exist in org[%s]: %w", policyName, orgId, approvals.ErrorPolicyNotFound)
+		}
+		return nil, fmt.Errorf("failed to load approval policy[%s] of org[%s]: %w", policyName, orgId, err)
+	}
+	return policy, nil
+}
+
+type OrgApprovalPolicyV1OutputUser struct {
+	Id    string `json:"id"`
+	Email string `json:"email"`
+}
+
+type OrgApprovalPolicyV1Output struct {
+	Id            string                         `json:"id"`
+	OrgId         string                         `json:"orgId"`
+	Name          string                         `json:"name"`
+	Spec          approvals.PolicySpec           `json:"spec"`
+	CreatedAt     time.Time                      `json:"createdAt"`
+	CreatedBy     *OrgApprovalPolicyV1OutputUser `json:"createdBy"`
+	LastUpdatedAt time.Time                      `json:"lastUpdatedAt"`
+	LastUpdatedBy *OrgApprovalPolicyV1OutputUser `json:"lastUpdatedBy"`
+}
+
+func newOrgApprovalPolicyV1Output(policy models.ApprovalPolicy) OrgApprovalPolicyV1Output {
+	output := OrgApprovalPolicyV1Output{
+		Id:            policy.GetId(),
+		OrgId:         policy.OrgId,
+		Name:          policy.Name,
+		Spec:          policy.Spec,
+		CreatedAt:     policy.CreatedAt,
+		LastUpdatedAt: policy.LastUpdatedAt,
+	}
+	if policy.CreatedBy != nil && policy.CreatedBy.Id != nil {
+		output.CreatedBy = &OrgApprovalPolicyV1OutputUser{Id: *policy.CreatedBy.Id, Email: policy.CreatedBy.Email}
+	}
+	if policy.LastUpdatedBy != nil && policy.LastUpdatedBy.Id != nil {
+		output.LastUpdatedBy = &OrgApprovalPolicyV1OutputUser{Id: *policy.LastUpdatedBy.Id, Email: policy.LastUpdatedBy.Email}
+	}
+	return output
+}
+
+type SetOrgApprovalPolicyV1Input struct {
+	// Data is the YAML of an ApprovalPolicy resource
+	Data []byte `json:"data"`
+}
+
+// handleSetOrgApprovalPolicyV1 creates an approval policy in the org or
+// replaces the spec of an existing one with the same name
+func handleSetOrgApprovalPolicyV1(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+	session := r.Context().Value(userAuthRequestContext).(userIdentity)
+
+	orgId := mux.Vars(r)["orgId"]
+	if err := validate.Uuid(orgId); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid org id", types.ErrorInvalidInput)
+		return
+	}
+	bodyData, err := io.ReadAll(r.Body)
+	if err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "failed to get body data", types.ErrorInvalidInput)
+		return
+	}
+	var input SetOrgApprovalPolicyV1Input
+	if err := json.Unmarshal(bodyData, &input); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "failed to parse body data", types.ErrorInvalidInput)
+		return
+	}
+	var policy approvals.Policy
+	if err := yaml.Unmarshal(input.Data, &policy); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "failed to parse approval policy data", types.ErrorInvalidInput)
+		return
+	}
+	if err := policy.Validate(); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, fmt.Sprintf("invalid approval policy: %s", err), types.ErrorInvalidInput)
+		return
+	}
+	policyName := policy.Metadata.Name
+	if err := models.ValidateApprovalPolicyName(policyName); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, err.Error(), types.ErrorInvalidInput)
+		return
+	}
+	log(common.LogLevelDebug, fmt.Sprintf("user[%s] is setting approval policy[%s] in org[%s]", session.UserId, policyName, orgId))
+
+	requiredAction := models.ActionUpdate
+	if _, err := getOrgApprovalPolicy(orgId, policyName); err != nil {
+		if !errors.Is(err, approvals.ErrorPolicyNotFound) {
+			log(common.LogLevelError, fmt.Sprintf("failed to check existence of approval policy[%s] in org[%s]: %s", policyName, orgId, err))
+			common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to set approval policy", types.ErrorDatabaseIssue)
+			return
+		}
+		requiredAction = models.ActionCreate
+	}
+	if isAllowed, err := canUserAccessOrgApprovalPolicies(orgId, session.UserId, requiredAction); err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to check approval policy permissions: %s", err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "not allowed", types.ErrorDatabaseIssue)
+		return
+	} else if !isAllowed {
+		log(common.LogLevelError, fmt.Sprintf("user[%s] is not allowed to set approval policies in org[%s]", session.UserId, orgId))
+		common.SendHttpFailResponse(w, r, http.StatusForbidden, "not allowed", types.ErrorInsufficientPermissions)
+		return
+	}
+
+	org := models.Org{Id: &orgId}
+	approvalPolicy, err := org.SetApprovalPolicyV1(models.SetOrgApprovalPolicyV1Input{
+		DatabaseConnection: models.DatabaseConnection{Db: dbInstance},
+		Name:               policyName,
+		Spec:               policy.Spec,
+		UserId:             session.UserId,
+	})
+	if err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to set approval policy[%s] in org[%s]: %s", policyName, orgId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to set approval policy", types.ErrorDatabaseIssue)
+		return
+	}
+	verb := audit.Update
+	if requiredAction == models.ActionCreate {
+		verb = audit.Create
+	}
+	audit.Log(audit.LogEntry{
+		EntityId:     session.UserId,
+		EntityType:   audit.UserEntity,
+		Verb:         verb,
+		ResourceId:   approvalPolicy.GetId(),
+		ResourceType: audit.ApprovalPolicyResource,
+		Status:       audit.Success,
+		SrcIp:        &session.SourceIp,
+		SrcUa:        &session.UserAgent,
+		DstHost:      &r.Host,
+		Data: map[string]any{
+			"orgId": orgId,
+			"name":  approvalPolicy.Name,
+		},
+	})
+	common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", newOrgApprovalPolicyV1Output(*approvalPolicy))
+}
+
+type ListOrgApprovalPoliciesV1Output []OrgApprovalPolicyV1Output
+
+// handleListOrgApprovalPoliciesV1 returns the approval policies of the org
+func handleListOrgApprovalPoliciesV1(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+	session := r.Context().Value(userAuthRequestContext).(userIdentity)
+
+	orgId := mux.Vars(r)["orgId"]
+	if err := validate.Uuid(orgId); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid org id", types.ErrorInvalidInput)
+		return
+	}
+	if isAllowed, err := canUserAccessOrgApprovalPolicies(orgId, session.UserId, models.ActionView); err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to check approval policy permissions: %s", err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "not allowed", types.ErrorDatabaseIssue)
+		return
+	} else if !isAllowed {
+		log(common.LogLevelError, fmt.Sprintf("user[%s] is not allowed to view approval policies in org[%s]", session.UserId, orgId))
+		common.SendHttpFailResponse(w, r, http.StatusForbidden, "not allowed", types.ErrorInsufficientPermissions)
+		return
+	}
+
+	org := models.Org{Id: &orgId}
+	policies, err := org.ListApprovalPoliciesV1(models.ListOrgApprovalPoliciesV1Input{
+		DatabaseConnection: models.DatabaseConnection{Db: dbInstance},
+	})
+	if err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to list approval policies of org[%s]: %s", orgId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to list approval policies", types.ErrorDatabaseIssue)
+		return
+	}
+	output := make(ListOrgApprovalPoliciesV1Output, 0, len(policies))
+	for _, policy := range policies {
+		output = append(output, newOrgApprovalPolicyV1Output(policy))
+	}
+	common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", output)
+}
+
+// handleGetOrgApprovalPolicyV1 returns an approval policy of the org
+func handleGetOrgApprovalPolicyV1(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+	session := r.Context().Value(userAuthRequestContext).(userIdentity)
+
+	vars := mux.Vars(r)
+	orgId := vars["orgId"]
+	policyName := vars["policyName"]
+	if err := validate.Uuid(orgId); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid org id", types.ErrorInvalidInput)
+		return
+	}
+	if err := models.ValidateApprovalPolicyName(policyName); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid approval policy name", types.ErrorInvalidInput)
+		return
+	}
+	if isAllowed, err := canUserAccessOrgApprovalPolicies(orgId, session.UserId, models.ActionView); err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to check approval policy permissions: %s", err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "not allowed", types.ErrorDatabaseIssue)
+		return
+	} else if !isAllowed {
+		log(common.LogLevelError, fmt.Sprintf("user[%s] is not allowed to view approval policies in org[%s]", session.UserId, orgId))
+		common.SendHttpFailResponse(w, r, http.StatusForbidden, "not allowed", types.ErrorInsufficientPermissions)
+		return
+	}
+
+	policy, err := getOrgApprovalPolicy(orgId, policyName)
+	if err != nil {
+		if errors.Is(err, approvals.ErrorPolicyNotFound) {
+			common.SendHttpFailResponse(w, r, http.StatusNotFound, "approval policy not found", types.ErrorNotFound)
+			return
+		}
+		log(common.LogLevelError, err.Error())
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to get approval policy", types.ErrorDatabaseIssue)
+		return
+	}
+	common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", newOrgApprovalPolicyV1Output(*policy))
+}
+
+// handleDeleteOrgApprovalPolicyV1 deletes an approval policy from the org
+func handleDeleteOrgApprovalPolicyV1(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+	session := r.Context().Value(userAuthRequestContext).(userIdentity)
+
+	vars := mux.Vars(r)
+	orgId := vars["orgId"]
+	policyName := vars["policyName"]
+	if err := validate.Uuid(orgId); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid org id", types.ErrorInvalidInput)
+		return
+	}
+	if err := models.ValidateApprovalPolicyName(policyName); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid approval policy name", types.ErrorInvalidInput)
+		return
+	}
+	if isAllowed, err := canUserAccessOrgApprovalPolicies(orgId, session.UserId, models.ActionDelete); err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to check approval policy permissions: %s", err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "not allowed", types.ErrorDatabaseIssue)
+		return
+	} else if !isAllowed {
+		log(common.LogLevelError, fmt.Sprintf("user[%s] is not allowed to delete approval policies in org[%s]", session.UserId, orgId))
+		common.SendHttpFailResponse(w, r, http.StatusForbidden, "not allowed", types.ErrorInsufficientPermissions)
+		return
+	}
+
+	org := models.Org{Id: &orgId}
+	if err := org.DeleteApprovalPolicyV1(models.DeleteOrgApprovalPolicyV1Input{
+		DatabaseConnection: models.DatabaseConnection{Db: dbInstance},
+		Name:               policyName,
+	}); err != nil {
+		if errors.Is(err, models.ErrorNotFound) {
+			common.SendHttpFailResponse(w, r, http.StatusNotFound, "approval policy not found", types.ErrorNotFound)
+			return
+		}
+		log(common.LogLevelError, fmt.Sprintf("failed to delete approval policy[%s] of org[%s]: %s", policyName, orgId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to delete approval policy", types.ErrorDatabaseIssue)
+		return
+	}
+	audit.Log(audit.LogEntry{
+		EntityId:     session.UserId,
+		EntityType:   audit.UserEntity,
+		Verb:         audit.Delete,
+		ResourceId:   policyName,
+		ResourceType: audit.ApprovalPolicyResource,
+		Status:       audit.Success,
+		SrcIp:        &session.SourceIp,
+		SrcUa:        &session.UserAgent,
+		DstHost:      &r.Host,
+		Data: map[string]any{
+			"orgId": orgId,
+		},
+	})
+	common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok")
+}
@@ -0,0 +1,408 @@
+package controller
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"opsicle/internal/approvals"
+	"opsicle/internal/audit"
+	"opsicle/internal/automations"
+	"opsicle/internal/common"
+	"opsicle/internal/controller/models"
+	"opsicle/internal/types"
+	"opsicle/internal/validate"
+	"opsicle/pkg/approver"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// approvalTokenHeaderKey is the header which carries the token the
+	// approver presents when it calls back with the result of an
+	// approval request
+	approvalTokenHeaderKey = "x-approval-token"
+
+	approvalTokenLength = 64
+)
+
+// getApprovalTokenHash returns the hash of an approval callback token
+// which is what gets stored with the automation
+func getApprovalTokenHash(token string) string {
+	tokenHash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(tokenHash[:])
+}
+
+// ApproverConfig configures the client of the approver service which
+// approval requests for automations are sent to
+type ApproverConfig struct {
+	Url        string
+	BasicAuth  *approver.NewClientBasicAuthOpts
+	BearerAuth *approver.NewClientBearerAuthOpts
+}
+
+// getAutomationApprovalPolicy returns the approval policy which
+// applies to the automation or nil if the automation does not require
+// approval; policies of templates apply to automations run in orgs only
+// when the org has approvals enabled
+func getAutomationApprovalPolicy(automation *models.Automation, orgId *string) (*approvals.PolicySpec, error) {
+	template, err := automation.GetTemplate()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template of automation[%s]: %w", automation.GetId(), err)
+	}
+	approvalPolicy := template.Spec.ApprovalPolicy
+	if approvalPolicy == nil {
+		return nil, nil
+	}
+	if orgId != nil {
+		org := models.Org{Id: orgId}
+		orgConfig, err := org.GetConfigV1(models.DatabaseConnection{Db: dbInstance})
+		if err != nil {
+			return nil, fmt.Errorf("failed to load config of org[%s]: %w", *orgId, err)
+		}
+		if !orgConfig.IsApprovalsEnabled {
+			return nil, nil
+		}
+	}
+	if approvalPolicy.Spec == nil {
+		if approvalPolicy.PolicyRef == nil {
+			return nil, fmt.Errorf("failed to receive an approval policy spec or reference in template[%s]", template.GetName())
+		}
+		// templates submitted before their policy reference was resolved
+		// at submission are resolved against the org they run in
+		if orgId == nil {
+			return nil, fmt.Errorf("failed to resolve approval policy[%s] of template[%s] outside of an org", *approvalPolicy.PolicyRef, template.GetName())
+		}
+		policy, err := getOrgApprovalPolicy(*orgId, *approvalPolicy.PolicyRef)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve approval policy[%s] of template[%s]: %w", *approvalPolicy.PolicyRef, template.GetName(), err)
+		}
+		return &policy.Spec, nil
+	}
+	return approvalPolicy.Spec, nil
+}
+
+type startAutomationOpts struct {
+	Automation *models.Automation
+	Input      map[string]any
+	OrgId      *string
+	UserId     string
+}
+
+type startAutomationOutput struct {
+	AutomationRunId string
+	Status          automations.RunStatusCode
+}
+
+// startAutomation submits the pending automation to the queue, if an
+// approval policy applies to it the automation is instead held in
+// status `pending-approval` until the approval request created for it
+// is resolved through handleResolveAutomationApprovalV1
+func startAutomation(opts startAutomationOpts) (*startAutomationOutput, error) {
+	approvalPolicy, err := getAutomationApprovalPolicy(opts.Automation, opts.OrgId)
+	if err != nil {
+		return nil, err
+	}
+	if approvalPolicy == nil {
+		queuedAutomationRun, err := opts.Automation.RunV1(models.QueueAutomationRunV1Opts{
+			Db: dbInstance,
+			Q:  queueInstance,
+
+			Input: opts.Input,
+			OrgId: opts.OrgId,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &startAutomationOutput{
+			AutomationRunId: queuedAutomationRun.AutomationRunId,
+			Status:          automations.RunStatusPendingExecution,
+		}, nil
+	}
+	if err := requestAutomationApproval(opts, *approvalPolicy); err != nil {
+		return nil, err
+	}
+	return &startAutomationOutput{
+		AutomationRunId: opts.Automation.GetId(),
+		Status:          automations.RunStatusPendingApproval,
+	}, nil
+}
+
+// requestAutomationApproval holds the automation for approval and
+// creates an approval request with the targets of `policy` which calls
+// back to the controller when it is resolved
+func requestAutomationApproval(opts startAutomationOpts, policy approvals.PolicySpec) error {
+	if approverClient == nil {
+		return fmt.Errorf("failed to request approval of automation[%s]: %w", opts.Automation.GetId(), ErrorApproverNotConfigured)
+	}
+	template, err := opts.Automation.GetTemplate()
+	if err != nil {
+		return fmt.Errorf("failed to parse template of automation[%s]: %w", opts.Automation.GetId(), err)
+	}
+	requester := models.User{Id: &opts.UserId}
+	if err := requester.LoadByIdV1(models.DatabaseConnection{Db: dbInstance}); err != nil {
+		return fmt.Errorf("failed to load user[%s]: %w", opts.UserId, err)
+	}
+	identities, err := requester.GetPlatformIdentitiesV1(models.DatabaseConnection{Db: dbInstance})
+	if err != nil {
+		return fmt.Errorf("failed to load platform identities of user[%s]: %w", opts.UserId, err)
+	}
+	approverIdentities, err := getApproverIdentities(opts.UserId, identities, opts.OrgId)
+	if err != nil {
+		return err
+	}
+	var requesterIdentities *approvals.RequesterIdentitiesSpec
+	if policy.DisallowSelfApproval {
+		// the requester could approve their own request if none of their
+		// users can be recognised so the request is not created
+		if len(identities.SlackIds) == 0 && len(identities.TelegramIds) == 0 {
+			return fmt.Errorf("%w: user[%s] has no linked slack or telegram users which are required to prevent self-approval of automation[%s]", types.ErrorInvalidInput, opts.UserId, opts.Automation.GetId())
+		}
+		requesterIdentities = &approvals.RequesterIdentitiesSpec{
+			SlackUserIds:    identities.SlackIds,
+			TelegramUserIds: identities.TelegramIds,
+		}
+	}
+	if err := opts.Automation.HoldForApprovalV1(models.QueueAutomationRunV1Opts{
+		Db: dbInstance,
+		Q:  queueInstance,
+
+		Input: opts.Input,
+		OrgId: opts.OrgId,
+	}); err != nil {
+		return err
+	}
+
+	automationId := opts.Automation.GetId()
+	approvalToken, err := generateApiKey(approvalTokenLength)
+	if err != nil {
+		return fmt.Errorf("failed to generate approval token for automation[%s]: %w", automationId, err)
+	}
+	callbackUrl := *publicServerUrl
+	callbackUrl.Path = fmt.Sprintf("/api/v1/automation/%s/approval", automationId)
+	owners := []string{}
+	for _, owner := range template.Spec.Metadata.Owners {
+		owners = append(owners, fmt.Sprintf("%s (%s)", owner.Name, owner.Email))
+	}
+	message := fmt.Sprintf(
+		"Requesting to execute '%s' as automation[%s]",
+		template.GetName(),
+		automationId,
+	)
+	if len(owners) > 0 {
+		message += fmt.Sprintf(" written by: %s", strings.Join(owners, ", "))
+	}
+	if opts.Automation.TriggererComment != "" {
+		message += fmt.Sprintf("\n\nComment: %s", opts.Automation.TriggererComment)
+	}
+	approvalRequest := approver.CreateApprovalRequestInput{
+		ApproverIdentities: approverIdentities,
+		Callback: &approvals.CallbackSpec{
+			Type: approvals.CallbackWebhook,
+			Webhook: &approvals.WebhookCallbackSpec{
+				Method: http.MethodPost,
+				Url:    callbackUrl.String(),
+				Auth: &approvals.WebhookCallbackAuthSpec{
+					Header: &approvals.WebhookCallbackHeaderAuthSpec{
+						Key:   approvalTokenHeaderKey,
+						Value: approvalToken,
+					},
+				},
+			},
+		},
+		DisallowSelfApproval: policy.DisallowSelfApproval,
+		Escalation:           policy.GetRequestEscalation(),
+		Id:                   template.GetName(),
+		Message:              message,
+		MinApprovals:         policy.MinApprovals,
+		Reminders:            policy.Reminders,
+		RequesterId:          opts.UserId,
+		RequesterIdentities:  requesterIdentities,
+		RequesterName:        requester.Email,
+		Stages:               policy.GetRequestStages(),
+		TtlSeconds:           policy.TtlSeconds,
+	}
+	if policy.Slack != nil {
+		approvalRequest.Slack = []approvals.SlackRequestSpec{*policy.Slack}
+	}
+	if policy.Telegram != nil {
+		approvalRequest.Telegram = []approvals.TelegramRequestSpec{*policy.Telegram}
+	}
+	approvalRequestId, err := approverClient.CreateApprovalRequest(approvalRequest)
+	if err == nil {
+		err = opts.Automation.SetApprovalRequestV1(models.DatabaseConnection{Db: dbInstance}, approvalRequestId, getApprovalTokenHash(approvalToken))
+	}
+	if err != nil {
+		if updateErr := opts.Automation.UpdateStatusV1(models.UpdateAutomationStatusV1Opts{
+			Db: models.DatabaseConnection{Db: dbInstance},
+			Update: automations.RunStatusUpdate{
+				AutomationId: automationId,
+				Status:       automations.RunStatusCompletedFailed,
+				Message:      "failed to request approval",
+			},
+		}); updateErr != nil {
+			return fmt.Errorf("failed to create approval request for automation[%s]: %w (failed to update status: %w)", automationId, err, updateErr)
+		}
+		return fmt.Errorf("failed to create approval request for automation[%s]: %w", automationId, err)
+	}
+	return nil
+}
+
+type ResolveAutomationApprovalV1Output struct {
+	AutomationId string `json:"automationId"`
+	Status       string `json:"status"`
+}
+
+// handleResolveAutomationApprovalV1 is an internal endpoint which the
+// approver calls back when the approval request of an automation held
+// for approval is resolved; approved automations are submitted to the
+// queue and rejected or expired automations are completed with the
+// status `rejected`; the approver authenticates with the token which was
+// issued with the approval request of the automation
+func handleResolveAutomationApprovalV1(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+
+	vars := mux.Vars(r)
+	automationId := vars["automationId"]
+	if err := validate.Uuid(automationId); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid automation id", types.ErrorInvalidInput)
+		return
+	}
+	bodyData, err := io.ReadAll(r.Body)
+	if err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "failed to get body data", types.ErrorInvalidInput)
+		return
+	}
+	var approval approvals.ApprovalSpec
+	if err := json.Unmarshal(bodyData, &approval); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "failed to parse body data", types.ErrorInvalidInput)
+		return
+	}
+	log(common.LogLevelDebug, fmt.Sprintf("received approval[%s] with status[%s] for automation[%s]", approval.Id, approval.Status, automationId))
+
+	automation := models.Automation{Id: &automationId}
+	if err := automation.LoadV1(models.DatabaseConnection{Db: dbInstance}); err != nil {
+		if errors.Is(err, models.ErrorNotFound) {
+			common.SendHttpFailResponse(w, r, http.StatusNotFound, "automation not found", types.ErrorNotFound)
+			return
+		}
+		log(common.LogLevelError, fmt.Sprintf("failed to load automation[%s]: %s", automationId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to retrieve automation", types.ErrorDatabaseIssue)
+		return
+	}
+	approvalToken := r.Header.Get(approvalTokenHeaderKey)
+	if approvalToken == "" || automation.ApprovalTokenHash == nil || subtle.ConstantTimeCompare([]byte(getApprovalTokenHash(approvalToken)), []byte(*automation.ApprovalTokenHash)) != 1 {
+		log(common.LogLevelWarn, fmt.Sprintf("received unauthenticated approval callback for automation[%s] from %s", automationId, r.RemoteAddr))
+		common.SendHttpFailResponse(w, r, http.StatusUnauthorized, "invalid approval token", types.ErrorInvalidCredentials)
+		return
+	}
+	if automation.ApprovalRequestId == nil || *automation.ApprovalRequestId != approval.RequestUuid {
+		log(common.LogLevelError, fmt.Sprintf("approval of request[%s] does not belong to automation[%s]", approval.RequestUuid, automationId))
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "approval does not belong to automation", types.ErrorInvalidInput)
+		return
+	}
+
+	auditEntry := audit.LogEntry{
+		EntityId:     approval.ApproverId,
+		EntityType:   audit.ApproverEntity,
+		ResourceId:   automationId,
+		ResourceType: audit.AutomationResource,
+		Status:       audit.Success,
+		SrcIp:        &r.RemoteAddr,
+		DstHost:      &r.Host,
+		Data: map[string]any{
+			"approvalId":        approval.Id,
+			"approvalRequestId": approval.RequestUuid,
+			"approverName":      approval.ApproverName,
+			"platform":          approval.Type,
+		},
+	}
+	resolveOpts := models.ResolveAutomationApprovalV1Opts{
+		Db: dbInstance,
+		Q:  queueInstance,
+	}
+	switch approval.Status {
+	case approvals.StatusApproved:
+		auditEntry.Verb = audit.Accept
+		resolveOpts.Message = fmt.Sprintf("approved by %s", approval.ApproverName)
+		_, err = automation.RunApprovedV1(resolveOpts)
+	case approvals.StatusRejected:
+		auditEntry.Verb = audit.Reject
+		resolveOpts.Message = fmt.Sprintf("rejected by %s", approval.ApproverName)
+		err = automation.RejectV1(resolveOpts)
+	case approvals.StatusExpired:
+		auditEntry.Verb = audit.Reject
+		auditEntry.Data["reason"] = string(approvals.StatusExpired)
+		resolveOpts.Message = fmt.Sprintf("approval request expired at %s", approval.StatusUpdatedAt.Format(time.RFC3339))
+		err = automation.RejectV1(resolveOpts)
+	default:
+		log(common.LogLevelInfo, fmt.Sprintf("ignoring approval[%s] with status[%s] for automation[%s]", approval.Id, approval.Status, automationId))
+		common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", ResolveAutomationApprovalV1Output{
+			AutomationId: automationId,
+			Status:       automation.LastKnownStatus,
+		})
+		return
+	}
+	if err != nil {
+		// callbacks are retried until they succeed so automations
+		// which were already resolved or cancelled are acknowledged
+		if errors.Is(err, models.ErrorAutomationNotPendingApproval) {
+			log(common.LogLevelWarn, fmt.Sprintf("automation[%s] is no longer pending approval, ignoring approval[%s]", automationId, approval.Id))
+			common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", ResolveAutomationApprovalV1Output{
+				AutomationId: automationId,
+				Status:       automation.LastKnownStatus,
+			})
+			return
+		}
+		auditEntry.Status = audit.Failed
+		audit.Log(auditEntry)
+		log(common.LogLevelError, fmt.Sprintf("failed to resolve approval of automation[%s]: %s", automationId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to resolve approval", types.ErrorQueueIssue)
+		return
+	}
+	audit.Log(auditEntry)
+	log(common.LogLevelInfo, fmt.Sprintf("automation[%s] was %s", automationId, resolveOpts.Message))
+	common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", ResolveAutomationApprovalV1Output{
+		AutomationId: automationId,
+		Status:       automation.LastKnownStatus,
+	})
+}
+
+// getApproverIdentities returns the platform users of the members of
+// the org with ID `orgId`, or only of the requester outside of an org,
+// so that a member who responds from more than one platform is counted
+// as a single approver
+func getApproverIdentities(requesterId string, requesterIdentities *models.UserPlatformIdentities, orgId *string) ([]approvals.ApproverIdentitySpec, error) {
+	memberIdentities := map[string]*models.UserPlatformIdentities{}
+	if orgId != nil {
+		org := models.Org{Id: orgId}
+		var err error
+		memberIdentities, err = org.GetMemberPlatformIdentitiesV1(models.DatabaseConnection{Db: dbInstance})
+		if err != nil {
+			return nil, fmt.Errorf("failed to load platform identities of members of org[%s]: %w", *orgId, err)
+		}
+	}
+	memberIdentities[requesterId] = requesterIdentities
+	approverIdentities := []approvals.ApproverIdentitySpec{}
+	for userId, identities := range memberIdentities {
+		if len(identities.SlackIds) == 0 && len(identities.TelegramIds) == 0 {
+			continue
+		}
+		approverIdentities = append(approverIdentities, approvals.ApproverIdentitySpec{
+			UserId:          userId,
+			SlackUserIds:    identities.SlackIds,
+			TelegramUserIds: identities.TelegramIds,
+		})
+	}
+	slices.SortFunc(approverIdentities, func(a, b approvals.ApproverIdentitySpec) int {
+		return strings.Compare(a.UserId, b.UserId)
+	})
+	return approverIdentities, nil
+}
@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"opsicle/internal/automations"
+	"opsicle/internal/common"
+	"opsicle/internal/controller/models"
+	"opsicle/internal/types"
+	"opsicle/internal/validate"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type UploadAutomationArtifactsV1Input struct {
+	OrgId *string `json:"orgId"`
+
+	// Content is the gzipped tarball of the artifacts
+	Content []byte `json:"content"`
+}
+
+type UploadAutomationArtifactsV1Output struct {
+	Size     int64  `json:"size"`
+	Checksum string `json:"checksum"`
+}
+
+// handleUploadAutomationArtifactsV1 is an internal endpoint used by the
+// coordinator to store the artifacts collected by workers
+func handleUploadAutomationArtifactsV1(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+
+	automationId := mux.Vars(r)["automationId"]
+	if err := validate.Uuid(automationId); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid automation id", types.ErrorInvalidInput)
+		return
+	}
+
+	bodyData, err := io.ReadAll(http.MaxBytesReader(w, r.Body, automations.MaxArtifactsRequestSize))
+	if err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusRequestEntityTooLarge, "failed to get body data", types.ErrorInvalidInput)
+		return
+	}
+	var input UploadAutomationArtifactsV1Input
+	if err := json.Unmarshal(bodyData, &input); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "failed to parse body data", types.ErrorInvalidInput)
+		return
+	}
+
+	automation := models.Automation{Id: &automationId}
+	if err := automation.LoadV1(models.DatabaseConnection{Db: dbInstance}); err != nil {
+		if errors.Is(err, models.ErrorNotFound) {
+			common.SendHttpFailResponse(w, r, http.StatusNotFound, "automation not found", types.ErrorNotFound)
+			return
+		}
+		log(common.LogLevelError, fmt.Sprintf("failed to load automation[%s]: %s", automationId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to retrieve automation", types.ErrorDatabaseIssue)
+		return
+	}
+	automationOrgId, inputOrgId := "", ""
+	if automation.OrgId != nil {
+		automationOrgId = *automation.OrgId
+	}
+	if input.OrgId != nil {
+		inputOrgId = *input.OrgId
+	}
+	if automationOrgId != inputOrgId {
+		log(common.LogLevelWarn, fmt.Sprintf("rejected artifacts for automation[%s] from org[%s]", automationId, inputOrgId))
+		common.SendHttpFailResponse(w, r, http.StatusForbidden, "org mismatch", types.ErrorInsufficientPermissions)
+		return
+	}
+
+	artifacts, err := automation.SetArtifactsV1(models.SetAutomationArtifactsV1Opts{
+		Db:      models.DatabaseConnection{Db: dbInstance},
+		Content: input.Content,
+	})
+	if err != nil {
+		if errors.Is(err, models.ErrorInvalidInput) {
+			common.SendHttpFailResponse(w, r, http.StatusBadRequest, err.Error(), types.ErrorInvalidInput)
+			return
+		}
+		log(common.LogLevelError, fmt.Sprintf("failed to store artifacts of automation[%s]: %s", automationId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to store artifacts", types.ErrorDatabaseIssue)
+		return
+	}
+	log(common.LogLevelDebug, fmt.Sprintf("stored %v bytes of artifacts for automation[%s]", artifacts.Size, automationId))
+	common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", UploadAutomationArtifactsV1Output{
+		Size:     artifacts.Size,
+		Checksum: artifacts.Checksum,
+	})
+}
+
+type GetAutomationArtifactsV1Output struct {
+	// Content is the gzipped tarball of the artifacts
+	Content   []byte    `json:"content"`
+	Size      int64     `json:"size"`
+	Checksum  string    `json:"checksum"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// handleGetAutomationArtifactsV1 returns the artifacts collected from
+// the phases of an automation run
+func handleGetAutomationArtifactsV1(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+	session := r.Context().Value(userAuthRequestContext).(userIdentity)
+
+	automationId := mux.Vars(r)["automationId"]
+	if err := validate.Uuid(automationId); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid automation id", types.ErrorInvalidInput)
+		return
+	}
+
+	automation := models.Automation{Id: &automationId}
+	if err := automation.LoadV1(models.DatabaseConnection{Db: dbInstance}); err != nil {
+		if errors.Is(err, models.ErrorNotFound) {
+			common.SendHttpFailResponse(w, r, http.StatusNotFound, "automation not found", types.ErrorNotFound)
+			return
+		}
+		log(common.LogLevelError, fmt.Sprintf("failed to load automation[%s]: %s", automationId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to retrieve automation", types.ErrorDatabaseIssue)
+		return
+	}
+	if canView, err := canUserViewAutomation(&automation, session.UserId, models.ResourceAutomations); err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to check permissions of user[%s] on automation[%s]: %s", session.UserId, automationId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to retrieve automation artifacts", types.ErrorDatabaseIssue)
+		return
+	} else if !canView {
+		log(common.LogLevelError, fmt.Sprintf("user[%s] is not allowed to view artifacts of automation[%s]", session.UserId, automationId))
+		common.SendHttpFailResponse(w, r, http.StatusForbidden, "not allowed", types.ErrorInsufficientPermissions)
+		return
+	}
+
+	artifacts, err := automation.GetArtifactsV1(models.DatabaseConnection{Db: dbInstance})
+	if err != nil {
+		if errors.Is(err, models.ErrorNotFound) {
+			common.SendHttpFailResponse(w, r, http.StatusNotFound, "automation has no artifacts", types.ErrorNotFound)
+			return
+		}
+		log(common.LogLevelError, fmt.Sprintf("failed to retrieve artifacts of automation[%s]: %s", automationId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to retrieve automation artifacts", types.ErrorDatabaseIssue)
+		return
+	}
+	common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", GetAutomationArtifactsV1Output{
+		Content:   artifacts.Content,
+		Size:      artifacts.Size,
+		Checksum:  artifacts.Checksum,
+		CreatedAt: artifacts.CreatedAt,
+	})
+}
@@ -0,0 +1,227 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"opsicle/internal/automations"
+	"opsicle/internal/common"
+	"opsicle/internal/controller/models"
+	"opsicle/internal/types"
+	"opsicle/internal/validate"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// canUserAccessOrgAutomations returns true if the user identified by
+// `userId` is allowed to perform `action` on automations of the org
+// identified by `orgId`
+func canUserAccessOrgAutomations(orgId, userId string, action models.Action) (bool, error) {
+	org := models.Org{Id: &orgId}
+	orgUser, err := org.GetUserV1(models.GetOrgUserV1Opts{Db: dbInstance, UserId: userId})
+	if err != nil {
+		if errors.Is(err, models.ErrorNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to load org user[%s] in org[%s]: %w", userId, orgId, err)
+	}
+	_, _, isAllowed, err := orgUser.CanV1(models.DatabaseConnection{Db: dbInstance}, models.ResourceAutomations, action)
+	if err != nil {
+		return false, fmt.Errorf("failed to check permissions of user[%s] in org[%s]: %w", userId, orgId, err)
+	}
+	return isAllowed, nil
+}
+
+type ListAutomationsV1Output struct {
+	Automations []ListAutomationsV1OutputAutomation `json:"automations"`
+
+	// NextCursor should be passed as the `cursor` query parameter to
+	// retrieve the next page, this is null on the last page
+	NextCursor *string `json:"nextCursor"`
+}
+
+type ListAutomationsV1OutputAutomation struct {
+	Id                 string     `json:"id"`
+	OrgId              *string    `json:"orgId"`
+	ParentAutomationId *string    `json:"parentAutomationId"`
+	TemplateId         string     `json:"templateId"`
+	TemplateName       string     `json:"templateName"`
+	TemplateVersion    int64      `json:"templateVersion"`
+	Status             string     `json:"status"`
+	TriggeredById      string     `json:"triggeredById"`
+	TriggeredByEmail   string     `json:"triggeredByEmail"`
+	TriggeredAt        time.Time  `json:"triggeredAt"`
+	TriggererComment   string     `json:"triggererComment"`
+	StartedAt          *time.Time `json:"startedAt"`
+	CompletedAt        *time.Time `json:"completedAt"`
+}
+
+// handleListAutomationsV1 returns a page of the automation runs
+// triggered by the requesting user, latest first; see
+// parseListAutomationsV1Query for the supported query parameters
+func handleListAutomationsV1(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+	session := r.Context().Value(userAuthRequestContext).(userIdentity)
+
+	opts, err := parseListAutomationsV1Query(r)
+	if err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, err.Error(), types.ErrorInvalidInput)
+		return
+	}
+	if opts.TriggeredBy != nil && *opts.TriggeredBy != session.UserId {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "triggeredBy can only be used when listing automations of an org", types.ErrorInvalidInput)
+		return
+	}
+	opts.TriggeredBy = &session.UserId
+	log(common.LogLevelDebug, fmt.Sprintf("listing automations of user[%s]", session.UserId))
+
+	listAutomations(w, r, opts)
+}
+
+// handleListOrgAutomationsV1 returns a page of the automation runs of
+// the org, latest first; see parseListAutomationsV1Query for the
+// supported query parameters
+func handleListOrgAutomationsV1(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+	session := r.Context().Value(userAuthRequestContext).(userIdentity)
+
+	orgId := mux.Vars(r)["orgId"]
+	if err := validate.Uuid(orgId); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid org id", types.ErrorInvalidInput)
+		return
+	}
+	opts, err := parseListAutomationsV1Query(r)
+	if err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, err.Error(), types.ErrorInvalidInput)
+		return
+	}
+	if isAllowed, err := canUserAccessOrgAutomations(orgId, session.UserId, models.ActionView); err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to check automation permissions: %s", err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "not allowed", types.ErrorDatabaseIssue)
+		return
+	} else if !isAllowed {
+		log(common.LogLevelError, fmt.Sprintf("user[%s] is not allowed to view automations in org[%s]", session.UserId, orgId))
+		common.SendHttpFailResponse(w, r, http.StatusForbidden, "not allowed", types.ErrorInsufficientPermissions)
+		return
+	}
+	opts.OrgId = &orgId
+	log(common.LogLevelDebug, fmt.Sprintf("user[%s] is listing automations of org[%s]", session.UserId, orgId))
+
+	listAutomations(w, r, opts)
+}
+
+// listAutomations sends a page of the automations matching `opts` as
+// the response
+func listAutomations(w http.ResponseWriter, r *http.Request, opts models.ListAutomationsV1Opts) {
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+
+	opts.DatabaseConnection = models.DatabaseConnection{Db: dbInstance}
+	automationsOutput, err := models.ListAutomationsV1(opts)
+	if err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to list automations: %s", err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to list automations", types.ErrorDatabaseIssue)
+		return
+	}
+	output := ListAutomationsV1Output{Automations: []ListAutomationsV1OutputAutomation{}}
+	for _, automation := range automationsOutput.Automations {
+		outputItem := ListAutomationsV1OutputAutomation{
+			Id:                 *automation.Id,
+			OrgId:              automation.OrgId,
+			ParentAutomationId: automation.ParentAutomationId,
+			TemplateId:         automation.TemplateId,
+			TemplateName:       automation.TemplateName,
+			TemplateVersion:    automation.TemplateVersion,
+			Status:             automation.LastKnownStatus,
+			TriggeredAt:        automation.TriggeredAt,
+			TriggererComment:   automation.TriggererComment,
+			StartedAt:          automation.StartedAt,
+			CompletedAt:        automation.CompletedAt,
+		}
+		if automation.TriggeredBy != nil {
+			outputItem.TriggeredById = automation.TriggeredBy.GetId()
+			outputItem.TriggeredByEmail = automation.TriggeredBy.Email
+		}
+		output.Automations = append(output.Automations, outputItem)
+	}
+	if automationsOutput.NextCursor != nil {
+		nextCursor := automationsOutput.NextCursor.String()
+		output.NextCursor = &nextCursor
+	}
+	common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", output)
+}
+
+// parseListAutomationsV1Query parses the filters and pagination of an
+// automations listing from the query parameters:
+//   - `templateId`: ID of the template which automations were run from
+//   - `status`: status which automations are in
+//   - `triggeredBy`: ID or email of the user who triggered automations
+//   - `since`/`until`: RFC3339 timestamps bounding when automations
+//     were triggered
+//   - `comment`: text contained in the triggerer's comment
+//   - `cursor`: the `nextCursor` of the previous page
+//   - `limit`: maximum number of automations to return
+func parseListAutomationsV1Query(r *http.Request) (models.ListAutomationsV1Opts, error) {
+	query := r.URL.Query()
+	opts := models.ListAutomationsV1Opts{}
+	if templateId := query.Get("templateId"); templateId != "" {
+		if err := validate.Uuid(templateId); err != nil {
+			return opts, fmt.Errorf("invalid template id")
+		}
+		opts.TemplateId = &templateId
+	}
+	if status := query.Get("status"); status != "" {
+		if !slices.Contains(automations.RunStatusCodes, automations.RunStatusCode(status)) {
+			return opts, fmt.Errorf("invalid status, must be one of %v", automations.RunStatusCodes)
+		}
+		opts.Status = &status
+	}
+	if triggeredBy := strings.TrimSpace(query.Get("triggeredBy")); triggeredBy != "" {
+		if err := validate.Uuid(triggeredBy); err != nil {
+			user := models.User{Email: triggeredBy}
+			if err := user.LoadByEmailV1(models.DatabaseConnection{Db: dbInstance}); err != nil {
+				return opts, fmt.Errorf("triggeredBy must be the id or email of an existing user")
+			}
+			triggeredBy = user.GetId()
+		}
+		opts.TriggeredBy = &triggeredBy
+	}
+	if since := query.Get("since"); since != "" {
+		timestamp, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			return opts, fmt.Errorf("since must be an RFC3339 timestamp")
+		}
+		opts.Since = &timestamp
+	}
+	if until := query.Get("until"); until != "" {
+		timestamp, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			return opts, fmt.Errorf("until must be an RFC3339 timestamp")
+		}
+		opts.Until = &timestamp
+	}
+	if opts.Since != nil && opts.Until != nil && !opts.Until.After(*opts.Since) {
+		return opts, fmt.Errorf("until must be after since")
+	}
+	if comment := strings.TrimSpace(query.Get("comment")); comment != "" {
+		opts.Comment = &comment
+	}
+	if cursor := query.Get("cursor"); cursor != "" {
+		parsedCursor, err := models.ParseAutomationCursor(cursor)
+		if err != nil {
+			return opts, fmt.Errorf("invalid cursor")
+		}
+		opts.Cursor = parsedCursor
+	}
+	if limit := query.Get("limit"); limit != "" {
+		parsedLimit, err := strconv.Atoi(limit)
+		if err != nil || parsedLimit <= 0 || parsedLimit > models.MaxAutomationListLimit {
+			return opts, fmt.Errorf("limit must be between 1 and %v", models.MaxAutomationListLimit)
+		}
+		opts.Limit = parsedLimit
+	}
+	return opts, nil
+}
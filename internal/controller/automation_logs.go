@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"opsicle/internal/automations"
+	"opsicle/internal/common"
+	"opsicle/internal/controller/models"
+	"opsicle/internal/queue"
+	"opsicle/internal/validate"
+	"sync"
+	"time"
+)
+
+const (
+	automationLogsConsumerId         = "controller-automation-logs"
+	automationLogsConsumerRetryDelay = 5 * time.Second
+
+	// maxAutomationLogsWaitDuration is the maximum duration a request
+	// for logs can be held open for, this must be lower than the
+	// server's write timeout
+	maxAutomationLogsWaitDuration = 5 * time.Second
+
+	// automationLogsRecheckInterval is how often requests waiting for
+	// logs check the database, this picks up logs which were appended
+	// by the consumer of another controller instance
+	automationLogsRecheckInterval = 2 * time.Second
+)
+
+var (
+	automationLogsWaiters      = map[string]map[chan struct{}]struct{}{}
+	automationLogsWaitersMutex sync.Mutex
+)
+
+// waitForAutomationLogs returns a channel which is closed once logs of
+// the automation identified by `automationId` are appended by this
+// controller instance, the returned function must be called when the
+// caller stops waiting
+func waitForAutomationLogs(automationId string) (<-chan struct{}, func()) {
+	logsAppended := make(chan struct{})
+	automationLogsWaitersMutex.Lock()
+	defer automationLogsWaitersMutex.Unlock()
+	if automationLogsWaiters[automationId] == nil {
+		automationLogsWaiters[automationId] = map[chan struct{}]struct{}{}
+	}
+	automationLogsWaiters[automationId][logsAppended] = struct{}{}
+	return logsAppended, func() {
+		automationLogsWaitersMutex.Lock()
+		defer automationLogsWaitersMutex.Unlock()
+		if _, exists := automationLogsWaiters[automationId][logsAppended]; !exists {
+			return
+		}
+		delete(automationLogsWaiters[automationId], logsAppended)
+		if len(automationLogsWaiters[automationId]) == 0 {
+			delete(automationLogsWaiters, automationId)
+		}
+	}
+}
+
+// notifyAutomationLogsWaiters wakes up all requests waiting for logs of
+// the automation identified by `automationId`
+func notifyAutomationLogsWaiters(automationId string) {
+	automationLogsWaitersMutex.Lock()
+	defer automationLogsWaitersMutex.Unlock()
+	for logsAppended := range automationLogsWaiters[automationId] {
+		close(logsAppended)
+	}
+	delete(automationLogsWaiters, automationId)
+}
+
+// startAutomationLogsConsumer consumes logs of automation runs from the
+// logs queue and appends them to the automation's logs in the database
+// until the provided context is cancelled
+func startAutomationLogsConsumer(ctx context.Context) {
+	for {
+		err := queueInstance.Subscribe(queue.SubscribeOpts{
+			ConsumerId: automationLogsConsumerId,
+			Context:    ctx,
+			Handler:    handleAutomationLogsMessage,
+			Queue:      automations.GetAllLogsQueue(),
+			Stream:     automations.GetLogsStreamOpts(),
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		*serviceLogs <- common.ServiceLogf(common.LogLevelError, "automation logs consumer stopped, restarting in %v: %s", automationLogsConsumerRetryDelay, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(automationLogsConsumerRetryDelay):
+		}
+	}
+}
+
+func handleAutomationLogsMessage(ctx context.Context, message queue.Message) error {
+	var logChunk automations.LogChunk
+	if err := json.Unmarshal(message.Data, &logChunk); err != nil {
+		*serviceLogs <- common.ServiceLogf(common.LogLevelWarn, "dropping malformed logs from subject[%s]: %s", message.Subject, err)
+		return nil
+	}
+	if err := validate.Uuid(logChunk.AutomationId); err != nil {
+		*serviceLogs <- common.ServiceLogf(common.LogLevelWarn, "dropping logs with invalid automation id from subject[%s]: %s", message.Subject, err)
+		return nil
+	}
+	automation := models.Automation{Id: &logChunk.AutomationId}
+	if err := automation.AppendLogsV1(models.AppendAutomationLogsV1Opts{
+		Db:    models.DatabaseConnection{Db: dbInstance},
+		Logs:  logChunk.Data,
+		OrgId: logChunk.OrgId,
+	}); err != nil {
+		if errors.Is(err, models.ErrorNotFound) {
+			*serviceLogs <- common.ServiceLogf(common.LogLevelWarn, "dropping logs for unknown automation[%s]: %s", logChunk.AutomationId, err)
+			return nil
+		}
+		return fmt.Errorf("failed to append logs of automation[%s]: %w", logChunk.AutomationId, err)
+	}
+	notifyAutomationLogsWaiters(logChunk.AutomationId)
+	return nil
+}
@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"opsicle/internal/automations"
+	"opsicle/internal/common"
+	"opsicle/internal/controller/models"
+	"opsicle/internal/types"
+	"opsicle/internal/validate"
+
+	"github.com/gorilla/mux"
+)
+
+type RerunAutomationV1Input struct {
+	Comment string `json:"comment"`
+
+	// FromPhase when defined resumes the new run from the phase with
+	// this name, phases which complete before it reuse their results
+	// from the previous run
+	FromPhase string `json:"fromPhase"`
+}
+
+type RerunAutomationV1Output struct {
+	CreateAutomationV1OutputData
+
+	ParentAutomationId string `json:"parentAutomationId"`
+	FromPhase          string `json:"fromPhase"`
+
+	// VariableValues are the values of the previous run's variables
+	// which the new run uses unless they are overridden, values of
+	// secret variables are redacted
+	VariableValues map[string]any `json:"variableValues"`
+}
+
+// handleRerunAutomationV1 creates a pending automation from the
+// template content, template version and variables of a completed
+// automation run; the pending automation is run with the usual
+// endpoint and records the completed run as its parent
+func handleRerunAutomationV1(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+	session := r.Context().Value(userAuthRequestContext).(userIdentity)
+
+	vars := mux.Vars(r)
+	parentAutomationId := vars["automationId"]
+	if err := validate.Uuid(parentAutomationId); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid automation id", types.ErrorInvalidInput)
+		return
+	}
+	var input RerunAutomationV1Input
+	bodyData, err := io.ReadAll(r.Body)
+	if err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "failed to get body data", types.ErrorInvalidInput)
+		return
+	}
+	if len(bodyData) > 0 {
+		if err := json.Unmarshal(bodyData, &input); err != nil {
+			common.SendHttpFailResponse(w, r, http.StatusBadRequest, "failed to parse body data", types.ErrorInvalidInput)
+			return
+		}
+	}
+	log(common.LogLevelDebug, fmt.Sprintf("user[%s] is preparing to re-run automation[%s]", session.UserId, parentAutomationId))
+
+	parentAutomation := models.Automation{Id: &parentAutomationId}
+	if err := parentAutomation.LoadV1(models.DatabaseConnection{Db: dbInstance}); err != nil {
+		if errors.Is(err, models.ErrorNotFound) {
+			common.SendHttpFailResponse(w, r, http.StatusNotFound, "automation not found", types.ErrorNotFound)
+			return
+		}
+		log(common.LogLevelError, fmt.Sprintf("failed to load automation[%s]: %s", parentAutomationId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to retrieve automation", types.ErrorDatabaseIssue)
+		return
+	}
+	if canView, err := canUserViewAutomation(&parentAutomation, session.UserId, models.ResourceAutomations); err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to check permissions of user[%s] on automation[%s]: %s", session.UserId, parentAutomationId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to retrieve automation", types.ErrorDatabaseIssue)
+		return
+	} else if !canView {
+		log(common.LogLevelError, fmt.Sprintf("user[%s] is not allowed to view automation[%s]", session.UserId, parentAutomationId))
+		common.SendHttpFailResponse(w, r, http.StatusForbidden, "not allowed", types.ErrorInsufficientPermissions)
+		return
+	}
+	if status := automations.RunStatusCode(parentAutomation.LastKnownStatus); !status.IsFinal() {
+		common.SendHttpFailResponse(w, r, http.StatusConflict, fmt.Sprintf("automation is still in status[%s]", status), types.ErrorInvalidInput)
+		return
+	}
+
+	templateId := parentAutomation.TemplateId
+	template, err := models.GetTemplateV1(models.GetTemplateV1Opts{
+		Db:         dbInstance,
+		TemplateId: &templateId,
+		UserId:     session.UserId,
+	})
+	if err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to get template[%s]: %s", templateId, err))
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid template", types.ErrorInvalidInput)
+		return
+	}
+	if canExecute, err := canUserExecuteTemplate(template, parentAutomation.OrgId, session.UserId); err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to check permissions of user[%s] on template[%s]: %s", session.UserId, templateId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "not allowed", types.ErrorDatabaseIssue)
+		return
+	} else if !canExecute {
+		log(common.LogLevelError, fmt.Sprintf("user[%s] is not allowed to execute template[%s]", session.UserId, templateId))
+		common.SendHttpFailResponse(w, r, http.StatusUnauthorized, "not allowed", types.ErrorInsufficientPermissions)
+		return
+	}
+
+	sourceTemplate, err := parentAutomation.GetTemplate()
+	if err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to parse template content for automation[%s]: %s", parentAutomationId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to parse template", types.ErrorInvalidTemplate)
+		return
+	}
+	if input.FromPhase != "" {
+		if _, err := sourceTemplate.Spec.Template.GetResumedPhaseResults(input.FromPhase, *parentAutomation.RunStatus); err != nil {
+			common.SendHttpFailResponse(w, r, http.StatusBadRequest, fmt.Sprintf("failed to resume from phase[%s]: %s", input.FromPhase, err), types.ErrorInvalidInput)
+			return
+		}
+	}
+	previousValues, err := parentAutomation.GetInputVars()
+	if err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to load variables of automation[%s]: %s", parentAutomationId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to load variables", types.ErrorCodeIssue)
+		return
+	}
+
+	user := models.User{Id: &session.UserId}
+	if err := user.LoadByIdV1(models.DatabaseConnection{Db: dbInstance}); err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to retrieve user[%s]: %s", session.UserId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "db query failed", types.ErrorDatabaseIssue)
+		return
+	}
+	pendingAutomation, err := models.CreatePendingAutomationV1(models.CreatePendingAutomationV1Opts{
+		Cache:              cacheInstance,
+		OrgId:              parentAutomation.OrgId,
+		TemplateContent:    parentAutomation.TemplateContent,
+		TemplateId:         parentAutomation.TemplateId,
+		TemplateVersion:    parentAutomation.TemplateVersion,
+		TriggeredBy:        session.UserId,
+		TriggererComment:   input.Comment,
+		InputVars:          parentAutomation.InputVars,
+		ParentAutomationId: &parentAutomationId,
+		ResumeFromPhase:    input.FromPhase,
+	})
+	if err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to create re-run of automation[%s]: %s", parentAutomationId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to create automation", types.ErrorDatabaseIssue)
+		return
+	}
+
+	variableMap := sourceTemplate.GetVariables()
+	for variableId, variable := range variableMap {
+		variableMap[variableId] = variable.GetRedacted()
+	}
+	log(common.LogLevelInfo, fmt.Sprintf("user[%s] created automation[%s] as a re-run of automation[%s]", session.UserId, *pendingAutomation.Id, parentAutomationId))
+	common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", RerunAutomationV1Output{
+		CreateAutomationV1OutputData: CreateAutomationV1OutputData{
+			AutomationId:     *pendingAutomation.Id,
+			TemplateId:       pendingAutomation.TemplateId,
+			TemplateName:     sourceTemplate.GetName(),
+			TriggeredById:    user.GetId(),
+			TriggeredByEmail: user.Email,
+			VariableMap:      variableMap,
+		},
+		ParentAutomationId: parentAutomationId,
+		FromPhase:          input.FromPhase,
+		VariableValues:     sourceTemplate.Spec.Variables.GetRedacted(previousValues),
+	})
+}
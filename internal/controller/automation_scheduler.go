@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"opsicle/internal/cache"
+	"opsicle/internal/common"
+	"opsicle/internal/controller/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	automationSchedulerBatchSize = 50
+	automationSchedulerInterval  = 15 * time.Second
+	automationSchedulerLockKey   = "lock:automation-scheduler"
+
+	// automationSchedulerLockTtl is how long the scheduler lock is held
+	// without being extended, this is how long it takes for another
+	// controller replica to take over when the one holding it exits
+	automationSchedulerLockTtl = 3 * automationSchedulerInterval
+)
+
+// startAutomationScheduler triggers automations of schedules when they
+// are due; all controller replicas run this but only the one holding the
+// scheduler lock in the cache triggers automations
+func startAutomationScheduler(ctx context.Context) {
+	schedulerId := uuid.NewString()
+	isLeader := false
+	ticker := time.NewTicker(automationSchedulerInterval)
+	defer ticker.Stop()
+	for {
+		isLocked, err := cache.AcquireLock(cacheInstance, automationSchedulerLockKey, schedulerId, automationSchedulerLockTtl)
+		if err != nil {
+			*serviceLogs <- common.ServiceLogf(common.LogLevelError, "automation scheduler[%s] failed to acquire lock: %s", schedulerId, err)
+		}
+		if isLocked != isLeader {
+			isLeader = isLocked
+			if isLeader {
+				*serviceLogs <- common.ServiceLogf(common.LogLevelInfo, "automation scheduler[%s] is now triggering scheduled automations", schedulerId)
+			} else {
+				*serviceLogs <- common.ServiceLogf(common.LogLevelInfo, "automation scheduler[%s] is no longer triggering scheduled automations", schedulerId)
+			}
+		}
+		if isLeader {
+			runDueAutomationSchedules(time.Now())
+		}
+		select {
+		case <-ctx.Done():
+			if isLeader {
+				if err := cache.ReleaseLock(cacheInstance, automationSchedulerLockKey, schedulerId); err != nil {
+					*serviceLogs <- common.ServiceLogf(common.LogLevelWarn, "automation scheduler[%s] failed to release lock: %s", schedulerId, err)
+				}
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runDueAutomationSchedules triggers automations of schedules which are
+// due at `now`
+func runDueAutomationSchedules(now time.Time) {
+	schedules, err := models.ListDueAutomationSchedulesV1(models.ListDueAutomationSchedulesV1Input{
+		DatabaseConnection: models.DatabaseConnection{Db: dbInstance},
+		Now:                now,
+		Limit:              automationSchedulerBatchSize,
+	})
+	if err != nil {
+		*serviceLogs <- common.ServiceLogf(common.LogLevelError, "failed to list due schedules: %s", err)
+		return
+	}
+	for _, schedule := range schedules {
+		isClaimed, err := schedule.ClaimRunV1(models.ClaimAutomationScheduleRunV1Input{
+			DatabaseConnection: models.DatabaseConnection{Db: dbInstance},
+			RunAt:              now,
+		})
+		if err != nil {
+			*serviceLogs <- common.ServiceLogf(common.LogLevelError, "failed to claim run of schedule[%s]: %s", schedule.GetId(), err)
+			continue
+		} else if !isClaimed {
+			*serviceLogs <- common.ServiceLogf(common.LogLevelDebug, "run of schedule[%s] was already claimed", schedule.GetId())
+			continue
+		}
+		automationId, err := runAutomationSchedule(schedule)
+		if err != nil {
+			*serviceLogs <- common.ServiceLogf(common.LogLevelError, "failed to trigger automation from schedule[%s]: %s", schedule.GetId(), err)
+			continue
+		}
+		if err := schedule.SetLastAutomationV1(models.SetAutomationScheduleLastAutomationV1Input{
+			DatabaseConnection: models.DatabaseConnection{Db: dbInstance},
+			AutomationId:       automationId,
+		}); err != nil {
+			*serviceLogs <- common.ServiceLogf(common.LogLevelWarn, "failed to record automation[%s] on schedule[%s]: %s", automationId, schedule.GetId(), err)
+		}
+		*serviceLogs <- common.ServiceLogf(common.LogLevelInfo, "schedule[%s] triggered automation[%s], next run is at %s", schedule.GetId(), automationId, schedule.NextRunAt.Format(time.RFC3339))
+	}
+}
+
+// runAutomationSchedule triggers an automation from the schedule's
+// template as the schedule's owner and returns the ID of the automation
+func runAutomationSchedule(schedule models.AutomationSchedule) (string, error) {
+	variableMap, err := schedule.GetVariableMap()
+	if err != nil {
+		return "", fmt.Errorf("failed to get variables: %w", err)
+	}
+	return runTemplateAsUser(runTemplateAsUserInput{
+		UserId:          schedule.GetOwnerId(),
+		OrgId:           schedule.OrgId,
+		TemplateId:      schedule.TemplateId,
+		TemplateVersion: schedule.TemplateVersion,
+		VariableMap:     variableMap,
+		Comment:         fmt.Sprintf("triggered by schedule[%s]", schedule.Name),
+		AuditData: map[string]any{
+			"scheduleId": schedule.GetId(),
+		},
+	})
+}
@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"opsicle/internal/common"
+	"opsicle/internal/controller/models"
+	"opsicle/internal/types"
+	"opsicle/internal/validate"
+
+	"github.com/gorilla/mux"
+)
+
+type ResolveAutomationSecretsV1Input struct {
+	OrgId *string `json:"orgId"`
+}
+
+type ResolveAutomationSecretsV1Output struct {
+	Values map[string]string `json:"values"`
+}
+
+// handleResolveAutomationSecretsV1 returns the decrypted values of the
+// secret variables an automation was run with; this is an internal-only
+// endpoint used by the coordinator when handing a job over to a worker
+// so that secret values are never stored in the queue
+func handleResolveAutomationSecretsV1(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+
+	automationId := mux.Vars(r)["automationId"]
+	if err := validate.Uuid(automationId); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid automation id", types.ErrorInvalidInput)
+		return
+	}
+	bodyData, err := io.ReadAll(r.Body)
+	if err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "failed to get body data", types.ErrorInvalidInput)
+		return
+	}
+	var input ResolveAutomationSecretsV1Input
+	if err := json.Unmarshal(bodyData, &input); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "failed to parse body data", types.ErrorInvalidInput)
+		return
+	}
+
+	automation := models.Automation{Id: &automationId}
+	if err := automation.LoadV1(models.DatabaseConnection{Db: dbInstance}); err != nil {
+		if errors.Is(err, models.ErrorNotFound) {
+			common.SendHttpFailResponse(w, r, http.StatusNotFound, "automation not found", types.ErrorNotFound)
+			return
+		}
+		log(common.LogLevelError, fmt.Sprintf("failed to load automation[%s]: %s", automationId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to retrieve automation", types.ErrorDatabaseIssue)
+		return
+	}
+	automationOrgId, inputOrgId := "", ""
+	if automation.OrgId != nil {
+		automationOrgId = *automation.OrgId
+	}
+	if input.OrgId != nil {
+		inputOrgId = *input.OrgId
+	}
+	if automationOrgId != inputOrgId {
+		log(common.LogLevelWarn, fmt.Sprintf("rejected secrets resolution for automation[%s] from org[%s]", automationId, inputOrgId))
+		common.SendHttpFailResponse(w, r, http.StatusForbidden, "org mismatch", types.ErrorInsufficientPermissions)
+		return
+	}
+
+	template, err := automation.GetTemplate()
+	if err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to parse template of automation[%s]: %s", automationId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to resolve secrets", types.ErrorGeneric)
+		return
+	}
+	inputVars, err := automation.GetInputVars()
+	if err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to decrypt variables of automation[%s]: %s", automationId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to resolve secrets", types.ErrorGeneric)
+		return
+	}
+	output := ResolveAutomationSecretsV1Output{Values: map[string]string{}}
+	for _, variableId := range template.Spec.Variables.GetInputSecretIds() {
+		if value, ok := inputVars[variableId].(string); ok {
+			output.Values[variableId] = value
+		}
+	}
+	log(common.LogLevelDebug, fmt.Sprintf("resolved %v secret variable(s) of automation[%s]", len(output.Values), automationId))
+	common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", output)
+}
@@ -6,22 +6,40 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"opsicle/internal/audit"
 	"opsicle/internal/automations"
 	"opsicle/internal/common"
 	"opsicle/internal/controller/models"
+	"opsicle/internal/queue"
 	"opsicle/internal/types"
 	"opsicle/internal/validate"
+	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 )
 
 func registerAutomationRoutes(opts RouteRegistrationOpts) {
 	requiresAuth := getRouteAuther(opts.ServiceLogs)
+	requireApiKey := getInternalRouteAuther(opts.ApiKeys, opts.ServiceLogs)
 
 	v1 := opts.Router.PathPrefix("/v1/automation").Subrouter()
 
 	v1.Handle("", requiresAuth(http.HandlerFunc(handleCreateAutomationV1))).Methods(http.MethodPost)
+	v1.Handle("/{automationId}", requiresAuth(http.HandlerFunc(handleGetAutomationV1))).Methods(http.MethodGet)
 	v1.Handle("/{automationId}", requiresAuth(http.HandlerFunc(handleRunAutomationV1))).Methods(http.MethodPost)
+	v1.Handle("/{automationId}/artifacts", requiresAuth(http.HandlerFunc(handleGetAutomationArtifactsV1))).Methods(http.MethodGet)
+	v1.Handle("/{automationId}/approval", http.HandlerFunc(handleResolveAutomationApprovalV1)).Methods(http.MethodPost)
+	v1.Handle("/{automationId}/artifacts", requireApiKey(http.HandlerFunc(handleUploadAutomationArtifactsV1))).Methods(http.MethodPost)
+	v1.Handle("/{automationId}/cancel", requiresAuth(http.HandlerFunc(handleCancelAutomationV1))).Methods(http.MethodPost)
+	v1.Handle("/{automationId}/logs", requiresAuth(http.HandlerFunc(handleGetAutomationLogsV1))).Methods(http.MethodGet)
+	v1.Handle("/{automationId}/rerun", requiresAuth(http.HandlerFunc(handleRerunAutomationV1))).Methods(http.MethodPost)
+	v1.Handle("/{automationId}/secrets/resolve", requireApiKey(http.HandlerFunc(handleResolveAutomationSecretsV1))).Methods(http.MethodPost)
+	v1.Handle("/{automationId}/status", requireApiKey(http.HandlerFunc(handleUpdateAutomationStatusV1))).Methods(http.MethodPost)
+
+	v1 = opts.Router.PathPrefix("/v1/automations").Subrouter()
+
+	v1.Handle("", requiresAuth(http.HandlerFunc(handleListAutomationsV1))).Methods(http.MethodGet)
 }
 
 type CreateAutomationV1OutputData struct {
@@ -71,45 +89,20 @@ func handleCreateAutomationV1(w http.ResponseWriter, r *http.Request) {
 		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid template", types.ErrorInvalidInput)
 		return
 	}
-	if input.OrgId == nil {
-		if canExecute, err := template.CanUserExecuteV1(models.DatabaseConnection{Db: dbInstance}, session.UserId); err != nil {
-			log(common.LogLevelError, fmt.Sprintf("failed to check permissions of user[%s] on template[%s]: %s", session.UserId, templateId, err))
-			common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "not allowed", types.ErrorDatabaseIssue)
-			return
-		} else if !canExecute {
-			log(common.LogLevelError, fmt.Sprintf("user[%s] is not allowed to execute template[%s]: %s", session.UserId, templateId, err))
-			common.SendHttpFailResponse(w, r, http.StatusUnauthorized, "not allowed", types.ErrorInsufficientPermissions)
-			return
-		}
-	} else {
+	if input.OrgId != nil {
 		if err := validate.Uuid(*input.OrgId); err != nil {
 			common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid org id", types.ErrorInvalidInput)
 			return
 		}
-		orgId := *input.OrgId
-		org := models.Org{Id: &orgId}
-		orgUser, err := org.GetUserV1(models.GetOrgUserV1Opts{Db: dbInstance, UserId: session.UserId})
-		if err != nil {
-			if errors.Is(err, models.ErrorNotFound) {
-				log(common.LogLevelError, fmt.Sprintf("user[%s] is not a member of org[%s]: %s", session.UserId, orgId, err))
-				common.SendHttpFailResponse(w, r, http.StatusUnauthorized, "not allowed", types.ErrorInsufficientPermissions)
-				return
-			}
-			log(common.LogLevelError, fmt.Sprintf("failed to load org user[%s] in org[%s]: %s", session.UserId, orgId, err))
-			common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "not allowed", types.ErrorDatabaseIssue)
-			return
-		}
-		_, _, canExecute, err := orgUser.CanV1(models.DatabaseConnection{Db: dbInstance}, models.ResourceTemplates, models.ActionExecute)
-		if err != nil {
-			log(common.LogLevelError, fmt.Sprintf("failed to check permissions of user[%s] in org[%s]: %s", session.UserId, orgId, err))
-			common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "not allowed", types.ErrorDatabaseIssue)
-			return
-		}
-		if !canExecute {
-			log(common.LogLevelError, fmt.Sprintf("user[%s] lacks execute permission on templates in org[%s]", session.UserId, orgId))
-			common.SendHttpFailResponse(w, r, http.StatusUnauthorized, "not allowed", types.ErrorInsufficientPermissions)
-			return
-		}
+	}
+	if canExecute, err := canUserExecuteTemplate(template, input.OrgId, session.UserId); err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to check permissions of user[%s] on template[%s]: %s", session.UserId, templateId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "not allowed", types.ErrorDatabaseIssue)
+		return
+	} else if !canExecute {
+		log(common.LogLevelError, fmt.Sprintf("user[%s] is not allowed to execute template[%s]", session.UserId, templateId))
+		common.SendHttpFailResponse(w, r, http.StatusUnauthorized, "not allowed", types.ErrorInsufficientPermissions)
+		return
 	}
 
 	automationParams, err := template.GetAutomationParamsV1(models.DatabaseConnection{Db: dbInstance})
@@ -148,6 +141,9 @@ func handleCreateAutomationV1(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	variableMap := sourceTemplate.GetVariables()
+	for variableId, variable := range variableMap {
+		variableMap[variableId] = variable.GetRedacted()
+	}
 
 	output := CreateAutomationV1OutputData{
 		AutomationId:     *pendingAutomation.Id,
@@ -165,6 +161,10 @@ type RunAutomationV1Output struct {
 	AutomationId    string `json:"automationId"`
 	AutomationRunId string `json:"automationRunId"`
 	IsSuccessful    bool   `json:"isSuccessful"`
+
+	// Status is `pending-approval` when the automation is held until
+	// its approval request is resolved
+	Status string `json:"status"`
 }
 
 type RunAutomationV1VariableMap map[string]any
@@ -207,23 +207,580 @@ func handleRunAutomationV1(w http.ResponseWriter, r *http.Request) {
 		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to retrieve automation", types.ErrorInvalidInput)
 		return
 	}
-	queueRunOpts := models.QueueAutomationRunV1Opts{
-		Db: dbInstance,
-		Q:  queueInstance,
-
-		Input: input.VariableMap,
-		OrgId: orgId,
+	startedAutomation, err := startAutomation(startAutomationOpts{
+		Automation: &automation,
+		Input:      input.VariableMap,
+		OrgId:      orgId,
+		UserId:     session.UserId,
+	})
+	auditVariableMap := map[string]any(input.VariableMap)
+	if template, templateErr := automation.GetTemplate(); templateErr == nil {
+		auditVariableMap = template.Spec.Variables.GetRedacted(input.VariableMap)
+	}
+	auditEntry := audit.LogEntry{
+		EntityId:     session.UserId,
+		EntityType:   audit.UserEntity,
+		Verb:         audit.Execute,
+		ResourceId:   automationId,
+		ResourceType: audit.AutomationResource,
+		Status:       audit.Success,
+		SrcIp:        &session.SourceIp,
+		SrcUa:        &session.UserAgent,
+		DstHost:      &r.Host,
+		Data: map[string]any{
+			"variables": auditVariableMap,
+		},
 	}
-	queuedAutomationRun, err := automation.RunV1(queueRunOpts)
 	if err != nil {
+		auditEntry.Status = audit.Failed
+		audit.Log(auditEntry)
 		log(common.LogLevelError, fmt.Sprintf("failed to run automation: %s", err))
+		if errors.Is(err, types.ErrorInvalidInput) {
+			common.SendHttpFailResponse(w, r, http.StatusBadRequest, err.Error(), types.ErrorInvalidInput)
+			return
+		}
 		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to insert automation into the queue", types.ErrorQueueIssue)
 		return
 	}
+	auditEntry.Data["status"] = startedAutomation.Status
+	audit.Log(auditEntry)
 	output := RunAutomationV1Output{
 		AutomationId:    automationId,
-		AutomationRunId: queuedAutomationRun.AutomationRunId,
+		AutomationRunId: startedAutomation.AutomationRunId,
 		IsSuccessful:    false,
+		Status:          string(startedAutomation.Status),
+	}
+	common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", output)
+}
+
+type GetAutomationV1Output struct {
+	Id                 string                 `json:"id"`
+	OrgId              *string                `json:"orgId"`
+	ParentAutomationId *string                `json:"parentAutomationId"`
+	TemplateId         string                 `json:"templateId"`
+	TemplateName       string                 `json:"templateName"`
+	TemplateVersion    int64                  `json:"templateVersion"`
+	Status             string                 `json:"status"`
+	RunStatus          *automations.RunStatus `json:"runStatus"`
+	TriggeredById      string                 `json:"triggeredById"`
+	TriggeredByEmail   string                 `json:"triggeredByEmail"`
+	TriggeredAt        time.Time              `json:"triggeredAt"`
+	TriggererComment   string                 `json:"triggererComment"`
+	StartedAt          *time.Time             `json:"startedAt"`
+	CompletedAt        *time.Time             `json:"completedAt"`
+	CreatedAt          time.Time              `json:"createdAt"`
+	LastUpdatedAt      time.Time              `json:"lastUpdatedAt"`
+}
+
+// handleGetAutomationV1 returns the status of an automation run; users
+// can view runs they triggered and runs of orgs where they have the
+// permission to view automations
+func handleGetAutomationV1(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+	session := r.Context().Value(userAuthRequestContext).(userIdentity)
+
+	vars := mux.Vars(r)
+	automationId := vars["automationId"]
+	if err := validate.Uuid(automationId); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid automation id", types.ErrorInvalidInput)
+		return
+	}
+	log(common.LogLevelDebug, fmt.Sprintf("user[%s] is retrieving automation[%s]", session.UserId, automationId))
+
+	automation := models.Automation{Id: &automationId}
+	if err := automation.LoadV1(models.DatabaseConnection{Db: dbInstance}); err != nil {
+		if errors.Is(err, models.ErrorNotFound) {
+			common.SendHttpFailResponse(w, r, http.StatusNotFound, "automation not found", types.ErrorNotFound)
+			return
+		}
+		log(common.LogLevelError, fmt.Sprintf("failed to load automation[%s]: %s", automationId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to retrieve automation", types.ErrorDatabaseIssue)
+		return
+	}
+
+	if canView, err := canUserViewAutomation(&automation, session.UserId, models.ResourceAutomations); err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to check permissions of user[%s] on automation[%s]: %s", session.UserId, automationId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to retrieve automation", types.ErrorDatabaseIssue)
+		return
+	} else if !canView {
+		log(common.LogLevelError, fmt.Sprintf("user[%s] is not allowed to view automation[%s]", session.UserId, automationId))
+		common.SendHttpFailResponse(w, r, http.StatusForbidden, "not allowed", types.ErrorInsufficientPermissions)
+		return
+	}
+
+	output := GetAutomationV1Output{
+		Id:                 automationId,
+		OrgId:              automation.OrgId,
+		ParentAutomationId: automation.ParentAutomationId,
+		TemplateId:         automation.TemplateId,
+		TemplateVersion:    automation.TemplateVersion,
+		Status:             automation.LastKnownStatus,
+		RunStatus:          automation.RunStatus,
+		TriggeredAt:        automation.TriggeredAt,
+		TriggererComment:   automation.TriggererComment,
+		StartedAt:          automation.StartedAt,
+		CompletedAt:        automation.CompletedAt,
+		CreatedAt:          automation.CreatedAt,
+		LastUpdatedAt:      automation.LastUpdatedAt,
+	}
+	if template, err := automation.GetTemplate(); err == nil {
+		output.TemplateName = template.GetName()
+	}
+	if automation.TriggeredBy != nil && automation.TriggeredBy.Id != nil {
+		output.TriggeredById = automation.TriggeredBy.GetId()
+		if err := automation.TriggeredBy.LoadByIdV1(models.DatabaseConnection{Db: dbInstance}); err == nil {
+			output.TriggeredByEmail = automation.TriggeredBy.Email
+		}
+	}
+
+	common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", output)
+}
+
+type CancelAutomationV1Input struct {
+	Reason string `json:"reason"`
+}
+
+type CancelAutomationV1Output struct {
+	AutomationId string `json:"automationId"`
+
+	// Status is the status of the run after it was cancelled, runs which
+	// are executing remain so until their worker has stopped them
+	Status string `json:"status"`
+}
+
+// handleCancelAutomationV1 cancels an automation run; the cancellation
+// is published to the run's cancellations queue for the worker
+// processing it, runs which have not started executing are marked as
+// cancelled immediately
+func handleCancelAutomationV1(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+	session := r.Context().Value(userAuthRequestContext).(userIdentity)
+
+	vars := mux.Vars(r)
+	automationId := vars["automationId"]
+	if err := validate.Uuid(automationId); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid automation id", types.ErrorInvalidInput)
+		return
+	}
+	var input CancelAutomationV1Input
+	bodyData, err := io.ReadAll(r.Body)
+	if err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "failed to get body data", types.ErrorInvalidInput)
+		return
+	}
+	if len(bodyData) > 0 {
+		if err := json.Unmarshal(bodyData, &input); err != nil {
+			common.SendHttpFailResponse(w, r, http.StatusBadRequest, "failed to parse body data", types.ErrorInvalidInput)
+			return
+		}
+	}
+	log(common.LogLevelDebug, fmt.Sprintf("user[%s] is cancelling automation[%s]", session.UserId, automationId))
+
+	automation := models.Automation{Id: &automationId}
+	if err := automation.LoadV1(models.DatabaseConnection{Db: dbInstance}); err != nil {
+		if errors.Is(err, models.ErrorNotFound) {
+			common.SendHttpFailResponse(w, r, http.StatusNotFound, "automation not found", types.ErrorNotFound)
+			return
+		}
+		log(common.LogLevelError, fmt.Sprintf("failed to load automation[%s]: %s", automationId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to retrieve automation", types.ErrorDatabaseIssue)
+		return
+	}
+	if canCancel, err := canUserActOnAutomation(&automation, session.UserId, models.ResourceAutomations, models.ActionExecute); err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to check permissions of user[%s] on automation[%s]: %s", session.UserId, automationId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to cancel automation", types.ErrorDatabaseIssue)
+		return
+	} else if !canCancel {
+		log(common.LogLevelError, fmt.Sprintf("user[%s] is not allowed to cancel automation[%s]", session.UserId, automationId))
+		common.SendHttpFailResponse(w, r, http.StatusForbidden, "not allowed", types.ErrorInsufficientPermissions)
+		return
+	}
+	status := automations.RunStatusCode(automation.LastKnownStatus)
+	if status.IsFinal() {
+		common.SendHttpFailResponse(w, r, http.StatusConflict, fmt.Sprintf("automation is already in status[%s]", status), types.ErrorAutomationCompleted)
+		return
+	}
+
+	auditEntry := audit.LogEntry{
+		EntityId:     session.UserId,
+		EntityType:   audit.UserEntity,
+		Verb:         audit.Terminate,
+		ResourceId:   automationId,
+		ResourceType: audit.AutomationResource,
+		Status:       audit.Success,
+		SrcIp:        &session.SourceIp,
+		SrcUa:        &session.UserAgent,
+		DstHost:      &r.Host,
+		Data: map[string]any{
+			"reason": input.Reason,
+		},
+	}
+	cancellation := automations.Cancellation{
+		AutomationId: automationId,
+		OrgId:        automation.OrgId,
+		CancelledBy:  session.UserId,
+		Reason:       input.Reason,
+		Timestamp:    time.Now(),
+	}
+	cancellationData, err := json.Marshal(cancellation)
+	if err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to cancel automation", types.ErrorCodeIssue)
+		return
+	}
+	if _, err := queueInstance.Push(queue.PushOpts{
+		Data:   cancellationData,
+		Queue:  automations.GetCancellationsQueue(automationId),
+		Stream: automations.GetCancellationsStreamOpts(),
+	}); err != nil {
+		auditEntry.Status = audit.Failed
+		audit.Log(auditEntry)
+		log(common.LogLevelError, fmt.Sprintf("failed to publish cancellation of automation[%s]: %s", automationId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to cancel automation", types.ErrorQueueIssue)
+		return
+	}
+	audit.Log(auditEntry)
+
+	// runs which are executing are marked as cancelled by their worker
+	// once their phases have stopped
+	updatedStatus := status
+	if status != automations.RunStatusExecuting {
+		if err := automation.UpdateStatusV1(models.UpdateAutomationStatusV1Opts{
+			Db: models.DatabaseConnection{Db: dbInstance},
+			Update: automations.RunStatusUpdate{
+				AutomationId: automationId,
+				Status:       automations.RunStatusCancelled,
+				Message:      cancellation.GetMessage(),
+				Timestamp:    cancellation.Timestamp,
+			},
+		}); err != nil {
+			log(common.LogLevelError, fmt.Sprintf("failed to update status of automation[%s]: %s", automationId, err))
+			common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to update automation status", types.ErrorDatabaseIssue)
+			return
+		}
+		updatedStatus = automations.RunStatusCode(automation.LastKnownStatus)
+	}
+	log(common.LogLevelInfo, fmt.Sprintf("user[%s] cancelled automation[%s] in status[%s], it is now in status[%s]", session.UserId, automationId, status, updatedStatus))
+	common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", CancelAutomationV1Output{
+		AutomationId: automationId,
+		Status:       string(updatedStatus),
+	})
+}
+
+type UpdateAutomationStatusV1Input struct {
+	OrgId  *string                     `json:"orgId"`
+	Update automations.RunStatusUpdate `json:"update"`
+}
+
+// handleUpdateAutomationStatusV1 is an internal endpoint used by the
+// coordinator to report status updates received from workers
+func handleUpdateAutomationStatusV1(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+
+	vars := mux.Vars(r)
+	automationId := vars["automationId"]
+	if err := validate.Uuid(automationId); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid automation id", types.ErrorInvalidInput)
+		return
+	}
+
+	bodyData, err := io.ReadAll(r.Body)
+	if err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "failed to get body data", types.ErrorInvalidInput)
+		return
+	}
+	var input UpdateAutomationStatusV1Input
+	if err := json.Unmarshal(bodyData, &input); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "failed to parse body data", types.ErrorInvalidInput)
+		return
+	}
+	if input.Update.AutomationId != "" && input.Update.AutomationId != automationId {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "automation id mismatch", types.ErrorInvalidInput)
+		return
+	}
+	input.Update.AutomationId = automationId
+
+	automation := models.Automation{Id: &automationId}
+	if err := automation.LoadV1(models.DatabaseConnection{Db: dbInstance}); err != nil {
+		if errors.Is(err, models.ErrorNotFound) {
+			common.SendHttpFailResponse(w, r, http.StatusNotFound, "automation not found", types.ErrorNotFound)
+			return
+		}
+		log(common.LogLevelError, fmt.Sprintf("failed to load automation[%s]: %s", automationId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to retrieve automation", types.ErrorDatabaseIssue)
+		return
+	}
+	automationOrgId, inputOrgId := "", ""
+	if automation.OrgId != nil {
+		automationOrgId = *automation.OrgId
+	}
+	if input.OrgId != nil {
+		inputOrgId = *input.OrgId
+	}
+	if automationOrgId != inputOrgId {
+		log(common.LogLevelWarn, fmt.Sprintf("rejected status update for automation[%s] from org[%s]", automationId, inputOrgId))
+		common.SendHttpFailResponse(w, r, http.StatusForbidden, "org mismatch", types.ErrorInsufficientPermissions)
+		return
+	}
+
+	if err := automation.UpdateStatusV1(models.UpdateAutomationStatusV1Opts{
+		Db:     models.DatabaseConnection{Db: dbInstance},
+		Update: input.Update,
+	}); err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to update status of automation[%s]: %s", automationId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to update automation status", types.ErrorDatabaseIssue)
+		return
+	}
+	log(common.LogLevelDebug, fmt.Sprintf("automation[%s] is now in status[%s]", automationId, automation.LastKnownStatus))
+	common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", automation.RunStatus)
+}
+
+// canUserViewAutomation returns true if the user identified by `userId`
+// triggered the automation or is a member of the automation's org with
+// permissions to view the specified `resource`
+func canUserViewAutomation(automation *models.Automation, userId string, resource models.Resource) (bool, error) {
+	return canUserActOnAutomation(automation, userId, resource, models.ActionView)
+}
+
+// canUserExecuteTemplate returns true if the user identified by `userId`
+// is allowed to execute the template; when `orgId` is defined this is
+// determined by the user's permissions on templates in the org,
+// otherwise by the user's permissions on the template itself
+func canUserExecuteTemplate(template *models.Template, orgId *string, userId string) (bool, error) {
+	if orgId == nil {
+		return template.CanUserExecuteV1(models.DatabaseConnection{Db: dbInstance}, userId)
+	}
+	org := models.Org{Id: orgId}
+	orgUser, err := org.GetUserV1(models.GetOrgUserV1Opts{Db: dbInstance, UserId: userId})
+	if err != nil {
+		if errors.Is(err, models.ErrorNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to load org user[%s] in org[%s]: %w", userId, *orgId, err)
+	}
+	_, _, isAllowed, err := orgUser.CanV1(models.DatabaseConnection{Db: dbInstance}, models.ResourceTemplates, models.ActionExecute)
+	if err != nil {
+		return false, fmt.Errorf("failed to check permissions of user[%s] in org[%s]: %w", userId, *orgId, err)
+	}
+	return isAllowed, nil
+}
+
+type runTemplateAsUserInput struct {
+	UserId          string
+	OrgId           *string
+	TemplateId      string
+	TemplateVersion *int64
+	VariableMap     map[string]any
+
+	// Comment is recorded as the triggerer's comment on the automation
+	Comment string
+
+	// AuditData is added to the data of the audit log entry
+	AuditData map[string]any
+}
+
+// runTemplateAsUser triggers an automation from a template as the user
+// identified by `.UserId` through the same pending automation path that
+// users go through and returns the ID of the automation; errors wrap
+// types.ErrorInsufficientPermissions if the user is not allowed to
+// execute the template and types.ErrorInvalidInput if the variables do
+// not satisfy the template
+func runTemplateAsUser(opts runTemplateAsUserInput) (string, error) {
+	template, err := models.GetTemplateV1(models.GetTemplateV1Opts{
+		Db:         dbInstance,
+		TemplateId: &opts.TemplateId,
+		UserId:     opts.UserId,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get template[%s] as user[%s]: %w", opts.TemplateId, opts.UserId, err)
+	}
+	if canExecute, err := canUserExecuteTemplate(template, opts.OrgId, opts.UserId); err != nil {
+		return "", fmt.Errorf("failed to check permissions of user[%s] on template[%s]: %w", opts.UserId, opts.TemplateId, err)
+	} else if !canExecute {
+		return "", fmt.Errorf("user[%s] is not allowed to execute template[%s]: %w", opts.UserId, opts.TemplateId, types.ErrorInsufficientPermissions)
+	}
+	if opts.TemplateVersion != nil {
+		if err := loadTemplateVersion(template, *opts.TemplateVersion); err != nil {
+			return "", err
+		}
+	}
+	sourceTemplate, err := automations.LoadAutomationTemplate(template.Content)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template[%s]: %w", opts.TemplateId, err)
+	}
+	if _, err := sourceTemplate.Spec.Variables.Resolve(sourceTemplate.Spec.Variables.GetUserInput(opts.VariableMap)); err != nil {
+		return "", fmt.Errorf("%w: invalid variables: %w", types.ErrorInvalidInput, err)
+	}
+
+	pendingAutomation, err := models.CreatePendingAutomationV1(models.CreatePendingAutomationV1Opts{
+		Cache:            cacheInstance,
+		OrgId:            opts.OrgId,
+		TemplateContent:  template.Content,
+		TemplateId:       template.GetId(),
+		TemplateVersion:  template.GetVersion(),
+		TriggeredBy:      opts.UserId,
+		TriggererComment: opts.Comment,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create pending automation: %w", err)
+	}
+	automation := models.Automation{Id: pendingAutomation.Id}
+	if err := automation.LoadPendingV1(models.DatabaseConnection{Db: dbInstance}); err != nil {
+		return "", fmt.Errorf("failed to load pending automation[%s]: %w", *pendingAutomation.Id, err)
+	}
+	_, err = startAutomation(startAutomationOpts{
+		Automation: &automation,
+		Input:      opts.VariableMap,
+		OrgId:      opts.OrgId,
+		UserId:     opts.UserId,
+	})
+	auditData := map[string]any{
+		"variables": sourceTemplate.Spec.Variables.GetRedacted(opts.VariableMap),
+	}
+	for key, value := range opts.AuditData {
+		auditData[key] = value
+	}
+	auditEntry := audit.LogEntry{
+		EntityId:     opts.UserId,
+		EntityType:   audit.UserEntity,
+		Verb:         audit.Execute,
+		ResourceId:   *automation.Id,
+		ResourceType: audit.AutomationResource,
+		Status:       audit.Success,
+		Data:         auditData,
+	}
+	if err != nil {
+		auditEntry.Status = audit.Failed
+		audit.Log(auditEntry)
+		return "", fmt.Errorf("failed to run automation[%s]: %w", *automation.Id, err)
+	}
+	audit.Log(auditEntry)
+	return *automation.Id, nil
+}
+
+// canUserActOnAutomation returns true if the user identified by `userId`
+// triggered the automation or is a member of the automation's org with
+// permissions to perform `action` on the specified `resource`
+func canUserActOnAutomation(automation *models.Automation, userId string, resource models.Resource, action models.Action) (bool, error) {
+	if automation.TriggeredBy != nil && automation.TriggeredBy.GetId() == userId {
+		return true, nil
+	}
+	if automation.OrgId == nil {
+		return false, nil
+	}
+	org := models.Org{Id: automation.OrgId}
+	orgUser, err := org.GetUserV1(models.GetOrgUserV1Opts{Db: dbInstance, UserId: userId})
+	if err != nil {
+		if errors.Is(err, models.ErrorNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to load org user[%s] in org[%s]: %w", userId, *automation.OrgId, err)
+	}
+	_, _, isAllowed, err := orgUser.CanV1(models.DatabaseConnection{Db: dbInstance}, resource, action)
+	if err != nil {
+		return false, fmt.Errorf("failed to check permissions of user[%s] in org[%s]: %w", userId, *automation.OrgId, err)
+	}
+	return isAllowed, nil
+}
+
+type GetAutomationLogsV1Output struct {
+	Logs       string `json:"logs"`
+	Offset     int    `json:"offset"`
+	Status     string `json:"status"`
+	IsComplete bool   `json:"isComplete"`
+}
+
+// handleGetAutomationLogsV1 returns the logs of an automation run after
+// the offset specified in the `offset` query parameter; the returned
+// `offset` should be used to retrieve subsequent logs. When
+// the `wait` query parameter is specified as a Go duration string (eg.
+// `5s`), the request is held open until logs after the offset are
+// available, the automation completes or the wait duration elapses
+func handleGetAutomationLogsV1(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+	session := r.Context().Value(userAuthRequestContext).(userIdentity)
+
+	vars := mux.Vars(r)
+	automationId := vars["automationId"]
+	if err := validate.Uuid(automationId); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid automation id", types.ErrorInvalidInput)
+		return
+	}
+	offset := 0
+	if offsetInput := r.URL.Query().Get("offset"); offsetInput != "" {
+		parsedOffset, err := strconv.Atoi(offsetInput)
+		if err != nil || parsedOffset < 0 {
+			common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid offset", types.ErrorInvalidInput)
+			return
+		}
+		offset = parsedOffset
+	}
+	waitDuration := time.Duration(0)
+	if waitInput := r.URL.Query().Get("wait"); waitInput != "" {
+		parsedWaitDuration, err := time.ParseDuration(waitInput)
+		if err != nil || parsedWaitDuration < 0 {
+			common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid wait duration", types.ErrorInvalidInput)
+			return
+		}
+		waitDuration = min(parsedWaitDuration, maxAutomationLogsWaitDuration)
+	}
+
+	automation := models.Automation{Id: &automationId}
+	if err := automation.LoadV1(models.DatabaseConnection{Db: dbInstance}); err != nil {
+		if errors.Is(err, models.ErrorNotFound) {
+			common.SendHttpFailResponse(w, r, http.StatusNotFound, "automation not found", types.ErrorNotFound)
+			return
+		}
+		log(common.LogLevelError, fmt.Sprintf("failed to load automation[%s]: %s", automationId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to retrieve automation", types.ErrorDatabaseIssue)
+		return
+	}
+	if canView, err := canUserViewAutomation(&automation, session.UserId, models.ResourceAutomationLogs); err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to check permissions of user[%s] on automation[%s]: %s", session.UserId, automationId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to retrieve automation logs", types.ErrorDatabaseIssue)
+		return
+	} else if !canView {
+		log(common.LogLevelError, fmt.Sprintf("user[%s] is not allowed to view logs of automation[%s]", session.UserId, automationId))
+		common.SendHttpFailResponse(w, r, http.StatusForbidden, "not allowed", types.ErrorInsufficientPermissions)
+		return
+	}
+
+	var logsOutput *models.GetAutomationLogsV1Output
+	deadline := time.Now().Add(waitDuration)
+	for {
+		// waiting starts before the logs are retrieved so that logs
+		// appended in between are not missed
+		logsAppended, stopWaiting := waitForAutomationLogs(automationId)
+		var err error
+		logsOutput, err = automation.GetLogsV1(models.GetAutomationLogsV1Opts{
+			Db:     models.DatabaseConnection{Db: dbInstance},
+			Offset: offset,
+		})
+		if err != nil {
+			stopWaiting()
+			log(common.LogLevelError, fmt.Sprintf("failed to retrieve logs of automation[%s]: %s", automationId, err))
+			common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to retrieve automation logs", types.ErrorDatabaseIssue)
+			return
+		}
+		remainingWait := time.Until(deadline)
+		if logsOutput.Logs != "" || automations.RunStatusCode(logsOutput.LastKnownStatus).IsFinal() || remainingWait <= 0 {
+			stopWaiting()
+			break
+		}
+		select {
+		case <-logsAppended:
+		case <-time.After(min(remainingWait, automationLogsRecheckInterval)):
+		case <-r.Context().Done():
+		}
+		stopWaiting()
+		if r.Context().Err() != nil {
+			return
+		}
+	}
+	output := GetAutomationLogsV1Output{
+		Logs:       logsOutput.Logs,
+		Offset:     logsOutput.Offset,
+		Status:     logsOutput.LastKnownStatus,
+		IsComplete: automations.RunStatusCode(logsOutput.LastKnownStatus).IsFinal(),
 	}
 	common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", output)
 }
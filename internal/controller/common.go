@@ -6,13 +6,19 @@ import (
 	"opsicle/internal/cache"
 	"opsicle/internal/common"
 	"opsicle/internal/queue"
+	"opsicle/pkg/approver"
 )
 
 const (
+	// MinSecretsMasterKeyLength is the minimum length of the key which
+	// encryption keys for secrets stored in the database are derived from
+	MinSecretsMasterKeyLength = 32
+
 	sessionCachePrefix = "session"
 )
 
 var apiKeys []string
+var approverClient *approver.Client
 var cacheInstance cache.Cache
 var dbInstance *sql.DB
 var publicServerUrl *url.URL
@@ -3,11 +3,15 @@ package controller
 import "errors"
 
 var (
+	ErrorApproverNotConfigured     = errors.New("approver_not_configured")
 	ErrorInvalidPublicServerUrl    = errors.New("invalid_public_server_url")
 	ErrorMissingApiKeys            = errors.New("missing_api_keys")
 	ErrorMissingCacheConnection    = errors.New("missing_cache_connection")
+	ErrorMissingContext            = errors.New("missing_context")
 	ErrorMissingDatabaseConnection = errors.New("missing_db_connection")
 	ErrorMissingEmailConfig        = errors.New("missing_email_config")
 	ErrorMissingQueueConnection    = errors.New("missing_queue_connection")
+	ErrorMissingSecretsMasterKey   = errors.New("missing_secrets_master_key")
 	ErrorMissingServiceLog         = errors.New("missing_service_log")
+	ErrorWeakSecretsMasterKey      = errors.New("weak_secrets_master_key")
 )
@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -9,6 +10,7 @@ import (
 	"opsicle/internal/common"
 	"opsicle/internal/persistence"
 	"opsicle/internal/queue"
+	"opsicle/pkg/approver"
 	"strings"
 
 	"opsicle/internal/controller/docs"
@@ -27,9 +29,19 @@ type HttpApplicationOpts struct {
 	// the old one),
 	ApiKeys []string
 
+	// ApproverConfig when defined enables approval requests to be sent
+	// to the approver service for automations whose templates define an
+	// approval policy
+	ApproverConfig *ApproverConfig
+
 	// CacheConnection provides a connection to a Redis cache
 	CacheConnection *persistence.Redis
 
+	// Context is the lifecycle context of the server, background processes
+	// such as the automation logs consumer and scheduler stop when it is
+	// cancelled
+	Context context.Context
+
 	// DatabaseConnection provides a connection to a MySQL compatible database
 	DatabaseConnection *persistence.Mysql
 
@@ -49,6 +61,12 @@ type HttpApplicationOpts struct {
 	// QueueConnection provides a connection to a NATS queue service
 	QueueConnection *persistence.Nats
 
+	// SecretsMasterKey is the key which encryption keys for secrets stored
+	// in the database are derived from, changing this makes all previously
+	// stored secrets unreadable; this is required and should be at least
+	// MinSecretsMasterKeyLength characters long
+	SecretsMasterKey string
+
 	// ServiceLogs is a centralised channel where logs get sent to
 	ServiceLogs chan<- common.ServiceLog
 
@@ -68,6 +86,10 @@ func (o HttpApplicationOpts) Validate() error {
 		errs = append(errs, fmt.Errorf("failed to receive a cache connection: %w", ErrorMissingDatabaseConnection))
 	}
 
+	if o.Context == nil {
+		errs = append(errs, fmt.Errorf("failed to receive a context: %w", ErrorMissingContext))
+	}
+
 	if o.DatabaseConnection == nil {
 		errs = append(errs, fmt.Errorf("failed to receive a database connection: %w", ErrorMissingDatabaseConnection))
 	}
@@ -80,6 +102,12 @@ func (o HttpApplicationOpts) Validate() error {
 		errs = append(errs, fmt.Errorf("failed to receive a queue connection: %w", ErrorMissingQueueConnection))
 	}
 
+	if o.SecretsMasterKey == "" {
+		errs = append(errs, fmt.Errorf("failed to receive a secrets master key: %w", ErrorMissingSecretsMasterKey))
+	} else if len(o.SecretsMasterKey) < MinSecretsMasterKeyLength {
+		errs = append(errs, fmt.Errorf("failed to accept a secrets master key shorter than %v characters: %w", MinSecretsMasterKeyLength, ErrorWeakSecretsMasterKey))
+	}
+
 	if o.ServiceLogs == nil {
 		errs = append(errs, fmt.Errorf("failed to receive a service log: %w", ErrorMissingServiceLog))
 	}
@@ -129,6 +157,7 @@ func GetHttpApplication(opts HttpApplicationOpts) (http.Handler, error) {
 		ServiceLogs:    *serviceLogs,
 	})
 	queueInstance = queue.Get()
+	go startAutomationLogsConsumer(opts.Context)
 
 	var err error
 	publicServerUrl, err = url.Parse(opts.PublicServerUrl)
@@ -140,6 +169,23 @@ func GetHttpApplication(opts HttpApplicationOpts) (http.Handler, error) {
 		models.SetSessionSigningToken(opts.SessionSigningToken)
 	}
 
+	models.SetSecretsMasterKey(opts.SecretsMasterKey)
+
+	if opts.ApproverConfig == nil {
+		*serviceLogs <- common.ServiceLogf(common.LogLevelWarn, "approvals are not enabled")
+	} else {
+		approverClient, err = approver.NewClient(approver.NewClientOpts{
+			ApproverUrl: opts.ApproverConfig.Url,
+			BasicAuth:   opts.ApproverConfig.BasicAuth,
+			BearerAuth:  opts.ApproverConfig.BearerAuth,
+			Id:          "opsicle-controller",
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create approver client: %w", err)
+		}
+	}
+	go startAutomationScheduler(opts.Context)
+
 	if opts.EmailConfig == nil {
 		*serviceLogs <- common.ServiceLogf(common.LogLevelWarn, "email is not enabled")
 	} else {
@@ -168,8 +214,12 @@ func GetHttpApplication(opts HttpApplicationOpts) (http.Handler, error) {
 	}
 
 	registerAutomationRoutes(apiOpts)
+	registerAutomationScheduleRoutes(apiOpts)
 	registerAutomationTemplatesRoutes(apiOpts)
+	registerTemplateTriggerRoutes(apiOpts)
 	registerOrgRoutes(apiOpts)
+	registerOrgApprovalPolicyRoutes(apiOpts)
+	registerOrgSecretRoutes(apiOpts)
 	registerSessionRoutes(apiOpts)
 	registerUserRoutes(apiOpts)
 	registerUtilityRoutes(apiOpts)
@@ -0,0 +1,4 @@
+ALTER TABLE `automations`
+    DROP COLUMN `completed_at`,
+    DROP COLUMN `started_at`,
+    DROP COLUMN `run_status`;
@@ -0,0 +1,4 @@
+ALTER TABLE `automations`
+    ADD COLUMN `run_status` JSON AFTER `last_known_status`,
+    ADD COLUMN `started_at` DATETIME NULL AFTER `triggerer_comment`,
+    ADD COLUMN `completed_at` DATETIME NULL AFTER `started_at`;
@@ -0,0 +1 @@
+ALTER TABLE `automations` MODIFY COLUMN `logs` LONGTEXT;
@@ -0,0 +1,2 @@
+DELETE FROM `org_role_permissions` WHERE `resource` = 'secrets';
+DROP TABLE IF EXISTS `org_secrets`;
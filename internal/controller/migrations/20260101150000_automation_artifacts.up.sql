@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS `automation_artifacts` (
+    `automation_id` VARCHAR(36) NOT NULL,
+    `org_id` VARCHAR(36) NULL,
+    `content` LONGBLOB NOT NULL,
+    `size` BIGINT NOT NULL,
+    `checksum` VARCHAR(64) NOT NULL,
+    `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`automation_id`),
+    CONSTRAINT `fk_automation_artifacts_automation` FOREIGN KEY (`automation_id`) REFERENCES `automations`(`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT `fk_automation_artifacts_org` FOREIGN KEY (`org_id`) REFERENCES `orgs`(`id`) ON DELETE SET NULL ON UPDATE CASCADE
+);
@@ -0,0 +1,11 @@
+UPDATE `automations` SET `last_known_status` = 'completed-failed' WHERE `last_known_status` = 'cancelled';
+ALTER TABLE `automations` MODIFY COLUMN `last_known_status` ENUM(
+    'created',
+    'accepted',
+    'rejected',
+    'pending-approval',
+    'pending-execution',
+    'executing',
+    'completed-success',
+    'completed-failed'
+) DEFAULT 'created';
@@ -0,0 +1,11 @@
+ALTER TABLE `automations` MODIFY COLUMN `last_known_status` ENUM(
+    'created',
+    'accepted',
+    'rejected',
+    'pending-approval',
+    'pending-execution',
+    'executing',
+    'completed-success',
+    'completed-failed',
+    'cancelled'
+) DEFAULT 'created';
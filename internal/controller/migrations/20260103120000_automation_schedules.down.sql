@@ -0,0 +1,2 @@
+DELETE FROM `org_role_permissions` WHERE `resource` = 'schedules';
+DROP TABLE IF EXISTS `automation_schedules`;
@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS `automation_schedules` (
+    `id` VARCHAR(36) NOT NULL,
+    `org_id` VARCHAR(36) NULL,
+    `name` VARCHAR(255) NOT NULL,
+    `cron_expression` VARCHAR(255) NOT NULL,
+    `timezone` VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    `template_id` VARCHAR(36) NOT NULL,
+    `template_version` BIGINT NULL,
+    `variable_map` JSON NULL,
+    `is_paused` BOOLEAN NOT NULL DEFAULT FALSE,
+    `next_run_at` DATETIME NULL,
+    `last_run_at` DATETIME NULL,
+    `last_automation_id` VARCHAR(36) NULL,
+    `created_by` VARCHAR(36) NOT NULL,
+    `last_updated_by` VARCHAR(36) NULL,
+    `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `last_updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_automation_schedules_next_run_at` (`is_paused`, `next_run_at`),
+    CONSTRAINT `fk_automation_schedules_org` FOREIGN KEY (`org_id`) REFERENCES `orgs`(`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT `fk_automation_schedules_template` FOREIGN KEY (`template_id`) REFERENCES `templates`(`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT `fk_automation_schedules_last_automation` FOREIGN KEY (`last_automation_id`) REFERENCES `automations`(`id`) ON DELETE SET NULL ON UPDATE CASCADE,
+    CONSTRAINT `fk_automation_schedules_created_by` FOREIGN KEY (`created_by`) REFERENCES `users`(`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT `fk_automation_schedules_last_updated_by` FOREIGN KEY (`last_updated_by`) REFERENCES `users`(`id`) ON DELETE SET NULL ON UPDATE CASCADE
+);
+
+INSERT INTO `org_role_permissions` (`id`, `org_role_id`, `resource`, `allows`, `denys`)
+    SELECT UUID(), `id`, 'schedules', 63, 0
+        FROM `org_roles`
+        WHERE `name` = 'Administrator (Default)';
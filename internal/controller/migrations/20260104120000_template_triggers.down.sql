@@ -0,0 +1,2 @@
+DELETE FROM `org_role_permissions` WHERE `resource` = 'triggers';
+DROP TABLE IF EXISTS `template_triggers`;
@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS `template_triggers` (
+    `id` VARCHAR(36) NOT NULL,
+    `org_id` VARCHAR(36) NULL,
+    `name` VARCHAR(255) NOT NULL,
+    `template_id` VARCHAR(36) NOT NULL,
+    `template_version` BIGINT NULL,
+    `auth_type` ENUM('hmac', 'org_token') NOT NULL,
+    `hmac_secret` TEXT NULL,
+    `org_token_id` VARCHAR(36) NULL,
+    `variable_mapping` JSON NULL,
+    `dedup_key` VARCHAR(1024) NULL,
+    `dedup_window_seconds` INT NOT NULL DEFAULT 300,
+    `rate_limit` INT NOT NULL DEFAULT 0,
+    `rate_limit_window_seconds` INT NOT NULL DEFAULT 60,
+    `last_triggered_at` DATETIME NULL,
+    `last_automation_id` VARCHAR(36) NULL,
+    `created_by` VARCHAR(36) NOT NULL,
+    `last_updated_by` VARCHAR(36) NULL,
+    `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `last_updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    KEY `idx_template_triggers_template` (`template_id`),
+    CONSTRAINT `fk_template_triggers_org` FOREIGN KEY (`org_id`) REFERENCES `orgs`(`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT `fk_template_triggers_template` FOREIGN KEY (`template_id`) REFERENCES `templates`(`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT `fk_template_triggers_org_token` FOREIGN KEY (`org_token_id`) REFERENCES `org_tokens`(`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT `fk_template_triggers_last_automation` FOREIGN KEY (`last_automation_id`) REFERENCES `automations`(`id`) ON DELETE SET NULL ON UPDATE CASCADE,
+    CONSTRAINT `fk_template_triggers_created_by` FOREIGN KEY (`created_by`) REFERENCES `users`(`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT `fk_template_triggers_last_updated_by` FOREIGN KEY (`last_updated_by`) REFERENCES `users`(`id`) ON DELETE SET NULL ON UPDATE CASCADE
+);
+
+INSERT INTO `org_role_permissions` (`id`, `org_role_id`, `resource`, `allows`, `denys`)
+    SELECT UUID(), `id`, 'triggers', 63, 0
+        FROM `org_roles`
+        WHERE `name` = 'Administrator (Default)';
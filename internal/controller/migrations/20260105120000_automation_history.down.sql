@@ -0,0 +1,10 @@
+DROP INDEX `idx_automations_status_triggered_at` ON `automations`;
+ALTER TABLE `automations`
+    ADD INDEX `idx_automations_template_id` (`template_id`),
+    DROP INDEX `idx_automations_template_triggered_at`;
+ALTER TABLE `automations`
+    ADD INDEX `idx_automations_triggered_by` (`triggered_by`),
+    DROP INDEX `idx_automations_triggered_by_triggered_at`;
+ALTER TABLE `automations`
+    ADD INDEX `idx_automations_org_id` (`org_id`),
+    DROP INDEX `idx_automations_org_triggered_at`;
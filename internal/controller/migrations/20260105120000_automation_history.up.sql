@@ -0,0 +1,4 @@
+CREATE INDEX `idx_automations_org_triggered_at` ON `automations` (`org_id`, `triggered_at`, `id`);
+CREATE INDEX `idx_automations_triggered_by_triggered_at` ON `automations` (`triggered_by`, `triggered_at`, `id`);
+CREATE INDEX `idx_automations_template_triggered_at` ON `automations` (`template_id`, `triggered_at`, `id`);
+CREATE INDEX `idx_automations_status_triggered_at` ON `automations` (`last_known_status`, `triggered_at`, `id`);
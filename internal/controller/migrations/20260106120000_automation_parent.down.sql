@@ -0,0 +1,5 @@
+ALTER TABLE `automations`
+    DROP FOREIGN KEY `fk_automations_parent_automation`;
+ALTER TABLE `automations`
+    DROP INDEX `idx_automations_parent_automation_id`,
+    DROP COLUMN `parent_automation_id`;
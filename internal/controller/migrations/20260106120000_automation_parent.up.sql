@@ -0,0 +1,4 @@
+ALTER TABLE `automations`
+    ADD COLUMN `parent_automation_id` VARCHAR(36) NULL AFTER `template_version`,
+    ADD INDEX `idx_automations_parent_automation_id` (`parent_automation_id`),
+    ADD CONSTRAINT `fk_automations_parent_automation` FOREIGN KEY (`parent_automation_id`) REFERENCES `automations`(`id`) ON DELETE SET NULL ON UPDATE CASCADE;
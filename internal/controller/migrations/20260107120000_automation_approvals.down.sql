@@ -0,0 +1,3 @@
+ALTER TABLE `automations`
+    DROP COLUMN `approval_request_id`,
+    DROP COLUMN `resume_from_phase`;
@@ -0,0 +1,3 @@
+ALTER TABLE `automations`
+    ADD COLUMN `resume_from_phase` VARCHAR(255) NULL AFTER `parent_automation_id`,
+    ADD COLUMN `approval_request_id` VARCHAR(36) NULL AFTER `resume_from_phase`;
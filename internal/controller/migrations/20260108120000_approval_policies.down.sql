@@ -0,0 +1,2 @@
+DELETE FROM `org_role_permissions` WHERE `resource` = 'approval_policies';
+DROP TABLE IF EXISTS `approval_policies`;
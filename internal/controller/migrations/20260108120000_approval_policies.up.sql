@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS `approval_policies` (
+    `id` VARCHAR(36) NOT NULL,
+    `org_id` VARCHAR(36) NOT NULL,
+    `name` VARCHAR(255) NOT NULL,
+    `spec` LONGTEXT NOT NULL,
+    `created_by` VARCHAR(36) NULL,
+    `last_updated_by` VARCHAR(36) NULL,
+    `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `last_updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_approval_policies_org_name` (`org_id`, `name`),
+    CONSTRAINT `fk_approval_policies_org` FOREIGN KEY (`org_id`) REFERENCES `orgs`(`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT `fk_approval_policies_created_by` FOREIGN KEY (`created_by`) REFERENCES `users`(`id`) ON DELETE SET NULL ON UPDATE CASCADE,
+    CONSTRAINT `fk_approval_policies_last_updated_by` FOREIGN KEY (`last_updated_by`) REFERENCES `users`(`id`) ON DELETE SET NULL ON UPDATE CASCADE
+);
+
+INSERT INTO `org_role_permissions` (`id`, `org_role_id`, `resource`, `allows`, `denys`)
+    SELECT UUID(), `id`, 'approval_policies', 63, 0
+        FROM `org_roles`
+        WHERE `name` = 'Administrator (Default)';
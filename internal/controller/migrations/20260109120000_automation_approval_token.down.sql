@@ -0,0 +1,2 @@
+ALTER TABLE `automations`
+    DROP COLUMN `approval_token_hash`;
@@ -0,0 +1,2 @@
+ALTER TABLE `automations`
+    ADD COLUMN `approval_token_hash` VARCHAR(64) NULL AFTER `approval_request_id`;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS `automation_log_chunks` (
+    `id` BIGINT NOT NULL AUTO_INCREMENT,
+    `automation_id` VARCHAR(36) NOT NULL,
+    `data` MEDIUMTEXT NOT NULL,
+    `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`id`),
+    INDEX `idx_automation_log_chunks_automation` (`automation_id`, `id`),
+    CONSTRAINT `fk_automation_log_chunks_automation` FOREIGN KEY (`automation_id`) REFERENCES `automations`(`id`) ON DELETE CASCADE ON UPDATE CASCADE
+);
+INSERT INTO `automation_log_chunks` (`automation_id`, `data`)
+    SELECT `id`, `logs` FROM `automations`
+        WHERE `logs` IS NOT NULL AND `logs` != '';
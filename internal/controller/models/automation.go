@@ -7,13 +7,13 @@ import (
 	"fmt"
 	"opsicle/internal/automations"
 	"opsicle/internal/cache"
+	"opsicle/internal/common"
 	"opsicle/internal/queue"
 	"opsicle/internal/validate"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/sirupsen/logrus"
 )
 
 type CreatePendingAutomationV1Opts struct {
@@ -24,6 +24,18 @@ type CreatePendingAutomationV1Opts struct {
 	TemplateVersion  int64
 	TriggeredBy      string
 	TriggererComment string
+
+	// InputVars when defined are the stored variables of a previous
+	// run which are used as the defaults of the automation's variables
+	InputVars []byte
+
+	// ParentAutomationId when defined is the ID of the automation which
+	// the automation is a re-run of
+	ParentAutomationId *string
+
+	// ResumeFromPhase when defined is the phase of the parent automation
+	// which the automation resumes from
+	ResumeFromPhase string
 }
 
 // CreatePendingAutomationV1 creates an Automation instance and inserts
@@ -38,8 +50,11 @@ func CreatePendingAutomationV1(opts CreatePendingAutomationV1Opts) (*Automation,
 		TriggeredBy: &User{
 			Id: &opts.TriggeredBy,
 		},
-		TriggeredAt:      time.Now(),
-		TriggererComment: opts.TriggererComment,
+		TriggeredAt:        time.Now(),
+		TriggererComment:   opts.TriggererComment,
+		InputVars:          opts.InputVars,
+		ParentAutomationId: opts.ParentAutomationId,
+		ResumeFromPhase:    opts.ResumeFromPhase,
 	}
 	if opts.OrgId != nil {
 		if err := validate.Uuid(*opts.OrgId); err != nil {
@@ -60,20 +75,32 @@ func CreatePendingAutomationV1(opts CreatePendingAutomationV1Opts) (*Automation,
 }
 
 type Automation struct {
-	Id                *string   `json:"id"`
-	InputVars         []byte    `json:"inputVars"`
-	LastKnownStatus   string    `json:"lastKnownStatus"`
-	Logs              []byte    `json:"logs"`
-	OrgId             *string   `json:"orgId"`
-	TemplateId        string    `json:"templateId"`
-	TemplateVersion   int64     `json:"templateVersion"`
-	TemplateContent   []byte    `json:"templateContent"`
-	TemplateCreatedBy *User     `json:"templateCreatedBy"`
-	TriggeredBy       *User     `json:"triggeredBy"`
-	TriggeredAt       time.Time `json:"triggeredAt"`
-	TriggererComment  string    `json:"triggererComment"`
-	CreatedAt         time.Time `json:"createdAt"`
-	LastUpdatedAt     time.Time `json:"lastUpdatedAt"`
+	Id                 *string                `json:"id"`
+	ApprovalRequestId  *string                `json:"approvalRequestId"`
+	ApprovalTokenHash  *string                `json:"-"`
+	InputVars          []byte                 `json:"inputVars"`
+	LastKnownStatus    string                 `json:"lastKnownStatus"`
+	Logs               []byte                 `json:"logs"`
+	OrgId              *string                `json:"orgId"`
+	ParentAutomationId *string                `json:"parentAutomationId"`
+	ResumeFromPhase    string                 `json:"resumeFromPhase"`
+	RunStatus          *automations.RunStatus `json:"runStatus"`
+	TemplateId         string                 `json:"templateId"`
+	TemplateVersion    int64                  `json:"templateVersion"`
+	TemplateContent    []byte                 `json:"templateContent"`
+	TemplateName       string                 `json:"templateName"`
+	TemplateCreatedBy  *User                  `json:"templateCreatedBy"`
+	TriggeredBy        *User                  `json:"triggeredBy"`
+	TriggeredAt        time.Time              `json:"triggeredAt"`
+	TriggererComment   string                 `json:"triggererComment"`
+	StartedAt          *time.Time             `json:"startedAt"`
+	CompletedAt        *time.Time             `json:"completedAt"`
+	CreatedAt          time.Time              `json:"createdAt"`
+	LastUpdatedAt      time.Time              `json:"lastUpdatedAt"`
+}
+
+func (a *Automation) GetId() string {
+	return *a.Id
 }
 
 func (a *Automation) assertId() error {
@@ -94,15 +121,22 @@ func (a *Automation) assertTemplateContent() error {
 
 func (a *Automation) CreateV1(opts DatabaseConnection) error {
 	insertMap := map[string]any{
-		"id":                a.Id,
-		"input_vars":        a.InputVars,
-		"org_id":            a.OrgId,
-		"template_content":  a.TemplateContent,
-		"template_id":       a.TemplateId,
-		"template_version":  a.TemplateVersion,
-		"triggered_by":      a.TriggeredBy.GetId(),
-		"triggered_at":      a.TriggeredAt,
-		"triggerer_comment": a.TriggererComment,
+		"id":                   a.Id,
+		"input_vars":           a.InputVars,
+		"org_id":               a.OrgId,
+		"parent_automation_id": a.ParentAutomationId,
+		"template_content":     a.TemplateContent,
+		"template_id":          a.TemplateId,
+		"template_version":     a.TemplateVersion,
+		"triggered_by":         a.TriggeredBy.GetId(),
+		"triggered_at":         a.TriggeredAt,
+		"triggerer_comment":    a.TriggererComment,
+	}
+	if a.LastKnownStatus != "" {
+		insertMap["last_known_status"] = a.LastKnownStatus
+	}
+	if a.ResumeFromPhase != "" {
+		insertMap["resume_from_phase"] = a.ResumeFromPhase
 	}
 	fields := []string{}
 	valuePlaceholders := []string{}
@@ -151,18 +185,31 @@ func (a *Automation) LoadV1(opts DatabaseConnection) error {
 	if a.TriggeredBy == nil {
 		a.TriggeredBy = &User{}
 	}
+	var runStatus []byte
+	var resumeFromPhase sql.NullString
 	if err := executeMysqlSelect(mysqlQueryInput{
 		Db: opts.Db,
 		Stmt: `
 			SELECT
 				id,
+				approval_request_id,
+				approval_token_hash,
+				input_vars,
+				last_known_status,
 				org_id,
+				parent_automation_id,
+				resume_from_phase,
+				run_status,
 				template_content,
 				template_id,
 				template_version,
 				triggered_at,
 				triggered_by,
-				triggerer_comment
+				triggerer_comment,
+				started_at,
+				completed_at,
+				created_at,
+				last_updated_at
 				FROM automations
 					WHERE id = ?
 		`,
@@ -171,18 +218,36 @@ func (a *Automation) LoadV1(opts DatabaseConnection) error {
 		ProcessRow: func(r *sql.Row) error {
 			return r.Scan(
 				&a.Id,
+				&a.ApprovalRequestId,
+				&a.ApprovalTokenHash,
+				&a.InputVars,
+				&a.LastKnownStatus,
 				&a.OrgId,
+				&a.ParentAutomationId,
+				&resumeFromPhase,
+				&runStatus,
 				&a.TemplateContent,
 				&a.TemplateId,
 				&a.TemplateVersion,
 				&a.TriggeredAt,
 				&a.TriggeredBy.Id,
 				&a.TriggererComment,
+				&a.StartedAt,
+				&a.CompletedAt,
+				&a.CreatedAt,
+				&a.LastUpdatedAt,
 			)
 		},
 	}); err != nil {
 		return err
 	}
+	a.ResumeFromPhase = resumeFromPhase.String
+	a.RunStatus = &automations.RunStatus{Status: automations.RunStatusCode(a.LastKnownStatus)}
+	if len(runStatus) > 0 {
+		if err := json.Unmarshal(runStatus, a.RunStatus); err != nil {
+			return fmt.Errorf("models.Automation.LoadV1: failed to unmarshal run status: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -199,8 +264,167 @@ type QueueAutomationRunV1Opts struct {
 	OrgId *string
 }
 
-// QueueRun submits the automation to the queue for processing
+// RunV1 submits the automation to the queue for processing
 func (a *Automation) RunV1(opts QueueAutomationRunV1Opts) (*QueueAutomationRunV1Output, error) {
+	automationInstance, err := a.getRun(opts.Db, opts.Input)
+	if err != nil {
+		return nil, err
+	}
+	a.LastKnownStatus = string(automations.RunStatusPendingExecution)
+	if err := a.CreateV1(DatabaseConnection{Db: opts.Db}); err != nil {
+		return nil, fmt.Errorf("failed to insert automation run to db: %w", err)
+	}
+	if err := pushRun(opts.Q, a.OrgId, automationInstance); err != nil {
+		return nil, err
+	}
+	return &QueueAutomationRunV1Output{
+		AutomationRunId:    automationInstance.Spec.Status.Id,
+		AutomationQueuedAt: automationInstance.Spec.Status.QueuedAt,
+	}, nil
+}
+
+// HoldForApprovalV1 validates the variables of the automation and
+// inserts it in status `pending-approval` without submitting it to the
+// queue, RunApprovedV1 submits it once it has been approved
+func (a *Automation) HoldForApprovalV1(opts QueueAutomationRunV1Opts) error {
+	if _, err := a.getRun(opts.Db, opts.Input); err != nil {
+		return err
+	}
+	a.LastKnownStatus = string(automations.RunStatusPendingApproval)
+	if err := a.CreateV1(DatabaseConnection{Db: opts.Db}); err != nil {
+		return fmt.Errorf("failed to insert automation run to db: %w", err)
+	}
+	return nil
+}
+
+// SetApprovalRequestV1 records the ID of the approval request which
+// decides whether the automation is run and the hash of the token which
+// the approver has to present when it calls back with the result
+func (a *Automation) SetApprovalRequestV1(opts DatabaseConnection, approvalRequestId, approvalTokenHash string) error {
+	if err := a.assertId(); err != nil {
+		return err
+	}
+	if err := executeMysqlUpdate(mysqlQueryInput{
+		Db:           opts.Db,
+		Stmt:         `UPDATE automations SET approval_request_id = ?, approval_token_hash = ? WHERE id = ?`,
+		Args:         []any{approvalRequestId, approvalTokenHash, *a.Id},
+		FnSource:     fmt.Sprintf("models.Automation.SetApprovalRequestV1[%s]", *a.Id),
+		RowsAffected: oneRowAffected,
+	}); err != nil {
+		return err
+	}
+	a.ApprovalRequestId = &approvalRequestId
+	a.ApprovalTokenHash = &approvalTokenHash
+	return nil
+}
+
+type ResolveAutomationApprovalV1Opts struct {
+	Db *sql.DB
+	Q  queue.Instance
+
+	// Message describes who resolved the approval and is recorded as
+	// the message of the automation's status
+	Message string
+}
+
+// RunApprovedV1 submits an automation held by HoldForApprovalV1 to the
+// queue with the variables it was held with; ErrorAutomationNotPendingApproval
+// is returned if the automation is no longer pending approval
+func (a *Automation) RunApprovedV1(opts ResolveAutomationApprovalV1Opts) (*QueueAutomationRunV1Output, error) {
+	if err := a.resolveApproval(opts.Db, automations.RunStatusUpdate{
+		Status:  automations.RunStatusPendingExecution,
+		Message: opts.Message,
+	}); err != nil {
+		return nil, err
+	}
+	automationInstance, err := a.getRun(opts.Db, nil)
+	if err == nil {
+		err = pushRun(opts.Q, a.OrgId, automationInstance)
+	}
+	if err != nil {
+		if updateErr := a.UpdateStatusV1(UpdateAutomationStatusV1Opts{
+			Db: DatabaseConnection{Db: opts.Db},
+			Update: automations.RunStatusUpdate{
+				AutomationId: *a.Id,
+				Status:       automations.RunStatusCompletedFailed,
+				Message:      fmt.Sprintf("failed to queue approved automation: %s", err),
+			},
+		}); updateErr != nil {
+			return nil, fmt.Errorf("models.Automation.RunApprovedV1: %w (failed to update status: %w)", err, updateErr)
+		}
+		return nil, fmt.Errorf("models.Automation.RunApprovedV1: %w", err)
+	}
+	return &QueueAutomationRunV1Output{
+		AutomationRunId:    automationInstance.Spec.Status.Id,
+		AutomationQueuedAt: automationInstance.Spec.Status.QueuedAt,
+	}, nil
+}
+
+// RejectV1 completes an automation held by HoldForApprovalV1 with the
+// status `rejected`; ErrorAutomationNotPendingApproval is returned if
+// the automation is no longer pending approval
+func (a *Automation) RejectV1(opts ResolveAutomationApprovalV1Opts) error {
+	return a.resolveApproval(opts.Db, automations.RunStatusUpdate{
+		Status:  automations.RunStatusRejected,
+		Message: opts.Message,
+	})
+}
+
+// resolveApproval loads the automation and applies `update` to it if
+// it is in status `pending-approval`, the update is conditional on the
+// status so that only one resolution of the approval takes effect
+func (a *Automation) resolveApproval(db *sql.DB, update automations.RunStatusUpdate) error {
+	if err := a.assertId(); err != nil {
+		return err
+	}
+	if err := a.LoadV1(DatabaseConnection{Db: db}); err != nil {
+		return fmt.Errorf("models.Automation.resolveApproval: failed to load automation: %w", err)
+	}
+	if automations.RunStatusCode(a.LastKnownStatus) != automations.RunStatusPendingApproval {
+		return fmt.Errorf("models.Automation.resolveApproval: %w", ErrorAutomationNotPendingApproval)
+	}
+	update.AutomationId = *a.Id
+	a.RunStatus.Apply(update)
+	a.LastKnownStatus = string(a.RunStatus.Status)
+	a.CompletedAt = a.RunStatus.CompletedAt
+	runStatus, err := json.Marshal(a.RunStatus)
+	if err != nil {
+		return fmt.Errorf("models.Automation.resolveApproval: failed to marshal run status: %w", err)
+	}
+	if err := executeMysqlUpdate(mysqlQueryInput{
+		Db: db,
+		Stmt: `
+			UPDATE automations
+				SET
+					last_known_status = ?,
+					run_status = ?,
+					completed_at = ?,
+					last_updated_at = NOW()
+				WHERE id = ? AND last_known_status = ?
+		`,
+		Args: []any{
+			a.LastKnownStatus,
+			string(runStatus),
+			a.CompletedAt,
+			*a.Id,
+			string(automations.RunStatusPendingApproval),
+		},
+		FnSource:     fmt.Sprintf("models.Automation.resolveApproval[%s]", *a.Id),
+		RowsAffected: oneRowAffected,
+	}); err != nil {
+		if errors.Is(err, ErrorRowsAffectedCheckFailed) {
+			return fmt.Errorf("models.Automation.resolveApproval: %w", ErrorAutomationNotPendingApproval)
+		}
+		return err
+	}
+	return nil
+}
+
+// getRun returns the Automation resource which is submitted to the
+// queue; variables are resolved from `input` on top of the stored
+// variables of the automation if any and are stored encrypted in
+// `.InputVars`
+func (a *Automation) getRun(db *sql.DB, input map[string]any) (*automations.Automation, error) {
 	if err := a.assertId(); err != nil {
 		return nil, err
 	} else if err = a.assertTemplateContent(); err != nil {
@@ -211,89 +435,104 @@ func (a *Automation) RunV1(opts QueueAutomationRunV1Opts) (*QueueAutomationRunV1
 		return nil, fmt.Errorf("invalid template content: %w", err)
 	}
 
-	variables := template.GetVariables()
-	finalVariableMap := map[string]any{}
-	errs := []error{}
-
-	for variableId, variable := range variables {
-		inputValue, inputVarExists := opts.Input[variableId]
-		// assign defaults if it exists
-		if !inputVarExists {
-			if variable.Default != nil {
-				logrus.Infof("assigned variable[%s] the default value[%v]", variableId, variable.Default)
-				inputValue = variable.Default
-			}
+	if len(a.InputVars) > 0 {
+		storedInput, err := a.GetInputVars()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load variables of previous run: %w", err)
 		}
-		// if it's marked as required and doesn't have a value yet...
-		if variable.IsRequired && inputValue == nil {
-			errs = append(errs, fmt.Errorf("var[%s] is required", variableId))
-			continue
-		}
-		// verify the type matches
-		switch variable.Type {
-		case "bool":
-			if _, ok := inputValue.(bool); !ok {
-				errs = append(errs, fmt.Errorf("var[%s] should be but is not a boolean", variableId))
-				continue
-			}
-		case "string":
-			if _, ok := inputValue.(string); !ok {
-				errs = append(errs, fmt.Errorf("var[%s] should be but is not a string", variableId))
-				continue
-			}
-		case "number":
-			if _, ok := inputValue.(float64); !ok {
-				errs = append(errs, fmt.Errorf("var[%s] should be but is not a number", variableId))
-				continue
-			}
-		case "float":
-			if _, ok := inputValue.(float64); !ok {
-				errs = append(errs, fmt.Errorf("var[%s] should be but is not a floating point", variableId))
-				continue
-			}
+		for id, value := range input {
+			storedInput[id] = value
 		}
-		finalVariableMap[variableId] = inputValue
+		input = storedInput
 	}
-
-	if len(errs) > 0 {
-		return nil, errors.Join(errs...)
+	finalVariableMap, err := template.Spec.Variables.Resolve(template.Spec.Variables.GetUserInput(input))
+	if err != nil {
+		return nil, err
 	}
 
 	for i, variable := range template.Spec.Variables {
+		// values of secret variables are never queued, the coordinator
+		// resolves them when the run is handed over to a worker
+		if variable.IsSecret() {
+			template.Spec.Variables[i].Value = nil
+			continue
+		}
 		template.Spec.Variables[i].Value = finalVariableMap[variable.Id]
 	}
 	automationSpec := template.Spec.Template
 	automationSpec.Variables = template.Spec.Variables
+	if template.Spec.Concurrency != nil {
+		concurrency, err := template.Spec.Concurrency.Render(template.Spec.Variables.GetTemplateVars(finalVariableMap))
+		if err != nil {
+			return nil, fmt.Errorf("failed to render concurrency key: %w", err)
+		}
+		automationSpec.Concurrency = &concurrency
+	}
+	if a.ResumeFromPhase != "" {
+		resume, err := a.getResumeSpec(db, automationSpec)
+		if err != nil {
+			return nil, err
+		}
+		automationSpec.Resume = resume
+	}
 	automationSpec.Status.Id = *a.Id
+	automationSpec.Status.OrgId = a.OrgId
 	automationSpec.Status.QueuedAt = time.Now()
-	automationData, err := json.MarshalIndent(automationSpec, "", "  ")
+
+	storedVariableMap, err := encryptSecretVariables(template.Spec.Variables, finalVariableMap)
 	if err != nil {
-		return nil, fmt.Errorf("invalid automation format: %w", errorInputValidationFailed)
+		return nil, fmt.Errorf("failed to encrypt secret variables: %w", err)
 	}
-
-	a.InputVars, err = json.Marshal(finalVariableMap)
+	a.InputVars, err = json.Marshal(storedVariableMap)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal variables: %w", err)
 	}
-	if err := a.CreateV1(DatabaseConnection{Db: opts.Db}); err != nil {
-		return nil, fmt.Errorf("failed to insert automation run to db: %w", err)
-	}
-
-	_, err = opts.Q.Push(queue.PushOpts{
-		Data: automationData,
-		Queue: queue.QueueOpts{
-			Stream:  "automations",
-			Subject: "runs",
+	return &automations.Automation{
+		Resource: common.Resource{
+			ApiVersion: template.ApiVersion,
+			Type:       "Automation",
+			Metadata: common.Metadata{
+				Name:   template.GetName(),
+				Labels: template.Metadata.Labels,
+			},
 		},
-	})
+		Spec: automationSpec,
+	}, nil
+}
+
+// pushRun submits the automation to the runs queue of the org
+func pushRun(q queue.Instance, orgId *string, automationInstance *automations.Automation) error {
+	automationData, err := json.MarshalIndent(automationInstance, "", "  ")
 	if err != nil {
-		return nil, fmt.Errorf("failed to insert automation run to q: %w", err)
+		return fmt.Errorf("invalid automation format: %w", errorInputValidationFailed)
 	}
-
-	output := &QueueAutomationRunV1Output{
-		AutomationRunId:    automationSpec.Status.Id,
-		AutomationQueuedAt: automationSpec.Status.QueuedAt,
+	if _, err := q.Push(queue.PushOpts{
+		Data:  automationData,
+		Queue: automations.GetRunsQueue(orgId),
+	}); err != nil {
+		return fmt.Errorf("failed to insert automation run to q: %w", err)
 	}
+	return nil
+}
 
-	return output, nil
+// getResumeSpec returns the spec which resumes the parent automation of
+// the automation from the phase `.ResumeFromPhase` using the results
+// of the parent automation's phases
+func (a *Automation) getResumeSpec(db *sql.DB, automationSpec automations.AutomationSpec) (*automations.ResumeSpec, error) {
+	if a.ParentAutomationId == nil {
+		return nil, fmt.Errorf("models.Automation.getResumeSpec: %w: a parent automation is required to resume from a phase", ErrorInvalidInput)
+	}
+	parent := Automation{Id: a.ParentAutomationId}
+	if err := parent.LoadV1(DatabaseConnection{Db: db}); err != nil {
+		return nil, fmt.Errorf("models.Automation.getResumeSpec: failed to load parent automation: %w", err)
+	}
+	phaseResults, err := automationSpec.GetResumedPhaseResults(a.ResumeFromPhase, *parent.RunStatus)
+	if err != nil {
+		return nil, fmt.Errorf("models.Automation.getResumeSpec: %w", err)
+	}
+	return &automations.ResumeSpec{
+		ParentAutomationId: *a.ParentAutomationId,
+		FromPhase:          a.ResumeFromPhase,
+		PhaseResults:       phaseResults,
+	}, nil
 }
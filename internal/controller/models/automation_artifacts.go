@@ -0,0 +1,110 @@
+package models
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"opsicle/internal/automations"
+	"strings"
+	"time"
+)
+
+type AutomationArtifacts struct {
+	AutomationId string    `json:"automationId"`
+	OrgId        *string   `json:"orgId"`
+	Content      []byte    `json:"-"`
+	Size         int64     `json:"size"`
+	Checksum     string    `json:"checksum"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+type SetAutomationArtifactsV1Opts struct {
+	Db DatabaseConnection
+
+	// Content is the gzipped tarball of the artifacts
+	Content []byte
+}
+
+// SetArtifactsV1 stores the artifacts of the automation, replacing any
+// artifacts that were previously stored
+func (a *Automation) SetArtifactsV1(opts SetAutomationArtifactsV1Opts) (*AutomationArtifacts, error) {
+	if err := a.assertId(); err != nil {
+		return nil, err
+	}
+	if len(opts.Content) == 0 {
+		return nil, fmt.Errorf("artifacts must not be empty: %w", ErrorInvalidInput)
+	}
+	if len(opts.Content) > automations.MaxArtifactsSize {
+		return nil, fmt.Errorf("artifacts exceed %v bytes: %w", automations.MaxArtifactsSize, ErrorInvalidInput)
+	}
+	checksum := sha256.Sum256(opts.Content)
+	insertMap := map[string]any{
+		"automation_id": *a.Id,
+		"org_id":        a.OrgId,
+		"content":       opts.Content,
+		"size":          int64(len(opts.Content)),
+		"checksum":      hex.EncodeToString(checksum[:]),
+	}
+	fieldNames, fieldValues, fieldPlaceholders, err := parseInsertMap(insertMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse insert map: %w", err)
+	}
+	if err := executeMysqlInsert(mysqlQueryInput{
+		Db: opts.Db.Db,
+		Stmt: fmt.Sprintf(
+			`INSERT INTO automation_artifacts (%s) VALUES (%s)
+				ON DUPLICATE KEY UPDATE
+					content = VALUES(content),
+					size = VALUES(size),
+					checksum = VALUES(checksum),
+					created_at = NOW()`,
+			strings.Join(fieldNames, ", "),
+			strings.Join(fieldPlaceholders, ", "),
+		),
+		Args:         fieldValues,
+		FnSource:     fmt.Sprintf("models.Automation.SetArtifactsV1[%s]", *a.Id),
+		RowsAffected: atLeastNRowsAffected(1),
+	}); err != nil {
+		return nil, err
+	}
+	return a.GetArtifactsV1(opts.Db)
+}
+
+// GetArtifactsV1 returns the stored artifacts of the automation,
+// ErrorNotFound is returned if the automation has no artifacts
+func (a *Automation) GetArtifactsV1(db DatabaseConnection) (*AutomationArtifacts, error) {
+	if err := a.assertId(); err != nil {
+		return nil, err
+	}
+	var artifacts AutomationArtifacts
+	if err := executeMysqlSelect(mysqlQueryInput{
+		Db: db.Db,
+		Stmt: `
+			SELECT
+				automation_id,
+				org_id,
+				content,
+				size,
+				checksum,
+				created_at
+				FROM automation_artifacts
+					WHERE automation_id = ?
+		`,
+		Args:     []any{*a.Id},
+		FnSource: fmt.Sprintf("models.Automation.GetArtifactsV1[%s]", *a.Id),
+		ProcessRow: func(r *sql.Row) error {
+			return r.Scan(
+				&artifacts.AutomationId,
+				&artifacts.OrgId,
+				&artifacts.Content,
+				&artifacts.Size,
+				&artifacts.Checksum,
+				&artifacts.CreatedAt,
+			)
+		},
+	}); err != nil {
+		return nil, err
+	}
+	return &artifacts, nil
+}
@@ -0,0 +1,225 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultAutomationListLimit is the number of automations returned
+	// when a limit is not specified
+	DefaultAutomationListLimit = 20
+
+	// MaxAutomationListLimit is the maximum number of automations which
+	// can be returned in a single page
+	MaxAutomationListLimit = 100
+)
+
+// AutomationCursor identifies the position of an automation in the
+// run history which is sorted by when automations were triggered
+// followed by their ID, both descending
+type AutomationCursor struct {
+	TriggeredAt time.Time
+	Id          string
+}
+
+// String returns the opaque representation of the cursor that is
+// passed to and from API consumers
+func (c AutomationCursor) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.TriggeredAt.UTC().Format(time.RFC3339Nano) + "|" + c.Id))
+}
+
+// ParseAutomationCursor parses a cursor created by AutomationCursor.String
+func ParseAutomationCursor(cursor string) (*AutomationCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cursor is not valid base64", ErrorInvalidInput)
+	}
+	triggeredAt, id, found := strings.Cut(string(data), "|")
+	if !found || id == "" {
+		return nil, fmt.Errorf("%w: cursor is malformed", ErrorInvalidInput)
+	}
+	timestamp, err := time.Parse(time.RFC3339Nano, triggeredAt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cursor timestamp is invalid", ErrorInvalidInput)
+	}
+	return &AutomationCursor{TriggeredAt: timestamp, Id: id}, nil
+}
+
+type ListAutomationsV1Opts struct {
+	DatabaseConnection
+
+	// OrgId when defined lists the automations of the org
+	OrgId *string
+
+	// TriggeredBy when defined lists only automations triggered by the
+	// user with this ID; at least one of `.OrgId` or `.TriggeredBy` is
+	// required
+	TriggeredBy *string
+
+	// TemplateId when defined lists only automations of the template
+	TemplateId *string
+
+	// Status when defined lists only automations in this status
+	Status *string
+
+	// Since when defined lists only automations triggered at or after
+	// this time
+	Since *time.Time
+
+	// Until when defined lists only automations triggered before this
+	// time
+	Until *time.Time
+
+	// Comment when defined lists only automations whose triggerer's
+	// comment contains this text
+	Comment *string
+
+	// Cursor when defined lists automations triggered before the
+	// automation it points to
+	Cursor *AutomationCursor
+
+	// Limit is the maximum number of automations to return, this
+	// defaults to DefaultAutomationListLimit
+	Limit int
+}
+
+type ListAutomationsV1Output struct {
+	Automations []Automation
+
+	// NextCursor points to the last automation returned and is defined
+	// only when there are more automations to list
+	NextCursor *AutomationCursor
+}
+
+// ListAutomationsV1 returns automations matching the filters sorted by
+// when they were triggered, latest first
+func ListAutomationsV1(opts ListAutomationsV1Opts) (*ListAutomationsV1Output, error) {
+	if opts.OrgId == nil && opts.TriggeredBy == nil {
+		return nil, fmt.Errorf("models.ListAutomationsV1: %w: org id or triggered by is required", ErrorInvalidInput)
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultAutomationListLimit
+	} else if limit > MaxAutomationListLimit {
+		limit = MaxAutomationListLimit
+	}
+
+	filters := []string{}
+	args := []any{}
+	if opts.OrgId != nil {
+		filters = append(filters, "a.org_id = ?")
+		args = append(args, *opts.OrgId)
+	}
+	if opts.TriggeredBy != nil {
+		filters = append(filters, "a.triggered_by = ?")
+		args = append(args, *opts.TriggeredBy)
+	}
+	if opts.TemplateId != nil {
+		filters = append(filters, "a.template_id = ?")
+		args = append(args, *opts.TemplateId)
+	}
+	if opts.Status != nil {
+		filters = append(filters, "a.last_known_status = ?")
+		args = append(args, *opts.Status)
+	}
+	if opts.Since != nil {
+		filters = append(filters, "a.triggered_at >= ?")
+		args = append(args, *opts.Since)
+	}
+	if opts.Until != nil {
+		filters = append(filters, "a.triggered_at < ?")
+		args = append(args, *opts.Until)
+	}
+	if opts.Comment != nil {
+		commentReplacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+		filters = append(filters, "a.triggerer_comment LIKE ?")
+		args = append(args, "%"+commentReplacer.Replace(*opts.Comment)+"%")
+	}
+	if opts.Cursor != nil {
+		filters = append(filters, "(a.triggered_at < ? OR (a.triggered_at = ? AND a.id < ?))")
+		args = append(args, opts.Cursor.TriggeredAt, opts.Cursor.TriggeredAt, opts.Cursor.Id)
+	}
+	args = append(args, limit+1)
+
+	output := ListAutomationsV1Output{Automations: []Automation{}}
+	if err := executeMysqlSelects(mysqlQueryInput{
+		Db: opts.Db,
+		Stmt: fmt.Sprintf(`
+			SELECT
+				a.id,
+				a.last_known_status,
+				a.org_id,
+				a.parent_automation_id,
+				a.template_id,
+				t.name,
+				a.template_version,
+				a.triggered_at,
+				a.triggered_by,
+				u.email,
+				a.triggerer_comment,
+				a.started_at,
+				a.completed_at,
+				a.created_at,
+				a.last_updated_at
+				FROM automations a
+					LEFT JOIN templates t ON t.id = a.template_id
+					LEFT JOIN users u ON u.id = a.triggered_by
+				WHERE %s
+				ORDER BY a.triggered_at DESC, a.id DESC
+				LIMIT ?
+		`, strings.Join(filters, " AND ")),
+		Args:     args,
+		FnSource: "models.ListAutomationsV1",
+		ProcessRows: func(r *sql.Rows) error {
+			var (
+				automation       Automation
+				templateId       sql.NullString
+				templateName     sql.NullString
+				templateVersion  sql.NullInt64
+				triggeredById    sql.NullString
+				triggeredByEmail sql.NullString
+				triggererComment sql.NullString
+			)
+			if err := r.Scan(
+				&automation.Id,
+				&automation.LastKnownStatus,
+				&automation.OrgId,
+				&automation.ParentAutomationId,
+				&templateId,
+				&templateName,
+				&templateVersion,
+				&automation.TriggeredAt,
+				&triggeredById,
+				&triggeredByEmail,
+				&triggererComment,
+				&automation.StartedAt,
+				&automation.CompletedAt,
+				&automation.CreatedAt,
+				&automation.LastUpdatedAt,
+			); err != nil {
+				return err
+			}
+			automation.TemplateId = templateId.String
+			automation.TemplateName = templateName.String
+			automation.TemplateVersion = templateVersion.Int64
+			if triggeredById.Valid {
+				automation.TriggeredBy = &User{Id: &triggeredById.String, Email: triggeredByEmail.String}
+			}
+			automation.TriggererComment = triggererComment.String
+			output.Automations = append(output.Automations, automation)
+			return nil
+		},
+	}); err != nil {
+		return nil, err
+	}
+	if len(output.Automations) > limit {
+		output.Automations = output.Automations[:limit]
+		last := output.Automations[limit-1]
+		output.NextCursor = &AutomationCursor{TriggeredAt: last.TriggeredAt, Id: *last.Id}
+	}
+	return &output, nil
+}
@@ -0,0 +1,120 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type AppendAutomationLogsV1Opts struct {
+	Db    DatabaseConnection
+	Logs  string
+	OrgId *string
+}
+
+// AppendLogsV1 appends the provided logs to the automation's logs as a
+// new log chunk; the logs are only appended if the automation belongs
+// to the org identified by `.OrgId`
+func (a *Automation) AppendLogsV1(opts AppendAutomationLogsV1Opts) error {
+	if err := a.assertId(); err != nil {
+		return err
+	}
+	if opts.Logs == "" {
+		return nil
+	}
+	if err := executeMysqlInsert(mysqlQueryInput{
+		Db: opts.Db.Db,
+		Stmt: `
+			INSERT INTO automation_log_chunks (automation_id, data)
+				SELECT id, ?
+					FROM automations
+						WHERE id = ? AND org_id <=> ?
+		`,
+		Args:         []any{opts.Logs, *a.Id, opts.OrgId},
+		FnSource:     fmt.Sprintf("models.Automation.AppendLogsV1[%s]", *a.Id),
+		RowsAffected: oneRowAffected,
+	}); err != nil {
+		if errors.Is(err, ErrorRowsAffectedCheckFailed) {
+			return fmt.Errorf("%w: %w", ErrorNotFound, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// maxAutomationLogChunksPerRead is the maximum number of log chunks
+// returned by a single call to GetLogsV1
+const maxAutomationLogChunksPerRead = 500
+
+type GetAutomationLogsV1Opts struct {
+	Db DatabaseConnection
+
+	// Offset is the id of the last log chunk that was already retrieved,
+	// use the `.Offset` of a previous output to retrieve subsequent logs
+	Offset int
+}
+
+type GetAutomationLogsV1Output struct {
+	Logs string
+
+	// Offset is the offset that should be used to retrieve logs
+	// following the ones returned
+	Offset int
+
+	// LastKnownStatus is the status of the automation at the time the
+	// logs were retrieved
+	LastKnownStatus string
+}
+
+// GetLogsV1 returns the logs of the automation which were appended
+// after the log chunk identified by `.Offset`
+func (a *Automation) GetLogsV1(opts GetAutomationLogsV1Opts) (*GetAutomationLogsV1Output, error) {
+	if err := a.assertId(); err != nil {
+		return nil, err
+	}
+	output := &GetAutomationLogsV1Output{Offset: max(opts.Offset, 0)}
+	if err := executeMysqlSelect(mysqlQueryInput{
+		Db: opts.Db.Db,
+		Stmt: `
+			SELECT
+				COALESCE(last_known_status, '')
+				FROM automations
+					WHERE id = ?
+		`,
+		Args:     []any{*a.Id},
+		FnSource: fmt.Sprintf("models.Automation.GetLogsV1[%s]", *a.Id),
+		ProcessRow: func(r *sql.Row) error {
+			return r.Scan(&output.LastKnownStatus)
+		},
+	}); err != nil {
+		return nil, err
+	}
+	var logs strings.Builder
+	if err := executeMysqlSelects(mysqlQueryInput{
+		Db: opts.Db.Db,
+		Stmt: `
+			SELECT
+				id,
+				data
+				FROM automation_log_chunks
+					WHERE automation_id = ? AND id > ?
+					ORDER BY id
+					LIMIT ?
+		`,
+		Args:     []any{*a.Id, output.Offset, maxAutomationLogChunksPerRead},
+		FnSource: fmt.Sprintf("models.Automation.GetLogsV1[%s]", *a.Id),
+		ProcessRows: func(r *sql.Rows) error {
+			var data string
+			if err := r.Scan(&output.Offset, &data); err != nil {
+				return err
+			}
+			logs.WriteString(data)
+			return nil
+		},
+	}); err != nil {
+		return nil, err
+	}
+	output.Logs = logs.String()
+	return output, nil
+}
@@ -0,0 +1,160 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"opsicle/internal/validate"
+	"regexp"
+	"time"
+)
+
+var automationScheduleNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_. -]{0,254}$`)
+
+type AutomationSchedules []AutomationSchedule
+
+// AutomationSchedule triggers automations from a template whenever its
+// cron expression fires; automations are triggered as the user who
+// created the schedule
+type AutomationSchedule struct {
+	Id               *string    `json:"id" yaml:"id"`
+	OrgId            *string    `json:"orgId" yaml:"orgId"`
+	Name             string     `json:"name" yaml:"name"`
+	CronExpression   string     `json:"cronExpression" yaml:"cronExpression"`
+	Timezone         string     `json:"timezone" yaml:"timezone"`
+	TemplateId       string     `json:"templateId" yaml:"templateId"`
+	TemplateName     string     `json:"templateName" yaml:"templateName"`
+	TemplateVersion  *int64     `json:"templateVersion" yaml:"templateVersion"`
+	IsPaused         bool       `json:"isPaused" yaml:"isPaused"`
+	NextRunAt        *time.Time `json:"nextRunAt" yaml:"nextRunAt"`
+	LastRunAt        *time.Time `json:"lastRunAt" yaml:"lastRunAt"`
+	LastAutomationId *string    `json:"lastAutomationId" yaml:"lastAutomationId"`
+	CreatedAt        time.Time  `json:"createdAt" yaml:"createdAt"`
+	CreatedBy        *User      `json:"createdBy" yaml:"createdBy"`
+	LastUpdatedAt    time.Time  `json:"lastUpdatedAt" yaml:"lastUpdatedAt"`
+	LastUpdatedBy    *User      `json:"lastUpdatedBy" yaml:"lastUpdatedBy"`
+
+	variableMap []byte
+}
+
+func (as AutomationSchedule) GetId() string {
+	if as.Id == nil {
+		return ""
+	}
+	return *as.Id
+}
+
+// GetOwnerId returns the ID of the user which automations triggered by
+// the schedule are run as
+func (as AutomationSchedule) GetOwnerId() string {
+	if as.CreatedBy == nil || as.CreatedBy.Id == nil {
+		return ""
+	}
+	return *as.CreatedBy.Id
+}
+
+func (as *AutomationSchedule) assertId() error {
+	if as.Id == nil {
+		return fmt.Errorf("%w: missing schedule id", ErrorIdRequired)
+	} else if err := validate.Uuid(*as.Id); err != nil {
+		return fmt.Errorf("%w: invalid schedule id", ErrorInvalidInput)
+	}
+	return nil
+}
+
+// GetVariableMap returns the variable values that automations triggered
+// by the schedule are run with, secret values are decrypted
+func (as AutomationSchedule) GetVariableMap() (map[string]any, error) {
+	values := map[string]any{}
+	if len(as.variableMap) == 0 {
+		return values, nil
+	}
+	if err := json.Unmarshal(as.variableMap, &values); err != nil {
+		return nil, fmt.Errorf("models.AutomationSchedule.GetVariableMap: failed to unmarshal variables: %w", err)
+	}
+	if err := decryptSecretVariables(values); err != nil {
+		return nil, fmt.Errorf("models.AutomationSchedule.GetVariableMap: %w", err)
+	}
+	return values, nil
+}
+
+// ValidateAutomationScheduleName returns an error if the provided name
+// cannot be used as the name of a schedule
+func ValidateAutomationScheduleName(name string) error {
+	if !automationScheduleNameRegex.MatchString(name) {
+		return fmt.Errorf("schedule name must start with an alphanumeric character and contain only alphanumerics, spaces, '_', '.' or '-': %w", ErrorInvalidInput)
+	}
+	return nil
+}
+
+const automationScheduleSelectFields = `
+	ass.id,
+	ass.org_id,
+	ass.name,
+	ass.cron_expression,
+	ass.timezone,
+	ass.template_id,
+	t.name,
+	ass.template_version,
+	ass.variable_map,
+	ass.is_paused,
+	ass.next_run_at,
+	ass.last_run_at,
+	ass.last_automation_id,
+	ass.created_at,
+	ass.created_by,
+	created_by_user.email,
+	ass.last_updated_at,
+	ass.last_updated_by,
+	last_updated_by_user.email
+`
+
+const automationScheduleSelectJoins = `
+	JOIN templates t ON t.id = ass.template_id
+	JOIN users created_by_user ON created_by_user.id = ass.created_by
+	LEFT JOIN users last_updated_by_user ON last_updated_by_user.id = ass.last_updated_by
+`
+
+type automationScheduleScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAutomationSchedule(row automationScheduleScanner) (*AutomationSchedule, error) {
+	var (
+		schedule           AutomationSchedule
+		id                 string
+		createdById        string
+		createdByEmail     string
+		lastUpdatedById    sql.NullString
+		lastUpdatedByEmail sql.NullString
+	)
+	if err := row.Scan(
+		&id,
+		&schedule.OrgId,
+		&schedule.Name,
+		&schedule.CronExpression,
+		&schedule.Timezone,
+		&schedule.TemplateId,
+		&schedule.TemplateName,
+		&schedule.TemplateVersion,
+		&schedule.variableMap,
+		&schedule.IsPaused,
+		&schedule.NextRunAt,
+		&schedule.LastRunAt,
+		&schedule.LastAutomationId,
+		&schedule.CreatedAt,
+		&createdById,
+		&createdByEmail,
+		&schedule.LastUpdatedAt,
+		&lastUpdatedById,
+		&lastUpdatedByEmail,
+	); err != nil {
+		return nil, err
+	}
+	schedule.Id = &id
+	schedule.CreatedBy = &User{Id: &createdById, Email: createdByEmail}
+	if lastUpdatedById.Valid {
+		schedule.LastUpdatedBy = &User{Id: &lastUpdatedById.String, Email: lastUpdatedByEmail.String}
+	}
+	return &schedule, nil
+}
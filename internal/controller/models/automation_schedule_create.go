@@ -0,0 +1,86 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"opsicle/internal/automations"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateAutomationScheduleV1Input struct {
+	DatabaseConnection
+
+	OrgId           *string
+	Name            string
+	CronExpression  string
+	Timezone        string
+	TemplateId      string
+	TemplateVersion *int64
+
+	// Variables is the variables specification of the template, this is
+	// used to identify which values in `.VariableMap` are secrets that
+	// need to be encrypted before they are stored
+	Variables   automations.VariablesSpec
+	VariableMap map[string]any
+
+	UserId string
+}
+
+// CreateAutomationScheduleV1 creates a schedule which triggers the
+// template identified by `.TemplateId` as the user identified by
+// `.UserId` whenever `.CronExpression` fires
+func CreateAutomationScheduleV1(opts CreateAutomationScheduleV1Input) (*AutomationSchedule, error) {
+	if err := ValidateAutomationScheduleName(opts.Name); err != nil {
+		return nil, err
+	}
+	schedule, err := automations.ParseSchedule(opts.CronExpression, opts.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrorInvalidInput, err)
+	}
+	storedVariableMap, err := encryptSecretVariables(opts.Variables, opts.VariableMap)
+	if err != nil {
+		return nil, fmt.Errorf("models.CreateAutomationScheduleV1: failed to encrypt secret variables: %w", err)
+	}
+	variableMap, err := json.Marshal(storedVariableMap)
+	if err != nil {
+		return nil, fmt.Errorf("models.CreateAutomationScheduleV1: failed to marshal variables: %w", err)
+	}
+	scheduleId := uuid.NewString()
+	insertMap := map[string]any{
+		"id":               scheduleId,
+		"org_id":           opts.OrgId,
+		"name":             opts.Name,
+		"cron_expression":  schedule.Expression,
+		"timezone":         schedule.Timezone,
+		"template_id":      opts.TemplateId,
+		"template_version": opts.TemplateVersion,
+		"variable_map":     variableMap,
+		"next_run_at":      schedule.GetNext(time.Now()),
+		"created_by":       opts.UserId,
+		"last_updated_by":  opts.UserId,
+	}
+	fieldNames, fieldValues, fieldPlaceholders, err := parseInsertMap(insertMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse insert map: %w", err)
+	}
+	if err := executeMysqlInsert(mysqlQueryInput{
+		Db: opts.Db,
+		Stmt: fmt.Sprintf(
+			`INSERT INTO automation_schedules (%s) VALUES (%s)`,
+			strings.Join(fieldNames, ", "),
+			strings.Join(fieldPlaceholders, ", "),
+		),
+		Args:         fieldValues,
+		FnSource:     "models.CreateAutomationScheduleV1",
+		RowsAffected: oneRowAffected,
+	}); err != nil {
+		return nil, err
+	}
+	return GetAutomationScheduleV1(GetAutomationScheduleV1Input{
+		DatabaseConnection: opts.DatabaseConnection,
+		ScheduleId:         scheduleId,
+	})
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DeleteV1 deletes the schedule, automations it triggered are retained
+func (as *AutomationSchedule) DeleteV1(opts DatabaseConnection) error {
+	if err := as.assertId(); err != nil {
+		return err
+	}
+	if err := executeMysqlDelete(mysqlQueryInput{
+		Db:           opts.Db,
+		Stmt:         `DELETE FROM automation_schedules WHERE id = ?`,
+		Args:         []any{as.GetId()},
+		FnSource:     "models.AutomationSchedule.DeleteV1",
+		RowsAffected: oneRowAffected,
+	}); err != nil {
+		if errors.Is(err, ErrorRowsAffectedCheckFailed) {
+			return fmt.Errorf("schedule[%s] does not exist: %w", as.GetId(), ErrorNotFound)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"opsicle/internal/validate"
+)
+
+type GetAutomationScheduleV1Input struct {
+	DatabaseConnection
+
+	ScheduleId string
+}
+
+// GetAutomationScheduleV1 returns the schedule identified by
+// `.ScheduleId`
+func GetAutomationScheduleV1(opts GetAutomationScheduleV1Input) (*AutomationSchedule, error) {
+	if err := validate.Uuid(opts.ScheduleId); err != nil {
+		return nil, fmt.Errorf("%w: invalid schedule id", ErrorInvalidInput)
+	}
+	var schedule *AutomationSchedule
+	if err := executeMysqlSelect(mysqlQueryInput{
+		Db: opts.Db,
+		Stmt: fmt.Sprintf(`
+			SELECT %s
+				FROM automation_schedules ass
+				%s
+				WHERE ass.id = ?
+		`, automationScheduleSelectFields, automationScheduleSelectJoins),
+		Args:     []any{opts.ScheduleId},
+		FnSource: "models.GetAutomationScheduleV1",
+		ProcessRow: func(r *sql.Row) error {
+			var err error
+			schedule, err = scanAutomationSchedule(r)
+			return err
+		},
+	}); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type ListAutomationSchedulesV1Input struct {
+	DatabaseConnection
+
+	// OrgId when defined lists the schedules of the org, otherwise the
+	// schedules created by `.UserId` outside of any org are listed
+	OrgId  *string
+	UserId string
+}
+
+// ListAutomationSchedulesV1 returns schedules sorted by name
+func ListAutomationSchedulesV1(opts ListAutomationSchedulesV1Input) (AutomationSchedules, error) {
+	filter := "ass.org_id IS NULL AND ass.created_by = ?"
+	args := []any{opts.UserId}
+	if opts.OrgId != nil {
+		filter = "ass.org_id = ?"
+		args = []any{*opts.OrgId}
+	}
+	schedules := AutomationSchedules{}
+	if err := executeMysqlSelects(mysqlQueryInput{
+		Db: opts.Db,
+		Stmt: fmt.Sprintf(`
+			SELECT %s
+				FROM automation_schedules ass
+				%s
+				WHERE %s
+				ORDER BY ass.name ASC
+		`, automationScheduleSelectFields, automationScheduleSelectJoins, filter),
+		Args:     args,
+		FnSource: "models.ListAutomationSchedulesV1",
+		ProcessRows: func(r *sql.Rows) error {
+			schedule, err := scanAutomationSchedule(r)
+			if err != nil {
+				return err
+			}
+			schedules = append(schedules, *schedule)
+			return nil
+		},
+	}); err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+type ListDueAutomationSchedulesV1Input struct {
+	DatabaseConnection
+
+	// Now is the time which schedules are due at
+	Now time.Time
+
+	// Limit is the maximum number of schedules to return
+	Limit int
+}
+
+// ListDueAutomationSchedulesV1 returns schedules which are not paused and
+// whose next run is at or before `.Now`, sorted by their next run
+func ListDueAutomationSchedulesV1(opts ListDueAutomationSchedulesV1Input) (AutomationSchedules, error) {
+	schedules := AutomationSchedules{}
+	if err := executeMysqlSelects(mysqlQueryInput{
+		Db: opts.Db,
+		Stmt: fmt.Sprintf(`
+			SELECT %s
+				FROM automation_schedules ass
+				%s
+				WHERE ass.is_paused = FALSE AND ass.next_run_at <= ?
+				ORDER BY ass.next_run_at ASC
+				LIMIT ?
+		`, automationScheduleSelectFields, automationScheduleSelectJoins),
+		Args:     []any{opts.Now, opts.Limit},
+		FnSource: "models.ListDueAutomationSchedulesV1",
+		ProcessRows: func(r *sql.Rows) error {
+			schedule, err := scanAutomationSchedule(r)
+			if err != nil {
+				return err
+			}
+			schedules = append(schedules, *schedule)
+			return nil
+		},
+	}); err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
@@ -0,0 +1,139 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"opsicle/internal/automations"
+	"time"
+)
+
+type SetAutomationSchedulePausedV1Input struct {
+	DatabaseConnection
+
+	IsPaused bool
+	UserId   string
+}
+
+// SetPausedV1 pauses or resumes the schedule; when the schedule is
+// resumed its next run is computed from the current time so that runs
+// missed while it was paused are not triggered
+func (as *AutomationSchedule) SetPausedV1(opts SetAutomationSchedulePausedV1Input) error {
+	if err := as.assertId(); err != nil {
+		return err
+	}
+	if as.IsPaused == opts.IsPaused {
+		return nil
+	}
+	var nextRunAt *time.Time
+	if !opts.IsPaused {
+		schedule, err := automations.ParseSchedule(as.CronExpression, as.Timezone)
+		if err != nil {
+			return fmt.Errorf("models.AutomationSchedule.SetPausedV1: %w", err)
+		}
+		next := schedule.GetNext(time.Now())
+		nextRunAt = &next
+	}
+	if err := executeMysqlUpdate(mysqlQueryInput{
+		Db: opts.Db,
+		Stmt: `
+			UPDATE automation_schedules
+				SET
+					is_paused = ?,
+					next_run_at = COALESCE(?, next_run_at),
+					last_updated_by = ?
+				WHERE id = ?
+		`,
+		Args:         []any{opts.IsPaused, nextRunAt, opts.UserId, as.GetId()},
+		FnSource:     "models.AutomationSchedule.SetPausedV1",
+		RowsAffected: oneRowAffected,
+	}); err != nil {
+		if errors.Is(err, ErrorRowsAffectedCheckFailed) {
+			return fmt.Errorf("schedule[%s] does not exist: %w", as.GetId(), ErrorNotFound)
+		}
+		return err
+	}
+	as.IsPaused = opts.IsPaused
+	if nextRunAt != nil {
+		as.NextRunAt = nextRunAt
+	}
+	return nil
+}
+
+type ClaimAutomationScheduleRunV1Input struct {
+	DatabaseConnection
+
+	// RunAt is the time the schedule is being run at
+	RunAt time.Time
+}
+
+// ClaimRunV1 records that the schedule is being run and advances its
+// next run; this only succeeds if the next run of the schedule was not
+// changed since it was loaded so that a run is claimed at most once even
+// when more than one scheduler sees it as due. Runs missed while the
+// scheduler was not running are not triggered individually, the next
+// run is computed from `.RunAt`
+func (as *AutomationSchedule) ClaimRunV1(opts ClaimAutomationScheduleRunV1Input) (bool, error) {
+	if err := as.assertId(); err != nil {
+		return false, err
+	}
+	if as.NextRunAt == nil {
+		return false, fmt.Errorf("%w: schedule[%s] has no next run", ErrorInvalidInput, as.GetId())
+	}
+	schedule, err := automations.ParseSchedule(as.CronExpression, as.Timezone)
+	if err != nil {
+		return false, fmt.Errorf("models.AutomationSchedule.ClaimRunV1: %w", err)
+	}
+	nextRunAt := schedule.GetNext(opts.RunAt)
+	if err := executeMysqlUpdate(mysqlQueryInput{
+		Db: opts.Db,
+		Stmt: `
+			UPDATE automation_schedules
+				SET
+					last_run_at = ?,
+					next_run_at = ?
+				WHERE
+					id = ?
+					AND is_paused = FALSE
+					AND next_run_at = ?
+		`,
+		Args:         []any{opts.RunAt, nextRunAt, as.GetId(), *as.NextRunAt},
+		FnSource:     "models.AutomationSchedule.ClaimRunV1",
+		RowsAffected: oneRowAffected,
+	}); err != nil {
+		if errors.Is(err, ErrorRowsAffectedCheckFailed) {
+			return false, nil
+		}
+		return false, err
+	}
+	as.LastRunAt = &opts.RunAt
+	as.NextRunAt = &nextRunAt
+	return true, nil
+}
+
+type SetAutomationScheduleLastAutomationV1Input struct {
+	DatabaseConnection
+
+	AutomationId string
+}
+
+// SetLastAutomationV1 records the automation which was last triggered by
+// the schedule
+func (as *AutomationSchedule) SetLastAutomationV1(opts SetAutomationScheduleLastAutomationV1Input) error {
+	if err := as.assertId(); err != nil {
+		return err
+	}
+	if err := executeMysqlUpdate(mysqlQueryInput{
+		Db:           opts.Db,
+		Stmt:         `UPDATE automation_schedules SET last_automation_id = ? WHERE id = ?`,
+		Args:         []any{opts.AutomationId, as.GetId()},
+		FnSource:     "models.AutomationSchedule.SetLastAutomationV1",
+		RowsAffected: oneRowAffected,
+	}); err != nil {
+		if errors.Is(err, ErrorRowsAffectedCheckFailed) {
+			return fmt.Errorf("schedule[%s] does not exist: %w", as.GetId(), ErrorNotFound)
+		}
+		return err
+	}
+	as.LastAutomationId = &opts.AutomationId
+	return nil
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"opsicle/internal/automations"
+)
+
+type UpdateAutomationStatusV1Opts struct {
+	Db     DatabaseConnection
+	Update automations.RunStatusUpdate
+}
+
+// UpdateStatusV1 applies the provided status update to the automation
+// and persists both the aggregated run status and the last known status
+// of the automation; the automation is loaded from the database before
+// the update is applied
+func (a *Automation) UpdateStatusV1(opts UpdateAutomationStatusV1Opts) error {
+	if err := a.assertId(); err != nil {
+		return err
+	}
+	if err := a.LoadV1(opts.Db); err != nil {
+		return fmt.Errorf("models.Automation.UpdateStatusV1: failed to load automation: %w", err)
+	}
+	a.RunStatus.Apply(opts.Update)
+	a.LastKnownStatus = string(a.RunStatus.Status)
+	a.StartedAt = a.RunStatus.StartedAt
+	a.CompletedAt = a.RunStatus.CompletedAt
+	runStatus, err := json.Marshal(a.RunStatus)
+	if err != nil {
+		return fmt.Errorf("models.Automation.UpdateStatusV1: failed to marshal run status: %w", err)
+	}
+	if err := executeMysqlUpdate(mysqlQueryInput{
+		Db: opts.Db.Db,
+		Stmt: `
+			UPDATE automations
+				SET
+					last_known_status = ?,
+					run_status = ?,
+					started_at = ?,
+					completed_at = ?,
+					last_updated_at = NOW()
+				WHERE id = ?
+		`,
+		Args: []any{
+			a.LastKnownStatus,
+			string(runStatus),
+			a.StartedAt,
+			a.CompletedAt,
+			*a.Id,
+		},
+		FnSource: fmt.Sprintf("models.Automation.UpdateStatusV1[%s]", *a.Id),
+	}); err != nil {
+		return err
+	}
+	return nil
+}
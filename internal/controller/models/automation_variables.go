@@ -0,0 +1,70 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"opsicle/internal/auth"
+	"opsicle/internal/automations"
+)
+
+// encryptSecretVariables returns a copy of `values` where the values of
+// secret variables are encrypted so that they can be stored at rest
+func encryptSecretVariables(variables automations.VariablesSpec, values map[string]any) (map[string]any, error) {
+	key, err := getEncryptionKey(encryptionPurposeInputVars)
+	if err != nil {
+		return nil, err
+	}
+	output := map[string]any{}
+	for id, value := range values {
+		output[id] = value
+	}
+	for _, variable := range variables {
+		secret, ok := output[variable.Id].(string)
+		if !variable.IsSecret() || !ok {
+			continue
+		}
+		encryptedSecret, err := auth.Encrypt(key, []byte(secret))
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt var[%s]: %w", variable.Id, err)
+		}
+		output[variable.Id] = encryptedSecret
+	}
+	return output, nil
+}
+
+// GetInputVars returns the variables the automation was run with with
+// the values of secret variables decrypted
+func (a *Automation) GetInputVars() (map[string]any, error) {
+	values := map[string]any{}
+	if len(a.InputVars) == 0 {
+		return values, nil
+	}
+	if err := json.Unmarshal(a.InputVars, &values); err != nil {
+		return nil, fmt.Errorf("models.Automation.GetInputVars: failed to unmarshal input vars: %w", err)
+	}
+	if err := decryptSecretVariables(values); err != nil {
+		return nil, fmt.Errorf("models.Automation.GetInputVars: %w", err)
+	}
+	return values, nil
+}
+
+// decryptSecretVariables decrypts in place the values in `values` which
+// were encrypted by encryptSecretVariables
+func decryptSecretVariables(values map[string]any) error {
+	key, err := getEncryptionKey(encryptionPurposeInputVars)
+	if err != nil {
+		return err
+	}
+	for id, value := range values {
+		encryptedSecret, ok := value.(string)
+		if !ok || !auth.IsEncrypted(encryptedSecret) {
+			continue
+		}
+		secret, err := auth.Decrypt(key, encryptedSecret)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt var[%s]: %w", id, err)
+		}
+		values[id] = string(secret)
+	}
+	return nil
+}
@@ -1,10 +1,35 @@
 package models
 
+import (
+	"fmt"
+	"opsicle/internal/auth"
+)
+
+const (
+	encryptionPurposeInputVars      = "automation-input-vars"
+	encryptionPurposeTriggerSecrets = "template-trigger-secrets"
+)
+
 var (
 	cachePrefixAutomationPending = "automation"
+	secretsMasterKey             []byte
 	sessionSigningToken          = "supersecretkey"
 )
 
 func SetSessionSigningToken(token string) {
 	sessionSigningToken = token
 }
+
+// SetSecretsMasterKey sets the key which encryption keys for secrets
+// stored in the database are derived from, changing this makes all
+// previously stored secrets unreadable
+func SetSecretsMasterKey(key string) {
+	secretsMasterKey = []byte(key)
+}
+
+func getEncryptionKey(purpose string) ([]byte, error) {
+	if len(secretsMasterKey) == 0 {
+		return nil, fmt.Errorf("failed to derive encryption key for %s: %w", purpose, ErrorSecretsMasterKeyNotSet)
+	}
+	return auth.DeriveEncryptionKey(secretsMasterKey, purpose)
+}
@@ -5,6 +5,7 @@ import (
 )
 
 var (
+	ErrorAutomationNotPendingApproval    = errors.New("automation_not_pending_approval")
 	ErrorCredentialsAuthenticationFailed = errors.New("credentials_authentication_failed")
 	ErrorDatabaseUndefined               = errors.New("database_undefined")
 	ErrorDeleteFailed                    = errors.New("delete_failed")
@@ -15,6 +16,7 @@ var (
 	ErrorNotFound                        = errors.New("not_found")
 	ErrorQueryFailed                     = errors.New("query_failed")
 	ErrorRowsAffectedCheckFailed         = errors.New("rows_affected_check_failed")
+	ErrorSecretsMasterKeyNotSet          = errors.New("secrets_master_key_not_set")
 	ErrorSelectFailed                    = errors.New("select_failed")
 	ErrorSelectsFailed                   = errors.New("selects_failed")
 	ErrorStmtPreparationFailed           = errors.New("stmt_preparation_failed")
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"opsicle/internal/approvals"
+	"regexp"
+	"time"
+)
+
+var approvalPolicyNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]{0,254}$`)
+
+type ApprovalPolicies []ApprovalPolicy
+
+// ApprovalPolicy is a named approval policy stored in an org which
+// templates of the org can reference using `approvalPolicy.policyRef`
+type ApprovalPolicy struct {
+	Id            *string              `json:"id" yaml:"id"`
+	OrgId         string               `json:"orgId" yaml:"orgId"`
+	Name          string               `json:"name" yaml:"name"`
+	Spec          approvals.PolicySpec `json:"spec" yaml:"spec"`
+	CreatedAt     time.Time            `json:"createdAt" yaml:"createdAt"`
+	CreatedBy     *User                `json:"createdBy" yaml:"createdBy"`
+	LastUpdatedAt time.Time            `json:"lastUpdatedAt" yaml:"lastUpdatedAt"`
+	LastUpdatedBy *User                `json:"lastUpdatedBy" yaml:"lastUpdatedBy"`
+}
+
+func (ap ApprovalPolicy) GetId() string {
+	if ap.Id == nil {
+		return ""
+	}
+	return *ap.Id
+}
+
+// ValidateApprovalPolicyName returns an error if the provided name
+// cannot be used as the name of an approval policy
+func ValidateApprovalPolicyName(name string) error {
+	if !approvalPolicyNameRegex.MatchString(name) {
+		return fmt.Errorf("approval policy name must start with an alphanumeric character and contain only alphanumerics, '_', '.' or '-': %w", ErrorInvalidInput)
+	}
+	return nil
+}
+
+const approvalPolicySelectFields = `
+	ap.id,
+	ap.org_id,
+	ap.name,
+	ap.spec,
+	ap.created_at,
+	ap.created_by,
+	created_by_user.email,
+	ap.last_updated_at,
+	ap.last_updated_by,
+	last_updated_by_user.email
+`
+
+const approvalPolicySelectJoins = `
+	LEFT JOIN users created_by_user ON created_by_user.id = ap.created_by
+	LEFT JOIN users last_updated_by_user ON last_updated_by_user.id = ap.last_updated_by
+`
+
+type approvalPolicyScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanApprovalPolicy(row approvalPolicyScanner) (*ApprovalPolicy, error) {
+	var (
+		policy             ApprovalPolicy
+		id                 string
+		spec               string
+		createdById        sql.NullString
+		createdByEmail     sql.NullString
+		lastUpdatedById    sql.NullString
+		lastUpdatedByEmail sql.NullString
+	)
+	if err := row.Scan(
+		&id,
+		&policy.OrgId,
+		&policy.Name,
+		&spec,
+		&policy.CreatedAt,
+		&createdById,
+		&createdByEmail,
+		&policy.LastUpdatedAt,
+		&lastUpdatedById,
+		&lastUpdatedByEmail,
+	); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(spec), &policy.Spec); err != nil {
+		return nil, fmt.Errorf("failed to parse spec of approval policy[%s]: %w", id, err)
+	}
+	policy.Id = &id
+	if createdById.Valid {
+		policy.CreatedBy = &User{Id: &createdById.String, Email: createdByEmail.String}
+	}
+	if lastUpdatedById.Valid {
+		policy.LastUpdatedBy = &User{Id: &lastUpdatedById.String, Email: lastUpdatedByEmail.String}
+	}
+	return &policy, nil
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"opsicle/internal/approvals"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type SetOrgApprovalPolicyV1Input struct {
+	DatabaseConnection
+
+	Name   string
+	Spec   approvals.PolicySpec
+	UserId string
+}
+
+// SetApprovalPolicyV1 creates the approval policy identified by `.Name`
+// in the org or replaces its spec if it already exists
+func (o *Org) SetApprovalPolicyV1(opts SetOrgApprovalPolicyV1Input) (*ApprovalPolicy, error) {
+	if err := o.assertIdDefined(); err != nil {
+		return nil, err
+	}
+	if err := ValidateApprovalPolicyName(opts.Name); err != nil {
+		return nil, err
+	}
+	if err := opts.Spec.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid approval policy spec: %w: %w", ErrorInvalidInput, err)
+	}
+	spec, err := json.Marshal(opts.Spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal approval policy spec: %w", err)
+	}
+	insertMap := map[string]any{
+		"id":              uuid.NewString(),
+		"org_id":          o.GetId(),
+		"name":            opts.Name,
+		"spec":            string(spec),
+		"created_by":      opts.UserId,
+		"last_updated_by": opts.UserId,
+	}
+	fieldNames, fieldValues, fieldPlaceholders, err := parseInsertMap(insertMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse insert map: %w", err)
+	}
+	if err := executeMysqlInsert(mysqlQueryInput{
+		Db: opts.Db,
+		Stmt: fmt.Sprintf(
+			`INSERT INTO approval_policies (%s) VALUES (%s)
+				ON DUPLICATE KEY UPDATE
+					spec = VALUES(spec),
+					last_updated_by = VALUES(last_updated_by)`,
+			strings.Join(fieldNames, ", "),
+			strings.Join(fieldPlaceholders, ", "),
+		),
+		Args:         fieldValues,
+		FnSource:     "models.Org.SetApprovalPolicyV1",
+		RowsAffected: atLeastNRowsAffected(1),
+	}); err != nil {
+		return nil, err
+	}
+	return o.GetApprovalPolicyV1(GetOrgApprovalPolicyV1Input{
+		DatabaseConnection: opts.DatabaseConnection,
+		Name:               opts.Name,
+	})
+}
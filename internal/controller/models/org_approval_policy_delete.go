@@ -0,0 +1,34 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+type DeleteOrgApprovalPolicyV1Input struct {
+	DatabaseConnection
+
+	Name string
+}
+
+// DeleteApprovalPolicyV1 deletes the approval policy identified by
+// `.Name` in the org; templates which already resolved the policy keep
+// their copy of it
+func (o *Org) DeleteApprovalPolicyV1(opts DeleteOrgApprovalPolicyV1Input) error {
+	if err := o.assertIdDefined(); err != nil {
+		return err
+	}
+	if err := executeMysqlDelete(mysqlQueryInput{
+		Db:           opts.Db,
+		Stmt:         `DELETE FROM approval_policies WHERE org_id = ? AND name = ?`,
+		Args:         []any{o.GetId(), opts.Name},
+		FnSource:     "models.Org.DeleteApprovalPolicyV1",
+		RowsAffected: oneRowAffected,
+	}); err != nil {
+		if errors.Is(err, ErrorRowsAffectedCheckFailed) {
+			return fmt.Errorf("approval policy[%s] does not exist: %w", opts.Name, ErrorNotFound)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+type GetOrgApprovalPolicyV1Input struct {
+	DatabaseConnection
+
+	Name string
+}
+
+// GetApprovalPolicyV1 returns the approval policy identified by `.Name`
+// in the org
+func (o *Org) GetApprovalPolicyV1(opts GetOrgApprovalPolicyV1Input) (*ApprovalPolicy, error) {
+	if err := o.assertIdDefined(); err != nil {
+		return nil, err
+	}
+	var policy *ApprovalPolicy
+	if err := executeMysqlSelect(mysqlQueryInput{
+		Db: opts.Db,
+		Stmt: fmt.Sprintf(`
+			SELECT %s
+				FROM approval_policies ap
+				%s
+				WHERE ap.org_id = ? AND ap.name = ?
+		`, approvalPolicySelectFields, approvalPolicySelectJoins),
+		Args:     []any{o.GetId(), opts.Name},
+		FnSource: "models.Org.GetApprovalPolicyV1",
+		ProcessRow: func(r *sql.Row) error {
+			var err error
+			policy, err = scanApprovalPolicy(r)
+			return err
+		},
+	}); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
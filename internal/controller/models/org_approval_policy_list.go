@@ -0,0 +1,42 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+type ListOrgApprovalPoliciesV1Input struct {
+	DatabaseConnection
+}
+
+// ListApprovalPoliciesV1 returns the approval policies of the org sorted
+// by name
+func (o *Org) ListApprovalPoliciesV1(opts ListOrgApprovalPoliciesV1Input) (ApprovalPolicies, error) {
+	if err := o.assertIdDefined(); err != nil {
+		return nil, err
+	}
+	policies := ApprovalPolicies{}
+	if err := executeMysqlSelects(mysqlQueryInput{
+		Db: opts.Db,
+		Stmt: fmt.Sprintf(`
+			SELECT %s
+				FROM approval_policies ap
+				%s
+				WHERE ap.org_id = ?
+				ORDER BY ap.name ASC
+		`, approvalPolicySelectFields, approvalPolicySelectJoins),
+		Args:     []any{o.GetId()},
+		FnSource: "models.Org.ListApprovalPoliciesV1",
+		ProcessRows: func(r *sql.Rows) error {
+			policy, err := scanApprovalPolicy(r)
+			if err != nil {
+				return err
+			}
+			policies = append(policies, *policy)
+			return nil
+		},
+	}); err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
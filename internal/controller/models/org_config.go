@@ -0,0 +1,67 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// OrgConfig holds the settings of an org, orgs without a row in
+// `org_config` use the defaults of the table
+type OrgConfig struct {
+	OrgId string `json:"orgId"`
+
+	// IsApprovalsEnabled indicates whether automations run in the org
+	// require approval when their template defines an approval policy
+	IsApprovalsEnabled bool `json:"isApprovalsEnabled"`
+}
+
+// GetConfigV1 returns the configuration of the org, the default
+// configuration is returned if the org has not been configured
+func (o *Org) GetConfigV1(opts DatabaseConnection) (*OrgConfig, error) {
+	if err := o.assertIdDefined(); err != nil {
+		return nil, err
+	}
+	config := OrgConfig{OrgId: o.GetId()}
+	if err := executeMysqlSelect(mysqlQueryInput{
+		Db:       opts.Db,
+		Stmt:     `SELECT is_approvals_enabled FROM org_config WHERE org_id = ?`,
+		Args:     []any{o.GetId()},
+		FnSource: "models.Org.GetConfigV1",
+		ProcessRow: func(r *sql.Row) error {
+			return r.Scan(&config.IsApprovalsEnabled)
+		},
+	}); err != nil && !errors.Is(err, ErrorNotFound) {
+		return nil, err
+	}
+	return &config, nil
+}
+
+type SetOrgApprovalsEnabledV1Input struct {
+	DatabaseConnection
+
+	IsApprovalsEnabled bool
+}
+
+// SetApprovalsEnabledV1 sets whether automations run in the org
+// require approval when their template defines an approval policy
+func (o *Org) SetApprovalsEnabledV1(opts SetOrgApprovalsEnabledV1Input) (*OrgConfig, error) {
+	if err := o.assertIdDefined(); err != nil {
+		return nil, err
+	}
+	if err := executeMysqlInsert(mysqlQueryInput{
+		Db: opts.Db,
+		Stmt: `
+			INSERT INTO org_config (id, org_id, is_approvals_enabled) VALUES (?, ?, ?)
+				ON DUPLICATE KEY UPDATE
+					is_approvals_enabled = VALUES(is_approvals_enabled)
+		`,
+		Args:     []any{uuid.NewString(), o.GetId(), opts.IsApprovalsEnabled},
+		FnSource: "models.Org.SetApprovalsEnabledV1",
+	}); err != nil {
+		return nil, fmt.Errorf("models.Org.SetApprovalsEnabledV1: %w", err)
+	}
+	return o.GetConfigV1(opts.DatabaseConnection)
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"crypto/rand"
+	"fmt"
+	"opsicle/internal/auth"
+	"regexp"
+	"time"
+)
+
+var orgSecretNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]{0,254}$`)
+
+type OrgSecrets []OrgSecret
+
+type OrgSecret struct {
+	Id            *string   `json:"id" yaml:"id"`
+	OrgId         string    `json:"orgId" yaml:"orgId"`
+	Name          string    `json:"name" yaml:"name"`
+	Description   *string   `json:"description" yaml:"description"`
+	CreatedAt     time.Time `json:"createdAt" yaml:"createdAt"`
+	CreatedBy     *User     `json:"createdBy" yaml:"createdBy"`
+	LastUpdatedAt time.Time `json:"lastUpdatedAt" yaml:"lastUpdatedAt"`
+	LastUpdatedBy *User     `json:"lastUpdatedBy" yaml:"lastUpdatedBy"`
+
+	encryptedDataKey string
+	encryptedValue   string
+}
+
+func (os OrgSecret) GetId() string {
+	if os.Id == nil {
+		return ""
+	}
+	return *os.Id
+}
+
+// ValidateOrgSecretName returns an error if the provided name cannot be
+// used as the name of a secret
+func ValidateOrgSecretName(name string) error {
+	if !orgSecretNameRegex.MatchString(name) {
+		return fmt.Errorf("secret name must start with an alphanumeric character and contain only alphanumerics, '_', '.' or '-': %w", ErrorInvalidInput)
+	}
+	return nil
+}
+
+// getOrgSecretsKey returns the key-encryption-key of the org's secrets
+// which is derived from the secrets master key and scoped to the org
+func getOrgSecretsKey(orgId string) ([]byte, error) {
+	return getEncryptionKey(fmt.Sprintf("org-secrets:%s", orgId))
+}
+
+// sealOrgSecret envelope-encrypts the provided `value`: the value is
+// encrypted with a random data key which is in turn encrypted with the
+// org's key-encryption-key
+func sealOrgSecret(orgId string, value []byte) (encryptedDataKey string, encryptedValue string, err error) {
+	orgKey, err := getOrgSecretsKey(orgId)
+	if err != nil {
+		return "", "", err
+	}
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", "", fmt.Errorf("failed to generate data key: %w", err)
+	}
+	encryptedValue, err = auth.Encrypt(dataKey, value)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encrypt value: %w", err)
+	}
+	encryptedDataKey, err = auth.Encrypt(orgKey, dataKey)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encrypt data key: %w", err)
+	}
+	return encryptedDataKey, encryptedValue, nil
+}
+
+// openOrgSecret reverses sealOrgSecret
+func openOrgSecret(orgId, encryptedDataKey, encryptedValue string) ([]byte, error) {
+	orgKey, err := getOrgSecretsKey(orgId)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := auth.Decrypt(orgKey, encryptedDataKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data key: %w", err)
+	}
+	value, err := auth.Decrypt(dataKey, encryptedValue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt value: %w", err)
+	}
+	return value, nil
+}
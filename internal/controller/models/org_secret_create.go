@@ -0,0 +1,72 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type SetOrgSecretV1Input struct {
+	DatabaseConnection
+
+	Name        string
+	Description *string
+	Value       string
+	UserId      string
+}
+
+// SetSecretV1 creates the secret identified by `.Name` in the org or
+// replaces its value if it already exists
+func (o *Org) SetSecretV1(opts SetOrgSecretV1Input) (*OrgSecret, error) {
+	if err := o.assertIdDefined(); err != nil {
+		return nil, err
+	}
+	if err := ValidateOrgSecretName(opts.Name); err != nil {
+		return nil, err
+	}
+	if opts.Value == "" {
+		return nil, fmt.Errorf("secret value must not be empty: %w", ErrorInvalidInput)
+	}
+	encryptedDataKey, encryptedValue, err := sealOrgSecret(o.GetId(), []byte(opts.Value))
+	if err != nil {
+		return nil, fmt.Errorf("models.Org.SetSecretV1: %w", err)
+	}
+	secretId := uuid.NewString()
+	insertMap := map[string]any{
+		"id":                 secretId,
+		"org_id":             o.GetId(),
+		"name":               opts.Name,
+		"description":        opts.Description,
+		"encrypted_data_key": encryptedDataKey,
+		"encrypted_value":    encryptedValue,
+		"created_by":         opts.UserId,
+		"last_updated_by":    opts.UserId,
+	}
+	fieldNames, fieldValues, fieldPlaceholders, err := parseInsertMap(insertMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse insert map: %w", err)
+	}
+	if err := executeMysqlInsert(mysqlQueryInput{
+		Db: opts.Db,
+		Stmt: fmt.Sprintf(
+			`INSERT INTO org_secrets (%s) VALUES (%s)
+				ON DUPLICATE KEY UPDATE
+					description = VALUES(description),
+					encrypted_data_key = VALUES(encrypted_data_key),
+					encrypted_value = VALUES(encrypted_value),
+					last_updated_by = VALUES(last_updated_by)`,
+			strings.Join(fieldNames, ", "),
+			strings.Join(fieldPlaceholders, ", "),
+		),
+		Args:         fieldValues,
+		FnSource:     "models.Org.SetSecretV1",
+		RowsAffected: atLeastNRowsAffected(1),
+	}); err != nil {
+		return nil, err
+	}
+	return o.GetSecretV1(GetOrgSecretV1Input{
+		DatabaseConnection: opts.DatabaseConnection,
+		Name:               opts.Name,
+	})
+}
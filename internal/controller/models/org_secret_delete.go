@@ -0,0 +1,32 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+type DeleteOrgSecretV1Input struct {
+	DatabaseConnection
+
+	Name string
+}
+
+// DeleteSecretV1 deletes the secret identified by `.Name` in the org
+func (o *Org) DeleteSecretV1(opts DeleteOrgSecretV1Input) error {
+	if err := o.assertIdDefined(); err != nil {
+		return err
+	}
+	if err := executeMysqlDelete(mysqlQueryInput{
+		Db:           opts.Db,
+		Stmt:         `DELETE FROM org_secrets WHERE org_id = ? AND name = ?`,
+		Args:         []any{o.GetId(), opts.Name},
+		FnSource:     "models.Org.DeleteSecretV1",
+		RowsAffected: oneRowAffected,
+	}); err != nil {
+		if errors.Is(err, ErrorRowsAffectedCheckFailed) {
+			return fmt.Errorf("secret[%s] does not exist: %w", opts.Name, ErrorNotFound)
+		}
+		return err
+	}
+	return nil
+}
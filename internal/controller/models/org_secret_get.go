@@ -0,0 +1,111 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+const orgSecretSelectFields = `
+	os.id,
+	os.org_id,
+	os.name,
+	os.description,
+	os.encrypted_data_key,
+	os.encrypted_value,
+	os.created_at,
+	os.created_by,
+	created_by_user.email,
+	os.last_updated_at,
+	os.last_updated_by,
+	last_updated_by_user.email
+`
+
+const orgSecretSelectJoins = `
+	LEFT JOIN users created_by_user ON created_by_user.id = os.created_by
+	LEFT JOIN users last_updated_by_user ON last_updated_by_user.id = os.last_updated_by
+`
+
+type orgSecretScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanOrgSecret(row orgSecretScanner) (*OrgSecret, error) {
+	var (
+		secret             OrgSecret
+		id                 string
+		createdById        sql.NullString
+		createdByEmail     sql.NullString
+		lastUpdatedById    sql.NullString
+		lastUpdatedByEmail sql.NullString
+	)
+	if err := row.Scan(
+		&id,
+		&secret.OrgId,
+		&secret.Name,
+		&secret.Description,
+		&secret.encryptedDataKey,
+		&secret.encryptedValue,
+		&secret.CreatedAt,
+		&createdById,
+		&createdByEmail,
+		&secret.LastUpdatedAt,
+		&lastUpdatedById,
+		&lastUpdatedByEmail,
+	); err != nil {
+		return nil, err
+	}
+	secret.Id = &id
+	if createdById.Valid {
+		secret.CreatedBy = &User{Id: &createdById.String, Email: createdByEmail.String}
+	}
+	if lastUpdatedById.Valid {
+		secret.LastUpdatedBy = &User{Id: &lastUpdatedById.String, Email: lastUpdatedByEmail.String}
+	}
+	return &secret, nil
+}
+
+type GetOrgSecretV1Input struct {
+	DatabaseConnection
+
+	Name string
+}
+
+// GetSecretV1 returns the metadata of the secret identified by `.Name`
+// in the org; the value can be retrieved using `.GetValue()`
+func (o *Org) GetSecretV1(opts GetOrgSecretV1Input) (*OrgSecret, error) {
+	if err := o.assertIdDefined(); err != nil {
+		return nil, err
+	}
+	var secret *OrgSecret
+	if err := executeMysqlSelect(mysqlQueryInput{
+		Db: opts.Db,
+		Stmt: fmt.Sprintf(`
+			SELECT %s
+				FROM org_secrets os
+				%s
+				WHERE os.org_id = ? AND os.name = ?
+		`, orgSecretSelectFields, orgSecretSelectJoins),
+		Args:     []any{o.GetId(), opts.Name},
+		FnSource: "models.Org.GetSecretV1",
+		ProcessRow: func(r *sql.Row) error {
+			var err error
+			secret, err = scanOrgSecret(r)
+			return err
+		},
+	}); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// GetValue returns the decrypted value of the secret
+func (os OrgSecret) GetValue() (string, error) {
+	if os.encryptedValue == "" {
+		return "", fmt.Errorf("secret[%s] has not been loaded: %w", os.Name, ErrorInvalidInput)
+	}
+	value, err := openOrgSecret(os.OrgId, os.encryptedDataKey, os.encryptedValue)
+	if err != nil {
+		return "", fmt.Errorf("models.OrgSecret.GetValue[%s]: %w", os.Name, err)
+	}
+	return string(value), nil
+}
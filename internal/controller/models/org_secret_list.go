@@ -0,0 +1,56 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+type ListOrgSecretsV1Input struct {
+	DatabaseConnection
+
+	// Names when defined limits the returned secrets to those with the
+	// provided names
+	Names []string
+}
+
+// ListSecretsV1 returns the secrets of the org sorted by name
+func (o *Org) ListSecretsV1(opts ListOrgSecretsV1Input) (OrgSecrets, error) {
+	if err := o.assertIdDefined(); err != nil {
+		return nil, err
+	}
+	args := []any{o.GetId()}
+	nameFilter := ""
+	if len(opts.Names) > 0 {
+		placeholders := []string{}
+		for _, name := range opts.Names {
+			placeholders = append(placeholders, "?")
+			args = append(args, name)
+		}
+		nameFilter = fmt.Sprintf("AND os.name IN (%s)", strings.Join(placeholders, ", "))
+	}
+	secrets := OrgSecrets{}
+	if err := executeMysqlSelects(mysqlQueryInput{
+		Db: opts.Db,
+		Stmt: fmt.Sprintf(`
+			SELECT %s
+				FROM org_secrets os
+				%s
+				WHERE os.org_id = ? %s
+				ORDER BY os.name ASC
+		`, orgSecretSelectFields, orgSecretSelectJoins, nameFilter),
+		Args:     args,
+		FnSource: "models.Org.ListSecretsV1",
+		ProcessRows: func(r *sql.Rows) error {
+			secret, err := scanOrgSecret(r)
+			if err != nil {
+				return err
+			}
+			secrets = append(secrets, *secret)
+			return nil
+		},
+	}); err != nil {
+		return nil, err
+	}
+	return secrets, nil
+}
@@ -2,8 +2,10 @@ package models
 
 import (
 	"database/sql"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"opsicle/internal/auth"
+	"opsicle/internal/validate"
 )
 
 type ValidateOrgTokenV1Opts struct {
@@ -13,7 +15,25 @@ type ValidateOrgTokenV1Opts struct {
 	Token   string
 }
 
+// ValidateOrgTokenV1 returns the org token identified by `TokenId` if
+// `Token` matches its API key, ErrorCredentialsAuthenticationFailed is
+// returned if the token does not exist or the API key does not match
 func ValidateOrgTokenV1(opts ValidateOrgTokenV1Opts) (*OrgToken, error) {
+	if opts.Db == nil {
+		return nil, fmt.Errorf("missing db connection: %w", errorInputValidationFailed)
+	}
+	if err := validate.Uuid(opts.TokenId); err != nil {
+		return nil, fmt.Errorf("token id invalid: %w", ErrorCredentialsAuthenticationFailed)
+	}
+	if opts.Token == "" {
+		return nil, fmt.Errorf("token undefined: %w", ErrorCredentialsAuthenticationFailed)
+	}
+	var (
+		token        OrgToken
+		tokenId      string
+		orgId        string
+		hashedApiKey string
+	)
 	if err := executeMysqlSelect(mysqlQueryInput{
 		Db: opts.Db,
 		Stmt: `
@@ -21,28 +41,31 @@ func ValidateOrgTokenV1(opts ValidateOrgTokenV1Opts) (*OrgToken, error) {
 				ot.id,
 				ot.org_id,
 				ot.name,
-				ot.api_key,
-				orp.id,
-				orp.resource,
-				orp.allows,
-				orp.denys
+				ot.api_key
 			FROM org_tokens ot
-				LEFT JOIN org_token_roles otr ON otr.org_token_id = ot.id
-				LEFT JOIN org_role_permissions orp ON orp.org_role_id = orls.id
 			WHERE
 				ot.id = ?
 		`,
 		Args:     []any{opts.TokenId},
 		FnSource: "models.ValidateOrgTokenV1",
 		ProcessRow: func(r *sql.Row) error {
-			var x map[string]interface{}
-			r.Scan(&x)
-			v, _ := json.MarshalIndent(x, "", "  ")
-			fmt.Println(string(v))
-			return nil
+			return r.Scan(
+				&tokenId,
+				&orgId,
+				&token.Name,
+				&hashedApiKey,
+			)
 		},
 	}); err != nil {
+		if errors.Is(err, ErrorNotFound) {
+			return nil, fmt.Errorf("token not found: %w", ErrorCredentialsAuthenticationFailed)
+		}
 		return nil, err
 	}
-	return &OrgToken{}, nil
+	if !auth.ValidatePassword(opts.Token, hashedApiKey) {
+		return nil, fmt.Errorf("api key mismatch: %w", ErrorCredentialsAuthenticationFailed)
+	}
+	token.Id = &tokenId
+	token.Org = &Org{Id: &orgId}
+	return &token, nil
 }
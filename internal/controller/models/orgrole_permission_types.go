@@ -16,11 +16,15 @@ var (
 	ActionSetOperator Action = ActionSetUser | ActionCreate | ActionUpdate | ActionDelete
 	ActionSetAdmin    Action = ActionSetOperator | ActionManage
 
-	ResourceAutomationLogs Resource = "automation_logs"
-	ResourceAutomations    Resource = "automations"
-	ResourceTemplates      Resource = "templates"
-	ResourceOrg            Resource = "org"
-	ResourceOrgBilling     Resource = "org_billing"
-	ResourceOrgConfig      Resource = "org_config"
-	ResourceOrgUser        Resource = "org_user"
+	ResourceApprovalPolicies Resource = "approval_policies"
+	ResourceAutomationLogs   Resource = "automation_logs"
+	ResourceAutomations      Resource = "automations"
+	ResourceTemplates        Resource = "templates"
+	ResourceOrg              Resource = "org"
+	ResourceOrgBilling       Resource = "org_billing"
+	ResourceOrgConfig        Resource = "org_config"
+	ResourceOrgUser          Resource = "org_user"
+	ResourceSchedules        Resource = "schedules"
+	ResourceSecrets          Resource = "secrets"
+	ResourceTriggers         Resource = "triggers"
 )
@@ -0,0 +1,213 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"opsicle/internal/auth"
+	"opsicle/internal/automations"
+	"opsicle/internal/validate"
+	"regexp"
+	"time"
+)
+
+const (
+	// TemplateTriggerAuthHmac authenticates webhooks by a HMAC-SHA256
+	// signature of the request body using a secret generated for the
+	// trigger
+	TemplateTriggerAuthHmac = "hmac"
+
+	// TemplateTriggerAuthOrgToken authenticates webhooks by the API key
+	// of an org token
+	TemplateTriggerAuthOrgToken = "org_token"
+
+	DefaultTemplateTriggerDedupWindow     = 5 * time.Minute
+	DefaultTemplateTriggerRateLimitWindow = time.Minute
+
+	// MinTemplateTriggerWindow is the shortest dedup and rate limit
+	// window, windows are stored in whole seconds and are used as the
+	// expiry of cache keys which cannot be shorter than a second
+	MinTemplateTriggerWindow = time.Second
+)
+
+var templateTriggerNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_. -]{0,254}$`)
+
+type TemplateTriggers []TemplateTrigger
+
+// TemplateTrigger triggers automations from a template when a webhook
+// is received from an external system; automations are triggered as the
+// user who created the trigger
+type TemplateTrigger struct {
+	Id              *string `json:"id" yaml:"id"`
+	OrgId           *string `json:"orgId" yaml:"orgId"`
+	Name            string  `json:"name" yaml:"name"`
+	TemplateId      string  `json:"templateId" yaml:"templateId"`
+	TemplateName    string  `json:"templateName" yaml:"templateName"`
+	TemplateVersion *int64  `json:"templateVersion" yaml:"templateVersion"`
+	AuthType        string  `json:"authType" yaml:"authType"`
+	OrgTokenId      *string `json:"orgTokenId" yaml:"orgTokenId"`
+
+	// VariableMapping maps variable IDs to expressions which produce
+	// their values from the webhook payload
+	VariableMapping automations.PayloadMapping `json:"variableMapping" yaml:"variableMapping"`
+
+	// DedupKey is an expression evaluated against the webhook payload,
+	// webhooks which produce the same key within `DedupWindow` of each
+	// other only trigger one automation
+	DedupKey    *string       `json:"dedupKey" yaml:"dedupKey"`
+	DedupWindow time.Duration `json:"dedupWindow" yaml:"dedupWindow"`
+
+	// RateLimit is the maximum number of automations triggered within
+	// `RateLimitWindow`, zero means no limit
+	RateLimit       int           `json:"rateLimit" yaml:"rateLimit"`
+	RateLimitWindow time.Duration `json:"rateLimitWindow" yaml:"rateLimitWindow"`
+
+	LastTriggeredAt  *time.Time `json:"lastTriggeredAt" yaml:"lastTriggeredAt"`
+	LastAutomationId *string    `json:"lastAutomationId" yaml:"lastAutomationId"`
+	CreatedAt        time.Time  `json:"createdAt" yaml:"createdAt"`
+	CreatedBy        *User      `json:"createdBy" yaml:"createdBy"`
+	LastUpdatedAt    time.Time  `json:"lastUpdatedAt" yaml:"lastUpdatedAt"`
+	LastUpdatedBy    *User      `json:"lastUpdatedBy" yaml:"lastUpdatedBy"`
+
+	hmacSecret *string
+}
+
+func (tt TemplateTrigger) GetId() string {
+	if tt.Id == nil {
+		return ""
+	}
+	return *tt.Id
+}
+
+// GetOwnerId returns the ID of the user which automations triggered by
+// the trigger are run as
+func (tt TemplateTrigger) GetOwnerId() string {
+	if tt.CreatedBy == nil || tt.CreatedBy.Id == nil {
+		return ""
+	}
+	return *tt.CreatedBy.Id
+}
+
+func (tt *TemplateTrigger) assertId() error {
+	if tt.Id == nil {
+		return fmt.Errorf("%w: missing trigger id", ErrorIdRequired)
+	} else if err := validate.Uuid(*tt.Id); err != nil {
+		return fmt.Errorf("%w: invalid trigger id", ErrorInvalidInput)
+	}
+	return nil
+}
+
+// GetHmacSecret returns the decrypted secret which webhooks are signed
+// with when the trigger uses HMAC authentication
+func (tt TemplateTrigger) GetHmacSecret() (string, error) {
+	if tt.hmacSecret == nil {
+		return "", fmt.Errorf("models.TemplateTrigger.GetHmacSecret: trigger[%s] has no hmac secret", tt.GetId())
+	}
+	key, err := getEncryptionKey(encryptionPurposeTriggerSecrets)
+	if err != nil {
+		return "", err
+	}
+	secret, err := auth.Decrypt(key, *tt.hmacSecret)
+	if err != nil {
+		return "", fmt.Errorf("models.TemplateTrigger.GetHmacSecret: failed to decrypt secret: %w", err)
+	}
+	return string(secret), nil
+}
+
+// ValidateTemplateTriggerName returns an error if the provided name
+// cannot be used as the name of a trigger
+func ValidateTemplateTriggerName(name string) error {
+	if !templateTriggerNameRegex.MatchString(name) {
+		return fmt.Errorf("trigger name must start with an alphanumeric character and contain only alphanumerics, spaces, '_', '.' or '-': %w", ErrorInvalidInput)
+	}
+	return nil
+}
+
+const templateTriggerSelectFields = `
+	tt.id,
+	tt.org_id,
+	tt.name,
+	tt.template_id,
+	t.name,
+	tt.template_version,
+	tt.auth_type,
+	tt.hmac_secret,
+	tt.org_token_id,
+	tt.variable_mapping,
+	tt.dedup_key,
+	tt.dedup_window_seconds,
+	tt.rate_limit,
+	tt.rate_limit_window_seconds,
+	tt.last_triggered_at,
+	tt.last_automation_id,
+	tt.created_at,
+	tt.created_by,
+	created_by_user.email,
+	tt.last_updated_at,
+	tt.last_updated_by,
+	last_updated_by_user.email
+`
+
+const templateTriggerSelectJoins = `
+	JOIN templates t ON t.id = tt.template_id
+	JOIN users created_by_user ON created_by_user.id = tt.created_by
+	LEFT JOIN users last_updated_by_user ON last_updated_by_user.id = tt.last_updated_by
+`
+
+type templateTriggerScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTemplateTrigger(row templateTriggerScanner) (*TemplateTrigger, error) {
+	var (
+		trigger                TemplateTrigger
+		id                     string
+		variableMapping        []byte
+		dedupWindowSeconds     int
+		rateLimitWindowSeconds int
+		createdById            string
+		createdByEmail         string
+		lastUpdatedById        sql.NullString
+		lastUpdatedByEmail     sql.NullString
+	)
+	if err := row.Scan(
+		&id,
+		&trigger.OrgId,
+		&trigger.Name,
+		&trigger.TemplateId,
+		&trigger.TemplateName,
+		&trigger.TemplateVersion,
+		&trigger.AuthType,
+		&trigger.hmacSecret,
+		&trigger.OrgTokenId,
+		&variableMapping,
+		&trigger.DedupKey,
+		&dedupWindowSeconds,
+		&trigger.RateLimit,
+		&rateLimitWindowSeconds,
+		&trigger.LastTriggeredAt,
+		&trigger.LastAutomationId,
+		&trigger.CreatedAt,
+		&createdById,
+		&createdByEmail,
+		&trigger.LastUpdatedAt,
+		&lastUpdatedById,
+		&lastUpdatedByEmail,
+	); err != nil {
+		return nil, err
+	}
+	trigger.Id = &id
+	trigger.VariableMapping = automations.PayloadMapping{}
+	if len(variableMapping) > 0 {
+		if err := json.Unmarshal(variableMapping, &trigger.VariableMapping); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal variable mapping of trigger[%s]: %w", id, err)
+		}
+	}
+	trigger.DedupWindow = time.Duration(dedupWindowSeconds) * time.Second
+	trigger.RateLimitWindow = time.Duration(rateLimitWindowSeconds) * time.Second
+	trigger.CreatedBy = &User{Id: &createdById, Email: createdByEmail}
+	if lastUpdatedById.Valid {
+		trigger.LastUpdatedBy = &User{Id: &lastUpdatedById.String, Email: lastUpdatedByEmail.String}
+	}
+	return &trigger, nil
+}
@@ -0,0 +1,137 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"opsicle/internal/auth"
+	"opsicle/internal/automations"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateTemplateTriggerV1Input struct {
+	DatabaseConnection
+
+	OrgId           *string
+	Name            string
+	TemplateId      string
+	TemplateVersion *int64
+	AuthType        string
+
+	// HmacSecret is the secret webhooks are signed with, this is required
+	// when `.AuthType` is TemplateTriggerAuthHmac
+	HmacSecret string
+
+	// OrgTokenId identifies the org token whose API key authenticates
+	// webhooks, this is required when `.AuthType` is
+	// TemplateTriggerAuthOrgToken
+	OrgTokenId *string
+
+	VariableMapping automations.PayloadMapping
+	DedupKey        *string
+	DedupWindow     time.Duration
+	RateLimit       int
+	RateLimitWindow time.Duration
+
+	UserId string
+}
+
+// CreateTemplateTriggerV1 creates a trigger which runs the template
+// identified by `.TemplateId` as the user identified by `.UserId` when
+// an authenticated webhook is received
+func CreateTemplateTriggerV1(opts CreateTemplateTriggerV1Input) (*TemplateTrigger, error) {
+	if err := ValidateTemplateTriggerName(opts.Name); err != nil {
+		return nil, err
+	}
+	if err := opts.VariableMapping.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrorInvalidInput, err)
+	}
+	if opts.DedupKey != nil {
+		if err := automations.ValidatePayloadExpression(*opts.DedupKey); err != nil {
+			return nil, fmt.Errorf("%w: invalid dedup key: %w", ErrorInvalidInput, err)
+		}
+	}
+	if opts.DedupWindow <= 0 {
+		opts.DedupWindow = DefaultTemplateTriggerDedupWindow
+	} else if opts.DedupWindow < MinTemplateTriggerWindow {
+		return nil, fmt.Errorf("%w: dedup window cannot be shorter than %v", ErrorInvalidInput, MinTemplateTriggerWindow)
+	}
+	if opts.RateLimitWindow <= 0 {
+		opts.RateLimitWindow = DefaultTemplateTriggerRateLimitWindow
+	} else if opts.RateLimitWindow < MinTemplateTriggerWindow {
+		return nil, fmt.Errorf("%w: rate limit window cannot be shorter than %v", ErrorInvalidInput, MinTemplateTriggerWindow)
+	}
+	if opts.RateLimit < 0 {
+		return nil, fmt.Errorf("%w: rate limit cannot be negative", ErrorInvalidInput)
+	}
+	insertMap := map[string]any{}
+	switch opts.AuthType {
+	case TemplateTriggerAuthHmac:
+		if opts.HmacSecret == "" {
+			return nil, fmt.Errorf("%w: hmac secret undefined", ErrorInvalidInput)
+		}
+		key, err := getEncryptionKey(encryptionPurposeTriggerSecrets)
+		if err != nil {
+			return nil, err
+		}
+		encryptedSecret, err := auth.Encrypt(key, []byte(opts.HmacSecret))
+		if err != nil {
+			return nil, fmt.Errorf("models.CreateTemplateTriggerV1: failed to encrypt hmac secret: %w", err)
+		}
+		insertMap["hmac_secret"] = encryptedSecret
+	case TemplateTriggerAuthOrgToken:
+		if opts.OrgId == nil || opts.OrgTokenId == nil {
+			return nil, fmt.Errorf("%w: org token triggers require an org and an org token", ErrorInvalidInput)
+		}
+		insertMap["org_token_id"] = *opts.OrgTokenId
+	default:
+		return nil, fmt.Errorf("%w: unsupported auth type[%s]", ErrorInvalidInput, opts.AuthType)
+	}
+	variableMapping, err := json.Marshal(opts.VariableMapping)
+	if err != nil {
+		return nil, fmt.Errorf("models.CreateTemplateTriggerV1: failed to marshal variable mapping: %w", err)
+	}
+	triggerId := uuid.NewString()
+	insertMap["id"] = triggerId
+	insertMap["org_id"] = opts.OrgId
+	insertMap["name"] = opts.Name
+	insertMap["template_id"] = opts.TemplateId
+	insertMap["template_version"] = opts.TemplateVersion
+	insertMap["auth_type"] = opts.AuthType
+	insertMap["variable_mapping"] = variableMapping
+	insertMap["dedup_key"] = opts.DedupKey
+	insertMap["dedup_window_seconds"] = getWindowSeconds(opts.DedupWindow)
+	insertMap["rate_limit"] = opts.RateLimit
+	insertMap["rate_limit_window_seconds"] = getWindowSeconds(opts.RateLimitWindow)
+	insertMap["created_by"] = opts.UserId
+	insertMap["last_updated_by"] = opts.UserId
+	fieldNames, fieldValues, fieldPlaceholders, err := parseInsertMap(insertMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse insert map: %w", err)
+	}
+	if err := executeMysqlInsert(mysqlQueryInput{
+		Db: opts.Db,
+		Stmt: fmt.Sprintf(
+			`INSERT INTO template_triggers (%s) VALUES (%s)`,
+			strings.Join(fieldNames, ", "),
+			strings.Join(fieldPlaceholders, ", "),
+		),
+		Args:         fieldValues,
+		FnSource:     "models.CreateTemplateTriggerV1",
+		RowsAffected: oneRowAffected,
+	}); err != nil {
+		return nil, err
+	}
+	return GetTemplateTriggerV1(GetTemplateTriggerV1Input{
+		DatabaseConnection: opts.DatabaseConnection,
+		TriggerId:          triggerId,
+	})
+}
+
+// getWindowSeconds returns the `window` in whole seconds rounded up so
+// that a partial second is not dropped
+func getWindowSeconds(window time.Duration) int {
+	return int((window + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DeleteV1 deletes the trigger, automations it triggered are retained
+func (tt *TemplateTrigger) DeleteV1(opts DatabaseConnection) error {
+	if err := tt.assertId(); err != nil {
+		return err
+	}
+	if err := executeMysqlDelete(mysqlQueryInput{
+		Db:           opts.Db,
+		Stmt:         `DELETE FROM template_triggers WHERE id = ?`,
+		Args:         []any{tt.GetId()},
+		FnSource:     "models.TemplateTrigger.DeleteV1",
+		RowsAffected: oneRowAffected,
+	}); err != nil {
+		if errors.Is(err, ErrorRowsAffectedCheckFailed) {
+			return fmt.Errorf("trigger[%s] does not exist: %w", tt.GetId(), ErrorNotFound)
+		}
+		return err
+	}
+	return nil
+}
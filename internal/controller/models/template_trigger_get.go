@@ -0,0 +1,40 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"opsicle/internal/validate"
+)
+
+type GetTemplateTriggerV1Input struct {
+	DatabaseConnection
+
+	TriggerId string
+}
+
+// GetTemplateTriggerV1 returns the trigger identified by `.TriggerId`
+func GetTemplateTriggerV1(opts GetTemplateTriggerV1Input) (*TemplateTrigger, error) {
+	if err := validate.Uuid(opts.TriggerId); err != nil {
+		return nil, fmt.Errorf("%w: invalid trigger id", ErrorInvalidInput)
+	}
+	var trigger *TemplateTrigger
+	if err := executeMysqlSelect(mysqlQueryInput{
+		Db: opts.Db,
+		Stmt: fmt.Sprintf(`
+			SELECT %s
+				FROM template_triggers tt
+				%s
+				WHERE tt.id = ?
+		`, templateTriggerSelectFields, templateTriggerSelectJoins),
+		Args:     []any{opts.TriggerId},
+		FnSource: "models.GetTemplateTriggerV1",
+		ProcessRow: func(r *sql.Row) error {
+			var err error
+			trigger, err = scanTemplateTrigger(r)
+			return err
+		},
+	}); err != nil {
+		return nil, err
+	}
+	return trigger, nil
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+type ListTemplateTriggersV1Input struct {
+	DatabaseConnection
+
+	// OrgId when defined lists the triggers of the org, otherwise the
+	// triggers created by `.UserId` outside of any org are listed
+	OrgId  *string
+	UserId string
+}
+
+// ListTemplateTriggersV1 returns triggers sorted by name
+func ListTemplateTriggersV1(opts ListTemplateTriggersV1Input) (TemplateTriggers, error) {
+	filter := "tt.org_id IS NULL AND tt.created_by = ?"
+	args := []any{opts.UserId}
+	if opts.OrgId != nil {
+		filter = "tt.org_id = ?"
+		args = []any{*opts.OrgId}
+	}
+	triggers := TemplateTriggers{}
+	if err := executeMysqlSelects(mysqlQueryInput{
+		Db: opts.Db,
+		Stmt: fmt.Sprintf(`
+			SELECT %s
+				FROM template_triggers tt
+				%s
+				WHERE %s
+				ORDER BY tt.name ASC
+		`, templateTriggerSelectFields, templateTriggerSelectJoins, filter),
+		Args:     args,
+		FnSource: "models.ListTemplateTriggersV1",
+		ProcessRows: func(r *sql.Rows) error {
+			trigger, err := scanTemplateTrigger(r)
+			if err != nil {
+				return err
+			}
+			triggers = append(triggers, *trigger)
+			return nil
+		},
+	}); err != nil {
+		return nil, err
+	}
+	return triggers, nil
+}
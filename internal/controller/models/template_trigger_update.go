@@ -0,0 +1,41 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+type SetTemplateTriggerLastAutomationV1Input struct {
+	DatabaseConnection
+
+	AutomationId string
+	TriggeredAt  time.Time
+}
+
+// SetLastAutomationV1 records the automation which was last triggered by
+// the trigger
+func (tt *TemplateTrigger) SetLastAutomationV1(opts SetTemplateTriggerLastAutomationV1Input) error {
+	if err := tt.assertId(); err != nil {
+		return err
+	}
+	if err := executeMysqlUpdate(mysqlQueryInput{
+		Db: opts.Db,
+		Stmt: `
+			UPDATE template_triggers
+				SET last_automation_id = ?, last_triggered_at = ?
+				WHERE id = ?
+		`,
+		Args:         []any{opts.AutomationId, opts.TriggeredAt, tt.GetId()},
+		FnSource:     "models.TemplateTrigger.SetLastAutomationV1",
+		RowsAffected: oneRowAffected,
+	}); err != nil {
+		if errors.Is(err, ErrorRowsAffectedCheckFailed) {
+			return fmt.Errorf("trigger[%s] does not exist: %w", tt.GetId(), ErrorNotFound)
+		}
+		return err
+	}
+	tt.LastAutomationId = &opts.AutomationId
+	tt.LastTriggeredAt = &opts.TriggeredAt
+	return nil
+}
@@ -0,0 +1,117 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// UserPlatformIdentities are the users linked to a user on the
+// platforms approval requests are sent to
+type UserPlatformIdentities struct {
+	SlackIds    []string
+	TelegramIds []int64
+}
+
+// GetPlatformIdentitiesV1 returns the Slack and Telegram users linked
+// to the user
+func (u *User) GetPlatformIdentitiesV1(opts DatabaseConnection) (*UserPlatformIdentities, error) {
+	if u.Id == nil {
+		return nil, fmt.Errorf("models.User.GetPlatformIdentitiesV1: %w", ErrorIdRequired)
+	}
+	output := UserPlatformIdentities{SlackIds: []string{}, TelegramIds: []int64{}}
+	if err := executeMysqlSelects(mysqlQueryInput{
+		Db:       opts.Db,
+		Stmt:     `SELECT slack_id FROM user_slack WHERE user_id = ?`,
+		Args:     []any{u.GetId()},
+		FnSource: fmt.Sprintf("models.User.GetPlatformIdentitiesV1[%s][slack]", u.GetId()),
+		ProcessRows: func(r *sql.Rows) error {
+			var slackId string
+			if err := r.Scan(&slackId); err != nil {
+				return err
+			}
+			output.SlackIds = append(output.SlackIds, slackId)
+			return nil
+		},
+	}); err != nil {
+		return nil, err
+	}
+	if err := executeMysqlSelects(mysqlQueryInput{
+		Db:       opts.Db,
+		Stmt:     `SELECT telegram_id FROM user_telegram WHERE user_id = ?`,
+		Args:     []any{u.GetId()},
+		FnSource: fmt.Sprintf("models.User.GetPlatformIdentitiesV1[%s][telegram]", u.GetId()),
+		ProcessRows: func(r *sql.Rows) error {
+			var telegramId int64
+			if err := r.Scan(&telegramId); err != nil {
+				return err
+			}
+			output.TelegramIds = append(output.TelegramIds, telegramId)
+			return nil
+		},
+	}); err != nil {
+		return nil, err
+	}
+	return &output, nil
+}
+
+// GetMemberPlatformIdentitiesV1 returns the Slack and Telegram users
+// linked to each member of the org by the ID of the member, members
+// without linked users are not included
+func (o *Org) GetMemberPlatformIdentitiesV1(opts DatabaseConnection) (map[string]*UserPlatformIdentities, error) {
+	if o.Id == nil {
+		return nil, fmt.Errorf("models.Org.GetMemberPlatformIdentitiesV1: %w", ErrorIdRequired)
+	}
+	output := map[string]*UserPlatformIdentities{}
+	getIdentities := func(userId string) *UserPlatformIdentities {
+		if _, ok := output[userId]; !ok {
+			output[userId] = &UserPlatformIdentities{SlackIds: []string{}, TelegramIds: []int64{}}
+		}
+		return output[userId]
+	}
+	if err := executeMysqlSelects(mysqlQueryInput{
+		Db: opts.Db,
+		Stmt: `
+			SELECT us.user_id, us.slack_id
+				FROM user_slack us
+					JOIN org_users ou ON ou.user_id = us.user_id
+				WHERE ou.org_id = ?
+		`,
+		Args:     []any{*o.Id},
+		FnSource: fmt.Sprintf("models.Org.GetMemberPlatformIdentitiesV1[%s][slack]", *o.Id),
+		ProcessRows: func(r *sql.Rows) error {
+			var userId, slackId string
+			if err := r.Scan(&userId, &slackId); err != nil {
+				return err
+			}
+			identities := getIdentities(userId)
+			identities.SlackIds = append(identities.SlackIds, slackId)
+			return nil
+		},
+	}); err != nil {
+		return nil, err
+	}
+	if err := executeMysqlSelects(mysqlQueryInput{
+		Db: opts.Db,
+		Stmt: `
+			SELECT ut.user_id, ut.telegram_id
+				FROM user_telegram ut
+					JOIN org_users ou ON ou.user_id = ut.user_id
+				WHERE ou.org_id = ?
+		`,
+		Args:     []any{*o.Id},
+		FnSource: fmt.Sprintf("models.Org.GetMemberPlatformIdentitiesV1[%s][telegram]", *o.Id),
+		ProcessRows: func(r *sql.Rows) error {
+			var userId string
+			var telegramId int64
+			if err := r.Scan(&userId, &telegramId); err != nil {
+				return err
+			}
+			identities := getIdentities(userId)
+			identities.TelegramIds = append(identities.TelegramIds, telegramId)
+			return nil
+		},
+	}); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"opsicle/internal/approvals"
 	"opsicle/internal/audit"
 	"opsicle/internal/automations"
 	"opsicle/internal/common"
@@ -29,6 +30,7 @@ import (
 
 func registerOrgRoutes(opts RouteRegistrationOpts) {
 	requiresAuth := getRouteAuther(opts.ServiceLogs)
+	requireApiKey := getInternalRouteAuther(opts.ApiKeys, opts.ServiceLogs)
 
 	v1 := opts.Router.PathPrefix("/v1/org").Subrouter()
 
@@ -36,6 +38,8 @@ func registerOrgRoutes(opts RouteRegistrationOpts) {
 	v1.Handle("", requiresAuth(http.HandlerFunc(handleCreateOrgV1))).Methods(http.MethodPost)
 	v1.Handle("/{orgId}", requiresAuth(http.HandlerFunc(handleDeleteOrgV1))).Methods(http.MethodDelete)
 	v1.Handle("/{orgRef}", requiresAuth(http.HandlerFunc(handleGetOrgV1))).Methods(http.MethodGet)
+	v1.Handle("/{orgId}/automations", requiresAuth(http.HandlerFunc(handleListOrgAutomationsV1))).Methods(http.MethodGet)
+	v1.Handle("/{orgId}/config/approvals", requiresAuth(http.HandlerFunc(handleSetOrgApprovalsConfigV1))).Methods(http.MethodPut)
 	v1.Handle("/{orgId}/member", requiresAuth(http.HandlerFunc(handleCreateOrgUserV1))).Methods(http.MethodPost)
 	v1.Handle("/{orgId}/member", requiresAuth(http.HandlerFunc(handleGetOrgCurrentUserV1))).Methods(http.MethodGet)
 	v1.Handle("/{orgId}/member", requiresAuth(http.HandlerFunc(handleUpdateOrgUserV1))).Methods(http.MethodPatch)
@@ -48,6 +52,7 @@ func registerOrgRoutes(opts RouteRegistrationOpts) {
 	v1.Handle("/{orgId}/templates", requiresAuth(http.HandlerFunc(handleListOrgTemplatesV1))).Methods(http.MethodGet)
 	v1.Handle("/{orgId}/tokens", requiresAuth(http.HandlerFunc(handleListOrgTokensV1))).Methods(http.MethodGet)
 	v1.Handle("/{orgId}/token/{tokenId}", requiresAuth(http.HandlerFunc(handleGetOrgTokenV1))).Methods(http.MethodGet)
+	v1.Handle("/{orgId}/token/{tokenId}/certificate/verify", requireApiKey(http.HandlerFunc(handleVerifyOrgTokenCertificateV1))).Methods(http.MethodPost)
 	v1.Handle("/{orgId}/token", requiresAuth(http.HandlerFunc(handleCreateOrgTokenV1))).Methods(http.MethodPost)
 	v1.Handle("/invitation/{invitationId}", requiresAuth(http.HandlerFunc(handleUpdateOrgInvitationV1))).Methods(http.MethodPatch)
 	v1.HandleFunc("/validate", handleOrgTokenValidationV1).Methods(http.MethodPost)
@@ -243,13 +248,17 @@ func handleCreateOrgV1(w http.ResponseWriter, r *http.Request) {
 	log(common.LogLevelDebug, fmt.Sprintf("added default admin role[%s] to org[%s]", defaultAdminRole.GetId(), orgInstance.GetId()))
 	log(common.LogLevelDebug, fmt.Sprintf("adding permissions to admin role[%s]...", defaultAdminRole.GetId()))
 	resources := []models.Resource{
+		models.ResourceApprovalPolicies,
 		models.ResourceAutomationLogs,
 		models.ResourceAutomations,
 		models.ResourceOrg,
 		models.ResourceOrgBilling,
 		models.ResourceOrgConfig,
 		models.ResourceOrgUser,
+		models.ResourceSchedules,
+		models.ResourceSecrets,
 		models.ResourceTemplates,
+		models.ResourceTriggers,
 	}
 	permissionErrs := []error{}
 	for _, resource := range resources {
@@ -776,6 +785,19 @@ func handleSubmitOrgTemplateV1(w http.ResponseWriter, r *http.Request) {
 		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "failed to parse automation tempalte data", types.ErrorInvalidInput)
 		return
 	}
+	if err := template.Validate(); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, fmt.Sprintf("invalid automation template: %s", err), types.ErrorInvalidInput)
+		return
+	}
+	if err := resolveTemplateApprovalPolicy(&template, &orgId); err != nil {
+		if errors.Is(err, approvals.ErrorPolicyNotFound) {
+			common.SendHttpFailResponse(w, r, http.StatusBadRequest, fmt.Sprintf("invalid automation template: %s", err), types.ErrorInvalidInput)
+			return
+		}
+		log(common.LogLevelError, fmt.Sprintf("failed to resolve approval policy of template[%s]: %s", template.GetName(), err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to resolve approval policy", types.ErrorDatabaseIssue)
+		return
+	}
 
 	automationTemplateVersion, err := models.SubmitOrgTemplateV1(models.SubmitOrgTemplateV1Opts{
 		Db:       dbInstance,
@@ -2034,6 +2056,8 @@ func mapOrgPermissionResource(resource string) (models.Resource, string, error)
 	value := strings.ToLower(strings.TrimSpace(resource))
 	value = strings.ReplaceAll(value, "-", "_")
 	switch value {
+	case string(models.ResourceApprovalPolicies):
+		return models.ResourceApprovalPolicies, value, nil
 	case string(models.ResourceTemplates):
 		return models.ResourceTemplates, value, nil
 	case string(models.ResourceAutomations):
@@ -2048,6 +2072,12 @@ func mapOrgPermissionResource(resource string) (models.Resource, string, error)
 		return models.ResourceOrgConfig, value, nil
 	case string(models.ResourceOrgUser):
 		return models.ResourceOrgUser, value, nil
+	case string(models.ResourceSchedules):
+		return models.ResourceSchedules, value, nil
+	case string(models.ResourceSecrets):
+		return models.ResourceSecrets, value, nil
+	case string(models.ResourceTriggers):
+		return models.ResourceTriggers, value, nil
 	default:
 		return models.Resource(""), "", fmt.Errorf("unsupported resource: %s", resource)
 	}
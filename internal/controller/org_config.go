@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"opsicle/internal/audit"
+	"opsicle/internal/common"
+	"opsicle/internal/controller/models"
+	"opsicle/internal/types"
+	"opsicle/internal/validate"
+
+	"github.com/gorilla/mux"
+)
+
+type SetOrgApprovalsConfigV1Input struct {
+	IsApprovalsEnabled bool `json:"isApprovalsEnabled"`
+}
+
+type SetOrgApprovalsConfigV1Output struct {
+	IsApprovalsEnabled bool `json:"isApprovalsEnabled"`
+}
+
+// handleSetOrgApprovalsConfigV1 sets whether automations run in the org
+// are held for approval when their template defines an approval policy
+func handleSetOrgApprovalsConfigV1(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+	session := r.Context().Value(userAuthRequestContext).(userIdentity)
+
+	orgId := mux.Vars(r)["orgId"]
+	if err := validate.Uuid(orgId); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid org id", types.ErrorInvalidInput)
+		return
+	}
+	bodyData, err := io.ReadAll(r.Body)
+	if err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "failed to get body data", types.ErrorInvalidInput)
+		return
+	}
+	var input SetOrgApprovalsConfigV1Input
+	if err := json.Unmarshal(bodyData, &input); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "failed to parse body data", types.ErrorInvalidInput)
+		return
+	}
+	log(common.LogLevelDebug, fmt.Sprintf("user[%s] is setting approvals of org[%s] to %v", session.UserId, orgId, input.IsApprovalsEnabled))
+
+	org := models.Org{Id: &orgId}
+	orgUser, err := org.GetUserV1(models.GetOrgUserV1Opts{Db: dbInstance, UserId: session.UserId})
+	if err != nil {
+		if errors.Is(err, models.ErrorNotFound) {
+			common.SendHttpFailResponse(w, r, http.StatusForbidden, "not allowed", types.ErrorInsufficientPermissions)
+			return
+		}
+		log(common.LogLevelError, fmt.Sprintf("failed to load org user[%s] in org[%s]: %s", session.UserId, orgId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to retrieve org user", types.ErrorDatabaseIssue)
+		return
+	}
+	if _, _, isAllowed, err := orgUser.CanV1(models.DatabaseConnection{Db: dbInstance}, models.ResourceOrgConfig, models.ActionUpdate); err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to check permissions of user[%s] in org[%s]: %s", session.UserId, orgId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "not allowed", types.ErrorDatabaseIssue)
+		return
+	} else if !isAllowed {
+		log(common.LogLevelError, fmt.Sprintf("user[%s] is not allowed to update the config of org[%s]", session.UserId, orgId))
+		common.SendHttpFailResponse(w, r, http.StatusForbidden, "not allowed", types.ErrorInsufficientPermissions)
+		return
+	}
+
+	auditEntry := audit.LogEntry{
+		EntityId:     session.UserId,
+		EntityType:   audit.UserEntity,
+		Verb:         audit.Update,
+		ResourceId:   orgId,
+		ResourceType: audit.OrgConfigResource,
+		Status:       audit.Success,
+		SrcIp:        &session.SourceIp,
+		SrcUa:        &session.UserAgent,
+		DstHost:      &r.Host,
+		Data: map[string]any{
+			"isApprovalsEnabled": input.IsApprovalsEnabled,
+		},
+	}
+	orgConfig, err := org.SetApprovalsEnabledV1(models.SetOrgApprovalsEnabledV1Input{
+		DatabaseConnection: models.DatabaseConnection{Db: dbInstance},
+		IsApprovalsEnabled: input.IsApprovalsEnabled,
+	})
+	if err != nil {
+		auditEntry.Status = audit.Failed
+		audit.Log(auditEntry)
+		log(common.LogLevelError, fmt.Sprintf("failed to set approvals of org[%s]: %s", orgId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to update org config", types.ErrorDatabaseIssue)
+		return
+	}
+	audit.Log(auditEntry)
+	log(common.LogLevelInfo, fmt.Sprintf("user[%s] set approvals of org[%s] to %v", session.UserId, orgId, orgConfig.IsApprovalsEnabled))
+	common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", SetOrgApprovalsConfigV1Output{
+		IsApprovalsEnabled: orgConfig.IsApprovalsEnabled,
+	})
+}
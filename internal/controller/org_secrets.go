@@ -0,0 +1,334 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"opsicle/internal/audit"
+	"opsicle/internal/common"
+	"opsicle/internal/controller/models"
+	"opsicle/internal/types"
+	"opsicle/internal/validate"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func registerOrgSecretRoutes(opts RouteRegistrationOpts) {
+	requiresAuth := getRouteAuther(opts.ServiceLogs)
+	requireApiKey := getInternalRouteAuther(opts.ApiKeys, opts.ServiceLogs)
+
+	v1 := opts.Router.PathPrefix("/v1/org").Subrouter()
+
+	v1.Handle("/{orgId}/secret", requiresAuth(http.HandlerFunc(handleSetOrgSecretV1))).Methods(http.MethodPost)
+	v1.Handle("/{orgId}/secret", requiresAuth(http.HandlerFunc(handleListOrgSecretsV1))).Methods(http.MethodGet)
+	v1.Handle("/{orgId}/secret/{secretName}", requiresAuth(http.HandlerFunc(handleDeleteOrgSecretV1))).Methods(http.MethodDelete)
+	v1.Handle("/{orgId}/secrets/resolve", requireApiKey(http.HandlerFunc(handleResolveOrgSecretsV1))).Methods(http.MethodPost)
+}
+
+// canUserAccessOrgSecrets returns true if the user identified by
+// `userId` is allowed to perform `action` on secrets of the org
+// identified by `orgId`
+func canUserAccessOrgSecrets(orgId, userId string, action models.Action) (bool, error) {
+	org := models.Org{Id: &orgId}
+	orgUser, err := org.GetUserV1(models.GetOrgUserV1Opts{Db: dbInstance, UserId: userId})
+	if err != nil {
+		if errors.Is(err, models.ErrorNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to load org user[%s] in org[%s]: %w", userId, orgId, err)
+	}
+	_, _, isAllowed, err := orgUser.CanV1(models.DatabaseConnection{Db: dbInstance}, models.ResourceSecrets, action)
+	if err != nil {
+		return false, fmt.Errorf("failed to check permissions of user[%s] in org[%s]: %w", userId, orgId, err)
+	}
+	return isAllowed, nil
+}
+
+type OrgSecretV1OutputUser struct {
+	Id    string `json:"id"`
+	Email string `json:"email"`
+}
+
+type OrgSecretV1Output struct {
+	Id            string                 `json:"id"`
+	OrgId         string                 `json:"orgId"`
+	Name          string                 `json:"name"`
+	Description   *string                `json:"description"`
+	CreatedAt     time.Time              `json:"createdAt"`
+	CreatedBy     *OrgSecretV1OutputUser `json:"createdBy"`
+	LastUpdatedAt time.Time              `json:"lastUpdatedAt"`
+	LastUpdatedBy *OrgSecretV1OutputUser `json:"lastUpdatedBy"`
+}
+
+func newOrgSecretV1Output(secret models.OrgSecret) OrgSecretV1Output {
+	output := OrgSecretV1Output{
+		Id:            secret.GetId(),
+		OrgId:         secret.OrgId,
+		Name:          secret.Name,
+		Description:   secret.Description,
+		CreatedAt:     secret.CreatedAt,
+		LastUpdatedAt: secret.LastUpdatedAt,
+	}
+	if secret.CreatedBy != nil && secret.CreatedBy.Id != nil {
+		output.CreatedBy = &OrgSecretV1OutputUser{Id: *secret.CreatedBy.Id, Email: secret.CreatedBy.Email}
+	}
+	if secret.LastUpdatedBy != nil && secret.LastUpdatedBy.Id != nil {
+		output.LastUpdatedBy = &OrgSecretV1OutputUser{Id: *secret.LastUpdatedBy.Id, Email: secret.LastUpdatedBy.Email}
+	}
+	return output
+}
+
+type SetOrgSecretV1Input struct {
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+	Value       string  `json:"value"`
+}
+
+// handleSetOrgSecretV1 creates a secret in the org or replaces the value
+// of an existing one; the value is never returned by any endpoint
+func handleSetOrgSecretV1(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+	session := r.Context().Value(userAuthRequestContext).(userIdentity)
+
+	orgId := mux.Vars(r)["orgId"]
+	if err := validate.Uuid(orgId); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid org id", types.ErrorInvalidInput)
+		return
+	}
+	bodyData, err := io.ReadAll(r.Body)
+	if err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "failed to get body data", types.ErrorInvalidInput)
+		return
+	}
+	var input SetOrgSecretV1Input
+	if err := json.Unmarshal(bodyData, &input); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "failed to parse body data", types.ErrorInvalidInput)
+		return
+	}
+	if err := models.ValidateOrgSecretName(input.Name); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, err.Error(), types.ErrorInvalidInput)
+		return
+	}
+	if input.Value == "" {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "secret value must not be empty", types.ErrorInvalidInput)
+		return
+	}
+	log(common.LogLevelDebug, fmt.Sprintf("user[%s] is setting secret[%s] in org[%s]", session.UserId, input.Name, orgId))
+
+	org := models.Org{Id: &orgId}
+	requiredAction := models.ActionUpdate
+	if _, err := org.GetSecretV1(models.GetOrgSecretV1Input{
+		DatabaseConnection: models.DatabaseConnection{Db: dbInstance},
+		Name:               input.Name,
+	}); err != nil {
+		if !errors.Is(err, models.ErrorNotFound) {
+			log(common.LogLevelError, fmt.Sprintf("failed to check existence of secret[%s] in org[%s]: %s", input.Name, orgId, err))
+			common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to set secret", types.ErrorDatabaseIssue)
+			return
+		}
+		requiredAction = models.ActionCreate
+	}
+	if isAllowed, err := canUserAccessOrgSecrets(orgId, session.UserId, requiredAction); err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to check secret permissions: %s", err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "not allowed", types.ErrorDatabaseIssue)
+		return
+	} else if !isAllowed {
+		log(common.LogLevelError, fmt.Sprintf("user[%s] is not allowed to set secrets in org[%s]", session.UserId, orgId))
+		common.SendHttpFailResponse(w, r, http.StatusForbidden, "not allowed", types.ErrorInsufficientPermissions)
+		return
+	}
+
+	secret, err := org.SetSecretV1(models.SetOrgSecretV1Input{
+		DatabaseConnection: models.DatabaseConnection{Db: dbInstance},
+		Name:               input.Name,
+		Description:        input.Description,
+		Value:              input.Value,
+		UserId:             session.UserId,
+	})
+	if err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to set secret[%s] in org[%s]: %s", input.Name, orgId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to set secret", types.ErrorDatabaseIssue)
+		return
+	}
+	verb := audit.Update
+	if requiredAction == models.ActionCreate {
+		verb = audit.Create
+	}
+	audit.Log(audit.LogEntry{
+		EntityId:     session.UserId,
+		EntityType:   audit.UserEntity,
+		Verb:         verb,
+		ResourceId:   secret.GetId(),
+		ResourceType: audit.OrgSecretResource,
+		Status:       audit.Success,
+		SrcIp:        &session.SourceIp,
+		SrcUa:        &session.UserAgent,
+		DstHost:      &r.Host,
+		Data: map[string]any{
+			"orgId": orgId,
+			"name":  secret.Name,
+		},
+	})
+	common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", newOrgSecretV1Output(*secret))
+}
+
+type ListOrgSecretsV1Output []OrgSecretV1Output
+
+// handleListOrgSecretsV1 returns the metadata of secrets in the org
+func handleListOrgSecretsV1(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+	session := r.Context().Value(userAuthRequestContext).(userIdentity)
+
+	orgId := mux.Vars(r)["orgId"]
+	if err := validate.Uuid(orgId); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid org id", types.ErrorInvalidInput)
+		return
+	}
+	if isAllowed, err := canUserAccessOrgSecrets(orgId, session.UserId, models.ActionView); err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to check secret permissions: %s", err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "not allowed", types.ErrorDatabaseIssue)
+		return
+	} else if !isAllowed {
+		log(common.LogLevelError, fmt.Sprintf("user[%s] is not allowed to view secrets in org[%s]", session.UserId, orgId))
+		common.SendHttpFailResponse(w, r, http.StatusForbidden, "not allowed", types.ErrorInsufficientPermissions)
+		return
+	}
+
+	org := models.Org{Id: &orgId}
+	secrets, err := org.ListSecretsV1(models.ListOrgSecretsV1Input{
+		DatabaseConnection: models.DatabaseConnection{Db: dbInstance},
+	})
+	if err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to list secrets of org[%s]: %s", orgId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to list secrets", types.ErrorDatabaseIssue)
+		return
+	}
+	output := make(ListOrgSecretsV1Output, 0, len(secrets))
+	for _, secret := range secrets {
+		output = append(output, newOrgSecretV1Output(secret))
+	}
+	common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", output)
+}
+
+// handleDeleteOrgSecretV1 deletes a secret from the org
+func handleDeleteOrgSecretV1(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+	session := r.Context().Value(userAuthRequestContext).(userIdentity)
+
+	vars := mux.Vars(r)
+	orgId := vars["orgId"]
+	secretName := vars["secretName"]
+	if err := validate.Uuid(orgId); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid org id", types.ErrorInvalidInput)
+		return
+	}
+	if err := models.ValidateOrgSecretName(secretName); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid secret name", types.ErrorInvalidInput)
+		return
+	}
+	if isAllowed, err := canUserAccessOrgSecrets(orgId, session.UserId, models.ActionDelete); err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to check secret permissions: %s", err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "not allowed", types.ErrorDatabaseIssue)
+		return
+	} else if !isAllowed {
+		log(common.LogLevelError, fmt.Sprintf("user[%s] is not allowed to delete secrets in org[%s]", session.UserId, orgId))
+		common.SendHttpFailResponse(w, r, http.StatusForbidden, "not allowed", types.ErrorInsufficientPermissions)
+		return
+	}
+
+	org := models.Org{Id: &orgId}
+	if err := org.DeleteSecretV1(models.DeleteOrgSecretV1Input{
+		DatabaseConnection: models.DatabaseConnection{Db: dbInstance},
+		Name:               secretName,
+	}); err != nil {
+		if errors.Is(err, models.ErrorNotFound) {
+			common.SendHttpFailResponse(w, r, http.StatusNotFound, "secret not found", types.ErrorNotFound)
+			return
+		}
+		log(common.LogLevelError, fmt.Sprintf("failed to delete secret[%s] of org[%s]: %s", secretName, orgId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to delete secret", types.ErrorDatabaseIssue)
+		return
+	}
+	audit.Log(audit.LogEntry{
+		EntityId:     session.UserId,
+		EntityType:   audit.UserEntity,
+		Verb:         audit.Delete,
+		ResourceId:   secretName,
+		ResourceType: audit.OrgSecretResource,
+		Status:       audit.Success,
+		SrcIp:        &session.SourceIp,
+		SrcUa:        &session.UserAgent,
+		DstHost:      &r.Host,
+		Data: map[string]any{
+			"orgId": orgId,
+		},
+	})
+	common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok")
+}
+
+type ResolveOrgSecretsV1Input struct {
+	Names []string `json:"names"`
+}
+
+type ResolveOrgSecretsV1Output struct {
+	Values map[string]string `json:"values"`
+}
+
+// handleResolveOrgSecretsV1 returns the decrypted values of the secrets
+// identified by the provided names; this is an internal-only endpoint
+// used by the coordinator when handing a job over to a worker
+func handleResolveOrgSecretsV1(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+
+	orgId := mux.Vars(r)["orgId"]
+	if err := validate.Uuid(orgId); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid org id", types.ErrorInvalidInput)
+		return
+	}
+	bodyData, err := io.ReadAll(r.Body)
+	if err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "failed to get body data", types.ErrorInvalidInput)
+		return
+	}
+	var input ResolveOrgSecretsV1Input
+	if err := json.Unmarshal(bodyData, &input); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "failed to parse body data", types.ErrorInvalidInput)
+		return
+	}
+	output := ResolveOrgSecretsV1Output{Values: map[string]string{}}
+	if len(input.Names) == 0 {
+		common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", output)
+		return
+	}
+
+	org := models.Org{Id: &orgId}
+	secrets, err := org.ListSecretsV1(models.ListOrgSecretsV1Input{
+		DatabaseConnection: models.DatabaseConnection{Db: dbInstance},
+		Names:              input.Names,
+	})
+	if err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to list secrets of org[%s]: %s", orgId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to resolve secrets", types.ErrorDatabaseIssue)
+		return
+	}
+	for _, secret := range secrets {
+		value, err := secret.GetValue()
+		if err != nil {
+			log(common.LogLevelError, fmt.Sprintf("failed to decrypt secret[%s] of org[%s]: %s", secret.Name, orgId, err))
+			common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to resolve secrets", types.ErrorDatabaseIssue)
+			return
+		}
+		output.Values[secret.Name] = value
+	}
+	for _, name := range input.Names {
+		if _, exists := output.Values[name]; !exists {
+			common.SendHttpFailResponse(w, r, http.StatusNotFound, fmt.Sprintf("secret[%s] not found", name), types.ErrorNotFound)
+			return
+		}
+	}
+	log(common.LogLevelDebug, fmt.Sprintf("resolved %v secret(s) of org[%s]", len(output.Values), orgId))
+	common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", output)
+}
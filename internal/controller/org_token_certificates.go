@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"opsicle/internal/common"
+	"opsicle/internal/controller/models"
+	"opsicle/internal/types"
+	"opsicle/internal/validate"
+
+	"github.com/gorilla/mux"
+)
+
+type VerifyOrgTokenCertificateV1Input struct {
+	// CaCertificateB64 is the base64-encoded DER of the certificate
+	// authority which verified the client certificate of the token
+	CaCertificateB64 string `json:"caCertificateB64"`
+}
+
+type VerifyOrgTokenCertificateV1Output struct {
+	OrgId   string `json:"orgId"`
+	TokenId string `json:"tokenId"`
+}
+
+// handleVerifyOrgTokenCertificateV1 verifies that the token exists in
+// the org and that the certificate authority which verified a client
+// certificate of the token is the active certificate authority of the
+// org; this is an internal-only endpoint used by the coordinator when
+// workers authenticate with their client certificates
+func handleVerifyOrgTokenCertificateV1(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+
+	vars := mux.Vars(r)
+	orgId := vars["orgId"]
+	tokenId := vars["tokenId"]
+	if validate.Uuid(orgId) != nil || validate.Uuid(tokenId) != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid org or token id", types.ErrorInvalidInput)
+		return
+	}
+	bodyData, err := io.ReadAll(r.Body)
+	if err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "failed to get body data", types.ErrorInvalidInput)
+		return
+	}
+	var input VerifyOrgTokenCertificateV1Input
+	if err := json.Unmarshal(bodyData, &input); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "failed to parse body data", types.ErrorInvalidInput)
+		return
+	}
+	presentedCa, err := base64.StdEncoding.DecodeString(input.CaCertificateB64)
+	if err != nil || len(presentedCa) == 0 {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid certificate authority", types.ErrorInvalidInput)
+		return
+	}
+
+	org := models.Org{Id: &orgId}
+	if _, err := org.GetTokenByIdV1(models.GetOrgTokenByIdV1Opts{
+		DatabaseConnection: models.DatabaseConnection{Db: dbInstance},
+		TokenId:            tokenId,
+	}); err != nil {
+		if errors.Is(err, models.ErrorNotFound) {
+			log(common.LogLevelWarn, fmt.Sprintf("rejected certificate of unknown token[%s] of org[%s]", tokenId, orgId))
+			common.SendHttpFailResponse(w, r, http.StatusUnauthorized, "token was not found", types.ErrorInvalidCredentials)
+			return
+		}
+		log(common.LogLevelError, fmt.Sprintf("failed to load token[%s] of org[%s]: %s", tokenId, orgId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to retrieve org token", types.ErrorDatabaseIssue)
+		return
+	}
+	ca, err := org.LoadCertificateAuthorityV1(models.LoadOrgCertificateAuthorityV1Opts{
+		DatabaseConnection: models.DatabaseConnection{Db: dbInstance},
+	})
+	if err != nil {
+		if errors.Is(err, models.ErrorNotFound) {
+			log(common.LogLevelWarn, fmt.Sprintf("rejected certificate of token[%s] of org[%s] without a certificate authority", tokenId, orgId))
+			common.SendHttpFailResponse(w, r, http.StatusUnauthorized, "certificate authority was not found", types.ErrorInvalidCredentials)
+			return
+		}
+		log(common.LogLevelError, fmt.Sprintf("failed to load certificate authority of org[%s]: %s", orgId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to retrieve certificate authority", types.ErrorDatabaseIssue)
+		return
+	}
+	caCert, _, err := ca.GetCryptoMaterials()
+	if err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to parse certificate authority of org[%s]: %s", orgId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to load certificate authority", types.ErrorDatabaseIssue)
+		return
+	}
+	if ca.IsDeactivated || !bytes.Equal(caCert.Raw, presentedCa) {
+		log(common.LogLevelWarn, fmt.Sprintf("rejected certificate of token[%s] which was not issued by the certificate authority of org[%s]", tokenId, orgId))
+		common.SendHttpFailResponse(w, r, http.StatusUnauthorized, "certificate was not issued by the org", types.ErrorInvalidCredentials)
+		return
+	}
+	common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", VerifyOrgTokenCertificateV1Output{
+		OrgId:   orgId,
+		TokenId: tokenId,
+	})
+}
@@ -0,0 +1,450 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"opsicle/internal/audit"
+	"opsicle/internal/automations"
+	"opsicle/internal/common"
+	"opsicle/internal/controller/models"
+	"opsicle/internal/types"
+	"opsicle/internal/validate"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func registerAutomationScheduleRoutes(opts RouteRegistrationOpts) {
+	requiresAuth := getRouteAuther(opts.ServiceLogs)
+
+	v1 := opts.Router.PathPrefix("/v1/schedules").Subrouter()
+	v1.Handle("", requiresAuth(http.HandlerFunc(handleListAutomationSchedulesV1))).Methods(http.MethodGet)
+
+	scheduleRouter := opts.Router.PathPrefix("/v1/schedule").Subrouter()
+	scheduleRouter.Handle("", requiresAuth(http.HandlerFunc(handleCreateAutomationScheduleV1))).Methods(http.MethodPost)
+	scheduleRouter.Handle("/{scheduleId}", requiresAuth(http.HandlerFunc(handleGetAutomationScheduleV1))).Methods(http.MethodGet)
+	scheduleRouter.Handle("/{scheduleId}", requiresAuth(http.HandlerFunc(handleDeleteAutomationScheduleV1))).Methods(http.MethodDelete)
+	scheduleRouter.Handle("/{scheduleId}/pause", requiresAuth(http.HandlerFunc(handlePauseAutomationScheduleV1))).Methods(http.MethodPost)
+	scheduleRouter.Handle("/{scheduleId}/resume", requiresAuth(http.HandlerFunc(handleResumeAutomationScheduleV1))).Methods(http.MethodPost)
+}
+
+// canUserActOnSchedule returns true if the user identified by `userId`
+// is allowed to perform `action` on the schedule; schedules outside of
+// orgs can only be acted on by the users who created them
+func canUserActOnSchedule(schedule *models.AutomationSchedule, userId string, action models.Action) (bool, error) {
+	if schedule.OrgId == nil {
+		return schedule.GetOwnerId() == userId, nil
+	}
+	return canUserAccessOrgSchedules(*schedule.OrgId, userId, action)
+}
+
+// canUserAccessOrgSchedules returns true if the user identified by
+// `userId` is allowed to perform `action` on schedules of the org
+// identified by `orgId`
+func canUserAccessOrgSchedules(orgId, userId string, action models.Action) (bool, error) {
+	org := models.Org{Id: &orgId}
+	orgUser, err := org.GetUserV1(models.GetOrgUserV1Opts{Db: dbInstance, UserId: userId})
+	if err != nil {
+		if errors.Is(err, models.ErrorNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to load org user[%s] in org[%s]: %w", userId, orgId, err)
+	}
+	_, _, isAllowed, err := orgUser.CanV1(models.DatabaseConnection{Db: dbInstance}, models.ResourceSchedules, action)
+	if err != nil {
+		return false, fmt.Errorf("failed to check permissions of user[%s] in org[%s]: %w", userId, orgId, err)
+	}
+	return isAllowed, nil
+}
+
+// loadTemplateVersion replaces the content of the template with that of
+// the version identified by `version`
+func loadTemplateVersion(template *models.Template, version int64) error {
+	if template.GetVersion() == version {
+		return nil
+	}
+	if err := template.LoadVersionsV1(models.DatabaseConnection{Db: dbInstance}); err != nil {
+		return fmt.Errorf("failed to load versions of template[%s]: %w", template.GetId(), err)
+	}
+	for _, templateVersion := range template.Versions {
+		if templateVersion.Version == version {
+			template.Content = []byte(templateVersion.Content)
+			template.Version = &templateVersion.Version
+			return nil
+		}
+	}
+	return fmt.Errorf("version[%v] of template[%s] does not exist: %w", version, template.GetId(), models.ErrorNotFound)
+}
+
+type AutomationScheduleV1OutputUser struct {
+	Id    string `json:"id"`
+	Email string `json:"email"`
+}
+
+type AutomationScheduleV1Output struct {
+	Id               string                          `json:"id"`
+	OrgId            *string                         `json:"orgId"`
+	Name             string                          `json:"name"`
+	CronExpression   string                          `json:"cronExpression"`
+	Timezone         string                          `json:"timezone"`
+	TemplateId       string                          `json:"templateId"`
+	TemplateName     string                          `json:"templateName"`
+	TemplateVersion  *int64                          `json:"templateVersion"`
+	IsPaused         bool                            `json:"isPaused"`
+	NextRunAt        *time.Time                      `json:"nextRunAt"`
+	LastRunAt        *time.Time                      `json:"lastRunAt"`
+	LastAutomationId *string                         `json:"lastAutomationId"`
+	CreatedAt        time.Time                       `json:"createdAt"`
+	CreatedBy        *AutomationScheduleV1OutputUser `json:"createdBy"`
+	LastUpdatedAt    time.Time                       `json:"lastUpdatedAt"`
+	LastUpdatedBy    *AutomationScheduleV1OutputUser `json:"lastUpdatedBy"`
+}
+
+func newAutomationScheduleV1Output(schedule models.AutomationSchedule) AutomationScheduleV1Output {
+	output := AutomationScheduleV1Output{
+		Id:               schedule.GetId(),
+		OrgId:            schedule.OrgId,
+		Name:             schedule.Name,
+		CronExpression:   schedule.CronExpression,
+		Timezone:         schedule.Timezone,
+		TemplateId:       schedule.TemplateId,
+		TemplateName:     schedule.TemplateName,
+		TemplateVersion:  schedule.TemplateVersion,
+		IsPaused:         schedule.IsPaused,
+		NextRunAt:        schedule.NextRunAt,
+		LastRunAt:        schedule.LastRunAt,
+		LastAutomationId: schedule.LastAutomationId,
+		CreatedAt:        schedule.CreatedAt,
+		LastUpdatedAt:    schedule.LastUpdatedAt,
+	}
+	if schedule.CreatedBy != nil && schedule.CreatedBy.Id != nil {
+		output.CreatedBy = &AutomationScheduleV1OutputUser{Id: *schedule.CreatedBy.Id, Email: schedule.CreatedBy.Email}
+	}
+	if schedule.LastUpdatedBy != nil && schedule.LastUpdatedBy.Id != nil {
+		output.LastUpdatedBy = &AutomationScheduleV1OutputUser{Id: *schedule.LastUpdatedBy.Id, Email: schedule.LastUpdatedBy.Email}
+	}
+	return output
+}
+
+type CreateAutomationScheduleV1Input struct {
+	Name            string         `json:"name"`
+	CronExpression  string         `json:"cronExpression"`
+	Timezone        string         `json:"timezone"`
+	OrgId           *string        `json:"orgId"`
+	TemplateId      string         `json:"templateId"`
+	TemplateVersion *int64         `json:"templateVersion"`
+	VariableMap     map[string]any `json:"variableMap"`
+}
+
+// handleCreateAutomationScheduleV1 creates a schedule which triggers
+// automations from a template as the requesting user; when no template
+// version is specified the latest version at the time of each run is
+// used
+func handleCreateAutomationScheduleV1(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+	session := r.Context().Value(userAuthRequestContext).(userIdentity)
+
+	bodyData, err := io.ReadAll(r.Body)
+	if err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "failed to get body data", types.ErrorInvalidInput)
+		return
+	}
+	var input CreateAutomationScheduleV1Input
+	if err := json.Unmarshal(bodyData, &input); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "failed to parse body data", types.ErrorInvalidInput)
+		return
+	}
+	if err := models.ValidateAutomationScheduleName(input.Name); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, err.Error(), types.ErrorInvalidInput)
+		return
+	}
+	if _, err := automations.ParseSchedule(input.CronExpression, input.Timezone); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, err.Error(), types.ErrorInvalidInput)
+		return
+	}
+	if err := validate.Uuid(input.TemplateId); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid template id", types.ErrorInvalidInput)
+		return
+	}
+	if input.OrgId != nil {
+		if err := validate.Uuid(*input.OrgId); err != nil {
+			common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid org id", types.ErrorInvalidInput)
+			return
+		}
+		if isAllowed, err := canUserAccessOrgSchedules(*input.OrgId, session.UserId, models.ActionCreate); err != nil {
+			log(common.LogLevelError, fmt.Sprintf("failed to check schedule permissions: %s", err))
+			common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "not allowed", types.ErrorDatabaseIssue)
+			return
+		} else if !isAllowed {
+			log(common.LogLevelError, fmt.Sprintf("user[%s] is not allowed to create schedules in org[%s]", session.UserId, *input.OrgId))
+			common.SendHttpFailResponse(w, r, http.StatusForbidden, "not allowed", types.ErrorInsufficientPermissions)
+			return
+		}
+	}
+	log(common.LogLevelDebug, fmt.Sprintf("user[%s] is creating schedule[%s] for template[%s]", session.UserId, input.Name, input.TemplateId))
+
+	template, err := models.GetTemplateV1(models.GetTemplateV1Opts{
+		Db:         dbInstance,
+		TemplateId: &input.TemplateId,
+		UserId:     session.UserId,
+	})
+	if err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to get template[%s]: %s", input.TemplateId, err))
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid template", types.ErrorInvalidInput)
+		return
+	}
+	if canExecute, err := canUserExecuteTemplate(template, input.OrgId, session.UserId); err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to check permissions of user[%s] on template[%s]: %s", session.UserId, input.TemplateId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "not allowed", types.ErrorDatabaseIssue)
+		return
+	} else if !canExecute {
+		log(common.LogLevelError, fmt.Sprintf("user[%s] is not allowed to execute template[%s]", session.UserId, input.TemplateId))
+		common.SendHttpFailResponse(w, r, http.StatusForbidden, "not allowed", types.ErrorInsufficientPermissions)
+		return
+	}
+	if input.TemplateVersion != nil {
+		if err := loadTemplateVersion(template, *input.TemplateVersion); err != nil {
+			if errors.Is(err, models.ErrorNotFound) {
+				common.SendHttpFailResponse(w, r, http.StatusBadRequest, "template version does not exist", types.ErrorInvalidInput)
+				return
+			}
+			log(common.LogLevelError, fmt.Sprintf("failed to load template[%s]: %s", input.TemplateId, err))
+			common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to load template", types.ErrorDatabaseIssue)
+			return
+		}
+	}
+	sourceTemplate, err := automations.LoadAutomationTemplate(template.Content)
+	if err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to parse template[%s]: %s", input.TemplateId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to parse template", types.ErrorInvalidTemplate)
+		return
+	}
+	variableMap := sourceTemplate.Spec.Variables.GetUserInput(input.VariableMap)
+	if _, err := sourceTemplate.Spec.Variables.Resolve(variableMap); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, fmt.Sprintf("invalid variables: %s", err), types.ErrorInvalidInput)
+		return
+	}
+
+	schedule, err := models.CreateAutomationScheduleV1(models.CreateAutomationScheduleV1Input{
+		DatabaseConnection: models.DatabaseConnection{Db: dbInstance},
+		OrgId:              input.OrgId,
+		Name:               input.Name,
+		CronExpression:     input.CronExpression,
+		Timezone:           input.Timezone,
+		TemplateId:         input.TemplateId,
+		TemplateVersion:    input.TemplateVersion,
+		Variables:          sourceTemplate.Spec.Variables,
+		VariableMap:        variableMap,
+		UserId:             session.UserId,
+	})
+	if err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to create schedule[%s]: %s", input.Name, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to create schedule", types.ErrorDatabaseIssue)
+		return
+	}
+	audit.Log(audit.LogEntry{
+		EntityId:     session.UserId,
+		EntityType:   audit.UserEntity,
+		Verb:         audit.Create,
+		ResourceId:   schedule.GetId(),
+		ResourceType: audit.AutomationScheduleResource,
+		Status:       audit.Success,
+		SrcIp:        &session.SourceIp,
+		SrcUa:        &session.UserAgent,
+		DstHost:      &r.Host,
+		Data: map[string]any{
+			"orgId":          input.OrgId,
+			"name":           schedule.Name,
+			"cronExpression": schedule.CronExpression,
+			"timezone":       schedule.Timezone,
+			"templateId":     schedule.TemplateId,
+			"variables":      sourceTemplate.Spec.Variables.GetRedacted(variableMap),
+		},
+	})
+	common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", newAutomationScheduleV1Output(*schedule))
+}
+
+type ListAutomationSchedulesV1Output []AutomationScheduleV1Output
+
+// handleListAutomationSchedulesV1 returns the schedules of the org
+// specified in the `orgId` query parameter, or the schedules the user
+// created outside of orgs if it is not specified
+func handleListAutomationSchedulesV1(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+	session := r.Context().Value(userAuthRequestContext).(userIdentity)
+
+	var orgId *string
+	if orgIdInput := r.URL.Query().Get("orgId"); orgIdInput != "" {
+		if err := validate.Uuid(orgIdInput); err != nil {
+			common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid org id", types.ErrorInvalidInput)
+			return
+		}
+		if isAllowed, err := canUserAccessOrgSchedules(orgIdInput, session.UserId, models.ActionView); err != nil {
+			log(common.LogLevelError, fmt.Sprintf("failed to check schedule permissions: %s", err))
+			common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "not allowed", types.ErrorDatabaseIssue)
+			return
+		} else if !isAllowed {
+			log(common.LogLevelError, fmt.Sprintf("user[%s] is not allowed to view schedules in org[%s]", session.UserId, orgIdInput))
+			common.SendHttpFailResponse(w, r, http.StatusForbidden, "not allowed", types.ErrorInsufficientPermissions)
+			return
+		}
+		orgId = &orgIdInput
+	}
+
+	schedules, err := models.ListAutomationSchedulesV1(models.ListAutomationSchedulesV1Input{
+		DatabaseConnection: models.DatabaseConnection{Db: dbInstance},
+		OrgId:              orgId,
+		UserId:             session.UserId,
+	})
+	if err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to list schedules of user[%s]: %s", session.UserId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to list schedules", types.ErrorDatabaseIssue)
+		return
+	}
+	output := ListAutomationSchedulesV1Output{}
+	for _, schedule := range schedules {
+		output = append(output, newAutomationScheduleV1Output(schedule))
+	}
+	common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", output)
+}
+
+// getAutomationScheduleForRequest loads the schedule identified in the
+// request path and verifies that the requesting user is allowed to
+// perform `action` on it, a response is sent if this is not successful
+func getAutomationScheduleForRequest(w http.ResponseWriter, r *http.Request, action models.Action) (*models.AutomationSchedule, bool) {
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+	session := r.Context().Value(userAuthRequestContext).(userIdentity)
+
+	scheduleId := mux.Vars(r)["scheduleId"]
+	if err := validate.Uuid(scheduleId); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid schedule id", types.ErrorInvalidInput)
+		return nil, false
+	}
+	schedule, err := models.GetAutomationScheduleV1(models.GetAutomationScheduleV1Input{
+		DatabaseConnection: models.DatabaseConnection{Db: dbInstance},
+		ScheduleId:         scheduleId,
+	})
+	if err != nil {
+		if errors.Is(err, models.ErrorNotFound) {
+			common.SendHttpFailResponse(w, r, http.StatusNotFound, "schedule not found", types.ErrorNotFound)
+			return nil, false
+		}
+		log(common.LogLevelError, fmt.Sprintf("failed to load schedule[%s]: %s", scheduleId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to retrieve schedule", types.ErrorDatabaseIssue)
+		return nil, false
+	}
+	if isAllowed, err := canUserActOnSchedule(schedule, session.UserId, action); err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to check schedule permissions: %s", err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "not allowed", types.ErrorDatabaseIssue)
+		return nil, false
+	} else if !isAllowed {
+		log(common.LogLevelError, fmt.Sprintf("user[%s] is not allowed to access schedule[%s]", session.UserId, scheduleId))
+		common.SendHttpFailResponse(w, r, http.StatusForbidden, "not allowed", types.ErrorInsufficientPermissions)
+		return nil, false
+	}
+	return schedule, true
+}
+
+// handleGetAutomationScheduleV1 returns a schedule
+func handleGetAutomationScheduleV1(w http.ResponseWriter, r *http.Request) {
+	schedule, ok := getAutomationScheduleForRequest(w, r, models.ActionView)
+	if !ok {
+		return
+	}
+	common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", newAutomationScheduleV1Output(*schedule))
+}
+
+// handlePauseAutomationScheduleV1 stops a schedule from triggering
+// automations until it is resumed
+func handlePauseAutomationScheduleV1(w http.ResponseWriter, r *http.Request) {
+	handleSetAutomationSchedulePausedV1(w, r, true)
+}
+
+// handleResumeAutomationScheduleV1 resumes a paused schedule, runs that
+// were missed while it was paused are not triggered
+func handleResumeAutomationScheduleV1(w http.ResponseWriter, r *http.Request) {
+	handleSetAutomationSchedulePausedV1(w, r, false)
+}
+
+func handleSetAutomationSchedulePausedV1(w http.ResponseWriter, r *http.Request, isPaused bool) {
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+	session := r.Context().Value(userAuthRequestContext).(userIdentity)
+
+	schedule, ok := getAutomationScheduleForRequest(w, r, models.ActionUpdate)
+	if !ok {
+		return
+	}
+	log(common.LogLevelDebug, fmt.Sprintf("user[%s] is setting paused status of schedule[%s] to %v", session.UserId, schedule.GetId(), isPaused))
+	verb := audit.Start
+	if isPaused {
+		verb = audit.Stop
+	}
+	auditEntry := audit.LogEntry{
+		EntityId:     session.UserId,
+		EntityType:   audit.UserEntity,
+		Verb:         verb,
+		ResourceId:   schedule.GetId(),
+		ResourceType: audit.AutomationScheduleResource,
+		Status:       audit.Success,
+		SrcIp:        &session.SourceIp,
+		SrcUa:        &session.UserAgent,
+		DstHost:      &r.Host,
+	}
+	if err := schedule.SetPausedV1(models.SetAutomationSchedulePausedV1Input{
+		DatabaseConnection: models.DatabaseConnection{Db: dbInstance},
+		IsPaused:           isPaused,
+		UserId:             session.UserId,
+	}); err != nil {
+		auditEntry.Status = audit.Failed
+		audit.Log(auditEntry)
+		log(common.LogLevelError, fmt.Sprintf("failed to update schedule[%s]: %s", schedule.GetId(), err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to update schedule", types.ErrorDatabaseIssue)
+		return
+	}
+	audit.Log(auditEntry)
+	common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", newAutomationScheduleV1Output(*schedule))
+}
+
+// handleDeleteAutomationScheduleV1 deletes a schedule, automations it
+// already triggered are not affected
+func handleDeleteAutomationScheduleV1(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+	session := r.Context().Value(userAuthRequestContext).(userIdentity)
+
+	schedule, ok := getAutomationScheduleForRequest(w, r, models.ActionDelete)
+	if !ok {
+		return
+	}
+	log(common.LogLevelDebug, fmt.Sprintf("user[%s] is deleting schedule[%s]", session.UserId, schedule.GetId()))
+	auditEntry := audit.LogEntry{
+		EntityId:     session.UserId,
+		EntityType:   audit.UserEntity,
+		Verb:         audit.Delete,
+		ResourceId:   schedule.GetId(),
+		ResourceType: audit.AutomationScheduleResource,
+		Status:       audit.Success,
+		SrcIp:        &session.SourceIp,
+		SrcUa:        &session.UserAgent,
+		DstHost:      &r.Host,
+		Data: map[string]any{
+			"orgId": schedule.OrgId,
+			"name":  schedule.Name,
+		},
+	}
+	if err := schedule.DeleteV1(models.DatabaseConnection{Db: dbInstance}); err != nil {
+		if errors.Is(err, models.ErrorNotFound) {
+			common.SendHttpFailResponse(w, r, http.StatusNotFound, "schedule not found", types.ErrorNotFound)
+			return
+		}
+		auditEntry.Status = audit.Failed
+		audit.Log(auditEntry)
+		log(common.LogLevelError, fmt.Sprintf("failed to delete schedule[%s]: %s", schedule.GetId(), err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to delete schedule", types.ErrorDatabaseIssue)
+		return
+	}
+	audit.Log(auditEntry)
+	common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok")
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"opsicle/internal/approvals"
 	"opsicle/internal/audit"
 	"opsicle/internal/automations"
 	"opsicle/internal/common"
@@ -69,6 +70,19 @@ func handleSubmitTemplateV1(w http.ResponseWriter, r *http.Request) {
 		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "failed to parse automation tempalte data", types.ErrorInvalidInput)
 		return
 	}
+	if err := template.Validate(); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, fmt.Sprintf("invalid automation template: %s", err), types.ErrorInvalidInput)
+		return
+	}
+	if err := resolveTemplateApprovalPolicy(&template, nil); err != nil {
+		if errors.Is(err, approvals.ErrorPolicyNotFound) {
+			common.SendHttpFailResponse(w, r, http.StatusBadRequest, fmt.Sprintf("invalid automation template: %s", err), types.ErrorInvalidInput)
+			return
+		}
+		log(common.LogLevelError, fmt.Sprintf("failed to resolve approval policy of template[%s]: %s", template.GetName(), err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to resolve approval policy", types.ErrorDatabaseIssue)
+		return
+	}
 
 	automationTemplateVersion, err := models.CreateTemplateVersionV1(models.CreateTemplateVersionV1Opts{
 		Db:       dbInstance,
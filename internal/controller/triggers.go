@@ -0,0 +1,693 @@
+package controller
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"opsicle/internal/audit"
+	"opsicle/internal/automations"
+	"opsicle/internal/common"
+	"opsicle/internal/controller/models"
+	"opsicle/internal/types"
+	"opsicle/internal/validate"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// TemplateTriggerSignatureHeader is the header which carries the
+	// HMAC-SHA256 signature of webhooks sent to triggers using HMAC
+	// authentication in the format `sha256=<hex-encoded-signature>`, the
+	// signature is of `<timestamp>.<body>`
+	TemplateTriggerSignatureHeader = "X-Opsicle-Signature"
+
+	// TemplateTriggerTimestampHeader is the header which carries the
+	// unix timestamp in seconds at which webhooks sent to triggers using
+	// HMAC authentication were signed
+	TemplateTriggerTimestampHeader = "X-Opsicle-Timestamp"
+
+	// TemplateTriggerMaxSignatureAge is how far the timestamp of a
+	// signed webhook can be from the current time before the webhook is
+	// rejected so that captured webhooks cannot be replayed later
+	TemplateTriggerMaxSignatureAge = 5 * time.Minute
+
+	templateTriggerHmacSecretLength = 64
+	templateTriggerMaxPayloadSize   = 1 << 20
+)
+
+func registerTemplateTriggerRoutes(opts RouteRegistrationOpts) {
+	requiresAuth := getRouteAuther(opts.ServiceLogs)
+
+	v1 := opts.Router.PathPrefix("/v1/triggers").Subrouter()
+	v1.Handle("", requiresAuth(http.HandlerFunc(handleListTemplateTriggersV1))).Methods(http.MethodGet)
+
+	triggerRouter := opts.Router.PathPrefix("/v1/trigger").Subrouter()
+	triggerRouter.Handle("", requiresAuth(http.HandlerFunc(handleCreateTemplateTriggerV1))).Methods(http.MethodPost)
+	triggerRouter.Handle("/{triggerId}", requiresAuth(http.HandlerFunc(handleGetTemplateTriggerV1))).Methods(http.MethodGet)
+	triggerRouter.Handle("/{triggerId}", requiresAuth(http.HandlerFunc(handleDeleteTemplateTriggerV1))).Methods(http.MethodDelete)
+
+	// webhooks are authenticated by the trigger's own credentials instead
+	// of user sessions so that external systems can call them
+	webhookRouter := opts.Router.PathPrefix("/v1/webhook").Subrouter()
+	webhookRouter.HandleFunc("/{triggerId}", handleTemplateTriggerWebhookV1).Methods(http.MethodPost)
+}
+
+// canUserActOnTrigger returns true if the user identified by `userId` is
+// allowed to perform `action` on the trigger; triggers outside of orgs
+// can only be acted on by the users who created them
+func canUserActOnTrigger(trigger *models.TemplateTrigger, userId string, action models.Action) (bool, error) {
+	if trigger.OrgId == nil {
+		return trigger.GetOwnerId() == userId, nil
+	}
+	return canUserAccessOrgTriggers(*trigger.OrgId, userId, action)
+}
+
+// canUserAccessOrgTriggers returns true if the user identified by
+// `userId` is allowed to perform `action` on triggers of the org
+// identified by `orgId`
+func canUserAccessOrgTriggers(orgId, userId string, action models.Action) (bool, error) {
+	org := models.Org{Id: &orgId}
+	orgUser, err := org.GetUserV1(models.GetOrgUserV1Opts{Db: dbInstance, UserId: userId})
+	if err != nil {
+		if errors.Is(err, models.ErrorNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to load org user[%s] in org[%s]: %w", userId, orgId, err)
+	}
+	_, _, isAllowed, err := orgUser.CanV1(models.DatabaseConnection{Db: dbInstance}, models.ResourceTriggers, action)
+	if err != nil {
+		return false, fmt.Errorf("failed to check permissions of user[%s] in org[%s]: %w", userId, orgId, err)
+	}
+	return isAllowed, nil
+}
+
+type TemplateTriggerV1OutputUser struct {
+	Id    string `json:"id"`
+	Email string `json:"email"`
+}
+
+type TemplateTriggerV1Output struct {
+	Id               string                       `json:"id"`
+	OrgId            *string                      `json:"orgId"`
+	Name             string                       `json:"name"`
+	TemplateId       string                       `json:"templateId"`
+	TemplateName     string                       `json:"templateName"`
+	TemplateVersion  *int64                       `json:"templateVersion"`
+	AuthType         string                       `json:"authType"`
+	OrgTokenId       *string                      `json:"orgTokenId"`
+	VariableMapping  map[string]string            `json:"variableMapping"`
+	DedupKey         *string                      `json:"dedupKey"`
+	DedupWindow      time.Duration                `json:"dedupWindow"`
+	RateLimit        int                          `json:"rateLimit"`
+	RateLimitWindow  time.Duration                `json:"rateLimitWindow"`
+	WebhookPath      string                       `json:"webhookPath"`
+	LastTriggeredAt  *time.Time                   `json:"lastTriggeredAt"`
+	LastAutomationId *string                      `json:"lastAutomationId"`
+	CreatedAt        time.Time                    `json:"createdAt"`
+	CreatedBy        *TemplateTriggerV1OutputUser `json:"createdBy"`
+	LastUpdatedAt    time.Time                    `json:"lastUpdatedAt"`
+	LastUpdatedBy    *TemplateTriggerV1OutputUser `json:"lastUpdatedBy"`
+
+	// HmacSecret is only returned when a trigger using HMAC authentication
+	// is created, it cannot be retrieved afterwards
+	HmacSecret string `json:"hmacSecret,omitempty"`
+}
+
+func newTemplateTriggerV1Output(trigger models.TemplateTrigger) TemplateTriggerV1Output {
+	output := TemplateTriggerV1Output{
+		Id:               trigger.GetId(),
+		OrgId:            trigger.OrgId,
+		Name:             trigger.Name,
+		TemplateId:       trigger.TemplateId,
+		TemplateName:     trigger.TemplateName,
+		TemplateVersion:  trigger.TemplateVersion,
+		AuthType:         trigger.AuthType,
+		OrgTokenId:       trigger.OrgTokenId,
+		VariableMapping:  trigger.VariableMapping,
+		DedupKey:         trigger.DedupKey,
+		DedupWindow:      trigger.DedupWindow,
+		RateLimit:        trigger.RateLimit,
+		RateLimitWindow:  trigger.RateLimitWindow,
+		WebhookPath:      fmt.Sprintf("/api/v1/webhook/%s", trigger.GetId()),
+		LastTriggeredAt:  trigger.LastTriggeredAt,
+		LastAutomationId: trigger.LastAutomationId,
+		CreatedAt:        trigger.CreatedAt,
+		LastUpdatedAt:    trigger.LastUpdatedAt,
+	}
+	if trigger.CreatedBy != nil && trigger.CreatedBy.Id != nil {
+		output.CreatedBy = &TemplateTriggerV1OutputUser{Id: *trigger.CreatedBy.Id, Email: trigger.CreatedBy.Email}
+	}
+	if trigger.LastUpdatedBy != nil && trigger.LastUpdatedBy.Id != nil {
+		output.LastUpdatedBy = &TemplateTriggerV1OutputUser{Id: *trigger.LastUpdatedBy.Id, Email: trigger.LastUpdatedBy.Email}
+	}
+	return output
+}
+
+type CreateTemplateTriggerV1Input struct {
+	Name            string            `json:"name"`
+	OrgId           *string           `json:"orgId"`
+	TemplateId      string            `json:"templateId"`
+	TemplateVersion *int64            `json:"templateVersion"`
+	AuthType        string            `json:"authType"`
+	OrgTokenId      *string           `json:"orgTokenId"`
+	VariableMapping map[string]string `json:"variableMapping"`
+	DedupKey        *string           `json:"dedupKey"`
+	DedupWindow     time.Duration     `json:"dedupWindow"`
+	RateLimit       int               `json:"rateLimit"`
+	RateLimitWindow time.Duration     `json:"rateLimitWindow"`
+}
+
+// handleCreateTemplateTriggerV1 creates a trigger which runs automations
+// from a template as the requesting user when a webhook is received;
+// when the trigger uses HMAC authentication the generated secret is
+// returned only in this response
+func handleCreateTemplateTriggerV1(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+	session := r.Context().Value(userAuthRequestContext).(userIdentity)
+
+	bodyData, err := io.ReadAll(r.Body)
+	if err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "failed to get body data", types.ErrorInvalidInput)
+		return
+	}
+	var input CreateTemplateTriggerV1Input
+	if err := json.Unmarshal(bodyData, &input); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "failed to parse body data", types.ErrorInvalidInput)
+		return
+	}
+	if err := models.ValidateTemplateTriggerName(input.Name); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, err.Error(), types.ErrorInvalidInput)
+		return
+	}
+	if err := validate.Uuid(input.TemplateId); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid template id", types.ErrorInvalidInput)
+		return
+	}
+	variableMapping := automations.PayloadMapping(input.VariableMapping)
+	if err := variableMapping.Validate(); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, err.Error(), types.ErrorInvalidInput)
+		return
+	}
+	if input.DedupKey != nil {
+		if err := automations.ValidatePayloadExpression(*input.DedupKey); err != nil {
+			common.SendHttpFailResponse(w, r, http.StatusBadRequest, fmt.Sprintf("invalid dedup key: %s", err), types.ErrorInvalidInput)
+			return
+		}
+	}
+	if input.RateLimit < 0 || input.DedupWindow < 0 || input.RateLimitWindow < 0 {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "rate limits and windows cannot be negative", types.ErrorInvalidInput)
+		return
+	}
+	for _, window := range []time.Duration{input.DedupWindow, input.RateLimitWindow} {
+		if window > 0 && window < models.MinTemplateTriggerWindow {
+			common.SendHttpFailResponse(w, r, http.StatusBadRequest, fmt.Sprintf("windows cannot be shorter than %v", models.MinTemplateTriggerWindow), types.ErrorInvalidInput)
+			return
+		}
+	}
+	if input.OrgId != nil {
+		if err := validate.Uuid(*input.OrgId); err != nil {
+			common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid org id", types.ErrorInvalidInput)
+			return
+		}
+		if isAllowed, err := canUserAccessOrgTriggers(*input.OrgId, session.UserId, models.ActionCreate); err != nil {
+			log(common.LogLevelError, fmt.Sprintf("failed to check trigger permissions: %s", err))
+			common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "not allowed", types.ErrorDatabaseIssue)
+			return
+		} else if !isAllowed {
+			log(common.LogLevelError, fmt.Sprintf("user[%s] is not allowed to create triggers in org[%s]", session.UserId, *input.OrgId))
+			common.SendHttpFailResponse(w, r, http.StatusForbidden, "not allowed", types.ErrorInsufficientPermissions)
+			return
+		}
+	}
+
+	hmacSecret := ""
+	switch input.AuthType {
+	case models.TemplateTriggerAuthHmac:
+		hmacSecret, err = generateApiKey(templateTriggerHmacSecretLength)
+		if err != nil {
+			log(common.LogLevelError, fmt.Sprintf("failed to generate hmac secret: %s", err))
+			common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to generate secret", types.ErrorGeneric)
+			return
+		}
+	case models.TemplateTriggerAuthOrgToken:
+		if input.OrgId == nil || input.OrgTokenId == nil {
+			common.SendHttpFailResponse(w, r, http.StatusBadRequest, "org token triggers require an org and an org token", types.ErrorInvalidInput)
+			return
+		}
+		org := models.Org{Id: input.OrgId}
+		if _, err := org.GetTokenByIdV1(models.GetOrgTokenByIdV1Opts{
+			DatabaseConnection: models.DatabaseConnection{Db: dbInstance},
+			TokenId:            *input.OrgTokenId,
+		}); err != nil {
+			if errors.Is(err, models.ErrorNotFound) || errors.Is(err, models.ErrorInvalidInput) {
+				common.SendHttpFailResponse(w, r, http.StatusBadRequest, "org token does not exist", types.ErrorInvalidInput)
+				return
+			}
+			log(common.LogLevelError, fmt.Sprintf("failed to get token[%s] of org[%s]: %s", *input.OrgTokenId, *input.OrgId, err))
+			common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to retrieve org token", types.ErrorDatabaseIssue)
+			return
+		}
+	default:
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, fmt.Sprintf("auth type must be one of ['%s', '%s']", models.TemplateTriggerAuthHmac, models.TemplateTriggerAuthOrgToken), types.ErrorInvalidInput)
+		return
+	}
+	log(common.LogLevelDebug, fmt.Sprintf("user[%s] is creating trigger[%s] for template[%s]", session.UserId, input.Name, input.TemplateId))
+
+	template, err := models.GetTemplateV1(models.GetTemplateV1Opts{
+		Db:         dbInstance,
+		TemplateId: &input.TemplateId,
+		UserId:     session.UserId,
+	})
+	if err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to get template[%s]: %s", input.TemplateId, err))
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid template", types.ErrorInvalidInput)
+		return
+	}
+	if canExecute, err := canUserExecuteTemplate(template, input.OrgId, session.UserId); err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to check permissions of user[%s] on template[%s]: %s", session.UserId, input.TemplateId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "not allowed", types.ErrorDatabaseIssue)
+		return
+	} else if !canExecute {
+		log(common.LogLevelError, fmt.Sprintf("user[%s] is not allowed to execute template[%s]", session.UserId, input.TemplateId))
+		common.SendHttpFailResponse(w, r, http.StatusForbidden, "not allowed", types.ErrorInsufficientPermissions)
+		return
+	}
+	if input.TemplateVersion != nil {
+		if err := loadTemplateVersion(template, *input.TemplateVersion); err != nil {
+			if errors.Is(err, models.ErrorNotFound) {
+				common.SendHttpFailResponse(w, r, http.StatusBadRequest, "template version does not exist", types.ErrorInvalidInput)
+				return
+			}
+			log(common.LogLevelError, fmt.Sprintf("failed to load template[%s]: %s", input.TemplateId, err))
+			common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to load template", types.ErrorDatabaseIssue)
+			return
+		}
+	}
+	sourceTemplate, err := automations.LoadAutomationTemplate(template.Content)
+	if err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to parse template[%s]: %s", input.TemplateId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to parse template", types.ErrorInvalidTemplate)
+		return
+	}
+	for variableId := range variableMapping {
+		if !slices.ContainsFunc(sourceTemplate.Spec.Variables, func(variable automations.VariableSpec) bool {
+			return variable.Id == variableId
+		}) {
+			common.SendHttpFailResponse(w, r, http.StatusBadRequest, fmt.Sprintf("var[%s] is not defined in the template", variableId), types.ErrorInvalidInput)
+			return
+		}
+	}
+
+	trigger, err := models.CreateTemplateTriggerV1(models.CreateTemplateTriggerV1Input{
+		DatabaseConnection: models.DatabaseConnection{Db: dbInstance},
+		OrgId:              input.OrgId,
+		Name:               input.Name,
+		TemplateId:         input.TemplateId,
+		TemplateVersion:    input.TemplateVersion,
+		AuthType:           input.AuthType,
+		HmacSecret:         hmacSecret,
+		OrgTokenId:         input.OrgTokenId,
+		VariableMapping:    variableMapping,
+		DedupKey:           input.DedupKey,
+		DedupWindow:        input.DedupWindow,
+		RateLimit:          input.RateLimit,
+		RateLimitWindow:    input.RateLimitWindow,
+		UserId:             session.UserId,
+	})
+	if err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to create trigger[%s]: %s", input.Name, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to create trigger", types.ErrorDatabaseIssue)
+		return
+	}
+	audit.Log(audit.LogEntry{
+		EntityId:     session.UserId,
+		EntityType:   audit.UserEntity,
+		Verb:         audit.Create,
+		ResourceId:   trigger.GetId(),
+		ResourceType: audit.TemplateTriggerResource,
+		Status:       audit.Success,
+		SrcIp:        &session.SourceIp,
+		SrcUa:        &session.UserAgent,
+		DstHost:      &r.Host,
+		Data: map[string]any{
+			"orgId":      input.OrgId,
+			"name":       trigger.Name,
+			"templateId": trigger.TemplateId,
+			"authType":   trigger.AuthType,
+			"orgTokenId": trigger.OrgTokenId,
+		},
+	})
+	output := newTemplateTriggerV1Output(*trigger)
+	output.HmacSecret = hmacSecret
+	common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", output)
+}
+
+type ListTemplateTriggersV1Output []TemplateTriggerV1Output
+
+// handleListTemplateTriggersV1 returns the triggers of the org specified
+// in the `orgId` query parameter, or the triggers the user created
+// outside of orgs if it is not specified
+func handleListTemplateTriggersV1(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+	session := r.Context().Value(userAuthRequestContext).(userIdentity)
+
+	var orgId *string
+	if orgIdInput := r.URL.Query().Get("orgId"); orgIdInput != "" {
+		if err := validate.Uuid(orgIdInput); err != nil {
+			common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid org id", types.ErrorInvalidInput)
+			return
+		}
+		if isAllowed, err := canUserAccessOrgTriggers(orgIdInput, session.UserId, models.ActionView); err != nil {
+			log(common.LogLevelError, fmt.Sprintf("failed to check trigger permissions: %s", err))
+			common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "not allowed", types.ErrorDatabaseIssue)
+			return
+		} else if !isAllowed {
+			log(common.LogLevelError, fmt.Sprintf("user[%s] is not allowed to view triggers in org[%s]", session.UserId, orgIdInput))
+			common.SendHttpFailResponse(w, r, http.StatusForbidden, "not allowed", types.ErrorInsufficientPermissions)
+			return
+		}
+		orgId = &orgIdInput
+	}
+
+	triggers, err := models.ListTemplateTriggersV1(models.ListTemplateTriggersV1Input{
+		DatabaseConnection: models.DatabaseConnection{Db: dbInstance},
+		OrgId:              orgId,
+		UserId:             session.UserId,
+	})
+	if err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to list triggers of user[%s]: %s", session.UserId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to list triggers", types.ErrorDatabaseIssue)
+		return
+	}
+	output := ListTemplateTriggersV1Output{}
+	for _, trigger := range triggers {
+		output = append(output, newTemplateTriggerV1Output(trigger))
+	}
+	common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", output)
+}
+
+// getTemplateTriggerForRequest loads the trigger identified in the
+// request path and verifies that the requesting user is allowed to
+// perform `action` on it, a response is sent if this is not successful
+func getTemplateTriggerForRequest(w http.ResponseWriter, r *http.Request, action models.Action) (*models.TemplateTrigger, bool) {
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+	session := r.Context().Value(userAuthRequestContext).(userIdentity)
+
+	triggerId := mux.Vars(r)["triggerId"]
+	if err := validate.Uuid(triggerId); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid trigger id", types.ErrorInvalidInput)
+		return nil, false
+	}
+	trigger, err := models.GetTemplateTriggerV1(models.GetTemplateTriggerV1Input{
+		DatabaseConnection: models.DatabaseConnection{Db: dbInstance},
+		TriggerId:          triggerId,
+	})
+	if err != nil {
+		if errors.Is(err, models.ErrorNotFound) {
+			common.SendHttpFailResponse(w, r, http.StatusNotFound, "trigger not found", types.ErrorNotFound)
+			return nil, false
+		}
+		log(common.LogLevelError, fmt.Sprintf("failed to load trigger[%s]: %s", triggerId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to retrieve trigger", types.ErrorDatabaseIssue)
+		return nil, false
+	}
+	if isAllowed, err := canUserActOnTrigger(trigger, session.UserId, action); err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to check trigger permissions: %s", err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "not allowed", types.ErrorDatabaseIssue)
+		return nil, false
+	} else if !isAllowed {
+		log(common.LogLevelError, fmt.Sprintf("user[%s] is not allowed to access trigger[%s]", session.UserId, triggerId))
+		common.SendHttpFailResponse(w, r, http.StatusForbidden, "not allowed", types.ErrorInsufficientPermissions)
+		return nil, false
+	}
+	return trigger, true
+}
+
+// handleGetTemplateTriggerV1 returns a trigger
+func handleGetTemplateTriggerV1(w http.ResponseWriter, r *http.Request) {
+	trigger, ok := getTemplateTriggerForRequest(w, r, models.ActionView)
+	if !ok {
+		return
+	}
+	common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", newTemplateTriggerV1Output(*trigger))
+}
+
+// handleDeleteTemplateTriggerV1 deletes a trigger, automations it
+// already triggered are not affected
+func handleDeleteTemplateTriggerV1(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+	session := r.Context().Value(userAuthRequestContext).(userIdentity)
+
+	trigger, ok := getTemplateTriggerForRequest(w, r, models.ActionDelete)
+	if !ok {
+		return
+	}
+	log(common.LogLevelDebug, fmt.Sprintf("user[%s] is deleting trigger[%s]", session.UserId, trigger.GetId()))
+	auditEntry := audit.LogEntry{
+		EntityId:     session.UserId,
+		EntityType:   audit.UserEntity,
+		Verb:         audit.Delete,
+		ResourceId:   trigger.GetId(),
+		ResourceType: audit.TemplateTriggerResource,
+		Status:       audit.Success,
+		SrcIp:        &session.SourceIp,
+		SrcUa:        &session.UserAgent,
+		DstHost:      &r.Host,
+		Data: map[string]any{
+			"orgId": trigger.OrgId,
+			"name":  trigger.Name,
+		},
+	}
+	if err := trigger.DeleteV1(models.DatabaseConnection{Db: dbInstance}); err != nil {
+		if errors.Is(err, models.ErrorNotFound) {
+			common.SendHttpFailResponse(w, r, http.StatusNotFound, "trigger not found", types.ErrorNotFound)
+			return
+		}
+		auditEntry.Status = audit.Failed
+		audit.Log(auditEntry)
+		log(common.LogLevelError, fmt.Sprintf("failed to delete trigger[%s]: %s", trigger.GetId(), err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to delete trigger", types.ErrorDatabaseIssue)
+		return
+	}
+	audit.Log(auditEntry)
+	common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok")
+}
+
+// isTemplateTriggerWebhookAuthenticated returns true if the webhook
+// request carries valid credentials for the trigger; HMAC triggers
+// expect a signature of the TemplateTriggerTimestampHeader header and
+// the raw `body` joined by a `.` in the TemplateTriggerSignatureHeader
+// header with a timestamp within TemplateTriggerMaxSignatureAge of the
+// current time and org token triggers expect the token ID and API key
+// of the trigger's org token either as a `Bearer <token-id>:<api-key>`
+// authorization header or as the username and password of basic
+// authentication
+func isTemplateTriggerWebhookAuthenticated(trigger *models.TemplateTrigger, r *http.Request, body []byte) (bool, error) {
+	switch trigger.AuthType {
+	case models.TemplateTriggerAuthHmac:
+		signature, found := strings.CutPrefix(r.Header.Get(TemplateTriggerSignatureHeader), "sha256=")
+		if !found {
+			return false, nil
+		}
+		expectedSignature, err := hex.DecodeString(signature)
+		if err != nil {
+			return false, nil
+		}
+		timestamp := r.Header.Get(TemplateTriggerTimestampHeader)
+		signedAt, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return false, nil
+		}
+		if signatureAge := time.Since(time.Unix(signedAt, 0)).Abs(); signatureAge > TemplateTriggerMaxSignatureAge {
+			return false, nil
+		}
+		secret, err := trigger.GetHmacSecret()
+		if err != nil {
+			return false, err
+		}
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(timestamp + "."))
+		mac.Write(body)
+		return hmac.Equal(mac.Sum(nil), expectedSignature), nil
+	case models.TemplateTriggerAuthOrgToken:
+		tokenId, apiKey, ok := r.BasicAuth()
+		if !ok {
+			credentials, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !found {
+				return false, nil
+			}
+			tokenId, apiKey, ok = strings.Cut(credentials, ":")
+			if !ok {
+				return false, nil
+			}
+		}
+		if trigger.OrgTokenId == nil || tokenId != *trigger.OrgTokenId {
+			return false, nil
+		}
+		token, err := models.ValidateOrgTokenV1(models.ValidateOrgTokenV1Opts{
+			DatabaseConnection: models.DatabaseConnection{Db: dbInstance},
+			TokenId:            tokenId,
+			Token:              apiKey,
+		})
+		if err != nil {
+			if errors.Is(err, models.ErrorCredentialsAuthenticationFailed) {
+				return false, nil
+			}
+			return false, err
+		}
+		return trigger.OrgId != nil && token.GetOrg().GetId() == *trigger.OrgId, nil
+	}
+	return false, nil
+}
+
+type TemplateTriggerWebhookV1Output struct {
+	AutomationId string `json:"automationId,omitempty"`
+
+	// IsDuplicate is true when the webhook was dropped because another
+	// webhook with the same dedup key was received within the trigger's
+	// dedup window
+	IsDuplicate bool `json:"isDuplicate"`
+}
+
+// handleTemplateTriggerWebhookV1 runs an automation from the template of
+// the trigger identified in the request path using variables mapped from
+// the JSON payload of the webhook
+func handleTemplateTriggerWebhookV1(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+
+	triggerId := mux.Vars(r)["triggerId"]
+	if err := validate.Uuid(triggerId); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid trigger id", types.ErrorInvalidInput)
+		return
+	}
+	bodyData, err := io.ReadAll(http.MaxBytesReader(w, r.Body, templateTriggerMaxPayloadSize))
+	if err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "failed to get body data", types.ErrorInvalidInput)
+		return
+	}
+	trigger, err := models.GetTemplateTriggerV1(models.GetTemplateTriggerV1Input{
+		DatabaseConnection: models.DatabaseConnection{Db: dbInstance},
+		TriggerId:          triggerId,
+	})
+	if err != nil {
+		if errors.Is(err, models.ErrorNotFound) {
+			common.SendHttpFailResponse(w, r, http.StatusNotFound, "trigger not found", types.ErrorNotFound)
+			return
+		}
+		log(common.LogLevelError, fmt.Sprintf("failed to load trigger[%s]: %s", triggerId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to retrieve trigger", types.ErrorDatabaseIssue)
+		return
+	}
+	if isAuthenticated, err := isTemplateTriggerWebhookAuthenticated(trigger, r, bodyData); err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to authenticate webhook for trigger[%s]: %s", triggerId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to authenticate webhook", types.ErrorGeneric)
+		return
+	} else if !isAuthenticated {
+		log(common.LogLevelWarn, fmt.Sprintf("received unauthenticated webhook for trigger[%s] from %s", triggerId, r.RemoteAddr))
+		common.SendHttpFailResponse(w, r, http.StatusUnauthorized, "invalid webhook credentials", types.ErrorInvalidCredentials)
+		return
+	}
+
+	var payload any
+	if len(bodyData) > 0 {
+		if err := json.Unmarshal(bodyData, &payload); err != nil {
+			common.SendHttpFailResponse(w, r, http.StatusBadRequest, "failed to parse body data", types.ErrorInvalidInput)
+			return
+		}
+	}
+	variableMap, err := trigger.VariableMapping.Resolve(payload)
+	if err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, err.Error(), types.ErrorInvalidInput)
+		return
+	}
+
+	dedupCacheKey := ""
+	if trigger.DedupKey != nil {
+		dedupKey, err := automations.EvaluatePayloadExpression(*trigger.DedupKey, payload)
+		if err != nil {
+			common.SendHttpFailResponse(w, r, http.StatusBadRequest, fmt.Sprintf("failed to evaluate dedup key: %s", err), types.ErrorInvalidInput)
+			return
+		}
+		if dedupKey != nil && fmt.Sprint(dedupKey) != "" {
+			dedupKeyHash := sha256.Sum256([]byte(fmt.Sprint(dedupKey)))
+			dedupCacheKey = fmt.Sprintf("trigger:%s:dedup:%s", trigger.GetId(), hex.EncodeToString(dedupKeyHash[:]))
+			isSet, err := cacheInstance.SetIfNotExists(dedupCacheKey, time.Now().Format(time.RFC3339), trigger.DedupWindow)
+			if err != nil {
+				log(common.LogLevelError, fmt.Sprintf("failed to set dedup key of trigger[%s]: %s", trigger.GetId(), err))
+				common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to check for duplicates", types.ErrorGeneric)
+				return
+			} else if !isSet {
+				log(common.LogLevelInfo, fmt.Sprintf("dropped duplicate webhook for trigger[%s]", trigger.GetId()))
+				common.SendHttpSuccessResponse(w, r, http.StatusOK, "duplicate", TemplateTriggerWebhookV1Output{IsDuplicate: true})
+				return
+			}
+		}
+	}
+	releaseDedupKey := func() {
+		if dedupCacheKey == "" {
+			return
+		}
+		if err := cacheInstance.Del(dedupCacheKey); err != nil {
+			log(common.LogLevelWarn, fmt.Sprintf("failed to release dedup key of trigger[%s]: %s", trigger.GetId(), err))
+		}
+	}
+
+	if trigger.RateLimit > 0 {
+		count, err := cacheInstance.Increment(fmt.Sprintf("trigger:%s:rate", trigger.GetId()), trigger.RateLimitWindow)
+		if err != nil {
+			releaseDedupKey()
+			log(common.LogLevelError, fmt.Sprintf("failed to check rate limit of trigger[%s]: %s", trigger.GetId(), err))
+			common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to check rate limit", types.ErrorGeneric)
+			return
+		} else if count > int64(trigger.RateLimit) {
+			releaseDedupKey()
+			log(common.LogLevelWarn, fmt.Sprintf("trigger[%s] exceeded its rate limit of %v per %s", trigger.GetId(), trigger.RateLimit, trigger.RateLimitWindow))
+			common.SendHttpFailResponse(w, r, http.StatusTooManyRequests, "rate limit exceeded", types.ErrorRateLimited)
+			return
+		}
+	}
+
+	automationId, err := runTemplateAsUser(runTemplateAsUserInput{
+		UserId:          trigger.GetOwnerId(),
+		OrgId:           trigger.OrgId,
+		TemplateId:      trigger.TemplateId,
+		TemplateVersion: trigger.TemplateVersion,
+		VariableMap:     variableMap,
+		Comment:         fmt.Sprintf("triggered by webhook of trigger[%s]", trigger.Name),
+		AuditData: map[string]any{
+			"triggerId": trigger.GetId(),
+			"sourceIp":  r.RemoteAddr,
+		},
+	})
+	if err != nil {
+		releaseDedupKey()
+		log(common.LogLevelError, fmt.Sprintf("failed to trigger automation from trigger[%s]: %s", trigger.GetId(), err))
+		switch true {
+		case errors.Is(err, types.ErrorInvalidInput):
+			common.SendHttpFailResponse(w, r, http.StatusBadRequest, err.Error(), types.ErrorInvalidInput)
+		case errors.Is(err, types.ErrorInsufficientPermissions):
+			common.SendHttpFailResponse(w, r, http.StatusForbidden, "not allowed", types.ErrorInsufficientPermissions)
+		default:
+			common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to trigger automation", types.ErrorGeneric)
+		}
+		return
+	}
+	if err := trigger.SetLastAutomationV1(models.SetTemplateTriggerLastAutomationV1Input{
+		DatabaseConnection: models.DatabaseConnection{Db: dbInstance},
+		AutomationId:       automationId,
+		TriggeredAt:        time.Now(),
+	}); err != nil {
+		log(common.LogLevelWarn, fmt.Sprintf("failed to record automation[%s] on trigger[%s]: %s", automationId, trigger.GetId(), err))
+	}
+	log(common.LogLevelInfo, fmt.Sprintf("trigger[%s] triggered automation[%s]", trigger.GetId(), automationId))
+	common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", TemplateTriggerWebhookV1Output{AutomationId: automationId})
+}
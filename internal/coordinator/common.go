@@ -8,6 +8,7 @@ import (
 )
 
 var apiKeys []string
+var controllerApiKey string
 var controllerUrl *url.URL
 var cacheInstance cache.Cache
 var queueInstance queue.Instance
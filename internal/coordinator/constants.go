@@ -0,0 +1,23 @@
+package coordinator
+
+import "time"
+
+const (
+	// DefaultJobWaitDuration is how long a request for a job is held
+	// open when the worker does not specify a duration
+	DefaultJobWaitDuration = 20 * time.Second
+
+	// MaxJobWaitDuration is the maximum duration a request for a job
+	// can be held open for, this must be lower than the server's write
+	// timeout
+	MaxJobWaitDuration = 25 * time.Second
+
+	// DefaultCertificateIdentityTtl is how long the verification of a
+	// client certificate by the controller is cached for, revoking a
+	// token takes effect for connected workers within this duration
+	DefaultCertificateIdentityTtl = time.Minute
+
+	// DefaultServerWriteTimeout is the write timeout to use for the
+	// coordinator's http server so that long-polling is possible
+	DefaultServerWriteTimeout = MaxJobWaitDuration + 5*time.Second
+)
@@ -0,0 +1,34 @@
+package coordinator
+
+import (
+	"fmt"
+	"opsicle/pkg/controller"
+	"sync"
+)
+
+var controllerClient *controller.Client
+var controllerClientLock sync.Mutex
+
+// getControllerClient returns a client for the controller's internal
+// endpoints, the client is created on first use so that the coordinator
+// can start before the controller is reachable
+func getControllerClient() (*controller.Client, error) {
+	controllerClientLock.Lock()
+	defer controllerClientLock.Unlock()
+	if controllerClient != nil {
+		return controllerClient, nil
+	}
+	if controllerUrl == nil {
+		return nil, fmt.Errorf("failed to receive a controller url")
+	}
+	client, err := controller.NewClient(controller.NewClientOpts{
+		ApiKey:        controllerApiKey,
+		ControllerUrl: controllerUrl.String(),
+		Id:            "opsicle/coordinator",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create controller client: %w", err)
+	}
+	controllerClient = client
+	return controllerClient, nil
+}
@@ -22,9 +22,6 @@ type HttpApplicationOpts struct {
 	LivenessChecks  []func() error
 	ReadinessChecks []func() error
 
-	CacheConnection *persistence.Redis
-	QueueConnection *persistence.Nats
-
 	ServiceLogs chan<- common.ServiceLog
 }
 
@@ -60,17 +57,18 @@ func GetHttpApplication(opts HttpApplicationOpts) (http.Handler, error) {
 	*serviceLogs <- common.ServiceLogf(common.LogLevelInfo, "controller has %v api key(s) registered", len(apiKeys))
 
 	cache.InitRedis(cache.InitRedisOpts{
-		RedisConnection: opts.CacheConnection,
+		RedisConnection: opts.Cache,
 		ServiceLogs:     *serviceLogs,
 	})
 	cacheInstance = cache.Get()
 
 	queue.InitNats(queue.InitNatsOpts{
-		NatsConnection: opts.QueueConnection,
+		NatsConnection: opts.Queue,
 		ServiceLogs:    *serviceLogs,
 	})
 	queueInstance = queue.Get()
 
+	controllerApiKey = opts.ControllerApiKey
 	var err error
 	controllerUrl, err = url.Parse(opts.ControllerUrl)
 	if err != nil {
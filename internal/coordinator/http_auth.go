@@ -2,10 +2,16 @@ package coordinator
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
 	"net/http"
 	"opsicle/internal/common"
 	"opsicle/internal/coordinator/models"
 	"opsicle/internal/types"
+	"opsicle/internal/validate"
+	"opsicle/pkg/controller"
 	"strings"
 )
 
@@ -20,6 +26,9 @@ type identity struct {
 
 	// OrgId is the ID of the current caller
 	OrgId string `json:"orgId"`
+
+	// TokenId is the ID of the org token used by the current caller
+	TokenId string `json:"tokenId"`
 }
 
 func getRouteAuther(serviceLogs chan<- common.ServiceLog) func(http.Handler) http.Handler {
@@ -27,6 +36,17 @@ func getRouteAuther(serviceLogs chan<- common.ServiceLog) func(http.Handler) htt
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
 			serviceLogs <- common.ServiceLogf(common.LogLevelTrace, "auth middleware is executing")
+			certIdentity, err := getCertificateIdentity(r)
+			if err != nil {
+				serviceLogs <- common.ServiceLogf(common.LogLevelWarn, "rejected client certificate from %s: %s", r.RemoteAddr, err)
+				common.SendHttpFailResponse(w, r, http.StatusUnauthorized, "invalid client certificate", types.ErrorInvalidCredentials)
+				return
+			} else if certIdentity != nil {
+				serviceLogs <- common.ServiceLogf(common.LogLevelTrace, "authenticated org[%s] using client certificate of token[%s]", certIdentity.OrgId, certIdentity.TokenId)
+				authContext := context.WithValue(r.Context(), authRequestContext, *certIdentity)
+				next.ServeHTTP(w, r.WithContext(authContext))
+				return
+			}
 			authorizationHeader := r.Header.Get("Authorization")
 			if strings.Index(authorizationHeader, "Bearer ") != 0 {
 				common.SendHttpFailResponse(w, r, http.StatusUnauthorized, "failed to receive an authorization header", types.ErrorAuthRequired)
@@ -35,8 +55,9 @@ func getRouteAuther(serviceLogs chan<- common.ServiceLog) func(http.Handler) htt
 			authorizationToken := strings.ReplaceAll(authorizationHeader, "Bearer ", "")
 			authorizationTokenId := r.Header.Get("X-Token-Id")
 			org, err := models.AuthOrgTokenV1(models.AuthOrgTokenV1Opts{
-				TokenId: authorizationTokenId,
-				Token:   authorizationToken,
+				CacheConnection: models.CacheConnection{Cache: cacheInstance},
+				TokenId:         authorizationTokenId,
+				Token:           authorizationToken,
 			})
 			if err != nil {
 				common.SendHttpFailResponse(w, r, http.StatusUnauthorized, "failed to retrieve session", types.ErrorAuthRequired)
@@ -45,6 +66,7 @@ func getRouteAuther(serviceLogs chan<- common.ServiceLog) func(http.Handler) htt
 			identityInstance := identity{
 				SourceIp:  r.RemoteAddr,
 				OrgId:     org.Id,
+				TokenId:   authorizationTokenId,
 				UserAgent: r.UserAgent(),
 			}
 			authContext := context.WithValue(r.Context(), authRequestContext, identityInstance)
@@ -52,3 +74,68 @@ func getRouteAuther(serviceLogs chan<- common.ServiceLog) func(http.Handler) htt
 		})
 	}
 }
+
+// getCertificateIdentity returns the identity of the caller if the caller
+// presented a client certificate which was verified against the configured
+// client certificate authorities, nil is returned when no org token
+// certificate was presented. Org token certificates are issued with the org ID as the
+// Organization (O) and the token ID as the first OrganizationalUnit (OU),
+// these are only trusted once the controller confirms that the token
+// exists and that the certificate authority which verified the chain is
+// the one of the org
+func getCertificateIdentity(r *http.Request) (*identity, error) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil, nil
+	}
+	clientCertificate := r.TLS.VerifiedChains[0][0]
+	if len(clientCertificate.Subject.Organization) == 0 || len(clientCertificate.Subject.OrganizationalUnit) == 0 {
+		return nil, nil
+	}
+	orgId := clientCertificate.Subject.Organization[0]
+	tokenId := clientCertificate.Subject.OrganizationalUnit[0]
+	if validate.Uuid(orgId) != nil || validate.Uuid(tokenId) != nil {
+		return nil, nil
+	}
+	var verifyErr error
+	for _, chain := range r.TLS.VerifiedChains {
+		if len(chain) == 0 || !chain[0].Equal(clientCertificate) {
+			continue
+		}
+		if verifyErr = verifyCertificateIdentity(orgId, tokenId, chain[len(chain)-1].Raw); verifyErr == nil {
+			return &identity{
+				SourceIp:  r.RemoteAddr,
+				OrgId:     orgId,
+				TokenId:   tokenId,
+				UserAgent: r.UserAgent(),
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("failed to verify token[%s] of org[%s]: %w", tokenId, orgId, verifyErr)
+}
+
+// verifyCertificateIdentity verifies with the controller that the token
+// exists in the org and that `caCertificate` is the certificate authority
+// of the org, successful verifications are cached for
+// DefaultCertificateIdentityTtl
+func verifyCertificateIdentity(orgId, tokenId string, caCertificate []byte) error {
+	caHash := sha256.Sum256(caCertificate)
+	cacheKey := strings.Join([]string{"org-token-certificate", orgId, tokenId, hex.EncodeToString(caHash[:])}, ":")
+	if _, err := cacheInstance.Get(cacheKey); err == nil {
+		return nil
+	}
+	client, err := getControllerClient()
+	if err != nil {
+		return fmt.Errorf("failed to connect to controller: %w", err)
+	}
+	if _, err := client.VerifyOrgTokenCertificateV1(controller.VerifyOrgTokenCertificateV1Input{
+		OrgId:            orgId,
+		TokenId:          tokenId,
+		CaCertificateB64: base64.StdEncoding.EncodeToString(caCertificate),
+	}); err != nil {
+		return err
+	}
+	if err := cacheInstance.Set(cacheKey, orgId, DefaultCertificateIdentityTtl); err != nil {
+		*serviceLogs <- common.ServiceLogf(common.LogLevelWarn, "failed to cache verification of token[%s] of org[%s]: %s", tokenId, orgId, err)
+	}
+	return nil
+}
@@ -1,19 +1,497 @@
 package coordinator
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"opsicle/internal/automations"
 	"opsicle/internal/common"
+	"opsicle/internal/coordinator/models"
+	"opsicle/internal/queue"
+	"opsicle/internal/types"
+	"opsicle/internal/validate"
+	"opsicle/pkg/controller"
+	"time"
+
+	"github.com/gorilla/mux"
 )
 
 func registerJobsRoutes(opts RouteRegistrationOpts) {
 	requiresAuth := getRouteAuther(opts.ServiceLogs)
 	v1 := opts.Router.PathPrefix("/v1/jobs").Subrouter()
 	v1.Handle("", requiresAuth(http.HandlerFunc(handleGetJobV1))).Methods(http.MethodGet)
+	v1.Handle("/{automationId}/artifacts", requiresAuth(http.HandlerFunc(handlePushJobArtifactsV1))).Methods(http.MethodPost)
+	v1.Handle("/{automationId}/cancellation", requiresAuth(http.HandlerFunc(handleGetJobCancellationV1))).Methods(http.MethodGet)
+	v1.Handle("/{automationId}/logs", requiresAuth(http.HandlerFunc(handlePushJobLogsV1))).Methods(http.MethodPost)
+	v1.Handle("/{automationId}/status", requiresAuth(http.HandlerFunc(handleUpdateJobStatusV1))).Methods(http.MethodPost)
+}
+
+type GetJobV1Output struct {
+	IsAvailable bool                    `json:"isAvailable"`
+	Automation  *automations.Automation `json:"automation"`
 }
 
+// handleGetJobV1 holds the request open until an automation run for the
+// caller's org is available or until the wait duration elapses. The wait
+// duration can be specified using the `wait` query parameter as a Go
+// duration string (eg. `15s`)
 func handleGetJobV1(w http.ResponseWriter, r *http.Request) {
-	// log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
-	// session := r.Context().Value(authRequestContext).(identity)
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+	session := r.Context().Value(authRequestContext).(identity)
+
+	waitDuration := DefaultJobWaitDuration
+	if waitInput := r.URL.Query().Get("wait"); waitInput != "" {
+		parsedWaitDuration, err := time.ParseDuration(waitInput)
+		if err != nil || parsedWaitDuration < 0 {
+			common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid wait duration", types.ErrorInvalidInput)
+			return
+		}
+		waitDuration = min(parsedWaitDuration, MaxJobWaitDuration)
+	}
+
+	workerId := r.URL.Query().Get("workerId")
+	if workerId != "" {
+		if worker, err := models.GetWorkerV1(models.GetWorkerV1Opts{
+			CacheConnection: models.CacheConnection{Cache: cacheInstance},
+			OrgId:           session.OrgId,
+			WorkerId:        workerId,
+		}); err == nil {
+			if err := worker.TouchV1(models.CacheConnection{Cache: cacheInstance}); err != nil {
+				log(common.LogLevelWarn, fmt.Sprintf("failed to update last seen of worker[%s]: %s", workerId, err))
+			}
+		}
+	}
+
+	var automation *automations.Automation
+	deadline := time.Now().Add(waitDuration)
+	for {
+		job, err := models.PopJobV1(models.PopJobV1Opts{
+			QueueConnection: models.QueueConnection{Queue: queueInstance},
+			Context:         r.Context(),
+			OrgId:           session.OrgId,
+			Wait:            time.Until(deadline),
+		})
+		if err != nil {
+			log(common.LogLevelError, fmt.Sprintf("failed to retrieve job for org[%s]: %s", session.OrgId, err))
+			common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to retrieve job", types.ErrorQueueIssue)
+			return
+		}
+		if job == nil {
+			break
+		}
+		poppedAutomationId := job.Automation.Spec.Status.Id
+		if isJobCancelled(r.Context(), job.Automation, session.OrgId) {
+			log(common.LogLevelInfo, fmt.Sprintf("dropping automation[%s] of org[%s] as it was cancelled while queued", poppedAutomationId, session.OrgId))
+			if err := job.AckV1(); err != nil {
+				log(common.LogLevelWarn, fmt.Sprintf("failed to remove automation[%s] of org[%s] from the queue: %s", poppedAutomationId, session.OrgId, err))
+			}
+			continue
+		}
+		isAllowed, err := acquireJobConcurrency(job, session.OrgId)
+		if err != nil {
+			log(common.LogLevelError, fmt.Sprintf("failed to apply concurrency of automation[%s] of org[%s]: %s", poppedAutomationId, session.OrgId, err))
+			common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to retrieve job", types.ErrorQueueIssue)
+			return
+		}
+		if !isAllowed {
+			if time.Now().After(deadline) {
+				break
+			}
+			continue
+		}
+		// jobs which fail to be removed from the queue are redelivered
+		// later so they are not handed to a worker now
+		if err := job.AckV1(); err != nil {
+			log(common.LogLevelError, fmt.Sprintf("failed to remove automation[%s] of org[%s] from the queue: %s", poppedAutomationId, session.OrgId, err))
+			releaseJobConcurrency(poppedAutomationId, session.OrgId)
+			common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to retrieve job", types.ErrorQueueIssue)
+			return
+		}
+		automation = job.Automation
+		break
+	}
+	if automation != nil {
+		if err := resolveJobSecrets(automation, session.OrgId); err != nil {
+			automationId := automation.Spec.Status.Id
+			log(common.LogLevelError, fmt.Sprintf("failed to resolve secrets of automation[%s] of org[%s]: %s", automationId, session.OrgId, err))
+			failAutomation(automationId, session.OrgId, fmt.Sprintf("failed to resolve secrets: %s", err))
+			automation = nil
+		}
+	}
+	output := GetJobV1Output{
+		IsAvailable: automation != nil,
+		Automation:  automation,
+	}
+	if automation != nil {
+		log(common.LogLevelInfo, fmt.Sprintf("handing automation[%s] of org[%s] to worker[%s]", automation.Spec.Status.Id, session.OrgId, workerId))
+	}
+	common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", output)
+}
+
+// isJobCancelled returns true if the automation run was cancelled while
+// it was queued; the controller marks such runs as cancelled so they are
+// dropped instead of being handed to a worker
+func isJobCancelled(ctx context.Context, automation *automations.Automation, orgId string) bool {
+	automationId := automation.Spec.Status.Id
+	cancellation, err := models.PopJobCancellationV1(models.PopJobCancellationV1Opts{
+		QueueConnection: models.QueueConnection{Queue: queueInstance},
+		AutomationId:    automationId,
+		Context:         ctx,
+		OrgId:           orgId,
+	})
+	if err != nil {
+		*serviceLogs <- common.ServiceLogf(common.LogLevelWarn, "failed to check for cancellation of automation[%s] of org[%s]: %s", automationId, orgId, err)
+		return false
+	}
+	return cancellation != nil
+}
+
+// acquireJobConcurrency acquires a concurrency slot for the automation
+// run if its template limits parallel runs and returns true if the run
+// can be handed to a worker; runs which cannot are handled according to
+// their concurrency policy and are either removed from or returned to
+// the queue
+func acquireJobConcurrency(job *models.Job, orgId string) (bool, error) {
+	automation := job.Automation
+	concurrency := automation.Spec.Concurrency
+	if concurrency == nil {
+		return true, nil
+	}
+	automationId := automation.Spec.Status.Id
+	slot, err := models.AcquireConcurrencySlotV1(models.AcquireConcurrencySlotV1Opts{
+		CacheConnection: models.CacheConnection{Cache: cacheInstance},
+		AutomationId:    automationId,
+		Concurrency:     *concurrency,
+		OrgId:           orgId,
+	})
+	if err != nil {
+		if requeueErr := job.RequeueV1(models.DefaultJobRequeueDelay); requeueErr != nil {
+			*serviceLogs <- common.ServiceLogf(common.LogLevelError, "failed to requeue automation[%s] of org[%s]: %s", automationId, orgId, requeueErr)
+		}
+		return false, err
+	}
+	if slot.IsAcquired {
+		return true, nil
+	}
+
+	switch concurrency.GetPolicy() {
+	case automations.ConcurrencyPolicyReject:
+		*serviceLogs <- common.ServiceLogf(common.LogLevelInfo, "rejecting automation[%s] of org[%s] as concurrency key[%s] is held by %v", automationId, orgId, concurrency.Key, slot.HolderIds)
+		updateAutomationStatus(automationId, orgId, automations.RunStatusRejected, fmt.Sprintf("rejected as concurrency key[%s] is held by automation(s) %v", concurrency.Key, slot.HolderIds))
+		if err := job.AckV1(); err != nil {
+			return false, err
+		}
+		return false, nil
+	case automations.ConcurrencyPolicyCancelPrevious:
+		for _, holderId := range slot.HolderIds {
+			isFirst, err := models.SupersedeConcurrencyHolderV1(models.SupersedeConcurrencyHolderV1Opts{
+				CacheConnection: models.CacheConnection{Cache: cacheInstance},
+				AutomationId:    holderId,
+				OrgId:           orgId,
+				SupersededBy:    automationId,
+			})
+			if err != nil {
+				*serviceLogs <- common.ServiceLogf(common.LogLevelError, "failed to supersede automation[%s] of org[%s]: %s", holderId, orgId, err)
+				continue
+			} else if !isFirst {
+				continue
+			}
+			if err := cancelAutomation(holderId, orgId, fmt.Sprintf("superseded by automation[%s] sharing concurrency key[%s]", automationId, concurrency.Key)); err != nil {
+				*serviceLogs <- common.ServiceLogf(common.LogLevelError, "failed to cancel automation[%s] of org[%s]: %s", holderId, orgId, err)
+				continue
+			}
+			*serviceLogs <- common.ServiceLogf(common.LogLevelInfo, "cancelled automation[%s] of org[%s] in favour of automation[%s]", holderId, orgId, automationId)
+		}
+	}
+	if err := job.RequeueV1(models.DefaultJobRequeueDelay); err != nil {
+		return false, err
+	}
+	*serviceLogs <- common.ServiceLogf(common.LogLevelDebug, "requeued automation[%s] of org[%s] for %v until concurrency key[%s] is released", automationId, orgId, models.DefaultJobRequeueDelay, concurrency.Key)
+	return false, nil
+}
+
+// cancelAutomation publishes a cancellation of the automation run
+// identified by `automationId` to the worker processing it
+func cancelAutomation(automationId, orgId, reason string) error {
+	cancellationData, err := json.Marshal(automations.Cancellation{
+		AutomationId: automationId,
+		OrgId:        &orgId,
+		Reason:       reason,
+		Timestamp:    time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal cancellation: %w", err)
+	}
+	if _, err := queueInstance.Push(queue.PushOpts{
+		Data:   cancellationData,
+		Queue:  automations.GetCancellationsQueue(automationId),
+		Stream: automations.GetCancellationsStreamOpts(),
+	}); err != nil {
+		return fmt.Errorf("failed to publish cancellation: %w", err)
+	}
+	return nil
+}
+
+// failAutomation reports the automation identified by `automationId` as
+// failed to the controller; this is used when the coordinator is unable
+// to hand the automation over to a worker
+func failAutomation(automationId, orgId, message string) {
+	releaseJobConcurrency(automationId, orgId)
+	updateAutomationStatus(automationId, orgId, automations.RunStatusCompletedFailed, message)
+}
+
+// releaseJobConcurrency frees the concurrency slot held by the
+// automation run if any so that queued runs sharing its concurrency key
+// can proceed
+func releaseJobConcurrency(automationId, orgId string) {
+	if err := models.ReleaseConcurrencySlotV1(models.ReleaseConcurrencySlotV1Opts{
+		CacheConnection: models.CacheConnection{Cache: cacheInstance},
+		AutomationId:    automationId,
+		OrgId:           orgId,
+	}); err != nil {
+		*serviceLogs <- common.ServiceLogf(common.LogLevelError, "failed to release concurrency slot of automation[%s] of org[%s]: %s", automationId, orgId, err)
+	}
+}
+
+// updateAutomationStatus reports a final status of the automation
+// identified by `automationId` to the controller on behalf of the
+// coordinator
+func updateAutomationStatus(automationId, orgId string, status automations.RunStatusCode, message string) {
+	client, err := getControllerClient()
+	if err != nil {
+		*serviceLogs <- common.ServiceLogf(common.LogLevelError, "failed to connect to controller: %s", err)
+		return
+	}
+	exitCode := 1
+	if _, err := client.UpdateAutomationStatusV1(controller.UpdateAutomationStatusV1Input{
+		AutomationId: automationId,
+		OrgId:        &orgId,
+		Update: automations.RunStatusUpdate{
+			AutomationId: automationId,
+			Status:       status,
+			ExitCode:     &exitCode,
+			Message:      message,
+			Timestamp:    time.Now(),
+		},
+	}); err != nil {
+		*serviceLogs <- common.ServiceLogf(common.LogLevelError, "failed to report status[%s] of automation[%s] of org[%s]: %s", status, automationId, orgId, err)
+	}
+}
 
+type GetJobCancellationV1Output struct {
+	IsCancelled  bool                      `json:"isCancelled"`
+	Cancellation *automations.Cancellation `json:"cancellation"`
+}
+
+// handleGetJobCancellationV1 holds the request open until the automation
+// run is cancelled or until the wait duration elapses; workers call this
+// repeatedly while processing a run so that cancellations published by
+// the controller reach them. The wait duration can be specified using
+// the `wait` query parameter as a Go duration string (eg. `15s`)
+func handleGetJobCancellationV1(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+	session := r.Context().Value(authRequestContext).(identity)
+
+	automationId := mux.Vars(r)["automationId"]
+	if err := validate.Uuid(automationId); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid automation id", types.ErrorInvalidInput)
+		return
+	}
+	waitDuration := DefaultJobWaitDuration
+	if waitInput := r.URL.Query().Get("wait"); waitInput != "" {
+		parsedWaitDuration, err := time.ParseDuration(waitInput)
+		if err != nil || parsedWaitDuration < 0 {
+			common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid wait duration", types.ErrorInvalidInput)
+			return
+		}
+		waitDuration = min(parsedWaitDuration, MaxJobWaitDuration)
+	}
+
+	if err := models.RenewConcurrencySlotV1(models.RenewConcurrencySlotV1Opts{
+		CacheConnection: models.CacheConnection{Cache: cacheInstance},
+		AutomationId:    automationId,
+		OrgId:           session.OrgId,
+	}); err != nil {
+		log(common.LogLevelWarn, fmt.Sprintf("failed to renew concurrency slot of automation[%s] of org[%s]: %s", automationId, session.OrgId, err))
+	}
+
+	cancellation, err := models.PopJobCancellationV1(models.PopJobCancellationV1Opts{
+		QueueConnection: models.QueueConnection{Queue: queueInstance},
+		AutomationId:    automationId,
+		Context:         r.Context(),
+		OrgId:           session.OrgId,
+		Wait:            waitDuration,
+	})
+	if err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to retrieve cancellation of automation[%s] of org[%s]: %s", automationId, session.OrgId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to retrieve cancellation", types.ErrorQueueIssue)
+		return
+	}
+	if cancellation != nil {
+		log(common.LogLevelInfo, fmt.Sprintf("handing cancellation of automation[%s] of org[%s] to worker", automationId, session.OrgId))
+	}
+	common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", GetJobCancellationV1Output{
+		IsCancelled:  cancellation != nil,
+		Cancellation: cancellation,
+	})
+}
+
+type UpdateJobStatusV1Input automations.RunStatusUpdate
+
+// handleUpdateJobStatusV1 receives status updates from workers and
+// forwards them to the controller scoped to the caller's org
+func handleUpdateJobStatusV1(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+	session := r.Context().Value(authRequestContext).(identity)
+
+	automationId := mux.Vars(r)["automationId"]
+	if err := validate.Uuid(automationId); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid automation id", types.ErrorInvalidInput)
+		return
+	}
+	bodyData, err := io.ReadAll(r.Body)
+	if err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "failed to get body data", types.ErrorInvalidInput)
+		return
+	}
+	var input UpdateJobStatusV1Input
+	if err := json.Unmarshal(bodyData, &input); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "failed to parse body data", types.ErrorInvalidInput)
+		return
+	}
+	input.AutomationId = automationId
+
+	client, err := getControllerClient()
+	if err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to connect to controller: %s", err))
+		common.SendHttpFailResponse(w, r, http.StatusBadGateway, "failed to connect to controller", types.ErrorControllerIssue)
+		return
+	}
+	orgId := session.OrgId
+	output, err := client.UpdateAutomationStatusV1(controller.UpdateAutomationStatusV1Input{
+		AutomationId: automationId,
+		OrgId:        &orgId,
+		Update:       automations.RunStatusUpdate(input),
+	})
+	if err != nil {
+		statusCode := http.StatusBadGateway
+		if output != nil && output.StatusCode >= 400 && output.StatusCode < 500 {
+			statusCode = output.StatusCode
+		}
+		log(common.LogLevelError, fmt.Sprintf("failed to update status of automation[%s] of org[%s]: %s", automationId, orgId, err))
+		common.SendHttpFailResponse(w, r, statusCode, "failed to update automation status", types.ErrorControllerIssue)
+		return
+	}
+	if input.Status.IsFinal() {
+		releaseJobConcurrency(automationId, orgId)
+	}
+	log(common.LogLevelDebug, fmt.Sprintf("automation[%s] of org[%s] is now in status[%s]", automationId, orgId, output.Data.Status))
+	common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", output.Data)
+}
+
+type PushJobLogsV1Input automations.LogChunk
+
+// handlePushJobLogsV1 receives logs from workers and publishes them to
+// the automation run's logs queue scoped to the caller's org
+func handlePushJobLogsV1(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+	session := r.Context().Value(authRequestContext).(identity)
+
+	automationId := mux.Vars(r)["automationId"]
+	if err := validate.Uuid(automationId); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid automation id", types.ErrorInvalidInput)
+		return
+	}
+	bodyData, err := io.ReadAll(r.Body)
+	if err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "failed to get body data", types.ErrorInvalidInput)
+		return
+	}
+	var input PushJobLogsV1Input
+	if err := json.Unmarshal(bodyData, &input); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "failed to parse body data", types.ErrorInvalidInput)
+		return
+	}
+	if input.Data == "" {
+		common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok")
+		return
+	}
+	orgId := session.OrgId
+	input.AutomationId = automationId
+	input.OrgId = &orgId
+	if input.Timestamp.IsZero() {
+		input.Timestamp = time.Now()
+	}
+	logChunk, err := json.Marshal(automations.LogChunk(input))
+	if err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "failed to process logs", types.ErrorInvalidInput)
+		return
+	}
+	if _, err := queueInstance.Push(queue.PushOpts{
+		Data:   logChunk,
+		Queue:  automations.GetLogsQueue(automationId),
+		Stream: automations.GetLogsStreamOpts(),
+	}); err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to publish logs of automation[%s] of org[%s]: %s", automationId, orgId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to publish logs", types.ErrorQueueIssue)
+		return
+	}
 	common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok")
 }
+
+type PushJobArtifactsV1Input struct {
+	Content []byte `json:"content"`
+}
+
+// handlePushJobArtifactsV1 receives the artifacts collected by workers
+// and forwards them to the controller scoped to the caller's org
+func handlePushJobArtifactsV1(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+	session := r.Context().Value(authRequestContext).(identity)
+
+	automationId := mux.Vars(r)["automationId"]
+	if err := validate.Uuid(automationId); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid automation id", types.ErrorInvalidInput)
+		return
+	}
+	bodyData, err := io.ReadAll(http.MaxBytesReader(w, r.Body, automations.MaxArtifactsRequestSize))
+	if err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusRequestEntityTooLarge, "failed to get body data", types.ErrorInvalidInput)
+		return
+	}
+	var input PushJobArtifactsV1Input
+	if err := json.Unmarshal(bodyData, &input); err != nil {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "failed to parse body data", types.ErrorInvalidInput)
+		return
+	}
+	if len(input.Content) == 0 || len(input.Content) > automations.MaxArtifactsSize {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "invalid artifacts size", types.ErrorInvalidInput)
+		return
+	}
+
+	client, err := getControllerClient()
+	if err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to connect to controller: %s", err))
+		common.SendHttpFailResponse(w, r, http.StatusBadGateway, "failed to connect to controller", types.ErrorControllerIssue)
+		return
+	}
+	orgId := session.OrgId
+	output, err := client.UploadAutomationArtifactsV1(controller.UploadAutomationArtifactsV1Input{
+		AutomationId: automationId,
+		OrgId:        &orgId,
+		Content:      input.Content,
+	})
+	if err != nil {
+		statusCode := http.StatusBadGateway
+		if output != nil && output.StatusCode >= 400 && output.StatusCode < 500 {
+			statusCode = output.StatusCode
+		}
+		log(common.LogLevelError, fmt.Sprintf("failed to upload artifacts of automation[%s] of org[%s]: %s", automationId, orgId, err))
+		common.SendHttpFailResponse(w, r, statusCode, "failed to upload artifacts", types.ErrorControllerIssue)
+		return
+	}
+	log(common.LogLevelDebug, fmt.Sprintf("uploaded %v bytes of artifacts of automation[%s] of org[%s]", output.Data.Size, automationId, orgId))
+	common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", output.Data)
+}
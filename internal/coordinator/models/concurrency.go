@@ -0,0 +1,189 @@
+package models
+
+import (
+	"fmt"
+	"opsicle/internal/automations"
+	"opsicle/internal/cache"
+	"opsicle/internal/integrations/redis"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	cachePrefixConcurrencyHolder     = "concurrency-holder"
+	cachePrefixConcurrencySlot       = "concurrency-slot"
+	cachePrefixConcurrencySuperseded = "concurrency-superseded"
+
+	// DefaultConcurrencySlotTtl is how long a concurrency slot is held
+	// for since it was last renewed, slots are renewed whenever the
+	// worker processing the run checks for cancellations so that slots
+	// held by crashed workers are eventually freed
+	DefaultConcurrencySlotTtl = 90 * time.Second
+
+	// concurrencyHolderTtl is how long the record of the slot held by a
+	// run is kept for since it was last renewed, this outlives the slot
+	// so that a run renewing a slot which expired finds out whether the
+	// slot was taken by another run instead of assuming it holds none
+	concurrencyHolderTtl = 10 * DefaultConcurrencySlotTtl
+)
+
+func getConcurrencyHolderCacheKey(orgId, automationId string) string {
+	return strings.Join([]string{cachePrefixConcurrencyHolder, orgId, automationId}, ":")
+}
+
+func getConcurrencySlotCacheKey(orgId, key string, slot int) string {
+	return strings.Join([]string{cachePrefixConcurrencySlot, orgId, key, strconv.Itoa(slot)}, ":")
+}
+
+func getConcurrencySupersededCacheKey(orgId, automationId string) string {
+	return strings.Join([]string{cachePrefixConcurrencySuperseded, orgId, automationId}, ":")
+}
+
+type AcquireConcurrencySlotV1Opts struct {
+	CacheConnection
+
+	// AutomationId is the ID of the automation run acquiring the slot
+	AutomationId string
+
+	// Concurrency is the rendered concurrency spec of the run
+	Concurrency automations.ConcurrencySpec
+
+	// OrgId is the ID of the org which the automation run belongs to
+	OrgId string
+}
+
+type AcquireConcurrencySlotV1Output struct {
+	// IsAcquired indicates whether the run can proceed
+	IsAcquired bool
+
+	// HolderIds are the IDs of the automation runs holding the slots
+	// of the concurrency key when the slot was not acquired
+	HolderIds []string
+}
+
+// AcquireConcurrencySlotV1 attempts to acquire one of the
+// `.Concurrency.MaxParallel` slots of the concurrency key on behalf of
+// the automation run identified by `.AutomationId`
+func AcquireConcurrencySlotV1(opts AcquireConcurrencySlotV1Opts) (*AcquireConcurrencySlotV1Output, error) {
+	if opts.Concurrency.Key == "" {
+		return nil, fmt.Errorf("models.AcquireConcurrencySlotV1: %w: missing concurrency key", ErrorInvalidInput)
+	}
+	output := AcquireConcurrencySlotV1Output{HolderIds: []string{}}
+	for slot := range opts.Concurrency.GetMaxParallel() {
+		slotKey := getConcurrencySlotCacheKey(opts.OrgId, opts.Concurrency.Key, slot)
+		isAcquired, err := cache.AcquireLock(opts.Cache, slotKey, opts.AutomationId, DefaultConcurrencySlotTtl)
+		if err != nil {
+			return nil, fmt.Errorf("models.AcquireConcurrencySlotV1: %w", err)
+		}
+		if isAcquired {
+			if err := opts.Cache.Set(getConcurrencyHolderCacheKey(opts.OrgId, opts.AutomationId), slotKey, concurrencyHolderTtl); err != nil {
+				return nil, fmt.Errorf("models.AcquireConcurrencySlotV1: failed to set cache: %w", err)
+			}
+			return &AcquireConcurrencySlotV1Output{IsAcquired: true}, nil
+		}
+		holderId, err := opts.Cache.Get(slotKey)
+		if err != nil {
+			if redis.IsNilResult(err) {
+				continue
+			}
+			return nil, fmt.Errorf("models.AcquireConcurrencySlotV1: failed to get cache: %w", err)
+		}
+		output.HolderIds = append(output.HolderIds, holderId)
+	}
+	return &output, nil
+}
+
+type RenewConcurrencySlotV1Opts struct {
+	CacheConnection
+
+	// AutomationId is the ID of the automation run holding the slot
+	AutomationId string
+
+	// OrgId is the ID of the org which the automation run belongs to
+	OrgId string
+}
+
+// RenewConcurrencySlotV1 extends the concurrency slot held by the
+// automation run identified by `.AutomationId`, runs without a
+// concurrency slot are ignored
+func RenewConcurrencySlotV1(opts RenewConcurrencySlotV1Opts) error {
+	holderKey := getConcurrencyHolderCacheKey(opts.OrgId, opts.AutomationId)
+	slotKey, err := opts.Cache.Get(holderKey)
+	if err != nil {
+		if redis.IsNilResult(err) {
+			return nil
+		}
+		return fmt.Errorf("models.RenewConcurrencySlotV1: failed to get cache: %w", err)
+	}
+	isAcquired, err := cache.AcquireLock(opts.Cache, slotKey, opts.AutomationId, DefaultConcurrencySlotTtl)
+	if err != nil {
+		return fmt.Errorf("models.RenewConcurrencySlotV1: %w", err)
+	} else if !isAcquired {
+		return fmt.Errorf("models.RenewConcurrencySlotV1: %w: slot[%s] is held by another automation", ErrorConcurrencySlotLost, slotKey)
+	}
+	if err := opts.Cache.Set(holderKey, slotKey, concurrencyHolderTtl); err != nil {
+		return fmt.Errorf("models.RenewConcurrencySlotV1: failed to set cache: %w", err)
+	}
+	return nil
+}
+
+type ReleaseConcurrencySlotV1Opts struct {
+	CacheConnection
+
+	// AutomationId is the ID of the automation run holding the slot
+	AutomationId string
+
+	// OrgId is the ID of the org which the automation run belongs to
+	OrgId string
+}
+
+// ReleaseConcurrencySlotV1 frees the concurrency slot held by the
+// automation run identified by `.AutomationId`, runs without a
+// concurrency slot are ignored
+func ReleaseConcurrencySlotV1(opts ReleaseConcurrencySlotV1Opts) error {
+	holderKey := getConcurrencyHolderCacheKey(opts.OrgId, opts.AutomationId)
+	slotKey, err := opts.Cache.Get(holderKey)
+	if err != nil {
+		if redis.IsNilResult(err) {
+			return nil
+		}
+		return fmt.Errorf("models.ReleaseConcurrencySlotV1: failed to get cache: %w", err)
+	}
+	if err := cache.ReleaseLock(opts.Cache, slotKey, opts.AutomationId); err != nil {
+		return fmt.Errorf("models.ReleaseConcurrencySlotV1: %w", err)
+	}
+	if err := opts.Cache.Del(holderKey); err != nil {
+		return fmt.Errorf("models.ReleaseConcurrencySlotV1: failed to delete cache: %w", err)
+	}
+	return nil
+}
+
+type SupersedeConcurrencyHolderV1Opts struct {
+	CacheConnection
+
+	// AutomationId is the ID of the automation run holding the slot
+	AutomationId string
+
+	// OrgId is the ID of the org which the automation run belongs to
+	OrgId string
+
+	// SupersededBy is the ID of the automation run waiting for the slot
+	SupersededBy string
+}
+
+// SupersedeConcurrencyHolderV1 records that the automation run
+// identified by `.AutomationId` is being cancelled in favour of a newer
+// run and returns true only the first time this is called for the run
+// so that a single cancellation is published while it stops
+func SupersedeConcurrencyHolderV1(opts SupersedeConcurrencyHolderV1Opts) (bool, error) {
+	isSet, err := opts.Cache.SetIfNotExists(
+		getConcurrencySupersededCacheKey(opts.OrgId, opts.AutomationId),
+		opts.SupersededBy,
+		DefaultConcurrencySlotTtl,
+	)
+	if err != nil {
+		return false, fmt.Errorf("models.SupersedeConcurrencyHolderV1: failed to set cache: %w", err)
+	}
+	return isSet, nil
+}
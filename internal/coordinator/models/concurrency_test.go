@@ -0,0 +1,251 @@
+package models
+
+import (
+	"errors"
+	"opsicle/internal/automations"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v7"
+)
+
+type fakeCacheEntry struct {
+	Value     string
+	ExpiresAt time.Time
+}
+
+// fakeCache is an in-memory cache whose entries expire according to
+// `.Now` so that tests can move time forward
+type fakeCache struct {
+	Now time.Time
+
+	mutex   sync.Mutex
+	entries map[string]fakeCacheEntry
+}
+
+func newFakeCache() *fakeCache {
+	return &fakeCache{
+		Now:     time.Now(),
+		entries: map[string]fakeCacheEntry{},
+	}
+}
+
+func (c *fakeCache) get(key string) (fakeCacheEntry, bool) {
+	entry, exists := c.entries[key]
+	if !exists {
+		return entry, false
+	}
+	if !entry.ExpiresAt.IsZero() && !c.Now.Before(entry.ExpiresAt) {
+		delete(c.entries, key)
+		return entry, false
+	}
+	return entry, true
+}
+
+func (c *fakeCache) set(key string, value string, ttl time.Duration) {
+	entry := fakeCacheEntry{Value: value}
+	if ttl > 0 {
+		entry.ExpiresAt = c.Now.Add(ttl)
+	}
+	c.entries[key] = entry
+}
+
+func (c *fakeCache) Set(key string, value string, ttl time.Duration) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.set(key, value, ttl)
+	return nil
+}
+
+func (c *fakeCache) SetIfNotExists(key string, value string, ttl time.Duration) (bool, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if _, exists := c.get(key); exists {
+		return false, nil
+	}
+	c.set(key, value, ttl)
+	return true, nil
+}
+
+func (c *fakeCache) CompareAndSet(key string, expected string, value string, ttl time.Duration) (bool, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if entry, exists := c.get(key); !exists || entry.Value != expected {
+		return false, nil
+	}
+	c.set(key, value, ttl)
+	return true, nil
+}
+
+func (c *fakeCache) CompareAndDel(key string, expected string) (bool, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if entry, exists := c.get(key); !exists || entry.Value != expected {
+		return false, nil
+	}
+	delete(c.entries, key)
+	return true, nil
+}
+
+func (c *fakeCache) Get(key string) (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	entry, exists := c.get(key)
+	if !exists {
+		return "", redis.Nil
+	}
+	return entry.Value, nil
+}
+
+func (c *fakeCache) Increment(key string, ttl time.Duration) (int64, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (c *fakeCache) Scan(prefix string) ([]string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	keys := []string{}
+	for key := range c.entries {
+		if _, exists := c.get(key); exists && strings.HasPrefix(key, strings.TrimSuffix(prefix, "*")) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (c *fakeCache) Del(key string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.entries, key)
+	return nil
+}
+
+func (c *fakeCache) Ping() error { return nil }
+
+func (c *fakeCache) Close() error { return nil }
+
+func acquireTestConcurrencySlot(t *testing.T, fakeCache *fakeCache, automationId string, maxParallel int) *AcquireConcurrencySlotV1Output {
+	t.Helper()
+	output, err := AcquireConcurrencySlotV1(AcquireConcurrencySlotV1Opts{
+		CacheConnection: CacheConnection{Cache: fakeCache},
+		AutomationId:    automationId,
+		Concurrency:     automations.ConcurrencySpec{Key: "deploy", MaxParallel: maxParallel},
+		OrgId:           "org",
+	})
+	if err != nil {
+		t.Fatalf("AcquireConcurrencySlotV1 returned error: %v", err)
+	}
+	return output
+}
+
+func TestAcquireConcurrencySlotV1WhenSlotsAreFull(t *testing.T) {
+	fakeCache := newFakeCache()
+	for _, automationId := range []string{"first", "second"} {
+		if output := acquireTestConcurrencySlot(t, fakeCache, automationId, 2); !output.IsAcquired {
+			t.Fatalf("expected automation[%s] to acquire a slot", automationId)
+		}
+	}
+	output := acquireTestConcurrencySlot(t, fakeCache, "third", 2)
+	if output.IsAcquired {
+		t.Fatalf("expected no slot to be acquired when all slots are held")
+	}
+	if len(output.HolderIds) != 2 || output.HolderIds[0] != "first" || output.HolderIds[1] != "second" {
+		t.Fatalf("expected the holders to be first and second, got %v", output.HolderIds)
+	}
+
+	fakeCache.Now = fakeCache.Now.Add(DefaultConcurrencySlotTtl)
+	if output := acquireTestConcurrencySlot(t, fakeCache, "third", 2); !output.IsAcquired {
+		t.Fatalf("expected a slot to be acquired once the slots of the holders expired")
+	}
+}
+
+func TestRenewConcurrencySlotV1(t *testing.T) {
+	fakeCache := newFakeCache()
+	acquireTestConcurrencySlot(t, fakeCache, "first", 1)
+	renewOpts := RenewConcurrencySlotV1Opts{
+		CacheConnection: CacheConnection{Cache: fakeCache},
+		AutomationId:    "first",
+		OrgId:           "org",
+	}
+	fakeCache.Now = fakeCache.Now.Add(DefaultConcurrencySlotTtl / 2)
+	if err := RenewConcurrencySlotV1(renewOpts); err != nil {
+		t.Fatalf("RenewConcurrencySlotV1 returned error: %v", err)
+	}
+	fakeCache.Now = fakeCache.Now.Add(DefaultConcurrencySlotTtl / 2)
+	if output := acquireTestConcurrencySlot(t, fakeCache, "second", 1); output.IsAcquired {
+		t.Fatalf("expected the renewed slot to still be held")
+	}
+}
+
+func TestRenewConcurrencySlotV1AfterSlotExpired(t *testing.T) {
+	fakeCache := newFakeCache()
+	acquireTestConcurrencySlot(t, fakeCache, "first", 1)
+	renewOpts := RenewConcurrencySlotV1Opts{
+		CacheConnection: CacheConnection{Cache: fakeCache},
+		AutomationId:    "first",
+		OrgId:           "org",
+	}
+
+	// a slot which expired without being taken is acquired again
+	fakeCache.Now = fakeCache.Now.Add(DefaultConcurrencySlotTtl)
+	if err := RenewConcurrencySlotV1(renewOpts); err != nil {
+		t.Fatalf("expected the expired slot to be acquired again, got %v", err)
+	}
+	if output := acquireTestConcurrencySlot(t, fakeCache, "second", 1); output.IsAcquired {
+		t.Fatalf("expected the slot to be held after it was renewed")
+	}
+
+	// a slot which expired and was taken by another run is lost
+	fakeCache.Now = fakeCache.Now.Add(DefaultConcurrencySlotTtl)
+	if output := acquireTestConcurrencySlot(t, fakeCache, "second", 1); !output.IsAcquired {
+		t.Fatalf("expected the expired slot to be acquired by another run")
+	}
+	if err := RenewConcurrencySlotV1(renewOpts); !errors.Is(err, ErrorConcurrencySlotLost) {
+		t.Fatalf("expected ErrorConcurrencySlotLost, got %v", err)
+	}
+}
+
+func TestReleaseConcurrencySlotV1(t *testing.T) {
+	fakeCache := newFakeCache()
+	acquireTestConcurrencySlot(t, fakeCache, "first", 1)
+	releaseOpts := ReleaseConcurrencySlotV1Opts{
+		CacheConnection: CacheConnection{Cache: fakeCache},
+		AutomationId:    "first",
+		OrgId:           "org",
+	}
+	for range 2 {
+		if err := ReleaseConcurrencySlotV1(releaseOpts); err != nil {
+			t.Fatalf("ReleaseConcurrencySlotV1 returned error: %v", err)
+		}
+	}
+	if output := acquireTestConcurrencySlot(t, fakeCache, "second", 1); !output.IsAcquired {
+		t.Fatalf("expected the released slot to be acquired")
+	}
+	if err := ReleaseConcurrencySlotV1(releaseOpts); err != nil {
+		t.Fatalf("ReleaseConcurrencySlotV1 returned error: %v", err)
+	}
+	if output := acquireTestConcurrencySlot(t, fakeCache, "third", 1); output.IsAcquired {
+		t.Fatalf("expected a repeated release to not free the slot of another run")
+	}
+}
+
+func TestReleaseConcurrencySlotV1AfterSlotWasTaken(t *testing.T) {
+	fakeCache := newFakeCache()
+	acquireTestConcurrencySlot(t, fakeCache, "first", 1)
+	fakeCache.Now = fakeCache.Now.Add(DefaultConcurrencySlotTtl)
+	if output := acquireTestConcurrencySlot(t, fakeCache, "second", 1); !output.IsAcquired {
+		t.Fatalf("expected the expired slot to be acquired by another run")
+	}
+	if err := ReleaseConcurrencySlotV1(ReleaseConcurrencySlotV1Opts{
+		CacheConnection: CacheConnection{Cache: fakeCache},
+		AutomationId:    "first",
+		OrgId:           "org",
+	}); err != nil {
+		t.Fatalf("ReleaseConcurrencySlotV1 returned error: %v", err)
+	}
+	if output := acquireTestConcurrencySlot(t, fakeCache, "third", 1); output.IsAcquired {
+		t.Fatalf("expected the release of an expired slot to not free the slot of another run")
+	}
+}
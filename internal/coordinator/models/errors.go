@@ -0,0 +1,10 @@
+package models
+
+import "errors"
+
+var (
+	ErrorConcurrencySlotLost = errors.New("concurrency_slot_lost")
+	ErrorInvalidInput        = errors.New("invalid_input")
+	ErrorNotFound            = errors.New("not_found")
+	ErrorOrgMismatch         = errors.New("org_mismatch")
+)
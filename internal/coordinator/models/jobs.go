@@ -0,0 +1,171 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"opsicle/internal/automations"
+	"opsicle/internal/queue"
+	"time"
+)
+
+const (
+	// DefaultJobPollInterval is the interval between checks of the
+	// queue while a worker is waiting for a job
+	DefaultJobPollInterval = 1 * time.Second
+
+	// DefaultJobRequeueDelay is how long a job which could not be handed
+	// to a worker yet is held back before it is popped again
+	DefaultJobRequeueDelay = 5 * time.Second
+
+	// DefaultJobCancellationConsumerInactiveThreshold is how long the
+	// consumer of a run's cancellations is kept after it was last used,
+	// consumers of runs which are no longer executing are removed by
+	// the queue after this duration
+	DefaultJobCancellationConsumerInactiveThreshold = 1 * time.Minute
+)
+
+type PopJobV1Opts struct {
+	QueueConnection
+
+	// Context is the context of the caller, popping stops when the
+	// context is done
+	Context context.Context
+
+	// OrgId is the ID of the org whose jobs should be retrieved
+	OrgId string
+
+	// Wait is the maximum duration to wait for a job to become
+	// available before returning
+	Wait time.Duration
+}
+
+// Job is an automation run popped from its org's queue, the run remains
+// in the queue until it is acknowledged using AckV1 or is returned to
+// it using RequeueV1
+type Job struct {
+	Automation *automations.Automation
+
+	message *queue.Message
+}
+
+// AckV1 removes the job from the queue once it was handed to a worker
+// or was otherwise dealt with
+func (j *Job) AckV1() error {
+	if err := j.message.Ack(); err != nil {
+		return fmt.Errorf("models.Job.AckV1: failed to ack automation[%s]: %w", j.Automation.Spec.Status.Id, err)
+	}
+	return nil
+}
+
+// RequeueV1 returns a job which could not be handed to a worker yet to
+// the queue, it is popped again after `delay` ahead of jobs which were
+// queued after it
+func (j *Job) RequeueV1(delay time.Duration) error {
+	if err := j.message.Nak(delay); err != nil {
+		return fmt.Errorf("models.Job.RequeueV1: failed to requeue automation[%s]: %w", j.Automation.Spec.Status.Id, err)
+	}
+	return nil
+}
+
+// PopJobV1 waits up to `.Wait` for an automation run belonging to the
+// org identified by `.OrgId` to become available in the queue and returns
+// it. Returns nil without an error if no jobs were available
+func PopJobV1(opts PopJobV1Opts) (*Job, error) {
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	deadline := time.Now().Add(opts.Wait)
+	for {
+		message, err := opts.Queue.Pop(queue.PopOpts{
+			ConsumerId:    fmt.Sprintf("coordinator-%s", opts.OrgId),
+			IsAckDeferred: true,
+			Queue:         automations.GetRunsQueue(&opts.OrgId),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("models.PopJobV1: failed to pop from queue: %w", err)
+		}
+		if message != nil {
+			var automation automations.Automation
+			if err := json.Unmarshal(message.Data, &automation); err != nil {
+				// unreadable jobs are dropped so that they are not
+				// redelivered indefinitely
+				_ = message.Ack()
+				return nil, fmt.Errorf("models.PopJobV1: failed to unmarshal automation: %w", err)
+			}
+			if automation.Spec.Status.OrgId == nil || *automation.Spec.Status.OrgId != opts.OrgId {
+				_ = message.Ack()
+				return nil, fmt.Errorf("models.PopJobV1: automation[%s] does not belong to org[%s]: %w", automation.Spec.Status.Id, opts.OrgId, ErrorOrgMismatch)
+			}
+			return &Job{Automation: &automation, message: message}, nil
+		}
+		if time.Now().Add(DefaultJobPollInterval).After(deadline) {
+			return nil, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		case <-time.After(DefaultJobPollInterval):
+		}
+	}
+}
+
+type PopJobCancellationV1Opts struct {
+	QueueConnection
+
+	// AutomationId is the ID of the automation run whose cancellation
+	// should be retrieved
+	AutomationId string
+
+	// Context is the context of the caller, popping stops when the
+	// context is done
+	Context context.Context
+
+	// OrgId is the ID of the org which the automation run belongs to
+	OrgId string
+
+	// Wait is the maximum duration to wait for a cancellation before
+	// returning
+	Wait time.Duration
+}
+
+// PopJobCancellationV1 waits up to `.Wait` for the automation run
+// identified by `.AutomationId` to be cancelled and returns the
+// cancellation. Returns nil without an error if the run was not
+// cancelled
+func PopJobCancellationV1(opts PopJobCancellationV1Opts) (*automations.Cancellation, error) {
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	deadline := time.Now().Add(opts.Wait)
+	for {
+		message, err := opts.Queue.Pop(queue.PopOpts{
+			ConsumerId:        fmt.Sprintf("coordinator-cancellation-%s", opts.AutomationId),
+			InactiveThreshold: DefaultJobCancellationConsumerInactiveThreshold,
+			Queue:             automations.GetCancellationsQueue(opts.AutomationId),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("models.PopJobCancellationV1: failed to pop from queue: %w", err)
+		}
+		if message != nil {
+			var cancellation automations.Cancellation
+			if err := json.Unmarshal(message.Data, &cancellation); err != nil {
+				return nil, fmt.Errorf("models.PopJobCancellationV1: failed to unmarshal cancellation: %w", err)
+			}
+			if cancellation.OrgId == nil || *cancellation.OrgId != opts.OrgId {
+				return nil, fmt.Errorf("models.PopJobCancellationV1: automation[%s] does not belong to org[%s]: %w", opts.AutomationId, opts.OrgId, ErrorOrgMismatch)
+			}
+			return &cancellation, nil
+		}
+		if time.Now().Add(DefaultJobPollInterval).After(deadline) {
+			return nil, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		case <-time.After(DefaultJobPollInterval):
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	cachePrefixWorker = "worker"
+
+	// DefaultWorkerRegistrationTtl is how long a worker registration
+	// is kept for since the worker was last seen
+	DefaultWorkerRegistrationTtl = 5 * time.Minute
+)
+
+// Worker represents a worker that has registered itself with the
+// coordinator
+type Worker struct {
+	Id           string    `json:"id"`
+	OrgId        string    `json:"orgId"`
+	TokenId      string    `json:"tokenId"`
+	Hostname     string    `json:"hostname"`
+	Runtime      string    `json:"runtime"`
+	SourceIp     string    `json:"sourceIp"`
+	RegisteredAt time.Time `json:"registeredAt"`
+	LastSeenAt   time.Time `json:"lastSeenAt"`
+}
+
+func getWorkerCacheKey(orgId, workerId string) string {
+	return strings.Join([]string{cachePrefixWorker, orgId, workerId}, ":")
+}
+
+type RegisterWorkerV1Opts struct {
+	CacheConnection
+
+	Worker Worker
+}
+
+// RegisterWorkerV1 stores the worker registration in the cache, the
+// registration expires if the worker is not seen within
+// `DefaultWorkerRegistrationTtl`
+func RegisterWorkerV1(opts RegisterWorkerV1Opts) (*Worker, error) {
+	if opts.Worker.Id == "" {
+		return nil, fmt.Errorf("models.RegisterWorkerV1: %w: missing worker id", ErrorInvalidInput)
+	} else if opts.Worker.OrgId == "" {
+		return nil, fmt.Errorf("models.RegisterWorkerV1: %w: missing org id", ErrorInvalidInput)
+	}
+	worker := opts.Worker
+	now := time.Now()
+	if existingWorker, err := GetWorkerV1(GetWorkerV1Opts{
+		CacheConnection: opts.CacheConnection,
+		OrgId:           worker.OrgId,
+		WorkerId:        worker.Id,
+	}); err == nil {
+		worker.RegisteredAt = existingWorker.RegisteredAt
+	} else {
+		worker.RegisteredAt = now
+	}
+	worker.LastSeenAt = now
+	if err := worker.save(opts.CacheConnection); err != nil {
+		return nil, fmt.Errorf("models.RegisterWorkerV1: %w", err)
+	}
+	return &worker, nil
+}
+
+type GetWorkerV1Opts struct {
+	CacheConnection
+
+	OrgId    string
+	WorkerId string
+}
+
+// GetWorkerV1 retrieves a worker registration from the cache
+func GetWorkerV1(opts GetWorkerV1Opts) (*Worker, error) {
+	cacheData, err := opts.Cache.Get(getWorkerCacheKey(opts.OrgId, opts.WorkerId))
+	if err != nil {
+		return nil, fmt.Errorf("models.GetWorkerV1: failed to get cache: %w", err)
+	}
+	if cacheData == "" {
+		return nil, fmt.Errorf("models.GetWorkerV1: %w", ErrorNotFound)
+	}
+	var worker Worker
+	if err := json.Unmarshal([]byte(cacheData), &worker); err != nil {
+		return nil, fmt.Errorf("models.GetWorkerV1: failed to unmarshal data: %w", err)
+	}
+	return &worker, nil
+}
+
+// TouchV1 updates the last seen timestamp of the worker and extends
+// the expiry of its registration
+func (w *Worker) TouchV1(opts CacheConnection) error {
+	w.LastSeenAt = time.Now()
+	if err := w.save(opts); err != nil {
+		return fmt.Errorf("models.Worker.TouchV1: %w", err)
+	}
+	return nil
+}
+
+func (w *Worker) save(opts CacheConnection) error {
+	cacheData, err := json.Marshal(w)
+	if err != nil {
+		return fmt.Errorf("failed to marshal worker: %w", err)
+	}
+	if err := opts.Cache.Set(getWorkerCacheKey(w.OrgId, w.Id), string(cacheData), DefaultWorkerRegistrationTtl); err != nil {
+		return fmt.Errorf("failed to update cache: %w", err)
+	}
+	return nil
+}
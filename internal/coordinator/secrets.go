@@ -0,0 +1,60 @@
+package coordinator
+
+import (
+	"fmt"
+	"opsicle/internal/automations"
+	"opsicle/pkg/controller"
+)
+
+// resolveJobSecrets populates the values of secret variables, both
+// those which reference org secrets and those input with the run; this
+// is done only when a job is being handed over to a worker so that
+// secret values are never stored in the queue
+func resolveJobSecrets(automation *automations.Automation, orgId string) error {
+	secretRefs := automation.Spec.Variables.GetSecretRefs()
+	inputSecretIds := automation.Spec.Variables.GetInputSecretIds()
+	if len(secretRefs) == 0 && len(inputSecretIds) == 0 {
+		return nil
+	}
+	client, err := getControllerClient()
+	if err != nil {
+		return fmt.Errorf("failed to connect to controller: %w", err)
+	}
+	if len(inputSecretIds) > 0 {
+		output, err := client.ResolveAutomationSecretsV1(controller.ResolveAutomationSecretsV1Input{
+			AutomationId: automation.Spec.Status.Id,
+			OrgId:        &orgId,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to resolve secret variables: %w", err)
+		}
+		for i, variable := range automation.Spec.Variables {
+			if value, exists := output.Data.Values[variable.Id]; exists && variable.IsSecret() && variable.GetSecretRef() == "" {
+				automation.Spec.Variables[i].Value = value
+			}
+		}
+	}
+	if len(secretRefs) == 0 {
+		return nil
+	}
+	output, err := client.ResolveOrgSecretsV1(controller.ResolveOrgSecretsV1Input{
+		OrgId: orgId,
+		Names: secretRefs,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to resolve secrets: %w", err)
+	}
+	for i, variable := range automation.Spec.Variables {
+		secretRef := variable.GetSecretRef()
+		if secretRef == "" {
+			continue
+		}
+		value, exists := output.Data.Values[secretRef]
+		if !exists {
+			return fmt.Errorf("failed to resolve secret[%s] of var[%s]", secretRef, variable.Id)
+		}
+		automation.Spec.Variables[i].Type = automations.VariableTypeSecret
+		automation.Spec.Variables[i].Value = value
+	}
+	return nil
+}
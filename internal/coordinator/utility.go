@@ -1,7 +1,15 @@
 package coordinator
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"opsicle/internal/common"
+	"opsicle/internal/coordinator/models"
+	"opsicle/internal/types"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 func registerInitRoutes(opts RouteRegistrationOpts) {
@@ -11,10 +19,76 @@ func registerInitRoutes(opts RouteRegistrationOpts) {
 	v1.Handle("/status", requiresAuth(http.HandlerFunc(handleWorkerStatusRetrievalV1))).Methods(http.MethodGet)
 }
 
+type WorkerRegistrationV1Output struct {
+	WorkerId        string    `json:"workerId"`
+	OrgId           string    `json:"orgId"`
+	RegisteredAt    time.Time `json:"registeredAt"`
+	JobWaitDuration string    `json:"jobWaitDuration"`
+}
+
+// handleWorkerRegistrationV1 registers the calling worker, the worker
+// can identify itself using the `workerId` query parameter, otherwise
+// an ID is generated for it
 func handleWorkerRegistrationV1(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+	session := r.Context().Value(authRequestContext).(identity)
+
+	query := r.URL.Query()
+	workerId := query.Get("workerId")
+	if workerId == "" {
+		workerId = uuid.NewString()
+	}
+	worker, err := models.RegisterWorkerV1(models.RegisterWorkerV1Opts{
+		CacheConnection: models.CacheConnection{Cache: cacheInstance},
+		Worker: models.Worker{
+			Id:       workerId,
+			OrgId:    session.OrgId,
+			TokenId:  session.TokenId,
+			Hostname: query.Get("hostname"),
+			Runtime:  query.Get("runtime"),
+			SourceIp: session.SourceIp,
+		},
+	})
+	if err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to register worker[%s] of org[%s]: %s", workerId, session.OrgId, err))
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to register worker", types.ErrorDatabaseIssue)
+		return
+	}
+	log(common.LogLevelInfo, fmt.Sprintf("registered worker[%s] of org[%s] using runtime[%s]", worker.Id, worker.OrgId, worker.Runtime))
 
+	output := WorkerRegistrationV1Output{
+		WorkerId:        worker.Id,
+		OrgId:           worker.OrgId,
+		RegisteredAt:    worker.RegisteredAt,
+		JobWaitDuration: DefaultJobWaitDuration.String(),
+	}
+	common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", output)
 }
 
+// handleWorkerStatusRetrievalV1 returns the registration of the worker
+// identified by the `workerId` query parameter
 func handleWorkerStatusRetrievalV1(w http.ResponseWriter, r *http.Request) {
+	log := r.Context().Value(common.HttpContextLogger).(common.HttpRequestLogger)
+	session := r.Context().Value(authRequestContext).(identity)
 
+	workerId := r.URL.Query().Get("workerId")
+	if workerId == "" {
+		common.SendHttpFailResponse(w, r, http.StatusBadRequest, "missing worker id", types.ErrorInvalidInput)
+		return
+	}
+	worker, err := models.GetWorkerV1(models.GetWorkerV1Opts{
+		CacheConnection: models.CacheConnection{Cache: cacheInstance},
+		OrgId:           session.OrgId,
+		WorkerId:        workerId,
+	})
+	if err != nil {
+		log(common.LogLevelError, fmt.Sprintf("failed to get worker[%s] of org[%s]: %s", workerId, session.OrgId, err))
+		if errors.Is(err, models.ErrorNotFound) {
+			common.SendHttpFailResponse(w, r, http.StatusNotFound, "worker is not registered", types.ErrorNotFound)
+			return
+		}
+		common.SendHttpFailResponse(w, r, http.StatusInternalServerError, "failed to get worker", types.ErrorDatabaseIssue)
+		return
+	}
+	common.SendHttpSuccessResponse(w, r, http.StatusOK, "ok", worker)
 }
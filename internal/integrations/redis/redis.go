@@ -13,6 +13,33 @@ import (
 const (
 	DefaultNetworkTimeout     = 5 * time.Second
 	DefaultNetworkIdleTimeout = 30 * time.Second
+
+	scanBatchSize = 100
+)
+
+var (
+	// compareAndSetScript sets KEYS[1] to ARGV[2] with an expiry of ARGV[3]
+	// milliseconds only if KEYS[1] currently holds ARGV[1]
+	compareAndSetScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+if tonumber(ARGV[3]) > 0 then
+	redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+else
+	redis.call("SET", KEYS[1], ARGV[2])
+end
+return 1
+`)
+
+	// compareAndDelScript deletes KEYS[1] only if it currently holds
+	// ARGV[1]
+	compareAndDelScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+return redis.call("DEL", KEYS[1])
+`)
 )
 
 func IsNilResult(err error) bool {
@@ -38,6 +65,49 @@ func (i *Instance) Set(key string, value string, ttl time.Duration) error {
 	return nil
 }
 
+func (i *Instance) SetIfNotExists(key string, value string, ttl time.Duration) (bool, error) {
+	response := i.Client.GetClient().SetNX(key, value, ttl)
+	if response.Err() != nil {
+		return false, fmt.Errorf("failed to set key[%s] if not exists: %w", key, response.Err())
+	}
+	isSet := response.Val()
+	i.ServiceLogs <- common.ServiceLogf(common.LogLevelDebug, "key[%s] conditional creation succeeded (set: %v)", key, isSet)
+	return isSet, nil
+}
+
+// CompareAndSet sets `key` to `value` with the provided `ttl` only if
+// `key` currently holds `expected`, the comparison and update are done
+// atomically on the server
+func (i *Instance) CompareAndSet(key string, expected string, value string, ttl time.Duration) (bool, error) {
+	response := compareAndSetScript.Run(i.Client.GetClient(), []string{key}, expected, value, ttl.Milliseconds())
+	if response.Err() != nil {
+		return false, fmt.Errorf("failed to compare and set key[%s]: %w", key, response.Err())
+	}
+	result, err := response.Int64()
+	if err != nil {
+		return false, fmt.Errorf("failed to parse compare and set result of key[%s]: %w", key, err)
+	}
+	isSet := result == 1
+	i.ServiceLogs <- common.ServiceLogf(common.LogLevelDebug, "key[%s] conditional update succeeded (set: %v)", key, isSet)
+	return isSet, nil
+}
+
+// CompareAndDel deletes `key` only if it currently holds `expected`, the
+// comparison and deletion are done atomically on the server
+func (i *Instance) CompareAndDel(key string, expected string) (bool, error) {
+	response := compareAndDelScript.Run(i.Client.GetClient(), []string{key}, expected)
+	if response.Err() != nil {
+		return false, fmt.Errorf("failed to compare and delete key[%s]: %w", key, response.Err())
+	}
+	result, err := response.Int64()
+	if err != nil {
+		return false, fmt.Errorf("failed to parse compare and delete result of key[%s]: %w", key, err)
+	}
+	isDeleted := result == 1
+	i.ServiceLogs <- common.ServiceLogf(common.LogLevelDebug, "key[%s] conditional deletion succeeded (deleted: %v)", key, isDeleted)
+	return isDeleted, nil
+}
+
 func (i *Instance) Get(key string) (string, error) {
 	response := i.Client.GetClient().Get(key)
 	if response.Err() != nil {
@@ -49,12 +119,41 @@ func (i *Instance) Get(key string) (string, error) {
 	return value, nil
 }
 
-func (i *Instance) Scan(pattern string) ([]string, error) {
-	response := i.Client.GetClient().Keys(pattern)
+// Increment increments the counter at `key` and returns its new value,
+// the `ttl` is only applied when the counter is created so that counters
+// can be used for fixed-window rate limiting
+func (i *Instance) Increment(key string, ttl time.Duration) (int64, error) {
+	response := i.Client.GetClient().Incr(key)
 	if response.Err() != nil {
-		return nil, fmt.Errorf("failed to list keys[%s]: %w", pattern, response.Err())
+		return 0, fmt.Errorf("failed to increment key[%s]: %w", key, response.Err())
+	}
+	count := response.Val()
+	if count == 1 && ttl > 0 {
+		if err := i.Client.GetClient().Expire(key, ttl).Err(); err != nil {
+			return count, fmt.Errorf("failed to set expiry of key[%s]: %w", key, err)
+		}
+	}
+	i.ServiceLogs <- common.ServiceLogf(common.LogLevelDebug, "key[%s] increment succeeded (count: %v)", key, count)
+	return count, nil
+}
+
+func (i *Instance) Scan(pattern string) ([]string, error) {
+	// keys are iterated using SCAN so that large keyspaces do not block
+	// the server as KEYS does, SCAN may return a key more than once
+	keys := []string{}
+	isSeen := map[string]bool{}
+	iterator := i.Client.GetClient().Scan(0, pattern, scanBatchSize).Iterator()
+	for iterator.Next() {
+		key := iterator.Val()
+		if isSeen[key] {
+			continue
+		}
+		isSeen[key] = true
+		keys = append(keys, key)
+	}
+	if err := iterator.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan keys[%s]: %w", pattern, err)
 	}
-	keys := response.Val()
 	i.ServiceLogs <- common.ServiceLogf(common.LogLevelDebug, "keys[%s] scan succeeded", pattern)
 	i.ServiceLogs <- common.ServiceLogf(common.LogLevelTrace, "found %v keys[%s]", len(keys), pattern)
 	return keys, nil
@@ -11,7 +11,6 @@ import (
 	"time"
 
 	"github.com/nats-io/nats.go"
-	"github.com/sirupsen/logrus"
 )
 
 const (
@@ -61,6 +60,7 @@ func (n *Nats) Push(opts PushOpts) (*PushOutput, error) {
 		if opts.Stream.ReplicaCount != 0 {
 			ensureStreamOpts.Replicas = opts.Stream.ReplicaCount
 		}
+		ensureStreamOpts.Subjects = opts.Stream.Subjects
 	}
 	if err := n.ensureStream(ensureStreamOpts); err != nil {
 		return nil, fmt.Errorf("failed to ensure stream: %w", err)
@@ -92,10 +92,29 @@ func (n *Nats) Pop(opts PopOpts) (*Message, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to get streaming context: %w", err)
 	}
+	subOpts := []nats.SubOpt{}
+	if opts.InactiveThreshold > 0 {
+		subOpts = append(subOpts, nats.InactiveThreshold(opts.InactiveThreshold))
+	}
+	if opts.IsAckDeferred {
+		// consumers created by the subscription are removed when it is
+		// unsubscribed, binding to an existing consumer keeps it so that
+		// messages which were not acknowledged are redelivered to it
+		if err := n.ensureDurable(NatsDurableOpts{
+			Durable:           opts.ConsumerId,
+			InactiveThreshold: opts.InactiveThreshold,
+			Stream:            stream,
+			Subject:           subject,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to ensure durable[%s]: %w", opts.ConsumerId, err)
+		}
+		subOpts = []nats.SubOpt{nats.Bind(stream, opts.ConsumerId)}
+	}
 	n.ServiceLogs <- common.ServiceLogf(common.LogLevelDebug, "subscribing to stream[%s]/subject[%s] with durable[%s]", stream, subject, opts.ConsumerId)
 	sub, err := jsContext.PullSubscribe(
 		subject,
 		opts.ConsumerId,
+		subOpts...,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to subscribe to stream[%s]/subject[%s] with durable[%s]: %w", stream, subject, opts.ConsumerId, err)
@@ -104,26 +123,50 @@ func (n *Nats) Pop(opts PopOpts) (*Message, error) {
 	defer cancel()
 	msg, err := sub.Fetch(1, nats.Context(ctx))
 	if err != nil {
+		_ = sub.Unsubscribe()
+		// messages which are not acknowledged remain in the stream while
+		// they wait to be redelivered so no message being available is
+		// expected
+		if opts.IsAckDeferred && (errors.Is(err, context.DeadlineExceeded) || errors.Is(err, nats.ErrTimeout)) {
+			return nil, nil
+		}
 		return nil, fmt.Errorf("failed to fetch from subject[%s]: %w", subject, err)
 	}
 	if len(msg) == 0 {
+		_ = sub.Unsubscribe()
 		return nil, nil
 	}
 	msgMetadata, err := msg[0].Metadata()
 	if err != nil {
+		_ = sub.Unsubscribe()
 		return nil, fmt.Errorf("failed to get message metadata: %w", err)
 	}
-	logrus.Infof("received message[%v:%v]", msgMetadata.Sequence.Consumer, msgMetadata.Sequence.Stream)
-	if err := msg[0].AckSync(); err != nil {
+	n.ServiceLogs <- common.ServiceLogf(common.LogLevelDebug, "received message[%v:%v] from subject[%s]", msgMetadata.Sequence.Consumer, msgMetadata.Sequence.Stream, subject)
+	message := &Message{
+		Data:    msg[0].Data,
+		Subject: msg[0].Subject,
+	}
+	if opts.IsAckDeferred {
+		message.Ack = func() error {
+			if err := msg[0].AckSync(); err != nil {
+				return fmt.Errorf("failed to ack msg[%v]: %w", msgMetadata.Sequence.Stream, err)
+			}
+			return nil
+		}
+		message.Nak = func(delay time.Duration) error {
+			if err := msg[0].NakWithDelay(delay); err != nil {
+				return fmt.Errorf("failed to nak msg[%v]: %w", msgMetadata.Sequence.Stream, err)
+			}
+			return nil
+		}
+	} else if err := msg[0].AckSync(); err != nil {
+		_ = sub.Unsubscribe()
 		return nil, fmt.Errorf("failed to ack msg[%v]: %w", msgMetadata.Sequence.Stream, err)
 	}
 	if err := sub.Unsubscribe(); err != nil {
 		return nil, fmt.Errorf("failed to set auto-unsubscribe: %w", err)
 	}
-	return &Message{
-		Data:    msg[0].Data,
-		Subject: msg[0].Subject,
-	}, nil
+	return message, nil
 }
 
 type NatsSubscribeHandler func(context.Context, Message) error
@@ -159,6 +202,7 @@ func (n *Nats) Subscribe(opts SubscribeOpts) error {
 		if opts.Stream.ReplicaCount != 0 {
 			ensureStreamOpts.Replicas = opts.Stream.ReplicaCount
 		}
+		ensureStreamOpts.Subjects = opts.Stream.Subjects
 	}
 	if err := n.ensureStream(ensureStreamOpts); err != nil {
 		return fmt.Errorf("failed to ensure stream: %w", err)
@@ -279,8 +323,13 @@ func (n *Nats) ensureNats() error {
 }
 
 type NatsDurableOpts struct {
-	AckWait    time.Duration
-	Durable    string
+	AckWait time.Duration
+	Durable string
+
+	// InactiveThreshold when defined is how long the durable is kept
+	// after it was last used, durables are kept indefinitely otherwise
+	InactiveThreshold time.Duration
+
 	Stream     string
 	Subject    string
 	StreamOpts NatsStreamOpts
@@ -316,7 +365,7 @@ func (n *Nats) ensureDurable(opts NatsDurableOpts) error {
 		MaxAckPending:     maxAck,
 		DeliverPolicy:     nats.DeliverAllPolicy,
 		ReplayPolicy:      nats.ReplayInstantPolicy,
-		InactiveThreshold: 0,
+		InactiveThreshold: opts.InactiveThreshold,
 	})
 	if err != nil && !errors.Is(err, nats.ErrConsumerNameAlreadyInUse) && !errors.Is(err, nats.ErrObjectAlreadyExists) {
 		return fmt.Errorf("failed to add consumer: %w", err)
@@ -330,21 +379,32 @@ type NatsStreamOpts struct {
 	MaxSizeBytes     int64
 	Replicas         int
 	StorageType      *int
+	Subjects         []string
 	QueueInfo        QueueOpts
 }
 
 func (n *Nats) ensureStream(opts NatsStreamOpts) error {
 	stream, subject := getNatsQueueInfo(opts.QueueInfo)
+	subjects := []string{subject}
+	if len(opts.Subjects) > 0 {
+		subjects = []string{}
+		for _, streamSubject := range opts.Subjects {
+			_, streamSubject = getNatsQueueInfo(QueueOpts{Stream: stream, Subject: streamSubject})
+			subjects = append(subjects, streamSubject)
+		}
+	}
 	jsContext, err := n.Client.GetStreamingClient()
 	if err != nil {
 		return fmt.Errorf("failed to get streaming context: %w", err)
 	}
 	if streamInfo, err := jsContext.StreamInfo(stream); err == nil && streamInfo != nil {
 		cfg := streamInfo.Config
-		if !n.isSubjectInSubjects(streamInfo.Config.Subjects, subject) {
-			cfg.Subjects = append(cfg.Subjects, subject)
-			if _, err := jsContext.UpdateStream(&cfg); err != nil {
-				return fmt.Errorf("failed to update stream[%s:%s]: %w", stream, subject, err)
+		for _, streamSubject := range subjects {
+			if !n.isSubjectInSubjects(cfg.Subjects, streamSubject) {
+				cfg.Subjects = append(cfg.Subjects, streamSubject)
+				if _, err := jsContext.UpdateStream(&cfg); err != nil {
+					return fmt.Errorf("failed to update stream[%s:%s]: %w", stream, streamSubject, err)
+				}
 			}
 		}
 		cfg.Retention = nats.WorkQueuePolicy
@@ -358,7 +418,7 @@ func (n *Nats) ensureStream(opts NatsStreamOpts) error {
 	cfg := &nats.StreamConfig{
 		NoAck:     false,
 		Name:      stream,
-		Subjects:  []string{subject},
+		Subjects:  subjects,
 		Replicas:  opts.Replicas,
 		Retention: nats.WorkQueuePolicy,
 		// Limits; -1 = unlimited
@@ -410,9 +470,12 @@ func (n *Nats) hasMessages(stream, subject string) (bool, error) {
 	return true, nil
 }
 
+// isSubjectInSubjects returns true if the target subject is captured by
+// any of the provided subjects, taking the `*` and `>` wildcards into
+// account
 func (n *Nats) isSubjectInSubjects(subjects []string, target string) bool {
 	for _, s := range subjects {
-		if s == target {
+		if s == target || isSubjectMatch(s, target) {
 			return true
 		}
 	}
@@ -3,6 +3,7 @@ package queue
 import (
 	"opsicle/internal/common"
 	"opsicle/internal/persistence"
+	"strings"
 )
 
 // InitNatsOpts configures the InitNats method
@@ -19,3 +20,23 @@ func InitNats(opts InitNatsOpts) error {
 	}
 	return nil
 }
+
+// isSubjectMatch returns true if the `subject` is captured by `pattern`
+// where `pattern` may contain NATS wildcards; `*` matches exactly one
+// token and `>` matches one or more trailing tokens
+func isSubjectMatch(pattern, subject string) bool {
+	patternTokens := strings.Split(pattern, ".")
+	subjectTokens := strings.Split(subject, ".")
+	for i, patternToken := range patternTokens {
+		if patternToken == ">" {
+			return len(subjectTokens) > i
+		}
+		if i >= len(subjectTokens) {
+			return false
+		}
+		if patternToken != "*" && patternToken != subjectTokens[i] {
+			return false
+		}
+	}
+	return len(patternTokens) == len(subjectTokens)
+}
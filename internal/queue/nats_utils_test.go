@@ -0,0 +1,23 @@
+package queue
+
+import "testing"
+
+func TestIsSubjectMatch(t *testing.T) {
+	cases := []struct {
+		pattern string
+		subject string
+		matches bool
+	}{
+		{"logs.*", "logs.abc", true},
+		{"logs.*", "logs.abc.def", false},
+		{"logs.>", "logs.abc.def", true},
+		{"logs.>", "logs", false},
+		{"logs.abc", "logs.abc", true},
+		{"logs.abc", "logs.def", false},
+	}
+	for _, c := range cases {
+		if got := isSubjectMatch(c.pattern, c.subject); got != c.matches {
+			t.Fatalf("expected isSubjectMatch(%q, %q) to be %v", c.pattern, c.subject, c.matches)
+		}
+	}
+}
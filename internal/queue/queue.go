@@ -32,13 +32,34 @@ type Instance interface {
 type Message struct {
 	Data    []byte `json:"data"`
 	Subject string `json:"subject"`
+
+	// Ack acknowledges the message so that it is removed from the queue,
+	// this is only defined for messages popped with `.IsAckDeferred`
+	Ack func() error `json:"-"`
+
+	// Nak returns the message to the queue to be redelivered after the
+	// provided delay without losing its position, this is only defined
+	// for messages popped with `.IsAckDeferred`
+	Nak func(delay time.Duration) error `json:"-"`
 }
 
 type MessageHandler func(context.Context, Message) error
 
 type PopOpts struct {
 	ConsumerId string
-	Queue      QueueOpts
+
+	// InactiveThreshold when defined is how long the consumer is kept
+	// after it was last used, use this for consumers of queues which
+	// are only popped for a limited time so that they do not pile up
+	InactiveThreshold time.Duration
+
+	// IsAckDeferred when true leaves the popped message unacknowledged
+	// until its `.Ack` is called or it is returned to the queue using
+	// its `.Nak`, the consumer is kept between pops so that returned
+	// messages are redelivered to it
+	IsAckDeferred bool
+
+	Queue QueueOpts
 }
 
 type PushOpts struct {
@@ -70,4 +91,9 @@ type StreamOpts struct {
 	MaxMessagesCount int64
 	MaxSizeBytes     int64
 	ReplicaCount     int
+
+	// Subjects when defined are the subjects (relative to the stream)
+	// that the stream should capture instead of the subject being
+	// pushed to/subscribed from, use this for wildcard subjects
+	Subjects []string
 }
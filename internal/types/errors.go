@@ -5,6 +5,7 @@ import "errors"
 var (
 	ErrorAccountSuspended        = errors.New("account_suspended")
 	ErrorAuthRequired            = errors.New("auth_required")
+	ErrorAutomationCompleted     = errors.New("automation_completed")
 	ErrorEmailExists             = errors.New("email_exists")
 	ErrorEmailUnverified         = errors.New("email_unverified")
 	ErrorGeneric                 = errors.New("generic_error")
@@ -24,6 +25,7 @@ var (
 	ErrorMfaTokenInvalid         = errors.New("mfa_token_invalid")
 	ErrorNotFound                = errors.New("not_found")
 	ErrorOrgExists               = errors.New("org_exists")
+	ErrorRateLimited             = errors.New("rate_limited")
 	ErrorUserExistsInOrg         = errors.New("user_exists_in_org")
 	ErrorSessionExpired          = errors.New("session_expired")
 	ErrorTotpInvalid             = errors.New("totp_invalid")
@@ -48,10 +50,11 @@ var (
 	// ErrorJwtClaims indicates that the claim data couldn't be parsed
 	ErrorJwtClaimsInvalid = errors.New("jwt_claims_invalid")
 
-	ErrorCodeIssue     = errors.New("code_issue")
-	ErrorDatabaseIssue = errors.New("database_issue")
-	ErrorQueueIssue    = errors.New("queue_issue")
-	ErrorUnknown       = errors.New("unknown_error")
+	ErrorCodeIssue       = errors.New("code_issue")
+	ErrorControllerIssue = errors.New("controller_issue")
+	ErrorDatabaseIssue   = errors.New("database_issue")
+	ErrorQueueIssue      = errors.New("queue_issue")
+	ErrorUnknown         = errors.New("unknown_error")
 
 	ErrorOutputNil        = errors.New("__output_nil")
 	ErrorOutputNotPointer = errors.New("__output_not_pointer")
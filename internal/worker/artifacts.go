@@ -0,0 +1,233 @@
+package worker
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"opsicle/internal/automations"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/docker/docker/client"
+)
+
+var errorArtifactsTooLarge = errors.New("artifacts_too_large")
+
+// artifactCollector collects phase outputs into a single gzipped
+// tarball where each output is stored under the name of its phase
+type artifactCollector struct {
+	buffer     bytes.Buffer
+	gzipWriter *gzip.Writer
+	tarWriter  *tar.Writer
+	mutex      sync.Mutex
+	count      int
+	maxSize    int
+
+	// err is set when the collector can no longer produce a valid
+	// tarball, eg. when the maximum size has been exceeded
+	err error
+}
+
+func newArtifactCollector(maxSize int) *artifactCollector {
+	collector := &artifactCollector{maxSize: maxSize}
+	collector.gzipWriter = gzip.NewWriter(&collector.buffer)
+	collector.tarWriter = tar.NewWriter(collector.gzipWriter)
+	return collector
+}
+
+// Add copies the entries of the provided tar stream into the collected
+// artifacts; entry names are rewritten so that `sourcePath` is replaced
+// with `artifactPath`
+func (c *artifactCollector) Add(archive io.Reader, sourcePath, artifactPath string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	tarReader := tar.NewReader(archive)
+	sourceBase := path.Base(sourcePath)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to read archive of path[%s]: %w", sourcePath, err)
+		}
+		// archives from docker are rooted at the basename of the copied path
+		relativeName := strings.TrimPrefix(strings.TrimPrefix(header.Name, sourceBase), "/")
+		header.Name = path.Join(artifactPath, relativeName)
+		if err := c.tarWriter.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write header of path[%s]: %w", header.Name, err)
+		}
+		if err := c.copyContent(tarReader, header.Name); err != nil {
+			return err
+		}
+		if err := c.tarWriter.Flush(); err != nil {
+			return fmt.Errorf("failed to flush path[%s]: %w", header.Name, err)
+		}
+		c.count++
+		if c.maxSize > 0 && c.buffer.Len() > c.maxSize {
+			c.err = fmt.Errorf("%w: exceeded %v bytes", errorArtifactsTooLarge, c.maxSize)
+			return c.err
+		}
+	}
+}
+
+// copyContent copies the content of the current entry into the tarball;
+// when a maximum size is set, at most one byte more than the remaining
+// budget is read so that a large entry fails without being buffered
+func (c *artifactCollector) copyContent(content io.Reader, name string) error {
+	if c.maxSize <= 0 {
+		if _, err := io.Copy(c.tarWriter, content); err != nil {
+			return fmt.Errorf("failed to write content of path[%s]: %w", name, err)
+		}
+		return nil
+	}
+	remainingSize := int64(c.maxSize - c.buffer.Len())
+	if remainingSize < 0 {
+		remainingSize = 0
+	}
+	copiedSize, err := io.CopyN(c.tarWriter, content, remainingSize+1)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to write content of path[%s]: %w", name, err)
+	}
+	if copiedSize > remainingSize {
+		c.err = fmt.Errorf("%w: exceeded %v bytes", errorArtifactsTooLarge, c.maxSize)
+		return c.err
+	}
+	return nil
+}
+
+// Close finalises the tarball and returns its bytes; nil is returned if
+// no artifacts were collected
+func (c *artifactCollector) Close() ([]byte, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.err != nil {
+		return nil, c.err
+	}
+	if err := c.tarWriter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close tar writer: %w", err)
+	}
+	if err := c.gzipWriter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close gzip writer: %w", err)
+	}
+	if c.count == 0 {
+		return nil, nil
+	}
+	return c.buffer.Bytes(), nil
+}
+
+// collectPhaseOutputs copies the declared outputs of the phase out of
+// its container into the collector; outputs that do not exist are
+// reported but do not fail the phase
+func collectPhaseOutputs(ctx context.Context, dockerClient *client.Client, containerId string, spec automationSpec, phase automations.Phase) error {
+	if spec.Artifacts == nil || len(phase.Outputs) == 0 {
+		return nil
+	}
+	errs := []error{}
+	for _, output := range phase.Outputs {
+		outputPath := automations.GetOutputPath(output)
+		archive, _, err := dockerClient.CopyFromContainer(ctx, containerId, outputPath)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to copy output[%s]: %w", output, err))
+			continue
+		}
+		err = spec.Artifacts.Add(archive, outputPath, automations.GetArtifactPath(phase.Name, output))
+		archive.Close()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to collect output[%s]: %w", output, err))
+			if errors.Is(err, errorArtifactsTooLarge) {
+				break
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// collectLocalPhaseOutputs collects the declared outputs of the phase
+// from the workspace directory at `workspaceDir` on the worker; outputs
+// outside of the workspace are reported but do not fail the phase
+func collectLocalPhaseOutputs(workspaceDir string, spec automationSpec, phase automations.Phase) error {
+	if spec.Artifacts == nil || len(phase.Outputs) == 0 {
+		return nil
+	}
+	errs := []error{}
+	for _, output := range phase.Outputs {
+		outputPath := automations.GetOutputPath(output)
+		relativePath, isInWorkspace := strings.CutPrefix(outputPath, automations.WorkspacePath+"/")
+		if !isInWorkspace {
+			errs = append(errs, fmt.Errorf("failed to copy output[%s]: only outputs in the workspace can be collected", output))
+			continue
+		}
+		localPath := filepath.Join(workspaceDir, filepath.FromSlash(relativePath))
+		archive, err := archiveLocalPath(localPath)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to copy output[%s]: %w", output, err))
+			continue
+		}
+		if err := spec.Artifacts.Add(archive, outputPath, automations.GetArtifactPath(phase.Name, output)); err != nil {
+			errs = append(errs, fmt.Errorf("failed to collect output[%s]: %w", output, err))
+			if errors.Is(err, errorArtifactsTooLarge) {
+				break
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// archiveLocalPath returns a tar stream of the file or directory at
+// `localPath` rooted at its basename like archives from docker
+func archiveLocalPath(localPath string) (io.Reader, error) {
+	if _, err := os.Stat(localPath); err != nil {
+		return nil, err
+	}
+	var buffer bytes.Buffer
+	tarWriter := tar.NewWriter(&buffer)
+	parentPath := filepath.Dir(localPath)
+	err := filepath.Walk(localPath, func(filePath string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() && !info.IsDir() {
+			return nil
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		relativePath, err := filepath.Rel(parentPath, filePath)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(relativePath)
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		file, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(tarWriter, file)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := tarWriter.Close(); err != nil {
+		return nil, err
+	}
+	return &buffer, nil
+}
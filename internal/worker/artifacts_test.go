@@ -0,0 +1,58 @@
+package worker
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func newTestArchive(t *testing.T, name string, content []byte) *bytes.Buffer {
+	var archive bytes.Buffer
+	tarWriter := tar.NewWriter(&archive)
+	if err := tarWriter.WriteHeader(&tar.Header{
+		Name:     name,
+		Mode:     0644,
+		Size:     int64(len(content)),
+		Typeflag: tar.TypeReg,
+	}); err != nil {
+		t.Fatalf("failed to write header: %v", err)
+	}
+	if _, err := tarWriter.Write(content); err != nil {
+		t.Fatalf("failed to write content: %v", err)
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatalf("failed to close archive: %v", err)
+	}
+	return &archive
+}
+
+func TestArtifactCollectorAdd(t *testing.T) {
+	collector := newArtifactCollector(1024)
+	archive := newTestArchive(t, "result.txt", []byte("done\n"))
+	if err := collector.Add(archive, "/workspace/result.txt", "build/result.txt"); err != nil {
+		t.Fatalf("Add returned error: %v", err)
+	}
+	artifacts, err := collector.Close()
+	if err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	if len(artifacts) == 0 {
+		t.Fatalf("expected artifacts to be collected")
+	}
+}
+
+func TestArtifactCollectorAddRejectsEntryLargerThanMaxSize(t *testing.T) {
+	collector := newArtifactCollector(1024)
+	archive := newTestArchive(t, "large.bin", make([]byte, 1024*1024))
+	err := collector.Add(archive, "/workspace/large.bin", "build/large.bin")
+	if !errors.Is(err, errorArtifactsTooLarge) {
+		t.Fatalf("expected errorArtifactsTooLarge, got %v", err)
+	}
+	if collector.buffer.Len() > 2*1024 {
+		t.Fatalf("expected at most the budget to be buffered, got %v bytes", collector.buffer.Len())
+	}
+	if _, err := collector.Close(); !errors.Is(err, errorArtifactsTooLarge) {
+		t.Fatalf("expected Close to return errorArtifactsTooLarge, got %v", err)
+	}
+}
@@ -2,28 +2,68 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"opsicle/internal/automations"
 	"opsicle/internal/common"
-	"os"
-	"path"
-	"path/filepath"
-	"strings"
 	"sync"
 	"time"
-
-	"github.com/docker/docker/api/types/container"
-	"github.com/docker/docker/api/types/image"
-	"github.com/docker/docker/api/types/mount"
-	"github.com/docker/docker/client"
 )
 
 type RunAutomationOpts struct {
+	// ArtifactsHandler when defined receives the gzipped tarball of the
+	// outputs declared by phases after the automation completes; it is
+	// not called if no outputs were collected
+	ArtifactsHandler func(artifacts []byte) error
+
+	// Context when defined cancels the automation when it is done,
+	// phases in progress are stopped and the run's final status is
+	// `automations.RunStatusCancelled` with the context's cause as the
+	// message
+	Context context.Context
+
 	DockerApiVersion *string
 	Spec             *automations.Automation
 	AutomationLogs   chan string
 	ServiceLogs      chan common.ServiceLog
 	Done             *chan common.Done
+
+	// Kubernetes configures the Kubernetes runtime, it is only used
+	// when `.Runtime` is `common.RuntimeKubernetes`
+	Kubernetes *KubernetesConfig
+
+	// Process configures the process runtime, it is only used when
+	// `.Runtime` is `common.RuntimeProcess`
+	Process *ProcessConfig
+
+	// Runtime is the runtime used to execute phases, defaults to
+	// `common.RuntimeDocker` when not defined
+	Runtime string
+
+	// Policy restricts the settings which the automation's phases are
+	// allowed to use
+	Policy Policy
+
+	// RegistryCredentials are used to authenticate with registries when
+	// pulling images of phases
+	RegistryCredentials []RegistryCredential
+
+	// StopGracePeriod is how long phases are given to exit after being
+	// sent SIGTERM when the automation is cancelled, defaults to
+	// `DefaultStopGracePeriod` when not defined
+	StopGracePeriod time.Duration
+
+	// StatusUpdates when defined receives updates whenever the status
+	// of the automation or one of its phases changes
+	StatusUpdates chan<- automations.RunStatusUpdate
+
+	// WorkerId when defined is included in status updates
+	WorkerId string
+
+	// WorkspaceRetention is how long the workspace of the automation is
+	// kept after it completes so that re-runs of it on this worker can
+	// reuse it, the workspace is removed immediately when not defined
+	WorkspaceRetention time.Duration
 }
 
 func RunAutomation(opts RunAutomationOpts) error {
@@ -40,169 +80,362 @@ func RunAutomation(opts RunAutomationOpts) error {
 	defer func() {
 		doneChannel <- struct{}{}
 	}()
+	// failures before the automation starts are emitted as its final
+	// status so that the run does not remain queued
+	failRun := func(err error) error {
+		automationSpec{
+			Id:            opts.Spec.Spec.Status.Id,
+			StatusUpdates: opts.StatusUpdates,
+			WorkerId:      opts.WorkerId,
+		}.emitStatus(automations.RunStatusUpdate{
+			Status:  automations.RunStatusCompletedFailed,
+			Message: err.Error(),
+		})
+		return err
+	}
 	if opts.Spec.Metadata.Name == "" {
-		return fmt.Errorf("failed to receive a name, the name needs to be defined")
+		return failRun(fmt.Errorf("failed to receive a name, the name needs to be defined"))
+	}
+	if err := opts.Spec.Spec.Validate(); err != nil {
+		return failRun(fmt.Errorf("failed to validate automation: %w", err))
+	}
+	if err := opts.Spec.Spec.ValidateRuntime(opts.Runtime); err != nil {
+		return failRun(fmt.Errorf("failed to validate automation for runtime[%s]: %w", opts.Runtime, err))
+	}
+	variables := opts.Spec.Spec.Variables
+	vars, err := variables.Resolve(variables.GetValueMap())
+	if err != nil {
+		return failRun(fmt.Errorf("failed to validate variables: %w", err))
 	}
 	dockerApiVersion := DefaultDockerApiVersion
 	if opts.DockerApiVersion != nil {
 		dockerApiVersion = *opts.DockerApiVersion
 	}
 
-	if err := runAutomation(
-		automationSpec{
-			Phases:         opts.Spec.Spec.Phases,
-			VolumeMounts:   opts.Spec.Spec.VolumeMounts,
-			AutomationLogs: opts.AutomationLogs,
-			ServiceLogs:    opts.ServiceLogs,
-		},
-		runAutomationOpts{
-			DockerApiVersion: dockerApiVersion,
-		},
-	); err != nil {
-		return fmt.Errorf("failed to run automation: %w", err)
+	spec := automationSpec{
+		Id:                 opts.Spec.Spec.Status.Id,
+		Phases:             opts.Spec.Spec.Phases,
+		MaxParallelPhases:  opts.Spec.Spec.MaxParallelPhases,
+		VolumeMounts:       opts.Spec.Spec.VolumeMounts,
+		Env:                variables.GetEnv(vars),
+		SecretEnvNames:     variables.GetSecretEnvNames(),
+		Secrets:            variables.GetSecrets(vars),
+		Artifacts:          newArtifactCollector(automations.MaxArtifactsSize),
+		StopGracePeriod:    opts.StopGracePeriod,
+		Vars:               variables.GetTemplateVars(vars),
+		AutomationLogs:     opts.AutomationLogs,
+		ServiceLogs:        opts.ServiceLogs,
+		StatusUpdates:      opts.StatusUpdates,
+		WorkerId:           opts.WorkerId,
+		WorkspaceRetention: opts.WorkspaceRetention,
+	}
+	if resume := opts.Spec.Spec.Resume; resume != nil {
+		spec.ParentId = resume.ParentAutomationId
+		spec.ResumedPhases = map[string]automations.PhaseResult{}
+		for _, phaseResult := range resume.PhaseResults {
+			spec.ResumedPhases[phaseResult.Name] = phaseResult
+		}
+	}
+	if err := opts.Policy.Check(opts.Spec.Spec, opts.Runtime); err != nil {
+		spec.emitStatus(automations.RunStatusUpdate{
+			Status:  automations.RunStatusCompletedFailed,
+			Message: err.Error(),
+		})
+		return fmt.Errorf("failed to satisfy policy of worker: %w", err)
+	}
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if ctx.Err() != nil {
+		spec.emitStatus(automations.RunStatusUpdate{
+			Status:  automations.RunStatusCancelled,
+			Message: getCancellationMessage(ctx),
+		})
+		return fmt.Errorf("failed to run automation: %w", errorRunCancelled)
+	}
+	spec.emitStatus(automations.RunStatusUpdate{
+		Status:  automations.RunStatusExecuting,
+		Message: "automation started",
+	})
+	runErr := runAutomation(ctx, spec, runAutomationOpts{
+		DockerApiVersion:    dockerApiVersion,
+		Kubernetes:          opts.Kubernetes,
+		Process:             opts.Process,
+		RegistryCredentials: opts.RegistryCredentials,
+		Runtime:             opts.Runtime,
+	})
+	artifacts, err := spec.Artifacts.Close()
+	if err != nil {
+		spec.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "automation[%s]: failed to collect artifacts: %s", spec.Id, err)
+	} else if artifacts != nil && opts.ArtifactsHandler != nil {
+		if err := opts.ArtifactsHandler(artifacts); err != nil {
+			spec.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "automation[%s]: failed to handle artifacts: %s", spec.Id, err)
+		} else {
+			spec.ServiceLogs <- common.ServiceLogf(common.LogLevelInfo, "automation[%s]: uploaded %v bytes of artifacts", spec.Id, len(artifacts))
+		}
+	}
+	exitCode := 0
+	finalStatus := automations.RunStatusUpdate{
+		Status:   automations.RunStatusCompletedSuccess,
+		ExitCode: &exitCode,
+		Message:  "automation completed successfully",
+	}
+	if runErr != nil {
+		exitCode = 1
+		var phaseErr *phaseError
+		if errors.As(runErr, &phaseErr) && phaseErr.ExitCode != 0 {
+			exitCode = phaseErr.ExitCode
+		}
+		finalStatus.Status = automations.RunStatusCompletedFailed
+		finalStatus.Message = runErr.Error()
+		if errors.Is(runErr, errorRunCancelled) {
+			finalStatus.Status = automations.RunStatusCancelled
+			finalStatus.Message = getCancellationMessage(ctx)
+		}
+	}
+	spec.emitStatus(finalStatus)
+	if runErr != nil {
+		return fmt.Errorf("failed to run automation: %w", runErr)
 	}
 
 	return nil
 }
 
+// getCancellationMessage returns the message of a cancelled
+// automation's final status from the cause of its context
+func getCancellationMessage(ctx context.Context) string {
+	cause := context.Cause(ctx)
+	if cause == nil || errors.Is(cause, context.Canceled) {
+		return "automation was cancelled"
+	}
+	return cause.Error()
+}
+
 type runAutomationOpts struct {
-	DockerApiVersion string
+	DockerApiVersion    string
+	Kubernetes          *KubernetesConfig
+	Process             *ProcessConfig
+	RegistryCredentials []RegistryCredential
+	Runtime             string
 }
 
-func runAutomation(spec automationSpec, opts runAutomationOpts) error {
-	baseCtx := context.Background()
-	dockerClient, err := client.NewClientWithOpts(
-		client.FromEnv,
-		client.WithVersion(opts.DockerApiVersion),
-	)
+// runAutomation runs the phases of the automation using the runtime
+// identified by `.Runtime`; phases which have not started when
+// `baseCtx` is cancelled are not run and `errorRunCancelled` is
+// returned
+func runAutomation(baseCtx context.Context, spec automationSpec, opts runAutomationOpts) error {
+	runtime, err := newPhaseRuntime(newPhaseRuntimeOpts{
+		DockerApiVersion:    opts.DockerApiVersion,
+		Kubernetes:          opts.Kubernetes,
+		Process:             opts.Process,
+		RegistryCredentials: opts.RegistryCredentials,
+		Runtime:             opts.Runtime,
+	})
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to create runtime: %w", err)
+	}
+	retainedWorkspaces.Prune(context.Background(), spec.ServiceLogs)
+	if err := runtime.Setup(context.Background(), spec); err != nil {
+		return fmt.Errorf("failed to set up runtime: %w", err)
 	}
+	defer runtime.Teardown(context.Background(), spec)
+	spec.Env = append(spec.Env, fmt.Sprintf("%s=%s", automations.WorkspaceEnvName, automations.WorkspacePath))
 
-	var mounts []mount.Mount
-	for _, vm := range spec.VolumeMounts {
-		hostVolumePath := vm.Host
-		if !path.IsAbs(hostVolumePath) {
-			workingDirectory, err := os.Getwd()
-			if err != nil {
-				return fmt.Errorf("failed to get working directory: %w", err)
+	var phaseResultsMutex sync.Mutex
+	phaseResults := map[string]automations.PhaseResult{}
+	graphErr := runPhaseGraph(runPhaseGraphOpts{
+		Spec: automations.AutomationSpec{
+			Phases:            spec.Phases,
+			MaxParallelPhases: spec.MaxParallelPhases,
+		},
+		Run: func(phase automations.Phase) phaseOutcome {
+			if baseCtx.Err() != nil {
+				phaseResult := getCancelledPhaseOutcome(phase.Name, 0, nil)
+				spec.emitStatus(automations.RunStatusUpdate{Phase: &phaseResult.Result})
+				return phaseResult
 			}
-			hostVolumePath = filepath.Join(workingDirectory, hostVolumePath)
-		}
-		mounts = append(mounts, mount.Mount{
-			Type:   mount.TypeBind,
-			Source: hostVolumePath,
-			Target: vm.Container,
-		})
+			phaseResultsMutex.Lock()
+			conditionData := automations.GetConditionData(spec.Vars, phaseResults)
+			phaseResultsMutex.Unlock()
+			phaseResult, shouldRun := evaluatePhaseCondition(phase, conditionData)
+			if resumedResult, isResumed := spec.ResumedPhases[phase.Name]; isResumed {
+				phaseResult = phaseOutcome{Result: resumedResult}
+				phaseResult.Result.Message = fmt.Sprintf("reused result from automation[%s]", spec.ParentId)
+				spec.ServiceLogs <- common.ServiceLogf(common.LogLevelInfo, "phase[%s]: not running: %s", phase.Name, phaseResult.Result.Message)
+			} else if shouldRun {
+				phaseResult = runPhaseWithRetries(baseCtx, runtime, spec, phase)
+			} else {
+				spec.ServiceLogs <- common.ServiceLogf(common.LogLevelInfo, "phase[%s]: not running: %s", phase.Name, phaseResult.Result.Message)
+			}
+			spec.emitStatus(automations.RunStatusUpdate{Phase: &phaseResult.Result})
+			phaseResultsMutex.Lock()
+			phaseResults[phase.Name] = phaseResult.Result
+			phaseResultsMutex.Unlock()
+			return phaseResult
+		},
+		Skip: func(phase automations.Phase, failedPhase string) {
+			if baseCtx.Err() != nil {
+				phaseResult := getCancelledPhaseOutcome(phase.Name, 0, nil)
+				spec.emitStatus(automations.RunStatusUpdate{Phase: &phaseResult.Result})
+				return
+			}
+			spec.emitStatus(automations.RunStatusUpdate{
+				Phase: &automations.PhaseResult{
+					Name:    phase.Name,
+					Status:  automations.PhaseStatusSkipped,
+					Message: fmt.Sprintf("skipped due to failure of phase[%s]", failedPhase),
+				},
+			})
+		},
+		ServiceLogs: spec.ServiceLogs,
+	})
+	if baseCtx.Err() != nil {
+		return errorRunCancelled
 	}
+	return graphErr
+}
 
-	for _, phase := range spec.Phases {
-		spec.ServiceLogs <- common.ServiceLogf(common.LogLevelInfo, "phase[%s]: starting", phase.Name)
+type phaseOutcome struct {
+	Result   automations.PhaseResult
+	ExitCode int
+	Err      error
+}
 
-		var timeout time.Duration
-		if phase.Timeout == 0 {
-			timeout = 60 * time.Second
-		} else {
-			timeout = time.Duration(phase.Timeout) * time.Second
-		}
-		phaseCtx, cancel := context.WithTimeout(baseCtx, timeout)
-		defer cancel()
+// evaluatePhaseCondition evaluates the `when` condition of the phase
+// against the provided data; when the phase should not run, the
+// returned outcome is a skipped result if the condition evaluated to
+// false or a failed result if it could not be evaluated
+func evaluatePhaseCondition(phase automations.Phase, data map[string]any) (phaseOutcome, bool) {
+	if phase.When == "" {
+		return phaseOutcome{}, true
+	}
+	evaluatedAt := time.Now()
+	condition, err := automations.ParseCondition(phase.When)
+	shouldRun := false
+	if err == nil {
+		shouldRun, err = condition.Evaluate(data)
+	}
+	if err != nil {
+		return phaseOutcome{
+			Result: automations.PhaseResult{
+				Name:        phase.Name,
+				Status:      automations.PhaseStatusFailed,
+				Message:     err.Error(),
+				CompletedAt: &evaluatedAt,
+			},
+			Err: err,
+		}, false
+	}
+	if !shouldRun {
+		return phaseOutcome{
+			Result: automations.PhaseResult{
+				Name:        phase.Name,
+				Status:      automations.PhaseStatusSkipped,
+				Message:     fmt.Sprintf("condition '%s' evaluated to false", phase.When),
+				CompletedAt: &evaluatedAt,
+			},
+		}, false
+	}
+	return phaseOutcome{}, true
+}
 
-		_, err := dockerClient.ImagePull(phaseCtx, phase.Image, image.PullOptions{})
-		if err != nil {
-			return fmt.Errorf("failed to pull image %s: %w", phase.Image, err)
+// runPhaseWithRetries runs the phase until it succeeds or until it
+// has been retried `.Retries` times, emitting status updates for each
+// attempt
+func runPhaseWithRetries(baseCtx context.Context, runtime phaseRuntime, spec automationSpec, phase automations.Phase) phaseOutcome {
+	maxAttempts := phase.Retries + 1
+	phaseStartedAt := time.Now()
+	var outcome phaseOutcome
+	renderedPhase, err := phase.Render(spec.Vars)
+	if err != nil {
+		return phaseOutcome{
+			Result: automations.PhaseResult{
+				Name:        phase.Name,
+				Status:      automations.PhaseStatusFailed,
+				Message:     err.Error(),
+				CompletedAt: &phaseStartedAt,
+			},
+			Err: err,
 		}
-
-		containerInfo, err := dockerClient.ContainerCreate(phaseCtx, &container.Config{
-			Image: phase.Image,
-			Cmd:   []string{"sh", "-c", strings.Join(phase.Commands, " && ")},
-			Tty:   false,
-		}, &container.HostConfig{
-			Mounts: mounts,
-		}, nil, nil, "")
-		if err != nil {
-			return err
+	}
+	phase = renderedPhase
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		spec.ServiceLogs <- common.ServiceLogf(common.LogLevelInfo, "phase[%s]: starting attempt %v/%v", phase.Name, attempt, maxAttempts)
+		spec.emitStatus(automations.RunStatusUpdate{
+			Phase: &automations.PhaseResult{
+				Name:      phase.Name,
+				Status:    automations.PhaseStatusRunning,
+				Attempts:  attempt,
+				StartedAt: &phaseStartedAt,
+			},
+		})
+		exitCode, err := runtime.RunPhase(baseCtx, spec, phase)
+		if baseCtx.Err() != nil {
+			return getCancelledPhaseOutcome(phase.Name, attempt, &phaseStartedAt)
 		}
-
-		displayContainerId := containerInfo.ID[:11]
-
-		if err := dockerClient.ContainerStart(phaseCtx, containerInfo.ID, container.StartOptions{}); err != nil {
-			return err
+		if err == nil && !phase.IsExitCodeAllowed(exitCode) {
+			err = fmt.Errorf("container exited with status %d", exitCode)
 		}
-
-		containerLogs := make(chan string, 128)
-		done := make(chan common.Done)
-		dockerLogStreamingOpts := streamDockerLogsOpts{
-			ContainerId:    containerInfo.ID,
-			ServiceLogs:    spec.ServiceLogs,
-			AutomationLogs: containerLogs,
-			DockerClient:   dockerClient,
-			DoneChannel:    done,
+		phaseCompletedAt := time.Now()
+		outcome = phaseOutcome{
+			Result: automations.PhaseResult{
+				Name:        phase.Name,
+				Status:      automations.PhaseStatusSucceeded,
+				Attempts:    attempt,
+				ExitCode:    &exitCode,
+				CompletedAt: &phaseCompletedAt,
+			},
+			ExitCode: exitCode,
+			Err:      err,
 		}
-		go func(dockerLogStreamingOpts streamDockerLogsOpts) {
-			if err := streamDockerLogs(dockerLogStreamingOpts); err != nil {
-				spec.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "phase[%s]: failed to stream logs for container[%s]: %s", phase.Name, displayContainerId, err)
-			}
-		}(dockerLogStreamingOpts)
-
-		containerResponses, containerErrors := dockerClient.ContainerWait(
-			phaseCtx,
-			containerInfo.ID,
-			container.WaitConditionNotRunning,
-		)
-
-		var waiter sync.WaitGroup
-		waiter.Add(1)
-		go func() {
-			defer waiter.Done()
-			spec.ServiceLogs <- common.ServiceLogf(common.LogLevelInfo, "phase[%s]: started streaming logs for container[%s]", phase.Name, displayContainerId)
-			var phaseLogsMutex sync.Mutex
-			for {
-				containerLog, ok := <-containerLogs
-				if !ok {
-					break
-				}
-				spec.AutomationLogs <- containerLog
-				phaseLogsMutex.Lock()
-				phase.Logs = append(phase.Logs, automations.PhaseLog{
-					Timestamp: time.Now().Format("2006-01-02T15:04:05"),
-					Message:   containerLog,
-				})
-				phaseLogsMutex.Unlock()
-			}
-		}()
-		waiter.Add(1)
-		go func() {
-			defer waiter.Done()
-			isDone := false
-			for !isDone {
-				select {
-				case <-phaseCtx.Done():
-					spec.ServiceLogs <- common.ServiceLogf(common.LogLevelWarn, "phase[%s]: timed out, killing container[%s]...", phase.Name, displayContainerId)
-					if err := dockerClient.ContainerKill(context.Background(), containerInfo.ID, "SIGKILL"); err != nil {
-						spec.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "phase[%s]: timed out but container[%s] failed to be killed", phase.Name, displayContainerId)
-					} else {
-						spec.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "phase[%s] timed out and container[%s] was killed", phase.Name, displayContainerId)
-					}
-				case err := <-containerErrors:
-					if err != nil {
-						spec.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "container[%s]: encountered error: %s", displayContainerId, err)
-					}
-				case <-done:
-					isDone = true
-				case status := <-containerResponses:
-					if status.StatusCode != 0 {
-						spec.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "container[%s]: exited with status %d", displayContainerId, status.StatusCode)
-						isDone = true
-					}
-				}
+		if err == nil {
+			return outcome
+		}
+		outcome.Result.Status = automations.PhaseStatusFailed
+		outcome.Result.Message = err.Error()
+		if attempt < maxAttempts {
+			backoff := phase.GetRetryBackoff()
+			spec.ServiceLogs <- common.ServiceLogf(common.LogLevelWarn, "phase[%s]: attempt %v/%v failed, retrying in %v: %s", phase.Name, attempt, maxAttempts, backoff, err)
+			select {
+			case <-baseCtx.Done():
+				return getCancelledPhaseOutcome(phase.Name, attempt, &phaseStartedAt)
+			case <-time.After(backoff):
 			}
-			spec.ServiceLogs <- common.ServiceLogf(common.LogLevelInfo, "phase[%s]: container[%s] is done", phase.Name, displayContainerId)
-			<-time.After(1 * time.Second)
-			close(containerLogs)
-		}()
-		waiter.Wait()
+		}
 	}
+	return outcome
+}
 
-	return nil
+// getCancelledPhaseOutcome returns the outcome of a phase which was
+// stopped or not started because the automation was cancelled
+func getCancelledPhaseOutcome(phaseName string, attempts int, startedAt *time.Time) phaseOutcome {
+	cancelledAt := time.Now()
+	return phaseOutcome{
+		Result: automations.PhaseResult{
+			Name:        phaseName,
+			Status:      automations.PhaseStatusCancelled,
+			Attempts:    attempts,
+			Message:     "phase was cancelled",
+			StartedAt:   startedAt,
+			CompletedAt: &cancelledAt,
+		},
+		Err: errorRunCancelled,
+	}
+}
+
+// phaseError is returned when a phase fails to execute or its
+// container exits with a non-zero status
+type phaseError struct {
+	Phase    string
+	ExitCode int
+	Err      error
+}
+
+func (e *phaseError) Error() string {
+	return fmt.Sprintf("phase[%s]: %s", e.Phase, e.Err)
+}
+
+func (e *phaseError) Unwrap() error {
+	return e.Err
 }
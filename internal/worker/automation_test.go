@@ -0,0 +1,39 @@
+package worker
+
+import (
+	"opsicle/internal/automations"
+	"opsicle/internal/common"
+	"testing"
+)
+
+func TestRunAutomationEmitsFailureOfInvalidAutomation(t *testing.T) {
+	statusUpdates := make(chan automations.RunStatusUpdate, 1)
+	spec := &automations.Automation{
+		Spec: automations.AutomationSpec{
+			Status: automations.AutomationStatus{Id: "automation-id"},
+		},
+	}
+	err := RunAutomation(RunAutomationOpts{
+		Spec:          spec,
+		ServiceLogs:   make(chan common.ServiceLog, 8),
+		StatusUpdates: statusUpdates,
+		WorkerId:      "worker-id",
+	})
+	if err == nil {
+		t.Fatalf("expected an automation without a name to fail")
+	}
+	select {
+	case update := <-statusUpdates:
+		if update.Status != automations.RunStatusCompletedFailed {
+			t.Fatalf("expected status[%s], got %s", automations.RunStatusCompletedFailed, update.Status)
+		}
+		if update.AutomationId != "automation-id" || update.WorkerId != "worker-id" {
+			t.Fatalf("expected the update to identify the run, got %+v", update)
+		}
+		if update.Message != err.Error() {
+			t.Fatalf("expected message[%s], got %s", err.Error(), update.Message)
+		}
+	default:
+		t.Fatalf("expected a status update to be emitted")
+	}
+}
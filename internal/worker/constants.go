@@ -1,8 +1,33 @@
 package worker
 
+import "time"
+
 const (
 	DefaultBufferSize       = 1024
 	DefaultDockerApiVersion = "1.49"
 	DefaultIsStderrEnabled  = true
 	DefaultIsStdoutEnabled  = true
+
+	// DefaultCancellationWaitDuration is how long the worker asks the
+	// coordinator to hold each request for cancellations open for
+	DefaultCancellationWaitDuration = 20 * time.Second
+
+	// DefaultDrainTimeout is how long the worker waits for automations
+	// in progress to complete after it is told to exit before they are
+	// cancelled
+	DefaultDrainTimeout = 5 * time.Minute
+
+	// DefaultImagePullTimeout is how long the docker runtime waits for
+	// the image of a phase to be pulled before the phase fails
+	DefaultImagePullTimeout = 10 * time.Minute
+
+	// DefaultStopGracePeriod is how long a phase is given to exit after
+	// receiving SIGTERM before it is sent SIGKILL
+	DefaultStopGracePeriod = 10 * time.Second
+
+	DefaultKubernetesDeadlineGracePeriod = 30 * time.Second
+	DefaultKubernetesLogsGracePeriod     = 5 * time.Second
+	DefaultKubernetesNamespace           = "default"
+	DefaultKubernetesPollInterval        = 2 * time.Second
+	DefaultKubernetesWorkspaceSize       = "1Gi"
 )
@@ -0,0 +1,204 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"opsicle/internal/automations"
+	"opsicle/internal/common"
+	"opsicle/pkg/coordinator"
+	"os"
+	"strings"
+	"time"
+)
+
+type startCoordinatorQueueLoopOpts struct {
+	Client       *coordinator.Client
+	Done         chan common.Done
+	Handler      func(automation *automations.Automation) error
+	PollInterval time.Duration
+	Runtime      string
+	ServiceLogs  chan common.ServiceLog
+	WorkerId     string
+}
+
+// startCoordinatorQueueLoop registers the worker with the coordinator and
+// long-polls it for automations to run until a signal is received on
+// `.Done`; automations are processed one at a time so that the worker does
+// not pull more jobs than it can run
+func startCoordinatorQueueLoop(opts startCoordinatorQueueLoopOpts) error {
+	if opts.Client == nil {
+		return fmt.Errorf("failed to receive a coordinator client")
+	}
+	if opts.Handler == nil {
+		return fmt.Errorf("failed to receive a handler")
+	}
+	exitSignal := make(chan struct{})
+	go func() {
+		<-opts.Done
+		opts.ServiceLogs <- common.ServiceLogf(common.LogLevelInfo, "triggering exit sequence...")
+		close(exitSignal)
+	}()
+	isDone := func() bool {
+		select {
+		case <-exitSignal:
+			return true
+		default:
+			return false
+		}
+	}
+	waitForNextPoll := func() {
+		opts.ServiceLogs <- common.ServiceLogf(common.LogLevelDebug, "waiting %v before next poll...", opts.PollInterval)
+		select {
+		case <-exitSignal:
+		case <-time.After(opts.PollInterval):
+		}
+	}
+
+	hostname, _ := os.Hostname()
+	workerId := opts.WorkerId
+	for !isDone() {
+		opts.ServiceLogs <- common.ServiceLogf(common.LogLevelDebug, "registering worker[%s] with coordinator[%s]...", workerId, opts.Client.CoordinatorUrl.String())
+		initOutput, err := opts.Client.InitWorkerV1(coordinator.InitWorkerV1Input{
+			Hostname: hostname,
+			Runtime:  opts.Runtime,
+			WorkerId: workerId,
+		})
+		if err != nil {
+			opts.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "failed to register with coordinator: %s", err)
+			waitForNextPoll()
+			continue
+		}
+		workerId = initOutput.Data.WorkerId
+		opts.ServiceLogs <- common.ServiceLogf(common.LogLevelInfo, "registered as worker[%s] of org[%s]", workerId, initOutput.Data.OrgId)
+		break
+	}
+
+	for !isDone() {
+		opts.ServiceLogs <- common.ServiceLogf(common.LogLevelTrace, "checking coordinator for new automations...")
+		jobOutput, err := opts.Client.GetJobV1(coordinator.GetJobV1Input{WorkerId: workerId})
+		if err != nil {
+			opts.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "failed to get job from coordinator: %s", err)
+			waitForNextPoll()
+			continue
+		}
+		if !jobOutput.Data.IsAvailable || jobOutput.Data.Automation == nil {
+			opts.ServiceLogs <- common.ServiceLogf(common.LogLevelDebug, "no pending automations found at coordinator")
+			continue
+		}
+		automation := jobOutput.Data.Automation
+		opts.ServiceLogs <- common.ServiceLogf(common.LogLevelInfo, "received automation[%s]", automation.Spec.Status.Id)
+		if err := opts.Handler(automation); err != nil {
+			opts.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "failed to process automation[%s]: %s", automation.Spec.Status.Id, err)
+		}
+	}
+	opts.ServiceLogs <- common.ServiceLogf(common.LogLevelInfo, "exitted execution loop gracefully")
+	return nil
+}
+
+const (
+	// DefaultLogsFlushInterval is the maximum duration logs are buffered
+	// for before they are sent to the coordinator
+	DefaultLogsFlushInterval = 1 * time.Second
+
+	// DefaultLogsFlushSizeBytes is the size of buffered logs that will
+	// trigger an immediate send to the coordinator
+	DefaultLogsFlushSizeBytes = 64 * 1024
+)
+
+type shipCoordinatorLogsOpts struct {
+	AutomationId string
+	Client       *coordinator.Client
+	Forward      chan<- string
+	Logs         <-chan string
+	ServiceLogs  chan common.ServiceLog
+	WorkerId     string
+}
+
+// shipCoordinatorLogs batches logs received on `.Logs` and sends them to
+// the coordinator until `.Logs` is closed; logs are also forwarded to
+// `.Forward` if it is defined
+func shipCoordinatorLogs(opts shipCoordinatorLogsOpts) {
+	var buffer strings.Builder
+	flush := func() {
+		if buffer.Len() == 0 {
+			return
+		}
+		if _, err := opts.Client.PushJobLogsV1(automations.LogChunk{
+			AutomationId: opts.AutomationId,
+			WorkerId:     opts.WorkerId,
+			Data:         buffer.String(),
+			Timestamp:    time.Now(),
+		}); err != nil {
+			opts.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "failed to send %v bytes of logs of automation[%s]: %s", buffer.Len(), opts.AutomationId, err)
+		}
+		buffer.Reset()
+	}
+	ticker := time.NewTicker(DefaultLogsFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case automationLog, ok := <-opts.Logs:
+			if !ok {
+				flush()
+				return
+			}
+			if opts.Forward != nil {
+				opts.Forward <- automationLog
+			}
+			buffer.WriteString(automationLog)
+			if buffer.Len() >= DefaultLogsFlushSizeBytes {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+type watchCoordinatorCancellationOpts struct {
+	AutomationId string
+	Client       *coordinator.Client
+
+	// Cancel is called with the cancellation's message as the cause
+	// when the automation run is cancelled
+	Cancel context.CancelCauseFunc
+
+	// Context stops the watch when it is done, this should be done when
+	// the automation run completes
+	Context      context.Context
+	PollInterval time.Duration
+	ServiceLogs  chan common.ServiceLog
+}
+
+// watchCoordinatorCancellation long-polls the coordinator for the
+// cancellation of the automation run identified by `.AutomationId` and
+// calls `.Cancel` when it is received
+func watchCoordinatorCancellation(opts watchCoordinatorCancellationOpts) {
+	for opts.Context.Err() == nil {
+		cancellationOutput, err := opts.Client.GetJobCancellationV1(coordinator.GetJobCancellationV1Input{
+			AutomationId: opts.AutomationId,
+			Wait:         DefaultCancellationWaitDuration,
+		})
+		if opts.Context.Err() != nil {
+			return
+		}
+		if err != nil {
+			opts.ServiceLogs <- common.ServiceLogf(common.LogLevelWarn, "failed to check cancellation of automation[%s]: %s", opts.AutomationId, err)
+			select {
+			case <-opts.Context.Done():
+			case <-time.After(opts.PollInterval):
+			}
+			continue
+		}
+		if cancellationOutput.Data.IsCancelled {
+			message := "automation was cancelled"
+			if cancellation := cancellationOutput.Data.Cancellation; cancellation != nil {
+				message = cancellation.GetMessage()
+			}
+			opts.ServiceLogs <- common.ServiceLogf(common.LogLevelWarn, "automation[%s]: %s, stopping it...", opts.AutomationId, message)
+			opts.Cancel(errors.New(message))
+			return
+		}
+	}
+}
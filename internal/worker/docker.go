@@ -2,13 +2,28 @@ package worker
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"opsicle/internal/automations"
 	"opsicle/internal/common"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
 	"sync"
+	"time"
 
+	cerrdefs "github.com/containerd/errdefs"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/volume"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/jsonmessage"
 	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/google/uuid"
 )
 
 // streamDockerLogsOpts provides options for the streamDockerLogs
@@ -24,7 +39,7 @@ type streamDockerLogsOpts struct {
 
 	// AutomationLogs is for the caller to receive logs from the
 	// **container**, NOT the function
-	AutomationLogs chan string
+	AutomationLogs chan phaseOutput
 
 	// Context is for inheritance of the caller's context
 	Context *context.Context
@@ -108,7 +123,7 @@ func streamDockerLogs(opts streamDockerLogsOpts) error {
 			n, err := outReader.Read(buffer)
 			if n > 0 {
 				opts.ServiceLogs <- common.ServiceLogf(common.LogLevelTrace, "container[%s]: streamed %v bytes from stdout", displayContainerId, n)
-				opts.AutomationLogs <- string(buffer[:n])
+				opts.AutomationLogs <- phaseOutput{Text: string(buffer[:n])}
 			}
 			if err != nil {
 				if err != io.EOF {
@@ -131,7 +146,7 @@ func streamDockerLogs(opts streamDockerLogsOpts) error {
 			n, err := errReader.Read(buffer)
 			if n > 0 {
 				opts.ServiceLogs <- common.ServiceLogf(common.LogLevelTrace, "container[%s]: streamed %v bytes from stderr", displayContainerId, n)
-				opts.AutomationLogs <- prefixWithStderr(string(buffer[:n]))
+				opts.AutomationLogs <- phaseOutput{Text: string(buffer[:n]), IsStderr: true}
 			}
 			if err != nil {
 				if err != io.EOF {
@@ -151,6 +166,325 @@ func streamDockerLogs(opts streamDockerLogsOpts) error {
 	return nil
 }
 
-func prefixWithStderr(text string) string {
-	return "[stderr] " + text
+// dockerRuntime runs each phase in a Docker container on the host of
+// the worker with a shared volume mounted as the workspace
+type dockerRuntime struct {
+	client *client.Client
+	mounts []mount.Mount
+
+	// registryCredentials are used when pulling images of phases
+	registryCredentials []RegistryCredential
+
+	// workspaceVolume is the name of the volume created in Setup
+	workspaceVolume string
+}
+
+func newDockerRuntime(dockerApiVersion string, registryCredentials []RegistryCredential) (*dockerRuntime, error) {
+	dockerClient, err := client.NewClientWithOpts(
+		client.FromEnv,
+		client.WithVersion(dockerApiVersion),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &dockerRuntime{
+		client:              dockerClient,
+		registryCredentials: registryCredentials,
+	}, nil
+}
+
+func (r *dockerRuntime) Setup(ctx context.Context, spec automationSpec) error {
+	if parentWorkspace, ok := spec.takeParentWorkspace(common.RuntimeDocker); ok {
+		r.workspaceVolume = parentWorkspace.Name
+	} else {
+		workspaceVolume, err := r.client.VolumeCreate(ctx, volume.CreateOptions{
+			Name: fmt.Sprintf("opsicle-workspace-%s", uuid.NewString()),
+			Labels: map[string]string{
+				"opsicle.io/automation-id": spec.Id,
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create workspace volume: %w", err)
+		}
+		r.workspaceVolume = workspaceVolume.Name
+	}
+	r.mounts = []mount.Mount{
+		{
+			Type:   mount.TypeVolume,
+			Source: r.workspaceVolume,
+			Target: automations.WorkspacePath,
+		},
+	}
+	for _, vm := range spec.VolumeMounts {
+		hostVolumePath := vm.Host
+		if !path.IsAbs(hostVolumePath) {
+			workingDirectory, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("failed to get working directory: %w", err)
+			}
+			hostVolumePath = filepath.Join(workingDirectory, hostVolumePath)
+		}
+		r.mounts = append(r.mounts, mount.Mount{
+			Type:   mount.TypeBind,
+			Source: hostVolumePath,
+			Target: vm.Container,
+		})
+	}
+	return nil
+}
+
+// pullImage ensures that the image of the phase is present according
+// to its pull policy and returns the image's reference by digest
+func (r *dockerRuntime) pullImage(ctx context.Context, spec automationSpec, phase automations.Phase) (string, error) {
+	pullPolicy := phase.GetImagePullPolicy()
+	if pullPolicy != automations.ImagePullPolicyAlways {
+		imageInfo, err := r.client.ImageInspect(ctx, phase.Image)
+		if err == nil {
+			spec.ServiceLogs <- common.ServiceLogf(common.LogLevelDebug, "phase[%s]: image %s is present, not pulling", phase.Name, phase.Image)
+			return getImageDigest(phase.Image, imageInfo.RepoDigests), nil
+		}
+		if !cerrdefs.IsNotFound(err) {
+			return "", fmt.Errorf("failed to inspect image %s: %w", phase.Image, err)
+		}
+		if pullPolicy == automations.ImagePullPolicyNever {
+			return "", fmt.Errorf("image %s is not present and imagePullPolicy is %s", phase.Image, pullPolicy)
+		}
+	}
+	registryAuth, err := getRegistryAuth(r.registryCredentials, phase.Image)
+	if err != nil {
+		return "", err
+	}
+	spec.ServiceLogs <- common.ServiceLogf(common.LogLevelInfo, "phase[%s]: pulling image %s...", phase.Name, phase.Image)
+	pullProgress, err := r.client.ImagePull(ctx, phase.Image, image.PullOptions{RegistryAuth: registryAuth})
+	if err != nil {
+		return "", fmt.Errorf("failed to pull image %s: %w", phase.Image, err)
+	}
+	defer pullProgress.Close()
+	decoder := json.NewDecoder(pullProgress)
+	for {
+		var message jsonmessage.JSONMessage
+		if err := decoder.Decode(&message); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return "", fmt.Errorf("failed to read progress of pulling image %s: %w", phase.Image, err)
+		}
+		if message.Error != nil {
+			return "", fmt.Errorf("failed to pull image %s: %s", phase.Image, message.Error.Message)
+		}
+		spec.ServiceLogs <- common.ServiceLogf(common.LogLevelTrace, "phase[%s]: pulling image %s: %s %s", phase.Name, phase.Image, message.Status, message.ID)
+	}
+	imageInfo, err := r.client.ImageInspect(ctx, phase.Image)
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect image %s: %w", phase.Image, err)
+	}
+	imageDigest := getImageDigest(phase.Image, imageInfo.RepoDigests)
+	spec.ServiceLogs <- common.ServiceLogf(common.LogLevelInfo, "phase[%s]: pulled image %s as %s", phase.Name, phase.Image, imageDigest)
+	return imageDigest, nil
+}
+
+// getHostConfig returns the host configuration of the phase's
+// container with its resource limits and security settings applied
+func (r *dockerRuntime) getHostConfig(phase automations.Phase) (*container.HostConfig, error) {
+	hostConfig := &container.HostConfig{
+		Mounts:         r.mounts,
+		NetworkMode:    container.NetworkMode(phase.Network),
+		ReadonlyRootfs: phase.ReadOnlyRootFs,
+		CapDrop:        phase.CapDrop,
+		Privileged:     phase.Privileged,
+	}
+	if phase.Resources != nil {
+		nanoCpus, err := phase.Resources.GetNanoCpus()
+		if err != nil {
+			return nil, err
+		}
+		memory, err := phase.Resources.GetMemoryBytes()
+		if err != nil {
+			return nil, err
+		}
+		hostConfig.Resources.NanoCPUs = nanoCpus
+		hostConfig.Resources.Memory = memory
+		if phase.Resources.Pids > 0 {
+			hostConfig.Resources.PidsLimit = &phase.Resources.Pids
+		}
+	}
+	return hostConfig, nil
+}
+
+func (r *dockerRuntime) Teardown(ctx context.Context, spec automationSpec) {
+	if r.workspaceVolume == "" {
+		return
+	}
+	workspaceVolume := r.workspaceVolume
+	if spec.retainWorkspace(common.RuntimeDocker, workspaceVolume, func(ctx context.Context) error {
+		return r.client.VolumeRemove(ctx, workspaceVolume, true)
+	}) {
+		return
+	}
+	if err := r.client.VolumeRemove(ctx, r.workspaceVolume, true); err != nil {
+		spec.ServiceLogs <- common.ServiceLogf(common.LogLevelWarn, "failed to remove workspace volume[%s]: %s", r.workspaceVolume, err)
+	}
+}
+
+// RunPhase executes a single phase and returns the exit code of its
+// container; an error is returned if the container could not be run
+// to completion
+func (r *dockerRuntime) RunPhase(baseCtx context.Context, spec automationSpec, phase automations.Phase) (int, error) {
+	// the image is pulled with its own timeout before the timeout of
+	// the phase starts so that the time taken to pull it does not count
+	// against the phase while a stalled pull does not hang the phase
+	pullCtx, cancelPull := context.WithTimeout(baseCtx, DefaultImagePullTimeout)
+	imageDigest, err := r.pullImage(pullCtx, spec, phase)
+	isPullTimedOut := errors.Is(pullCtx.Err(), context.DeadlineExceeded)
+	cancelPull()
+	if err != nil {
+		if baseCtx.Err() != nil {
+			return 0, errorRunCancelled
+		}
+		if isPullTimedOut {
+			return 0, fmt.Errorf("failed to pull image %s within %v: %w", phase.Image, DefaultImagePullTimeout, err)
+		}
+		return 0, err
+	}
+	if imageDigest != "" {
+		spec.emitStatus(automations.RunStatusUpdate{
+			Phase: &automations.PhaseResult{Name: phase.Name, ImageDigest: imageDigest},
+		})
+	}
+
+	var timeout time.Duration
+	if phase.Timeout == 0 {
+		timeout = 60 * time.Second
+	} else {
+		timeout = time.Duration(phase.Timeout) * time.Second
+	}
+	phaseCtx, cancel := context.WithTimeout(baseCtx, timeout)
+	defer cancel()
+
+	hostConfig, err := r.getHostConfig(phase)
+	if err != nil {
+		return 0, err
+	}
+	containerInfo, err := r.client.ContainerCreate(phaseCtx, &container.Config{
+		Image: phase.Image,
+		Cmd:   []string{"sh", "-c", strings.Join(phase.Commands, " && ")},
+		Env:   spec.Env,
+		User:  phase.User,
+		Tty:   false,
+	}, hostConfig, nil, nil, "")
+	if err != nil {
+		return 0, err
+	}
+	// the container is removed once its outputs are collected so that
+	// the workspace volume is no longer in use when it is removed
+	defer r.removeContainer(spec, phase, containerInfo.ID)
+
+	displayContainerId := containerInfo.ID[:11]
+
+	if err := r.client.ContainerStart(phaseCtx, containerInfo.ID, container.StartOptions{}); err != nil {
+		return 0, err
+	}
+
+	containerLogs := make(chan phaseOutput, 128)
+	done := make(chan common.Done)
+	dockerLogStreamingOpts := streamDockerLogsOpts{
+		ContainerId:    containerInfo.ID,
+		ServiceLogs:    spec.ServiceLogs,
+		AutomationLogs: containerLogs,
+		DockerClient:   r.client,
+		DoneChannel:    done,
+	}
+	go func(dockerLogStreamingOpts streamDockerLogsOpts) {
+		if err := streamDockerLogs(dockerLogStreamingOpts); err != nil {
+			spec.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "phase[%s]: failed to stream logs for container[%s]: %s", phase.Name, displayContainerId, err)
+		}
+	}(dockerLogStreamingOpts)
+
+	containerResponses, containerErrors := r.client.ContainerWait(
+		phaseCtx,
+		containerInfo.ID,
+		container.WaitConditionNotRunning,
+	)
+
+	var waiter sync.WaitGroup
+	waiter.Add(1)
+	go func() {
+		defer waiter.Done()
+		spec.ServiceLogs <- common.ServiceLogf(common.LogLevelInfo, "phase[%s]: started streaming logs for container[%s]", phase.Name, displayContainerId)
+		forwardPhaseLogs(spec, &phase, containerLogs)
+	}()
+	exitCode := 0
+	var phaseErr error
+	waiter.Add(1)
+	go func() {
+		defer waiter.Done()
+		isDone := false
+		timedOut := phaseCtx.Done()
+		for !isDone {
+			select {
+			case <-timedOut:
+				timedOut = nil
+				if baseCtx.Err() != nil {
+					phaseErr = errorRunCancelled
+					r.stopContainer(spec, phase, containerInfo.ID)
+					continue
+				}
+				phaseErr = fmt.Errorf("timed out after %v", timeout)
+				spec.ServiceLogs <- common.ServiceLogf(common.LogLevelWarn, "phase[%s]: timed out, killing container[%s]...", phase.Name, displayContainerId)
+				if err := r.client.ContainerKill(context.Background(), containerInfo.ID, "SIGKILL"); err != nil {
+					spec.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "phase[%s]: timed out but container[%s] failed to be killed", phase.Name, displayContainerId)
+				} else {
+					spec.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "phase[%s] timed out and container[%s] was killed", phase.Name, displayContainerId)
+				}
+			case err := <-containerErrors:
+				if err != nil {
+					spec.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "container[%s]: encountered error: %s", displayContainerId, err)
+				}
+			case <-done:
+				isDone = true
+			case status := <-containerResponses:
+				exitCode = int(status.StatusCode)
+				if status.StatusCode != 0 {
+					spec.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "container[%s]: exited with status %d", displayContainerId, status.StatusCode)
+					isDone = true
+				}
+			}
+		}
+		spec.ServiceLogs <- common.ServiceLogf(common.LogLevelInfo, "phase[%s]: container[%s] is done", phase.Name, displayContainerId)
+		<-time.After(1 * time.Second)
+		close(containerLogs)
+	}()
+	waiter.Wait()
+	if err := collectPhaseOutputs(context.Background(), r.client, containerInfo.ID, spec, phase); err != nil {
+		spec.ServiceLogs <- common.ServiceLogf(common.LogLevelWarn, "phase[%s]: failed to collect outputs: %s", phase.Name, err)
+	}
+	return exitCode, phaseErr
+}
+
+// stopContainer sends SIGTERM to the container of a cancelled phase
+// and lets Docker send SIGKILL if it has not exited after the stop
+// grace period
+func (r *dockerRuntime) stopContainer(spec automationSpec, phase automations.Phase, containerId string) {
+	displayContainerId := containerId[:11]
+	gracePeriod := spec.getStopGracePeriod()
+	stopTimeout := int(gracePeriod.Seconds())
+	spec.ServiceLogs <- common.ServiceLogf(common.LogLevelWarn, "phase[%s]: cancelled, stopping container[%s] with a grace period of %v...", phase.Name, displayContainerId, gracePeriod)
+	if err := r.client.ContainerStop(context.Background(), containerId, container.StopOptions{
+		Signal:  "SIGTERM",
+		Timeout: &stopTimeout,
+	}); err != nil {
+		spec.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "phase[%s]: cancelled but container[%s] failed to be stopped: %s", phase.Name, displayContainerId, err)
+		return
+	}
+	spec.ServiceLogs <- common.ServiceLogf(common.LogLevelWarn, "phase[%s] was cancelled and container[%s] was stopped", phase.Name, displayContainerId)
+}
+
+// removeContainer removes the container of a phase which has exited or
+// failed to start
+func (r *dockerRuntime) removeContainer(spec automationSpec, phase automations.Phase, containerId string) {
+	displayContainerId := containerId[:11]
+	if err := r.client.ContainerRemove(context.Background(), containerId, container.RemoveOptions{Force: true}); err != nil {
+		spec.ServiceLogs <- common.ServiceLogf(common.LogLevelWarn, "phase[%s]: failed to remove container[%s]: %s", phase.Name, displayContainerId, err)
+		return
+	}
+	spec.ServiceLogs <- common.ServiceLogf(common.LogLevelDebug, "phase[%s]: removed container[%s]", phase.Name, displayContainerId)
 }
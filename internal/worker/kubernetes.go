@@ -0,0 +1,670 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"opsicle/internal/automations"
+	"opsicle/internal/common"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	// kubernetesContainerName is the name of the container which runs
+	// the phase's commands in the pod created by the phase's Job
+	kubernetesContainerName = "phase"
+
+	// kubernetesLabelPhaseRunId is the pod label used to find the pod
+	// of a phase's Job
+	kubernetesLabelPhaseRunId = "opsicle.io/phase-run-id"
+
+	// kubernetesWorkspaceVolumeName is the name of the pod volume which
+	// contains the workspace
+	kubernetesWorkspaceVolumeName = "workspace"
+)
+
+var kubernetesInvalidNameCharacters = regexp.MustCompile("[^a-z0-9-]+")
+
+// KubernetesConfig configures how the Kubernetes runtime connects to
+// the cluster and the resources it creates
+type KubernetesConfig struct {
+	// Kubeconfig is the path to a kubeconfig file, the in-cluster
+	// configuration is used when this is not defined
+	Kubeconfig string
+
+	// Namespace is where Jobs and workspace claims are created,
+	// defaults to DefaultKubernetesNamespace
+	Namespace string
+
+	// PollInterval is the duration between checks on the status of a
+	// phase's Job, defaults to DefaultKubernetesPollInterval
+	PollInterval time.Duration
+
+	// ImagePullSecrets are the names of secrets in the namespace which
+	// are used to pull images of phases
+	ImagePullSecrets []string
+
+	// WorkspaceSize is the storage requested for the workspace claim,
+	// defaults to DefaultKubernetesWorkspaceSize
+	WorkspaceSize string
+
+	// WorkspaceStorageClass is the storage class of the workspace
+	// claim, the cluster's default storage class is used when this is
+	// not defined
+	WorkspaceStorageClass string
+}
+
+// kubernetesRuntime runs each phase as a `batch/v1` Job with a
+// PersistentVolumeClaim created per automation as the workspace; the
+// claim is ReadWriteOnce so the pods of an automation are pinned to the
+// node which its first pod was scheduled to
+type kubernetesRuntime struct {
+	clientset             kubernetes.Interface
+	imagePullSecrets      []string
+	namespace             string
+	pollInterval          time.Duration
+	workspaceSize         string
+	workspaceStorageClass string
+
+	// workspaceClaim is the name of the claim created in Setup
+	workspaceClaim string
+
+	// workspaceNode is the node which the first pod of the automation
+	// was scheduled to
+	workspaceNode string
+
+	// workspaceNodeMutex is held while the first pod of the automation
+	// is being scheduled so that pods of parallel phases wait for
+	// workspaceNode
+	workspaceNodeMutex sync.Mutex
+}
+
+func newKubernetesRuntime(config KubernetesConfig) (*kubernetesRuntime, error) {
+	var restConfig *rest.Config
+	var err error
+	if config.Kubeconfig == "" {
+		restConfig, err = rest.InClusterConfig()
+	} else {
+		restConfig, err = clientcmd.BuildConfigFromFlags("", config.Kubeconfig)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubernetes configuration: %w", err)
+	}
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+	return newKubernetesRuntimeWithClientset(clientset, config), nil
+}
+
+func newKubernetesRuntimeWithClientset(clientset kubernetes.Interface, config KubernetesConfig) *kubernetesRuntime {
+	runtime := &kubernetesRuntime{
+		clientset:             clientset,
+		imagePullSecrets:      config.ImagePullSecrets,
+		namespace:             DefaultKubernetesNamespace,
+		pollInterval:          DefaultKubernetesPollInterval,
+		workspaceSize:         DefaultKubernetesWorkspaceSize,
+		workspaceStorageClass: config.WorkspaceStorageClass,
+	}
+	if config.Namespace != "" {
+		runtime.namespace = config.Namespace
+	}
+	if config.PollInterval > 0 {
+		runtime.pollInterval = config.PollInterval
+	}
+	if config.WorkspaceSize != "" {
+		runtime.workspaceSize = config.WorkspaceSize
+	}
+	return runtime
+}
+
+func (r *kubernetesRuntime) Setup(ctx context.Context, spec automationSpec) error {
+	if parentWorkspace, ok := spec.takeParentWorkspace(common.RuntimeKubernetes); ok {
+		r.workspaceClaim = parentWorkspace.Name
+		r.warnUnavailableVolumeMounts(spec)
+		return nil
+	}
+	workspaceSize, err := resource.ParseQuantity(r.workspaceSize)
+	if err != nil {
+		return fmt.Errorf("failed to parse workspace size[%s]: %w", r.workspaceSize, err)
+	}
+	claim := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   getKubernetesName("opsicle-workspace", spec.Id),
+			Labels: r.getLabels(spec),
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: workspaceSize,
+				},
+			},
+		},
+	}
+	if r.workspaceStorageClass != "" {
+		claim.Spec.StorageClassName = &r.workspaceStorageClass
+	}
+	createdClaim, err := r.clientset.CoreV1().PersistentVolumeClaims(r.namespace).Create(ctx, claim, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to create workspace claim: %w", err)
+	}
+	r.workspaceClaim = createdClaim.Name
+	r.warnUnavailableVolumeMounts(spec)
+	return nil
+}
+
+// warnUnavailableVolumeMounts logs the volume mounts of host paths
+// which are replaced by empty directories in kubernetes
+func (r *kubernetesRuntime) warnUnavailableVolumeMounts(spec automationSpec) {
+	for _, vm := range spec.VolumeMounts {
+		if _, isClaim := automations.GetVolumeMountClaim(vm); !isClaim {
+			spec.ServiceLogs <- common.ServiceLogf(common.LogLevelWarn, "volume mount of host path[%s] is not available in kubernetes, an empty directory will be mounted at path[%s]", vm.Host, vm.Container)
+		}
+	}
+}
+
+func (r *kubernetesRuntime) Teardown(ctx context.Context, spec automationSpec) {
+	if r.workspaceClaim == "" {
+		return
+	}
+	workspaceClaim := r.workspaceClaim
+	if spec.retainWorkspace(common.RuntimeKubernetes, workspaceClaim, func(ctx context.Context) error {
+		return r.clientset.CoreV1().PersistentVolumeClaims(r.namespace).Delete(ctx, workspaceClaim, metav1.DeleteOptions{})
+	}) {
+		return
+	}
+	if err := r.clientset.CoreV1().PersistentVolumeClaims(r.namespace).Delete(ctx, r.workspaceClaim, metav1.DeleteOptions{}); err != nil {
+		spec.ServiceLogs <- common.ServiceLogf(common.LogLevelWarn, "failed to remove workspace claim[%s]: %s", r.workspaceClaim, err)
+	}
+}
+
+// RunPhase creates a Job for the phase and waits for it to complete,
+// the Job is removed before returning; the timeout of the phase is
+// enforced by the Job's `activeDeadlineSeconds` and cancelled phases
+// are stopped by removing the Job early
+func (r *kubernetesRuntime) RunPhase(baseCtx context.Context, spec automationSpec, phase automations.Phase) (int, error) {
+	timeout := 60 * time.Second
+	if phase.Timeout != 0 {
+		timeout = time.Duration(phase.Timeout) * time.Second
+	}
+	// the deadline is enforced by kubernetes, the additional time allows
+	// for it to report that the deadline was exceeded
+	phaseCtx, cancel := context.WithTimeout(baseCtx, timeout+DefaultKubernetesDeadlineGracePeriod)
+	defer cancel()
+
+	if phase.Resources != nil && phase.Resources.Pids > 0 {
+		spec.ServiceLogs <- common.ServiceLogf(common.LogLevelWarn, "phase[%s]: pids limit is not applied when using the kubernetes runtime", phase.Name)
+	}
+
+	// the first phase holds the lock until its pod is scheduled so that
+	// the pods of phases running in parallel can be pinned to its node
+	r.workspaceNodeMutex.Lock()
+	var releaseWorkspaceNodeOnce sync.Once
+	releaseWorkspaceNode := func() {
+		releaseWorkspaceNodeOnce.Do(r.workspaceNodeMutex.Unlock)
+	}
+	defer releaseWorkspaceNode()
+	isSchedulingWorkspaceNode := r.workspaceNode == ""
+
+	jobSpec, secretSpec, err := r.getJob(spec, phase, timeout)
+	if err != nil {
+		return 0, err
+	}
+	if !isSchedulingWorkspaceNode {
+		setKubernetesNodeAffinity(&jobSpec.Spec.Template.Spec, r.workspaceNode)
+		releaseWorkspaceNode()
+	}
+	if secretSpec != nil {
+		secret, err := r.clientset.CoreV1().Secrets(r.namespace).Create(phaseCtx, secretSpec, metav1.CreateOptions{})
+		if err != nil {
+			return 0, fmt.Errorf("failed to create secret: %w", err)
+		}
+		defer func() {
+			if err := r.clientset.CoreV1().Secrets(r.namespace).Delete(context.Background(), secret.Name, metav1.DeleteOptions{}); err != nil {
+				spec.ServiceLogs <- common.ServiceLogf(common.LogLevelWarn, "phase[%s]: failed to remove secret[%s]: %s", phase.Name, secret.Name, err)
+			}
+		}()
+	}
+	job, err := r.clientset.BatchV1().Jobs(r.namespace).Create(phaseCtx, jobSpec, metav1.CreateOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create job: %w", err)
+	}
+	defer func() {
+		propagationPolicy := metav1.DeletePropagationBackground
+		if err := r.clientset.BatchV1().Jobs(r.namespace).Delete(context.Background(), job.Name, metav1.DeleteOptions{
+			PropagationPolicy: &propagationPolicy,
+		}); err != nil {
+			spec.ServiceLogs <- common.ServiceLogf(common.LogLevelWarn, "phase[%s]: failed to remove job[%s]: %s", phase.Name, job.Name, err)
+		}
+	}()
+	spec.ServiceLogs <- common.ServiceLogf(common.LogLevelInfo, "phase[%s]: created job[%s] in namespace[%s]", phase.Name, job.Name, r.namespace)
+
+	podLogs := make(chan phaseOutput, 128)
+	logsDone := make(chan common.Done)
+	go func() {
+		defer close(logsDone)
+		forwardPhaseLogs(spec, &phase, podLogs)
+	}()
+	streamDone := make(chan common.Done)
+	isStreaming := false
+	defer func() {
+		if isStreaming {
+			select {
+			case <-streamDone:
+			case <-time.After(DefaultKubernetesLogsGracePeriod):
+				spec.ServiceLogs <- common.ServiceLogf(common.LogLevelWarn, "phase[%s]: stopped waiting for logs of job[%s]", phase.Name, job.Name)
+				cancel()
+				<-streamDone
+			}
+		}
+		close(podLogs)
+		<-logsDone
+	}()
+
+	podSelector := fmt.Sprintf("%s=%s", kubernetesLabelPhaseRunId, job.Spec.Template.Labels[kubernetesLabelPhaseRunId])
+	var pod *corev1.Pod
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-phaseCtx.Done():
+			if baseCtx.Err() != nil {
+				spec.ServiceLogs <- common.ServiceLogf(common.LogLevelWarn, "phase[%s]: cancelled, removing job[%s] with a grace period of %v...", phase.Name, job.Name, spec.getStopGracePeriod())
+				return 0, errorRunCancelled
+			}
+			spec.ServiceLogs <- common.ServiceLogf(common.LogLevelWarn, "phase[%s]: job[%s] did not complete, removing it...", phase.Name, job.Name)
+			return 0, fmt.Errorf("timed out after %v", timeout)
+		case <-ticker.C:
+		}
+		if pod == nil {
+			pods, err := r.clientset.CoreV1().Pods(r.namespace).List(phaseCtx, metav1.ListOptions{LabelSelector: podSelector})
+			if err == nil && len(pods.Items) > 0 && isSchedulingWorkspaceNode && pods.Items[0].Spec.NodeName != "" {
+				r.workspaceNode = pods.Items[0].Spec.NodeName
+				isSchedulingWorkspaceNode = false
+				releaseWorkspaceNode()
+				spec.ServiceLogs <- common.ServiceLogf(common.LogLevelDebug, "phase[%s]: pinned pods of automation[%s] to node[%s]", phase.Name, spec.Id, r.workspaceNode)
+			}
+			if err != nil {
+				spec.ServiceLogs <- common.ServiceLogf(common.LogLevelWarn, "phase[%s]: failed to list pods of job[%s]: %s", phase.Name, job.Name, err)
+			} else if len(pods.Items) > 0 && pods.Items[0].Status.Phase != corev1.PodPending {
+				pod = &pods.Items[0]
+				isStreaming = true
+				go func(podName string) {
+					defer close(streamDone)
+					if err := r.streamPodLogs(phaseCtx, podName, podLogs); err != nil {
+						spec.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "phase[%s]: failed to stream logs for pod[%s]: %s", phase.Name, podName, err)
+					}
+				}(pod.Name)
+			}
+		}
+		jobStatus, err := r.clientset.BatchV1().Jobs(r.namespace).Get(phaseCtx, job.Name, metav1.GetOptions{})
+		if err != nil {
+			spec.ServiceLogs <- common.ServiceLogf(common.LogLevelWarn, "phase[%s]: failed to get status of job[%s]: %s", phase.Name, job.Name, err)
+			continue
+		}
+		isComplete, failureReason := getKubernetesJobCompletion(jobStatus)
+		if !isComplete {
+			continue
+		}
+		if failureReason == batchv1.JobReasonDeadlineExceeded {
+			spec.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "phase[%s] timed out and job[%s] was stopped", phase.Name, job.Name)
+			return 0, fmt.Errorf("timed out after %v", timeout)
+		}
+		if pod == nil {
+			return 0, fmt.Errorf("job[%s] completed without running a pod: %s", job.Name, failureReason)
+		}
+		podName := pod.Name
+		pod, err = r.clientset.CoreV1().Pods(r.namespace).Get(phaseCtx, podName, metav1.GetOptions{})
+		if err != nil {
+			return 0, fmt.Errorf("failed to get status of pod[%s]: %w", podName, err)
+		}
+		if imageDigest := getKubernetesPodImageDigest(pod); imageDigest != "" {
+			spec.emitStatus(automations.RunStatusUpdate{
+				Phase: &automations.PhaseResult{Name: phase.Name, ImageDigest: imageDigest},
+			})
+		}
+		exitCode, err := getKubernetesPodExitCode(pod)
+		if err != nil {
+			return 0, fmt.Errorf("job[%s] failed: %s: %w", job.Name, failureReason, err)
+		}
+		spec.ServiceLogs <- common.ServiceLogf(common.LogLevelInfo, "phase[%s]: job[%s] is done", phase.Name, job.Name)
+		return exitCode, nil
+	}
+}
+
+// setKubernetesNodeAffinity requires the pod to be scheduled to the
+// node named `nodeName`
+func setKubernetesNodeAffinity(podSpec *corev1.PodSpec, nodeName string) {
+	podSpec.Affinity = &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{
+					{
+						MatchFields: []corev1.NodeSelectorRequirement{
+							{
+								Key:      "metadata.name",
+								Operator: corev1.NodeSelectorOpIn,
+								Values:   []string{nodeName},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// streamPodLogs follows the logs of the phase container in the pod
+// identified by `podName` until the container terminates; logs from
+// kubernetes do not differentiate between stdout and stderr
+func (r *kubernetesRuntime) streamPodLogs(ctx context.Context, podName string, logs chan<- phaseOutput) error {
+	stream, err := r.clientset.CoreV1().Pods(r.namespace).GetLogs(podName, &corev1.PodLogOptions{
+		Container: kubernetesContainerName,
+		Follow:    true,
+	}).Stream(ctx)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+	buffer := make([]byte, DefaultBufferSize)
+	for {
+		n, err := stream.Read(buffer)
+		if n > 0 {
+			logs <- phaseOutput{Text: string(buffer[:n])}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// getJob returns the Job which runs the phase and the Secret holding the
+// values of secret variables which the Job references, the Secret is
+// nil when the automation has no secret variables
+func (r *kubernetesRuntime) getJob(spec automationSpec, phase automations.Phase, timeout time.Duration) (*batchv1.Job, *corev1.Secret, error) {
+	labels := r.getLabels(spec)
+	labels["opsicle.io/phase"] = getKubernetesName("", phase.Name)
+	podLabels := map[string]string{kubernetesLabelPhaseRunId: uuid.NewString()}
+	for key, value := range labels {
+		podLabels[key] = value
+	}
+
+	// the name is truncated before the suffix is added so that it stays unique
+	jobName := getKubernetesName("opsicle", phase.Name)
+	if len(jobName) > 54 {
+		jobName = strings.TrimRight(jobName[:54], "-")
+	}
+	jobName = fmt.Sprintf("%s-%s", jobName, uuid.NewString()[:8])
+
+	// values of secret variables are kept out of the Job's spec and are
+	// referenced from a Secret which shares the Job's name
+	isSecretEnv := map[string]bool{}
+	for _, envName := range spec.SecretEnvNames {
+		isSecretEnv[envName] = true
+	}
+	secretData := map[string][]byte{}
+	env := []corev1.EnvVar{}
+	for _, envVar := range spec.Env {
+		key, value, _ := strings.Cut(envVar, "=")
+		if !isSecretEnv[key] {
+			env = append(env, corev1.EnvVar{Name: key, Value: value})
+			continue
+		}
+		secretData[key] = []byte(value)
+		env = append(env, corev1.EnvVar{
+			Name: key,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: jobName},
+					Key:                  key,
+				},
+			},
+		})
+	}
+	var secret *corev1.Secret
+	if len(secretData) > 0 {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   jobName,
+				Labels: labels,
+			},
+			Type: corev1.SecretTypeOpaque,
+			Data: secretData,
+		}
+	}
+	volumes := []corev1.Volume{
+		{
+			Name: kubernetesWorkspaceVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: r.workspaceClaim},
+			},
+		},
+	}
+	volumeMounts := []corev1.VolumeMount{
+		{Name: kubernetesWorkspaceVolumeName, MountPath: automations.WorkspacePath},
+	}
+	for i, vm := range spec.VolumeMounts {
+		volume := corev1.Volume{
+			Name: fmt.Sprintf("volume-%v", i),
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		}
+		if claimName, isClaim := automations.GetVolumeMountClaim(vm); isClaim {
+			volume.VolumeSource = corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claimName},
+			}
+		}
+		volumes = append(volumes, volume)
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: volume.Name, MountPath: vm.Container})
+	}
+
+	resources, err := getKubernetesResources(phase)
+	if err != nil {
+		return nil, nil, err
+	}
+	securityContext, err := getKubernetesSecurityContext(phase)
+	if err != nil {
+		return nil, nil, err
+	}
+	if phase.Network == automations.PhaseNetworkNone {
+		return nil, nil, fmt.Errorf("network '%s' is not supported by the kubernetes runtime", phase.Network)
+	}
+
+	activeDeadlineSeconds := int64(timeout.Seconds())
+	backoffLimit := int32(0)
+	// pods are sent SIGTERM and then SIGKILL after this period when the
+	// job is removed, which is how cancelled phases are stopped
+	terminationGracePeriodSeconds := int64(spec.getStopGracePeriod().Seconds())
+	imagePullSecrets := []corev1.LocalObjectReference{}
+	for _, imagePullSecret := range r.imagePullSecrets {
+		imagePullSecrets = append(imagePullSecrets, corev1.LocalObjectReference{Name: imagePullSecret})
+	}
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   jobName,
+			Labels: labels,
+		},
+		Spec: batchv1.JobSpec{
+			ActiveDeadlineSeconds: &activeDeadlineSeconds,
+			BackoffLimit:          &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: podLabels},
+				Spec: corev1.PodSpec{
+					RestartPolicy:                 corev1.RestartPolicyNever,
+					TerminationGracePeriodSeconds: &terminationGracePeriodSeconds,
+					Containers: []corev1.Container{
+						{
+							Name:            kubernetesContainerName,
+							Image:           phase.Image,
+							ImagePullPolicy: corev1.PullPolicy(phase.GetImagePullPolicy()),
+							Command:         []string{"sh", "-c", strings.Join(phase.Commands, " && ")},
+							Env:             env,
+							VolumeMounts:    volumeMounts,
+							Resources:       resources,
+							SecurityContext: securityContext,
+						},
+					},
+					HostNetwork:      phase.Network == automations.PhaseNetworkHost,
+					ImagePullSecrets: imagePullSecrets,
+					Volumes:          volumes,
+				},
+			},
+		},
+	}, secret, nil
+}
+
+// getKubernetesResources returns the resource limits of the phase's
+// container
+func getKubernetesResources(phase automations.Phase) (corev1.ResourceRequirements, error) {
+	requirements := corev1.ResourceRequirements{}
+	if phase.Resources == nil {
+		return requirements, nil
+	}
+	nanoCpus, err := phase.Resources.GetNanoCpus()
+	if err != nil {
+		return requirements, err
+	}
+	memory, err := phase.Resources.GetMemoryBytes()
+	if err != nil {
+		return requirements, err
+	}
+	limits := corev1.ResourceList{}
+	if nanoCpus > 0 {
+		limits[corev1.ResourceCPU] = *resource.NewMilliQuantity(nanoCpus/1e6, resource.DecimalSI)
+	}
+	if memory > 0 {
+		limits[corev1.ResourceMemory] = *resource.NewQuantity(memory, resource.BinarySI)
+	}
+	if len(limits) > 0 {
+		requirements.Limits = limits
+	}
+	return requirements, nil
+}
+
+// getKubernetesSecurityContext returns the security context of the
+// phase's container; kubernetes only accepts numeric users and groups
+func getKubernetesSecurityContext(phase automations.Phase) (*corev1.SecurityContext, error) {
+	securityContext := &corev1.SecurityContext{
+		Privileged:             &phase.Privileged,
+		ReadOnlyRootFilesystem: &phase.ReadOnlyRootFs,
+	}
+	if len(phase.CapDrop) > 0 {
+		securityContext.Capabilities = &corev1.Capabilities{}
+		for _, capability := range phase.CapDrop {
+			securityContext.Capabilities.Drop = append(securityContext.Capabilities.Drop, corev1.Capability(capability))
+		}
+	}
+	if phase.User != "" {
+		user, group, hasGroup := strings.Cut(phase.User, ":")
+		userId, err := strconv.ParseInt(user, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("user '%s' must be numeric when using the kubernetes runtime", phase.User)
+		}
+		securityContext.RunAsUser = &userId
+		if hasGroup {
+			groupId, err := strconv.ParseInt(group, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("group of user '%s' must be numeric when using the kubernetes runtime", phase.User)
+			}
+			securityContext.RunAsGroup = &groupId
+		}
+	}
+	return securityContext, nil
+}
+
+func (r *kubernetesRuntime) getLabels(spec automationSpec) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/managed-by": "opsicle",
+		"opsicle.io/automation-id":     getKubernetesName("", spec.Id),
+	}
+}
+
+// getKubernetesJobCompletion returns true if the job has completed,
+// the reason is returned when the job failed
+func getKubernetesJobCompletion(job *batchv1.Job) (bool, string) {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return true, ""
+		case batchv1.JobFailed:
+			return true, condition.Reason
+		}
+	}
+	return false, ""
+}
+
+// getKubernetesPodExitCode returns the exit code of the phase container
+// of the provided pod, an error is returned if it has not terminated
+func getKubernetesPodExitCode(pod *corev1.Pod) (int, error) {
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if containerStatus.Name != kubernetesContainerName {
+			continue
+		}
+		if containerStatus.State.Terminated == nil {
+			return 0, fmt.Errorf("container[%s] of pod[%s] has not terminated", kubernetesContainerName, pod.Name)
+		}
+		return int(containerStatus.State.Terminated.ExitCode), nil
+	}
+	return 0, fmt.Errorf("container[%s] of pod[%s] was not found", kubernetesContainerName, pod.Name)
+}
+
+// getKubernetesPodImageDigest returns the reference by digest of the
+// image which the phase container of the provided pod ran with
+func getKubernetesPodImageDigest(pod *corev1.Pod) string {
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if containerStatus.Name != kubernetesContainerName {
+			continue
+		}
+		// image IDs may be prefixed with a scheme such as `docker-pullable://`
+		imageId := containerStatus.ImageID
+		if _, imageReference, hasScheme := strings.Cut(imageId, "://"); hasScheme {
+			imageId = imageReference
+		}
+		if !strings.Contains(imageId, "@sha256:") {
+			return ""
+		}
+		return imageId
+	}
+	return ""
+}
+
+// getKubernetesName joins the non-empty `parts` with the `prefix` into
+// a valid DNS-1123 label
+func getKubernetesName(prefix string, parts ...string) string {
+	nameParts := []string{}
+	if prefix != "" {
+		nameParts = append(nameParts, prefix)
+	}
+	for _, part := range parts {
+		part = strings.Trim(kubernetesInvalidNameCharacters.ReplaceAllString(strings.ToLower(part), "-"), "-")
+		if part != "" {
+			nameParts = append(nameParts, part)
+		}
+	}
+	name := strings.Join(nameParts, "-")
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	return strings.TrimRight(name, "-")
+}
@@ -0,0 +1,208 @@
+package worker
+
+import (
+	"context"
+	"opsicle/internal/automations"
+	"opsicle/internal/common"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func newTestKubernetesSpec() (automationSpec, chan string) {
+	automationLogs := make(chan string, 16)
+	serviceLogs := make(chan common.ServiceLog, 64)
+	go func() {
+		for range serviceLogs {
+		}
+	}()
+	return automationSpec{
+		Id:             "automation-id",
+		Env:            []string{"GREETING=hello=world", "OPSICLE_VAR_TOKEN=hunter2"},
+		SecretEnvNames: []string{"OPSICLE_VAR_TOKEN"},
+		VolumeMounts:   []automations.VolumeMount{{Host: "pvc:data", Container: "/data"}, {Host: "./cache", Container: "/cache"}},
+		AutomationLogs: automationLogs,
+		ServiceLogs:    serviceLogs,
+	}, automationLogs
+}
+
+func TestKubernetesRuntimeGetJob(t *testing.T) {
+	spec, _ := newTestKubernetesSpec()
+	runtime := newKubernetesRuntimeWithClientset(fake.NewClientset(), KubernetesConfig{})
+	runtime.workspaceClaim = "workspace-claim"
+	job, secret, err := runtime.getJob(spec, automations.Phase{
+		Name:      "Say Hello",
+		Image:     "alpine:latest",
+		Commands:  []string{"echo $GREETING", "exit 0"},
+		Resources: &automations.PhaseResources{Cpu: "0.5", Memory: "256m"},
+		User:      "1000:1000",
+		CapDrop:   []string{"ALL"},
+	}, 30*time.Second)
+	if err != nil {
+		t.Fatalf("getJob returned error: %v", err)
+	}
+
+	if !regexp.MustCompile("^opsicle-say-hello-[0-9a-f]{8}$").MatchString(job.Name) {
+		t.Fatalf("expected a valid job name, got %s", job.Name)
+	}
+	if *job.Spec.ActiveDeadlineSeconds != 30 || *job.Spec.BackoffLimit != 0 {
+		t.Fatalf("expected a 30s deadline without retries, got %v and %v", *job.Spec.ActiveDeadlineSeconds, *job.Spec.BackoffLimit)
+	}
+	podSpec := job.Spec.Template.Spec
+	container := podSpec.Containers[0]
+	if container.ImagePullPolicy != corev1.PullAlways {
+		t.Fatalf("expected image pull policy[%s], got %s", corev1.PullAlways, container.ImagePullPolicy)
+	}
+	if command := strings.Join(container.Command, " "); command != "sh -c echo $GREETING && exit 0" {
+		t.Fatalf("expected commands to be joined, got %s", command)
+	}
+	if len(container.Env) != 2 || container.Env[0].Name != "GREETING" || container.Env[0].Value != "hello=world" {
+		t.Fatalf("expected env to be split on the first '=', got %+v", container.Env)
+	}
+	secretEnv := container.Env[1]
+	if secretEnv.Value != "" || secretEnv.ValueFrom == nil || secretEnv.ValueFrom.SecretKeyRef.Name != job.Name || secretEnv.ValueFrom.SecretKeyRef.Key != "OPSICLE_VAR_TOKEN" {
+		t.Fatalf("expected the secret to be referenced from secret[%s], got %+v", job.Name, secretEnv)
+	}
+	if secret == nil || secret.Name != job.Name || string(secret.Data["OPSICLE_VAR_TOKEN"]) != "hunter2" {
+		t.Fatalf("expected a secret holding the secret variable, got %+v", secret)
+	}
+	if len(podSpec.Volumes) != 3 {
+		t.Fatalf("expected 3 volumes, got %v", len(podSpec.Volumes))
+	}
+	if podSpec.Volumes[0].PersistentVolumeClaim.ClaimName != "workspace-claim" || container.VolumeMounts[0].MountPath != automations.WorkspacePath {
+		t.Fatalf("expected the workspace claim to be mounted at %s", automations.WorkspacePath)
+	}
+	if podSpec.Volumes[1].PersistentVolumeClaim == nil || podSpec.Volumes[1].PersistentVolumeClaim.ClaimName != "data" {
+		t.Fatalf("expected claim 'data' to be mounted, got %+v", podSpec.Volumes[1])
+	}
+	if podSpec.Volumes[2].EmptyDir == nil || container.VolumeMounts[2].MountPath != "/cache" {
+		t.Fatalf("expected an empty directory to be mounted at /cache, got %+v", podSpec.Volumes[2])
+	}
+	if cpu := container.Resources.Limits.Cpu().String(); cpu != "500m" {
+		t.Fatalf("expected a cpu limit of 500m, got %s", cpu)
+	}
+	if memory := container.Resources.Limits.Memory().String(); memory != "256Mi" {
+		t.Fatalf("expected a memory limit of 256Mi, got %s", memory)
+	}
+	securityContext := container.SecurityContext
+	if *securityContext.Privileged || *securityContext.RunAsUser != 1000 || *securityContext.RunAsGroup != 1000 || securityContext.Capabilities.Drop[0] != "ALL" {
+		t.Fatalf("expected security settings to be applied, got %+v", securityContext)
+	}
+	if _, _, err := runtime.getJob(spec, automations.Phase{Name: "named-user", User: "nobody"}, 30*time.Second); err == nil {
+		t.Fatalf("expected an error for a non-numeric user")
+	}
+}
+
+func TestKubernetesRuntimeRunPhase(t *testing.T) {
+	spec, automationLogs := newTestKubernetesSpec()
+	clientset := fake.NewClientset()
+	runtime := newKubernetesRuntimeWithClientset(clientset, KubernetesConfig{
+		Namespace:    "opsicle",
+		PollInterval: 10 * time.Millisecond,
+	})
+	ctx := context.Background()
+	if err := runtime.Setup(ctx, spec); err != nil {
+		t.Fatalf("Setup returned error: %v", err)
+	}
+	claims, _ := clientset.CoreV1().PersistentVolumeClaims("opsicle").List(ctx, metav1.ListOptions{})
+	if len(claims.Items) != 1 {
+		t.Fatalf("expected 1 workspace claim, got %v", len(claims.Items))
+	}
+
+	go completeTestKubernetesJob(ctx, clientset, "node-1")
+
+	exitCode, err := runtime.RunPhase(ctx, spec, automations.Phase{
+		Name:     "fail",
+		Image:    "alpine:latest",
+		Commands: []string{"exit 3"},
+		Timeout:  5,
+	})
+	if err != nil {
+		t.Fatalf("RunPhase returned error: %v", err)
+	}
+	if exitCode != 3 {
+		t.Fatalf("expected exit code 3, got %v", exitCode)
+	}
+	if automationLog := <-automationLogs; automationLog != "[fail] fake logs" {
+		t.Fatalf("expected pod logs to be forwarded, got %s", automationLog)
+	}
+	jobs, _ := clientset.BatchV1().Jobs("opsicle").List(ctx, metav1.ListOptions{})
+	if len(jobs.Items) != 0 {
+		t.Fatalf("expected the job to be removed, got %v jobs", len(jobs.Items))
+	}
+	secrets, _ := clientset.CoreV1().Secrets("opsicle").List(ctx, metav1.ListOptions{})
+	if len(secrets.Items) != 0 {
+		t.Fatalf("expected the secret to be removed, got %v secrets", len(secrets.Items))
+	}
+	if runtime.workspaceNode != "node-1" {
+		t.Fatalf("expected the workspace to be pinned to node[node-1], got %s", runtime.workspaceNode)
+	}
+
+	// pods of later phases are pinned to the node of the first pod
+	createdJobs := make(chan *batchv1.Job, 1)
+	clientset.PrependReactor("create", "jobs", func(action k8stesting.Action) (bool, k8sruntime.Object, error) {
+		createdJobs <- action.(k8stesting.CreateAction).GetObject().(*batchv1.Job).DeepCopy()
+		return false, nil, nil
+	})
+	go completeTestKubernetesJob(ctx, clientset, "node-1")
+	if _, err := runtime.RunPhase(ctx, spec, automations.Phase{
+		Name:     "pinned",
+		Image:    "alpine:latest",
+		Commands: []string{"exit 3"},
+		Timeout:  5,
+	}); err != nil {
+		t.Fatalf("RunPhase returned error: %v", err)
+	}
+	affinity := (<-createdJobs).Spec.Template.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchFields[0].Values[0] != "node-1" {
+		t.Fatalf("expected the pod to be pinned to node[node-1], got %+v", affinity)
+	}
+
+	runtime.Teardown(ctx, spec)
+	claims, _ = clientset.CoreV1().PersistentVolumeClaims("opsicle").List(ctx, metav1.ListOptions{})
+	if len(claims.Items) != 0 {
+		t.Fatalf("expected the workspace claim to be removed, got %v claims", len(claims.Items))
+	}
+}
+
+// completeTestKubernetesJob simulates the job controller by failing the
+// first job once it exists with a pod scheduled to `nodeName`
+func completeTestKubernetesJob(ctx context.Context, clientset *fake.Clientset, nodeName string) {
+	for {
+		jobs, err := clientset.BatchV1().Jobs("opsicle").List(ctx, metav1.ListOptions{})
+		if err != nil || len(jobs.Items) == 0 {
+			<-time.After(5 * time.Millisecond)
+			continue
+		}
+		job := jobs.Items[0]
+		_, _ = clientset.CoreV1().Pods("opsicle").Create(ctx, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   job.Name + "-pod",
+				Labels: job.Spec.Template.Labels,
+			},
+			Spec: corev1.PodSpec{NodeName: nodeName},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodFailed,
+				ContainerStatuses: []corev1.ContainerStatus{
+					{
+						Name:  kubernetesContainerName,
+						State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 3}},
+					},
+				},
+			},
+		}, metav1.CreateOptions{})
+		job.Status.Conditions = []batchv1.JobCondition{
+			{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: batchv1.JobReasonBackoffLimitExceeded},
+		}
+		_, _ = clientset.BatchV1().Jobs("opsicle").UpdateStatus(ctx, &job, metav1.UpdateOptions{})
+		return
+	}
+}
@@ -0,0 +1,67 @@
+package worker
+
+import (
+	"errors"
+	"fmt"
+	"opsicle/internal/automations"
+	"opsicle/internal/common"
+)
+
+var errorPolicyViolation = errors.New("policy_violation")
+
+// Policy restricts the settings which phases are allowed to use on a
+// worker; the zero value allows everything except privileged phases
+type Policy struct {
+	// AllowPrivileged allows phases to set `privileged: true`, this is
+	// required to use the process runtime
+	AllowPrivileged bool
+
+	// ForbidHostMounts rejects automations with volume mounts of paths
+	// on the worker's host, this includes every automation run by the
+	// process runtime
+	ForbidHostMounts bool
+
+	// ForbidHostNetwork rejects phases which use the host's network,
+	// this includes every phase run by the process runtime
+	ForbidHostNetwork bool
+
+	// RequireImageDigests rejects phases whose image is not referenced
+	// by its digest, eg. `alpine@sha256:...`
+	RequireImageDigests bool
+}
+
+// Check returns an error describing all settings of the automation
+// which are not allowed by the policy when it is run using `runtime`
+func (p Policy) Check(spec automations.AutomationSpec, runtime string) error {
+	errs := []error{}
+	if p.ForbidHostMounts {
+		for _, vm := range spec.VolumeMounts {
+			if _, isClaim := automations.GetVolumeMountClaim(vm); !isClaim {
+				errs = append(errs, fmt.Errorf("%w: volume mount of host path[%s] is forbidden", errorPolicyViolation, vm.Host))
+			}
+		}
+	}
+	if runtime == common.RuntimeProcess {
+		// phases run as processes have access to the worker's host like a
+		// privileged container with the host's filesystem mounted
+		if !p.AllowPrivileged {
+			errs = append(errs, fmt.Errorf("%w: the process runtime runs phases on the worker's host and privileged phases are not allowed", errorPolicyViolation))
+		}
+		if p.ForbidHostMounts {
+			errs = append(errs, fmt.Errorf("%w: the process runtime runs phases with access to paths on the worker's host which is forbidden", errorPolicyViolation))
+		}
+	}
+	for _, phase := range spec.Phases {
+		if phase.Privileged && !p.AllowPrivileged {
+			errs = append(errs, fmt.Errorf("%w: phase[%s]: privileged phases are not allowed", errorPolicyViolation, phase.Name))
+		}
+		if p.RequireImageDigests && !phase.IsImagePinned() {
+			errs = append(errs, fmt.Errorf("%w: phase[%s]: image %s is not pinned to a digest", errorPolicyViolation, phase.Name, phase.Image))
+		}
+		isHostNetwork := phase.Network == automations.PhaseNetworkHost || runtime == common.RuntimeProcess
+		if isHostNetwork && p.ForbidHostNetwork {
+			errs = append(errs, fmt.Errorf("%w: phase[%s]: host network is forbidden", errorPolicyViolation, phase.Name))
+		}
+	}
+	return errors.Join(errs...)
+}
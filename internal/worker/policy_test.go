@@ -0,0 +1,62 @@
+package worker
+
+import (
+	"errors"
+	"opsicle/internal/automations"
+	"opsicle/internal/common"
+	"testing"
+)
+
+func TestPolicyCheck(t *testing.T) {
+	spec := automations.AutomationSpec{
+		VolumeMounts: []automations.VolumeMount{{Host: "pvc:data", Container: "/data"}},
+		Phases: []automations.Phase{
+			{Name: "privileged", Image: "alpine", Privileged: true},
+			{Name: "host-network", Image: "alpine", Network: automations.PhaseNetworkHost},
+		},
+	}
+	if err := (Policy{}).Check(spec, common.RuntimeDocker); !errors.Is(err, errorPolicyViolation) {
+		t.Fatalf("expected privileged phases to be rejected by default, got %v", err)
+	}
+	policy := Policy{AllowPrivileged: true, ForbidHostMounts: true}
+	if err := policy.Check(spec, common.RuntimeDocker); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	spec.VolumeMounts = append(spec.VolumeMounts, automations.VolumeMount{Host: "/etc", Container: "/host-etc"})
+	policy.ForbidHostNetwork = true
+	err := policy.Check(spec, common.RuntimeDocker)
+	if !errors.Is(err, errorPolicyViolation) || len(err.(interface{ Unwrap() []error }).Unwrap()) != 2 {
+		t.Fatalf("expected host mount and host network violations, got %v", err)
+	}
+	policy = Policy{AllowPrivileged: true, RequireImageDigests: true}
+	spec.Phases = spec.Phases[:1]
+	if err := policy.Check(spec, common.RuntimeDocker); !errors.Is(err, errorPolicyViolation) {
+		t.Fatalf("expected an unpinned image to be rejected, got %v", err)
+	}
+	spec.Phases[0].Image = "alpine@sha256:4bcff63911fcb4448bd4fdacec207030997caf25e9bea4045fa6c8c44de311d1"
+	if err := policy.Check(spec, common.RuntimeDocker); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	policy.ForbidHostNetwork = true
+	if err := policy.Check(spec, common.RuntimeDocker); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := policy.Check(spec, common.RuntimeProcess); !errors.Is(err, errorPolicyViolation) {
+		t.Fatalf("expected phases run as processes to be rejected for using the host network, got %v", err)
+	}
+}
+
+func TestPolicyCheckProcessRuntime(t *testing.T) {
+	spec := automations.AutomationSpec{
+		Phases: []automations.Phase{{Name: "build"}},
+	}
+	if err := (Policy{}).Check(spec, common.RuntimeProcess); !errors.Is(err, errorPolicyViolation) {
+		t.Fatalf("expected the process runtime to be rejected when privileged phases are not allowed, got %v", err)
+	}
+	if err := (Policy{AllowPrivileged: true}).Check(spec, common.RuntimeProcess); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := (Policy{AllowPrivileged: true, ForbidHostMounts: true}).Check(spec, common.RuntimeProcess); !errors.Is(err, errorPolicyViolation) {
+		t.Fatalf("expected the process runtime to be rejected when host mounts are forbidden, got %v", err)
+	}
+}
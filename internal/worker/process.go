@@ -0,0 +1,331 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"opsicle/internal/automations"
+	"opsicle/internal/common"
+	"os"
+	"os/exec"
+	"os/user"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var errorProcessLimitsUnsupported = errors.New("process_limits_unsupported")
+
+// processLimitsGate is prepended to the commands of a phase so that the
+// shell waits until the resource limits of the phase have been applied
+// to it, processes which it spawns inherit the limits
+const processLimitsGate = "read _ && "
+
+// ProcessConfig configures the isolation of subprocesses run by the
+// process runtime
+type ProcessConfig struct {
+	// User is the name or ID of an unprivileged user which phases are
+	// run as, the worker must be run as root to switch to it; phases run
+	// as the worker's user when this is not defined and can read
+	// everything the worker can such as its private key
+	User string
+}
+
+// processCredential identifies the user and group which the
+// subprocesses of phases are run as
+type processCredential struct {
+	Uid uint32
+	Gid uint32
+}
+
+// processRuntime runs each phase as a subprocess of the worker without
+// a container; the subprocess runs in its own process group with the
+// workspace as its working directory and only the automation's
+// environment variables, the phase's image is not used
+type processRuntime struct {
+	// credential when defined is the user which subprocesses run as
+	credential *processCredential
+
+	// workspaceDir is the directory created in Setup
+	workspaceDir string
+}
+
+func newProcessRuntime(config ProcessConfig) (*processRuntime, error) {
+	runtime := &processRuntime{}
+	if config.User != "" {
+		credential, err := lookupProcessCredential(config.User)
+		if err != nil {
+			return nil, err
+		}
+		runtime.credential = credential
+	}
+	return runtime, nil
+}
+
+// lookupProcessCredential resolves `username`, which can be a name or
+// an ID, to the IDs of the user and its primary group
+func lookupProcessCredential(username string) (*processCredential, error) {
+	userInfo, err := user.Lookup(username)
+	if err != nil {
+		userInfo, err = user.LookupId(username)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find user[%s]: %w", username, err)
+		}
+	}
+	uid, err := strconv.ParseUint(userInfo.Uid, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse id of user[%s]: %w", username, err)
+	}
+	gid, err := strconv.ParseUint(userInfo.Gid, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse group id of user[%s]: %w", username, err)
+	}
+	if uid == 0 {
+		return nil, fmt.Errorf("user[%s] is root and cannot be used to isolate phases", username)
+	}
+	return &processCredential{Uid: uint32(uid), Gid: uint32(gid)}, nil
+}
+
+func (r *processRuntime) Setup(ctx context.Context, spec automationSpec) error {
+	if parentWorkspace, ok := spec.takeParentWorkspace(common.RuntimeProcess); ok {
+		r.workspaceDir = parentWorkspace.Name
+	} else {
+		workspaceDir, err := os.MkdirTemp("", "opsicle-workspace-")
+		if err != nil {
+			return fmt.Errorf("failed to create workspace directory: %w", err)
+		}
+		r.workspaceDir = workspaceDir
+		if r.credential != nil {
+			if err := os.Chown(workspaceDir, int(r.credential.Uid), int(r.credential.Gid)); err != nil {
+				return fmt.Errorf("failed to change owner of workspace directory: %w", err)
+			}
+		}
+	}
+	for _, vm := range spec.VolumeMounts {
+		spec.ServiceLogs <- common.ServiceLogf(common.LogLevelWarn, "volume mount of host path[%s] at path[%s] is not available in the process runtime", vm.Host, vm.Container)
+	}
+	return nil
+}
+
+func (r *processRuntime) Teardown(ctx context.Context, spec automationSpec) {
+	if r.workspaceDir == "" {
+		return
+	}
+	workspaceDir := r.workspaceDir
+	if spec.retainWorkspace(common.RuntimeProcess, workspaceDir, func(ctx context.Context) error {
+		return os.RemoveAll(workspaceDir)
+	}) {
+		return
+	}
+	if err := os.RemoveAll(r.workspaceDir); err != nil {
+		spec.ServiceLogs <- common.ServiceLogf(common.LogLevelWarn, "failed to remove workspace directory[%s]: %s", r.workspaceDir, err)
+	}
+}
+
+// RunPhase executes the commands of the phase with `sh` and returns
+// its exit code; the process group is killed when the phase times out
+// and is stopped gracefully when the automation is cancelled
+func (r *processRuntime) RunPhase(baseCtx context.Context, spec automationSpec, phase automations.Phase) (int, error) {
+	var timeout time.Duration
+	if phase.Timeout == 0 {
+		timeout = 60 * time.Second
+	} else {
+		timeout = time.Duration(phase.Timeout) * time.Second
+	}
+	phaseCtx, cancel := context.WithTimeout(baseCtx, timeout)
+	defer cancel()
+
+	if unsupportedSettings := getUnsupportedProcessSettings(phase); len(unsupportedSettings) > 0 {
+		return 0, fmt.Errorf("%s cannot be enforced by the process runtime", strings.Join(unsupportedSettings, ", "))
+	}
+	if phase.Resources != nil && phase.Resources.Cpu != "" {
+		spec.ServiceLogs <- common.ServiceLogf(common.LogLevelWarn, "phase[%s]: cpu limits are not applied when using the process runtime", phase.Name)
+	}
+
+	cmd := exec.Command("sh", "-c", processLimitsGate+strings.Join(phase.Commands, " && "))
+	cmd.Dir = r.workspaceDir
+	cmd.Env = r.getEnv(spec)
+	setProcessGroup(cmd)
+	if err := setProcessCredential(cmd, r.credential); err != nil {
+		return 0, err
+	}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get stdin: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get stdout: %w", err)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get stderr: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start process: %w", err)
+	}
+	processId := cmd.Process.Pid
+	if err := startLimitedProcess(spec, phase, processId, stdin); err != nil {
+		if killErr := killProcessGroup(cmd); killErr != nil {
+			spec.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "phase[%s]: failed to kill process[%v]: %s", phase.Name, processId, killErr)
+		}
+		cmd.Wait()
+		return 0, err
+	}
+	spec.ServiceLogs <- common.ServiceLogf(common.LogLevelInfo, "phase[%s]: started process[%v] in path[%s]", phase.Name, processId, r.workspaceDir)
+
+	processLogs := make(chan phaseOutput, 128)
+	var waiter sync.WaitGroup
+	waiter.Add(1)
+	go func() {
+		defer waiter.Done()
+		forwardPhaseLogs(spec, &phase, processLogs)
+	}()
+	var streamWaiter sync.WaitGroup
+	streamWaiter.Add(2)
+	go func() {
+		defer streamWaiter.Done()
+		streamProcessOutput(stdout, processLogs, false)
+	}()
+	go func() {
+		defer streamWaiter.Done()
+		streamProcessOutput(stderr, processLogs, true)
+	}()
+
+	processDone := make(chan error, 1)
+	go func() {
+		// pipes must be fully read before calling Wait
+		streamWaiter.Wait()
+		processDone <- cmd.Wait()
+	}()
+	var phaseErr error
+	var waitErr error
+	select {
+	case waitErr = <-processDone:
+	case <-phaseCtx.Done():
+		if baseCtx.Err() != nil {
+			phaseErr = errorRunCancelled
+			waitErr = stopProcessGroup(spec, phase, cmd, processDone)
+			break
+		}
+		phaseErr = fmt.Errorf("timed out after %v", timeout)
+		spec.ServiceLogs <- common.ServiceLogf(common.LogLevelWarn, "phase[%s]: timed out, killing process[%v]...", phase.Name, processId)
+		if err := killProcessGroup(cmd); err != nil {
+			spec.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "phase[%s]: timed out but process[%v] failed to be killed: %s", phase.Name, processId, err)
+		} else {
+			spec.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "phase[%s] timed out and process[%v] was killed", phase.Name, processId)
+		}
+		waitErr = <-processDone
+	}
+	close(processLogs)
+	waiter.Wait()
+
+	exitCode := 0
+	var exitErr *exec.ExitError
+	if errors.As(waitErr, &exitErr) {
+		exitCode = getProcessExitCode(exitErr)
+		if exitCode != 0 {
+			spec.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "process[%v]: exited with status %d", processId, exitCode)
+		}
+	} else if waitErr != nil && phaseErr == nil {
+		phaseErr = fmt.Errorf("failed to wait for process: %w", waitErr)
+	}
+	spec.ServiceLogs <- common.ServiceLogf(common.LogLevelInfo, "phase[%s]: process[%v] is done", phase.Name, processId)
+	if err := collectLocalPhaseOutputs(r.workspaceDir, spec, phase); err != nil {
+		spec.ServiceLogs <- common.ServiceLogf(common.LogLevelWarn, "phase[%s]: failed to collect outputs: %s", phase.Name, err)
+	}
+	return exitCode, phaseErr
+}
+
+// startLimitedProcess applies the resource limits of the phase to the
+// process waiting at processLimitsGate and then lets it continue by
+// closing its stdin
+func startLimitedProcess(spec automationSpec, phase automations.Phase, processId int, stdin io.WriteCloser) error {
+	defer stdin.Close()
+	if phase.Resources != nil {
+		if err := setProcessLimits(processId, *phase.Resources); errors.Is(err, errorProcessLimitsUnsupported) {
+			spec.ServiceLogs <- common.ServiceLogf(common.LogLevelWarn, "phase[%s]: resource limits are not applied when using the process runtime on this platform", phase.Name)
+		} else if err != nil {
+			return fmt.Errorf("failed to apply resource limits: %w", err)
+		}
+	}
+	if _, err := io.WriteString(stdin, "\n"); err != nil {
+		return fmt.Errorf("failed to start process: %w", err)
+	}
+	return nil
+}
+
+// stopProcessGroup sends SIGTERM to the process group of a cancelled
+// phase and sends SIGKILL if the process has not exited after the stop
+// grace period; the result of waiting for the process is returned
+func stopProcessGroup(spec automationSpec, phase automations.Phase, cmd *exec.Cmd, processDone <-chan error) error {
+	processId := cmd.Process.Pid
+	gracePeriod := spec.getStopGracePeriod()
+	spec.ServiceLogs <- common.ServiceLogf(common.LogLevelWarn, "phase[%s]: cancelled, stopping process[%v] with a grace period of %v...", phase.Name, processId, gracePeriod)
+	if err := terminateProcessGroup(cmd); err != nil {
+		spec.ServiceLogs <- common.ServiceLogf(common.LogLevelWarn, "phase[%s]: failed to send SIGTERM to process[%v]: %s", phase.Name, processId, err)
+	}
+	select {
+	case waitErr := <-processDone:
+		spec.ServiceLogs <- common.ServiceLogf(common.LogLevelWarn, "phase[%s] was cancelled and process[%v] exited", phase.Name, processId)
+		return waitErr
+	case <-time.After(gracePeriod):
+	}
+	spec.ServiceLogs <- common.ServiceLogf(common.LogLevelWarn, "phase[%s]: process[%v] did not exit after %v, killing it...", phase.Name, processId, gracePeriod)
+	if err := killProcessGroup(cmd); err != nil {
+		spec.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "phase[%s]: cancelled but process[%v] failed to be killed: %s", phase.Name, processId, err)
+	}
+	return <-processDone
+}
+
+// getUnsupportedProcessSettings returns the security settings of the
+// phase which cannot be applied to a subprocess; these are rejected
+// instead of ignored as the phase's author expects them to be enforced
+func getUnsupportedProcessSettings(phase automations.Phase) []string {
+	unsupportedSettings := []string{}
+	if phase.Network != "" && phase.Network != automations.PhaseNetworkHost {
+		unsupportedSettings = append(unsupportedSettings, fmt.Sprintf("network '%s'", phase.Network))
+	}
+	if phase.User != "" {
+		unsupportedSettings = append(unsupportedSettings, "user")
+	}
+	if phase.ReadOnlyRootFs {
+		unsupportedSettings = append(unsupportedSettings, "readOnlyRootFs")
+	}
+	if len(phase.CapDrop) > 0 {
+		unsupportedSettings = append(unsupportedSettings, "capDrop")
+	}
+	if phase.Privileged {
+		unsupportedSettings = append(unsupportedSettings, "privileged")
+	}
+	return unsupportedSettings
+}
+
+// getEnv returns the environment of a phase's process, variables of
+// the worker are not inherited other than PATH
+func (r *processRuntime) getEnv(spec automationSpec) []string {
+	env := []string{
+		fmt.Sprintf("PATH=%s", os.Getenv("PATH")),
+		fmt.Sprintf("HOME=%s", r.workspaceDir),
+	}
+	env = append(env, spec.Env...)
+	// later values take precedence so this replaces the container path
+	return append(env, fmt.Sprintf("%s=%s", automations.WorkspaceEnvName, r.workspaceDir))
+}
+
+// streamProcessOutput reads from `reader` until it is closed and sends
+// what was read to `logs`
+func streamProcessOutput(reader io.Reader, logs chan<- phaseOutput, isStderr bool) {
+	buffer := make([]byte, DefaultBufferSize)
+	for {
+		n, err := reader.Read(buffer)
+		if n > 0 {
+			logs <- phaseOutput{Text: string(buffer[:n]), IsStderr: isStderr}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
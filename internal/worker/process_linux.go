@@ -0,0 +1,33 @@
+//go:build linux
+
+package worker
+
+import (
+	"fmt"
+	"opsicle/internal/automations"
+
+	"golang.org/x/sys/unix"
+)
+
+// setProcessLimits applies the memory and pids limits of `resources` to
+// the process identified by `processId`; memory limits its address
+// space and pids limits the number of processes of its user
+func setProcessLimits(processId int, resources automations.PhaseResources) error {
+	memory, err := resources.GetMemoryBytes()
+	if err != nil {
+		return err
+	}
+	if memory > 0 {
+		limit := unix.Rlimit{Cur: uint64(memory), Max: uint64(memory)}
+		if err := unix.Prlimit(processId, unix.RLIMIT_AS, &limit, nil); err != nil {
+			return fmt.Errorf("failed to limit memory of process[%v]: %w", processId, err)
+		}
+	}
+	if resources.Pids > 0 {
+		limit := unix.Rlimit{Cur: uint64(resources.Pids), Max: uint64(resources.Pids)}
+		if err := unix.Prlimit(processId, unix.RLIMIT_NPROC, &limit, nil); err != nil {
+			return fmt.Errorf("failed to limit pids of process[%v]: %w", processId, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+//go:build linux
+
+package worker
+
+import (
+	"context"
+	"opsicle/internal/automations"
+	"strings"
+	"testing"
+)
+
+func TestProcessRuntimeRunPhaseAppliesResourceLimits(t *testing.T) {
+	spec, automationLogs := newTestProcessSpec()
+	runtime := newTestProcessRuntime(t)
+	ctx := context.Background()
+	if err := runtime.Setup(ctx, spec); err != nil {
+		t.Fatalf("Setup returned error: %v", err)
+	}
+	defer runtime.Teardown(ctx, spec)
+
+	exitCode, err := runtime.RunPhase(ctx, spec, automations.Phase{
+		Name:      "limited",
+		Commands:  []string{"ulimit -v"},
+		Resources: &automations.PhaseResources{Memory: "64m"},
+	})
+	if err != nil {
+		t.Fatalf("RunPhase returned error: %v", err)
+	}
+	if exitCode != 0 {
+		t.Fatalf("expected exit code 0, got %v", exitCode)
+	}
+	close(automationLogs)
+	logs := ""
+	for automationLog := range automationLogs {
+		logs += automationLog
+	}
+	if strings.TrimSpace(logs) != "[limited] 65536" {
+		t.Fatalf("expected a memory limit of 65536 KiB, got %q", logs)
+	}
+}
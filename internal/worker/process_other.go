@@ -0,0 +1,14 @@
+//go:build !linux
+
+package worker
+
+import "opsicle/internal/automations"
+
+// setProcessLimits returns errorProcessLimitsUnsupported if `resources`
+// defines memory or pids limits as these are only applied on linux
+func setProcessLimits(processId int, resources automations.PhaseResources) error {
+	if resources.Memory != "" || resources.Pids > 0 {
+		return errorProcessLimitsUnsupported
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+//go:build !windows
+
+package worker
+
+import (
+	"context"
+	"errors"
+	"opsicle/internal/automations"
+	"opsicle/internal/common"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestProcessSpec() (automationSpec, chan string) {
+	automationLogs := make(chan string, 64)
+	serviceLogs := make(chan common.ServiceLog, 64)
+	go func() {
+		for range serviceLogs {
+		}
+	}()
+	return automationSpec{
+		Id:             "automation-id",
+		Env:            []string{"GREETING=hello", "PASSWORD=hunter2"},
+		Secrets:        []string{"hunter2"},
+		Artifacts:      newArtifactCollector(automations.MaxArtifactsSize),
+		AutomationLogs: automationLogs,
+		ServiceLogs:    serviceLogs,
+	}, automationLogs
+}
+
+func newTestProcessRuntime(t *testing.T) *processRuntime {
+	runtime, err := newProcessRuntime(ProcessConfig{})
+	if err != nil {
+		t.Fatalf("newProcessRuntime returned error: %v", err)
+	}
+	return runtime
+}
+
+func TestProcessRuntimeRunPhase(t *testing.T) {
+	spec, automationLogs := newTestProcessSpec()
+	runtime := newTestProcessRuntime(t)
+	ctx := context.Background()
+	if err := runtime.Setup(ctx, spec); err != nil {
+		t.Fatalf("Setup returned error: %v", err)
+	}
+	defer runtime.Teardown(ctx, spec)
+
+	exitCode, err := runtime.RunPhase(ctx, spec, automations.Phase{
+		Name: "greet",
+		Commands: []string{
+			"echo $GREETING $PASSWORD",
+			"echo oops >&2",
+			"echo done > $OPSICLE_WORKSPACE/result.txt",
+			"exit 3",
+		},
+		Outputs: []string{"result.txt"},
+	})
+	if err != nil {
+		t.Fatalf("RunPhase returned error: %v", err)
+	}
+	if exitCode != 3 {
+		t.Fatalf("expected exit code 3, got %v", exitCode)
+	}
+	close(automationLogs)
+	logs := ""
+	for automationLog := range automationLogs {
+		logs += automationLog
+	}
+	if !strings.Contains(logs, "[greet] hello "+automations.RedactedValue) || strings.Contains(logs, "hunter2") {
+		t.Fatalf("expected stdout with secrets redacted, got %q", logs)
+	}
+	if !strings.Contains(logs, automations.PrefixStderr("[greet] oops")) {
+		t.Fatalf("expected prefixed stderr, got %q", logs)
+	}
+	artifacts, err := spec.Artifacts.Close()
+	if err != nil || artifacts == nil {
+		t.Fatalf("expected outputs to be collected, got error: %v", err)
+	}
+}
+
+func TestProcessRuntimeRunPhaseTimeout(t *testing.T) {
+	spec, _ := newTestProcessSpec()
+	runtime := newTestProcessRuntime(t)
+	ctx := context.Background()
+	if err := runtime.Setup(ctx, spec); err != nil {
+		t.Fatalf("Setup returned error: %v", err)
+	}
+	defer runtime.Teardown(ctx, spec)
+
+	exitCode, err := runtime.RunPhase(ctx, spec, automations.Phase{
+		Name:     "sleep",
+		Commands: []string{"sleep 30"},
+		Timeout:  1,
+	})
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("expected a timeout error, got %v", err)
+	}
+	if exitCode != 137 {
+		t.Fatalf("expected exit code 137, got %v", exitCode)
+	}
+}
+
+func TestProcessRuntimeRunPhaseCancelled(t *testing.T) {
+	spec, _ := newTestProcessSpec()
+	spec.StopGracePeriod = 500 * time.Millisecond
+	runtime := newTestProcessRuntime(t)
+	if err := runtime.Setup(context.Background(), spec); err != nil {
+		t.Fatalf("Setup returned error: %v", err)
+	}
+	defer runtime.Teardown(context.Background(), spec)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-time.After(200 * time.Millisecond)
+		cancel()
+	}()
+	// SIGTERM is ignored so that the process is only stopped by the
+	// SIGKILL sent after the grace period
+	exitCode, err := runtime.RunPhase(ctx, spec, automations.Phase{
+		Name:     "ignore-sigterm",
+		Commands: []string{"trap '' TERM", "sleep 30"},
+		Timeout:  10,
+	})
+	if !errors.Is(err, errorRunCancelled) {
+		t.Fatalf("expected a cancellation error, got %v", err)
+	}
+	if exitCode != 137 {
+		t.Fatalf("expected exit code 137, got %v", exitCode)
+	}
+}
+
+func TestProcessRuntimeReusesRetainedWorkspace(t *testing.T) {
+	spec, _ := newTestProcessSpec()
+	spec.WorkspaceRetention = time.Minute
+	ctx := context.Background()
+	parentRuntime := newTestProcessRuntime(t)
+	if err := parentRuntime.Setup(ctx, spec); err != nil {
+		t.Fatalf("Setup returned error: %v", err)
+	}
+	if _, err := parentRuntime.RunPhase(ctx, spec, automations.Phase{
+		Name:     "build",
+		Commands: []string{"echo built > $OPSICLE_WORKSPACE/build.txt"},
+	}); err != nil {
+		t.Fatalf("RunPhase returned error: %v", err)
+	}
+	parentRuntime.Teardown(ctx, spec)
+	defer retainedWorkspaces.Release(ctx, spec.ServiceLogs)
+
+	rerunSpec, _ := newTestProcessSpec()
+	rerunSpec.Id = "rerun-automation-id"
+	rerunSpec.ParentId = spec.Id
+	rerunRuntime := newTestProcessRuntime(t)
+	if err := rerunRuntime.Setup(ctx, rerunSpec); err != nil {
+		t.Fatalf("Setup returned error: %v", err)
+	}
+	defer rerunRuntime.Teardown(ctx, rerunSpec)
+	if rerunRuntime.workspaceDir != parentRuntime.workspaceDir {
+		t.Fatalf("expected workspace[%s] to be reused, got workspace[%s]", parentRuntime.workspaceDir, rerunRuntime.workspaceDir)
+	}
+	exitCode, err := rerunRuntime.RunPhase(ctx, rerunSpec, automations.Phase{
+		Name:     "deploy",
+		Commands: []string{"test -f $OPSICLE_WORKSPACE/build.txt"},
+	})
+	if err != nil {
+		t.Fatalf("RunPhase returned error: %v", err)
+	}
+	if exitCode != 0 {
+		t.Fatalf("expected the file from the parent run to exist, got exit code %v", exitCode)
+	}
+	if _, ok := retainedWorkspaces.Take(spec.Id, common.RuntimeProcess); ok {
+		t.Fatalf("expected the retained workspace to be taken by the re-run")
+	}
+}
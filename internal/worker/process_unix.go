@@ -0,0 +1,49 @@
+//go:build !windows
+
+package worker
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup starts the process in a new process group so that
+// processes it spawns can be killed together with it
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// setProcessCredential runs the process as the user and group of
+// `credential` without supplementary groups, the process runs as the
+// worker's user when `credential` is nil
+func setProcessCredential(cmd *exec.Cmd, credential *processCredential) error {
+	if credential == nil {
+		return nil
+	}
+	cmd.SysProcAttr.Credential = &syscall.Credential{
+		Uid:    credential.Uid,
+		Gid:    credential.Gid,
+		Groups: []uint32{},
+	}
+	return nil
+}
+
+// terminateProcessGroup sends SIGTERM to the process group of the
+// process
+func terminateProcessGroup(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+}
+
+// killProcessGroup sends SIGKILL to the process group of the process
+func killProcessGroup(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
+
+// getProcessExitCode returns the exit code of the process, processes
+// terminated by a signal return 128 plus the signal number like a shell
+func getProcessExitCode(exitErr *exec.ExitError) int {
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal())
+	}
+	return exitErr.ExitCode()
+}
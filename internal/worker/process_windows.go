@@ -0,0 +1,37 @@
+//go:build windows
+
+package worker
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// setProcessGroup is a noop on windows
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// setProcessCredential returns an error if `credential` is defined as
+// processes cannot be started as another user on windows
+func setProcessCredential(cmd *exec.Cmd, credential *processCredential) error {
+	if credential == nil {
+		return nil
+	}
+	return errors.New("running phases as another user is not supported on windows")
+}
+
+// terminateProcessGroup kills the process as signals other than kill
+// cannot be sent to processes on windows
+func terminateProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
+
+// killProcessGroup kills the process, processes it spawned are not
+// killed on windows
+func killProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
+
+// getProcessExitCode returns the exit code of the process
+func getProcessExitCode(exitErr *exec.ExitError) int {
+	return exitErr.ExitCode()
+}
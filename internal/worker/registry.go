@@ -0,0 +1,131 @@
+package worker
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/distribution/reference"
+	"github.com/docker/docker/api/types/registry"
+)
+
+// dockerHubRegistry is the registry which images without a registry
+// in their name are pulled from
+const dockerHubRegistry = "docker.io"
+
+// RegistryCredential authenticates the worker with a container
+// registry when pulling images of phases
+type RegistryCredential struct {
+	// Server is the host of the registry, eg. "ghcr.io"
+	Server string `json:"server" yaml:"server"`
+
+	Username string `json:"username" yaml:"username"`
+	Password string `json:"password" yaml:"password"`
+
+	// IdentityToken is used instead of the username and password when
+	// defined
+	IdentityToken string `json:"identityToken" yaml:"identityToken"`
+}
+
+// dockerConfig is the subset of a Docker `config.json` which contains
+// registry credentials
+type dockerConfig struct {
+	Auths map[string]dockerConfigAuth `json:"auths"`
+}
+
+type dockerConfigAuth struct {
+	Auth          string `json:"auth"`
+	Username      string `json:"username"`
+	Password      string `json:"password"`
+	IdentityToken string `json:"identitytoken"`
+}
+
+// LoadRegistryCredentialsFromDockerConfig returns the credentials in
+// the `auths` of the Docker `config.json` at `configPath`; credential
+// helpers are not supported
+func LoadRegistryCredentialsFromDockerConfig(configPath string) ([]RegistryCredential, error) {
+	configData, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read docker config from path[%s]: %w", configPath, err)
+	}
+	var config dockerConfig
+	if err := json.Unmarshal(configData, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse docker config from path[%s]: %w", configPath, err)
+	}
+	credentials := []RegistryCredential{}
+	for server, auth := range config.Auths {
+		credential := RegistryCredential{
+			Server:        server,
+			Username:      auth.Username,
+			Password:      auth.Password,
+			IdentityToken: auth.IdentityToken,
+		}
+		if auth.Auth != "" {
+			decodedAuth, err := base64.StdEncoding.DecodeString(auth.Auth)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode auth of registry[%s]: %w", server, err)
+			}
+			username, password, _ := strings.Cut(string(decodedAuth), ":")
+			credential.Username = username
+			credential.Password = password
+		}
+		credentials = append(credentials, credential)
+	}
+	return credentials, nil
+}
+
+// getRegistryAuth returns the encoded credentials for pulling `image`
+// from its registry, an empty string is returned if there are none
+func getRegistryAuth(credentials []RegistryCredential, image string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse image[%s]: %w", image, err)
+	}
+	imageRegistry := reference.Domain(named)
+	for _, credential := range credentials {
+		if normaliseRegistry(credential.Server) != imageRegistry {
+			continue
+		}
+		return registry.EncodeAuthConfig(registry.AuthConfig{
+			Username:      credential.Username,
+			Password:      credential.Password,
+			IdentityToken: credential.IdentityToken,
+			ServerAddress: credential.Server,
+		})
+	}
+	return "", nil
+}
+
+// normaliseRegistry returns the host of the provided registry server
+// which may be a url as found in a Docker `config.json`
+func normaliseRegistry(server string) string {
+	server = strings.TrimPrefix(strings.TrimPrefix(server, "https://"), "http://")
+	server, _, _ = strings.Cut(server, "/")
+	switch server {
+	case "index.docker.io", "registry-1.docker.io":
+		return dockerHubRegistry
+	}
+	return server
+}
+
+// getImageDigest returns the reference of `image` by digest from the
+// repository digests of the pulled image, an empty string is returned
+// if none of them are from the same repository
+func getImageDigest(image string, repoDigests []string) string {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return ""
+	}
+	for _, repoDigest := range repoDigests {
+		namedDigest, err := reference.ParseNormalizedNamed(repoDigest)
+		if err != nil {
+			continue
+		}
+		if namedDigest.Name() == named.Name() {
+			return reference.FamiliarString(namedDigest)
+		}
+	}
+	return ""
+}
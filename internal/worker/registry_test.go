@@ -0,0 +1,50 @@
+package worker
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/api/types/registry"
+)
+
+func TestLoadRegistryCredentialsFromDockerConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	auth := base64.StdEncoding.EncodeToString([]byte("robot:s3cr3t"))
+	if err := os.WriteFile(configPath, []byte(`{"auths":{"https://index.docker.io/v1/":{"auth":"`+auth+`"}}}`), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	credentials, err := LoadRegistryCredentialsFromDockerConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadRegistryCredentialsFromDockerConfig returned error: %v", err)
+	}
+	if len(credentials) != 1 || credentials[0].Username != "robot" || credentials[0].Password != "s3cr3t" {
+		t.Fatalf("expected decoded credentials, got %+v", credentials)
+	}
+
+	encodedAuth, err := getRegistryAuth(credentials, "alpine:latest")
+	if err != nil || encodedAuth == "" {
+		t.Fatalf("expected credentials for docker hub images, got error: %v", err)
+	}
+	authConfig, err := registry.DecodeAuthConfig(encodedAuth)
+	if err != nil || authConfig.Username != "robot" {
+		t.Fatalf("expected encoded credentials of robot, got %+v", authConfig)
+	}
+	if encodedAuth, _ := getRegistryAuth(credentials, "ghcr.io/opsicle/opsicle:latest"); encodedAuth != "" {
+		t.Fatalf("expected no credentials for other registries")
+	}
+}
+
+func TestGetImageDigest(t *testing.T) {
+	repoDigests := []string{
+		"mirror.example.com/alpine@sha256:4bcff63911fcb4448bd4fdacec207030997caf25e9bea4045fa6c8c44de311d1",
+		"alpine@sha256:4bcff63911fcb4448bd4fdacec207030997caf25e9bea4045fa6c8c44de311d1",
+	}
+	if digest := getImageDigest("docker.io/library/alpine:latest", repoDigests); digest != repoDigests[1] {
+		t.Fatalf("expected the digest of the same repository, got %s", digest)
+	}
+	if digest := getImageDigest("ubuntu:latest", repoDigests); digest != "" {
+		t.Fatalf("expected no digest, got %s", digest)
+	}
+}
@@ -0,0 +1,151 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"opsicle/internal/automations"
+	"opsicle/internal/common"
+	"strings"
+	"time"
+)
+
+var errorRunCancelled = errors.New("run_cancelled")
+
+// phaseRuntime executes the phases of an automation in an isolated
+// environment such as a Docker container or a Kubernetes Job
+type phaseRuntime interface {
+	// Setup creates the resources shared by all phases of the automation
+	// such as its workspace, it is called once before any phase is run
+	Setup(ctx context.Context, spec automationSpec) error
+
+	// RunPhase executes a single phase and returns the exit code of its
+	// process; an error is returned if the phase could not be run to
+	// completion. When `ctx` is cancelled, the phase is sent SIGTERM
+	// and is sent SIGKILL if it has not exited after the spec's stop
+	// grace period, `errorRunCancelled` is returned in this case
+	RunPhase(ctx context.Context, spec automationSpec, phase automations.Phase) (int, error)
+
+	// Teardown removes the resources created by Setup, it is called once
+	// after all phases have completed
+	Teardown(ctx context.Context, spec automationSpec)
+}
+
+type newPhaseRuntimeOpts struct {
+	DockerApiVersion    string
+	Kubernetes          *KubernetesConfig
+	Process             *ProcessConfig
+	RegistryCredentials []RegistryCredential
+	Runtime             string
+}
+
+// newPhaseRuntime returns the phaseRuntime identified by `.Runtime`,
+// the Docker runtime is used when it is not defined
+func newPhaseRuntime(opts newPhaseRuntimeOpts) (phaseRuntime, error) {
+	switch opts.Runtime {
+	case "", common.RuntimeDocker:
+		return newDockerRuntime(opts.DockerApiVersion, opts.RegistryCredentials)
+	case common.RuntimeKubernetes:
+		config := KubernetesConfig{}
+		if opts.Kubernetes != nil {
+			config = *opts.Kubernetes
+		}
+		return newKubernetesRuntime(config)
+	case common.RuntimeProcess:
+		config := ProcessConfig{}
+		if opts.Process != nil {
+			config = *opts.Process
+		}
+		return newProcessRuntime(config)
+	}
+	return nil, fmt.Errorf("failed to identify runtime[%s], expected one of %v", opts.Runtime, common.Runtimes)
+}
+
+// maxPhaseLogLineSize is the size in bytes after which output of a
+// phase without a newline is forwarded before the line is complete
+const maxPhaseLogLineSize = 16 * 1024
+
+// phaseOutput is output read from the stdout or stderr of a phase
+type phaseOutput struct {
+	Text     string
+	IsStderr bool
+}
+
+// forwardPhaseLogs redacts secrets from output received on `logs` and
+// forwards it to the automation logs with every line prefixed by the
+// name of the phase until `logs` is closed; output is held per stream
+// until its lines are complete so that secrets which are split across
+// reads are redacted
+func forwardPhaseLogs(spec automationSpec, phase *automations.Phase, logs <-chan phaseOutput) {
+	forward := func(text string, isStderr bool) {
+		phaseLog := automations.RedactSecrets(text, spec.Secrets)
+		automationLog := automations.PrefixPhase(phaseLog, phase.Name)
+		if isStderr {
+			phaseLog = prefixWithStderr(phaseLog)
+			automationLog = prefixWithStderr(automationLog)
+		}
+		spec.AutomationLogs <- automationLog
+		phase.Logs = append(phase.Logs, automations.PhaseLog{
+			Timestamp: time.Now().Format("2006-01-02T15:04:05"),
+			Message:   phaseLog,
+		})
+	}
+	pending := map[bool]string{}
+	for output := range logs {
+		text := pending[output.IsStderr] + output.Text
+		if lineEnd := strings.LastIndex(text, "\n") + 1; lineEnd > 0 {
+			forward(text[:lineEnd], output.IsStderr)
+			text = text[lineEnd:]
+		}
+		if len(text) > maxPhaseLogLineSize {
+			if cut := getRedactableLength(text, spec.Secrets); cut > 0 {
+				forward(text[:cut], output.IsStderr)
+				text = text[cut:]
+			}
+		}
+		pending[output.IsStderr] = text
+	}
+	for _, isStderr := range []bool{false, true} {
+		if pending[isStderr] != "" {
+			forward(pending[isStderr], isStderr)
+		}
+	}
+}
+
+// getRedactableLength returns the length of the start of `text` which
+// can be redacted on its own; the rest could be the start of a secret
+// which continues in output that has not been read yet
+func getRedactableLength(text string, secrets []string) int {
+	redactables := []string{}
+	longestLength := 0
+	for _, secret := range secrets {
+		for _, redactable := range append(strings.Split(secret, "\n"), secret) {
+			if redactable == "" {
+				continue
+			}
+			redactables = append(redactables, redactable)
+			longestLength = max(longestLength, len(redactable))
+		}
+	}
+	length := len(text) - max(longestLength-1, 0)
+	if length <= 0 {
+		return 0
+	}
+	// secrets which span the cut are held back in their entirety
+	for isMoved := true; isMoved; {
+		isMoved = false
+		for _, redactable := range redactables {
+			windowStart := max(length-len(redactable)+1, 0)
+			windowEnd := min(length+len(redactable)-1, len(text))
+			if index := strings.Index(text[windowStart:windowEnd], redactable); index >= 0 {
+				length = windowStart + index
+				isMoved = true
+			}
+		}
+	}
+	return length
+}
+
+func prefixWithStderr(text string) string {
+	return automations.PrefixStderr(text)
+}
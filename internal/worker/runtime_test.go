@@ -0,0 +1,69 @@
+package worker
+
+import (
+	"opsicle/internal/automations"
+	"strings"
+	"testing"
+)
+
+func forwardTestPhaseOutput(secrets []string, outputs ...phaseOutput) string {
+	automationLogs := make(chan string, 64)
+	spec := automationSpec{Secrets: secrets, AutomationLogs: automationLogs}
+	logs := make(chan phaseOutput, len(outputs))
+	for _, output := range outputs {
+		logs <- output
+	}
+	close(logs)
+	forwardPhaseLogs(spec, &automations.Phase{Name: "test"}, logs)
+	close(automationLogs)
+	forwarded := ""
+	for automationLog := range automationLogs {
+		forwarded += automationLog
+	}
+	return forwarded
+}
+
+func TestForwardPhaseLogsRedactsSecretsAcrossChunks(t *testing.T) {
+	forwarded := forwardTestPhaseOutput(
+		[]string{"hunter2"},
+		phaseOutput{Text: "password is hun"},
+		phaseOutput{Text: "oops\n", IsStderr: true},
+		phaseOutput{Text: "ter2\ndone"},
+	)
+	if strings.Contains(forwarded, "hun") || strings.Contains(forwarded, "ter2") {
+		t.Fatalf("expected the secret to be redacted, got %q", forwarded)
+	}
+	expected := automations.StderrPrefix + "[test] oops\n[test] password is " + automations.RedactedValue + "\n[test] done"
+	if forwarded != expected {
+		t.Fatalf("expected %q, got %q", expected, forwarded)
+	}
+}
+
+func TestForwardPhaseLogsRedactsSecretsAcrossChunksOfLongLines(t *testing.T) {
+	longLine := strings.Repeat("a", maxPhaseLogLineSize)
+	forwarded := forwardTestPhaseOutput(
+		[]string{"hunter2"},
+		phaseOutput{Text: longLine + "hun"},
+		phaseOutput{Text: "ter2"},
+	)
+	if !strings.HasPrefix(forwarded, "[test] ") {
+		t.Fatalf("expected the output to be prefixed with the phase name")
+	}
+	forwarded = strings.ReplaceAll(forwarded, "[test] ", "")
+	if forwarded != longLine+automations.RedactedValue {
+		t.Fatalf("expected the secret to be redacted, got %q", forwarded[len(longLine):])
+	}
+}
+
+func TestGetRedactableLength(t *testing.T) {
+	secrets := []string{"hunter2"}
+	if length := getRedactableLength("abcdefghij", secrets); length != 4 {
+		t.Fatalf("expected the last 6 bytes to be held back, got %v", length)
+	}
+	if length := getRedactableLength("abchunter2j", secrets); length != 3 {
+		t.Fatalf("expected the secret spanning the cut to be held back, got %v", length)
+	}
+	if length := getRedactableLength("abcdefghij", nil); length != 10 {
+		t.Fatalf("expected everything to be redactable without secrets, got %v", length)
+	}
+}
@@ -0,0 +1,96 @@
+package worker
+
+import (
+	"opsicle/internal/automations"
+	"opsicle/internal/common"
+)
+
+type runPhaseGraphOpts struct {
+	Spec automations.AutomationSpec
+
+	// Run executes a phase, it is called concurrently for phases whose
+	// dependencies have completed
+	Run func(phase automations.Phase) phaseOutcome
+
+	// Skip is called for each phase which was not started because a
+	// phase failed
+	Skip func(phase automations.Phase, failedPhase string)
+
+	ServiceLogs chan common.ServiceLog
+}
+
+type phaseGraphResult struct {
+	Phase   automations.Phase
+	Outcome phaseOutcome
+}
+
+// runPhaseGraph runs the phases of the automation once their
+// dependencies have completed with at most `.Spec.GetMaxParallelPhases()`
+// phases running at the same time. When a phase fails without
+// `continueOnError`, no further phases are started, running phases are
+// allowed to complete and the error of the failed phase is returned
+func runPhaseGraph(opts runPhaseGraphOpts) error {
+	phases := map[string]automations.Phase{}
+	for _, phase := range opts.Spec.Phases {
+		phases[phase.Name] = phase
+	}
+	dependencies := opts.Spec.GetPhaseDependencies()
+	dependents := opts.Spec.GetPhaseDependents()
+	pendingDependencies := map[string]int{}
+	readyPhases := []string{}
+	for _, phase := range opts.Spec.Phases {
+		pendingDependencies[phase.Name] = len(dependencies[phase.Name])
+		if pendingDependencies[phase.Name] == 0 {
+			readyPhases = append(readyPhases, phase.Name)
+		}
+	}
+
+	maxParallelPhases := opts.Spec.GetMaxParallelPhases()
+	results := make(chan phaseGraphResult, len(opts.Spec.Phases))
+	startedPhases := map[string]bool{}
+	runningPhases := 0
+	var failure *phaseError
+	for {
+		for failure == nil && runningPhases < maxParallelPhases && len(readyPhases) > 0 {
+			phase := phases[readyPhases[0]]
+			readyPhases = readyPhases[1:]
+			startedPhases[phase.Name] = true
+			runningPhases++
+			go func(phase automations.Phase) {
+				results <- phaseGraphResult{Phase: phase, Outcome: opts.Run(phase)}
+			}(phase)
+		}
+		if runningPhases == 0 {
+			break
+		}
+		result := <-results
+		runningPhases--
+		phase := result.Phase
+		if result.Outcome.Err != nil {
+			if !phase.ContinueOnError {
+				if failure == nil {
+					opts.ServiceLogs <- common.ServiceLogf(common.LogLevelError, "phase[%s]: failed, not starting remaining phases: %s", phase.Name, result.Outcome.Err)
+					failure = &phaseError{Phase: phase.Name, ExitCode: result.Outcome.ExitCode, Err: result.Outcome.Err}
+				}
+				continue
+			}
+			opts.ServiceLogs <- common.ServiceLogf(common.LogLevelWarn, "phase[%s]: failed but continuing as continueOnError is set: %s", phase.Name, result.Outcome.Err)
+		}
+		for _, dependent := range dependents[phase.Name] {
+			pendingDependencies[dependent]--
+			if pendingDependencies[dependent] == 0 {
+				readyPhases = append(readyPhases, dependent)
+			}
+		}
+	}
+
+	if failure == nil {
+		return nil
+	}
+	for _, phase := range opts.Spec.Phases {
+		if !startedPhases[phase.Name] {
+			opts.Skip(phase, failure.Phase)
+		}
+	}
+	return failure
+}
@@ -0,0 +1,202 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"opsicle/internal/automations"
+	"opsicle/internal/common"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakePhaseRuntime runs phases by sleeping for `.Duration`, it exits
+// with the code in `.ExitCodes` for the phase and records the phases it
+// ran and the maximum number of phases which ran at the same time
+type fakePhaseRuntime struct {
+	Duration  time.Duration
+	ExitCodes map[string]int
+
+	mutex         sync.Mutex
+	ranPhases     []string
+	runningPhases int
+	maxRunning    int
+}
+
+func (r *fakePhaseRuntime) Setup(ctx context.Context, spec automationSpec) error { return nil }
+
+func (r *fakePhaseRuntime) RunPhase(ctx context.Context, spec automationSpec, phase automations.Phase) (int, error) {
+	r.mutex.Lock()
+	r.ranPhases = append(r.ranPhases, phase.Name)
+	r.runningPhases++
+	r.maxRunning = max(r.maxRunning, r.runningPhases)
+	r.mutex.Unlock()
+	time.Sleep(r.Duration)
+	r.mutex.Lock()
+	r.runningPhases--
+	r.mutex.Unlock()
+	return r.ExitCodes[phase.Name], nil
+}
+
+func (r *fakePhaseRuntime) Teardown(ctx context.Context, spec automationSpec) error { return nil }
+
+// skippedPhases records the phases passed to the Skip callback of the
+// phase graph along with the phase whose failure caused the skip
+type skippedPhases struct {
+	mutex  sync.Mutex
+	phases map[string]string
+}
+
+func (s *skippedPhases) Skip(phase automations.Phase, failedPhase string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.phases[phase.Name] = failedPhase
+}
+
+func runTestPhaseGraph(t *testing.T, runtime *fakePhaseRuntime, spec automations.AutomationSpec) (map[string]string, error) {
+	t.Helper()
+	serviceLogs := make(chan common.ServiceLog, 64)
+	skipped := &skippedPhases{phases: map[string]string{}}
+	err := runPhaseGraph(runPhaseGraphOpts{
+		Spec: spec,
+		Run: func(phase automations.Phase) phaseOutcome {
+			exitCode, err := runtime.RunPhase(context.Background(), automationSpec{}, phase)
+			if err == nil && exitCode != 0 {
+				err = errors.New("phase exited with a non-zero exit code")
+			}
+			return phaseOutcome{ExitCode: exitCode, Err: err}
+		},
+		Skip:        skipped.Skip,
+		ServiceLogs: serviceLogs,
+	})
+	return skipped.phases, err
+}
+
+func TestRunPhaseGraphLimitsParallelPhases(t *testing.T) {
+	runtime := &fakePhaseRuntime{Duration: 20 * time.Millisecond}
+	skipped, err := runTestPhaseGraph(t, runtime, automations.AutomationSpec{
+		MaxParallelPhases: 2,
+		Phases: []automations.Phase{
+			{Name: "checkout"},
+			{Name: "build", DependsOn: []string{"checkout"}},
+			{Name: "test", DependsOn: []string{"checkout"}},
+			{Name: "lint", DependsOn: []string{"checkout"}},
+			{Name: "scan", DependsOn: []string{"checkout"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("runPhaseGraph returned error: %v", err)
+	}
+	if len(skipped) != 0 {
+		t.Fatalf("expected no phases to be skipped, got %v", skipped)
+	}
+	if len(runtime.ranPhases) != 5 {
+		t.Fatalf("expected all phases to run, got %v", runtime.ranPhases)
+	}
+	if runtime.maxRunning != 2 {
+		t.Fatalf("expected at most 2 phases to run at the same time, got %v", runtime.maxRunning)
+	}
+}
+
+func TestRunPhaseGraphRunsPhasesInOrderWithoutDependencies(t *testing.T) {
+	runtime := &fakePhaseRuntime{Duration: time.Millisecond}
+	_, err := runTestPhaseGraph(t, runtime, automations.AutomationSpec{
+		Phases: []automations.Phase{
+			{Name: "build"},
+			{Name: "test"},
+			{Name: "deploy"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("runPhaseGraph returned error: %v", err)
+	}
+	if runtime.maxRunning != 1 {
+		t.Fatalf("expected phases to run one at a time, got %v", runtime.maxRunning)
+	}
+	expected := []string{"build", "test", "deploy"}
+	for index, phaseName := range expected {
+		if index >= len(runtime.ranPhases) || runtime.ranPhases[index] != phaseName {
+			t.Fatalf("expected phases to run in the order %v, got %v", expected, runtime.ranPhases)
+		}
+	}
+}
+
+func TestRunPhaseGraphRunsDependentsAfterDependencies(t *testing.T) {
+	runtime := &fakePhaseRuntime{Duration: time.Millisecond}
+	_, err := runTestPhaseGraph(t, runtime, automations.AutomationSpec{
+		Phases: []automations.Phase{
+			{Name: "deploy", DependsOn: []string{"build", "test"}},
+			{Name: "build"},
+			{Name: "test", DependsOn: []string{"build"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("runPhaseGraph returned error: %v", err)
+	}
+	expected := []string{"build", "test", "deploy"}
+	for index, phaseName := range expected {
+		if index >= len(runtime.ranPhases) || runtime.ranPhases[index] != phaseName {
+			t.Fatalf("expected phases to run in the order %v, got %v", expected, runtime.ranPhases)
+		}
+	}
+}
+
+func TestRunPhaseGraphSkipsPhasesAfterFailure(t *testing.T) {
+	runtime := &fakePhaseRuntime{
+		Duration:  time.Millisecond,
+		ExitCodes: map[string]int{"build": 2},
+	}
+	skipped, err := runTestPhaseGraph(t, runtime, automations.AutomationSpec{
+		MaxParallelPhases: 1,
+		Phases: []automations.Phase{
+			{Name: "build"},
+			{Name: "test", DependsOn: []string{"build"}},
+			{Name: "lint"},
+			{Name: "deploy", DependsOn: []string{"test"}},
+		},
+	})
+	var phaseErr *phaseError
+	if !errors.As(err, &phaseErr) {
+		t.Fatalf("expected a phaseError, got %v", err)
+	}
+	if phaseErr.Phase != "build" || phaseErr.ExitCode != 2 {
+		t.Fatalf("expected phase[build] to fail with exit code 2, got phase[%s] with exit code %v", phaseErr.Phase, phaseErr.ExitCode)
+	}
+	if len(runtime.ranPhases) != 1 {
+		t.Fatalf("expected no phases to start after the failure, got %v", runtime.ranPhases)
+	}
+	skippedPhaseNames := []string{}
+	for phaseName, failedPhase := range skipped {
+		if failedPhase != "build" {
+			t.Fatalf("expected phase[%s] to be skipped due to phase[build], got phase[%s]", phaseName, failedPhase)
+		}
+		skippedPhaseNames = append(skippedPhaseNames, phaseName)
+	}
+	sort.Strings(skippedPhaseNames)
+	if len(skippedPhaseNames) != 3 || skippedPhaseNames[0] != "deploy" || skippedPhaseNames[1] != "lint" || skippedPhaseNames[2] != "test" {
+		t.Fatalf("expected phases deploy, lint and test to be skipped, got %v", skippedPhaseNames)
+	}
+}
+
+func TestRunPhaseGraphContinuesOnError(t *testing.T) {
+	runtime := &fakePhaseRuntime{
+		Duration:  time.Millisecond,
+		ExitCodes: map[string]int{"test": 1},
+	}
+	skipped, err := runTestPhaseGraph(t, runtime, automations.AutomationSpec{
+		Phases: []automations.Phase{
+			{Name: "test", ContinueOnError: true},
+			{Name: "deploy", DependsOn: []string{"test"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("expected the failure of phase[test] to be ignored, got %v", err)
+	}
+	if len(skipped) != 0 {
+		t.Fatalf("expected no phases to be skipped, got %v", skipped)
+	}
+	if len(runtime.ranPhases) != 2 || runtime.ranPhases[1] != "deploy" {
+		t.Fatalf("expected phase[deploy] to run after phase[test], got %v", runtime.ranPhases)
+	}
+}
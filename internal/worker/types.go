@@ -3,12 +3,80 @@ package worker
 import (
 	"opsicle/internal/automations"
 	"opsicle/internal/common"
+	"time"
 )
 
 type automationSpec struct {
+	Id           string                    `json:"id" yaml:"id"`
 	Phases       []automations.Phase       `json:"phases" yaml:"phases"`
 	VolumeMounts []automations.VolumeMount `json:"volumeMounts" yaml:"volumeMounts"`
 
-	AutomationLogs chan string            `json:"-"`
-	ServiceLogs    chan common.ServiceLog `json:"-"`
+	// MaxParallelPhases is the maximum number of phases which can run
+	// at the same time
+	MaxParallelPhases int `json:"maxParallelPhases" yaml:"maxParallelPhases"`
+
+	// Env is the list of `KEY=value` environment variables derived from
+	// the automation's variables which are injected into every phase
+	Env []string `json:"-" yaml:"-"`
+
+	// SecretEnvNames are the names of the variables in `Env` which hold
+	// the values of secret variables
+	SecretEnvNames []string `json:"-" yaml:"-"`
+
+	// Secrets are the values of secret variables which are redacted from
+	// the automation's logs
+	Secrets []string `json:"-" yaml:"-"`
+
+	// Vars is the resolved mapping of variable ID to value used when
+	// rendering phase templates, secret variables are excluded
+	Vars map[string]any `json:"-" yaml:"-"`
+
+	// Artifacts when defined collects the outputs of phases
+	Artifacts *artifactCollector `json:"-" yaml:"-"`
+
+	// StopGracePeriod is how long a phase is given to exit after being
+	// sent SIGTERM when the automation is cancelled before it is sent
+	// SIGKILL, `DefaultStopGracePeriod` is used when this is zero
+	StopGracePeriod time.Duration `json:"-" yaml:"-"`
+
+	// ParentId is the ID of the automation which this automation is a
+	// re-run of, its workspace is reused when it was retained
+	ParentId string `json:"-" yaml:"-"`
+
+	// ResumedPhases maps the names of phases which are not run again to
+	// their results from the parent automation
+	ResumedPhases map[string]automations.PhaseResult `json:"-" yaml:"-"`
+
+	// WorkspaceRetention is how long the workspace is kept after the
+	// automation completes for re-runs to reuse, the workspace is
+	// removed immediately when this is zero
+	WorkspaceRetention time.Duration `json:"-" yaml:"-"`
+
+	AutomationLogs chan string                        `json:"-"`
+	ServiceLogs    chan common.ServiceLog             `json:"-"`
+	StatusUpdates  chan<- automations.RunStatusUpdate `json:"-"`
+	WorkerId       string                             `json:"-"`
+}
+
+// getStopGracePeriod returns the duration a phase is given to exit
+// after being sent SIGTERM
+func (s automationSpec) getStopGracePeriod() time.Duration {
+	if s.StopGracePeriod <= 0 {
+		return DefaultStopGracePeriod
+	}
+	return s.StopGracePeriod
+}
+
+// emitStatus sends the provided update to the status updates channel
+// if one was provided, populating the automation and worker IDs
+func (s automationSpec) emitStatus(update automations.RunStatusUpdate) {
+	if s.StatusUpdates == nil {
+		return
+	}
+	update.AutomationId = s.Id
+	update.WorkerId = s.WorkerId
+	if update.Timestamp.IsZero() {
+		update.Timestamp = time.Now()
+	}
+	s.StatusUpdates <- update
 }
@@ -1,10 +1,13 @@
 package worker
 
 import (
+	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"opsicle/internal/automations"
 	"opsicle/internal/common"
+	"opsicle/pkg/coordinator"
 	"os"
 	"path"
 	"path/filepath"
@@ -33,9 +36,16 @@ type Worker struct {
 	Ca []byte
 
 	// DoneChannel will tell the worker to gracefully exit
-	// when it's possible to do so
+	// when it's possible to do so, the worker stops pulling
+	// automations when it receives a signal and cancels those
+	// in progress after DrainTimeout or on a second signal
 	DoneChannel chan common.Done
 
+	// DrainTimeout is how long the worker waits for automations
+	// in progress to complete after a signal on DoneChannel
+	// before cancelling them, defaults to DefaultDrainTimeout
+	DrainTimeout time.Duration
+
 	// FilesystemPath defines the directory path where automation
 	// manifests will be placed for execution, this is used when
 	// the Mode is set to `ModeFilesystem`
@@ -44,6 +54,14 @@ type Worker struct {
 	// Id is an ID that can be used to identify the worker
 	Id string
 
+	// Kubernetes configures the Kubernetes runtime, this is used when
+	// the Runtime is set to `common.RuntimeKubernetes`
+	Kubernetes *KubernetesConfig
+
+	// Process configures the process runtime, this is used when the
+	// Runtime is set to `common.RuntimeProcess`
+	Process *ProcessConfig
+
 	// ServiceLogs is a channel where service-level logs are emitted to
 	ServiceLogs *chan common.ServiceLog
 
@@ -54,17 +72,31 @@ type Worker struct {
 	// PollInterval is the duration between polls of the queue
 	PollInterval time.Duration
 
+	// Policy restricts the settings which phases are allowed to use
+	Policy Policy
+
+	// RegistryCredentials are used to authenticate with registries when
+	// pulling images of phases
+	RegistryCredentials []RegistryCredential
+
 	// Runtime defines the runtime of the worker
 	Runtime string
 
+	// StopGracePeriod is how long phases are given to exit after
+	// being sent SIGTERM when their automation is cancelled
+	StopGracePeriod time.Duration
+
 	// Mode which the worker should run in
 	Mode string
+
+	// WorkspaceRetention is how long workspaces of completed
+	// automations are kept for re-runs of them to reuse
+	WorkspaceRetention time.Duration
 }
 
 func (w *Worker) Start() error {
 	var serviceLogs chan common.ServiceLog
 	if w.ServiceLogs == nil {
-		serviceLogs <- common.ServiceLogf(common.LogLevelWarn, "worker is starting with a noop service log, messages may be missed")
 		serviceLogs = make(chan common.ServiceLog, 128)
 		defer close(serviceLogs)
 		go func() { // noop loop if log channel isn't specified
@@ -75,6 +107,7 @@ func (w *Worker) Start() error {
 				}
 			}
 		}()
+		serviceLogs <- common.ServiceLogf(common.LogLevelWarn, "worker is starting with a noop service log, messages may be missed")
 	} else {
 		serviceLogs = *w.ServiceLogs
 	}
@@ -95,6 +128,11 @@ func (w *Worker) Start() error {
 		automationLogs = *w.AutomationLogs
 	}
 
+	runsCtx, cancelRuns := context.WithCancelCause(context.Background())
+	defer cancelRuns(nil)
+	loopDone := make(chan common.Done, 1)
+	go w.drain(runsCtx, loopDone, cancelRuns, serviceLogs)
+
 	var lifecycleWaiter sync.WaitGroup
 	lifecycleWaiter.Add(1)
 	go func() {
@@ -102,7 +140,101 @@ func (w *Worker) Start() error {
 		serviceLogs <- common.ServiceLogf(common.LogLevelInfo, "worker is starting in mode[%s] using runtime[%s]", w.Mode, w.Runtime)
 		switch w.Mode {
 		case ModeCoordinator:
-			panic("TODO")
+			var cert *tls.Certificate
+			if len(w.Cert.Certificate) > 0 {
+				cert = &w.Cert
+			} else {
+				serviceLogs <- common.ServiceLogf(common.LogLevelWarn, "no client certificate was provided, the coordinator will reject the worker unless bearer auth is configured")
+			}
+			coordinatorClient, err := coordinator.NewClient(coordinator.NewClientOpts{
+				CoordinatorUrl: w.CoordinatorUrl,
+				Ca:             w.Ca,
+				Cert:           cert,
+				Id:             w.Id,
+			})
+			if err != nil {
+				serviceLogs <- common.ServiceLogf(common.LogLevelError, "failed to create coordinator client: %s", err)
+				break
+			}
+			serviceLogs <- common.ServiceLogf(common.LogLevelInfo, "using coordinator[%s] as the queue", w.CoordinatorUrl)
+			if err := startCoordinatorQueueLoop(startCoordinatorQueueLoopOpts{
+				Client: coordinatorClient,
+				Done:   loopDone,
+				Handler: func(automationInstance *automations.Automation) error {
+					automationId := automationInstance.Spec.Status.Id
+					serviceLogs <- common.ServiceLogf(common.LogLevelDebug, "running automation[%s]...", automationId)
+					statusUpdates := make(chan automations.RunStatusUpdate, 16)
+					var statusWaiter sync.WaitGroup
+					statusWaiter.Add(1)
+					go func() {
+						defer statusWaiter.Done()
+						for update := range statusUpdates {
+							if _, err := coordinatorClient.UpdateJobStatusV1(update); err != nil {
+								serviceLogs <- common.ServiceLogf(common.LogLevelError, "failed to report status[%s] of automation[%s]: %s", update.Status, automationId, err)
+							}
+						}
+					}()
+					automationCtx, cancelAutomation := context.WithCancelCause(runsCtx)
+					go watchCoordinatorCancellation(watchCoordinatorCancellationOpts{
+						AutomationId: automationId,
+						Cancel:       cancelAutomation,
+						Client:       coordinatorClient,
+						Context:      automationCtx,
+						PollInterval: w.PollInterval,
+						ServiceLogs:  serviceLogs,
+					})
+					runLogs := make(chan string, 128)
+					statusWaiter.Add(1)
+					go func() {
+						defer statusWaiter.Done()
+						shipCoordinatorLogs(shipCoordinatorLogsOpts{
+							AutomationId: automationId,
+							Client:       coordinatorClient,
+							Forward:      automationLogs,
+							Logs:         runLogs,
+							ServiceLogs:  serviceLogs,
+							WorkerId:     w.Id,
+						})
+					}()
+					err := RunAutomation(RunAutomationOpts{
+						ArtifactsHandler: func(artifacts []byte) error {
+							_, err := coordinatorClient.PushJobArtifactsV1(coordinator.PushJobArtifactsV1Input{
+								AutomationId: automationId,
+								Content:      artifacts,
+							})
+							return err
+						},
+						Context:             automationCtx,
+						Spec:                automationInstance,
+						AutomationLogs:      runLogs,
+						Kubernetes:          w.Kubernetes,
+						Policy:              w.Policy,
+						Process:             w.Process,
+						RegistryCredentials: w.RegistryCredentials,
+						Runtime:             w.Runtime,
+						ServiceLogs:         serviceLogs,
+						StatusUpdates:       statusUpdates,
+						StopGracePeriod:     w.StopGracePeriod,
+						WorkerId:            w.Id,
+						WorkspaceRetention:  w.WorkspaceRetention,
+					})
+					cancelAutomation(nil)
+					close(runLogs)
+					close(statusUpdates)
+					statusWaiter.Wait()
+					if err != nil {
+						return fmt.Errorf("failed to run automation[%s]: %w", automationId, err)
+					}
+					serviceLogs <- common.ServiceLogf(common.LogLevelDebug, "successfully processed automation[%s]", automationId)
+					return nil
+				},
+				PollInterval: w.PollInterval,
+				Runtime:      w.Runtime,
+				ServiceLogs:  serviceLogs,
+				WorkerId:     w.Id,
+			}); err != nil {
+				serviceLogs <- common.ServiceLogf(common.LogLevelError, "failure in execution loop: %s", err)
+			}
 
 		case ModeFilesystem:
 			directoryToWatch := w.FilesystemPath
@@ -144,7 +276,7 @@ func (w *Worker) Start() error {
 
 			if err := startFilesystemQueueLoop(startFilesystemQueueLoopOpts{
 				AutomationsCounter: &ongoingAutomations,
-				Done:               w.DoneChannel,
+				Done:               loopDone,
 				Handler: func(nextAutomation string, logsPath string) error {
 					serviceLogs <- common.ServiceLogf(common.LogLevelDebug, "loading automation from path[%s]...", nextAutomation)
 					automationInstance, err := automations.LoadAutomationFromFile(nextAutomation)
@@ -154,9 +286,21 @@ func (w *Worker) Start() error {
 					}
 					serviceLogs <- common.ServiceLogf(common.LogLevelDebug, "running automation from path[%s]...", nextAutomation)
 					err = RunAutomation(RunAutomationOpts{
-						Spec:           automationInstance,
-						AutomationLogs: automationLogs,
-						ServiceLogs:    serviceLogs,
+						ArtifactsHandler: func(artifacts []byte) error {
+							artifactsPath := filepath.Join(logsPath, fmt.Sprintf("%s.artifacts.tar.gz", filepath.Base(nextAutomation)))
+							return os.WriteFile(artifactsPath, artifacts, 0o644)
+						},
+						Context:             runsCtx,
+						Spec:                automationInstance,
+						AutomationLogs:      automationLogs,
+						Kubernetes:          w.Kubernetes,
+						Policy:              w.Policy,
+						Process:             w.Process,
+						RegistryCredentials: w.RegistryCredentials,
+						Runtime:             w.Runtime,
+						ServiceLogs:         serviceLogs,
+						StopGracePeriod:     w.StopGracePeriod,
+						WorkspaceRetention:  w.WorkspaceRetention,
 					})
 					if err != nil {
 						serviceLogs <- common.ServiceLogf(common.LogLevelError, "failed to run automation from path[%s]: %s", nextAutomation, err)
@@ -183,9 +327,37 @@ func (w *Worker) Start() error {
 	}()
 
 	lifecycleWaiter.Wait()
+	retainedWorkspaces.Release(context.Background(), serviceLogs)
 	return nil
 }
 
+// drain waits for a signal on `.DoneChannel` and stops the queue loop
+// from pulling further automations; automations in progress are
+// cancelled when `.DrainTimeout` elapses or when another signal is
+// received. This returns early when `ctx` is done
+func (w *Worker) drain(ctx context.Context, loopDone chan<- common.Done, cancelRuns context.CancelCauseFunc, serviceLogs chan common.ServiceLog) {
+	select {
+	case <-ctx.Done():
+		return
+	case <-w.DoneChannel:
+	}
+	drainTimeout := w.DrainTimeout
+	if drainTimeout <= 0 {
+		drainTimeout = DefaultDrainTimeout
+	}
+	serviceLogs <- common.ServiceLogf(common.LogLevelInfo, "draining worker, automations in progress will be cancelled if they do not complete within %v", drainTimeout)
+	loopDone <- common.Done{}
+	select {
+	case <-ctx.Done():
+		return
+	case <-w.DoneChannel:
+		serviceLogs <- common.ServiceLogf(common.LogLevelWarn, "received another exit signal, cancelling automations in progress...")
+	case <-time.After(drainTimeout):
+		serviceLogs <- common.ServiceLogf(common.LogLevelWarn, "automations in progress did not complete within %v, cancelling them...", drainTimeout)
+	}
+	cancelRuns(errors.New("automation was cancelled as its worker is exiting"))
+}
+
 type NewWorkerOpts struct {
 	// AutomationLogs when defined will be the channel to which
 	// **automation runtime** logs are emitted to
@@ -203,12 +375,36 @@ type NewWorkerOpts struct {
 	// when it's possible to do so
 	DoneChannel chan common.Done
 
+	// DrainTimeout is how long the worker waits for automations
+	// in progress to complete after a signal on DoneChannel
+	// before cancelling them
+	DrainTimeout time.Duration
+
+	// Id is an ID that can be used to identify the worker, when
+	// not defined, the coordinator assigns one
+	Id string
+
+	// Kubernetes configures the Kubernetes runtime, this is used when
+	// the Runtime is set to `common.RuntimeKubernetes`
+	Kubernetes *KubernetesConfig
+
+	// Process configures the process runtime, this is used when the
+	// Runtime is set to `common.RuntimeProcess`
+	Process *ProcessConfig
+
 	// Mode defines the mode which the worker should run in
 	Mode string
 
 	// PollInterval is the duration between polls of the queue
 	PollInterval time.Duration
 
+	// Policy restricts the settings which phases are allowed to use
+	Policy Policy
+
+	// RegistryCredentials are used to authenticate with registries when
+	// pulling images of phases
+	RegistryCredentials []RegistryCredential
+
 	// Runtime defines the runtime of the worker
 	Runtime string
 
@@ -216,18 +412,36 @@ type NewWorkerOpts struct {
 	// **function** logs are emitted to
 	ServiceLogs *chan common.ServiceLog
 
+	// StopGracePeriod is how long phases are given to exit after
+	// being sent SIGTERM when their automation is cancelled
+	StopGracePeriod time.Duration
+
 	// Source defines the source path/url depending on the
 	// mode the worker is running in
 	Source string
+
+	// WorkspaceRetention is how long workspaces of completed
+	// automations are kept for re-runs of them to reuse, workspaces
+	// are removed immediately when this is zero
+	WorkspaceRetention time.Duration
 }
 
 func NewWorker(opts NewWorkerOpts) *Worker {
 	worker := Worker{
-		AutomationLogs: opts.AutomationLogs,
-		DoneChannel:    opts.DoneChannel,
-		ServiceLogs:    opts.ServiceLogs,
-		Mode:           opts.Mode,
-		PollInterval:   opts.PollInterval,
+		AutomationLogs:      opts.AutomationLogs,
+		DoneChannel:         opts.DoneChannel,
+		DrainTimeout:        opts.DrainTimeout,
+		Id:                  opts.Id,
+		Kubernetes:          opts.Kubernetes,
+		ServiceLogs:         opts.ServiceLogs,
+		Mode:                opts.Mode,
+		PollInterval:        opts.PollInterval,
+		Policy:              opts.Policy,
+		Process:             opts.Process,
+		RegistryCredentials: opts.RegistryCredentials,
+		Runtime:             opts.Runtime,
+		StopGracePeriod:     opts.StopGracePeriod,
+		WorkspaceRetention:  opts.WorkspaceRetention,
 	}
 	switch opts.Mode {
 	case ModeCoordinator:
@@ -0,0 +1,125 @@
+package worker
+
+import (
+	"context"
+	"opsicle/internal/common"
+	"sync"
+	"time"
+)
+
+// retainedWorkspaces holds the workspaces of automations which have
+// completed on this worker so that re-runs of them can reuse their
+// workspaces
+var retainedWorkspaces = newWorkspaceStore()
+
+// retainedWorkspace is the workspace of a completed automation which
+// is kept instead of being removed in the runtime's Teardown
+type retainedWorkspace struct {
+	// Runtime is the runtime which created the workspace, workspaces
+	// are only reused by the same runtime
+	Runtime string
+
+	// Name is the runtime's name for the workspace such as a volume
+	// name, claim name or directory path
+	Name string
+
+	// ExpiresAt is when the workspace is removed if it has not been
+	// reused
+	ExpiresAt time.Time
+
+	// Remove removes the workspace
+	Remove func(ctx context.Context) error
+}
+
+type workspaceStore struct {
+	mutex      sync.Mutex
+	workspaces map[string]retainedWorkspace
+}
+
+func newWorkspaceStore() *workspaceStore {
+	return &workspaceStore{workspaces: map[string]retainedWorkspace{}}
+}
+
+// Retain stores the workspace of the automation identified by
+// `automationId` until it is taken or it expires
+func (s *workspaceStore) Retain(automationId string, workspace retainedWorkspace) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.workspaces[automationId] = workspace
+}
+
+// Take removes the workspace of the automation identified by
+// `automationId` from the store and returns it, the second return value
+// is false if there is no workspace created by `runtime` which has not
+// expired
+func (s *workspaceStore) Take(automationId, runtime string) (retainedWorkspace, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	workspace, ok := s.workspaces[automationId]
+	if !ok || workspace.Runtime != runtime || time.Now().After(workspace.ExpiresAt) {
+		return retainedWorkspace{}, false
+	}
+	delete(s.workspaces, automationId)
+	return workspace, true
+}
+
+// Prune removes the workspaces which have expired
+func (s *workspaceStore) Prune(ctx context.Context, serviceLogs chan<- common.ServiceLog) {
+	s.remove(ctx, serviceLogs, func(workspace retainedWorkspace) bool {
+		return time.Now().After(workspace.ExpiresAt)
+	})
+}
+
+// Release removes all workspaces, this is called when the worker exits
+func (s *workspaceStore) Release(ctx context.Context, serviceLogs chan<- common.ServiceLog) {
+	s.remove(ctx, serviceLogs, func(retainedWorkspace) bool { return true })
+}
+
+func (s *workspaceStore) remove(ctx context.Context, serviceLogs chan<- common.ServiceLog, shouldRemove func(retainedWorkspace) bool) {
+	s.mutex.Lock()
+	workspacesToRemove := map[string]retainedWorkspace{}
+	for automationId, workspace := range s.workspaces {
+		if shouldRemove(workspace) {
+			workspacesToRemove[automationId] = workspace
+			delete(s.workspaces, automationId)
+		}
+	}
+	s.mutex.Unlock()
+	for automationId, workspace := range workspacesToRemove {
+		if err := workspace.Remove(ctx); err != nil {
+			serviceLogs <- common.ServiceLogf(common.LogLevelWarn, "failed to remove retained workspace[%s] of automation[%s]: %s", workspace.Name, automationId, err)
+			continue
+		}
+		serviceLogs <- common.ServiceLogf(common.LogLevelDebug, "removed retained workspace[%s] of automation[%s]", workspace.Name, automationId)
+	}
+}
+
+// takeParentWorkspace returns the retained workspace of the automation
+// being re-run if it was created by `runtime`
+func (s automationSpec) takeParentWorkspace(runtime string) (retainedWorkspace, bool) {
+	if s.ParentId == "" {
+		return retainedWorkspace{}, false
+	}
+	workspace, ok := retainedWorkspaces.Take(s.ParentId, runtime)
+	if ok {
+		s.ServiceLogs <- common.ServiceLogf(common.LogLevelInfo, "automation[%s]: reusing workspace[%s] of automation[%s]", s.Id, workspace.Name, s.ParentId)
+	}
+	return workspace, ok
+}
+
+// retainWorkspace stores the workspace of the automation for re-runs
+// to reuse when the spec has a workspace retention, false is returned
+// when the workspace was not retained and should be removed
+func (s automationSpec) retainWorkspace(runtime, name string, remove func(ctx context.Context) error) bool {
+	if s.WorkspaceRetention <= 0 {
+		return false
+	}
+	retainedWorkspaces.Retain(s.Id, retainedWorkspace{
+		Runtime:   runtime,
+		Name:      name,
+		ExpiresAt: time.Now().Add(s.WorkspaceRetention),
+		Remove:    remove,
+	})
+	s.ServiceLogs <- common.ServiceLogf(common.LogLevelDebug, "automation[%s]: retaining workspace[%s] for %v", s.Id, name, s.WorkspaceRetention)
+	return true
+}
@@ -9,6 +9,8 @@ import (
 	"net/url"
 	"opsicle/internal/approvals"
 	"opsicle/internal/common"
+	"strconv"
+	"time"
 )
 
 type NewClientOpts struct {
@@ -66,13 +68,22 @@ type Client struct {
 // returns the UUID of the request issued by the approver service
 func (c *Client) CreateApprovalRequest(input CreateApprovalRequestInput) (requestUuid string, err error) {
 	approvalRequest := approvals.RequestSpec{
-		Id:            input.Id,
-		Links:         input.Links,
-		Message:       input.Message,
-		RequesterId:   input.RequesterId,
-		RequesterName: input.RequesterName,
-		Slack:         input.Slack,
-		Telegram:      input.Telegram,
+		ApproverIdentities:   input.ApproverIdentities,
+		Callback:             input.Callback,
+		DisallowSelfApproval: input.DisallowSelfApproval,
+		Escalation:           input.Escalation,
+		Id:                   input.Id,
+		Links:                input.Links,
+		Message:              input.Message,
+		MinApprovals:         input.MinApprovals,
+		Reminders:            input.Reminders,
+		RequesterId:          input.RequesterId,
+		RequesterIdentities:  input.RequesterIdentities,
+		RequesterName:        input.RequesterName,
+		Slack:                input.Slack,
+		Stages:               input.Stages,
+		Telegram:             input.Telegram,
+		TtlSeconds:           input.TtlSeconds,
 	}
 	approvalRequestData, err := json.Marshal(approvalRequest)
 	if err != nil {
@@ -212,9 +223,31 @@ func (c *Client) GetApprovalRequest(requestUuid string) (*approvals.RequestSpec,
 
 }
 
-func (c *Client) ListApprovalRequests() ([]string, error) {
+// ListApprovalRequests returns the approval requests matching `filter`
+// from the newest to the oldest
+func (c *Client) ListApprovalRequests(filter approvals.RequestFilter) ([]approvals.RequestSpec, error) {
 	approverUrl := *c.ApproverUrl
 	approverUrl.Path = "/api/v1/approval-request"
+	query := url.Values{}
+	if filter.Status != "" {
+		query.Set("status", string(filter.Status))
+	}
+	if filter.Requester != "" {
+		query.Set("requester", filter.Requester)
+	}
+	if filter.CreatedAfter != nil {
+		query.Set("createdAfter", filter.CreatedAfter.Format(time.RFC3339))
+	}
+	if filter.CreatedBefore != nil {
+		query.Set("createdBefore", filter.CreatedBefore.Format(time.RFC3339))
+	}
+	if filter.Platform != "" {
+		query.Set("platform", string(filter.Platform))
+	}
+	if filter.Limit > 0 {
+		query.Set("limit", strconv.Itoa(filter.Limit))
+	}
+	approverUrl.RawQuery = query.Encode()
 	httpRequest, err := http.NewRequest(
 		http.MethodGet,
 		approverUrl.String(),
@@ -250,11 +283,11 @@ func (c *Client) ListApprovalRequests() ([]string, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse response data from approver service: %w", err)
 	}
-	var approvalRequestKeys []string
-	if err := json.Unmarshal(responseData, &approvalRequestKeys); err != nil {
-		return nil, fmt.Errorf("failed to parse response from approver service into an approval: %w", err)
+	approvalRequests := []approvals.RequestSpec{}
+	if err := json.Unmarshal(responseData, &approvalRequests); err != nil {
+		return nil, fmt.Errorf("failed to parse response from approver service into approval requests: %w", err)
 	}
-	return approvalRequestKeys, nil
+	return approvalRequests, nil
 }
 
 func (c *Client) Ping() error {
@@ -5,10 +5,24 @@ import "opsicle/internal/approvals"
 // CreateApprovalRequestInput represents data meant to be sent to the approval
 // request creation endpoint
 type CreateApprovalRequestInput struct {
+	// ApproverIdentities are the users who can respond to the request
+	// with their users on the platforms the request is sent to, these
+	// are required for a user responding from more than one platform to
+	// count as a single responder
+	ApproverIdentities []approvals.ApproverIdentitySpec `json:"approverIdentities" yaml:"approverIdentities"`
+
 	// Callback is a field that when specified, results in the approver
 	// service processing a callback to the specified endpoint
 	Callback *approvals.CallbackSpec `json:"callback" yaml:"callback"`
 
+	// DisallowSelfApproval when true prevents the requester from
+	// approving the request
+	DisallowSelfApproval bool `json:"disallowSelfApproval" yaml:"disallowSelfApproval"`
+
+	// Escalation when specified escalates the request to additional
+	// targets when nobody responds to it in time
+	Escalation *approvals.EscalationSpec `json:"escalation" yaml:"escalation"`
+
 	// Id is the ID of a request which will be the same for all
 	// requests of a given type
 	Id string `json:"id" yaml:"id"`
@@ -19,15 +33,37 @@ type CreateApprovalRequestInput struct {
 	// Message is an additional message describing the request
 	Message string `json:"message" yaml:"message"`
 
+	// MinApprovals is the number of distinct responders who have to
+	// approve the request when it has no stages, defaults to 1
+	MinApprovals int `json:"minApprovals" yaml:"minApprovals"`
+
+	// Reminders when specified results in reminders being sent to the
+	// targets of the request until it is decided or expires
+	Reminders *approvals.ReminderSpec `json:"reminders" yaml:"reminders"`
+
 	// RequesterName indicates the requester's system ID
 	RequesterId string `json:"requesterId" yaml:"requesterId"`
 
 	// RequesterName indicates the requester's name
 	RequesterName string `json:"requesterName" yaml:"requesterName"`
 
+	// RequesterIdentities are the users of the requester on the
+	// platforms the request is sent to, these are required for
+	// DisallowSelfApproval to recognise the requester
+	RequesterIdentities *approvals.RequesterIdentitiesSpec `json:"requesterIdentities" yaml:"requesterIdentities"`
+
 	// Slack specifies the targets in Slack to send this request to
 	Slack []approvals.SlackRequestSpec `json:"slack" yaml:"slack"`
 
+	// Stages when specified are approved in order with each stage being
+	// sent only after the previous one is approved, Slack and Telegram
+	// are ignored when stages are specified
+	Stages []approvals.RequestStageSpec `json:"stages" yaml:"stages"`
+
 	// Telegram specifies the targets in Telegram to send this request to
 	Telegram []approvals.TelegramRequestSpec `json:"telegram" yaml:"telegram"`
+
+	// TtlSeconds indicates the duration in seconds until the request
+	// expires, defaults to approvals.DefaultRequestTtl
+	TtlSeconds int `json:"ttlSeconds" yaml:"ttlSeconds"`
 }
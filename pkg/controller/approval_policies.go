@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"opsicle/internal/controller"
+	"opsicle/internal/types"
+)
+
+type SetOrgApprovalPolicyV1Output struct {
+	Data controller.OrgApprovalPolicyV1Output
+	http.Response
+}
+
+type SetOrgApprovalPolicyV1Input struct {
+	OrgId string `json:"-"`
+
+	// Data is the YAML of an ApprovalPolicy resource
+	Data []byte `json:"data"`
+}
+
+// SetOrgApprovalPolicyV1 creates an approval policy in the org or
+// replaces the spec of an existing one with the same name
+func (c Client) SetOrgApprovalPolicyV1(input SetOrgApprovalPolicyV1Input) (*SetOrgApprovalPolicyV1Output, error) {
+	var outputData controller.OrgApprovalPolicyV1Output
+	outputClient, err := c.do(request{
+		Method: http.MethodPost,
+		Path:   fmt.Sprintf("/api/v1/org/%s/approval-policy", input.OrgId),
+		Data:   input,
+		Output: &outputData,
+	})
+	var output *SetOrgApprovalPolicyV1Output = nil
+	if !errors.Is(err, types.ErrorOutputNil) {
+		output = &SetOrgApprovalPolicyV1Output{
+			Data:     outputData,
+			Response: outputClient.Response,
+		}
+	}
+	return output, err
+}
+
+type ListOrgApprovalPoliciesV1Output struct {
+	Data controller.ListOrgApprovalPoliciesV1Output
+	http.Response
+}
+
+type ListOrgApprovalPoliciesV1Input struct {
+	OrgId string
+}
+
+// ListOrgApprovalPoliciesV1 returns the approval policies of the org
+func (c Client) ListOrgApprovalPoliciesV1(input ListOrgApprovalPoliciesV1Input) (*ListOrgApprovalPoliciesV1Output, error) {
+	var outputData controller.ListOrgApprovalPoliciesV1Output
+	outputClient, err := c.do(request{
+		Method: http.MethodGet,
+		Path:   fmt.Sprintf("/api/v1/org/%s/approval-policies", input.OrgId),
+		Output: &outputData,
+	})
+	var output *ListOrgApprovalPoliciesV1Output = nil
+	if !errors.Is(err, types.ErrorOutputNil) {
+		output = &ListOrgApprovalPoliciesV1Output{
+			Data:     outputData,
+			Response: outputClient.Response,
+		}
+	}
+	return output, err
+}
+
+type GetOrgApprovalPolicyV1Output struct {
+	Data controller.OrgApprovalPolicyV1Output
+	http.Response
+}
+
+type GetOrgApprovalPolicyV1Input struct {
+	OrgId string
+	Name  string
+}
+
+// GetOrgApprovalPolicyV1 returns the approval policy identified by
+// `.Name` in the org
+func (c Client) GetOrgApprovalPolicyV1(input GetOrgApprovalPolicyV1Input) (*GetOrgApprovalPolicyV1Output, error) {
+	var outputData controller.OrgApprovalPolicyV1Output
+	outputClient, err := c.do(request{
+		Method: http.MethodGet,
+		Path:   fmt.Sprintf("/api/v1/org/%s/approval-policy/%s", input.OrgId, input.Name),
+		Output: &outputData,
+	})
+	var output *GetOrgApprovalPolicyV1Output = nil
+	if !errors.Is(err, types.ErrorOutputNil) {
+		output = &GetOrgApprovalPolicyV1Output{
+			Data:     outputData,
+			Response: outputClient.Response,
+		}
+	}
+	return output, err
+}
+
+type DeleteOrgApprovalPolicyV1Output struct {
+	http.Response
+}
+
+type DeleteOrgApprovalPolicyV1Input struct {
+	OrgId string
+	Name  string
+}
+
+// DeleteOrgApprovalPolicyV1 deletes an approval policy from the org
+func (c Client) DeleteOrgApprovalPolicyV1(input DeleteOrgApprovalPolicyV1Input) (*DeleteOrgApprovalPolicyV1Output, error) {
+	var outputData any
+	outputClient, err := c.do(request{
+		Method: http.MethodDelete,
+		Path:   fmt.Sprintf("/api/v1/org/%s/approval-policy/%s", input.OrgId, input.Name),
+		Output: &outputData,
+	})
+	var output *DeleteOrgApprovalPolicyV1Output = nil
+	if !errors.Is(err, types.ErrorOutputNil) {
+		output = &DeleteOrgApprovalPolicyV1Output{
+			Response: outputClient.Response,
+		}
+	}
+	return output, err
+}
@@ -4,8 +4,12 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"opsicle/internal/automations"
 	"opsicle/internal/controller"
 	"opsicle/internal/types"
+	"strconv"
+	"time"
 )
 
 type CreateAutomationV1Output struct {
@@ -69,3 +73,320 @@ func (c Client) RunAutomationV1(input RunAutomationV1Input) (*RunAutomationV1Out
 	}
 	return output, err
 }
+
+type GetAutomationV1Output struct {
+	Data controller.GetAutomationV1Output
+	http.Response
+}
+
+type GetAutomationV1Input struct {
+	AutomationId string
+}
+
+func (c Client) GetAutomationV1(input GetAutomationV1Input) (*GetAutomationV1Output, error) {
+	var outputData controller.GetAutomationV1Output
+	outputClient, err := c.do(request{
+		Method: http.MethodGet,
+		Path:   fmt.Sprintf("/api/v1/automation/%s", input.AutomationId),
+		Output: &outputData,
+	})
+	var output *GetAutomationV1Output = nil
+	if !errors.Is(err, types.ErrorOutputNil) {
+		output = &GetAutomationV1Output{
+			Data:     outputData,
+			Response: outputClient.Response,
+		}
+	}
+	return output, err
+}
+
+type CancelAutomationV1Output struct {
+	Data controller.CancelAutomationV1Output
+	http.Response
+}
+
+type CancelAutomationV1Input struct {
+	AutomationId string `json:"-"`
+	Reason       string `json:"reason"`
+}
+
+// CancelAutomationV1 cancels an automation run, runs which are executing
+// are stopped by the worker processing them
+func (c Client) CancelAutomationV1(input CancelAutomationV1Input) (*CancelAutomationV1Output, error) {
+	var outputData controller.CancelAutomationV1Output
+	outputClient, err := c.do(request{
+		Method: http.MethodPost,
+		Path:   fmt.Sprintf("/api/v1/automation/%s/cancel", input.AutomationId),
+		Data:   input,
+		Output: &outputData,
+	})
+	var output *CancelAutomationV1Output = nil
+	if !errors.Is(err, types.ErrorOutputNil) {
+		output = &CancelAutomationV1Output{
+			Data:     outputData,
+			Response: outputClient.Response,
+		}
+	}
+	return output, err
+}
+
+type RerunAutomationV1Output struct {
+	Data controller.RerunAutomationV1Output
+	http.Response
+}
+
+type RerunAutomationV1Input struct {
+	AutomationId string `json:"-"`
+	Comment      string `json:"comment"`
+	FromPhase    string `json:"fromPhase"`
+}
+
+// RerunAutomationV1 creates a pending automation from a completed
+// automation run which is then run using RunAutomationV1, the pending
+// automation uses the variables of the completed run unless they are
+// overridden
+func (c Client) RerunAutomationV1(input RerunAutomationV1Input) (*RerunAutomationV1Output, error) {
+	var outputData controller.RerunAutomationV1Output
+	outputClient, err := c.do(request{
+		Method: http.MethodPost,
+		Path:   fmt.Sprintf("/api/v1/automation/%s/rerun", input.AutomationId),
+		Data:   input,
+		Output: &outputData,
+	})
+	var output *RerunAutomationV1Output = nil
+	if !errors.Is(err, types.ErrorOutputNil) {
+		output = &RerunAutomationV1Output{
+			Data:     outputData,
+			Response: outputClient.Response,
+		}
+	}
+	return output, err
+}
+
+type UpdateAutomationStatusV1Output struct {
+	Data automations.RunStatus
+	http.Response
+}
+
+type UpdateAutomationStatusV1Input struct {
+	AutomationId string                      `json:"-"`
+	OrgId        *string                     `json:"orgId"`
+	Update       automations.RunStatusUpdate `json:"update"`
+}
+
+// UpdateAutomationStatusV1 reports a status update of an automation
+// run to the controller, this requires the client to be created with
+// an `ApiKey`
+func (c Client) UpdateAutomationStatusV1(input UpdateAutomationStatusV1Input) (*UpdateAutomationStatusV1Output, error) {
+	var outputData automations.RunStatus
+	outputClient, err := c.do(request{
+		Method: http.MethodPost,
+		Path:   fmt.Sprintf("/api/v1/automation/%s/status", input.AutomationId),
+		Data:   input,
+		Output: &outputData,
+	})
+	var output *UpdateAutomationStatusV1Output = nil
+	if !errors.Is(err, types.ErrorOutputNil) {
+		output = &UpdateAutomationStatusV1Output{
+			Data:     outputData,
+			Response: outputClient.Response,
+		}
+	}
+	return output, err
+}
+
+type ResolveAutomationSecretsV1Output struct {
+	Data controller.ResolveAutomationSecretsV1Output
+	http.Response
+}
+
+type ResolveAutomationSecretsV1Input struct {
+	AutomationId string  `json:"-"`
+	OrgId        *string `json:"orgId"`
+}
+
+// ResolveAutomationSecretsV1 returns the values of the secret variables
+// an automation run was submitted with, this requires the client to be
+// created with an `ApiKey`
+func (c Client) ResolveAutomationSecretsV1(input ResolveAutomationSecretsV1Input) (*ResolveAutomationSecretsV1Output, error) {
+	var outputData controller.ResolveAutomationSecretsV1Output
+	outputClient, err := c.do(request{
+		Method: http.MethodPost,
+		Path:   fmt.Sprintf("/api/v1/automation/%s/secrets/resolve", input.AutomationId),
+		Data:   input,
+		Output: &outputData,
+	})
+	var output *ResolveAutomationSecretsV1Output = nil
+	if !errors.Is(err, types.ErrorOutputNil) {
+		output = &ResolveAutomationSecretsV1Output{
+			Data:     outputData,
+			Response: outputClient.Response,
+		}
+	}
+	return output, err
+}
+
+type GetAutomationLogsV1Output struct {
+	Data controller.GetAutomationLogsV1Output
+	http.Response
+}
+
+type GetAutomationLogsV1Input struct {
+	AutomationId string
+
+	// Offset is the position in the logs to retrieve logs after, use
+	// the `.Data.Offset` of a previous output to retrieve subsequent
+	// logs
+	Offset int
+
+	// Wait when defined is the duration the controller should wait for
+	// logs after `.Offset` before responding, this must be lower than
+	// the client's request timeout
+	Wait time.Duration
+}
+
+func (c Client) GetAutomationLogsV1(input GetAutomationLogsV1Input) (*GetAutomationLogsV1Output, error) {
+	var outputData controller.GetAutomationLogsV1Output
+	query := url.Values{"offset": []string{strconv.Itoa(input.Offset)}}
+	if input.Wait != 0 {
+		query.Set("wait", input.Wait.String())
+	}
+	outputClient, err := c.do(request{
+		Method: http.MethodGet,
+		Path:   fmt.Sprintf("/api/v1/automation/%s/logs", input.AutomationId),
+		Query:  query,
+		Output: &outputData,
+	})
+	var output *GetAutomationLogsV1Output = nil
+	if !errors.Is(err, types.ErrorOutputNil) {
+		output = &GetAutomationLogsV1Output{
+			Data:     outputData,
+			Response: outputClient.Response,
+		}
+	}
+	return output, err
+}
+
+type UploadAutomationArtifactsV1Output struct {
+	Data controller.UploadAutomationArtifactsV1Output
+	http.Response
+}
+
+type UploadAutomationArtifactsV1Input struct {
+	AutomationId string  `json:"-"`
+	OrgId        *string `json:"orgId"`
+	Content      []byte  `json:"content"`
+}
+
+// UploadAutomationArtifactsV1 stores the artifacts of an automation run
+// in the controller, this requires the client to be created with an
+// `ApiKey`
+func (c Client) UploadAutomationArtifactsV1(input UploadAutomationArtifactsV1Input) (*UploadAutomationArtifactsV1Output, error) {
+	var outputData controller.UploadAutomationArtifactsV1Output
+	outputClient, err := c.do(request{
+		Method: http.MethodPost,
+		Path:   fmt.Sprintf("/api/v1/automation/%s/artifacts", input.AutomationId),
+		Data:   input,
+		Output: &outputData,
+	})
+	var output *UploadAutomationArtifactsV1Output = nil
+	if !errors.Is(err, types.ErrorOutputNil) {
+		output = &UploadAutomationArtifactsV1Output{
+			Data:     outputData,
+			Response: outputClient.Response,
+		}
+	}
+	return output, err
+}
+
+type GetAutomationArtifactsV1Output struct {
+	Data controller.GetAutomationArtifactsV1Output
+	http.Response
+}
+
+type GetAutomationArtifactsV1Input struct {
+	AutomationId string
+}
+
+func (c Client) GetAutomationArtifactsV1(input GetAutomationArtifactsV1Input) (*GetAutomationArtifactsV1Output, error) {
+	var outputData controller.GetAutomationArtifactsV1Output
+	outputClient, err := c.do(request{
+		Method: http.MethodGet,
+		Path:   fmt.Sprintf("/api/v1/automation/%s/artifacts", input.AutomationId),
+		Output: &outputData,
+	})
+	var output *GetAutomationArtifactsV1Output = nil
+	if !errors.Is(err, types.ErrorOutputNil) {
+		output = &GetAutomationArtifactsV1Output{
+			Data:     outputData,
+			Response: outputClient.Response,
+		}
+	}
+	return output, err
+}
+
+type ListAutomationsV1Output struct {
+	Data controller.ListAutomationsV1Output
+	http.Response
+}
+
+type ListAutomationsV1Input struct {
+	// OrgId when defined lists the automations of the org instead of
+	// the automations triggered by the user
+	OrgId *string
+
+	TemplateId  string
+	Status      string
+	TriggeredBy string
+	Since       *time.Time
+	Until       *time.Time
+	Comment     string
+
+	// Cursor is the `nextCursor` of the previous page
+	Cursor string
+	Limit  int
+}
+
+// ListAutomationsV1 returns a page of automation runs, latest first
+func (c Client) ListAutomationsV1(input ListAutomationsV1Input) (*ListAutomationsV1Output, error) {
+	var outputData controller.ListAutomationsV1Output
+	path := "/api/v1/automations"
+	if input.OrgId != nil {
+		path = fmt.Sprintf("/api/v1/org/%s/automations", *input.OrgId)
+	}
+	query := url.Values{}
+	for key, value := range map[string]string{
+		"templateId":  input.TemplateId,
+		"status":      input.Status,
+		"triggeredBy": input.TriggeredBy,
+		"comment":     input.Comment,
+		"cursor":      input.Cursor,
+	} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	if input.Since != nil {
+		query.Set("since", input.Since.Format(time.RFC3339))
+	}
+	if input.Until != nil {
+		query.Set("until", input.Until.Format(time.RFC3339))
+	}
+	if input.Limit > 0 {
+		query.Set("limit", strconv.Itoa(input.Limit))
+	}
+	outputClient, err := c.do(request{
+		Method: http.MethodGet,
+		Path:   path,
+		Query:  query,
+		Output: &outputData,
+	})
+	var output *ListAutomationsV1Output = nil
+	if !errors.Is(err, types.ErrorOutputNil) {
+		output = &ListAutomationsV1Output{
+			Data:     outputData,
+			Response: outputClient.Response,
+		}
+	}
+	return output, err
+}
@@ -24,6 +24,9 @@ var (
 )
 
 type NewClientOpts struct {
+	// ApiKey when defined is sent in the `x-api-key` header and is
+	// required for internal-only endpoints
+	ApiKey         string
 	ControllerUrl  string
 	BasicAuth      *NewClientBasicAuthOpts
 	BearerAuth     *NewClientBearerAuthOpts
@@ -57,6 +60,7 @@ func NewClient(opts NewClientOpts) (*Client, error) {
 		timeout = opts.RequestTimeout
 	}
 	client := &Client{
+		ApiKey:     opts.ApiKey,
 		BasicAuth:  opts.BasicAuth,
 		BearerAuth: opts.BearerAuth,
 		HttpClient: &http.Client{
@@ -89,6 +93,7 @@ func NewClient(opts NewClientOpts) (*Client, error) {
 type request struct {
 	Method string
 	Path   string
+	Query  url.Values
 	Data   any
 	Output any
 }
@@ -122,6 +127,8 @@ func (c clientOutput) Error() error {
 
 func (c clientOutput) GetErrorCode() error {
 	switch c.code.Error() {
+	case types.ErrorAutomationCompleted.Error():
+		return types.ErrorAutomationCompleted
 	case types.ErrorInvalidCredentials.Error():
 		return types.ErrorInvalidCredentials
 	case types.ErrorInvalidInput.Error():
@@ -130,6 +137,8 @@ func (c clientOutput) GetErrorCode() error {
 		return types.ErrorInsufficientPermissions
 	case types.ErrorDatabaseIssue.Error():
 		return types.ErrorDatabaseIssue
+	case types.ErrorNotFound.Error():
+		return types.ErrorNotFound
 	}
 	return c.code
 }
@@ -150,6 +159,7 @@ type Client struct {
 	// ControllerUrl is the URL where the approver service is accessible
 	// at
 	ControllerUrl *url.URL
+	ApiKey        string
 	BasicAuth     *NewClientBasicAuthOpts
 	BearerAuth    *NewClientBearerAuthOpts
 
@@ -176,6 +186,9 @@ func (c Client) WithAuth(auth ...string) Client {
 func (c Client) addRequiredHeaders(httpRequest *http.Request) {
 	httpRequest.Header.Add("Content-Type", "application/json")
 	httpRequest.Header.Add("User-Agent", fmt.Sprintf("opsicle/controller-sdk/client-%s", c.Id))
+	if c.ApiKey != "" {
+		httpRequest.Header.Add("x-api-key", c.ApiKey)
+	}
 	if c.BasicAuth != nil {
 		httpRequest.SetBasicAuth(c.BasicAuth.Username, c.BasicAuth.Password)
 	}
@@ -187,6 +200,9 @@ func (c Client) addRequiredHeaders(httpRequest *http.Request) {
 func (c Client) do(input request) (*clientOutput, error) {
 	controllerUrl := *c.ControllerUrl
 	controllerUrl.Path = input.Path
+	if input.Query != nil {
+		controllerUrl.RawQuery = input.Query.Encode()
+	}
 	var requestBody *bytes.Buffer = nil
 	if input.Data != nil {
 		inputData, err := json.Marshal(input.Data)
@@ -594,6 +594,48 @@ func (c Client) GetOrgTokenV1(input GetOrgTokenV1Input) (*GetOrgTokenV1Output, e
 	return output, err
 }
 
+type VerifyOrgTokenCertificateV1Output struct {
+	Data controller.VerifyOrgTokenCertificateV1Output
+
+	http.Response
+}
+
+type VerifyOrgTokenCertificateV1Input struct {
+	OrgId            string `json:"-"`
+	TokenId          string `json:"-"`
+	CaCertificateB64 string `json:"caCertificateB64"`
+}
+
+// VerifyOrgTokenCertificateV1 verifies that the token exists in the org
+// and that its client certificate was verified by the certificate
+// authority of the org, this requires the client to be created with an
+// `ApiKey`
+func (c Client) VerifyOrgTokenCertificateV1(input VerifyOrgTokenCertificateV1Input) (*VerifyOrgTokenCertificateV1Output, error) {
+	var outputData controller.VerifyOrgTokenCertificateV1Output
+	outputClient, err := c.do(request{
+		Method: http.MethodPost,
+		Path:   fmt.Sprintf("/api/v1/org/%s/token/%s/certificate/verify", input.OrgId, input.TokenId),
+		Data:   input,
+		Output: &outputData,
+	})
+	var output *VerifyOrgTokenCertificateV1Output
+	if outputClient != nil {
+		output = &VerifyOrgTokenCertificateV1Output{
+			Data:     outputData,
+			Response: outputClient.Response,
+		}
+	}
+	if err != nil && outputClient != nil {
+		switch outputClient.GetErrorCode().Error() {
+		case types.ErrorInvalidCredentials.Error():
+			err = types.ErrorInvalidCredentials
+		case types.ErrorNotFound.Error():
+			err = types.ErrorNotFound
+		}
+	}
+	return output, err
+}
+
 type CreateOrgTokenV1Input controller.CreateOrgTokenV1Input
 
 type CreateOrgTokenV1Output struct {
@@ -0,0 +1,125 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"opsicle/internal/controller"
+	"opsicle/internal/types"
+)
+
+type SetOrgSecretV1Output struct {
+	Data controller.OrgSecretV1Output
+	http.Response
+}
+
+type SetOrgSecretV1Input struct {
+	OrgId       string  `json:"-"`
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+	Value       string  `json:"value"`
+}
+
+// SetOrgSecretV1 creates a secret in the org or replaces the value of
+// an existing one
+func (c Client) SetOrgSecretV1(input SetOrgSecretV1Input) (*SetOrgSecretV1Output, error) {
+	var outputData controller.OrgSecretV1Output
+	outputClient, err := c.do(request{
+		Method: http.MethodPost,
+		Path:   fmt.Sprintf("/api/v1/org/%s/secret", input.OrgId),
+		Data:   input,
+		Output: &outputData,
+	})
+	var output *SetOrgSecretV1Output = nil
+	if !errors.Is(err, types.ErrorOutputNil) {
+		output = &SetOrgSecretV1Output{
+			Data:     outputData,
+			Response: outputClient.Response,
+		}
+	}
+	return output, err
+}
+
+type ListOrgSecretsV1Output struct {
+	Data controller.ListOrgSecretsV1Output
+	http.Response
+}
+
+type ListOrgSecretsV1Input struct {
+	OrgId string
+}
+
+// ListOrgSecretsV1 returns the metadata of secrets in the org
+func (c Client) ListOrgSecretsV1(input ListOrgSecretsV1Input) (*ListOrgSecretsV1Output, error) {
+	var outputData controller.ListOrgSecretsV1Output
+	outputClient, err := c.do(request{
+		Method: http.MethodGet,
+		Path:   fmt.Sprintf("/api/v1/org/%s/secret", input.OrgId),
+		Output: &outputData,
+	})
+	var output *ListOrgSecretsV1Output = nil
+	if !errors.Is(err, types.ErrorOutputNil) {
+		output = &ListOrgSecretsV1Output{
+			Data:     outputData,
+			Response: outputClient.Response,
+		}
+	}
+	return output, err
+}
+
+type DeleteOrgSecretV1Output struct {
+	http.Response
+}
+
+type DeleteOrgSecretV1Input struct {
+	OrgId string
+	Name  string
+}
+
+// DeleteOrgSecretV1 deletes a secret from the org
+func (c Client) DeleteOrgSecretV1(input DeleteOrgSecretV1Input) (*DeleteOrgSecretV1Output, error) {
+	var outputData any
+	outputClient, err := c.do(request{
+		Method: http.MethodDelete,
+		Path:   fmt.Sprintf("/api/v1/org/%s/secret/%s", input.OrgId, input.Name),
+		Output: &outputData,
+	})
+	var output *DeleteOrgSecretV1Output = nil
+	if !errors.Is(err, types.ErrorOutputNil) {
+		output = &DeleteOrgSecretV1Output{
+			Response: outputClient.Response,
+		}
+	}
+	return output, err
+}
+
+type ResolveOrgSecretsV1Output struct {
+	Data controller.ResolveOrgSecretsV1Output
+	http.Response
+}
+
+type ResolveOrgSecretsV1Input struct {
+	OrgId string   `json:"-"`
+	Names []string `json:"names"`
+}
+
+// ResolveOrgSecretsV1 returns the values of the secrets identified by
+// `.Names` in the org; this requires the client to be created with an
+// `ApiKey`
+func (c Client) ResolveOrgSecretsV1(input ResolveOrgSecretsV1Input) (*ResolveOrgSecretsV1Output, error) {
+	var outputData controller.ResolveOrgSecretsV1Output
+	outputClient, err := c.do(request{
+		Method: http.MethodPost,
+		Path:   fmt.Sprintf("/api/v1/org/%s/secrets/resolve", input.OrgId),
+		Data:   input,
+		Output: &outputData,
+	})
+	var output *ResolveOrgSecretsV1Output = nil
+	if !errors.Is(err, types.ErrorOutputNil) {
+		output = &ResolveOrgSecretsV1Output{
+			Data:     outputData,
+			Response: outputClient.Response,
+		}
+	}
+	return output, err
+}
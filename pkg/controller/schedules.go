@@ -0,0 +1,139 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"opsicle/internal/controller"
+	"opsicle/internal/types"
+)
+
+type CreateAutomationScheduleV1Output struct {
+	Data controller.AutomationScheduleV1Output
+	http.Response
+}
+
+type CreateAutomationScheduleV1Input struct {
+	Name            string         `json:"name"`
+	CronExpression  string         `json:"cronExpression"`
+	Timezone        string         `json:"timezone"`
+	OrgId           *string        `json:"orgId"`
+	TemplateId      string         `json:"templateId"`
+	TemplateVersion *int64         `json:"templateVersion"`
+	VariableMap     map[string]any `json:"variableMap"`
+}
+
+// CreateAutomationScheduleV1 creates a schedule which triggers
+// automations from a template whenever its cron expression fires
+func (c Client) CreateAutomationScheduleV1(input CreateAutomationScheduleV1Input) (*CreateAutomationScheduleV1Output, error) {
+	var outputData controller.AutomationScheduleV1Output
+	outputClient, err := c.do(request{
+		Method: http.MethodPost,
+		Path:   "/api/v1/schedule",
+		Data:   input,
+		Output: &outputData,
+	})
+	var output *CreateAutomationScheduleV1Output = nil
+	if !errors.Is(err, types.ErrorOutputNil) {
+		output = &CreateAutomationScheduleV1Output{
+			Data:     outputData,
+			Response: outputClient.Response,
+		}
+	}
+	return output, err
+}
+
+type ListAutomationSchedulesV1Output struct {
+	Data controller.ListAutomationSchedulesV1Output
+	http.Response
+}
+
+type ListAutomationSchedulesV1Input struct {
+	// OrgId when defined lists the schedules of the org instead of the
+	// user's own schedules
+	OrgId *string
+}
+
+// ListAutomationSchedulesV1 returns the schedules of an org or those
+// created by the user outside of orgs
+func (c Client) ListAutomationSchedulesV1(input ListAutomationSchedulesV1Input) (*ListAutomationSchedulesV1Output, error) {
+	var outputData controller.ListAutomationSchedulesV1Output
+	var query url.Values
+	if input.OrgId != nil {
+		query = url.Values{"orgId": []string{*input.OrgId}}
+	}
+	outputClient, err := c.do(request{
+		Method: http.MethodGet,
+		Path:   "/api/v1/schedules",
+		Query:  query,
+		Output: &outputData,
+	})
+	var output *ListAutomationSchedulesV1Output = nil
+	if !errors.Is(err, types.ErrorOutputNil) {
+		output = &ListAutomationSchedulesV1Output{
+			Data:     outputData,
+			Response: outputClient.Response,
+		}
+	}
+	return output, err
+}
+
+type SetAutomationSchedulePausedV1Output struct {
+	Data controller.AutomationScheduleV1Output
+	http.Response
+}
+
+type SetAutomationSchedulePausedV1Input struct {
+	ScheduleId string
+	IsPaused   bool
+}
+
+// SetAutomationSchedulePausedV1 pauses or resumes a schedule, runs that
+// are missed while a schedule is paused are not triggered when it is
+// resumed
+func (c Client) SetAutomationSchedulePausedV1(input SetAutomationSchedulePausedV1Input) (*SetAutomationSchedulePausedV1Output, error) {
+	var outputData controller.AutomationScheduleV1Output
+	action := "resume"
+	if input.IsPaused {
+		action = "pause"
+	}
+	outputClient, err := c.do(request{
+		Method: http.MethodPost,
+		Path:   fmt.Sprintf("/api/v1/schedule/%s/%s", input.ScheduleId, action),
+		Output: &outputData,
+	})
+	var output *SetAutomationSchedulePausedV1Output = nil
+	if !errors.Is(err, types.ErrorOutputNil) {
+		output = &SetAutomationSchedulePausedV1Output{
+			Data:     outputData,
+			Response: outputClient.Response,
+		}
+	}
+	return output, err
+}
+
+type DeleteAutomationScheduleV1Output struct {
+	http.Response
+}
+
+type DeleteAutomationScheduleV1Input struct {
+	ScheduleId string
+}
+
+// DeleteAutomationScheduleV1 deletes a schedule
+func (c Client) DeleteAutomationScheduleV1(input DeleteAutomationScheduleV1Input) (*DeleteAutomationScheduleV1Output, error) {
+	var outputData any
+	outputClient, err := c.do(request{
+		Method: http.MethodDelete,
+		Path:   fmt.Sprintf("/api/v1/schedule/%s", input.ScheduleId),
+		Output: &outputData,
+	})
+	var output *DeleteAutomationScheduleV1Output = nil
+	if !errors.Is(err, types.ErrorOutputNil) {
+		output = &DeleteAutomationScheduleV1Output{
+			Response: outputClient.Response,
+		}
+	}
+	return output, err
+}
@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"opsicle/internal/controller"
+	"opsicle/internal/types"
+	"time"
+)
+
+type CreateTemplateTriggerV1Output struct {
+	Data controller.TemplateTriggerV1Output
+	http.Response
+}
+
+type CreateTemplateTriggerV1Input struct {
+	Name            string            `json:"name"`
+	OrgId           *string           `json:"orgId"`
+	TemplateId      string            `json:"templateId"`
+	TemplateVersion *int64            `json:"templateVersion"`
+	AuthType        string            `json:"authType"`
+	OrgTokenId      *string           `json:"orgTokenId"`
+	VariableMapping map[string]string `json:"variableMapping"`
+	DedupKey        *string           `json:"dedupKey"`
+	DedupWindow     time.Duration     `json:"dedupWindow"`
+	RateLimit       int               `json:"rateLimit"`
+	RateLimitWindow time.Duration     `json:"rateLimitWindow"`
+}
+
+// CreateTemplateTriggerV1 creates a trigger which runs automations from
+// a template when a webhook is received, the HMAC secret of triggers
+// using HMAC authentication is only returned by this call
+func (c Client) CreateTemplateTriggerV1(input CreateTemplateTriggerV1Input) (*CreateTemplateTriggerV1Output, error) {
+	var outputData controller.TemplateTriggerV1Output
+	outputClient, err := c.do(request{
+		Method: http.MethodPost,
+		Path:   "/api/v1/trigger",
+		Data:   input,
+		Output: &outputData,
+	})
+	var output *CreateTemplateTriggerV1Output = nil
+	if !errors.Is(err, types.ErrorOutputNil) {
+		output = &CreateTemplateTriggerV1Output{
+			Data:     outputData,
+			Response: outputClient.Response,
+		}
+	}
+	return output, err
+}
+
+type ListTemplateTriggersV1Output struct {
+	Data controller.ListTemplateTriggersV1Output
+	http.Response
+}
+
+type ListTemplateTriggersV1Input struct {
+	// OrgId when defined lists the triggers of the org instead of the
+	// user's own triggers
+	OrgId *string
+}
+
+// ListTemplateTriggersV1 returns the triggers of an org or those created
+// by the user outside of orgs
+func (c Client) ListTemplateTriggersV1(input ListTemplateTriggersV1Input) (*ListTemplateTriggersV1Output, error) {
+	var outputData controller.ListTemplateTriggersV1Output
+	var query url.Values
+	if input.OrgId != nil {
+		query = url.Values{"orgId": []string{*input.OrgId}}
+	}
+	outputClient, err := c.do(request{
+		Method: http.MethodGet,
+		Path:   "/api/v1/triggers",
+		Query:  query,
+		Output: &outputData,
+	})
+	var output *ListTemplateTriggersV1Output = nil
+	if !errors.Is(err, types.ErrorOutputNil) {
+		output = &ListTemplateTriggersV1Output{
+			Data:     outputData,
+			Response: outputClient.Response,
+		}
+	}
+	return output, err
+}
+
+type DeleteTemplateTriggerV1Output struct {
+	http.Response
+}
+
+type DeleteTemplateTriggerV1Input struct {
+	TriggerId string
+}
+
+// DeleteTemplateTriggerV1 deletes a trigger, webhooks sent to it are
+// rejected afterwards
+func (c Client) DeleteTemplateTriggerV1(input DeleteTemplateTriggerV1Input) (*DeleteTemplateTriggerV1Output, error) {
+	var outputData any
+	outputClient, err := c.do(request{
+		Method: http.MethodDelete,
+		Path:   fmt.Sprintf("/api/v1/trigger/%s", input.TriggerId),
+		Output: &outputData,
+	})
+	var output *DeleteTemplateTriggerV1Output = nil
+	if !errors.Is(err, types.ErrorOutputNil) {
+		output = &DeleteTemplateTriggerV1Output{
+			Response: outputClient.Response,
+		}
+	}
+	return output, err
+}
@@ -0,0 +1,239 @@
+package coordinator
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"opsicle/internal/common"
+	"opsicle/internal/types"
+	"reflect"
+	"syscall"
+	"time"
+)
+
+var (
+	// DefaultClientTimeout is the request timeout of the client, this
+	// needs to be higher than the duration which the coordinator holds
+	// requests for jobs open for
+	DefaultClientTimeout = 40 * time.Second
+)
+
+type NewClientOpts struct {
+	// CoordinatorUrl is the URL of the coordinator
+	CoordinatorUrl string
+
+	// Ca is a PEM-encoded certificate authority bundle used to verify
+	// the coordinator's server certificate, when not defined the system
+	// pool is used
+	Ca []byte
+
+	// Cert is the client certificate presented to the coordinator,
+	// this should be a certificate issued by the org's certificate
+	// authority
+	Cert *tls.Certificate
+
+	// BearerAuth when defined sends the org token as a bearer token
+	BearerAuth *NewClientBearerAuthOpts
+
+	// Id will be included in the user-agent for identification
+	Id string
+
+	// RequestTimeout overrides `DefaultClientTimeout` when defined
+	RequestTimeout time.Duration
+}
+
+type NewClientBearerAuthOpts struct {
+	TokenId string
+	Token   string
+}
+
+func NewClient(opts NewClientOpts) (*Client, error) {
+	timeout := DefaultClientTimeout
+	if opts.RequestTimeout != 0 {
+		timeout = opts.RequestTimeout
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if len(opts.Ca) > 0 {
+		rootCas := x509.NewCertPool()
+		if !rootCas.AppendCertsFromPEM(opts.Ca) {
+			return nil, fmt.Errorf("%w: failed to parse any certificates from the provided ca", types.ErrorInvalidInput)
+		}
+		tlsConfig.RootCAs = rootCas
+	}
+	if opts.Cert != nil && len(opts.Cert.Certificate) > 0 {
+		tlsConfig.Certificates = []tls.Certificate{*opts.Cert}
+	}
+
+	client := &Client{
+		BearerAuth: opts.BearerAuth,
+		HttpClient: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: tlsConfig,
+			},
+		},
+		Id: opts.Id,
+	}
+
+	coordinatorUrl, err := url.Parse(opts.CoordinatorUrl)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to parse provided coordinatorUrl[%s]: %w", types.ErrorInvalidInput, opts.CoordinatorUrl, err)
+	}
+	if coordinatorUrl.Scheme == "" {
+		return nil, fmt.Errorf("%w: failed to determine url scheme of coordinatorUrl[%s]", types.ErrorInvalidInput, opts.CoordinatorUrl)
+	}
+	client.CoordinatorUrl = coordinatorUrl
+
+	return client, nil
+}
+
+type Client struct {
+	// CoordinatorUrl is the URL where the coordinator service is
+	// accessible at
+	CoordinatorUrl *url.URL
+	BearerAuth     *NewClientBearerAuthOpts
+
+	// HttpClient is the HTTP client
+	HttpClient *http.Client
+
+	// Id will be included in the user-agent for identification
+	Id string
+}
+
+type request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Data   any
+	Output any
+}
+
+func (c request) Validate() error {
+	errs := []error{}
+	if c.Output == nil {
+		errs = append(errs, types.ErrorOutputNil)
+	} else if reflect.TypeOf(c.Output).Kind() != reflect.Ptr {
+		errs = append(errs, types.ErrorOutputNotPointer)
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+type clientOutput struct {
+	code    error
+	message string
+
+	http.Response
+}
+
+func (c clientOutput) GetErrorCode() error {
+	if c.code == nil {
+		return nil
+	}
+	switch c.code.Error() {
+	case types.ErrorAuthRequired.Error():
+		return types.ErrorAuthRequired
+	case types.ErrorInvalidInput.Error():
+		return types.ErrorInvalidInput
+	case types.ErrorNotFound.Error():
+		return types.ErrorNotFound
+	case types.ErrorQueueIssue.Error():
+		return types.ErrorQueueIssue
+	}
+	return c.code
+}
+
+func (c clientOutput) GetMessage() string {
+	return c.message
+}
+
+func (c clientOutput) GetStatusCode() int {
+	return c.Response.StatusCode
+}
+
+func (c Client) addRequiredHeaders(httpRequest *http.Request) {
+	httpRequest.Header.Add("Content-Type", "application/json")
+	httpRequest.Header.Add("User-Agent", fmt.Sprintf("opsicle/coordinator-sdk/client-%s", c.Id))
+	if c.BearerAuth != nil {
+		httpRequest.Header.Add("Authorization", fmt.Sprintf("Bearer %s", c.BearerAuth.Token))
+		httpRequest.Header.Add("X-Token-Id", c.BearerAuth.TokenId)
+	}
+}
+
+func (c Client) do(input request) (*clientOutput, error) {
+	coordinatorUrl := *c.CoordinatorUrl
+	coordinatorUrl.Path = input.Path
+	if input.Query != nil {
+		coordinatorUrl.RawQuery = input.Query.Encode()
+	}
+	var requestBody io.Reader = nil
+	if input.Data != nil {
+		inputData, err := json.Marshal(input.Data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", types.ErrorClientMarshalInput, err)
+		}
+		requestBody = bytes.NewBuffer(inputData)
+	}
+	httpRequest, err := http.NewRequest(input.Method, coordinatorUrl.String(), requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w: %w", types.ErrorClientRequestCreation, types.ErrorOutputNil, err)
+	}
+	c.addRequiredHeaders(httpRequest)
+	httpResponse, err := c.HttpClient.Do(httpRequest)
+	if err != nil {
+		if isConnectionRefused(err) {
+			return nil, fmt.Errorf("%w: %w: %w", types.ErrorConnectionRefused, types.ErrorOutputNil, err)
+		} else if isTimeout(err) {
+			return nil, fmt.Errorf("%w: %w: %w", types.ErrorConnectionTimedOut, types.ErrorOutputNil, err)
+		}
+		return nil, fmt.Errorf("%w: %w: %w", types.ErrorClientRequestExecution, types.ErrorOutputNil, err)
+	}
+	defer httpResponse.Body.Close()
+	output := &clientOutput{Response: *httpResponse}
+	if httpResponse.StatusCode == http.StatusMethodNotAllowed {
+		return output, types.ErrorInvalidEndpoint
+	}
+	responseBody, err := io.ReadAll(httpResponse.Body)
+	if err != nil {
+		return output, fmt.Errorf("%w: %w", types.ErrorClientResponseReading, err)
+	}
+	var response common.HttpResponse
+	if err := json.Unmarshal(responseBody, &response); err != nil {
+		return output, fmt.Errorf("%w: %w", types.ErrorClientUnmarshalResponse, err)
+	}
+	output.message = response.Message
+	output.code = errors.New(response.Code)
+	if response.Data != nil && input.Output != nil {
+		responseData, err := json.Marshal(response.Data)
+		if err != nil {
+			return output, fmt.Errorf("%w: %w", types.ErrorClientMarshalResponseData, err)
+		}
+		if err := json.Unmarshal(responseData, input.Output); err != nil {
+			return output, fmt.Errorf("%w: %w", types.ErrorClientUnmarshalOutput, err)
+		}
+	}
+	if !response.Success {
+		return output, fmt.Errorf("%w: received status code %v ('%s'): %w", types.ErrorClientUnsuccessfulResponse, output.GetStatusCode(), output.GetMessage(), output.GetErrorCode())
+	}
+	return output, nil
+}
+
+func isConnectionRefused(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,157 @@
+package coordinator
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"opsicle/internal/automations"
+	"opsicle/internal/coordinator"
+	"time"
+)
+
+type GetJobV1Input struct {
+	// Wait is the duration the coordinator should wait for a job
+	// before responding, the coordinator's default is used if this
+	// is zero
+	Wait time.Duration
+
+	WorkerId string
+}
+
+type GetJobV1Output struct {
+	Data coordinator.GetJobV1Output
+	http.Response
+}
+
+// GetJobV1 long-polls the coordinator for an automation to run,
+// `.Data.IsAvailable` is false if no automation became available
+func (c Client) GetJobV1(input GetJobV1Input) (*GetJobV1Output, error) {
+	var outputData coordinator.GetJobV1Output
+	query := url.Values{}
+	if input.Wait != 0 {
+		query.Set("wait", input.Wait.String())
+	}
+	query.Set("workerId", input.WorkerId)
+	outputClient, err := c.do(request{
+		Method: http.MethodGet,
+		Path:   "/api/v1/jobs",
+		Query:  query,
+		Output: &outputData,
+	})
+	if outputClient == nil {
+		return nil, err
+	}
+	return &GetJobV1Output{
+		Data:     outputData,
+		Response: outputClient.Response,
+	}, err
+}
+
+type GetJobCancellationV1Input struct {
+	AutomationId string
+
+	// Wait is the duration the coordinator should wait for a
+	// cancellation before responding, the coordinator's default is
+	// used if this is zero
+	Wait time.Duration
+}
+
+type GetJobCancellationV1Output struct {
+	Data coordinator.GetJobCancellationV1Output
+	http.Response
+}
+
+// GetJobCancellationV1 long-polls the coordinator for the cancellation
+// of an automation run that the worker is processing,
+// `.Data.IsCancelled` is false if the run was not cancelled
+func (c Client) GetJobCancellationV1(input GetJobCancellationV1Input) (*GetJobCancellationV1Output, error) {
+	var outputData coordinator.GetJobCancellationV1Output
+	query := url.Values{}
+	if input.Wait != 0 {
+		query.Set("wait", input.Wait.String())
+	}
+	outputClient, err := c.do(request{
+		Method: http.MethodGet,
+		Path:   fmt.Sprintf("/api/v1/jobs/%s/cancellation", input.AutomationId),
+		Query:  query,
+		Output: &outputData,
+	})
+	if outputClient == nil {
+		return nil, err
+	}
+	return &GetJobCancellationV1Output{
+		Data:     outputData,
+		Response: outputClient.Response,
+	}, err
+}
+
+type UpdateJobStatusV1Output struct {
+	Data automations.RunStatus
+	http.Response
+}
+
+// UpdateJobStatusV1 reports a status update of an automation run that
+// the worker is processing
+func (c Client) UpdateJobStatusV1(input automations.RunStatusUpdate) (*UpdateJobStatusV1Output, error) {
+	var outputData automations.RunStatus
+	outputClient, err := c.do(request{
+		Method: http.MethodPost,
+		Path:   fmt.Sprintf("/api/v1/jobs/%s/status", input.AutomationId),
+		Data:   input,
+		Output: &outputData,
+	})
+	if outputClient == nil {
+		return nil, err
+	}
+	return &UpdateJobStatusV1Output{
+		Data:     outputData,
+		Response: outputClient.Response,
+	}, err
+}
+
+type PushJobLogsV1Output struct {
+	http.Response
+}
+
+// PushJobLogsV1 sends logs of an automation run that the worker is
+// processing to the coordinator
+func (c Client) PushJobLogsV1(input automations.LogChunk) (*PushJobLogsV1Output, error) {
+	outputClient, err := c.do(request{
+		Method: http.MethodPost,
+		Path:   fmt.Sprintf("/api/v1/jobs/%s/logs", input.AutomationId),
+		Data:   input,
+	})
+	if outputClient == nil {
+		return nil, err
+	}
+	return &PushJobLogsV1Output{
+		Response: outputClient.Response,
+	}, err
+}
+
+type PushJobArtifactsV1Input struct {
+	AutomationId string `json:"-"`
+
+	// Content is the gzipped tarball of the artifacts
+	Content []byte `json:"content"`
+}
+
+type PushJobArtifactsV1Output struct {
+	http.Response
+}
+
+// PushJobArtifactsV1 sends the artifacts collected from an automation
+// run that the worker processed to the coordinator
+func (c Client) PushJobArtifactsV1(input PushJobArtifactsV1Input) (*PushJobArtifactsV1Output, error) {
+	outputClient, err := c.do(request{
+		Method: http.MethodPost,
+		Path:   fmt.Sprintf("/api/v1/jobs/%s/artifacts", input.AutomationId),
+		Data:   input,
+	})
+	if outputClient == nil {
+		return nil, err
+	}
+	return &PushJobArtifactsV1Output{
+		Response: outputClient.Response,
+	}, err
+}
@@ -0,0 +1,67 @@
+package coordinator
+
+import (
+	"net/http"
+	"net/url"
+	"opsicle/internal/coordinator"
+	"opsicle/internal/coordinator/models"
+)
+
+type InitWorkerV1Input struct {
+	Hostname string
+	Runtime  string
+	WorkerId string
+}
+
+type InitWorkerV1Output struct {
+	Data coordinator.WorkerRegistrationV1Output
+	http.Response
+}
+
+// InitWorkerV1 registers the worker with the coordinator
+func (c Client) InitWorkerV1(input InitWorkerV1Input) (*InitWorkerV1Output, error) {
+	var outputData coordinator.WorkerRegistrationV1Output
+	query := url.Values{}
+	query.Set("hostname", input.Hostname)
+	query.Set("runtime", input.Runtime)
+	query.Set("workerId", input.WorkerId)
+	outputClient, err := c.do(request{
+		Method: http.MethodGet,
+		Path:   "/api/v1/init",
+		Query:  query,
+		Output: &outputData,
+	})
+	if outputClient == nil {
+		return nil, err
+	}
+	return &InitWorkerV1Output{
+		Data:     outputData,
+		Response: outputClient.Response,
+	}, err
+}
+
+type GetWorkerStatusV1Output struct {
+	Data models.Worker
+	http.Response
+}
+
+// GetWorkerStatusV1 retrieves the registration of the worker identified
+// by `workerId`
+func (c Client) GetWorkerStatusV1(workerId string) (*GetWorkerStatusV1Output, error) {
+	var outputData models.Worker
+	query := url.Values{}
+	query.Set("workerId", workerId)
+	outputClient, err := c.do(request{
+		Method: http.MethodGet,
+		Path:   "/api/v1/status",
+		Query:  query,
+		Output: &outputData,
+	})
+	if outputClient == nil {
+		return nil, err
+	}
+	return &GetWorkerStatusV1Output{
+		Data:     outputData,
+		Response: outputClient.Response,
+	}, err
+}